@@ -0,0 +1,213 @@
+// Package alerts sends notifications when budget usage or remaining engine
+// credits cross configured thresholds, or when watched prices change.
+// Webhook payloads are compatible with Slack incoming webhooks.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Alert kinds
+const (
+	KindBudget  = "budget"
+	KindCredits = "credits"
+	KindPrice   = "price"
+)
+
+// Alert describes one threshold crossing. Price alerts report the previous
+// price as the threshold and the current price as the value.
+type Alert struct {
+	Kind      string    `json:"kind"`
+	Subject   string    `json:"subject"` // engine or tenant name
+	Threshold float64   `json:"threshold"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// Notifier delivers alerts
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Webhook posts alerts as Slack-compatible JSON: the message is in the
+// "text" field and the structured alert in the "alert" field
+type Webhook struct {
+	URL    string
+	Client *http.Client // defaults to a client with a 10s timeout
+}
+
+// NewWebhook creates a webhook notifier for url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(map[string]any{
+		"text":  alert.Message,
+		"alert": alert,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := w.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	// #nosec G704 -- webhook URL is configured by the operator
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error: %s: %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// Monitor fires alerts once per threshold crossing. Budget usage alerts fire
+// as the used fraction rises through BudgetThresholds (e.g. 0.8, 0.95, 1);
+// credit alerts fire as remaining credits fall through CreditThresholds
+// (e.g. 1000, 100, 0). Thresholds re-arm when the value moves back, such as
+// when a budget period resets or credits are topped up.
+type Monitor struct {
+	notifier Notifier
+	budget   *tracker
+	credits  *tracker
+}
+
+// NewMonitor creates a monitor that sends alerts through notifier
+func NewMonitor(notifier Notifier, budgetThresholds, creditThresholds []float64) *Monitor {
+	return &Monitor{
+		notifier: notifier,
+		budget:   newTracker(budgetThresholds, false),
+		credits:  newTracker(creditThresholds, true),
+	}
+}
+
+// Budget records budget usage for subject and returns alerts for the
+// thresholds it newly crossed
+func (m *Monitor) Budget(subject string, used, limit int) []Alert {
+	if limit <= 0 {
+		return nil
+	}
+	fraction := float64(used) / float64(limit)
+
+	var alerts []Alert
+	for _, threshold := range m.budget.cross(subject, fraction) {
+		alerts = append(alerts, Alert{
+			Kind:      KindBudget,
+			Subject:   subject,
+			Threshold: threshold,
+			Value:     fraction,
+			Message:   fmt.Sprintf(":warning: %s has used %d of %d budgeted requests (%.0f%%, threshold %.0f%%)", subject, used, limit, fraction*100, threshold*100),
+			Time:      time.Now().UTC(),
+		})
+	}
+	return alerts
+}
+
+// Credits records the remaining credits of engine and returns alerts for
+// the thresholds it newly crossed
+func (m *Monitor) Credits(engine string, remaining int) []Alert {
+	var alerts []Alert
+	for _, threshold := range m.credits.cross(engine, float64(remaining)) {
+		alerts = append(alerts, Alert{
+			Kind:      KindCredits,
+			Subject:   engine,
+			Threshold: threshold,
+			Value:     float64(remaining),
+			Message:   fmt.Sprintf(":warning: %s has %d API credits remaining (threshold %.0f)", engine, remaining, threshold),
+			Time:      time.Now().UTC(),
+		})
+	}
+	return alerts
+}
+
+// Send delivers alerts in order and returns the delivery errors joined.
+// Tracking and delivery are separate so callers can deliver in the
+// background without reordering threshold crossings.
+func (m *Monitor) Send(ctx context.Context, alerts []Alert) error {
+	var errs []error
+	for _, alert := range alerts {
+		if err := m.notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckBudget records budget usage and sends alerts for newly crossed thresholds
+func (m *Monitor) CheckBudget(ctx context.Context, subject string, used, limit int) error {
+	return m.Send(ctx, m.Budget(subject, used, limit))
+}
+
+// CheckCredits records remaining credits and sends alerts for newly crossed thresholds
+func (m *Monitor) CheckCredits(ctx context.Context, engine string, remaining int) error {
+	return m.Send(ctx, m.Credits(engine, remaining))
+}
+
+// tracker remembers which thresholds have fired per subject
+type tracker struct {
+	thresholds []float64 // sorted in the direction of crossing
+	falling    bool
+
+	mu    sync.Mutex
+	fired map[string]int // number of thresholds fired per subject
+}
+
+func newTracker(thresholds []float64, falling bool) *tracker {
+	sorted := slices.Clone(thresholds)
+	slices.Sort(sorted)
+	if falling {
+		slices.Reverse(sorted)
+	}
+	return &tracker{thresholds: sorted, falling: falling, fired: make(map[string]int)}
+}
+
+// crossed reports whether value is at or past threshold
+func (t *tracker) crossed(value, threshold float64) bool {
+	if t.falling {
+		return value <= threshold
+	}
+	return value >= threshold
+}
+
+// cross returns the thresholds newly crossed by value for subject
+func (t *tracker) cross(subject string, value float64) []float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for n < len(t.thresholds) && t.crossed(value, t.thresholds[n]) {
+		n++
+	}
+
+	fired := t.fired[subject]
+	t.fired[subject] = n
+	if n <= fired {
+		return nil
+	}
+	return t.thresholds[fired:n]
+}
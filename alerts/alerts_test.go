@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recorder struct {
+	alerts []Alert
+}
+
+func (r *recorder) Notify(ctx context.Context, alert Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestMonitorBudget(t *testing.T) {
+	rec := &recorder{}
+	m := NewMonitor(rec, []float64{0.9, 0.5, 1}, nil)
+	ctx := context.Background()
+
+	steps := []struct {
+		used int
+		want []float64
+	}{
+		{40, nil},
+		{50, []float64{0.5}},
+		{60, nil},
+		{100, []float64{0.9, 1}},
+		{100, nil},
+		{0, nil}, // period reset re-arms thresholds
+		{95, []float64{0.5, 0.9}},
+	}
+	for _, step := range steps {
+		rec.alerts = nil
+		if err := m.CheckBudget(ctx, "team-a", step.used, 100); err != nil {
+			t.Fatalf("CheckBudget failed: %v", err)
+		}
+		if len(rec.alerts) != len(step.want) {
+			t.Fatalf("used=%d: expected %d alerts, got %+v", step.used, len(step.want), rec.alerts)
+		}
+		for i, alert := range rec.alerts {
+			if alert.Threshold != step.want[i] || alert.Kind != KindBudget {
+				t.Errorf("used=%d: unexpected alert %+v", step.used, alert)
+			}
+		}
+	}
+}
+
+func TestMonitorCredits(t *testing.T) {
+	rec := &recorder{}
+	m := NewMonitor(rec, nil, []float64{100, 1000})
+	ctx := context.Background()
+
+	for _, remaining := range []int{5000, 900, 800, 50, 2000, 500} {
+		if err := m.CheckCredits(ctx, "serpapi", remaining); err != nil {
+			t.Fatalf("CheckCredits failed: %v", err)
+		}
+	}
+
+	want := []float64{1000, 100, 1000}
+	if len(rec.alerts) != len(want) {
+		t.Fatalf("Expected %d alerts, got %+v", len(want), rec.alerts)
+	}
+	for i, alert := range rec.alerts {
+		if alert.Threshold != want[i] || alert.Subject != "serpapi" {
+			t.Errorf("Alert %d: unexpected %+v", i, alert)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	m := NewMonitor(NewWebhook(server.URL), []float64{0.8}, nil)
+	if err := m.CheckBudget(context.Background(), "team-a", 8, 10); err != nil {
+		t.Fatalf("CheckBudget failed: %v", err)
+	}
+	if text, _ := payload["text"].(string); text == "" {
+		t.Errorf("Expected Slack-compatible text field, got %v", payload)
+	}
+	if alert, _ := payload["alert"].(map[string]any); alert["kind"] != KindBudget {
+		t.Errorf("Expected structured alert, got %v", payload)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := NewWebhook(failing.URL).Notify(context.Background(), Alert{Message: "x"}); err == nil {
+		t.Error("Expected error for non-2xx webhook response")
+	}
+}
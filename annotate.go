@@ -0,0 +1,197 @@
+package omniserp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Sentiment labels set by KeywordSentiment
+const (
+	SentimentPositive = "positive"
+	SentimentNegative = "negative"
+	SentimentNeutral  = "neutral"
+)
+
+// Annotations enrich a result item, such as with sentiment and the
+// entities it mentions
+type Annotations struct {
+	Sentiment      string   `json:"sentiment,omitempty"`
+	SentimentScore float64  `json:"sentiment_score,omitempty"` // -1 (negative) to 1 (positive)
+	Entities       []string `json:"entities,omitempty"`
+
+	// Labels holds the output of custom annotators
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// AnnotationInput is the text of a result item passed to annotators
+type AnnotationInput struct {
+	Title   string
+	Snippet string
+	Source  string
+	Link    string
+}
+
+// Text returns the title and snippet
+func (in AnnotationInput) Text() string {
+	return strings.TrimSpace(in.Title + "\n" + in.Snippet)
+}
+
+// Annotator adds annotations to a result item
+type Annotator interface {
+	Annotate(ctx context.Context, input AnnotationInput, annotations *Annotations) error
+}
+
+// AnnotatorFunc adapts a function to the Annotator interface
+type AnnotatorFunc func(ctx context.Context, input AnnotationInput, annotations *Annotations) error
+
+// Annotate implements Annotator
+func (f AnnotatorFunc) Annotate(ctx context.Context, input AnnotationInput, annotations *Annotations) error {
+	return f(ctx, input, annotations)
+}
+
+// Annotate runs the annotators over the organic and news results of a
+// normalized result in place. Every item is annotated even if some fail;
+// the errors are joined.
+func Annotate(ctx context.Context, result *NormalizedSearchResult, annotators ...Annotator) error {
+	if result == nil || len(annotators) == 0 {
+		return nil
+	}
+
+	var errs []error
+	annotate := func(input AnnotationInput, target **Annotations) {
+		annotations := *target
+		if annotations == nil {
+			annotations = &Annotations{}
+		}
+		for _, annotator := range annotators {
+			if err := annotator.Annotate(ctx, input, annotations); err != nil {
+				errs = append(errs, fmt.Errorf("failed to annotate %s: %w", input.Link, err))
+			}
+		}
+		*target = annotations
+	}
+	for i := range result.OrganicResults {
+		r := &result.OrganicResults[i]
+		annotate(AnnotationInput{Title: r.Title, Snippet: r.Snippet, Source: r.Domain, Link: r.Link}, &r.Annotations)
+	}
+	for i := range result.NewsResults {
+		r := &result.NewsResults[i]
+		annotate(AnnotationInput{Title: r.Title, Snippet: r.Snippet, Source: r.Source, Link: r.Link}, &r.Annotations)
+	}
+	return errors.Join(errs...)
+}
+
+// KeywordSentiment is a lexicon-based sentiment baseline. The score is the
+// sum of the weights of the words in the title and snippet, with a word
+// preceded by a negation such as "not" counting inversely, divided by the
+// number of matched words plus one so it stays within -1..1.
+// It is fast and dependency-free but far less accurate than a model.
+type KeywordSentiment struct {
+	// Weights maps lowercased words to weights, positive or negative
+	Weights map[string]float64
+
+	// Threshold is the absolute score below which text is neutral
+	Threshold float64
+}
+
+// defaultSentimentWords are the words of the default sentiment lexicon
+var defaultSentimentWords = map[float64][]string{
+	1: {
+		"gain", "gains", "growth", "grows", "rise", "rises", "surge", "surges", "soar", "soars",
+		"record", "beat", "beats", "profit", "profits", "win", "wins", "success", "successful",
+		"strong", "improve", "improves", "improved", "boost", "boosts", "upgrade", "upgraded",
+		"launch", "launches", "award", "praised", "positive", "good", "great", "best", "innovative",
+	},
+	-1: {
+		"loss", "losses", "fall", "falls", "drop", "drops", "plunge", "plunges", "decline", "declines",
+		"miss", "misses", "lawsuit", "sued", "fine", "fined", "fraud", "scandal", "recall", "recalls",
+		"layoff", "layoffs", "cut", "cuts", "weak", "downgrade", "downgraded", "crisis", "breach",
+		"outage", "bankruptcy", "investigation", "probe", "negative", "bad", "worst", "fail", "fails", "failure",
+	},
+}
+
+// negations invert the weight of the following word
+var negations = map[string]bool{"not": true, "no": true, "never": true, "without": true}
+
+// NewKeywordSentiment creates a sentiment annotator with a default English
+// lexicon geared to business and news headlines
+func NewKeywordSentiment() *KeywordSentiment {
+	weights := make(map[string]float64)
+	for weight, words := range defaultSentimentWords {
+		for _, w := range words {
+			weights[w] = weight
+		}
+	}
+	return &KeywordSentiment{Weights: weights, Threshold: 0.2}
+}
+
+// Annotate implements Annotator
+func (k *KeywordSentiment) Annotate(ctx context.Context, input AnnotationInput, annotations *Annotations) error {
+	var score float64
+	var hits int
+	negated := false
+	for _, w := range annotationWords(input.Text()) {
+		if negations[w] {
+			negated = true
+			continue
+		}
+		if weight, ok := k.Weights[w]; ok {
+			if negated {
+				weight = -weight
+			}
+			score += weight
+			hits++
+		}
+		negated = false
+	}
+
+	// Saturates as evidence accumulates: one positive word scores 0.5, two 0.67
+	score /= float64(hits + 1)
+
+	annotations.SentimentScore = score
+	switch {
+	case score > 0 && score >= k.Threshold:
+		annotations.Sentiment = SentimentPositive
+	case score < 0 && score <= -k.Threshold:
+		annotations.Sentiment = SentimentNegative
+	default:
+		annotations.Sentiment = SentimentNeutral
+	}
+	return nil
+}
+
+// EntityMatcher annotates the entities from a watch list that a result
+// mentions, such as brands or competitors in media monitoring. Entities
+// map a canonical name to aliases; the name itself always matches.
+type EntityMatcher struct {
+	Entities map[string][]string
+}
+
+// Annotate implements Annotator
+func (e *EntityMatcher) Annotate(ctx context.Context, input AnnotationInput, annotations *Annotations) error {
+	text := " " + strings.Join(annotationWords(input.Text()), " ") + " "
+	for name, aliases := range e.Entities {
+		for _, alias := range append([]string{name}, aliases...) {
+			phrase := strings.Join(annotationWords(alias), " ")
+			if phrase != "" && strings.Contains(text, " "+phrase+" ") {
+				if !slices.Contains(annotations.Entities, name) {
+					annotations.Entities = append(annotations.Entities, name)
+				}
+				break
+			}
+		}
+	}
+	slices.Sort(annotations.Entities)
+	return nil
+}
+
+// annotationWords splits text into lowercased words
+func annotationWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
@@ -0,0 +1,68 @@
+package omniserp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestKeywordSentiment(t *testing.T) {
+	sentiment := NewKeywordSentiment()
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Acme shares surge after record profits", SentimentPositive},
+		{"Acme hit with lawsuit over data breach", SentimentNegative},
+		{"Acme growth not strong this quarter", SentimentNeutral},
+		{"Acme to hold annual meeting on Tuesday", SentimentNeutral},
+	}
+	for _, tt := range tests {
+		var a Annotations
+		if err := sentiment.Annotate(context.Background(), AnnotationInput{Title: tt.text}, &a); err != nil {
+			t.Fatalf("Annotate failed: %v", err)
+		}
+		if a.Sentiment != tt.want {
+			t.Errorf("%q: sentiment %s (score %g), want %s", tt.text, a.Sentiment, a.SentimentScore, tt.want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	result := &NormalizedSearchResult{
+		OrganicResults: []OrganicResult{{Title: "Acme Corp beats estimates", Link: "https://a.example"}},
+		NewsResults:    []NewsResult{{Title: "Globex and ACME announce merger", Snippet: "Regulators open probe", Link: "https://b.example"}},
+	}
+	entities := &EntityMatcher{Entities: map[string][]string{
+		"Acme Corp": {"acme"},
+		"Globex":    nil,
+		"Initech":   nil,
+	}}
+
+	if err := Annotate(context.Background(), result, NewKeywordSentiment(), entities); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	organic := result.OrganicResults[0].Annotations
+	if organic == nil || organic.Sentiment != SentimentPositive || !slices.Equal(organic.Entities, []string{"Acme Corp"}) {
+		t.Errorf("Unexpected organic annotations: %+v", organic)
+	}
+	news := result.NewsResults[0].Annotations
+	if news == nil || news.Sentiment != SentimentNegative || !slices.Equal(news.Entities, []string{"Acme Corp", "Globex"}) {
+		t.Errorf("Unexpected news annotations: %+v", news)
+	}
+
+	failing := AnnotatorFunc(func(ctx context.Context, input AnnotationInput, a *Annotations) error {
+		return errors.New("model unavailable")
+	})
+	labeler := AnnotatorFunc(func(ctx context.Context, input AnnotationInput, a *Annotations) error {
+		a.Labels = map[string]string{"topic": "business"}
+		return nil
+	})
+	if err := Annotate(context.Background(), result, failing, labeler); err == nil {
+		t.Error("Expected annotator errors to be returned")
+	}
+	if result.NewsResults[0].Annotations.Labels["topic"] != "business" {
+		t.Error("Expected later annotators to run after a failure")
+	}
+}
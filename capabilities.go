@@ -0,0 +1,176 @@
+package omniserp
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Search parameters, by their JSON names in SearchParams
+const (
+	ParamQuery      = "query"
+	ParamLocation   = "location"
+	ParamLanguage   = "language"
+	ParamCountry    = "country"
+	ParamNumResults = "num_results"
+	ParamPage       = "page"
+	ParamFreshness  = "freshness"
+	ParamSafeSearch = "safe_search"
+	ParamVerbatim   = "verbatim"
+
+	// ParamCites is only honored by some engines for scholar searches and
+	// is not part of AllParams
+	ParamCites = "cites"
+)
+
+// AllParams returns the names of all search parameters in SearchParams order
+func AllParams() []string {
+	return []string{
+		ParamQuery,
+		ParamLocation,
+		ParamLanguage,
+		ParamCountry,
+		ParamNumResults,
+		ParamPage,
+		ParamFreshness,
+		ParamSafeSearch,
+		ParamVerbatim,
+	}
+}
+
+// ParamReporter is implemented by engines that report the search parameters
+// they honor per operation. Engines that do not implement it are assumed to
+// honor every parameter of their supported search operations.
+type ParamReporter interface {
+	// SupportedParams returns the honored search parameters of a supported
+	// operation, or none for operations that take other parameters, such as
+	// webpage scraping
+	SupportedParams(operation string) []string
+}
+
+// scrapeOperation is the operation that takes ScrapeParams
+const scrapeOperation = "webpage_scrape"
+
+// Capabilities is a matrix of engines, the operations they support, and the
+// search parameters they honor for each
+type Capabilities struct {
+	// Engines are the engine names in sorted order
+	Engines []string `json:"engines"`
+
+	// Operations are all operations supported by any engine, in the order
+	// reported by the engine supporting the most
+	Operations []string `json:"operations"`
+
+	// Params are all search parameters
+	Params []string `json:"params"`
+
+	// Support maps engine and operation names to the honored parameters;
+	// unsupported operations are absent
+	Support map[string]map[string][]string `json:"support"`
+}
+
+// CapabilityMatrix builds the capability matrix of the registered engines
+func CapabilityMatrix(registry *Registry) *Capabilities {
+	engines := registry.GetAll()
+	m := &Capabilities{
+		Engines: registry.List(),
+		Params:  AllParams(),
+		Support: make(map[string]map[string][]string, len(engines)),
+	}
+	slices.Sort(m.Engines)
+
+	// Take the operation order from the most capable engine first
+	byTools := slices.Clone(m.Engines)
+	slices.SortStableFunc(byTools, func(a, b string) int {
+		return cmp.Compare(len(engines[b].GetSupportedTools()), len(engines[a].GetSupportedTools()))
+	})
+	for _, name := range byTools {
+		for _, operation := range engines[name].GetSupportedTools() {
+			if !slices.Contains(m.Operations, operation) {
+				m.Operations = append(m.Operations, operation)
+			}
+		}
+	}
+
+	for _, name := range m.Engines {
+		engine := engines[name]
+		reporter, _ := engine.(ParamReporter)
+		support := make(map[string][]string)
+		for _, operation := range engine.GetSupportedTools() {
+			switch {
+			case reporter != nil:
+				support[operation] = reporter.SupportedParams(operation)
+			case operation == scrapeOperation:
+				support[operation] = []string{}
+			default:
+				support[operation] = AllParams()
+			}
+			if support[operation] == nil {
+				support[operation] = []string{}
+			}
+		}
+		m.Support[name] = support
+	}
+	return m
+}
+
+// Supports reports whether an engine supports an operation
+func (m *Capabilities) Supports(engine, operation string) bool {
+	_, ok := m.Support[engine][operation]
+	return ok
+}
+
+// SupportedParams returns the parameters that an engine honors for an
+// operation, or nil if it does not support the operation
+func (m *Capabilities) SupportedParams(engine, operation string) []string {
+	return m.Support[engine][operation]
+}
+
+// Markdown renders the matrix as a table of operations by engines, followed
+// by a table of honored parameters per engine
+func (m *Capabilities) Markdown() string {
+	var b strings.Builder
+	writeRow := func(cells ...string) {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	writeHeader := func(cells ...string) {
+		writeRow(cells...)
+		rule := make([]string, len(cells))
+		for i := range rule {
+			rule[i] = "---"
+		}
+		writeRow(rule...)
+	}
+	mark := func(ok bool) string {
+		if ok {
+			return "✓"
+		}
+		return "✗"
+	}
+
+	writeHeader(append([]string{"Operation"}, m.Engines...)...)
+	for _, operation := range m.Operations {
+		row := []string{operation}
+		for _, engine := range m.Engines {
+			row = append(row, mark(m.Supports(engine, operation)))
+		}
+		writeRow(row...)
+	}
+
+	for _, engine := range m.Engines {
+		fmt.Fprintf(&b, "\n### %s\n\n", engine)
+		writeHeader(append([]string{"Operation"}, m.Params...)...)
+		for _, operation := range m.Operations {
+			if !m.Supports(engine, operation) || operation == scrapeOperation {
+				continue
+			}
+			row := []string{operation}
+			for _, param := range m.Params {
+				row = append(row, mark(slices.Contains(m.SupportedParams(engine, operation), param)))
+			}
+			writeRow(row...)
+		}
+	}
+	return b.String()
+}
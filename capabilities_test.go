@@ -0,0 +1,69 @@
+package omniserp
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// toolEngine is an Engine stub that reports its name and tools
+type toolEngine struct {
+	Engine
+	name  string
+	tools []string
+}
+
+func (e toolEngine) GetName() string             { return e.name }
+func (e toolEngine) GetSupportedTools() []string { return e.tools }
+
+// paramEngine is a toolEngine that honors only the query
+type paramEngine struct {
+	toolEngine
+}
+
+func (e paramEngine) SupportedParams(operation string) []string {
+	return []string{ParamQuery}
+}
+
+func TestCapabilityMatrix(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(toolEngine{name: "full", tools: []string{"google_search", "google_search_news", "webpage_scrape"}})
+	registry.Register(paramEngine{toolEngine{name: "basic", tools: []string{"google_search"}}})
+
+	m := CapabilityMatrix(registry)
+	if !slices.Equal(m.Engines, []string{"basic", "full"}) {
+		t.Errorf("Expected sorted engines, got %v", m.Engines)
+	}
+	if !slices.Equal(m.Operations, []string{"google_search", "google_search_news", "webpage_scrape"}) {
+		t.Errorf("Unexpected operations: %v", m.Operations)
+	}
+	if !m.Supports("full", "google_search_news") || m.Supports("basic", "google_search_news") {
+		t.Error("Unexpected support for google_search_news")
+	}
+	if !slices.Equal(m.SupportedParams("basic", "google_search"), []string{ParamQuery}) {
+		t.Errorf("Expected the reported parameters, got %v", m.SupportedParams("basic", "google_search"))
+	}
+	if !slices.Equal(m.SupportedParams("full", "google_search"), AllParams()) {
+		t.Errorf("Expected all parameters by default, got %v", m.SupportedParams("full", "google_search"))
+	}
+	if params := m.SupportedParams("full", "webpage_scrape"); params == nil || len(params) != 0 {
+		t.Errorf("Expected no search parameters for scraping, got %v", params)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Capabilities
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Supports("full", "webpage_scrape") {
+		t.Errorf("Expected the matrix to round-trip through JSON, got %s (%v)", data, err)
+	}
+
+	md := m.Markdown()
+	for _, want := range []string{"| Operation | basic | full |", "| google_search_news | ✗ | ✓ |", "### basic"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in the Markdown:\n%s", want, md)
+		}
+	}
+}
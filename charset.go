@@ -0,0 +1,137 @@
+package omniserp
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Charsets that DecodeHTML transcodes to UTF-8
+const (
+	CharsetUTF8        = "utf-8"
+	CharsetUTF16LE     = "utf-16le"
+	CharsetUTF16BE     = "utf-16be"
+	CharsetWindows1252 = "windows-1252"
+	CharsetISO885915   = "iso-8859-15"
+)
+
+// metaCharset matches <meta charset=...> and the charset parameter of
+// <meta http-equiv="Content-Type" content="...">
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.-]+)`)
+
+// metaPrescanBytes is how much of a page is searched for a <meta> charset,
+// as in the HTML standard's prescan
+const metaPrescanBytes = 1024
+
+// charsetAliases maps charset labels to the charsets above. As in browsers,
+// ISO-8859-1 and US-ASCII are decoded as their superset windows-1252.
+var charsetAliases = map[string]string{
+	"utf-8": CharsetUTF8, "utf8": CharsetUTF8, "unicode-1-1-utf-8": CharsetUTF8,
+	"utf-16": CharsetUTF16LE, "utf-16le": CharsetUTF16LE, "utf-16be": CharsetUTF16BE,
+	"windows-1252": CharsetWindows1252, "cp1252": CharsetWindows1252, "x-cp1252": CharsetWindows1252,
+	"iso-8859-1": CharsetWindows1252, "iso8859-1": CharsetWindows1252, "iso_8859-1": CharsetWindows1252,
+	"latin1": CharsetWindows1252, "l1": CharsetWindows1252, "us-ascii": CharsetWindows1252, "ascii": CharsetWindows1252,
+	"iso-8859-15": CharsetISO885915, "iso8859-15": CharsetISO885915, "iso_8859-15": CharsetISO885915,
+	"latin9": CharsetISO885915, "l9": CharsetISO885915,
+}
+
+// windows1252 maps bytes 0x80-0x9F to runes; the other bytes equal their
+// code points. Undefined bytes map to the C1 control of the same value.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// iso885915 lists the bytes where ISO-8859-15 differs from ISO-8859-1
+var iso885915 = map[byte]rune{
+	0xA4: 0x20AC, 0xA6: 0x0160, 0xA8: 0x0161, 0xB4: 0x017D,
+	0xB8: 0x017E, 0xBC: 0x0152, 0xBD: 0x0153, 0xBE: 0x0178,
+}
+
+// DetectCharset returns the charset label of a page from its byte order
+// mark, the charset parameter of contentType, or a <meta> tag, in that
+// order. Without a declaration it returns "utf-8" for valid UTF-8 and
+// "windows-1252" otherwise.
+func DetectCharset(contentType string, body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return CharsetUTF8
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return CharsetUTF16LE
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		return CharsetUTF16BE
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(strings.TrimSpace(params["charset"]))
+	}
+
+	if match := metaCharset.FindSubmatch(body[:min(len(body), metaPrescanBytes)]); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+
+	if utf8.Valid(body) {
+		return CharsetUTF8
+	}
+	return CharsetWindows1252
+}
+
+// DecodeHTML transcodes a page to UTF-8 using the charset found by
+// DetectCharset and returns the text with the detected charset label.
+// Byte order marks are removed. Charsets other than UTF-8, UTF-16,
+// windows-1252 (including ISO-8859-1 and US-ASCII), and ISO-8859-15 are not
+// transcoded; their invalid UTF-8 sequences are replaced with U+FFFD.
+func DecodeHTML(contentType string, body []byte) (string, string) {
+	label := DetectCharset(contentType, body)
+
+	switch charsetAliases[label] {
+	case CharsetUTF16LE, CharsetUTF16BE:
+		return decodeUTF16(body, charsetAliases[label] == CharsetUTF16BE), label
+	case CharsetWindows1252:
+		return decodeSingleByte(body, nil), label
+	case CharsetISO885915:
+		return decodeSingleByte(body, iso885915), label
+	default:
+		text := strings.TrimPrefix(string(body), "\uFEFF")
+		return strings.ToValidUTF8(text, "\uFFFD"), label
+	}
+}
+
+// decodeSingleByte decodes windows-1252, applying overrides for the
+// ISO-8859 variants that differ from it
+func decodeSingleByte(body []byte, overrides map[byte]rune) string {
+	var b strings.Builder
+	b.Grow(len(body))
+	for _, c := range body {
+		switch r, ok := overrides[c]; {
+		case ok:
+			b.WriteRune(r)
+		case c >= 0x80 && c < 0xA0 && overrides == nil:
+			b.WriteRune(windows1252[c-0x80])
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// decodeUTF16 decodes UTF-16 text, skipping a byte order mark
+func decodeUTF16(body []byte, bigEndian bool) string {
+	if len(body) >= 2 && (body[0] == 0xFF && body[1] == 0xFE || body[0] == 0xFE && body[1] == 0xFF) {
+		body = body[2:]
+	}
+	units := make([]uint16, len(body)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(body[2*i])<<8 | uint16(body[2*i+1])
+		} else {
+			units[i] = uint16(body[2*i+1])<<8 | uint16(body[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
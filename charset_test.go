@@ -0,0 +1,47 @@
+package omniserp
+
+import (
+	"testing"
+)
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"header", "text/html; charset=ISO-8859-1", "<html>", "iso-8859-1"},
+		{"meta charset", "text/html", `<head><meta charset="Shift_JIS">`, "shift_jis"},
+		{"meta http-equiv", "", `<meta http-equiv="Content-Type" content="text/html; charset=windows-1252">`, "windows-1252"},
+		{"bom", "text/html; charset=iso-8859-1", "\xEF\xBB\xBFcaf\xC3\xA9", "utf-8"},
+		{"valid utf-8", "", "caf\xC3\xA9", "utf-8"},
+		{"undeclared legacy", "", "caf\xE9", "windows-1252"},
+	}
+	for _, tt := range tests {
+		if got := DetectCharset(tt.contentType, []byte(tt.body)); got != tt.want {
+			t.Errorf("%s: DetectCharset() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{"latin1", "text/html; charset=iso-8859-1", []byte("caf\xE9 \x93quoted\x94"), "café “quoted”"},
+		{"latin9", "text/html; charset=iso-8859-15", []byte("\xA4 5"), "€ 5"},
+		{"utf-8 bom", "", []byte("\xEF\xBB\xBFna\xC3\xAFve"), "naïve"},
+		{"utf-16le", "", []byte{0xFF, 0xFE, 'h', 0, 0xE9, 0}, "hé"},
+		{"utf-16be", "", []byte{0xFE, 0xFF, 0, 'h', 0, 0xE9}, "hé"},
+		{"unsupported", "text/html; charset=shift_jis", []byte("a\x82\xa0b"), "a�b"},
+	}
+	for _, tt := range tests {
+		if got, _ := DecodeHTML(tt.contentType, tt.body); got != tt.want {
+			t.Errorf("%s: DecodeHTML() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
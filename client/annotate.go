@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"log"
+
+	"github.com/plexusone/omniserp"
+)
+
+// SetAnnotators sets the annotators run over the organic and news results
+// of normalized searches, or disables annotation if none are given
+func (c *Client) SetAnnotators(annotators ...omniserp.Annotator) {
+	c.mu.Lock()
+	c.annotators = annotators
+	c.mu.Unlock()
+}
+
+// currentAnnotators returns the annotators of normalized searches
+func (c *Client) currentAnnotators() []omniserp.Annotator {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.annotators
+}
+
+// annotate runs the annotators over a normalized result. Like indexing,
+// annotation is best effort: failures are logged and do not fail the search.
+func (c *Client) annotate(ctx context.Context, normalized *omniserp.NormalizedSearchResult) {
+	annotators := c.currentAnnotators()
+	if len(annotators) == 0 {
+		return
+	}
+	if err := omniserp.Annotate(ctx, normalized, annotators...); err != nil {
+		log.Printf("Failed to annotate results: %v", err)
+	}
+}
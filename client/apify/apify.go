@@ -0,0 +1,495 @@
+// Package apify implements the omniserp.Engine interface with the Apify
+// Google Search Results Scraper actor. Apify actors run asynchronously: a
+// search starts a run of the actor, waits for it to finish, and reads the
+// result pages from the run's dataset. The engine hides the run, so every
+// search is one call bounded by a configurable timeout.
+package apify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.apify.com"
+	engineName    = "apify"
+	engineVersion = "1.0.0"
+
+	// actorID is the Google Search Results Scraper, with "~" separating
+	// the owner and the name in API paths
+	actorID = "apify~google-search-scraper"
+
+	// DefaultTimeout bounds a search, including the wait for the run,
+	// unless set with SetTimeout or the APIFY_TIMEOUT env var
+	DefaultTimeout = 2 * time.Minute
+
+	// maxWaitForFinish is the longest wait of one poll of a run, in
+	// seconds, that the API allows
+	maxWaitForFinish = 60
+
+	// abortTimeout bounds the request that aborts a run after a search
+	// timed out
+	abortTimeout = 10 * time.Second
+
+	// maxResultsPerPage is the largest number of results the actor returns
+	// per page
+	maxResultsPerPage = 100
+)
+
+// Statuses of actor runs
+const (
+	statusSucceeded = "SUCCEEDED"
+	statusFailed    = "FAILED"
+	statusTimedOut  = "TIMED-OUT"
+	statusAborted   = "ABORTED"
+)
+
+// Engine implements the omniserp.Engine interface for Apify. Results are
+// normalized by the engine and returned as the Data of each search result
+// as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiToken string
+	baseURL  string
+	client   *http.Client
+	timeout  time.Duration
+}
+
+// New creates a new Apify engine from the APIFY_TOKEN env var, and the
+// optional APIFY_TIMEOUT, a duration such as "90s"
+func New() (*Engine, error) {
+	apiToken := os.Getenv("APIFY_TOKEN")
+	if apiToken == "" {
+		return nil, fmt.Errorf("APIFY_TOKEN environment variable is required")
+	}
+	engine, err := NewWithAPIKey(apiToken)
+	if err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("APIFY_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("APIFY_TIMEOUT must be a positive duration such as 90s, got %q", v)
+		}
+		engine.SetTimeout(timeout)
+	}
+	return engine, nil
+}
+
+// NewWithAPIKey creates a new Apify engine with the provided API token
+func NewWithAPIKey(apiToken string) (*Engine, error) {
+	if apiToken == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+
+	return &Engine{
+		apiToken: apiToken,
+		baseURL:  baseURL,
+		client:   &http.Client{},
+		timeout:  DefaultTimeout,
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// SetTimeout sets the longest time a search waits for its actor run
+// (default DefaultTimeout). Runs still going at the timeout are aborted.
+func (e *Engine) SetTimeout(d time.Duration) {
+	e.timeout = d
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{"google_search"}
+}
+
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	if operation != "google_search" {
+		return nil
+	}
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+	}
+}
+
+// Extra declares the actor input fields accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	MobileResults            bool   `extra:"mobileResults" description:"Return the results of a mobile device"`
+	LocationUule             string `extra:"locationUule" description:"Google UULE code of the location to search from"`
+	SearchLanguage           string `extra:"searchLanguage" description:"Only results in this language (lr), such as en"`
+	IncludeUnfilteredResults bool   `extra:"includeUnfilteredResults" description:"Include the results Google omits as similar"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// quickDateRange returns the actor's date range of a freshness period
+func quickDateRange(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour:
+		return "h1"
+	case omniserp.FreshnessDay:
+		return "d1"
+	case omniserp.FreshnessWeek:
+		return "w1"
+	case omniserp.FreshnessMonth:
+		return "m1"
+	case omniserp.FreshnessYear:
+		return "y1"
+	}
+	return ""
+}
+
+// buildInput returns the actor input of a search. The actor scrapes pages
+// from the first, so a later page is reached by scraping the pages before
+// it too.
+func (e *Engine) buildInput(params omniserp.SearchParams) map[string]any {
+	input := map[string]any{
+		"queries":          params.Query,
+		"maxPagesPerQuery": max(params.Page, 1),
+		"saveHtml":         false,
+	}
+	if params.NumResults > 0 {
+		input["resultsPerPage"] = min(params.NumResults, maxResultsPerPage)
+	}
+	if params.Country != "" {
+		input["countryCode"] = strings.ToLower(params.Country)
+	}
+	if params.Language != "" {
+		input["languageCode"] = params.Language
+	}
+	if r := quickDateRange(params.Freshness); r != "" {
+		input["quickDateRange"] = r
+	}
+	for name, value := range omniserp.ExtraArgs(params, e.ExtraParams()) {
+		input[name] = value
+	}
+	return input
+}
+
+// run is an actor run
+type run struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	StatusMessage    string `json:"statusMessage"`
+	DefaultDatasetID string `json:"defaultDatasetId"`
+}
+
+// page is a scraped result page in the dataset of a run
+type page struct {
+	SearchQuery struct {
+		Page int `json:"page"`
+	} `json:"searchQuery"`
+	ResultsTotal   int64 `json:"resultsTotal"`
+	RelatedQueries []struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"relatedQueries"`
+	OrganicResults []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+		Date        string `json:"date"`
+	} `json:"organicResults"`
+	PeopleAlsoAsk []struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+		URL      string `json:"url"`
+		Title    string `json:"title"`
+	} `json:"peopleAlsoAsk"`
+}
+
+// do sends an authenticated request and returns the response body
+func (e *Engine) do(req *http.Request) ([]byte, *omniserp.ResponseMeta, error) {
+	req.Header.Set("Authorization", "Bearer "+e.apiToken)
+
+	start := time.Now()
+	// #nosec G704 -- request to the Apify API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+	return body, meta, nil
+}
+
+// doRun sends a request answered with a run
+func (e *Engine) doRun(req *http.Request) (*run, error) {
+	body, _, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data run `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal run: %w", err)
+	}
+	return &resp.Data, nil
+}
+
+// startRun starts a run of the actor. The run is given the rest of the
+// search timeout, so it stops by itself if the search gives up.
+func (e *Engine) startRun(ctx context.Context, input map[string]any, deadline time.Time) (*run, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input: %w", err)
+	}
+	q := url.Values{}
+	q.Set("timeout", strconv.Itoa(max(int(time.Until(deadline).Seconds()), 1)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v2/acts/"+actorID+"/runs?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return e.doRun(req)
+}
+
+// waitForRun polls a run until it finishes, using the API's long polling
+func (e *Engine) waitForRun(ctx context.Context, r *run, deadline time.Time) (*run, error) {
+	for {
+		switch r.Status {
+		case statusSucceeded:
+			return r, nil
+		case statusFailed, statusTimedOut, statusAborted:
+			return nil, fmt.Errorf("apify run %s %s: %s", r.ID, strings.ToLower(r.Status), r.StatusMessage)
+		}
+
+		wait := min(int(time.Until(deadline).Seconds()), maxWaitForFinish)
+		if wait <= 0 {
+			return nil, fmt.Errorf("apify run %s is not finished: %w", r.ID, context.DeadlineExceeded)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"/v2/actor-runs/"+r.ID+"?waitForFinish="+strconv.Itoa(wait), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		next, err := e.doRun(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to poll apify run %s: %w", r.ID, err)
+		}
+		r = next
+	}
+}
+
+// abortRun stops a run that a search gave up on. It is best effort, with a
+// context of its own since the search context may be done.
+func (e *Engine) abortRun(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v2/actor-runs/"+id+"/abort", nil)
+	if err != nil {
+		return
+	}
+	_, _, _ = e.do(req)
+}
+
+// search runs the actor for a search and returns the requested page of its
+// dataset. The response metadata is that of the dataset request, with the
+// run ID as its request ID.
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams) (*page, string, *omniserp.ResponseMeta, error) {
+	deadline := time.Now().Add(e.timeout)
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	started, err := e.startRun(ctx, e.buildInput(params), deadline)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	finished, err := e.waitForRun(ctx, started, deadline)
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+			e.abortRun(ctx, started.ID)
+		}
+		return nil, "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"/v2/datasets/"+finished.DefaultDatasetID+"/items?clean=true&format=json", nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	body, meta, err := e.do(req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = finished.ID
+	}
+
+	var pages []page
+	if err := json.Unmarshal(body, &pages); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal dataset: %w", err)
+	}
+	want := max(params.Page, 1)
+	for i := range pages {
+		if pages[i].SearchQuery.Page == want {
+			return &pages[i], string(body), meta, nil
+		}
+	}
+	// Fewer pages than requested: the query has no results on that page
+	return &page{}, string(body), meta, nil
+}
+
+// domain returns the host of a URL without www.
+func domain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Language:     params.Language,
+			Country:      params.Country,
+			TotalResults: result.ResultsTotal,
+		},
+	}
+	for _, o := range result.OrganicResults {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: len(normalized.OrganicResults) + 1,
+			Title:    o.Title,
+			Link:     o.URL,
+			URL:      o.URL,
+			Snippet:  o.Description,
+			Domain:   domain(o.URL),
+			Date:     o.Date,
+		})
+	}
+	for _, q := range result.PeopleAlsoAsk {
+		normalized.PeopleAlsoAsk = append(normalized.PeopleAlsoAsk, omniserp.PeopleAlsoAsk{
+			Question: q.Question,
+			Answer:   q.Answer,
+			Title:    q.Title,
+			Link:     q.URL,
+			Source:   domain(q.URL),
+		})
+	}
+	for _, r := range result.RelatedQueries {
+		normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: r.Title, Link: r.URL})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search (not supported by Apify)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Apify")
+}
+
+// SearchImages performs an image search (not supported by Apify)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Apify")
+}
+
+// SearchVideos performs a video search (not supported by Apify)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Apify")
+}
+
+// SearchPlaces performs a places search (not supported by Apify)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Apify")
+}
+
+// SearchMaps performs a maps search (not supported by Apify)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Apify")
+}
+
+// SearchReviews performs a reviews search (not supported by Apify)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Apify")
+}
+
+// SearchShopping performs a shopping search (not supported by Apify)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Apify")
+}
+
+// SearchScholar performs a scholar search (not supported by Apify)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Apify")
+}
+
+// SearchLens performs a visual search (not supported by Apify)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Apify")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Apify)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Apify")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Apify)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Apify")
+}
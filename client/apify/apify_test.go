@@ -0,0 +1,185 @@
+package apify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// fakeAPI is an Apify API with one actor run that finishes after a number
+// of polls
+type fakeAPI struct {
+	mu       sync.Mutex
+	input    map[string]any
+	timeout  string
+	polls    int
+	finishAt int // polls before the run succeeds; negative never finishes
+	aborted  bool
+}
+
+func newTestServer(t *testing.T, finishAt int) (*Engine, *fakeAPI) {
+	t.Helper()
+	dataset, err := os.ReadFile(filepath.Join("testdata", "dataset.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	api := &fakeAPI{finishAt: finishAt}
+	writeRun := func(w http.ResponseWriter, status string) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"id": "run1", "status": status, "defaultDatasetId": "ds1",
+		}})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"type":"token-not-valid","message":"Authentication token is not valid."}}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/acts/"+actorID+"/runs":
+			_ = json.NewDecoder(r.Body).Decode(&api.input)
+			api.timeout = r.URL.Query().Get("timeout")
+			w.WriteHeader(http.StatusCreated)
+			writeRun(w, "READY")
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/actor-runs/run1":
+			if r.URL.Query().Get("waitForFinish") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			api.polls++
+			if api.finishAt >= 0 && api.polls >= api.finishAt {
+				writeRun(w, statusSucceeded)
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+			writeRun(w, "RUNNING")
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/actor-runs/run1/abort":
+			api.aborted = true
+			writeRun(w, statusAborted)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/datasets/ds1/items":
+			_, _ = w.Write(dataset)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-token")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, api
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("APIFY_TOKEN", "test-token")
+	t.Setenv("APIFY_TIMEOUT", "90s")
+	engine, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if engine.timeout != 90*time.Second {
+		t.Errorf("Expected a 90s timeout, got %v", engine.timeout)
+	}
+
+	t.Setenv("APIFY_TIMEOUT", "soon")
+	if _, err := New(); err == nil {
+		t.Error("Expected an error for an invalid timeout")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine, api := newTestServer(t, 2)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Country:    "US",
+		Language:   "en",
+		NumResults: 10,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		Extra:      map[string]any{"mobileResults": "true"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]any{
+		"queries":          "golang",
+		"maxPagesPerQuery": float64(2),
+		"resultsPerPage":   float64(10),
+		"countryCode":      "us",
+		"languageCode":     "en",
+		"quickDateRange":   "w1",
+		"mobileResults":    true,
+	}
+	for name, value := range want {
+		if api.input[name] != value {
+			t.Errorf("Input %s: expected %v, got %v", name, value, api.input[name])
+		}
+	}
+	if api.timeout == "" || api.polls != 2 || api.aborted {
+		t.Errorf("Unexpected run: timeout %q, %d polls, aborted %v", api.timeout, api.polls, api.aborted)
+	}
+	if result.Response.RequestID != "run1" {
+		t.Errorf("Expected the run ID as the request ID, got %q", result.Response.RequestID)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected the 2 results of page 2, got %d", len(normalized.OrganicResults))
+	}
+	if first := normalized.OrganicResults[0]; first.Domain != "en.wikipedia.org" || first.Date != "Jan 2, 2026" || first.Position != 1 {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || normalized.PeopleAlsoAsk[0].Source != "go.dev" {
+		t.Errorf("Unexpected people also ask: %+v", normalized.PeopleAlsoAsk)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.SearchMetadata.TotalResults != 2540000 {
+		t.Errorf("Unexpected related searches %+v or metadata %+v", normalized.RelatedSearches, normalized.SearchMetadata)
+	}
+}
+
+func TestSearchTimeout(t *testing.T) {
+	engine, api := newTestServer(t, -1)
+	engine.SetTimeout(1200 * time.Millisecond)
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if !api.aborted {
+		t.Error("Expected the run to be aborted")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t, 1)
+	engine.apiToken = "wrong-token"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || !strings.Contains(apiErr.Body, "token-not-valid") {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+// Package baidu implements the omniserp.Engine interface for Baidu through
+// the Baidu engines of SerpAPI. Results are normalized by the engine: Baidu
+// links are redirects, so domains come from the displayed links, and Chinese
+// dates are converted to ISO dates.
+package baidu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/serpapi"
+)
+
+const (
+	engineName    = "baidu"
+	engineVersion = "1.0.0"
+)
+
+// Engine implements the omniserp.Engine interface for Baidu. Results are
+// returned as the Data of each search result as a
+// *omniserp.NormalizedSearchResult.
+type Engine struct {
+	serp *serpapi.Engine
+}
+
+// New creates a new Baidu engine from the SERPAPI_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("SERPAPI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("SERPAPI_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Baidu engine with the provided SerpAPI key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	serp, err := serpapi.NewFamilyWithAPIKey(apiKey, serpapi.FamilyBaidu)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{serp: serp}, nil
+}
+
+// SetBaseURL overrides the SerpAPI base URL, e.g. to route requests through
+// a CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.serp.SetBaseURL(u)
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// gateways that require signed requests. A nil signer stops signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	e.serp.SetRequestSigner(signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	e.serp.SetTransport(rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return e.serp.GetSupportedTools()
+}
+
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	return e.serp.SupportedParams(operation)
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return e.serp.ExtraParams()
+}
+
+// response is the JSON response of the Baidu and Baidu News engines of
+// SerpAPI
+type response struct {
+	SearchInformation struct {
+		TotalResults int64 `json:"total_results"`
+	} `json:"search_information"`
+	OrganicResults []struct {
+		Title         string `json:"title"`
+		Link          string `json:"link"`
+		Snippet       string `json:"snippet"`
+		DisplayedLink string `json:"displayed_link"`
+		Source        string `json:"source"`
+		Date          string `json:"date"`
+		Thumbnail     string `json:"thumbnail"`
+	} `json:"organic_results"`
+	RelatedSearches []struct {
+		Query string `json:"query"`
+		Link  string `json:"link"`
+	} `json:"related_searches"`
+}
+
+// chineseDate matches a date such as 2026年1月15日, optionally followed by a
+// dash separating it from the rest of a snippet
+var chineseDate = regexp.MustCompile(`^\s*(\d{4})年(\d{1,2})月(\d{1,2})日\s*[—-]?\s*`)
+
+// parseDate converts a Chinese date to an ISO date; other dates, such as
+// relative ones, are returned unchanged
+func parseDate(s string) string {
+	if date, rest := splitSnippetDate(s); date != "" && strings.TrimSpace(rest) == "" {
+		return date
+	}
+	return s
+}
+
+// splitSnippetDate removes a leading Chinese date from a snippet and returns
+// it as an ISO date
+func splitSnippetDate(snippet string) (date, rest string) {
+	m := chineseDate.FindStringSubmatch(snippet)
+	if m == nil {
+		return "", snippet
+	}
+	return fmt.Sprintf("%s-%02s-%02s", m[1], m[2], m[3]), snippet[len(m[0]):]
+}
+
+// domain returns the domain of a result. Links are Baidu redirects, so the
+// displayed link is used when it names a host.
+func domain(link, displayed string) string {
+	host, _, _ := strings.Cut(displayed, "/")
+	if strings.Contains(host, ".") && !strings.Contains(host, " ") {
+		return strings.TrimPrefix(host, "www.")
+	}
+	if u, err := url.Parse(link); err == nil {
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	return ""
+}
+
+// parse decodes the raw response of a SerpAPI result
+func parse(result *omniserp.SearchResult) (*response, error) {
+	var resp response
+	if err := json.Unmarshal([]byte(result.Raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &resp, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, resp *response) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			TotalResults: resp.SearchInformation.TotalResults,
+		},
+	}
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, err := e.serp.Search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parse(result)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.OrganicResults {
+		date, snippet := splitSnippetDate(r.Snippet)
+		if r.Date != "" {
+			date = parseDate(r.Date)
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: i + 1,
+			Title:    r.Title,
+			Link:     r.Link,
+			URL:      r.Link,
+			Snippet:  snippet,
+			Domain:   domain(r.Link, r.DisplayedLink),
+			Date:     date,
+		})
+	}
+	for _, r := range resp.RelatedSearches {
+		normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: r.Query, Link: r.Link})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: result.Raw, Response: result.Response}, nil
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, err := e.serp.SearchNews(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parse(result)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.OrganicResults {
+		source := r.Source
+		if source == "" {
+			source = domain(r.Link, r.DisplayedLink)
+		}
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position:  i + 1,
+			Title:     r.Title,
+			Link:      r.Link,
+			Source:    source,
+			Date:      parseDate(r.Date),
+			Snippet:   r.Snippet,
+			Thumbnail: r.Thumbnail,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: result.Raw, Response: result.Response}, nil
+}
+
+// SearchImages performs an image search (not supported by Baidu)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Baidu")
+}
+
+// SearchVideos performs a video search (not supported by Baidu)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Baidu")
+}
+
+// SearchPlaces performs a places search (not supported by Baidu)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Baidu")
+}
+
+// SearchMaps performs a maps search (not supported by Baidu)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Baidu")
+}
+
+// SearchReviews performs a reviews search (not supported by Baidu)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Baidu")
+}
+
+// SearchShopping performs a shopping search (not supported by Baidu)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Baidu")
+}
+
+// SearchScholar performs a scholar search (not supported by Baidu)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Baidu")
+}
+
+// SearchLens performs a visual search (not supported by Baidu)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Baidu")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Baidu)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Baidu")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Baidu)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Baidu")
+}
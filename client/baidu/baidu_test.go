@@ -0,0 +1,131 @@
+package baidu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each SerpAPI Baidu engine and records
+// the query of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, news := fixture("search.json"), fixture("news.json")
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		switch query.Get("engine") {
+		case "baidu":
+			_, _ = w.Write(search)
+		case "baidu_news":
+			_, _ = w.Write(news)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Unsupported engine"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL)
+	return engine, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", NumResults: 20, Page: 2}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if query.Get("q") != "golang" || query.Get("rn") != "20" || query.Get("pn") != "20" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	first := normalized.OrganicResults[0]
+	if first.Domain != "go.dev" || first.Date != "2026-01-15" || first.Engine != engineName {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if want := "Go 是一个开源的编程语言,它能让构造简单、可靠且高效的软件变得容易。"; first.Snippet != want {
+		t.Errorf("Expected the snippet without its date, got %q", first.Snippet)
+	}
+	if second := normalized.OrganicResults[1]; second.Domain != "baidu.com" || second.Date != "" {
+		t.Errorf("Expected the redirect domain without a display host, got %+v", second)
+	}
+	if normalized.SearchMetadata.TotalResults != 100000000 || len(normalized.RelatedSearches) != 1 {
+		t.Errorf("Unexpected metadata or related searches: %+v", normalized)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 2 {
+		t.Fatalf("Expected 2 news results, got %d", len(normalized.NewsResults))
+	}
+	if first := normalized.NewsResults[0]; first.Source != "开源中国" || first.Date != "2026-02-10" {
+		t.Errorf("Unexpected first news result: %+v", first)
+	}
+	if second := normalized.NewsResults[1]; second.Source != "news.example.cn" || second.Date != "3小时前" {
+		t.Errorf("Expected the link domain and the relative date, got %+v", second)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	if _, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "golang"}); err == nil {
+		t.Error("Expected image search to be unsupported")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	defer srv.Close()
+	engine.SetBaseURL(srv.URL)
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
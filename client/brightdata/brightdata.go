@@ -0,0 +1,565 @@
+// Package brightdata implements the omniserp.Engine interface for the
+// Bright Data SERP API. Searches are Google result pages fetched through a
+// SERP API zone of the account with Bright Data's parsed JSON output, and
+// their results are normalized by the engine.
+package brightdata
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.brightdata.com"
+	engineName    = "brightdata"
+	engineVersion = "1.0.0"
+	requestPath   = "/request"
+
+	// googleURL is the page the zone fetches; brd_json=1 asks for parsed
+	// JSON instead of HTML
+	googleURL = "https://www.google.com/search"
+
+	// defaultNum is the number of results per page when none is set, and
+	// maxNum the largest number Google returns per page
+	defaultNum = 10
+	maxNum     = 100
+)
+
+// Google search types, by the tbm parameter
+const (
+	tbmNews     = "nws"
+	tbmImages   = "isch"
+	tbmShopping = "shop"
+	tbmPlaces   = "lcl"
+)
+
+// Engine implements the omniserp.Engine interface for Bright Data. Results
+// are normalized by the engine and returned as the Data of each search
+// result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiToken string
+	zone     string
+	baseURL  string
+	client   *http.Client
+}
+
+// New creates a new Bright Data engine from the BRIGHTDATA_API_TOKEN and
+// BRIGHTDATA_SERP_ZONE env vars
+func New() (*Engine, error) {
+	apiToken := os.Getenv("BRIGHTDATA_API_TOKEN")
+	if apiToken == "" {
+		return nil, fmt.Errorf("BRIGHTDATA_API_TOKEN environment variable is required")
+	}
+	zone := os.Getenv("BRIGHTDATA_SERP_ZONE")
+	if zone == "" {
+		return nil, fmt.Errorf("BRIGHTDATA_SERP_ZONE environment variable is required")
+	}
+	return NewWithCredentials(apiToken, zone)
+}
+
+// NewWithCredentials creates a new Bright Data engine with the provided API
+// token and the name of a SERP API zone of the account, such as "serp_api1"
+func NewWithCredentials(apiToken, zone string) (*Engine, error) {
+	if apiToken == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+	if zone == "" {
+		return nil, fmt.Errorf("SERP API zone is required")
+	}
+
+	return &Engine{
+		apiToken: apiToken,
+		zone:     zone,
+		baseURL:  baseURL,
+		client:   &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// Zone returns the SERP API zone searches are made through
+func (e *Engine) Zone() string {
+	return e.zone
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"google_search_images",
+		"google_search_places",
+		"google_search_shopping",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	return omniserp.AllParams()
+}
+
+// Extra declares the Bright Data-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Mobile  string `extra:"brd_mobile" description:"Device to emulate: 1 for a mobile, or ios, ipad, android, or android_tablet"`
+	Browser string `extra:"brd_browser" description:"Browser to emulate: chrome, safari, or firefox"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// buildURL returns the Google URL of a search type, which is empty for web
+// searches. The location is sent as Google's uule parameter, which Bright
+// Data encodes from a canonical location name, and a Google domain selects
+// the host. Pages are offsets in results.
+func (e *Engine) buildURL(params omniserp.SearchParams, tbm string) string {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	q.Set("brd_json", "1")
+	if tbm != "" {
+		q.Set("tbm", tbm)
+	}
+
+	if params.Location != "" {
+		q.Set("uule", params.Location)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	num := defaultNum
+	if params.NumResults > 0 {
+		num = min(params.NumResults, maxNum)
+		q.Set("num", strconv.Itoa(num))
+	}
+	if params.Page > 1 {
+		q.Set("start", strconv.Itoa((params.Page-1)*num))
+	}
+	if tbs := params.Freshness.TBS(); tbs != "" {
+		q.Set("tbs", tbs)
+	}
+	if params.SafeSearch {
+		q.Set("safe", "active")
+	}
+	if params.Verbatim {
+		q.Set("nfpr", "1")
+	}
+	for name, value := range omniserp.ExtraQuery(params, e.ExtraParams()) {
+		q.Set(name, value)
+	}
+	target := googleURL
+	if domain := strings.TrimPrefix(strings.ToLower(params.GoogleDomain), "www."); strings.HasPrefix(domain, "google.") {
+		// Only Google domains are fetched; others keep google.com
+		target = "https://www." + domain + "/search"
+	}
+	return target + "?" + q.Encode()
+}
+
+// request is the body of a Direct API request
+type request struct {
+	Zone   string `json:"zone"`
+	URL    string `json:"url"`
+	Format string `json:"format"`
+
+	// Country is the country of the proxy the page is fetched through
+	Country string `json:"country,omitempty"`
+}
+
+// response is Bright Data's parsed JSON of a Google result page; the fields
+// in use depend on the search type
+type response struct {
+	General struct {
+		ResultsCount int64 `json:"results_cnt"`
+	} `json:"general"`
+
+	Organic       []organic       `json:"organic"`
+	Knowledge     *knowledge      `json:"knowledge"`
+	PeopleAlsoAsk []peopleAlsoAsk `json:"people_also_ask"`
+	Related       []struct {
+		Text string `json:"text"`
+		Link string `json:"link"`
+	} `json:"related"`
+
+	News      []news    `json:"news"`
+	Images    []image   `json:"images"`
+	Shopping  []product `json:"shopping"`
+	SnackPack []place   `json:"snack_pack"`
+}
+
+type organic struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Description string `json:"description"`
+	Date        string `json:"date"`
+}
+
+type knowledge struct {
+	Name              string `json:"name"`
+	Subtitle          string `json:"subtitle"`
+	Description       string `json:"description"`
+	DescriptionSource string `json:"description_source"`
+	Image             string `json:"image"`
+}
+
+type peopleAlsoAsk struct {
+	Question     string `json:"question"`
+	AnswerSource string `json:"answer_source"`
+	AnswerLink   string `json:"answer_link"`
+	Answers      []struct {
+		Value struct {
+			Text string `json:"text"`
+		} `json:"value"`
+	} `json:"answers"`
+}
+
+type news struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Source      string `json:"source"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+type image struct {
+	Title          string `json:"title"`
+	Link           string `json:"link"`
+	Source         string `json:"source"`
+	Image          string `json:"image"`
+	OriginalImage  string `json:"original_image"`
+	OriginalWidth  int    `json:"original_width"`
+	OriginalHeight int    `json:"original_height"`
+}
+
+type product struct {
+	Title         string  `json:"title"`
+	Link          string  `json:"link"`
+	ProductID     string  `json:"product_id"`
+	Price         string  `json:"price"`
+	OriginalPrice string  `json:"original_price"`
+	Currency      string  `json:"currency"`
+	Shop          string  `json:"shop"`
+	Rating        float64 `json:"rating"`
+	ReviewsCount  int     `json:"reviews_cnt"`
+	Delivery      string  `json:"delivery"`
+	Image         string  `json:"image"`
+}
+
+type place struct {
+	Name         string  `json:"name"`
+	CID          string  `json:"cid"`
+	Address      string  `json:"address"`
+	Phone        string  `json:"phone"`
+	Site         string  `json:"site"`
+	Rating       float64 `json:"rating"`
+	ReviewsCount int     `json:"reviews_cnt"`
+	Type         string  `json:"type"`
+	WorkStatus   string  `json:"work_status"`
+	Price        string  `json:"price"`
+	Image        string  `json:"image"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+}
+
+// search fetches the Google result page of a search type through the zone.
+// Bright Data reports failed requests in the x-brd-error header.
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams, tbm string) (*response, string, *omniserp.ResponseMeta, error) {
+	body, err := json.Marshal(request{
+		Zone:    e.zone,
+		URL:     e.buildURL(params, tbm),
+		Format:  "raw",
+		Country: strings.ToLower(params.Country),
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+requestPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to the Bright Data API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		message := cmp.Or(strings.TrimSpace(string(respBody)), resp.Header.Get("x-brd-error"))
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: message, Response: meta}
+	}
+
+	var result response
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, string(respBody), meta, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, result *response) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Location:     params.Location,
+			Language:     params.Language,
+			Country:      params.Country,
+			TotalResults: result.General.ResultsCount,
+		},
+	}
+}
+
+// domain returns the host of a URL without www.
+func domain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, "")
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, o := range result.Organic {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: len(normalized.OrganicResults) + 1,
+			Title:    o.Title,
+			Link:     o.Link,
+			URL:      o.Link,
+			Snippet:  o.Description,
+			Domain:   domain(o.Link),
+			Date:     o.Date,
+		})
+	}
+	if kg := result.Knowledge; kg != nil {
+		normalized.KnowledgeGraph = &omniserp.KnowledgeGraph{
+			Title:       kg.Name,
+			Type:        kg.Subtitle,
+			Description: kg.Description,
+			Source:      kg.DescriptionSource,
+			ImageURL:    kg.Image,
+		}
+	}
+	for _, q := range result.PeopleAlsoAsk {
+		var answer []string
+		for _, a := range q.Answers {
+			if a.Value.Text != "" {
+				answer = append(answer, a.Value.Text)
+			}
+		}
+		normalized.PeopleAlsoAsk = append(normalized.PeopleAlsoAsk, omniserp.PeopleAlsoAsk{
+			Question: q.Question,
+			Answer:   strings.Join(answer, "\n"),
+			Link:     q.AnswerLink,
+			Source:   cmp.Or(q.AnswerSource, domain(q.AnswerLink)),
+		})
+	}
+	for _, r := range result.Related {
+		normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: r.Text, Link: r.Link})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmNews)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, n := range result.News {
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position:  len(normalized.NewsResults) + 1,
+			Title:     n.Title,
+			Link:      n.Link,
+			Source:    cmp.Or(n.Source, domain(n.Link)),
+			Date:      n.Date,
+			Snippet:   n.Description,
+			Thumbnail: n.Image,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmImages)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, img := range result.Images {
+		normalized.ImageResults = append(normalized.ImageResults, omniserp.ImageResult{
+			Position:  len(normalized.ImageResults) + 1,
+			Title:     img.Title,
+			ImageURL:  cmp.Or(img.OriginalImage, img.Image),
+			Thumbnail: img.Image,
+			Source:    cmp.Or(img.Source, domain(img.Link)),
+			SourceURL: img.Link,
+			Width:     img.OriginalWidth,
+			Height:    img.OriginalHeight,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchPlaces performs a places search of Google's local results
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmPlaces)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, p := range result.SnackPack {
+		place := omniserp.PlaceResult{
+			Position:  len(normalized.PlaceResults) + 1,
+			Title:     p.Name,
+			DataID:    p.CID,
+			Address:   p.Address,
+			Phone:     p.Phone,
+			Website:   p.Site,
+			Rating:    p.Rating,
+			Reviews:   p.ReviewsCount,
+			Type:      p.Type,
+			Hours:     p.WorkStatus,
+			Price:     p.Price,
+			Thumbnail: p.Image,
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+		}
+		place.OpeningHours, _ = omniserp.ParseHours(place.Hours)
+		normalized.PlaceResults = append(normalized.PlaceResults, place)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchShopping performs a shopping search
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmShopping)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, p := range result.Shopping {
+		normalized.ShoppingResults = append(normalized.ShoppingResults, omniserp.ShoppingResult{
+			Position:      len(normalized.ShoppingResults) + 1,
+			Title:         p.Title,
+			Link:          p.Link,
+			ProductID:     p.ProductID,
+			Price:         p.Price,
+			OriginalPrice: p.OriginalPrice,
+			Currency:      p.Currency,
+			Rating:        p.Rating,
+			Reviews:       p.ReviewsCount,
+			Source:        p.Shop,
+			Delivery:      p.Delivery,
+			Thumbnail:     p.Image,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchVideos performs a video search (not supported by Bright Data)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Bright Data")
+}
+
+// SearchMaps performs a maps search (not supported by Bright Data)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Bright Data")
+}
+
+// SearchReviews performs a reviews search (not supported by Bright Data)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Bright Data")
+}
+
+// SearchScholar performs a scholar search (not supported by Bright Data)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Bright Data")
+}
+
+// SearchLens performs a visual search (not supported by Bright Data)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Bright Data")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Bright Data)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Bright Data")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Bright Data)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Bright Data")
+}
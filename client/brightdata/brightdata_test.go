@@ -0,0 +1,240 @@
+package brightdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each search type and records the body
+// of the last request
+func newTestServer(t *testing.T) (*Engine, *request) {
+	t.Helper()
+	fixtures := map[string][]byte{}
+	for tbm, name := range map[string]string{"": "search.json", tbmNews: "news.json", tbmImages: "images.json", tbmShopping: "shopping.json", tbmPlaces: "places.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[tbm] = data
+	}
+
+	var last request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != requestPath || r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("x-brd-error", "Auth failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&last); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if last.Zone != "serp_test" {
+			w.Header().Set("x-brd-error", "Zone not found")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		target, err := url.Parse(last.URL)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(fixtures[target.Query().Get("tbm")])
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithCredentials("test-token", "serp_test")
+	if err != nil {
+		t.Fatalf("NewWithCredentials failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &last
+}
+
+// normalizedOf returns the normalized result of a search
+func normalizedOf(t *testing.T, result *omniserp.SearchResult) *omniserp.NormalizedSearchResult {
+	t.Helper()
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	return normalized
+}
+
+func TestNewWithCredentials(t *testing.T) {
+	if _, err := NewWithCredentials("test-token", ""); err == nil {
+		t.Error("Expected an error without a zone")
+	}
+	if _, err := NewWithCredentials("", "serp_test"); err == nil {
+		t.Error("Expected an error without a token")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine, last := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Location:   "Austin,Texas,United States",
+		Country:    "US",
+		Language:   "en",
+		NumResults: 20,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Extra:      map[string]any{"brd_mobile": "ios"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if last.Format != "raw" || last.Country != "us" {
+		t.Errorf("Unexpected request: %+v", last)
+	}
+	target, _ := url.Parse(last.URL)
+	if target.Host != "www.google.com" || target.Path != "/search" {
+		t.Errorf("Unexpected target URL %s", last.URL)
+	}
+	want := map[string]string{
+		"q":          "golang",
+		"brd_json":   "1",
+		"uule":       "Austin,Texas,United States",
+		"gl":         "us",
+		"hl":         "en",
+		"num":        "20",
+		"start":      "20",
+		"tbs":        "qdr:w",
+		"safe":       "active",
+		"brd_mobile": "ios",
+		"tbm":        "",
+	}
+	for name, value := range want {
+		if got := target.Query().Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	if second := normalized.OrganicResults[1]; second.Position != 2 || second.Domain != "github.com" || second.Date != "Jan 2, 2026" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || normalized.PeopleAlsoAsk[0].Answer != "Go is used for cloud services.\nAnd command-line tools." {
+		t.Errorf("Unexpected people also ask: %+v", normalized.PeopleAlsoAsk)
+	}
+	if kg := normalized.KnowledgeGraph; kg == nil || kg.Source != "Wikipedia" || kg.Type != "Programming language" {
+		t.Errorf("Unexpected knowledge graph: %+v", kg)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[1].Query != "golang vs rust" {
+		t.Errorf("Unexpected related searches: %+v", normalized.RelatedSearches)
+	}
+	if normalized.SearchMetadata.TotalResults != 2540000 {
+		t.Errorf("Unexpected metadata: %+v", normalized.SearchMetadata)
+	}
+}
+
+func TestSearchGoogleDomain(t *testing.T) {
+	engine, last := newTestServer(t)
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", Locale: "de-DE"}.WithLocale()); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	target, _ := url.Parse(last.URL)
+	if target.Host != "www.google.de" || target.Query().Get("gl") != "de" || target.Query().Get("hl") != "de" {
+		t.Errorf("Expected a search of google.de, got %s", last.URL)
+	}
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", GoogleDomain: "example.com"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if target, _ := url.Parse(last.URL); target.Host != "www.google.com" {
+		t.Errorf("Expected other domains to search google.com, got %s", last.URL)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	news := normalizedOf(t, result).NewsResults
+	if len(news) != 1 || news[0].Source != "The Go Blog" || news[0].Thumbnail == "" {
+		t.Errorf("Unexpected news: %+v", news)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher"})
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+	images := normalizedOf(t, result).ImageResults
+	if len(images) != 1 {
+		t.Fatalf("Expected 1 image, got %d", len(images))
+	}
+	if img := images[0]; img.ImageURL != "https://go.dev/blog/gopher/header.jpg" || img.SourceURL != "https://go.dev/blog/gopher" || img.Width != 1200 {
+		t.Errorf("Unexpected image: %+v", img)
+	}
+}
+
+func TestSearchShopping(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchShopping(context.Background(), omniserp.SearchParams{Query: "gopher plush"})
+	if err != nil {
+		t.Fatalf("SearchShopping failed: %v", err)
+	}
+	products := normalizedOf(t, result).ShoppingResults
+	if len(products) != 1 {
+		t.Fatalf("Expected 1 product, got %d", len(products))
+	}
+	if p := products[0]; p.Source != "Gopher Shop" || p.Reviews != 152 || p.Currency != "USD" || p.OriginalPrice != "$24.99" {
+		t.Errorf("Unexpected product: %+v", p)
+	}
+}
+
+func TestSearchPlaces(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchPlaces(context.Background(), omniserp.SearchParams{Query: "coffee"})
+	if err != nil {
+		t.Fatalf("SearchPlaces failed: %v", err)
+	}
+	places := normalizedOf(t, result).PlaceResults
+	if len(places) != 1 {
+		t.Fatalf("Expected 1 place, got %d", len(places))
+	}
+	if p := places[0]; p.DataID != "1234567890" || p.Reviews != 812 || p.Latitude != 30.2637 || p.Website != "https://gophercoffee.example" {
+		t.Errorf("Unexpected place: %+v", p)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.zone = "missing"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Body != "Zone not found" {
+		t.Errorf("Expected a 400 APIError with the Bright Data error, got %v", err)
+	}
+}
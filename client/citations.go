@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Defaults of CitationOptions
+const (
+	defaultCitationDepth     = 1
+	defaultCitationsPerPaper = 10
+	defaultCitationMaxPapers = 50
+)
+
+// CitationOptions bounds CitationGraph
+type CitationOptions struct {
+	// Depth is the number of citation hops followed from the seed paper
+	// (default 1)
+	Depth int
+
+	// PerPaper is the number of citing papers fetched per paper (default 10)
+	PerPaper int
+
+	// MaxPapers is the total number of papers in the graph, including the
+	// seed paper (default 50)
+	MaxPapers int
+
+	// References returns the papers cited by a paper. Google Scholar does
+	// not list references, so they are only followed with another source,
+	// such as a bibliographic database; without one only citing papers are
+	// followed.
+	References func(ctx context.Context, paper omniserp.ScholarResult) ([]omniserp.ScholarResult, error)
+}
+
+// CitationPaper is a paper in a citation graph
+type CitationPaper struct {
+	omniserp.ScholarResult
+
+	// ID identifies the paper in the edges of the graph
+	ID string `json:"id"`
+
+	// Depth is the number of citation hops from the seed paper
+	Depth int `json:"depth"`
+
+	// Error is set if fetching the citing papers or references failed
+	Error string `json:"error,omitempty"`
+}
+
+// CitationEdge is a citation of the paper To by the paper From
+type CitationEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// CitationGraph holds the papers around a seed paper and the citations
+// between them
+type CitationGraph struct {
+	Query  string           `json:"query"`
+	Seed   string           `json:"seed"`
+	Papers []*CitationPaper `json:"papers"`
+	Edges  []CitationEdge   `json:"edges"`
+
+	// Engine is the engine that searched for citing papers, or empty if no
+	// engine honors omniserp.ParamCites
+	Engine string `json:"engine,omitempty"`
+
+	// Searches is the number of searches made, including the seed search
+	Searches int `json:"searches"`
+
+	keys  map[string]*CitationPaper
+	edges map[CitationEdge]bool
+}
+
+// CitationGraph searches scholar for params and takes the top result as the
+// seed paper, then follows the papers citing it, and their references if
+// opts.References is set, level by level up to the bounds of opts. The
+// searches run on the current engine if it honors omniserp.ParamCites, or
+// else on the first engine by name that does. Papers are deduplicated by
+// cites ID, canonical link, and title. Only a failure of the seed search is
+// returned as an error; failed follow-up searches are recorded on their
+// papers.
+func (c *Client) CitationGraph(ctx context.Context, params omniserp.SearchParams, opts *CitationOptions) (*CitationGraph, error) {
+	if opts == nil {
+		opts = &CitationOptions{}
+	}
+	maxDepth, perPaper, maxPapers := opts.Depth, opts.PerPaper, opts.MaxPapers
+	if maxDepth <= 0 {
+		maxDepth = defaultCitationDepth
+	}
+	if perPaper <= 0 {
+		perPaper = defaultCitationsPerPaper
+	}
+	if maxPapers <= 0 {
+		maxPapers = defaultCitationMaxPapers
+	}
+
+	engine := c.citationEngine()
+	if engine == "" && opts.References == nil {
+		return nil, fmt.Errorf("%w: no engine honors %s for %s", ErrOperationNotSupported, omniserp.ParamCites, OpSearchScholar)
+	}
+
+	// The seed is searched on the same engine for its cites ID
+	var result *omniserp.NormalizedSearchResult
+	var err error
+	if engine != "" {
+		result, err = c.runNormalized(ctx, OpSearchScholar, engine, params)
+	} else {
+		result, err = c.SearchScholarNormalized(ctx, params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %q: %w", params.Query, err)
+	}
+	if len(result.ScholarResults) == 0 {
+		return nil, fmt.Errorf("no scholar results for %q", params.Query)
+	}
+	graph := &CitationGraph{
+		Query:    params.Query,
+		Engine:   engine,
+		Searches: 1,
+		keys:     make(map[string]*CitationPaper),
+		edges:    make(map[CitationEdge]bool),
+	}
+	seed, _ := graph.add(result.ScholarResults[0], 0, maxPapers)
+	if seed == nil {
+		return nil, fmt.Errorf("no identifiable scholar result for %q", params.Query)
+	}
+	graph.Seed = seed.ID
+
+	level := []*CitationPaper{seed}
+	for depth := 1; depth <= maxDepth && len(level) > 0; depth++ {
+		if engine != "" {
+			for _, paper := range level {
+				if paper.CitesID != "" {
+					graph.Searches++
+				}
+			}
+		}
+
+		citing := make([][]omniserp.ScholarResult, len(level))
+		references := make([][]omniserp.ScholarResult, len(level))
+		runMatrix(ctx, len(level), func(ctx context.Context, i int) error {
+			paper := level[i]
+			var errs []error
+			if engine != "" && paper.CitesID != "" {
+				p := omniserp.SearchParams{Cites: paper.CitesID, Language: params.Language, NumResults: perPaper}
+				result, err := c.runNormalized(ctx, OpSearchScholar, engine, p)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to search citing papers: %w", err))
+				} else {
+					citing[i] = result.ScholarResults
+				}
+			}
+			if opts.References != nil {
+				refs, err := opts.References(ctx, paper.ScholarResult)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to get references: %w", err))
+				} else {
+					references[i] = refs
+				}
+			}
+			if err := errors.Join(errs...); err != nil {
+				paper.Error = err.Error()
+				return err
+			}
+			return nil
+		})
+
+		// Papers are added in level order so duplicates resolve
+		// deterministically
+		var next []*CitationPaper
+		for i, paper := range level {
+			for _, r := range citing[i] {
+				if node, added := graph.add(r, depth, maxPapers); node != nil {
+					graph.link(node, paper)
+					if added {
+						next = append(next, node)
+					}
+				}
+			}
+			for _, r := range references[i] {
+				if node, added := graph.add(r, depth, maxPapers); node != nil {
+					graph.link(paper, node)
+					if added {
+						next = append(next, node)
+					}
+				}
+			}
+		}
+		level = next
+	}
+	return graph, nil
+}
+
+// add returns the paper of the graph matching r, adding it at depth unless
+// the graph already has maxPapers papers. It returns nil for papers that are
+// not in the graph and whether the paper was added.
+func (g *CitationGraph) add(r omniserp.ScholarResult, depth, maxPapers int) (*CitationPaper, bool) {
+	keys := paperKeys(r)
+	if len(keys) == 0 {
+		return nil, false
+	}
+	for _, key := range keys {
+		if paper, ok := g.keys[key]; ok {
+			// Remember the other keys of the paper for later duplicates
+			for _, other := range keys {
+				if _, ok := g.keys[other]; !ok {
+					g.keys[other] = paper
+				}
+			}
+			if paper.CitesID == "" {
+				paper.CitesID = r.CitesID
+			}
+			return paper, false
+		}
+	}
+	if len(g.Papers) >= maxPapers {
+		return nil, false
+	}
+	paper := &CitationPaper{ScholarResult: r, ID: keys[0], Depth: depth}
+	for _, key := range keys {
+		g.keys[key] = paper
+	}
+	g.Papers = append(g.Papers, paper)
+	return paper, true
+}
+
+// link adds an edge for the citation of cited by citing, once
+func (g *CitationGraph) link(citing, cited *CitationPaper) {
+	edge := CitationEdge{From: citing.ID, To: cited.ID}
+	if citing == cited || g.edges[edge] {
+		return
+	}
+	g.edges[edge] = true
+	g.Edges = append(g.Edges, edge)
+}
+
+// paperKeys returns the keys identifying a paper, most specific first
+func paperKeys(r omniserp.ScholarResult) []string {
+	var keys []string
+	if r.CitesID != "" {
+		keys = append(keys, "cites:"+r.CitesID)
+	}
+	if r.Link != "" {
+		keys = append(keys, "url:"+omniserp.CanonicalURL(r.Link))
+	}
+	if title := omniserp.CanonicalQuery(r.Title); title != "" {
+		keys = append(keys, "title:"+title)
+	}
+	return keys
+}
+
+// citationEngine returns the name of the engine to search for citing papers
+// on: the current engine if it honors omniserp.ParamCites for scholar
+// searches, or else the first such engine by name. In deterministic mode only
+// the pinned engine is considered. It returns an empty name if no engine
+// honors it.
+func (c *Client) citationEngine() string {
+	honors := func(engine omniserp.Engine) bool {
+		reporter, ok := engine.(omniserp.ParamReporter)
+		return ok && slices.Contains(engine.GetSupportedTools(), OpSearchScholar) &&
+			slices.Contains(reporter.SupportedParams(OpSearchScholar), omniserp.ParamCites)
+	}
+	if d := c.deterministic(); d != nil {
+		if engine, ok := c.registry.Get(d.Engine); ok && honors(engine) {
+			return d.Engine
+		}
+		return ""
+	}
+	if current := c.GetCurrentEngine(); honors(current) {
+		return current.GetName()
+	}
+	names := c.registry.List()
+	slices.Sort(names)
+	for _, name := range names {
+		if engine, _ := c.registry.Get(name); honors(engine) {
+			return name
+		}
+	}
+	return ""
+}
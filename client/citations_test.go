@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// citingEngine is a serpapi-named fake engine that honors omniserp.ParamCites
+type citingEngine struct {
+	*fakeEngine
+}
+
+func (e *citingEngine) SupportedParams(operation string) []string {
+	return []string{omniserp.ParamQuery, omniserp.ParamCites}
+}
+
+// scholarResponse builds a serpapi-shaped scholar response with papers given
+// as title and cites ID pairs; papers without a cites ID have no citations
+func scholarResponse(papers ...[2]string) *omniserp.SearchResult {
+	organic := make([]any, 0, len(papers))
+	for _, paper := range papers {
+		item := map[string]any{"title": paper[0], "link": "https://papers.example/" + paper[0]}
+		if paper[1] != "" {
+			item["inline_links"] = map[string]any{"cited_by": map[string]any{"total": 1.0, "cites_id": paper[1]}}
+		}
+		organic = append(organic, item)
+	}
+	return &omniserp.SearchResult{Data: map[string]any{"organic_results": organic}}
+}
+
+func TestCitationGraph(t *testing.T) {
+	citedBy := map[string]*omniserp.SearchResult{
+		"a": scholarResponse([2]string{"b", "b"}, [2]string{"c", ""}),
+		"b": scholarResponse([2]string{"d", "d"}, [2]string{"c", ""}),
+	}
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		t.Errorf("Unexpected search on serper: %+v", p)
+		return organicResponse(), nil
+	}})
+	registry.Register(&citingEngine{&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if p.Cites == "" {
+			return scholarResponse([2]string{"a", "a"}), nil
+		}
+		if result, ok := citedBy[p.Cites]; ok {
+			return result, nil
+		}
+		return nil, errors.New("quota exceeded")
+	}}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	references := func(ctx context.Context, paper omniserp.ScholarResult) ([]omniserp.ScholarResult, error) {
+		if paper.Title == "a" {
+			return []omniserp.ScholarResult{{Title: "e", Link: "https://papers.example/e"}}, nil
+		}
+		return nil, nil
+	}
+	graph, err := c.CitationGraph(context.Background(), omniserp.SearchParams{Query: "a"}, &CitationOptions{Depth: 3, References: references})
+	if err != nil {
+		t.Fatalf("CitationGraph failed: %v", err)
+	}
+
+	if graph.Engine != "serpapi" || graph.Seed != "cites:a" || graph.Searches != 4 {
+		t.Errorf("Unexpected graph: engine %s, seed %s, %d searches", graph.Engine, graph.Seed, graph.Searches)
+	}
+	var titles []string
+	for _, paper := range graph.Papers {
+		titles = append(titles, paper.Title)
+	}
+	if want := []string{"a", "b", "c", "e", "d"}; !slices.Equal(titles, want) {
+		t.Errorf("Papers = %v, want %v", titles, want)
+	}
+	if d := graph.Papers[4]; d.Depth != 2 || d.Error == "" {
+		t.Errorf("Expected the failed search for d to be recorded at depth 2, got %+v", d)
+	}
+
+	want := []CitationEdge{
+		{From: "cites:b", To: "cites:a"},
+		{From: "url:papers.example/c", To: "cites:a"},
+		{From: "cites:a", To: "url:papers.example/e"},
+		{From: "cites:d", To: "cites:b"},
+		{From: "url:papers.example/c", To: "cites:b"},
+	}
+	if len(graph.Edges) != len(want) {
+		t.Fatalf("Edges = %+v, want %+v", graph.Edges, want)
+	}
+	for i := range want {
+		if graph.Edges[i] != want[i] {
+			t.Errorf("Edges[%d] = %+v, want %+v", i, graph.Edges[i], want[i])
+		}
+	}
+}
+
+func TestCitationGraphMaxPapers(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&citingEngine{&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if p.Cites == "" {
+			return scholarResponse([2]string{"a", "a"}), nil
+		}
+		return scholarResponse([2]string{"b", "b"}, [2]string{"c", "c"}, [2]string{"d", "d"}), nil
+	}}})
+	c, err := NewWithRegistry(registry, "serpapi")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	graph, err := c.CitationGraph(context.Background(), omniserp.SearchParams{Query: "a"}, &CitationOptions{Depth: 3, MaxPapers: 3})
+	if err != nil {
+		t.Fatalf("CitationGraph failed: %v", err)
+	}
+	if len(graph.Papers) != 3 {
+		t.Errorf("Expected 3 papers, got %d", len(graph.Papers))
+	}
+	for _, edge := range graph.Edges {
+		if edge.From == "cites:d" || edge.To == "cites:d" {
+			t.Errorf("Unexpected edge to a paper beyond the limit: %+v", edge)
+		}
+	}
+}
+
+func TestCitationGraphUnsupported(t *testing.T) {
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse(), nil
+	})
+	if _, err := c.CitationGraph(context.Background(), omniserp.SearchParams{Query: "a"}, nil); !errors.Is(err, ErrOperationNotSupported) {
+		t.Errorf("Expected ErrOperationNotSupported, got %v", err)
+	}
+}
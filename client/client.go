@@ -5,13 +5,42 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/apify"
+	"github.com/plexusone/omniserp/client/baidu"
+	"github.com/plexusone/omniserp/client/brightdata"
+	"github.com/plexusone/omniserp/client/dataforseo"
+	"github.com/plexusone/omniserp/client/duckduckgo"
+	"github.com/plexusone/omniserp/client/elasticsearch"
+	"github.com/plexusone/omniserp/client/exa"
+	"github.com/plexusone/omniserp/client/firecrawl"
+	"github.com/plexusone/omniserp/client/googlecse"
+	"github.com/plexusone/omniserp/client/jina"
+	"github.com/plexusone/omniserp/client/kagi"
+	"github.com/plexusone/omniserp/client/mojeek"
+	"github.com/plexusone/omniserp/client/perplexity"
+	"github.com/plexusone/omniserp/client/scaleserp"
+	"github.com/plexusone/omniserp/client/searchapi"
+	"github.com/plexusone/omniserp/client/searxng"
 	"github.com/plexusone/omniserp/client/serpapi"
 	"github.com/plexusone/omniserp/client/serper"
+	"github.com/plexusone/omniserp/client/tavily"
+	"github.com/plexusone/omniserp/client/valueserp"
+	"github.com/plexusone/omniserp/client/wikipedia"
+	"github.com/plexusone/omniserp/client/youcom"
+	"github.com/plexusone/omniserp/client/zenserp"
+	"github.com/plexusone/omniserp/events"
+	"github.com/plexusone/omniserp/index"
 )
 
-// Operation names that map to Engine interface methods
+// Operation names that map to Engine interface methods. These are the tool
+// names reported by Engine.GetSupportedTools and used by the MCP server; the
+// google_ prefix is historical and they apply to every engine.
 const (
 	OpSearch             = "google_search"
 	OpSearchNews         = "google_search_news"
@@ -27,13 +56,129 @@ const (
 	OpScrapeWebpage      = "webpage_scrape"
 )
 
+// AllOperations returns all operation names in Engine interface order
+func AllOperations() []string {
+	return []string{
+		OpSearch,
+		OpSearchNews,
+		OpSearchImages,
+		OpSearchVideos,
+		OpSearchPlaces,
+		OpSearchMaps,
+		OpSearchReviews,
+		OpSearchShopping,
+		OpSearchScholar,
+		OpSearchLens,
+		OpSearchAutocomplete,
+		OpScrapeWebpage,
+	}
+}
+
+// Engine-neutral operation identifiers. They are accepted wherever an
+// operation name is, and map to the tool names above with ToolName.
+const (
+	OpWeb          = "web"
+	OpNews         = "news"
+	OpImages       = "images"
+	OpVideos       = "videos"
+	OpPlaces       = "places"
+	OpMaps         = "maps"
+	OpReviews      = "reviews"
+	OpShopping     = "shopping"
+	OpScholar      = "scholar"
+	OpLens         = "lens"
+	OpAutocomplete = "autocomplete"
+	OpScrape       = "scrape"
+)
+
+// toolNames maps engine-neutral operation identifiers to tool names
+var toolNames = map[string]string{
+	OpWeb:          OpSearch,
+	OpNews:         OpSearchNews,
+	OpImages:       OpSearchImages,
+	OpVideos:       OpSearchVideos,
+	OpPlaces:       OpSearchPlaces,
+	OpMaps:         OpSearchMaps,
+	OpReviews:      OpSearchReviews,
+	OpShopping:     OpSearchShopping,
+	OpScholar:      OpSearchScholar,
+	OpLens:         OpSearchLens,
+	OpAutocomplete: OpSearchAutocomplete,
+	OpScrape:       OpScrapeWebpage,
+}
+
+// AllOperationIDs returns all engine-neutral operation identifiers in
+// Engine interface order
+func AllOperationIDs() []string {
+	return []string{
+		OpWeb,
+		OpNews,
+		OpImages,
+		OpVideos,
+		OpPlaces,
+		OpMaps,
+		OpReviews,
+		OpShopping,
+		OpScholar,
+		OpLens,
+		OpAutocomplete,
+		OpScrape,
+	}
+}
+
+// ToolName returns the tool name of an operation given by its
+// engine-neutral identifier, such as "google_search_news" for OpNews. Tool
+// names and unknown names are returned unchanged.
+func ToolName(operation string) string {
+	if tool, ok := toolNames[operation]; ok {
+		return tool
+	}
+	return operation
+}
+
+// OperationID returns the engine-neutral identifier of an operation given
+// by its tool name, such as OpNews for "google_search_news". Identifiers
+// and unknown names are returned unchanged.
+func OperationID(operation string) string {
+	for id, tool := range toolNames {
+		if tool == operation {
+			return id
+		}
+	}
+	return operation
+}
+
 // ErrOperationNotSupported is returned when an operation is not supported by the current engine
 var ErrOperationNotSupported = errors.New("operation not supported by current engine")
 
 // Client is a unified SDK that fronts multiple search engine backends
 type Client struct {
-	registry *omniserp.Registry
-	engine   omniserp.Engine
+	registry   *omniserp.Registry
+	stats      *statsRecorder
+	failover   *FailoverPolicy
+	selection  SelectionPolicy
+	optIn      map[string]bool
+	pages      PageStore
+	indexer    index.Indexer
+	publisher  events.Publisher
+	annotators []omniserp.Annotator
+	sanitizer  *omniserp.Sanitizer
+	sources    *omniserp.SourcePolicy
+	saved      *SavedSearches
+	inflight   inflightRequests
+	usage      usageRecorder
+
+	// determinism pins the engine and records or replays responses
+	determinism *Deterministic
+
+	// verbatimRequery repeats spelling-corrected web searches verbatim
+	verbatimRequery bool
+
+	// init records which engines initialized when the client was created
+	init *InitReport
+
+	mu     sync.RWMutex
+	engine omniserp.Engine
 }
 
 // New creates a new client with all available engines auto-registered
@@ -57,6 +202,87 @@ type Options struct {
 
 	// Silent suppresses initialization logs
 	Silent bool
+
+	// Fallback controls whether a missing SEARCH_ENGINE engine falls back
+	// to another engine (default) or fails. It does not apply to EngineName,
+	// which must always exist.
+	Fallback omniserp.FallbackPolicy
+
+	// Failover enables routing away from failing or degraded engines.
+	// If nil, all requests go to the selected engine.
+	Failover *FailoverPolicy
+
+	// Selection picks the engine per request among the engines that support
+	// the operation. If nil, requests go to the selected engine.
+	Selection SelectionPolicy
+
+	// Routes maps operation names to engine names, with RouteDefault ("*")
+	// for all other operations. Routes are validated against the registered
+	// engines and take precedence over Selection, which handles operations
+	// without a route.
+	Routes map[string]string
+
+	// PolicyEngines opts keyless and special-purpose engines (duckduckgo,
+	// wikipedia and elasticsearch) in to selection and failover. Otherwise
+	// they only serve requests as the current engine, a preferred engine or
+	// the engine of a route, so general web searches are not sent to them.
+	PolicyEngines []string
+
+	// NoKeylessEngines leaves out the engines that need no API key,
+	// duckduckgo and wikipedia, so the client fails with
+	// omniserp.ErrNoEngines when no API key is set rather than falling back
+	// to them
+	NoKeylessEngines bool
+
+	// Indexer receives the results of normalized searches and scrapes, such
+	// as a vector store. If nil, results are not indexed.
+	Indexer index.Indexer
+
+	// Publisher receives an event with the metadata and results of every
+	// normalized search, such as a Kafka topic or NATS subject. If nil,
+	// events are not published.
+	Publisher events.Publisher
+
+	// Annotators enrich the organic and news results of normalized
+	// searches, such as with sentiment (see omniserp.Annotate)
+	Annotators []omniserp.Annotator
+
+	// Sanitizer removes secrets and tracking parameters from engine
+	// responses before they are returned. If nil, responses are returned
+	// as received.
+	Sanitizer *omniserp.Sanitizer
+
+	// SourcePolicy filters and reorders the organic and news results of
+	// normalized searches by the reputation of their sources (see
+	// omniserp.ApplySourcePolicy). If nil, the engine's results are kept.
+	SourcePolicy *omniserp.SourcePolicy
+
+	// RequeryVerbatim repeats web searches that the engine spelling-corrected
+	// with the query as written, returning the verbatim results with the
+	// correction as their SuggestedQuery. It costs a second request for
+	// every corrected search.
+	RequeryVerbatim bool
+
+	// ProxyURL sends the requests of every engine through a recording proxy,
+	// such as omniserp-proxy, at <ProxyURL>/<engine name>. If empty, uses
+	// the OMNISERP_PROXY_URL env var.
+	ProxyURL string
+
+	// RequestSigners sign the requests of engines by engine name, for APIs
+	// and API gateways that require signed requests (see
+	// omniserp.HMACSigner). Signers of unregistered engines are ignored.
+	RequestSigners map[string]omniserp.RequestSigner
+
+	// Transports configure the HTTP transports of engines by engine name,
+	// such as client certificates for self-hosted engines behind mTLS
+	// gateways. Configurations of unregistered engines are ignored.
+	Transports map[string]omniserp.TransportConfig
+
+	// ValidateKeys checks the credentials of every registered engine when
+	// the client is created (see Client.ValidateKeys), failing with an
+	// *InvalidKeysError if any are rejected. Engines that cannot report
+	// credits spend a search request on the check.
+	ValidateKeys bool
 }
 
 // NewWithRegistry creates a new client with a pre-configured registry and engine name
@@ -69,9 +295,39 @@ func NewWithRegistry(registry *omniserp.Registry, engineName string) (*Client, e
 	return &Client{
 		registry: registry,
 		engine:   engine,
+		stats:    newStatsRecorder(),
+		pages:    NewMemoryPageStore(),
+		saved:    NewSavedSearches(),
+		init:     registryReport(registry, engineName),
 	}, nil
 }
 
+// EngineExtraParams returns the engine-specific parameters of the built-in
+// engines by engine name, whether or not the engines are configured, so they
+// can be documented without credentials. Engines without any are omitted.
+func EngineExtraParams() map[string][]omniserp.ExtraParam {
+	return map[string][]omniserp.ExtraParam{
+		"serper":  omniserp.DescribeExtraParams(serper.Extra{}),
+		"serpapi": omniserp.DescribeExtraParams(serpapi.Extra{}),
+		"searxng": omniserp.DescribeExtraParams(searxng.Extra{}),
+		"kagi":    omniserp.DescribeExtraParams(kagi.Extra{}),
+		"tavily":  omniserp.DescribeExtraParams(tavily.Extra{}),
+		"exa":     omniserp.DescribeExtraParams(exa.Extra{}),
+
+		"dataforseo":    omniserp.DescribeExtraParams(dataforseo.Extra{}),
+		"valueserp":     omniserp.DescribeExtraParams(valueserp.Extra{}),
+		"scaleserp":     omniserp.DescribeExtraParams(scaleserp.Extra{}),
+		"zenserp":       omniserp.DescribeExtraParams(zenserp.Extra{}),
+		"searchapi":     omniserp.DescribeExtraParams(searchapi.Extra{}),
+		"brightdata":    omniserp.DescribeExtraParams(brightdata.Extra{}),
+		"apify":         omniserp.DescribeExtraParams(apify.Extra{}),
+		"perplexity":    omniserp.DescribeExtraParams(perplexity.Extra{}),
+		"jina":          omniserp.DescribeExtraParams(jina.Extra{}),
+		"firecrawl":     omniserp.DescribeExtraParams(firecrawl.Extra{}),
+		"elasticsearch": omniserp.DescribeExtraParams(elasticsearch.Extra{}),
+	}
+}
+
 // NewWithOptions creates a new client with custom options
 func NewWithOptions(opts *Options) (*Client, error) {
 	if opts == nil {
@@ -80,31 +336,115 @@ func NewWithOptions(opts *Options) (*Client, error) {
 
 	registry := omniserp.NewRegistry()
 
-	// Register all available engines
-	if serperEngine, err := serper.New(); err == nil {
-		registry.Register(serperEngine)
-		if !opts.Silent {
-			log.Printf("Registered Serper engine")
+	// Register all available engines, recording why the others failed
+	report := &InitReport{}
+	registerEngine(registry, report, opts.Silent, "serper", "Serper", serper.New)
+	registerEngine(registry, report, opts.Silent, "serpapi", "SerpAPI", serpapi.New)
+
+	// The Bing and Yandex engines of SerpAPI share its key, so their
+	// failures are reported but not logged again
+	for _, family := range serpapi.Families {
+		name := "serpapi-" + string(family)
+		familyEngine, err := serpapi.NewFamily(family)
+		if err != nil {
+			report.Engines = append(report.Engines, EngineInit{Engine: name, Error: err.Error()})
+			continue
 		}
-	} else {
+		registry.Register(familyEngine)
+		report.Engines = append(report.Engines, EngineInit{Engine: familyEngine.GetName(), Loaded: true})
 		if !opts.Silent {
-			log.Printf("Failed to initialize Serper engine: %v", err)
+			log.Printf("Registered SerpAPI %s engine", family)
 		}
 	}
 
-	if serpApiEngine, err := serpapi.New(); err == nil {
-		registry.Register(serpApiEngine)
+	registerEngine(registry, report, opts.Silent, "searxng", "SearXNG", searxng.New)
+	registerEngine(registry, report, opts.Silent, "googlecse", "Google Custom Search", googlecse.New)
+	registerEngine(registry, report, opts.Silent, "kagi", "Kagi", kagi.New)
+	registerEngine(registry, report, opts.Silent, "tavily", "Tavily", tavily.New)
+	registerEngine(registry, report, opts.Silent, "exa", "Exa", exa.New)
+	registerEngine(registry, report, opts.Silent, "youcom", "You.com", youcom.New)
+	registerEngine(registry, report, opts.Silent, "mojeek", "Mojeek", mojeek.New)
+	registerEngine(registry, report, opts.Silent, "baidu", "Baidu", baidu.New)
+	registerEngine(registry, report, opts.Silent, "dataforseo", "DataForSEO", dataforseo.New)
+	registerEngine(registry, report, opts.Silent, "valueserp", "ValueSerp", valueserp.New)
+	registerEngine(registry, report, opts.Silent, "scaleserp", "ScaleSERP", scaleserp.New)
+	registerEngine(registry, report, opts.Silent, "zenserp", "Zenserp", zenserp.New)
+	registerEngine(registry, report, opts.Silent, "searchapi", "SearchAPI.io", searchapi.New)
+
+	if brightdataEngine, err := brightdata.New(); err == nil {
+		registry.Register(brightdataEngine)
+		report.Engines = append(report.Engines, EngineInit{Engine: brightdataEngine.GetName(), Loaded: true})
 		if !opts.Silent {
-			log.Printf("Registered SerpAPI engine")
+			log.Printf("Registered Bright Data engine (zone %s)", brightdataEngine.Zone())
 		}
 	} else {
+		report.Engines = append(report.Engines, EngineInit{Engine: "brightdata", Error: err.Error()})
+		if !opts.Silent {
+			log.Printf("Failed to initialize Bright Data engine: %v", err)
+		}
+	}
+
+	registerEngine(registry, report, opts.Silent, "apify", "Apify", apify.New)
+	registerEngine(registry, report, opts.Silent, "perplexity", "Perplexity", perplexity.New)
+	registerEngine(registry, report, opts.Silent, "jina", "Jina", jina.New)
+	registerEngine(registry, report, opts.Silent, "firecrawl", "Firecrawl", firecrawl.New)
+	registerEngine(registry, report, opts.Silent, "elasticsearch", "Elasticsearch", elasticsearch.New)
+
+	if !opts.NoKeylessEngines {
+		// Wikipedia needs no API key either and serves encyclopedic searches
+		registerEngine(registry, report, opts.Silent, "wikipedia", "Wikipedia", wikipedia.New)
+
+		// DuckDuckGo needs no API key, so basic searches work without any
+		registerEngine(registry, report, opts.Silent, "duckduckgo", "DuckDuckGo", duckduckgo.New)
+	}
+
+	if err := ConfigureEngineTransports(registry, opts.Transports); err != nil {
+		return nil, err
+	}
+	if err := SignEngineRequests(registry, opts.RequestSigners); err != nil {
+		return nil, err
+	}
+
+	proxyURL := opts.ProxyURL
+	if proxyURL == "" {
+		proxyURL = os.Getenv("OMNISERP_PROXY_URL")
+	}
+	if proxyURL != "" {
+		RouteThroughProxy(registry, proxyURL)
 		if !opts.Silent {
-			log.Printf("Failed to initialize SerpAPI engine: %v", err)
+			log.Printf("Routing engine requests through %s", proxyURL)
 		}
 	}
 
 	client := &Client{
-		registry: registry,
+		registry:   registry,
+		stats:      newStatsRecorder(),
+		pages:      NewMemoryPageStore(),
+		saved:      NewSavedSearches(),
+		failover:   opts.Failover,
+		selection:  opts.Selection,
+		optIn:      make(map[string]bool, len(opts.PolicyEngines)),
+		indexer:    opts.Indexer,
+		publisher:  opts.Publisher,
+		annotators: opts.Annotators,
+		sanitizer:  opts.Sanitizer,
+		sources:    opts.SourcePolicy,
+		init:       report,
+
+		verbatimRequery: opts.RequeryVerbatim,
+	}
+
+	for _, name := range opts.PolicyEngines {
+		client.optIn[name] = true
+	}
+
+	if len(opts.Routes) > 0 {
+		policy := NewRoutingPolicy(opts.Routes)
+		policy.Base = opts.Selection
+		if err := policy.Validate(registry); err != nil {
+			return nil, fmt.Errorf("invalid routes: %w", err)
+		}
+		client.selection = policy
 	}
 
 	// Select the engine
@@ -117,17 +457,32 @@ func NewWithOptions(opts *Options) (*Client, error) {
 			return nil, err
 		}
 	} else {
-		engine, err = omniserp.GetDefaultEngine(registry)
-		if err != nil && !opts.Silent {
-			log.Printf("Warning: %v", err)
+		var info omniserp.FallbackInfo
+		engine, info, err = omniserp.GetDefaultEngine(registry, opts.Fallback)
+		if errors.Is(err, omniserp.ErrNoEngines) {
+			return nil, fmt.Errorf("%w. Please ensure API keys are set", omniserp.ErrNoEngines)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.FellBack {
+			report.Fallback = &info
+			if !opts.Silent {
+				log.Printf("Warning: %s", info)
+			}
 		}
-	}
-
-	if engine == nil {
-		return nil, fmt.Errorf("no search engines available. Please ensure API keys are set")
 	}
 
 	client.engine = engine
+	report.Selected = engine.GetName()
+
+	if opts.ValidateKeys {
+		ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+		defer cancel()
+		if err := client.ValidateKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	if !opts.Silent {
 		log.Printf("Using search engine: %s v%s", engine.GetName(), engine.GetVersion())
@@ -151,10 +506,56 @@ func (c *Client) SetEngine(name string) error {
 	if err != nil {
 		return err
 	}
+	c.mu.Lock()
 	c.engine = engine
+	c.mu.Unlock()
 	return nil
 }
 
+// SetSanitizer sets the sanitizer of engine responses, or disables
+// sanitization if nil
+func (c *Client) SetSanitizer(sanitizer *omniserp.Sanitizer) {
+	c.mu.Lock()
+	c.sanitizer = sanitizer
+	c.mu.Unlock()
+}
+
+// currentSanitizer returns the sanitizer of engine responses, or nil
+func (c *Client) currentSanitizer() *omniserp.Sanitizer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sanitizer
+}
+
+// SetSourcePolicy sets the source reputation policy of normalized searches,
+// or disables it if nil
+func (c *Client) SetSourcePolicy(policy *omniserp.SourcePolicy) {
+	c.mu.Lock()
+	c.sources = policy
+	c.mu.Unlock()
+}
+
+// sourcePolicy returns the source reputation policy, or nil
+func (c *Client) sourcePolicy() *omniserp.SourcePolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sources
+}
+
+// SetFailoverPolicy enables failover with the given policy, or disables it if nil
+func (c *Client) SetFailoverPolicy(policy *FailoverPolicy) {
+	c.mu.Lock()
+	c.failover = policy
+	c.mu.Unlock()
+}
+
+// failoverPolicy returns the failover policy, or nil
+func (c *Client) failoverPolicy() *FailoverPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.failover
+}
+
 // GetRegistry returns the underlying engine registry
 func (c *Client) GetRegistry() *omniserp.Registry {
 	return c.registry
@@ -167,14 +568,24 @@ func (c *Client) ListEngines() []string {
 
 // GetCurrentEngine returns the currently selected engine
 func (c *Client) GetCurrentEngine() omniserp.Engine {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.engine
 }
 
-// SupportsOperation checks if the current engine supports a specific operation
+// SupportsOperation checks if the current engine supports a specific
+// operation, given by tool name or engine-neutral identifier. With a
+// selection policy, any registered engine may serve it.
 func (c *Client) SupportsOperation(operation string) bool {
-	supportedTools := c.engine.GetSupportedTools()
-	for _, tool := range supportedTools {
-		if tool == operation {
+	operation = ToolName(operation)
+	if slices.Contains(c.GetCurrentEngine().GetSupportedTools(), operation) {
+		return true
+	}
+	if c.selectionPolicy() == nil {
+		return false
+	}
+	for _, engine := range c.registry.GetAll() {
+		if slices.Contains(engine.GetSupportedTools(), operation) {
 			return true
 		}
 	}
@@ -183,9 +594,11 @@ func (c *Client) SupportsOperation(operation string) bool {
 
 // checkSupport returns an error if the operation is not supported by the current engine
 func (c *Client) checkSupport(operation string) error {
+	operation = ToolName(operation)
 	if !c.SupportsOperation(operation) {
+		engine := c.GetCurrentEngine()
 		return fmt.Errorf("%w: '%s' (engine: %s, supported: %v)",
-			ErrOperationNotSupported, operation, c.engine.GetName(), c.engine.GetSupportedTools())
+			ErrOperationNotSupported, operation, engine.GetName(), engine.GetSupportedTools())
 	}
 	return nil
 }
@@ -194,146 +607,343 @@ func (c *Client) checkSupport(operation string) error {
 
 // GetName returns the name of the current search engine
 func (c *Client) GetName() string {
-	return c.engine.GetName()
+	return c.GetCurrentEngine().GetName()
 }
 
 // GetVersion returns the version of the current engine implementation
 func (c *Client) GetVersion() string {
-	return c.engine.GetVersion()
+	return c.GetCurrentEngine().GetVersion()
 }
 
 // GetSupportedTools returns a list of tool names supported by the current engine
 func (c *Client) GetSupportedTools() []string {
-	return c.engine.GetSupportedTools()
+	return c.GetCurrentEngine().GetSupportedTools()
 }
 
 // Search performs a general web search
 func (c *Client) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearch); err != nil {
-		return nil, err
-	}
-	return c.engine.Search(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearch, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.Search(ctx, params)
+	})
+	return result, err
 }
 
 // SearchNews performs a news search
 func (c *Client) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchNews); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchNews(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchNews, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchNews(ctx, params)
+	})
+	return result, err
 }
 
 // SearchImages performs an image search
 func (c *Client) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchImages); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchImages(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchImages, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchImages(ctx, params)
+	})
+	return result, err
 }
 
 // SearchVideos performs a video search
 func (c *Client) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchVideos); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchVideos(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchVideos, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchVideos(ctx, params)
+	})
+	return result, err
 }
 
 // SearchPlaces performs a places search
 func (c *Client) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchPlaces); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchPlaces(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchPlaces, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchPlaces(ctx, params)
+	})
+	return result, err
 }
 
 // SearchMaps performs a maps search
 func (c *Client) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchMaps); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchMaps(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchMaps, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchMaps(ctx, params)
+	})
+	return result, err
 }
 
 // SearchReviews performs a reviews search
 func (c *Client) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchReviews); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchReviews(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchReviews, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchReviews(ctx, params)
+	})
+	return result, err
 }
 
 // SearchShopping performs a shopping search
 func (c *Client) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchShopping); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchShopping(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchShopping, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchShopping(ctx, params)
+	})
+	return result, err
 }
 
 // SearchScholar performs a scholar search
 func (c *Client) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchScholar); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchScholar(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchScholar, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchScholar(ctx, params)
+	})
+	return result, err
 }
 
 // SearchLens performs a visual search (if supported)
 func (c *Client) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchLens); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchLens(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchLens, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchLens(ctx, params)
+	})
+	return result, err
 }
 
 // SearchAutocomplete gets search suggestions
 func (c *Client) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpSearchAutocomplete); err != nil {
-		return nil, err
-	}
-	return c.engine.SearchAutocomplete(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, _, err := c.call(ctx, OpSearchAutocomplete, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchAutocomplete(ctx, params)
+	})
+	return result, err
 }
 
 // ScrapeWebpage scrapes content from a webpage
 func (c *Client) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
-	if err := c.checkSupport(OpScrapeWebpage); err != nil {
-		return nil, err
-	}
-	return c.engine.ScrapeWebpage(ctx, params)
+	result, _, err := c.call(ctx, OpScrapeWebpage, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.ScrapeWebpage(ctx, params)
+	})
+	return result, err
 }
 
 // Normalized response methods - these return unified response structures across all engines
 
+// finishSearch records the query fingerprint in the search metadata, drops
+// images that do not match params.Image, numbers the positions of later pages
+// after the earlier ones, ranks news by params.NewsRanking, applies the
+// source policy, and annotates, indexes, and publishes the result of a
+// successful normalization of an operation
+func (c *Client) finishSearch(ctx context.Context, operation string, normalized *omniserp.NormalizedSearchResult, params omniserp.SearchParams, err error) (*omniserp.NormalizedSearchResult, error) {
+	if normalized != nil {
+		normalized.SearchMetadata.Fingerprint = params.Fingerprint()
+		normalized.ImageResults = omniserp.FilterImages(normalized.ImageResults, params.Image)
+		normalized.OffsetPositions(pageOffset(params))
+		if params.NewsRanking != nil {
+			normalized.NewsResults = omniserp.RankNews(normalized.NewsResults, *params.NewsRanking, time.Now())
+		}
+		if sources := c.sourcePolicy(); sources != nil {
+			omniserp.ApplySourcePolicy(normalized, *sources)
+		}
+	}
+	if err == nil {
+		c.annotate(ctx, normalized)
+		c.index(ctx, index.FromSearch(normalized))
+		c.publish(ctx, operation, normalized)
+	}
+	return normalized, err
+}
+
+// defaultPageSize is the number of results per page when
+// SearchParams.NumResults is not set
+const defaultPageSize = 10
+
+// pageOffset estimates the number of results on the pages before params.Page
+func pageOffset(params omniserp.SearchParams) int {
+	if params.Page <= 1 {
+		return 0
+	}
+	perPage := params.NumResults
+	if perPage <= 0 {
+		perPage = defaultPageSize
+	}
+	return (params.Page - 1) * perPage
+}
+
 // SearchNormalized performs a web search and returns a normalized response
 func (c *Client) SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
-	result, err := c.Search(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearch, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.Search(ctx, params)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	normalizer := omniserp.NewNormalizer(c.GetName())
-	return normalizer.NormalizeSearch(result, params.Query)
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeSearch(result, params.Query)
+	if err == nil && c.requeryVerbatimEnabled() && !params.Verbatim && normalized.SearchMetadata.CorrectedQuery != "" {
+		return c.requeryVerbatim(ctx, normalized, params)
+	}
+	return c.finishSearch(ctx, OpSearch, normalized, params, err)
 }
 
-// SearchNewsNormalized performs a news search and returns a normalized response
+// SearchNewsNormalized performs a news search and returns a normalized
+// response, ordered by recency and source diversity if params.NewsRanking is
+// set
 func (c *Client) SearchNewsNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
-	result, err := c.SearchNews(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchNews, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchNews(ctx, params)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	normalizer := omniserp.NewNormalizer(c.GetName())
-	return normalizer.NormalizeNews(result, params.Query)
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeNews(result, params.Query)
+	return c.finishSearch(ctx, OpSearchNews, normalized, params, err)
 }
 
-// SearchImagesNormalized performs an image search and returns a normalized response
+// SearchImagesNormalized performs an image search and returns a normalized
+// response. Images that do not meet the size and aspect ratio of
+// params.Image are dropped.
 func (c *Client) SearchImagesNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
-	result, err := c.SearchImages(ctx, params)
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchImages, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchImages(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeImages(result, params.Query)
+	return c.finishSearch(ctx, OpSearchImages, normalized, params, err)
+}
+
+// SearchShoppingNormalized performs a shopping search and returns a normalized response
+func (c *Client) SearchShoppingNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchShopping, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchShopping(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeShopping(result, params.Query)
+	return c.finishSearch(ctx, OpSearchShopping, normalized, params, err)
+}
+
+// SearchPlacesNormalized performs a places search and returns a normalized response
+func (c *Client) SearchPlacesNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchPlaces, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchPlaces(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizePlaces(result, params.Query)
+	return c.finishSearch(ctx, OpSearchPlaces, normalized, params, err)
+}
+
+// SearchMapsNormalized performs a maps search and returns a normalized response
+func (c *Client) SearchMapsNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchMaps, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchMaps(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizePlaces(result, params.Query)
+	return c.finishSearch(ctx, OpSearchMaps, normalized, params, err)
+}
+
+// SearchReviewsNormalized performs a reviews search and returns a normalized
+// response with a summary of the ratings on the page; see CollectReviews to
+// summarize several pages
+func (c *Client) SearchReviewsNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchReviews, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchReviews(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeReviews(result, params.Query)
+	return c.finishSearch(ctx, OpSearchReviews, normalized, params, err)
+}
+
+// SearchScholarNormalized performs a scholar search and returns a normalized response
+func (c *Client) SearchScholarNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchScholar, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchScholar(ctx, params)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	normalizer := omniserp.NewNormalizer(c.GetName())
-	return normalizer.NormalizeImages(result, params.Query)
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeScholar(result, params.Query)
+	return c.finishSearch(ctx, OpSearchScholar, normalized, params, err)
+}
+
+// SearchAutocompleteNormalized gets search suggestions and returns a
+// normalized response
+func (c *Client) SearchAutocompleteNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	result, engine, err := c.call(ctx, OpSearchAutocomplete, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+		return engine.SearchAutocomplete(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := omniserp.NewNormalizer(engine.GetName())
+	normalized, err := normalizer.NormalizeAutocomplete(result, params.Query)
+	return c.finishSearch(ctx, OpSearchAutocomplete, normalized, params, err)
+}
+
+// ScrapeNormalized scrapes a webpage and returns a normalized response
+func (c *Client) ScrapeNormalized(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.NormalizedScrapeResult, error) {
+	result, err := c.ScrapeWebpage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	scraped, err := omniserp.NormalizeScrape(result, params.URL)
+	if err != nil {
+		return nil, err
+	}
+	c.index(ctx, index.FromScrape(scraped))
+	return scraped, nil
+}
+
+// Credits returns the remaining API credits of the named engine, or of the
+// current engine if name is empty. It returns ErrOperationNotSupported if
+// the engine cannot report credits.
+func (c *Client) Credits(ctx context.Context, name string) (*omniserp.Credits, error) {
+	engine := c.GetCurrentEngine()
+	if name != "" {
+		var err error
+		if engine, err = c.GetEngine(name); err != nil {
+			return nil, err
+		}
+	}
+
+	reporter, ok := engine.(omniserp.CreditReporter)
+	if !ok {
+		return nil, fmt.Errorf("%w: credits (engine: %s)", ErrOperationNotSupported, engine.GetName())
+	}
+	return reporter.Credits(ctx)
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/scaleserp"
+	"github.com/plexusone/omniserp/client/searchapi"
 )
 
 // TestCapabilityChecking tests that the client properly validates operation support
@@ -51,6 +54,63 @@ func TestCapabilityChecking(t *testing.T) {
 		}
 	})
 
+	// Test with ScaleSERP (supports shopping but not images)
+	t.Run("ScaleSERP supports shopping but not images", func(t *testing.T) {
+		engine, err := scaleserp.NewWithAPIKey("placeholder")
+		if err != nil {
+			t.Fatalf("NewWithAPIKey failed: %v", err)
+		}
+		registry := omniserp.NewRegistry()
+		registry.Register(engine)
+		c, err := NewWithRegistry(registry, "scaleserp")
+		if err != nil {
+			t.Fatalf("NewWithRegistry failed: %v", err)
+		}
+
+		for _, op := range []string{OpSearch, OpSearchNews, OpSearchPlaces, OpSearchShopping} {
+			if !c.SupportsOperation(op) {
+				t.Errorf("ScaleSERP should support %s", op)
+			}
+		}
+		for _, op := range []string{OpSearchImages, OpSearchMaps, OpSearchLens} {
+			if c.SupportsOperation(op) {
+				t.Errorf("ScaleSERP should NOT support %s", op)
+			}
+		}
+
+		_, err = c.SearchImages(context.Background(), omniserp.SearchParams{Query: "test"})
+		if !errors.Is(err, ErrOperationNotSupported) {
+			t.Errorf("Expected ErrOperationNotSupported, got: %v", err)
+		}
+	})
+
+	t.Run("SearchAPI.io supports Lens but not scraping", func(t *testing.T) {
+		engine, err := searchapi.NewWithAPIKey("placeholder")
+		if err != nil {
+			t.Fatalf("NewWithAPIKey failed: %v", err)
+		}
+		registry := omniserp.NewRegistry()
+		registry.Register(engine)
+		c, err := NewWithRegistry(registry, "searchapi")
+		if err != nil {
+			t.Fatalf("NewWithRegistry failed: %v", err)
+		}
+
+		for _, op := range []string{OpSearch, OpSearchReviews, OpSearchScholar, OpSearchLens, OpSearchAutocomplete} {
+			if !c.SupportsOperation(op) {
+				t.Errorf("SearchAPI.io should support %s", op)
+			}
+		}
+		if c.SupportsOperation(OpScrapeWebpage) {
+			t.Errorf("SearchAPI.io should NOT support %s", OpScrapeWebpage)
+		}
+
+		_, err = c.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "https://example.com"})
+		if !errors.Is(err, ErrOperationNotSupported) {
+			t.Errorf("Expected ErrOperationNotSupported, got: %v", err)
+		}
+	})
+
 	// Test all engines support basic search
 	t.Run("All engines support basic search", func(t *testing.T) {
 		c, err := New()
@@ -210,3 +270,165 @@ func TestOperationConstants(t *testing.T) {
 		}
 	}
 }
+
+// TestOperationIDs verifies the mapping between engine-neutral identifiers
+// and tool names
+func TestOperationIDs(t *testing.T) {
+	ids, tools := AllOperationIDs(), AllOperations()
+	if len(ids) != len(tools) {
+		t.Fatalf("Expected %d identifiers, got %d", len(tools), len(ids))
+	}
+	for i, id := range ids {
+		if got := ToolName(id); got != tools[i] {
+			t.Errorf("ToolName(%q) = %q, expected %q", id, got, tools[i])
+		}
+		if got := OperationID(tools[i]); got != id {
+			t.Errorf("OperationID(%q) = %q, expected %q", tools[i], got, id)
+		}
+		if ToolName(tools[i]) != tools[i] || OperationID(id) != id {
+			t.Errorf("Expected %q and %q to map to themselves", tools[i], id)
+		}
+	}
+
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse(), nil
+	})
+	if !c.SupportsOperation(OpNews) || c.SupportsOperation("bogus") {
+		t.Error("Expected capability checks to accept engine-neutral identifiers")
+	}
+}
+
+func TestSanitizer(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://go.dev/?utm_source=serp"), nil
+	})
+	c.SetSanitizer(omniserp.NewSanitizer())
+
+	result, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: "go"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if link := result.OrganicResults[0].Link; link != "https://go.dev/" {
+		t.Errorf("Expected a sanitized link, got %q", link)
+	}
+}
+
+func TestSearchImagesFilter(t *testing.T) {
+	var sent omniserp.SearchParams
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		sent = params
+		return &omniserp.SearchResult{Data: map[string]any{"images": []any{
+			map[string]any{"title": "small", "imageUrl": "https://example.com/small.png", "imageWidth": 320, "imageHeight": 240},
+			map[string]any{"title": "large", "imageUrl": "https://example.com/large.png", "imageWidth": 1920, "imageHeight": 1080},
+		}}}, nil
+	})
+
+	filter := &omniserp.ImageFilter{MinWidth: 1024, License: omniserp.LicenseCreativeCommons}
+	normalized, err := c.SearchImagesNormalized(context.Background(), omniserp.SearchParams{Query: "gopher", Image: filter})
+	if err != nil {
+		t.Fatalf("SearchImagesNormalized failed: %v", err)
+	}
+	if sent.Image != filter {
+		t.Error("Expected the image filter to be passed to the engine")
+	}
+	if len(normalized.ImageResults) != 1 || normalized.ImageResults[0].Title != "large" || normalized.ImageResults[0].Position != 1 {
+		t.Errorf("Expected only the large image at position 1, got %+v", normalized.ImageResults)
+	}
+}
+
+func TestSearchNewsRanking(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return &omniserp.SearchResult{Data: map[string]any{"news": []any{
+			map[string]any{"title": "Last week", "link": "https://a.example/1", "source": "A", "date": "1 week ago"},
+			map[string]any{"title": "This morning", "link": "https://b.example/1", "source": "B", "date": "2 hours ago"},
+		}}}, nil
+	})
+
+	params := omniserp.SearchParams{Query: "golang"}
+	normalized, err := c.SearchNewsNormalized(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SearchNewsNormalized failed: %v", err)
+	}
+	if normalized.NewsResults[0].Title != "Last week" || normalized.NewsResults[1].PublishedAt.IsZero() {
+		t.Errorf("Expected the engine's order with parsed dates, got %+v", normalized.NewsResults)
+	}
+
+	params.NewsRanking = &omniserp.NewsRanking{}
+	normalized, err = c.SearchNewsNormalized(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SearchNewsNormalized failed: %v", err)
+	}
+	if first := normalized.NewsResults[0]; first.Title != "This morning" || first.Position != 1 || first.SourcePosition != 2 {
+		t.Errorf("Expected the recent story first, got %+v", normalized.NewsResults)
+	}
+}
+
+func TestSearchSourcePolicy(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return &omniserp.SearchResult{Data: map[string]any{"organic": []any{
+			map[string]any{"title": "Farm", "link": "https://contentfarm.example/go"},
+			map[string]any{"title": "Docs", "link": "https://go.dev/doc"},
+			map[string]any{"title": "Blog", "link": "https://blog.example/go"},
+		}}}, nil
+	})
+	c.SetSourcePolicy(&omniserp.SourcePolicy{
+		Scorer:   omniserp.NewSourceList([]string{"go.dev"}, []string{"contentfarm.example"}),
+		MinScore: 0.1,
+		Weight:   1,
+	})
+
+	normalized, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	organic := normalized.OrganicResults
+	if len(organic) != 2 || organic[0].Title != "Docs" || organic[0].Position != 1 || organic[1].Title != "Blog" {
+		t.Errorf("Expected the trusted source first without the content farm, got %+v", organic)
+	}
+}
+
+// TestSettersDuringSearches changes the client's settings, as the MCP config
+// reloader does, while normalized searches are running; run with -race
+func TestSettersDuringSearches(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			enabled := i%2 == 0
+			c.SetAnnotators()
+			c.SetPublisher(nil)
+			c.SetIndexer(nil)
+			c.SetPageStore(NewMemoryPageStore())
+			c.SetSavedSearches(NewSavedSearches())
+			c.SetVerbatimRequery(enabled)
+			if enabled {
+				if err := c.SetDeterministic(&Deterministic{Engine: "serper"}); err != nil {
+					t.Errorf("SetDeterministic failed: %v", err)
+					return
+				}
+			} else if err := c.SetDeterministic(nil); err != nil {
+				t.Errorf("SetDeterministic failed: %v", err)
+				return
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	for range 1000 {
+		if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "q"}); err != nil {
+			t.Errorf("SearchNormalized failed: %v", err)
+			break
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
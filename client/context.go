@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// engineKey is the context key of the preferred engine
+type engineKey struct{}
+
+// failoverHookKey is the context key of the failover hook
+type failoverHookKey struct{}
+
+// FailoverEvent describes a request moved from one engine to the next
+type FailoverEvent struct {
+	Operation string
+	From      string // engine that failed
+	To        string // engine tried next
+	Err       error  // error of From; ErrEngineSwitched if it was switched away
+}
+
+// ContextWithEngine returns a copy of ctx preferring the named engine for
+// the searches made with it, such as those of a user session. The engine
+// replaces the current engine and any selection policy; other engines are
+// only tried with a failover policy. Searches fail if the engine is not
+// registered or does not support the operation. An empty name removes the
+// preference.
+func ContextWithEngine(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, engineKey{}, name)
+}
+
+// EngineFromContext returns the engine preferred by ctx with
+// ContextWithEngine
+func EngineFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(engineKey{}).(string)
+	return name, ok && name != ""
+}
+
+// checkPreferred returns an error if the preferred engine cannot run the
+// operation
+func (c *Client) checkPreferred(operation, name string) error {
+	if d := c.deterministic(); d != nil && name != d.Engine {
+		return fmt.Errorf("deterministic mode is pinned to engine %s, not %s", d.Engine, name)
+	}
+	engine, err := c.GetEngine(name)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(engine.GetSupportedTools(), operation) {
+		return fmt.Errorf("%w: %s (engine: %s)", ErrOperationNotSupported, operation, name)
+	}
+	return nil
+}
+
+// ContextWithFailoverHook returns a copy of ctx calling fn each time a
+// search made with it is retried on another engine, so callers can tell
+// users why a search was slow or served by an unexpected engine. fn is
+// called synchronously before the next engine is tried.
+func ContextWithFailoverHook(ctx context.Context, fn func(FailoverEvent)) context.Context {
+	return context.WithValue(ctx, failoverHookKey{}, fn)
+}
+
+// notifyFailover calls the failover hook of ctx, if any
+func notifyFailover(ctx context.Context, event FailoverEvent) {
+	if fn, ok := ctx.Value(failoverHookKey{}).(func(FailoverEvent)); ok && fn != nil {
+		fn(event)
+	}
+}
@@ -0,0 +1,731 @@
+// Package dataforseo implements the omniserp.Engine interface for the
+// DataForSEO SERP and Merchant APIs. DataForSEO is task based: web, news,
+// image, and maps searches use the Live endpoints, which answer a task in
+// the request that posts it, and shopping searches post a task and poll for
+// its result. The engine hides the tasks, so every search is one call.
+package dataforseo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.dataforseo.com"
+	engineName    = "dataforseo"
+	engineVersion = "1.0.0"
+
+	organicPath      = "/v3/serp/google/organic/live/advanced"
+	newsPath         = "/v3/serp/google/news/live/advanced"
+	imagesPath       = "/v3/serp/google/images/live/advanced"
+	mapsPath         = "/v3/serp/google/maps/live/advanced"
+	productsPostPath = "/v3/merchant/google/products/task_post"
+	productsGetPath  = "/v3/merchant/google/products/task_get/advanced/"
+
+	// maxDepth is the largest number of results of a task
+	maxDepth = 700
+
+	// defaultPollInterval is the time between polls of a shopping task
+	defaultPollInterval = 2 * time.Second
+
+	// taskTimeout bounds the wait for a shopping task when the context has
+	// no earlier deadline
+	taskTimeout = 2 * time.Minute
+)
+
+// Status codes of DataForSEO responses and tasks
+const (
+	statusOK          = 20000
+	statusTaskCreated = 20100
+	statusTaskHanded  = 40601
+	statusTaskInQueue = 40602
+)
+
+// Engine implements the omniserp.Engine interface for DataForSEO. Results
+// are normalized by the engine and returned as the Data of each search
+// result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	login        string
+	password     string
+	baseURL      string
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+// New creates a new DataForSEO engine from the DATAFORSEO_LOGIN and
+// DATAFORSEO_PASSWORD env vars
+func New() (*Engine, error) {
+	login := os.Getenv("DATAFORSEO_LOGIN")
+	if login == "" {
+		return nil, fmt.Errorf("DATAFORSEO_LOGIN environment variable is required")
+	}
+	password := os.Getenv("DATAFORSEO_PASSWORD")
+	if password == "" {
+		return nil, fmt.Errorf("DATAFORSEO_PASSWORD environment variable is required")
+	}
+	return NewWithCredentials(login, password)
+}
+
+// NewWithCredentials creates a new DataForSEO engine with the provided API
+// login and password
+func NewWithCredentials(login, password string) (*Engine, error) {
+	if login == "" {
+		return nil, fmt.Errorf("API login is required")
+	}
+	if password == "" {
+		return nil, fmt.Errorf("API password is required")
+	}
+
+	return &Engine{
+		login:        login,
+		password:     password,
+		baseURL:      baseURL,
+		client:       &http.Client{},
+		pollInterval: defaultPollInterval,
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// SetPollInterval sets the time between polls of shopping tasks
+func (e *Engine) SetPollInterval(d time.Duration) {
+	e.pollInterval = d
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"google_search_images",
+		"google_search_maps",
+		"google_search_shopping",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. Freshness and safe
+// search are only sent with web and news searches.
+func (e *Engine) SupportedParams(operation string) []string {
+	params := []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLocation,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+	}
+	switch operation {
+	case "google_search", "google_search_news":
+		params = append(params, omniserp.ParamFreshness, omniserp.ParamSafeSearch)
+	}
+	return params
+}
+
+// Extra declares the DataForSEO-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Device       string `extra:"device" description:"Device to search from: desktop (default) or mobile"`
+	LocationCode int    `extra:"location_code" description:"DataForSEO location code, overriding the location and country"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// countryCodes maps ISO 3166 country codes to their numeric codes. The
+// location code of a country is 2000 plus its numeric code.
+var countryCodes = map[string]int{
+	"ar": 32, "at": 40, "au": 36, "be": 56, "br": 76, "ca": 124, "ch": 756,
+	"cl": 152, "cn": 156, "co": 170, "cz": 203, "de": 276, "dk": 208,
+	"es": 724, "fi": 246, "fr": 250, "gb": 826, "gr": 300, "hk": 344,
+	"id": 360, "ie": 372, "il": 376, "in": 356, "it": 380, "jp": 392,
+	"kr": 410, "mx": 484, "my": 458, "nl": 528, "no": 578, "nz": 554,
+	"ph": 608, "pl": 616, "pt": 620, "ro": 642, "ru": 643, "sa": 682,
+	"se": 752, "sg": 702, "th": 764, "tr": 792, "tw": 158, "ua": 804,
+	"us": 840, "vn": 704, "za": 710,
+}
+
+// freshness returns the Google time range of a freshness
+func freshness(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour:
+		return "h"
+	case omniserp.FreshnessDay:
+		return "d"
+	case omniserp.FreshnessWeek:
+		return "w"
+	case omniserp.FreshnessMonth:
+		return "m"
+	case omniserp.FreshnessYear:
+		return "y"
+	}
+	return ""
+}
+
+// resultsPerPage returns the number of results per page of params
+func resultsPerPage(params omniserp.SearchParams) int {
+	if params.NumResults > 0 {
+		return params.NumResults
+	}
+	return 10
+}
+
+// buildTask converts SearchParams to a task. Tasks have no pages, so the
+// depth covers the results of all pages up to the requested one.
+func (e *Engine) buildTask(params omniserp.SearchParams, googleParams bool) (map[string]any, error) {
+	task := map[string]any{"keyword": params.Query}
+
+	language := params.Language
+	if language == "" {
+		language = "en"
+	}
+	task["language_code"] = language
+
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	switch country := strings.ToLower(params.Country); {
+	case extra["location_code"] != nil:
+	case params.Location != "":
+		task["location_name"] = params.Location
+	case country == "":
+		task["location_code"] = 2000 + countryCodes["us"]
+	default:
+		code, ok := countryCodes[country]
+		if !ok {
+			return nil, fmt.Errorf("country %q has no DataForSEO location code; set the location or the location_code extra parameter", params.Country)
+		}
+		task["location_code"] = 2000 + code
+	}
+
+	task["depth"] = min(resultsPerPage(params)*max(params.Page, 1), maxDepth)
+
+	if googleParams {
+		var searchParam string
+		if f := freshness(params.Freshness); f != "" {
+			searchParam += "&tbs=qdr:" + f
+		}
+		if params.SafeSearch {
+			searchParam += "&safe=active"
+		}
+		if searchParam != "" {
+			task["search_param"] = searchParam
+		}
+	}
+
+	for name, value := range extra {
+		task[name] = value
+	}
+	return task, nil
+}
+
+// envelope is the JSON response of every endpoint
+type envelope struct {
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+	Tasks         []struct {
+		ID            string       `json:"id"`
+		StatusCode    int          `json:"status_code"`
+		StatusMessage string       `json:"status_message"`
+		Result        []taskResult `json:"result"`
+	} `json:"tasks"`
+}
+
+// taskResult is the result of a task
+type taskResult struct {
+	SEResultsCount int64 `json:"se_results_count"`
+	Spell          *struct {
+		Keyword string `json:"keyword"`
+		Type    string `json:"type"`
+	} `json:"spell"`
+	Items []item `json:"items"`
+}
+
+// item is a SERP element; the fields in use depend on its type
+type item struct {
+	Type      string `json:"type"`
+	RankGroup int    `json:"rank_group"`
+
+	Title         string  `json:"title"`
+	URL           string  `json:"url"`
+	Domain        string  `json:"domain"`
+	Description   string  `json:"description"`
+	Timestamp     string  `json:"timestamp"`
+	Source        string  `json:"source"`
+	Snippet       string  `json:"snippet"`
+	TimePublished string  `json:"time_published"`
+	ImageURL      string  `json:"image_url"`
+	SourceURL     string  `json:"source_url"`
+	EncodedURL    string  `json:"encoded_url"`
+	Subtitle      string  `json:"subtitle"`
+	Address       string  `json:"address"`
+	Phone         string  `json:"phone"`
+	Category      string  `json:"category"`
+	PlaceID       string  `json:"place_id"`
+	CID           string  `json:"cid"`
+	MainImage     string  `json:"main_image"`
+	PriceLevel    string  `json:"price_level"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	Rating        *rating `json:"rating"`
+
+	// Shopping products
+	ShoppingURL   string  `json:"shopping_url"`
+	ProductID     string  `json:"product_id"`
+	Price         float64 `json:"price"`
+	OldPrice      float64 `json:"old_price"`
+	Currency      string  `json:"currency"`
+	Seller        string  `json:"seller"`
+	ReviewsCount  int     `json:"reviews_count"`
+	ProductRating *rating `json:"product_rating"`
+
+	// Items are the related searches, as strings, and the questions of
+	// people also ask elements
+	Items json.RawMessage `json:"items"`
+}
+
+// rating is the rating of a place or product
+type rating struct {
+	Value      float64 `json:"value"`
+	VotesCount int     `json:"votes_count"`
+}
+
+// question is a people also ask question
+type question struct {
+	Title    string `json:"title"`
+	Expanded []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Domain      string `json:"domain"`
+		Description string `json:"description"`
+	} `json:"expanded_element"`
+}
+
+// post sends tasks to a Live or task_post endpoint
+func (e *Engine) post(ctx context.Context, path string, task map[string]any) (*envelope, string, *omniserp.ResponseMeta, error) {
+	body, err := json.Marshal([]map[string]any{task})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return e.do(req)
+}
+
+// do sends an authenticated request and parses the response envelope
+func (e *Engine) do(req *http.Request) (*envelope, string, *omniserp.ResponseMeta, error) {
+	req.SetBasicAuth(e.login, e.password)
+
+	start := time.Now()
+	// #nosec G704 -- request to the DataForSEO API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var parsed envelope
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if parsed.StatusCode != statusOK {
+		return nil, "", nil, fmt.Errorf("dataforseo error %d: %s", parsed.StatusCode, parsed.StatusMessage)
+	}
+	if len(parsed.Tasks) == 0 {
+		return nil, "", nil, fmt.Errorf("dataforseo response has no task")
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = parsed.Tasks[0].ID
+	}
+	return &parsed, string(body), meta, nil
+}
+
+// live runs a task on a Live endpoint and returns its result
+func (e *Engine) live(ctx context.Context, path string, params omniserp.SearchParams, googleParams bool) (*taskResult, string, *omniserp.ResponseMeta, error) {
+	task, err := e.buildTask(params, googleParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	resp, raw, meta, err := e.post(ctx, path, task)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	result, err := taskResultOf(resp)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return result, raw, meta, nil
+}
+
+// taskResultOf returns the result of the first task of a response
+func taskResultOf(resp *envelope) (*taskResult, error) {
+	task := resp.Tasks[0]
+	if task.StatusCode != statusOK {
+		return nil, fmt.Errorf("dataforseo task error %d: %s", task.StatusCode, task.StatusMessage)
+	}
+	if len(task.Result) == 0 {
+		return &taskResult{}, nil
+	}
+	return &task.Result[0], nil
+}
+
+// waitForTask posts a task and polls until its result is ready
+func (e *Engine) waitForTask(ctx context.Context, task map[string]any) (*taskResult, string, *omniserp.ResponseMeta, error) {
+	resp, _, _, err := e.post(ctx, productsPostPath, task)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if code := resp.Tasks[0].StatusCode; code != statusTaskCreated {
+		return nil, "", nil, fmt.Errorf("dataforseo task error %d: %s", code, resp.Tasks[0].StatusMessage)
+	}
+	id := resp.Tasks[0].ID
+
+	ctx, cancel := context.WithTimeout(ctx, taskTimeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, "", nil, fmt.Errorf("dataforseo task %s is not ready: %w", id, ctx.Err())
+		case <-time.After(e.pollInterval):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+productsGetPath+id, nil)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, raw, meta, err := e.do(req)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		switch resp.Tasks[0].StatusCode {
+		case statusTaskHanded, statusTaskInQueue:
+			continue
+		}
+		result, err := taskResultOf(resp)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return result, raw, meta, nil
+	}
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, result *taskResult) *omniserp.NormalizedSearchResult {
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Location:     params.Location,
+			Language:     params.Language,
+			Country:      params.Country,
+			TotalResults: result.SEResultsCount,
+		},
+	}
+	if result.Spell != nil {
+		switch result.Spell.Type {
+		case "showing_results_for", "including_results_for":
+			normalized.SearchMetadata.CorrectedQuery = result.Spell.Keyword
+		case "did_you_mean":
+			normalized.SearchMetadata.SuggestedQuery = result.Spell.Keyword
+		}
+	}
+	return normalized
+}
+
+// onPage reports whether the item at a 1-based rank is on the requested
+// page, since tasks return the results of all earlier pages too
+func onPage(rank int, params omniserp.SearchParams) bool {
+	return rank > resultsPerPage(params)*(max(params.Page, 1)-1)
+}
+
+// formatTimestamp converts a DataForSEO timestamp to RFC 3339, keeping
+// other dates as given
+func formatTimestamp(timestamp string) string {
+	t, err := time.Parse("2006-01-02 15:04:05 -07:00", timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatPrice formats a price without trailing zeros
+func formatPrice(price float64) string {
+	if price == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.live(ctx, organicPath, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, it := range result.Items {
+		switch it.Type {
+		case "organic":
+			if !onPage(it.RankGroup, params) {
+				continue
+			}
+			normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+				Position: len(normalized.OrganicResults) + 1,
+				Title:    it.Title,
+				Link:     it.URL,
+				URL:      it.URL,
+				Snippet:  it.Description,
+				Domain:   strings.TrimPrefix(it.Domain, "www."),
+				Date:     formatTimestamp(it.Timestamp),
+			})
+		case "featured_snippet":
+			normalized.AnswerBox = &omniserp.AnswerBox{
+				Type:    "featured_snippet",
+				Title:   it.Title,
+				Snippet: it.Description,
+				Source:  it.Domain,
+				Link:    it.URL,
+			}
+		case "people_also_ask":
+			var questions []question
+			_ = json.Unmarshal(it.Items, &questions)
+			for _, q := range questions {
+				paa := omniserp.PeopleAlsoAsk{Question: q.Title}
+				if len(q.Expanded) > 0 {
+					x := q.Expanded[0]
+					paa.Answer, paa.Title, paa.Link, paa.Source = x.Description, x.Title, x.URL, x.Domain
+				}
+				normalized.PeopleAlsoAsk = append(normalized.PeopleAlsoAsk, paa)
+			}
+		case "related_searches":
+			var queries []string
+			_ = json.Unmarshal(it.Items, &queries)
+			for _, query := range queries {
+				normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: query})
+			}
+		}
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.live(ctx, newsPath, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, it := range result.Items {
+		if it.Type != "news_search" || !onPage(it.RankGroup, params) {
+			continue
+		}
+		date := formatTimestamp(it.Timestamp)
+		if date == "" {
+			date = it.TimePublished
+		}
+		source := it.Source
+		if source == "" {
+			source = strings.TrimPrefix(it.Domain, "www.")
+		}
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position: len(normalized.NewsResults) + 1,
+			Title:    it.Title,
+			Link:     it.URL,
+			Source:   source,
+			Date:     date,
+			Snippet:  it.Snippet,
+			ImageURL: it.ImageURL,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.live(ctx, imagesPath, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, it := range result.Items {
+		if it.Type != "images_search" || !onPage(it.RankGroup, params) {
+			continue
+		}
+		normalized.ImageResults = append(normalized.ImageResults, omniserp.ImageResult{
+			Position:  len(normalized.ImageResults) + 1,
+			Title:     it.Title,
+			ImageURL:  it.SourceURL,
+			Thumbnail: it.EncodedURL,
+			Source:    it.Subtitle,
+			SourceURL: it.URL,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchPlaces performs a places search (not supported by DataForSEO)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by DataForSEO")
+}
+
+// SearchMaps performs a Google Maps search
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.live(ctx, mapsPath, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, it := range result.Items {
+		if it.Type != "maps_search" || !onPage(it.RankGroup, params) {
+			continue
+		}
+		place := omniserp.PlaceResult{
+			Position:  len(normalized.PlaceResults) + 1,
+			Title:     it.Title,
+			PlaceID:   it.PlaceID,
+			DataID:    it.CID,
+			Address:   it.Address,
+			Phone:     it.Phone,
+			Website:   it.URL,
+			Type:      it.Category,
+			Price:     it.PriceLevel,
+			Latitude:  it.Latitude,
+			Longitude: it.Longitude,
+			Thumbnail: it.MainImage,
+		}
+		if it.Rating != nil {
+			place.Rating, place.Reviews = it.Rating.Value, it.Rating.VotesCount
+		}
+		normalized.PlaceResults = append(normalized.PlaceResults, place)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchShopping performs a Google Shopping search. The Merchant API has no
+// Live endpoint, so the search posts a task and polls until it is ready.
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	task, err := e.buildTask(params, false)
+	if err != nil {
+		return nil, err
+	}
+	result, raw, meta, err := e.waitForTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, it := range result.Items {
+		if it.Type != "google_shopping_serp" || !onPage(it.RankGroup, params) {
+			continue
+		}
+		link := it.URL
+		if link == "" {
+			link = it.ShoppingURL
+		}
+		product := omniserp.ShoppingResult{
+			Position:      len(normalized.ShoppingResults) + 1,
+			Title:         it.Title,
+			Link:          link,
+			ProductID:     it.ProductID,
+			Price:         formatPrice(it.Price),
+			OriginalPrice: formatPrice(it.OldPrice),
+			Currency:      it.Currency,
+			Reviews:       it.ReviewsCount,
+			Source:        it.Seller,
+		}
+		if it.ProductRating != nil {
+			product.Rating = it.ProductRating.Value
+		}
+		normalized.ShoppingResults = append(normalized.ShoppingResults, product)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchVideos performs a video search (not supported by DataForSEO)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by DataForSEO")
+}
+
+// SearchReviews performs a reviews search (not supported by DataForSEO)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by DataForSEO")
+}
+
+// SearchScholar performs a scholar search (not supported by DataForSEO)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by DataForSEO")
+}
+
+// SearchLens performs a visual search (not supported by DataForSEO)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by DataForSEO")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by DataForSEO)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by DataForSEO")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by DataForSEO)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by DataForSEO")
+}
@@ -0,0 +1,265 @@
+package dataforseo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixtures of the Live endpoints and a shopping
+// task that is queued on its first poll, and records the task of the last
+// POST request and the number of polls
+func newTestServer(t *testing.T) (*Engine, *map[string]any, *int) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	fixtures := map[string][]byte{
+		organicPath:      fixture("organic.json"),
+		newsPath:         fixture("news.json"),
+		imagesPath:       fixture("images.json"),
+		mapsPath:         fixture("maps.json"),
+		productsPostPath: fixture("shopping_post.json"),
+	}
+	queued, shopping := fixture("shopping_queued.json"), fixture("shopping.json")
+
+	var task map[string]any
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if login, password, _ := r.BasicAuth(); login != "login" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status_code":40100,"status_message":"You are not authorized to access this resource."}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			var tasks []map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&tasks); err != nil || len(tasks) != 1 {
+				http.Error(w, "expected one task", http.StatusBadRequest)
+				return
+			}
+			task = tasks[0]
+		}
+		if strings.HasPrefix(r.URL.Path, productsGetPath) {
+			polls++
+			if polls == 1 {
+				_, _ = w.Write(queued)
+			} else {
+				_, _ = w.Write(shopping)
+			}
+			return
+		}
+		data, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithCredentials("login", "password")
+	if err != nil {
+		t.Fatalf("NewWithCredentials failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	engine.SetPollInterval(time.Millisecond)
+	return engine, &task, &polls
+}
+
+func TestSearch(t *testing.T) {
+	engine, task, _ := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", NumResults: 2, Page: 2, Country: "DE", Freshness: omniserp.FreshnessWeek}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	want := map[string]any{"keyword": "golang", "language_code": "en", "location_code": 2276.0, "depth": 4.0, "search_param": "&tbs=qdr:w"}
+	for name, value := range want {
+		if (*task)[name] != value {
+			t.Errorf("Expected task %s %v, got %v", name, value, (*task)[name])
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	// The second page of two results holds the third organic result only
+	if len(normalized.OrganicResults) != 1 {
+		t.Fatalf("Expected 1 organic result on page 2, got %d", len(normalized.OrganicResults))
+	}
+	if r := normalized.OrganicResults[0]; r.Position != 1 || r.Domain != "wikipedia.org" || r.Engine != engineName {
+		t.Errorf("Unexpected organic result: %+v", r)
+	}
+	if normalized.AnswerBox == nil || normalized.AnswerBox.Link != "https://go.dev/" {
+		t.Errorf("Expected the featured snippet as the answer box, got %+v", normalized.AnswerBox)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || normalized.PeopleAlsoAsk[0].Source != "go.dev" {
+		t.Errorf("Unexpected people also ask: %+v", normalized.PeopleAlsoAsk)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[1].Query != "golang generics" {
+		t.Errorf("Unexpected related searches: %+v", normalized.RelatedSearches)
+	}
+	meta := normalized.SearchMetadata
+	if meta.TotalResults != 2540000 || meta.CorrectedQuery != "golang" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+	if result.Response.RequestID != "10161234-1234-0066-0000-7d8b3c8f4b2a" {
+		t.Errorf("Expected the task ID as the request ID, got %q", result.Response.RequestID)
+	}
+}
+
+func TestSearchFirstPage(t *testing.T) {
+	engine, task, _ := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", Location: "Austin,Texas,United States", Extra: map[string]any{"device": "mobile"}}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if (*task)["location_name"] != params.Location || (*task)["device"] != "mobile" || (*task)["depth"] != 10.0 {
+		t.Errorf("Unexpected task %v", *task)
+	}
+	normalized := result.Data.(*omniserp.NormalizedSearchResult)
+	if len(normalized.OrganicResults) != 3 {
+		t.Fatalf("Expected 3 organic results, got %d", len(normalized.OrganicResults))
+	}
+	if second := normalized.OrganicResults[1]; second.Position != 2 || second.Date != "2026-01-02T00:00:00Z" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+}
+
+func TestLocationCode(t *testing.T) {
+	engine, task, _ := newTestServer(t)
+
+	// The location code replaces countries without a known code
+	params := omniserp.SearchParams{Query: "golang", Country: "xx", Extra: map[string]any{"location_code": "1023191"}}
+	if _, err := engine.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if (*task)["location_code"] != 1023191.0 {
+		t.Errorf("Expected the extra location code, got %v", (*task)["location_code"])
+	}
+
+	params.Extra = nil
+	if _, err := engine.Search(context.Background(), params); err == nil {
+		t.Error("Expected an error for a country without a location code")
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 2 {
+		t.Fatalf("Expected 2 news results, got %d", len(normalized.NewsResults))
+	}
+	if first := normalized.NewsResults[0]; first.Source != "Example News" || first.Date != "2026-02-10T09:30:00Z" {
+		t.Errorf("Unexpected first news result: %+v", first)
+	}
+	if second := normalized.NewsResults[1]; second.Source != "news.example.com" || second.Date != "3 days ago" {
+		t.Errorf("Expected the domain and the relative date, got %+v", second)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, task, _ := newTestServer(t)
+
+	result, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher", SafeSearch: true})
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+	if _, ok := (*task)["search_param"]; ok {
+		t.Errorf("Expected no Google parameters for images, got %v", *task)
+	}
+	images := result.Data.(*omniserp.NormalizedSearchResult).ImageResults
+	if len(images) != 1 || images[0].ImageURL != "https://go.dev/blog/gopher/header.jpg" || images[0].SourceURL != "https://go.dev/blog/gopher" {
+		t.Errorf("Unexpected image results: %+v", images)
+	}
+}
+
+func TestSearchMaps(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+
+	result, err := engine.SearchMaps(context.Background(), omniserp.SearchParams{Query: "coffee austin"})
+	if err != nil {
+		t.Fatalf("SearchMaps failed: %v", err)
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizePlaces(result, "coffee austin")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	if len(normalized.PlaceResults) != 1 {
+		t.Fatalf("Expected 1 place, got %d", len(normalized.PlaceResults))
+	}
+	place := normalized.PlaceResults[0]
+	if place.Rating != 4.6 || place.Reviews != 812 || place.Latitude != 30.2637 || place.Website != "https://gophercoffee.example.com/" {
+		t.Errorf("Unexpected place: %+v", place)
+	}
+}
+
+func TestSearchShopping(t *testing.T) {
+	engine, _, polls := newTestServer(t)
+
+	result, err := engine.SearchShopping(context.Background(), omniserp.SearchParams{Query: "gopher plush"})
+	if err != nil {
+		t.Fatalf("SearchShopping failed: %v", err)
+	}
+	if *polls != 2 {
+		t.Errorf("Expected to poll until the task was ready, got %d polls", *polls)
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeShopping(result, "gopher plush")
+	if err != nil {
+		t.Fatalf("NormalizeShopping failed: %v", err)
+	}
+	if len(normalized.ShoppingResults) != 1 {
+		t.Fatalf("Expected 1 product, got %d", len(normalized.ShoppingResults))
+	}
+	product := normalized.ShoppingResults[0]
+	if product.Price != "19.5" || product.OriginalPrice != "24.99" || product.Rating != 4.8 || product.Source != "Example Shop" {
+		t.Errorf("Unexpected product: %+v", product)
+	}
+}
+
+func TestSearchShoppingCancelled(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+	engine.SetPollInterval(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := engine.SearchShopping(ctx, omniserp.SearchParams{Query: "gopher plush"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to end the wait, got %v", err)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+	engine.password = "wrong"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
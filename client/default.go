@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/plexusone/omniserp"
+)
+
+// defaultClient is the lazily created client used by the package-level
+// search functions
+var defaultClient struct {
+	mu     sync.Mutex
+	client *Client
+}
+
+// Default returns the shared client used by the package-level search
+// functions, creating it on first use from the environment like New, but
+// without initialization logs. A failed creation is retried on the next call
+// so a script can set API keys and try again.
+func Default() (*Client, error) {
+	defaultClient.mu.Lock()
+	defer defaultClient.mu.Unlock()
+
+	if defaultClient.client == nil {
+		c, err := NewWithOptions(&Options{Silent: true})
+		if err != nil {
+			return nil, err
+		}
+		defaultClient.client = c
+	}
+	return defaultClient.client, nil
+}
+
+// SetDefault replaces the shared client used by the package-level search
+// functions; nil resets it so the next call creates one from the environment
+func SetDefault(c *Client) {
+	defaultClient.mu.Lock()
+	defer defaultClient.mu.Unlock()
+	defaultClient.client = c
+}
+
+// SearchOption customizes a package-level search. Options are applied in
+// order, so later options override earlier ones.
+//
+//	result, err := client.Search(ctx, "golang",
+//		client.WithCountry("us"),
+//		client.WithNum(20),
+//		client.WithFreshness(omniserp.FreshnessWeek),
+//		client.WithEngine("serpapi"))
+type SearchOption func(*searchOptions)
+
+// searchOptions are the parameters of a package-level search
+type searchOptions struct {
+	params omniserp.SearchParams
+	engine string
+}
+
+// WithParams sets all search parameters, replacing those set by earlier
+// options; the query argument of the search function takes precedence over
+// params.Query
+func WithParams(params omniserp.SearchParams) SearchOption {
+	return func(o *searchOptions) {
+		o.params = params
+	}
+}
+
+// WithLocation sets the search location, such as "Paris, France"
+func WithLocation(location string) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Location = location
+	}
+}
+
+// WithLanguage sets the search language, such as "en"
+func WithLanguage(language string) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Language = language
+	}
+}
+
+// WithCountry sets the search country code, such as "us"
+func WithCountry(country string) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Country = country
+	}
+}
+
+// WithLocale sets the country and language preset, such as "de-DE"; see
+// omniserp.Locales
+func WithLocale(locale string) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Locale = locale
+	}
+}
+
+// WithNum sets the number of results
+func WithNum(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.params.NumResults = n
+	}
+}
+
+// WithPage sets the results page, starting at 1
+func WithPage(page int) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Page = page
+	}
+}
+
+// WithFreshness limits results to those published within a recent period
+func WithFreshness(freshness omniserp.Freshness) SearchOption {
+	return func(o *searchOptions) {
+		o.params.Freshness = freshness
+	}
+}
+
+// WithExtra sets an engine-specific parameter; see omniserp.SearchParams.Extra
+func WithExtra(name string, value any) SearchOption {
+	return func(o *searchOptions) {
+		if o.params.Extra == nil {
+			o.params.Extra = make(map[string]any)
+		}
+		o.params.Extra[name] = value
+	}
+}
+
+// WithEngine runs the search on the named engine without failover instead of
+// the client's current or selected engine
+func WithEngine(name string) SearchOption {
+	return func(o *searchOptions) {
+		o.engine = name
+	}
+}
+
+// newSearchOptions applies opts to build a search for query
+func newSearchOptions(query string, opts []SearchOption) searchOptions {
+	var o searchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.params.Query = query
+	return o
+}
+
+// searchDefault runs a normalized operation with the default client
+func searchDefault(ctx context.Context, operation, query string, opts []SearchOption) (*omniserp.NormalizedSearchResult, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	o := newSearchOptions(query, opts)
+	return c.runNormalized(ctx, operation, o.engine, o.params)
+}
+
+// Search performs a normalized web search with the default client
+func Search(ctx context.Context, query string, opts ...SearchOption) (*omniserp.NormalizedSearchResult, error) {
+	return searchDefault(ctx, OpSearch, query, opts)
+}
+
+// SearchNews performs a normalized news search with the default client
+func SearchNews(ctx context.Context, query string, opts ...SearchOption) (*omniserp.NormalizedSearchResult, error) {
+	return searchDefault(ctx, OpSearchNews, query, opts)
+}
+
+// SearchImages performs a normalized image search with the default client
+func SearchImages(ctx context.Context, query string, opts ...SearchOption) (*omniserp.NormalizedSearchResult, error) {
+	return searchDefault(ctx, OpSearchImages, query, opts)
+}
+
+// SearchScholar performs a normalized scholar search with the default client
+func SearchScholar(ctx context.Context, query string, opts ...SearchOption) (*omniserp.NormalizedSearchResult, error) {
+	return searchDefault(ctx, OpSearchScholar, query, opts)
+}
+
+// Scrape scrapes a webpage with the default client
+func Scrape(ctx context.Context, url string) (*omniserp.NormalizedScrapeResult, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return c.ScrapeNormalized(ctx, omniserp.ScrapeParams{URL: url})
+}
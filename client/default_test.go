@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestPackageSearch(t *testing.T) {
+	var got omniserp.SearchParams
+	SetDefault(newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		got = params
+		return organicResponse("https://go.dev"), nil
+	}))
+	defer SetDefault(nil)
+
+	result, err := Search(context.Background(), "golang", WithParams(omniserp.SearchParams{Query: "ignored", Country: "us"}))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got.Query != "golang" || got.Country != "us" {
+		t.Errorf("Unexpected params: %+v", got)
+	}
+	if len(result.OrganicResults) != 1 || result.OrganicResults[0].Link != "https://go.dev" {
+		t.Errorf("Unexpected result: %+v", result.OrganicResults)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	var got omniserp.SearchParams
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://serper.example"), nil
+	}})
+	registry.Register(&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		got = params
+		organic := []any{map[string]any{"title": params.Query, "link": "https://serpapi.example"}}
+		return &omniserp.SearchResult{Data: map[string]any{"organic_results": organic}}, nil
+	}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	SetDefault(c)
+	defer SetDefault(nil)
+
+	result, err := Search(context.Background(), "golang",
+		WithCountry("us"),
+		WithNum(20),
+		WithFreshness(omniserp.FreshnessWeek),
+		WithExtra("device", "mobile"),
+		WithEngine("serpapi"))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	want := omniserp.SearchParams{Query: "golang", Country: "us", NumResults: 20, Freshness: omniserp.FreshnessWeek, Extra: map[string]any{"device": "mobile"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if link := result.OrganicResults[0].Link; link != "https://serpapi.example" {
+		t.Errorf("Expected the serpapi engine, got %s", link)
+	}
+
+	if _, err := Search(context.Background(), "golang", WithEngine("missing")); err == nil {
+		t.Error("Expected an error for an unknown engine")
+	}
+}
+
+func TestContextDefaults(t *testing.T) {
+	var got []omniserp.SearchParams
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		got = append(got, params)
+		return organicResponse("https://go.dev"), nil
+	})
+	ctx := omniserp.WithDefaults(context.Background(), omniserp.SearchParams{Language: "fr", SafeSearch: true})
+
+	if _, err := c.Search(ctx, omniserp.SearchParams{Query: "go"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "go", Language: "de"}); err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+
+	want := []omniserp.SearchParams{
+		{Query: "go", Language: "fr", SafeSearch: true},
+		{Query: "go", Language: "de", SafeSearch: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,381 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ErrNotRecorded is returned in replay mode for requests that have no
+// recorded response
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Default locale of deterministic runs, used when a request sets none so
+// results do not depend on the location of the caller's IP address
+const (
+	deterministicCountry  = "us"
+	deterministicLanguage = "en"
+)
+
+// ReplayMode selects whether a deterministic run calls the engine
+type ReplayMode int
+
+const (
+	// ModeRecord calls the engine and records every response
+	ModeRecord ReplayMode = iota
+
+	// ModeReplay serves recorded responses without calling the engine and
+	// fails requests that were not recorded with ErrNotRecorded
+	ModeReplay
+)
+
+// Deterministic configures reproducible runs, such as agent evaluations
+// comparing prompt variants, so they are not confounded by engine choice,
+// personalization, or results changing over time
+type Deterministic struct {
+	// Engine pins every request to this engine, bypassing failover and
+	// selection policies (default: the current engine)
+	Engine string
+
+	// Country and Language are used for requests that set none, instead of
+	// letting the engine infer them from the caller (default "us" and "en")
+	Country  string
+	Language string
+
+	// Store records responses with their request IDs in ModeRecord and
+	// serves them in ModeReplay. If nil, responses are not recorded.
+	Store RecordingStore
+
+	// Mode selects recording or replay
+	Mode ReplayMode
+}
+
+// RecordedResponse is one engine response of a deterministic run
+type RecordedResponse struct {
+	// Key identifies the request: operation, engine, and the fingerprint of
+	// the search parameters or the scraped URL
+	Key       string `json:"key"`
+	Operation string `json:"operation"`
+	Engine    string `json:"engine"`
+
+	// RequestID is the provider's ID of the request, if it reported one
+	RequestID  string    `json:"request_id,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+
+	// Raw is the raw response body and Data the parsed response; DataType
+	// tells how to decode Data: "" for parsed JSON, "search" for a
+	// normalized search result, or "scrape" for a scraped page
+	Raw      string          `json:"raw,omitempty"`
+	Data     json.RawMessage `json:"data"`
+	DataType string          `json:"data_type,omitempty"`
+
+	// Response is the HTTP response metadata, if any
+	Response *omniserp.ResponseMeta `json:"response,omitempty"`
+}
+
+// Data types of RecordedResponse
+const (
+	recordedSearch = "search"
+	recordedScrape = "scrape"
+)
+
+// newRecordedResponse records a result
+func newRecordedResponse(key, operation, engine string, result *omniserp.SearchResult) (*RecordedResponse, error) {
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	recorded := &RecordedResponse{
+		Key:        key,
+		Operation:  operation,
+		Engine:     engine,
+		RecordedAt: time.Now().UTC(),
+		Raw:        result.Raw,
+		Data:       data,
+		Response:   result.Response,
+	}
+	switch result.Data.(type) {
+	case *omniserp.NormalizedSearchResult:
+		recorded.DataType = recordedSearch
+	case *omniserp.NormalizedScrapeResult:
+		recorded.DataType = recordedScrape
+	}
+	if result.Response != nil {
+		recorded.RequestID = result.Response.RequestID
+	}
+	return recorded, nil
+}
+
+// Result decodes the recorded response
+func (r *RecordedResponse) Result() (*omniserp.SearchResult, error) {
+	var data any
+	switch r.DataType {
+	case recordedSearch:
+		data = &omniserp.NormalizedSearchResult{}
+	case recordedScrape:
+		data = &omniserp.NormalizedScrapeResult{}
+	}
+	if data != nil {
+		if err := json.Unmarshal(r.Data, data); err != nil {
+			return nil, fmt.Errorf("failed to decode recorded response: %w", err)
+		}
+	} else if err := json.Unmarshal(r.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode recorded response: %w", err)
+	}
+	return &omniserp.SearchResult{Data: data, Raw: r.Raw, Response: r.Response}, nil
+}
+
+// RecordingStore stores the responses of deterministic runs by key.
+// Implementations must be safe for concurrent use.
+type RecordingStore interface {
+	Get(key string) (*RecordedResponse, bool)
+	Put(response *RecordedResponse) error
+}
+
+// MemoryRecordingStore keeps recorded responses in memory
+type MemoryRecordingStore struct {
+	mu        sync.RWMutex
+	responses map[string]*RecordedResponse
+}
+
+// NewMemoryRecordingStore creates an empty in-memory recording store
+func NewMemoryRecordingStore() *MemoryRecordingStore {
+	return &MemoryRecordingStore{responses: make(map[string]*RecordedResponse)}
+}
+
+// Get implements RecordingStore
+func (s *MemoryRecordingStore) Get(key string) (*RecordedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	response, ok := s.responses[key]
+	return response, ok
+}
+
+// Put implements RecordingStore
+func (s *MemoryRecordingStore) Put(response *RecordedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[response.Key] = response
+	return nil
+}
+
+// FileRecordingStore appends recorded responses to a JSON Lines file, so a
+// recording can be committed alongside an evaluation and replayed later.
+// When a key is recorded more than once, the latest response is served.
+type FileRecordingStore struct {
+	path string
+	mem  *MemoryRecordingStore
+}
+
+// OpenRecordingStore loads the recording at path, which need not exist yet
+func OpenRecordingStore(path string) (*FileRecordingStore, error) {
+	store := &FileRecordingStore{path: path, mem: NewMemoryRecordingStore()}
+
+	// #nosec G304 -- recording path is provided by the caller
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var response RecordedResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s line %d: %w", path, line, err)
+		}
+		store.mem.responses[response.Key] = &response
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return store, nil
+}
+
+// Get implements RecordingStore
+func (s *FileRecordingStore) Get(key string) (*RecordedResponse, bool) {
+	return s.mem.Get(key)
+}
+
+// Put implements RecordingStore and appends the response to the file
+func (s *FileRecordingStore) Put(response *RecordedResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recorded response: %w", err)
+	}
+
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	s.mem.responses[response.Key] = response
+
+	// #nosec G304 -- recording path is provided by the caller
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return f.Close()
+}
+
+// SetDeterministic enables deterministic mode, or disables it if d is nil.
+// It returns an error if the pinned engine is not registered.
+func (c *Client) SetDeterministic(d *Deterministic) error {
+	if d == nil {
+		c.mu.Lock()
+		c.determinism = nil
+		c.mu.Unlock()
+		return nil
+	}
+	pinned := *d
+	if pinned.Engine == "" {
+		pinned.Engine = c.GetCurrentEngine().GetName()
+	}
+	if _, err := c.GetEngine(pinned.Engine); err != nil {
+		return err
+	}
+	if pinned.Country == "" {
+		pinned.Country = deterministicCountry
+	}
+	if pinned.Language == "" {
+		pinned.Language = deterministicLanguage
+	}
+	if pinned.Mode == ModeReplay && pinned.Store == nil {
+		return errors.New("replay mode requires a recording store")
+	}
+	c.mu.Lock()
+	c.determinism = &pinned
+	c.mu.Unlock()
+	return nil
+}
+
+// deterministic returns the settings of deterministic mode, or nil
+func (c *Client) deterministic() *Deterministic {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.determinism
+}
+
+// deterministicCandidates returns the pinned engine, wrapped to fix the
+// locale and record or replay responses, or none if it does not support the
+// operation
+func (c *Client) deterministicCandidates(d *Deterministic, operation string) []omniserp.Engine {
+	engine, ok := c.registry.Get(d.Engine)
+	if !ok || !slices.Contains(engine.GetSupportedTools(), operation) {
+		return nil
+	}
+	return []omniserp.Engine{&deterministicEngine{Engine: engine, d: d}}
+}
+
+// deterministicEngine fixes the locale of requests and records or replays
+// the responses of an engine
+type deterministicEngine struct {
+	omniserp.Engine
+	d *Deterministic
+}
+
+// search runs a search operation under the deterministic settings
+func (e *deterministicEngine) search(ctx context.Context, operation string, params omniserp.SearchParams, fn func(context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)) (*omniserp.SearchResult, error) {
+	if params.Country == "" {
+		params.Country = e.d.Country
+	}
+	if params.Language == "" {
+		params.Language = e.d.Language
+	}
+	return e.do(operation, params.Fingerprint(), func() (*omniserp.SearchResult, error) {
+		return fn(ctx, params)
+	})
+}
+
+// do replays the response recorded under the key of the request, or calls
+// the engine and records its response
+func (e *deterministicEngine) do(operation, id string, call func() (*omniserp.SearchResult, error)) (*omniserp.SearchResult, error) {
+	key := operation + "|" + e.GetName() + "|" + id
+	if e.d.Mode == ModeReplay {
+		recorded, ok := e.d.Store.Get(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotRecorded, key)
+		}
+		return recorded.Result()
+	}
+
+	result, err := call()
+	if err != nil || e.d.Store == nil {
+		return result, err
+	}
+	recorded, err := newRecordedResponse(key, operation, e.GetName(), result)
+	if err == nil {
+		err = e.d.Store.Put(recorded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	return result, nil
+}
+
+func (e *deterministicEngine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearch, params, e.Engine.Search)
+}
+
+func (e *deterministicEngine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchNews, params, e.Engine.SearchNews)
+}
+
+func (e *deterministicEngine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchImages, params, e.Engine.SearchImages)
+}
+
+func (e *deterministicEngine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchVideos, params, e.Engine.SearchVideos)
+}
+
+func (e *deterministicEngine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchPlaces, params, e.Engine.SearchPlaces)
+}
+
+func (e *deterministicEngine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchMaps, params, e.Engine.SearchMaps)
+}
+
+func (e *deterministicEngine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchReviews, params, e.Engine.SearchReviews)
+}
+
+func (e *deterministicEngine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchShopping, params, e.Engine.SearchShopping)
+}
+
+func (e *deterministicEngine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchScholar, params, e.Engine.SearchScholar)
+}
+
+func (e *deterministicEngine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchLens, params, e.Engine.SearchLens)
+}
+
+func (e *deterministicEngine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, OpSearchAutocomplete, params, e.Engine.SearchAutocomplete)
+}
+
+func (e *deterministicEngine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return e.do(OpScrapeWebpage, params.URL, func() (*omniserp.SearchResult, error) {
+		return e.Engine.ScrapeWebpage(ctx, params)
+	})
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestDeterministic(t *testing.T) {
+	calls := 0
+	var got omniserp.SearchParams
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		calls++
+		got = params
+		result := organicResponse("https://go.dev")
+		result.Response = &omniserp.ResponseMeta{StatusCode: 200, RequestID: "req-1"}
+		return result, nil
+	})
+	ctx := context.Background()
+
+	if err := c.SetDeterministic(&Deterministic{Engine: "bing"}); err == nil {
+		t.Error("Expected an error for an unknown engine")
+	}
+	if err := c.SetDeterministic(&Deterministic{Mode: ModeReplay}); err == nil {
+		t.Error("Expected an error for replay without a store")
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	store, err := OpenRecordingStore(path)
+	if err != nil {
+		t.Fatalf("OpenRecordingStore failed: %v", err)
+	}
+	if err := c.SetDeterministic(&Deterministic{Store: store}); err != nil {
+		t.Fatalf("SetDeterministic failed: %v", err)
+	}
+	recorded, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "go", Language: "de"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if got.Country != "us" || got.Language != "de" {
+		t.Errorf("Expected country us and language de, got %q and %q", got.Country, got.Language)
+	}
+	if _, err := c.runNormalized(ctx, OpSearch, "serpapi", omniserp.SearchParams{Query: "go"}); err == nil {
+		t.Error("Expected an error for an engine other than the pinned one")
+	}
+
+	// Replay from the file without calling the engine
+	store, err = OpenRecordingStore(path)
+	if err != nil {
+		t.Fatalf("OpenRecordingStore failed: %v", err)
+	}
+	if err := c.SetDeterministic(&Deterministic{Store: store, Mode: ModeReplay}); err != nil {
+		t.Fatalf("SetDeterministic failed: %v", err)
+	}
+	replayed, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "go", Language: "de"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 engine call, got %d", calls)
+	}
+	if len(replayed.OrganicResults) != 1 || replayed.OrganicResults[0].Link != recorded.OrganicResults[0].Link {
+		t.Errorf("Replayed results differ from the recording: %+v", replayed.OrganicResults)
+	}
+	if replayed.Raw == nil || replayed.Raw.Response == nil || replayed.Raw.Response.RequestID != "req-1" {
+		t.Errorf("Expected the recorded request ID, got %+v", replayed.Raw)
+	}
+
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "rust"}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded, got %v", err)
+	}
+
+	if err := c.SetDeterministic(nil); err != nil {
+		t.Fatalf("SetDeterministic failed: %v", err)
+	}
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "rust"}); err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if got.Country != "" {
+		t.Errorf("Expected no country after disabling deterministic mode, got %q", got.Country)
+	}
+}
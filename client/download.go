@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ImageManifestFile is the manifest file name written by DownloadImages
+const ImageManifestFile = "manifest.json"
+
+// ErrImageTooLarge is returned when an image exceeds DownloadOptions.MaxBytes
+var ErrImageTooLarge = errors.New("image exceeds maximum size")
+
+// DownloadOptions configures DownloadImages
+type DownloadOptions struct {
+	// Concurrency is the number of parallel downloads (default 4)
+	Concurrency int
+
+	// MaxBytes is the maximum image size in bytes (default 10 MiB)
+	MaxBytes int64
+
+	// AllowedTypes lists accepted content types such as "image/png"
+	// If empty, any "image/*" content type is accepted
+	AllowedTypes []string
+
+	// UseThumbnails downloads thumbnails instead of full-size images
+	UseThumbnails bool
+
+	// HTTPClient is used for downloads (default: omniserp.NewPublicClient,
+	// which refuses private addresses)
+	HTTPClient *http.Client
+}
+
+// DownloadedImage is a manifest entry for one image result
+type DownloadedImage struct {
+	Position    int    `json:"position"`
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url"`
+	SourceURL   string `json:"source_url,omitempty"`
+	File        string `json:"file,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	Bytes       int64  `json:"bytes,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty"` // URL of the first image with the same content
+	Error       string `json:"error,omitempty"`
+}
+
+// ImageManifest describes the outcome of a DownloadImages call
+type ImageManifest struct {
+	Directory    string            `json:"directory"`
+	DownloadedAt time.Time         `json:"downloaded_at"`
+	Images       []DownloadedImage `json:"images"`
+	Downloaded   int               `json:"downloaded"`
+	Duplicates   int               `json:"duplicates"`
+	Failed       int               `json:"failed"`
+}
+
+// DownloadImages concurrently downloads image results into dir, skipping
+// duplicate content (by SHA-256), enforcing size and type limits, and writing
+// a manifest.json describing every result. Files are named by content hash.
+// Downloads are written to temporary files that are renamed into place once
+// duplicates are known, so only the highest ranked copy of an image is kept
+// and files left incomplete by an interrupted run are replaced.
+func (c *Client) DownloadImages(ctx context.Context, results []omniserp.ImageResult, dir string, opts *DownloadOptions) (*ImageManifest, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = omniserp.NewPublicClient()
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	manifest := &ImageManifest{
+		Directory:    dir,
+		DownloadedAt: time.Now().UTC(),
+		Images:       make([]DownloadedImage, len(results)),
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+
+		// temps holds the temporary file of each downloaded image
+		temps = make([]string, len(results))
+	)
+
+	for i, result := range results {
+		imageURL := result.ImageURL
+		if opts.UseThumbnails && result.Thumbnail != "" {
+			imageURL = result.Thumbnail
+		}
+
+		manifest.Images[i] = DownloadedImage{
+			Position:  result.Position,
+			Title:     result.Title,
+			URL:       imageURL,
+			SourceURL: result.SourceURL,
+		}
+
+		wg.Add(1)
+		go func(entry *DownloadedImage, temp *string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				entry.Error = ctx.Err().Error()
+				return
+			}
+
+			data, contentType, err := fetchImage(ctx, httpClient, entry.URL, maxBytes, opts.AllowedTypes)
+			if err != nil {
+				entry.Error = err.Error()
+				return
+			}
+
+			sum := sha256.Sum256(data)
+			entry.SHA256 = hex.EncodeToString(sum[:])
+			entry.Bytes = int64(len(data))
+			entry.ContentType = contentType
+
+			path, err := writeTempFile(dir, data)
+			if err != nil {
+				entry.Error = fmt.Sprintf("failed to write file: %v", err)
+				return
+			}
+			*temp = path
+		}(&manifest.Images[i], &temps[i])
+	}
+	wg.Wait()
+
+	// Mark duplicates in result order so the highest ranked image is kept,
+	// and move the images that are kept into place
+	hashes := make(map[string]string)
+	for i := range manifest.Images {
+		entry := &manifest.Images[i]
+		if entry.Error != "" {
+			continue
+		}
+		if first, ok := hashes[entry.SHA256]; ok {
+			entry.DuplicateOf = first
+			_ = os.Remove(temps[i])
+			continue
+		}
+		hashes[entry.SHA256] = entry.URL
+
+		// Files are content-addressed, so a file of the same name holds the
+		// same image, unless it is incomplete, and is replaced
+		file := entry.SHA256[:16] + imageExtension(entry.ContentType)
+		if err := os.Rename(temps[i], filepath.Join(dir, file)); err != nil {
+			entry.Error = fmt.Sprintf("failed to write file: %v", err)
+			_ = os.Remove(temps[i])
+			continue
+		}
+		entry.File = file
+	}
+
+	for _, entry := range manifest.Images {
+		switch {
+		case entry.Error != "":
+			manifest.Failed++
+		case entry.DuplicateOf != "":
+			manifest.Duplicates++
+		default:
+			manifest.Downloaded++
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ImageManifestFile), data, 0o600); err != nil {
+		return manifest, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// fetchImage downloads an image, validating its content type and size
+func fetchImage(ctx context.Context, httpClient *http.Client, imageURL string, maxBytes int64, allowedTypes []string) ([]byte, string, error) {
+	if imageURL == "" {
+		return nil, "", fmt.Errorf("missing image URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// #nosec G704 -- image URLs come from search results by design
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download error: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, "", fmt.Errorf("%w: %d bytes", ErrImageTooLarge, resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, maxBytes)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(data)
+		contentType, _, _ = mime.ParseMediaType(contentType)
+	}
+	if !imageTypeAllowed(contentType, allowedTypes) {
+		return nil, "", fmt.Errorf("content type %q not allowed", contentType)
+	}
+
+	return data, contentType, nil
+}
+
+// writeTempFile writes data to a new temporary file in dir and returns its
+// path
+func writeTempFile(dir string, data []byte) (string, error) {
+	f, err := os.CreateTemp(dir, ".download-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// imageTypeAllowed reports whether contentType is accepted
+func imageTypeAllowed(contentType string, allowedTypes []string) bool {
+	if len(allowedTypes) == 0 {
+		return strings.HasPrefix(contentType, "image/")
+	}
+	for _, allowed := range allowedTypes {
+		if strings.EqualFold(allowed, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageExtension returns a file extension for an image content type
+func imageExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	case "image/avif":
+		return ".avif"
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".img"
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestDownloadImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n-image-a")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.png", "/copy-of-a.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		case "/big.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(make([]byte, 2048))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results := []omniserp.ImageResult{
+		{Position: 1, ImageURL: server.URL + "/a.png"},
+		{Position: 2, ImageURL: server.URL + "/copy-of-a.png"},
+		{Position: 3, ImageURL: server.URL + "/big.png"},
+		{Position: 4, ImageURL: server.URL + "/page.html"},
+		{Position: 5, ImageURL: server.URL + "/missing.png"},
+	}
+
+	dir := t.TempDir()
+	c := &Client{}
+	manifest, err := c.DownloadImages(context.Background(), results, dir, &DownloadOptions{MaxBytes: 1024, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("DownloadImages failed: %v", err)
+	}
+
+	if manifest.Downloaded != 1 || manifest.Duplicates != 1 || manifest.Failed != 3 {
+		t.Errorf("Unexpected counts: downloaded=%d duplicates=%d failed=%d",
+			manifest.Downloaded, manifest.Duplicates, manifest.Failed)
+	}
+
+	first := manifest.Images[0]
+	if first.File == "" || filepath.Ext(first.File) != ".png" {
+		t.Errorf("Expected first image saved as .png, got %q", first.File)
+	}
+	if manifest.Images[1].DuplicateOf != first.URL {
+		t.Errorf("Expected second image to be duplicate of %s, got %q", first.URL, manifest.Images[1].DuplicateOf)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ImageManifestFile)); err != nil {
+		t.Errorf("Manifest not written: %v", err)
+	}
+}
+
+func TestDownloadImagesFiles(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n-image-a")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The same bytes with two content types
+		if r.URL.Path == "/a.gif" {
+			w.Header().Set("Content-Type", "image/gif")
+		} else {
+			w.Header().Set("Content-Type", "image/png")
+		}
+		_, _ = w.Write(png)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(png)
+	file := hex.EncodeToString(sum[:])[:16] + ".png"
+	dir := t.TempDir()
+	// A file left incomplete by an interrupted run
+	if err := os.WriteFile(filepath.Join(dir, file), png[:4], 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	results := []omniserp.ImageResult{
+		{Position: 1, ImageURL: server.URL + "/a.png"},
+		{Position: 2, ImageURL: server.URL + "/a.gif"},
+	}
+	manifest, err := (&Client{}).DownloadImages(context.Background(), results, dir, &DownloadOptions{HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("DownloadImages failed: %v", err)
+	}
+	if manifest.Downloaded != 1 || manifest.Duplicates != 1 || manifest.Images[0].File != file {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{file, ImageManifestFile}; !slices.Equal(names, want) {
+		t.Errorf("Expected only %v, got %v", want, names)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, file)); err != nil || !bytes.Equal(data, png) {
+		t.Errorf("Expected the incomplete file to be replaced, got %q, %v", data, err)
+	}
+}
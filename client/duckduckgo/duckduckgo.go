@@ -0,0 +1,639 @@
+// Package duckduckgo implements the omniserp.Engine interface for
+// DuckDuckGo, which needs no API key. Web searches combine the Instant
+// Answer API, for answers, abstracts, and related topics, with the HTML
+// endpoint for the web results; news searches use the news endpoint of the
+// DuckDuckGo site. DuckDuckGo rate limits automated clients, so it is best
+// suited as a free fallback for basic searches.
+package duckduckgo
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	engineName    = "duckduckgo"
+	engineVersion = "1.0.0"
+
+	instantAnswerPath = "/"
+	htmlPath          = "/html/"
+	tokenPath         = "/"
+	newsPath          = "/news.js"
+
+	// userAgent is sent with every request; the HTML endpoint rejects the
+	// default Go user agent
+	userAgent = "Mozilla/5.0 (compatible; omniserp/" + engineVersion + ")"
+)
+
+// Default endpoints of the DuckDuckGo services
+const (
+	defaultAPIURL  = "https://api.duckduckgo.com"
+	defaultHTMLURL = "https://html.duckduckgo.com"
+	defaultSiteURL = "https://duckduckgo.com"
+)
+
+// defaultPageSize is the number of web results per page when
+// SearchParams.NumResults is not set
+const defaultPageSize = 10
+
+// ErrRateLimited is returned when DuckDuckGo answers with a challenge page
+// instead of results because it considers the client automated
+var ErrRateLimited = errors.New("duckduckgo rejected the request as automated")
+
+// Engine implements the omniserp.Engine interface for DuckDuckGo. Results
+// are normalized by the engine and returned as the Data of each search
+// result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiURL  string
+	htmlURL string
+	siteURL string
+	client  *http.Client
+}
+
+// New creates a new DuckDuckGo engine. It needs no configuration; the error
+// is returned for symmetry with the other engines and is always nil.
+func New() (*Engine, error) {
+	return &Engine{
+		apiURL:  defaultAPIURL,
+		htmlURL: defaultHTMLURL,
+		siteURL: defaultSiteURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL sends all requests to one host instead of the DuckDuckGo
+// services, for testing or a proxy
+func (e *Engine) SetBaseURL(u string) {
+	u = strings.TrimSuffix(u, "/")
+	e.apiURL, e.htmlURL, e.siteURL = u, u, u
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. DuckDuckGo has no
+// location parameter and selects the market by region; the number of results
+// is applied by truncation.
+func (e *Engine) SupportedParams(operation string) []string {
+	if operation == "google_search_news" {
+		// The news endpoint returns a single page
+		return []string{
+			omniserp.ParamQuery,
+			omniserp.ParamLanguage,
+			omniserp.ParamCountry,
+			omniserp.ParamNumResults,
+			omniserp.ParamFreshness,
+			omniserp.ParamSafeSearch,
+		}
+	}
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// region returns the DuckDuckGo region of the country and language, such as
+// "us-en", or "wt-wt" for no region. Without a language, the country code is
+// used as the language code, as in "de-de".
+func region(params omniserp.SearchParams) string {
+	country := strings.ToLower(params.Country)
+	language := strings.ToLower(params.Language)
+	switch {
+	case country == "":
+		return "wt-wt"
+	case country == "gb":
+		// DuckDuckGo uses "uk" for the United Kingdom
+		country, language = "uk", cmp.Or(language, "en")
+	}
+	return country + "-" + cmp.Or(language, country)
+}
+
+// freshness returns the DuckDuckGo date filter; there is no hourly filter
+func freshness(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour, omniserp.FreshnessDay:
+		return "d"
+	case omniserp.FreshnessWeek:
+		return "w"
+	case omniserp.FreshnessMonth:
+		return "m"
+	case omniserp.FreshnessYear:
+		return "y"
+	}
+	return ""
+}
+
+// get performs a GET request and returns the response body
+func (e *Engine) get(ctx context.Context, reqURL string) ([]byte, *omniserp.ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	start := time.Now()
+	// #nosec G704 -- request to the DuckDuckGo services or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		// The HTML endpoint answers automated clients with a 202 challenge
+		return nil, meta, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+	return body, meta, nil
+}
+
+// instantAnswer is the JSON response of the Instant Answer API
+type instantAnswer struct {
+	Heading        string          `json:"Heading"`
+	AbstractText   string          `json:"AbstractText"`
+	AbstractSource string          `json:"AbstractSource"`
+	AbstractURL    string          `json:"AbstractURL"`
+	Image          string          `json:"Image"`
+	Answer         json.RawMessage `json:"Answer"`
+	Definition     string          `json:"Definition"`
+	DefinitionURL  string          `json:"DefinitionURL"`
+	Entity         string          `json:"Entity"`
+	Results        []topic         `json:"Results"`
+	RelatedTopics  []topic         `json:"RelatedTopics"`
+}
+
+// topic is an Instant Answer result or related topic, or a group of topics
+type topic struct {
+	FirstURL string  `json:"FirstURL"`
+	Text     string  `json:"Text"`
+	Result   string  `json:"Result"`
+	Name     string  `json:"Name"`
+	Topics   []topic `json:"Topics"`
+}
+
+// answer returns the instant answer, which is a string or, for interactive
+// answers, an object
+func (a *instantAnswer) answer() string {
+	var text string
+	if json.Unmarshal(a.Answer, &text) == nil {
+		return text
+	}
+	return ""
+}
+
+// Search performs a general web search. The Instant Answer API provides
+// the answer box, knowledge graph, and related searches of the first page,
+// and the HTML endpoint the organic results. If the HTML endpoint fails, the
+// instant answer is returned on its own when it has any content.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	normalized := newNormalized(params)
+
+	// Instant answers are not paged
+	var answerErr error
+	var raw string
+	var meta *omniserp.ResponseMeta
+	if params.Page <= 1 {
+		var answer *instantAnswer
+		answer, raw, meta, answerErr = e.instantAnswer(ctx, params)
+		if answerErr == nil {
+			applyInstantAnswer(normalized, answer, e.siteURL)
+		}
+	}
+
+	body, htmlMeta, err := e.get(ctx, e.htmlURL+htmlPath+"?"+e.htmlParams(params).Encode())
+	if err == nil {
+		results := parseHTMLResults(string(body))
+		if len(results) == 0 && strings.Contains(string(body), "anomaly") {
+			err = ErrRateLimited
+		} else {
+			normalized.OrganicResults = mergeOrganic(normalized.OrganicResults, results, pageSize(params))
+			raw, meta = string(body), htmlMeta
+		}
+	}
+	if err != nil && (answerErr != nil || !hasContent(normalized)) {
+		return nil, errors.Join(err, answerErr)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// instantAnswer queries the Instant Answer API
+func (e *Engine) instantAnswer(ctx context.Context, params omniserp.SearchParams) (*instantAnswer, string, *omniserp.ResponseMeta, error) {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	q.Set("format", "json")
+	q.Set("no_html", "1")
+	q.Set("no_redirect", "1")
+	q.Set("skip_disambig", "1")
+	q.Set("kl", region(params))
+
+	body, meta, err := e.get(ctx, e.apiURL+instantAnswerPath+"?"+q.Encode())
+	if err != nil {
+		return nil, "", meta, err
+	}
+	var answer instantAnswer
+	if err := json.Unmarshal(body, &answer); err != nil {
+		return nil, "", meta, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &answer, string(body), meta, nil
+}
+
+// htmlParams converts SearchParams to HTML endpoint query parameters
+func (e *Engine) htmlParams(params omniserp.SearchParams) url.Values {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	q.Set("kl", region(params))
+	if params.Page > 1 {
+		q.Set("s", strconv.Itoa((params.Page-1)*pageSize(params)))
+	}
+	if df := freshness(params.Freshness); df != "" {
+		q.Set("df", df)
+	}
+	if params.SafeSearch {
+		q.Set("kp", "1")
+	}
+	return q
+}
+
+// pageSize returns the number of results per page
+func pageSize(params omniserp.SearchParams) int {
+	if params.NumResults > 0 {
+		return params.NumResults
+	}
+	return defaultPageSize
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:   engineName,
+			Query:    params.Query,
+			Language: params.Language,
+			Country:  params.Country,
+		},
+	}
+}
+
+// applyInstantAnswer adds the answer, abstract, official sites, and related
+// topics of an instant answer to a normalized result
+func applyInstantAnswer(normalized *omniserp.NormalizedSearchResult, answer *instantAnswer, siteURL string) {
+	if text := answer.answer(); text != "" {
+		normalized.AnswerBox = &omniserp.AnswerBox{Answer: text}
+	} else if answer.Definition != "" {
+		normalized.AnswerBox = &omniserp.AnswerBox{Answer: answer.Definition, Link: answer.DefinitionURL}
+	}
+
+	if answer.AbstractText != "" {
+		graph := &omniserp.KnowledgeGraph{
+			Title:       answer.Heading,
+			Type:        answer.Entity,
+			Description: answer.AbstractText,
+			Source:      answer.AbstractSource,
+		}
+		if answer.AbstractURL != "" {
+			graph.Attributes = map[string]string{"source_url": answer.AbstractURL}
+		}
+		if answer.Image != "" {
+			graph.ImageURL = answer.Image
+			if strings.HasPrefix(graph.ImageURL, "/") {
+				graph.ImageURL = siteURL + graph.ImageURL
+			}
+		}
+		normalized.KnowledgeGraph = graph
+	}
+
+	// Results are the official sites of the topic
+	for _, r := range answer.Results {
+		if r.FirstURL == "" {
+			continue
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: len(normalized.OrganicResults) + 1,
+			Title:    topicTitle(r),
+			Link:     r.FirstURL,
+			URL:      r.FirstURL,
+		})
+	}
+
+	for _, r := range flattenTopics(answer.RelatedTopics) {
+		if title := topicTitle(r); title != "" {
+			normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: title})
+		}
+	}
+}
+
+// flattenTopics returns the topics of groups in place of the groups
+func flattenTopics(topics []topic) []topic {
+	var flat []topic
+	for _, t := range topics {
+		if len(t.Topics) > 0 {
+			flat = append(flat, flattenTopics(t.Topics)...)
+		} else {
+			flat = append(flat, t)
+		}
+	}
+	return flat
+}
+
+// topicTitle returns the link text of a topic, falling back to its text
+func topicTitle(t topic) string {
+	if m := anchorText.FindStringSubmatch(t.Result); m != nil {
+		if title := cleanText(m[1]); title != "" {
+			return title
+		}
+	}
+	return cleanText(t.Text)
+}
+
+// hasContent reports whether a result has anything to return
+func hasContent(normalized *omniserp.NormalizedSearchResult) bool {
+	return len(normalized.OrganicResults) > 0 || normalized.AnswerBox != nil || normalized.KnowledgeGraph != nil
+}
+
+// mergeOrganic appends the web results not already in organic, by link, up
+// to limit results in total
+func mergeOrganic(organic, results []omniserp.OrganicResult, limit int) []omniserp.OrganicResult {
+	seen := make(map[string]bool, len(organic))
+	for _, r := range organic {
+		seen[omniserp.CanonicalURL(r.Link)] = true
+	}
+	for _, r := range results {
+		if len(organic) >= limit {
+			break
+		}
+		key := omniserp.CanonicalURL(r.Link)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		r.Position = len(organic) + 1
+		organic = append(organic, r)
+	}
+	return organic
+}
+
+var (
+	// resultStart matches the start of a result block of the HTML endpoint
+	resultStart = regexp.MustCompile(`<div class="result[ "]`)
+
+	// resultLink matches the title link of a result
+	resultLink = regexp.MustCompile(`(?s)<a[^>]*class="result__a"[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+
+	// resultSnippet matches the snippet of a result
+	resultSnippet = regexp.MustCompile(`(?s)class="result__snippet"[^>]*>(.*?)</(?:a|div|td)>`)
+
+	// anchorText matches the text of the first link of a fragment
+	anchorText = regexp.MustCompile(`(?s)<a[^>]*>(.*?)</a>`)
+
+	// tag matches any tag
+	tag = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// parseHTMLResults extracts the web results of an HTML endpoint page,
+// skipping ads
+func parseHTMLResults(page string) []omniserp.OrganicResult {
+	var results []omniserp.OrganicResult
+	starts := resultStart.FindAllStringIndex(page, -1)
+	for i, start := range starts {
+		end := len(page)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		block := page[start[0]:end]
+		if strings.Contains(block[:strings.Index(block, ">")+1], "result--ad") {
+			continue
+		}
+		link := resultLink.FindStringSubmatch(block)
+		if link == nil {
+			continue
+		}
+		target := resultURL(link[1])
+		if target == "" {
+			continue
+		}
+		r := omniserp.OrganicResult{
+			Position: len(results) + 1,
+			Title:    cleanText(link[2]),
+			Link:     target,
+			URL:      target,
+		}
+		if snippet := resultSnippet.FindStringSubmatch(block); snippet != nil {
+			r.Snippet = cleanText(snippet[1])
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// resultURL returns the target of a result link, which is usually a
+// DuckDuckGo redirect such as "//duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev"
+func resultURL(href string) string {
+	href = html.UnescapeString(href)
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || strings.HasSuffix(u.Host, "duckduckgo.com") {
+		// Ad and internal links
+		return ""
+	}
+	return href
+}
+
+// cleanText removes tags and entities and collapses whitespace
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tag.ReplaceAllString(s, ""))), " ")
+}
+
+// vqdPattern matches the query token embedded in DuckDuckGo pages
+var vqdPattern = regexp.MustCompile(`vqd=["']?([0-9-]+)`)
+
+// newsResponse is the JSON response of the news endpoint
+type newsResponse struct {
+	Results []struct {
+		Date         int64  `json:"date"`
+		Excerpt      string `json:"excerpt"`
+		Image        string `json:"image"`
+		RelativeTime string `json:"relative_time"`
+		Source       string `json:"source"`
+		Title        string `json:"title"`
+		URL          string `json:"url"`
+	} `json:"results"`
+}
+
+// SearchNews performs a news search. The news endpoint needs a query token,
+// which is read from the search page first.
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	token, err := e.token(ctx, params.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("q", params.Query)
+	q.Set("vqd", token)
+	q.Set("l", region(params))
+	q.Set("o", "json")
+	q.Set("noamp", "1")
+	if df := freshness(params.Freshness); df != "" {
+		q.Set("df", df)
+	}
+	if params.SafeSearch {
+		q.Set("p", "1")
+	}
+	body, meta, err := e.get(ctx, e.siteURL+newsPath+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var parsed newsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	normalized := newNormalized(params)
+	for _, r := range parsed.Results {
+		if params.NumResults > 0 && len(normalized.NewsResults) == params.NumResults {
+			break
+		}
+		news := omniserp.NewsResult{
+			Position: len(normalized.NewsResults) + 1,
+			Title:    cleanText(r.Title),
+			Link:     r.URL,
+			Source:   r.Source,
+			Date:     r.RelativeTime,
+			Snippet:  cleanText(r.Excerpt),
+			ImageURL: r.Image,
+		}
+		if r.Date > 0 {
+			news.Date = time.Unix(r.Date, 0).UTC().Format(time.RFC3339)
+		}
+		normalized.NewsResults = append(normalized.NewsResults, news)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: string(body), Response: meta}, nil
+}
+
+// token reads the query token of the news endpoint from the search page
+func (e *Engine) token(ctx context.Context, query string) (string, error) {
+	q := url.Values{}
+	q.Set("q", query)
+	q.Set("ia", "news")
+	body, _, err := e.get(ctx, e.siteURL+tokenPath+"?"+q.Encode())
+	if err != nil {
+		return "", err
+	}
+	m := vqdPattern.FindSubmatch(body)
+	if m == nil {
+		return "", ErrRateLimited
+	}
+	return string(m[1]), nil
+}
+
+// SearchImages performs an image search (not supported by DuckDuckGo)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by DuckDuckGo")
+}
+
+// SearchVideos performs a video search (not supported by DuckDuckGo)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by DuckDuckGo")
+}
+
+// SearchPlaces performs a places search (not supported by DuckDuckGo)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by DuckDuckGo")
+}
+
+// SearchMaps performs a maps search (not supported by DuckDuckGo)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by DuckDuckGo")
+}
+
+// SearchReviews performs a reviews search (not supported by DuckDuckGo)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by DuckDuckGo")
+}
+
+// SearchShopping performs a shopping search (not supported by DuckDuckGo)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by DuckDuckGo")
+}
+
+// SearchScholar performs a scholar search (not supported by DuckDuckGo)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by DuckDuckGo")
+}
+
+// SearchLens performs a visual search (not supported by DuckDuckGo)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by DuckDuckGo")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by DuckDuckGo)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by DuckDuckGo")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by DuckDuckGo)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by DuckDuckGo")
+}
@@ -0,0 +1,233 @@
+package duckduckgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixtures of the Instant Answer API, the HTML
+// endpoint, and the news endpoint, answering the HTML endpoint with
+// htmlStatus, and records the query of each path
+func newTestServer(t *testing.T, htmlStatus int) (*httptest.Server, map[string]url.Values) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	answer, page, news := fixture("instant_answer.json"), fixture("search.html"), fixture("news.json")
+
+	var mu sync.Mutex
+	queries := make(map[string]url.Values)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("Unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		query := r.URL.Query()
+		key := r.URL.Path
+		if key == "/" {
+			key += query.Get("format") + query.Get("ia")
+		}
+		mu.Lock()
+		queries[key] = query
+		mu.Unlock()
+
+		switch key {
+		case "/json":
+			_, _ = w.Write(answer)
+		case htmlPath:
+			w.WriteHeader(htmlStatus)
+			_, _ = w.Write(page)
+		case "/news":
+			_, _ = w.Write([]byte(`<script>DDG.deep.initialize('/d.js?q=golang&vqd="4-123456789"');</script>`))
+		case newsPath:
+			_, _ = w.Write(news)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, queries
+}
+
+// newTestEngine creates an engine for the test server
+func newTestEngine(t *testing.T, srv *httptest.Server) *Engine {
+	t.Helper()
+	engine, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine
+}
+
+func TestSearch(t *testing.T) {
+	srv, queries := newTestServer(t, http.StatusOK)
+	engine := newTestEngine(t, srv)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Language:   "en",
+		Country:    "us",
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{"q": "golang", "kl": "us-en", "df": "w", "kp": "1"}
+	for name, value := range want {
+		if got := queries[htmlPath].Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+	if got := queries["/json"].Get("kl"); got != "us-en" {
+		t.Errorf("Expected the instant answer region us-en, got %q", got)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	links := []string{"https://go.dev/", "https://github.com/golang/go", "https://en.wikipedia.org/wiki/Go_(programming_language)"}
+	if len(normalized.OrganicResults) != len(links) {
+		t.Fatalf("Expected %d organic results without ads and duplicates, got %+v", len(links), normalized.OrganicResults)
+	}
+	for i, link := range links {
+		if r := normalized.OrganicResults[i]; r.Link != link || r.Position != i+1 || r.Engine != engineName {
+			t.Errorf("Result %d: expected %s at position %d, got %+v", i, link, i+1, r)
+		}
+	}
+	if first := normalized.OrganicResults[0]; first.Title != "Official site" {
+		t.Errorf("Expected the official site from the instant answer first, got %+v", first)
+	}
+	if second := normalized.OrganicResults[1]; second.Snippet != "The Go programming language. Contribute to golang/go development by creating an account on GitHub." {
+		t.Errorf("Unexpected snippet %q", second.Snippet)
+	}
+	if third := normalized.OrganicResults[2]; third.Snippet != "Go is a statically typed & compiled language." {
+		t.Errorf("Expected entities to be decoded, got %q", third.Snippet)
+	}
+
+	graph := normalized.KnowledgeGraph
+	if graph == nil || graph.Title != "Go (programming language)" || graph.Source != "Wikipedia" || graph.ImageURL != srv.URL+"/i/1b9fc3a8.png" {
+		t.Errorf("Unexpected knowledge graph: %+v", graph)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[1].Query != "Erlang" {
+		t.Errorf("Expected related searches from grouped topics, got %+v", normalized.RelatedSearches)
+	}
+}
+
+func TestSearchFallback(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusAccepted)
+	engine := newTestEngine(t, srv)
+
+	result, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("Expected the instant answer despite the failed HTML endpoint, got %v", err)
+	}
+	normalized := result.Data.(*omniserp.NormalizedSearchResult)
+	if len(normalized.OrganicResults) != 1 || normalized.KnowledgeGraph == nil {
+		t.Errorf("Expected only the instant answer, got %+v", normalized)
+	}
+
+	// Later pages have no instant answer to fall back to
+	_, err = engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", Page: 2})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected a 202 APIError, got %v", err)
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	srv, queries := newTestServer(t, http.StatusOK)
+	engine := newTestEngine(t, srv)
+
+	result, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", NumResults: 2, Page: 3})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, ok := queries["/json"]; ok {
+		t.Error("Expected no instant answer for a later page")
+	}
+	if got := queries[htmlPath].Get("s"); got != "4" {
+		t.Errorf("Expected the offset 4, got %q", got)
+	}
+	if normalized := result.Data.(*omniserp.NormalizedSearchResult); len(normalized.OrganicResults) != 2 {
+		t.Errorf("Expected NumResults to limit the results to 2, got %d", len(normalized.OrganicResults))
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	srv, queries := newTestServer(t, http.StatusOK)
+	engine := newTestEngine(t, srv)
+
+	params := omniserp.SearchParams{Query: "golang", Country: "gb", NumResults: 1, Freshness: omniserp.FreshnessDay}
+	result, err := engine.SearchNews(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+
+	want := map[string]string{"q": "golang", "vqd": "4-123456789", "l": "uk-en", "o": "json", "df": "d"}
+	for name, value := range want {
+		if got := queries[newsPath].Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 1 {
+		t.Fatalf("Expected NumResults to limit the results to 1, got %d", len(normalized.NewsResults))
+	}
+	news := normalized.NewsResults[0]
+	if news.Link != "https://go.dev/blog/go1.26" || news.Source != "Go Blog" || news.Date != "2026-01-01T00:00:00Z" {
+		t.Errorf("Unexpected news result: %+v", news)
+	}
+	if news.Snippet != "The Go team announced the release of Go 1.26." {
+		t.Errorf("Expected the snippet without tags, got %q", news.Snippet)
+	}
+}
+
+func TestSearchNewsWithoutToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>anomaly</body></html>`))
+	}))
+	defer srv.Close()
+
+	engine := newTestEngine(t, srv)
+	if _, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		country, language, want string
+	}{
+		{"", "", "wt-wt"},
+		{"", "en", "wt-wt"},
+		{"us", "en", "us-en"},
+		{"DE", "", "de-de"},
+		{"gb", "", "uk-en"},
+	}
+	for _, tt := range tests {
+		if got := region(omniserp.SearchParams{Country: tt.country, Language: tt.language}); got != tt.want {
+			t.Errorf("region(%q, %q) = %q, want %q", tt.country, tt.language, got, tt.want)
+		}
+	}
+}
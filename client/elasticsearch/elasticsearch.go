@@ -0,0 +1,548 @@
+// Package elasticsearch implements the omniserp.Engine interface for an
+// internal Elasticsearch or OpenSearch index, so a private corpus can be
+// searched with the same client and MCP tools as the web engines. Hits are
+// returned as organic results; Fields maps the fields of the indexed
+// documents to those of the results.
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	engineName    = "elasticsearch"
+	engineVersion = "1.0.0"
+
+	// defaultNumResults is the number of hits of searches without one
+	defaultNumResults = 10
+
+	// snippetRunes is the length of snippets taken from the content field
+	// of hits without highlights
+	snippetRunes = 300
+)
+
+// Fields maps the fields of the indexed documents to the fields of organic
+// results. Empty fields use the defaults of DefaultFields, except Language,
+// which disables the language filter.
+type Fields struct {
+	// Title is the document title, searched with a boost
+	Title string `json:"title"`
+
+	// URL is the link of the document
+	URL string `json:"url"`
+
+	// Content is the searched document text, highlighted for snippets
+	Content string `json:"content"`
+
+	// Date is the publication date, filtered by the freshness
+	Date string `json:"date"`
+
+	// Language is a keyword field with the language code of the document,
+	// filtered by the language of searches
+	Language string `json:"language,omitempty"`
+}
+
+// DefaultFields are the document fields used unless configured
+var DefaultFields = Fields{
+	Title:   "title",
+	URL:     "url",
+	Content: "content",
+	Date:    "date",
+}
+
+// ParseFields parses a field mapping such as "title=headline,content=body",
+// as in the ELASTICSEARCH_FIELDS env var. Unmapped fields keep their
+// defaults.
+func ParseFields(s string) (Fields, error) {
+	fields := DefaultFields
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, field, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return Fields{}, fmt.Errorf("invalid field mapping %q, expected name=field", pair)
+		}
+		switch strings.TrimSpace(name) {
+		case "title":
+			fields.Title = field
+		case "url":
+			fields.URL = field
+		case "content":
+			fields.Content = field
+		case "date":
+			fields.Date = field
+		case "language":
+			fields.Language = field
+		default:
+			return Fields{}, fmt.Errorf("unknown field %q (available: title, url, content, date, language)", name)
+		}
+	}
+	return fields, nil
+}
+
+// Engine implements the omniserp.Engine interface for an Elasticsearch or
+// OpenSearch index. Results are normalized by the engine and returned as
+// the Data of each search result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	baseURL string
+	index   string
+	fields  Fields
+	client  *http.Client
+
+	// Credentials: an API key (Elasticsearch) or a username and password
+	apiKey   string
+	username string
+	password string
+}
+
+// New creates a new engine for the ELASTICSEARCH_INDEX index of the
+// cluster at the ELASTICSEARCH_URL env var. ELASTICSEARCH_API_KEY, or
+// ELASTICSEARCH_USERNAME and ELASTICSEARCH_PASSWORD, authenticate the
+// requests, and ELASTICSEARCH_FIELDS maps the document fields (see
+// ParseFields). ELASTICSEARCH_CA_CERT names the CA bundle verifying the
+// cluster, and ELASTICSEARCH_CLIENT_CERT and ELASTICSEARCH_CLIENT_KEY a PEM
+// client certificate and key.
+func New() (*Engine, error) {
+	baseURL := os.Getenv("ELASTICSEARCH_URL")
+	if baseURL == "" {
+		return nil, fmt.Errorf("ELASTICSEARCH_URL environment variable is required")
+	}
+	index := os.Getenv("ELASTICSEARCH_INDEX")
+	if index == "" {
+		return nil, fmt.Errorf("ELASTICSEARCH_INDEX environment variable is required")
+	}
+	engine, err := NewWithURL(baseURL, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping := os.Getenv("ELASTICSEARCH_FIELDS"); mapping != "" {
+		fields, err := ParseFields(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ELASTICSEARCH_FIELDS: %w", err)
+		}
+		engine.SetFields(fields)
+	}
+	if apiKey := os.Getenv("ELASTICSEARCH_API_KEY"); apiKey != "" {
+		engine.SetAPIKey(apiKey)
+	} else if username := os.Getenv("ELASTICSEARCH_USERNAME"); username != "" {
+		engine.SetBasicAuth(username, os.Getenv("ELASTICSEARCH_PASSWORD"))
+	}
+
+	tlsConfig := omniserp.TransportConfig{
+		CertFile: os.Getenv("ELASTICSEARCH_CLIENT_CERT"),
+		KeyFile:  os.Getenv("ELASTICSEARCH_CLIENT_KEY"),
+		CAFile:   os.Getenv("ELASTICSEARCH_CA_CERT"),
+	}
+	if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" || tlsConfig.CAFile != "" {
+		transport, err := tlsConfig.NewTransport()
+		if err != nil {
+			return nil, fmt.Errorf("invalid Elasticsearch TLS configuration: %w", err)
+		}
+		engine.SetTransport(transport)
+	}
+	return engine, nil
+}
+
+// NewWithURL creates a new engine for an index, or a comma-separated list of
+// indexes or patterns, of the cluster at baseURL
+func NewWithURL(baseURL, index string) (*Engine, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid Elasticsearch URL: %w", err)
+	}
+	if strings.TrimSpace(index) == "" {
+		return nil, fmt.Errorf("index is required")
+	}
+	return &Engine{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		index:   index,
+		fields:  DefaultFields,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the cluster URL
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetFields sets the mapping of document fields; empty fields use the
+// defaults
+func (e *Engine) SetFields(fields Fields) {
+	if fields.Title == "" {
+		fields.Title = DefaultFields.Title
+	}
+	if fields.URL == "" {
+		fields.URL = DefaultFields.URL
+	}
+	if fields.Content == "" {
+		fields.Content = DefaultFields.Content
+	}
+	if fields.Date == "" {
+		fields.Date = DefaultFields.Date
+	}
+	e.fields = fields
+}
+
+// SetAPIKey authenticates requests with an Elasticsearch API key, the
+// base64 encoded credentials returned when the key is created
+func (e *Engine) SetAPIKey(apiKey string) {
+	e.apiKey = apiKey
+}
+
+// SetBasicAuth authenticates requests with a username and password, as
+// used by OpenSearch and the Elasticsearch native realm
+func (e *Engine) SetBasicAuth(username, password string) {
+	e.username, e.password = username, password
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests, such as a SigV4 signer
+// for Amazon OpenSearch Service. A nil signer stops signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The language is
+// filtered only with a mapped language field.
+func (e *Engine) SupportedParams(operation string) []string {
+	supported := []string{
+		omniserp.ParamQuery,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+	}
+	if e.fields.Language != "" {
+		supported = append(supported, omniserp.ParamLanguage)
+	}
+	return supported
+}
+
+// Extra declares the Elasticsearch-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Index       string `extra:"index" description:"Index, or comma-separated indexes, to search instead of the configured one"`
+	QueryString bool   `extra:"query_string" description:"Parse the query with the Lucene query string syntax, such as field:value, AND, and OR"`
+	Fuzziness   string `extra:"fuzziness" description:"Fuzziness of matches, such as AUTO, 0, 1, or 2"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// freshnessRanges are the date math ranges of the freshness values
+var freshnessRanges = map[omniserp.Freshness]string{
+	omniserp.FreshnessHour:  "now-1h",
+	omniserp.FreshnessDay:   "now-1d",
+	omniserp.FreshnessWeek:  "now-1w",
+	omniserp.FreshnessMonth: "now-1M",
+	omniserp.FreshnessYear:  "now-1y",
+}
+
+// buildQuery converts SearchParams to a search request body
+func (e *Engine) buildQuery(params omniserp.SearchParams, extra map[string]any) map[string]any {
+	size := params.NumResults
+	if size <= 0 {
+		size = defaultNumResults
+	}
+	fields := []string{e.fields.Title + "^2", e.fields.Content}
+
+	var match map[string]any
+	if qs, _ := extra["query_string"].(bool); qs {
+		match = map[string]any{"query_string": map[string]any{"query": params.Query, "fields": fields}}
+	} else {
+		multiMatch := map[string]any{"query": params.Query, "fields": fields}
+		if fuzziness, _ := extra["fuzziness"].(string); fuzziness != "" {
+			multiMatch["fuzziness"] = fuzziness
+		}
+		match = map[string]any{"multi_match": multiMatch}
+	}
+
+	var filters []any
+	if gte, ok := freshnessRanges[params.Freshness]; ok {
+		filters = append(filters, map[string]any{"range": map[string]any{e.fields.Date: map[string]any{"gte": gte}}})
+	}
+	if e.fields.Language != "" && params.Language != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{e.fields.Language: strings.ToLower(params.Language)}})
+	}
+	boolQuery := map[string]any{"must": match}
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+
+	query := map[string]any{
+		"size":    size,
+		"query":   map[string]any{"bool": boolQuery},
+		"_source": []string{e.fields.Title, e.fields.URL, e.fields.Content, e.fields.Date},
+		"highlight": map[string]any{
+			"fields": map[string]any{
+				e.fields.Content: map[string]any{"fragment_size": 200, "number_of_fragments": 2},
+			},
+			"pre_tags":  []string{""},
+			"post_tags": []string{""},
+		},
+	}
+	if params.Page > 1 {
+		query["from"] = (params.Page - 1) * size
+	}
+	return query
+}
+
+// response is the JSON response of the search API
+type response struct {
+	Took int64 `json:"took"`
+	Hits struct {
+		// Total is an object with a value in Elasticsearch 7 and later and
+		// OpenSearch, and a number before
+		Total json.RawMessage `json:"total"`
+		Hits  []hit           `json:"hits"`
+	} `json:"hits"`
+}
+
+// hit is one matching document
+type hit struct {
+	Source    map[string]any      `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+}
+
+// totalHits returns the total number of hits of a response
+func totalHits(raw json.RawMessage) int64 {
+	var total struct {
+		Value int64 `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &total); err == nil {
+		return total.Value
+	}
+	var n int64
+	_ = json.Unmarshal(raw, &n)
+	return n
+}
+
+// indexPath returns the path of an index list, escaping each index
+func indexPath(index string) string {
+	indexes := strings.Split(index, ",")
+	for i, name := range indexes {
+		indexes[i] = url.PathEscape(strings.TrimSpace(name))
+	}
+	return "/" + strings.Join(indexes, ",")
+}
+
+// Search searches the index and returns the hits as organic results
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	index := e.index
+	if override, _ := extra["index"].(string); override != "" {
+		index = override
+	}
+
+	data, err := json.Marshal(e.buildQuery(params, extra))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	reqURL := e.baseURL + indexPath(index) + "/_search"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case e.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	start := time.Now()
+	// #nosec G704 -- request to the configured Elasticsearch cluster
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &omniserp.SearchResult{
+		Data:     e.normalize(&parsed, params),
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// normalize converts a search response to a normalized result
+func (e *Engine) normalize(resp *response, params omniserp.SearchParams) *omniserp.NormalizedSearchResult {
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Language:     params.Language,
+			TotalResults: totalHits(resp.Hits.Total),
+			TimeTaken:    float64(resp.Took) / 1000,
+		},
+	}
+
+	for i, h := range resp.Hits.Hits {
+		link := sourceString(h.Source, e.fields.URL)
+		snippet := strings.Join(h.Highlight[e.fields.Content], " … ")
+		if snippet == "" {
+			snippet = omniserp.TruncateText(sourceString(h.Source, e.fields.Content), snippetRunes)
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: i + 1,
+			Title:    sourceString(h.Source, e.fields.Title),
+			Link:     link,
+			URL:      link,
+			Snippet:  snippet,
+			Domain:   hostname(link),
+			Date:     sourceString(h.Source, e.fields.Date),
+		})
+	}
+
+	return normalized
+}
+
+// sourceString returns a field of a document as a string, following dots
+// into objects, so "meta.title" reads {"meta": {"title": ...}}. The first
+// value of arrays is used.
+func sourceString(source map[string]any, field string) string {
+	value, ok := source[field]
+	if !ok {
+		name, rest, found := strings.Cut(field, ".")
+		if !found {
+			return ""
+		}
+		nested, _ := source[name].(map[string]any)
+		return sourceString(nested, rest)
+	}
+	if values, ok := value.([]any); ok {
+		if len(values) == 0 {
+			return ""
+		}
+		value = values[0]
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// SearchNews performs a news search (not supported by Elasticsearch)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Elasticsearch")
+}
+
+// SearchImages performs an image search (not supported by Elasticsearch)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Elasticsearch")
+}
+
+// SearchVideos performs a video search (not supported by Elasticsearch)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Elasticsearch")
+}
+
+// SearchPlaces performs a places search (not supported by Elasticsearch)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Elasticsearch")
+}
+
+// SearchMaps performs a maps search (not supported by Elasticsearch)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Elasticsearch")
+}
+
+// SearchReviews performs a reviews search (not supported by Elasticsearch)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Elasticsearch")
+}
+
+// SearchShopping performs a shopping search (not supported by Elasticsearch)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Elasticsearch")
+}
+
+// SearchScholar performs a scholar search (not supported by Elasticsearch)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Elasticsearch")
+}
+
+// SearchLens performs a visual search (not supported by Elasticsearch)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Elasticsearch")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by
+// Elasticsearch)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Elasticsearch")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Elasticsearch)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Elasticsearch")
+}
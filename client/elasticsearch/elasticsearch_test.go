@@ -0,0 +1,167 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the search fixture and records the last request and
+// its body. Requests must authenticate with the API key "test-key".
+func newTestServer(t *testing.T) (*Engine, **http.Request, *map[string]any) {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var last *http.Request
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		body = nil
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			_ = json.Unmarshal(data, &body)
+		}
+		if r.Header.Get("Authorization") != "ApiKey test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"type":"security_exception","reason":"unable to authenticate with provided credentials"},"status":401}`))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/_search") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(fixture)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithURL(srv.URL+"/", "wiki")
+	if err != nil {
+		t.Fatalf("NewWithURL failed: %v", err)
+	}
+	engine.SetAPIKey("test-key")
+	return engine, &last, &body
+}
+
+func TestSearch(t *testing.T) {
+	engine, last, body := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "code review",
+		NumResults: 5,
+		Page:       3,
+		Freshness:  omniserp.FreshnessMonth,
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if (*last).Method != http.MethodPost || (*last).URL.Path != "/wiki/_search" {
+		t.Errorf("Unexpected request %s %s", (*last).Method, (*last).URL.Path)
+	}
+	got := *body
+	if got["size"] != float64(5) || got["from"] != float64(10) {
+		t.Errorf("Expected size 5 from 10, got %v and %v", got["size"], got["from"])
+	}
+	query, _ := json.Marshal(got["query"])
+	for _, want := range []string{`"multi_match"`, `"title^2"`, `"gte":"now-1M"`} {
+		if !strings.Contains(string(query), want) {
+			t.Errorf("Expected %s in query %s", want, query)
+		}
+	}
+
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	if normalized.SearchMetadata.TotalResults != 42 || len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Unexpected result: %+v", normalized)
+	}
+	first, second := normalized.OrganicResults[0], normalized.OrganicResults[1]
+	if first.Snippet != "describes the onboarding checklist … the code review process" || first.Date != "2026-09-14T08:00:00Z" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if second.Title != "Code Review Guidelines" || second.Domain != "wiki.example.internal" || !strings.HasPrefix(second.Snippet, "Reviews should") {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+}
+
+func TestSearchExtra(t *testing.T) {
+	engine, last, body := newTestServer(t)
+	engine.SetFields(Fields{Title: "meta.headline", Language: "lang"})
+
+	params := omniserp.SearchParams{
+		Query:    "title:onboarding AND review",
+		Language: "EN",
+		Extra:    map[string]any{"index": "wiki,handbook-*", "query_string": true},
+	}
+	if _, err := engine.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if (*last).URL.Path != "/wiki,handbook-*/_search" {
+		t.Errorf("Expected the index override, got %s", (*last).URL.Path)
+	}
+	query, _ := json.Marshal((*body)["query"])
+	for _, want := range []string{`"query_string"`, `"meta.headline^2"`, `"term":{"lang":"en"}`} {
+		if !strings.Contains(string(query), want) {
+			t.Errorf("Expected %s in query %s", want, query)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("title=headline, content=body,language=lang")
+	if err != nil {
+		t.Fatalf("ParseFields failed: %v", err)
+	}
+	want := Fields{Title: "headline", URL: "url", Content: "body", Date: "date", Language: "lang"}
+	if fields != want {
+		t.Errorf("Expected %+v, got %+v", want, fields)
+	}
+
+	for _, invalid := range []string{"title", "title=", "author=name"} {
+		if _, err := ParseFields(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	source := map[string]any{
+		"meta":  map[string]any{"title": "Nested"},
+		"tags":  []any{"first", "second"},
+		"views": float64(12),
+	}
+	for field, want := range map[string]string{"meta.title": "Nested", "tags": "first", "views": "12", "missing.field": ""} {
+		if got := sourceString(source, field); got != want {
+			t.Errorf("sourceString(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, last, _ := newTestServer(t)
+	engine.SetAPIKey("")
+	engine.SetBasicAuth("elastic", "changeme")
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "onboarding"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+	if user, pass, ok := (*last).BasicAuth(); !ok || user != "elastic" || pass != "changeme" {
+		t.Error("Expected basic authentication")
+	}
+}
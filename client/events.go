@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"log"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/events"
+)
+
+// SetPublisher sets the publisher that receives an event for every
+// normalized search, or disables publishing if nil
+func (c *Client) SetPublisher(publisher events.Publisher) {
+	c.mu.Lock()
+	c.publisher = publisher
+	c.mu.Unlock()
+}
+
+// currentPublisher returns the publisher of search events, or nil
+func (c *Client) currentPublisher() events.Publisher {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.publisher
+}
+
+// publish sends the event of a normalized search to the publisher.
+// Publishing is best effort: a failure is logged and does not fail the
+// search.
+func (c *Client) publish(ctx context.Context, operation string, normalized *omniserp.NormalizedSearchResult) {
+	publisher := c.currentPublisher()
+	if publisher == nil || normalized == nil {
+		return
+	}
+	if err := publisher.Publish(ctx, events.NewEvent(operation, normalized)); err != nil {
+		log.Printf("Failed to publish %s event: %v", operation, err)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/events"
+)
+
+// recordingPublisher collects published events
+type recordingPublisher struct {
+	events []events.Event
+	err    error
+}
+
+func (r *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func TestPublisher(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if params.Query == "fail" {
+			return nil, errors.New("engine failed")
+		}
+		return organicResponse("https://a.example", "https://b.example"), nil
+	})
+	publisher := &recordingPublisher{}
+	c.SetPublisher(publisher)
+	ctx := context.Background()
+
+	if _, err := c.SearchNewsNormalized(ctx, omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Fatalf("SearchNewsNormalized failed: %v", err)
+	}
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "fail"}); err == nil {
+		t.Fatal("Expected the search to fail")
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("Expected 1 event for the completed search, got %d", len(publisher.events))
+	}
+	event := publisher.events[0]
+	if event.Operation != OpSearchNews || event.Query != "golang" || event.Fingerprint == "" || event.Result == nil {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	// A publishing failure does not fail the search
+	publisher.err = errors.New("broker unavailable")
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Errorf("Expected the search to succeed, got %v", err)
+	}
+}
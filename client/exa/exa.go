@@ -0,0 +1,427 @@
+// Package exa implements the omniserp.Engine interface for the Exa API
+// (formerly Metaphor). Exa searches its own index by meaning with neural
+// embeddings or by keywords, and its contents endpoint returns the text of
+// pages for scraping.
+package exa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.exa.ai"
+	engineName    = "exa"
+	engineVersion = "1.0.0"
+
+	searchPath   = "/search"
+	contentsPath = "/contents"
+
+	// maxResults is the largest number of results the API returns
+	maxResults = 100
+
+	// highlightSentences is the length of the highlights used as snippets
+	highlightSentences = 2
+)
+
+// Search types of the Search API, selected with the "type" extra parameter
+const (
+	TypeAuto    = "auto"
+	TypeNeural  = "neural"
+	TypeKeyword = "keyword"
+	TypeFast    = "fast"
+)
+
+// Engine implements the omniserp.Engine interface for Exa. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult, or a *omniserp.NormalizedScrapeResult
+// for scrapes.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Exa engine from the EXA_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("EXA_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("EXA_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Exa engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"webpage_scrape",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The country is sent as
+// the location of the user, safe search as content moderation, and freshness
+// as the earliest publication date; there are no pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	if operation == "webpage_scrape" {
+		return nil
+	}
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamFreshness,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// Extra declares the Exa-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Type     string `extra:"type" description:"Search type: auto (default), neural for semantic search, keyword, or fast"`
+	Category string `extra:"category" description:"Only results of a category, such as company, research paper, pdf, github, or personal site"`
+	Text     bool   `extra:"text" description:"Include the text of each page as raw_content"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// contentsOptions selects the contents returned with each result
+type contentsOptions struct {
+	Text       bool               `json:"text,omitempty"`
+	Highlights *highlightsOptions `json:"highlights,omitempty"`
+}
+
+// highlightsOptions selects the highlights of each page
+type highlightsOptions struct {
+	NumSentences int `json:"numSentences"`
+}
+
+// searchRequest is the JSON body of a Search API request
+type searchRequest struct {
+	Query              string          `json:"query"`
+	Type               string          `json:"type,omitempty"`
+	Category           string          `json:"category,omitempty"`
+	NumResults         int             `json:"numResults,omitempty"`
+	StartPublishedDate string          `json:"startPublishedDate,omitempty"`
+	UserLocation       string          `json:"userLocation,omitempty"`
+	Moderation         bool            `json:"moderation,omitempty"`
+	Contents           contentsOptions `json:"contents"`
+}
+
+// contentsRequest is the JSON body of a contents request
+type contentsRequest struct {
+	URLs []string `json:"urls"`
+	Text bool     `json:"text"`
+}
+
+// result is a search result or the contents of a page
+type result struct {
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	PublishedDate string   `json:"publishedDate"`
+	Author        string   `json:"author"`
+	Text          string   `json:"text"`
+	Highlights    []string `json:"highlights"`
+	Image         string   `json:"image"`
+}
+
+// response is the JSON response of the Search API and the contents endpoint
+type response struct {
+	Results  []result `json:"results"`
+	Statuses []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Error  *struct {
+			Tag            string `json:"tag"`
+			HTTPStatusCode int    `json:"httpStatusCode"`
+		} `json:"error"`
+	} `json:"statuses"`
+}
+
+// freshnessPeriods are the periods of the freshness filters
+var freshnessPeriods = map[omniserp.Freshness]time.Duration{
+	omniserp.FreshnessHour:  time.Hour,
+	omniserp.FreshnessDay:   24 * time.Hour,
+	omniserp.FreshnessWeek:  7 * 24 * time.Hour,
+	omniserp.FreshnessMonth: 30 * 24 * time.Hour,
+	omniserp.FreshnessYear:  365 * 24 * time.Hour,
+}
+
+// startPublishedDate returns the earliest publication date of results
+// published within the freshness period before now
+func startPublishedDate(f omniserp.Freshness, now time.Time) string {
+	period, ok := freshnessPeriods[f]
+	if !ok {
+		return ""
+	}
+	return now.Add(-period).UTC().Format(time.RFC3339)
+}
+
+// buildRequest converts SearchParams to a Search API request. Highlights of
+// each page are requested as snippets, and the text with the "text" extra
+// parameter.
+func (e *Engine) buildRequest(params omniserp.SearchParams, category string) searchRequest {
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	searchType, _ := extra["type"].(string)
+	if category == "" {
+		category, _ = extra["category"].(string)
+	}
+	text, _ := extra["text"].(bool)
+
+	return searchRequest{
+		Query:              params.Query,
+		Type:               searchType,
+		Category:           category,
+		NumResults:         min(params.NumResults, maxResults),
+		StartPublishedDate: startPublishedDate(params.Freshness, time.Now()),
+		UserLocation:       strings.ToUpper(params.Country),
+		Moderation:         params.SafeSearch,
+		Contents: contentsOptions{
+			Text:       text,
+			Highlights: &highlightsOptions{NumSentences: highlightSentences},
+		},
+	}
+}
+
+// post performs a POST request against an Exa endpoint and parses the
+// response
+func (e *Engine) post(ctx context.Context, path string, body any) (*response, string, *omniserp.ResponseMeta, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+path, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-api-key", e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to the Exa API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(raw), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &parsed, string(raw), meta, nil
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:  engineName,
+			Query:   params.Query,
+			Country: params.Country,
+		},
+	}
+}
+
+// Search performs a general web search. Results have the highlights of
+// their page as the snippet, and its text as RawContent with the "text"
+// extra parameter.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.post(ctx, searchPath, e.buildRequest(params, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params)
+	for i, r := range resp.Results {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position:   i + 1,
+			Title:      r.Title,
+			Link:       r.URL,
+			URL:        r.URL,
+			Snippet:    strings.Join(r.Highlights, " "),
+			Domain:     hostname(r.URL),
+			Date:       r.PublishedDate,
+			RawContent: r.Text,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search in the news category
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.post(ctx, searchPath, e.buildRequest(params, "news"))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params)
+	for i, r := range resp.Results {
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position: i + 1,
+			Title:    r.Title,
+			Link:     r.URL,
+			Source:   hostname(r.URL),
+			Date:     r.PublishedDate,
+			Snippet:  strings.Join(r.Highlights, " "),
+			ImageURL: r.Image,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search (not supported by Exa)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Exa")
+}
+
+// SearchVideos performs a video search (not supported by Exa)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Exa")
+}
+
+// SearchPlaces performs a places search (not supported by Exa)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Exa")
+}
+
+// SearchMaps performs a maps search (not supported by Exa)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Exa")
+}
+
+// SearchReviews performs a reviews search (not supported by Exa)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Exa")
+}
+
+// SearchShopping performs a shopping search (not supported by Exa)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Exa")
+}
+
+// SearchScholar performs a scholar search (not supported by Exa)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Exa")
+}
+
+// SearchLens performs a visual search (not supported by Exa)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Exa")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Exa)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Exa")
+}
+
+// ScrapeWebpage returns the text of a page from the contents endpoint, which
+// serves pages from the Exa index or crawls them. Redirect options are not
+// honored.
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.post(ctx, contentsPath, contentsRequest{URLs: []string{params.URL}, Text: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range resp.Statuses {
+		if status.Error != nil {
+			return nil, fmt.Errorf("failed to get the contents of %s: %s (status %d)", status.ID, status.Error.Tag, status.Error.HTTPStatusCode)
+		}
+	}
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("no contents returned for %s", params.URL)
+	}
+
+	page := resp.Results[0]
+	scraped := &omniserp.NormalizedScrapeResult{
+		URL:         params.URL,
+		FinalURL:    page.URL,
+		Title:       page.Title,
+		Text:        page.Text,
+		ContentHash: omniserp.ContentHash(page.Text),
+		Engine:      engineName,
+		FetchedAt:   time.Now(),
+	}
+	if page.Author != "" {
+		scraped.Metadata = map[string]string{"author": page.Author}
+	}
+	return &omniserp.SearchResult{Data: scraped, Raw: raw, Response: meta}, nil
+}
@@ -0,0 +1,173 @@
+package exa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixtures of the Search API and the contents
+// endpoint, and records the body of the last search request
+func newTestServer(t *testing.T) (*Engine, *searchRequest) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, contents := fixture("search.json"), fixture("contents.json")
+
+	var body searchRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Invalid API key"}`))
+			return
+		}
+		switch r.URL.Path {
+		case searchPath:
+			body = searchRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write(search)
+		case contentsPath:
+			var req contentsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.URLs) != 1 || !req.Text {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if req.URLs[0] != "https://go.dev/doc/" {
+				_, _ = w.Write([]byte(`{"results":[],"statuses":[{"id":"` + req.URLs[0] + `","status":"error","error":{"tag":"CRAWL_NOT_FOUND","httpStatusCode":404}}]}`))
+				return
+			}
+			_, _ = w.Write(contents)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &body
+}
+
+func TestSearch(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "languages for building scalable systems",
+		Country:    "us",
+		NumResults: 200,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Extra:      map[string]any{"type": TypeNeural, "text": "true"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if body.Type != TypeNeural || body.NumResults != maxResults || body.UserLocation != "US" || !body.Moderation {
+		t.Errorf("Unexpected request: %+v", *body)
+	}
+	if !body.Contents.Text || body.Contents.Highlights == nil {
+		t.Errorf("Expected the text and highlights to be requested, got %+v", body.Contents)
+	}
+	start, err := time.Parse(time.RFC3339, body.StartPublishedDate)
+	if err != nil || time.Since(start) < 7*24*time.Hour-time.Minute || time.Since(start) > 7*24*time.Hour+time.Minute {
+		t.Errorf("Expected a start date a week ago, got %q", body.StartPublishedDate)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	first := normalized.OrganicResults[0]
+	if first.Snippet != "Go is an open source programming language. It makes it simple to build secure, scalable systems." {
+		t.Errorf("Expected the highlights as the snippet, got %q", first.Snippet)
+	}
+	if first.RawContent != "Build simple, secure, scalable systems with Go." || first.Domain != "go.dev" || first.Engine != engineName {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+}
+
+func TestSearchDefaults(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if body.Type != "" || body.Category != "" || body.StartPublishedDate != "" || body.Contents.Text {
+		t.Errorf("Expected the defaults of the API, got %+v", *body)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", Extra: map[string]any{"category": "company"}}
+	result, err := engine.SearchNews(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if body.Category != "news" {
+		t.Errorf("Expected the news category, got %q", body.Category)
+	}
+
+	news := result.Data.(*omniserp.NormalizedSearchResult).NewsResults
+	if len(news) != 2 || news[1].Source != "example.org" || news[1].ImageURL != "https://www.example.org/gopher.png" {
+		t.Errorf("Unexpected news results: %+v", news)
+	}
+}
+
+func TestScrapeWebpage(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "https://go.dev/doc/"})
+	if err != nil {
+		t.Fatalf("ScrapeWebpage failed: %v", err)
+	}
+	scraped, err := omniserp.NormalizeScrape(result, "https://go.dev/doc/")
+	if err != nil {
+		t.Fatalf("NormalizeScrape failed: %v", err)
+	}
+	if scraped.Title != "Documentation - The Go Programming Language" || scraped.Metadata["author"] != "The Go Team" {
+		t.Errorf("Unexpected scrape result: %+v", scraped)
+	}
+	if scraped.ContentHash != omniserp.ContentHash(scraped.Text) {
+		t.Error("Expected the content hash of the text")
+	}
+
+	if _, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "https://go.dev/missing"}); err == nil {
+		t.Error("Expected an error for a page without contents")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
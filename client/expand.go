@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Sources of expanded queries
+const (
+	SourceRelated      = "related"
+	SourceAutocomplete = "autocomplete"
+)
+
+// ExpandedQuery is a query of the keyword universe built by ExpandQuery
+type ExpandedQuery struct {
+	Query string `json:"query"`
+
+	// Parent is the query that this one was found from; it is empty for the
+	// initial query
+	Parent string `json:"parent,omitempty"`
+
+	// Depth is the number of steps from the initial query, which has depth 0
+	Depth int `json:"depth"`
+
+	// Source is SourceRelated or SourceAutocomplete
+	Source string `json:"source,omitempty"`
+}
+
+// ExpandQuery builds a keyword universe around query by following the
+// related searches and autocomplete suggestions of each query, level by
+// level, up to depth levels from the initial query. It returns at most
+// maxQueries queries (0 means no limit), starting with the initial one, in
+// the order they were found; queries that differ only in casing or whitespace are kept once.
+// Autocomplete is skipped if the engine does not support it. Only a failure
+// of the initial query is returned as an error; the queries found from a
+// failed expansion are omitted.
+func (c *Client) ExpandQuery(ctx context.Context, query string, depth, maxQueries int) ([]ExpandedQuery, error) {
+	universe := []ExpandedQuery{{Query: query}}
+	seen := map[string]bool{omniserp.CanonicalQuery(query): true}
+	autocomplete := c.SupportsOperation(OpSearchAutocomplete)
+	full := func() bool {
+		return maxQueries > 0 && len(universe) >= maxQueries
+	}
+
+	level := universe
+	for d := 1; d <= depth && len(level) > 0 && !full(); d++ {
+		found := make([][]ExpandedQuery, len(level))
+		errs := runMatrix(ctx, len(level), func(ctx context.Context, i int) error {
+			var err error
+			found[i], err = c.expandOne(ctx, level[i].Query, autocomplete)
+			return err
+		})
+		if d == 1 && errs[0] != nil {
+			return nil, fmt.Errorf("failed to expand %q: %w", query, errs[0])
+		}
+
+		var next []ExpandedQuery
+		for i, queries := range found {
+			for _, q := range queries {
+				key := omniserp.CanonicalQuery(q.Query)
+				if key == "" || seen[key] || full() {
+					continue
+				}
+				seen[key] = true
+				q.Parent, q.Depth = level[i].Query, d
+				universe = append(universe, q)
+				next = append(next, q)
+			}
+		}
+		level = next
+	}
+	return universe, nil
+}
+
+// expandOne returns the related searches and autocomplete suggestions of
+// a query
+func (c *Client) expandOne(ctx context.Context, query string, autocomplete bool) ([]ExpandedQuery, error) {
+	params := omniserp.SearchParams{Query: query}
+	result, err := c.SearchNormalized(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	var queries []ExpandedQuery
+	for _, related := range result.RelatedSearches {
+		queries = append(queries, ExpandedQuery{Query: related.Query, Source: SourceRelated})
+	}
+
+	if autocomplete {
+		suggestions, err := c.SearchAutocompleteNormalized(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, suggestion := range suggestions.Suggestions {
+			queries = append(queries, ExpandedQuery{Query: suggestion, Source: SourceAutocomplete})
+		}
+	}
+	return queries, nil
+}
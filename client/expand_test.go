@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// expandResponse builds a serper-shaped response with related searches and
+// autocomplete suggestions
+func expandResponse(related []string, suggestions []string) *omniserp.SearchResult {
+	data := map[string]any{}
+	var items []any
+	for _, q := range related {
+		items = append(items, map[string]any{"query": q})
+	}
+	data["relatedSearches"] = items
+	items = nil
+	for _, s := range suggestions {
+		items = append(items, map[string]any{"value": s})
+	}
+	data["suggestions"] = items
+	return &omniserp.SearchResult{Data: data}
+}
+
+func TestExpandQuery(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		switch params.Query {
+		case "go":
+			return expandResponse([]string{"golang", "go tutorial"}, []string{"Go Tutorial", "go generics"}), nil
+		case "golang":
+			return expandResponse([]string{"golang jobs"}, nil), nil
+		case "go tutorial":
+			return nil, errors.New("boom")
+		case "broken":
+			return nil, errors.New("boom")
+		}
+		return expandResponse(nil, nil), nil
+	})
+	ctx := context.Background()
+
+	universe, err := c.ExpandQuery(ctx, "go", 2, 0)
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	want := []ExpandedQuery{
+		{Query: "go"},
+		{Query: "golang", Parent: "go", Depth: 1, Source: SourceRelated},
+		{Query: "go tutorial", Parent: "go", Depth: 1, Source: SourceRelated},
+		{Query: "go generics", Parent: "go", Depth: 1, Source: SourceAutocomplete},
+		{Query: "golang jobs", Parent: "golang", Depth: 2, Source: SourceRelated},
+	}
+	if len(universe) != len(want) {
+		t.Fatalf("Expected %d queries, got %+v", len(want), universe)
+	}
+	for i := range want {
+		if universe[i] != want[i] {
+			t.Errorf("Query %d: expected %+v, got %+v", i, want[i], universe[i])
+		}
+	}
+
+	universe, err = c.ExpandQuery(ctx, "go", 2, 3)
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if len(universe) != 3 {
+		t.Errorf("Expected the universe limited to 3 queries, got %d", len(universe))
+	}
+
+	if _, err := c.ExpandQuery(ctx, "broken", 1, 0); err == nil {
+		t.Error("Expected an error when the initial query fails")
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// FailoverPolicy controls routing away from failing or degraded engines.
+// An engine is degraded once it has at least MinRequests samples and its
+// rolling error rate exceeds MaxErrorRate or its p95 latency exceeds MaxP95.
+type FailoverPolicy struct {
+	// MaxErrorRate is the error rate (0-1) above which an engine is degraded.
+	// Zero disables the error rate check.
+	MaxErrorRate float64
+
+	// MaxP95 is the p95 latency above which an engine is degraded.
+	// Zero disables the latency check.
+	MaxP95 time.Duration
+
+	// MinRequests is the number of samples required before an engine can be
+	// considered degraded (default: 10)
+	MinRequests int
+}
+
+// defaultMinRequests is used when FailoverPolicy.MinRequests is not set
+const defaultMinRequests = 10
+
+// degraded reports whether stats violate the policy thresholds
+func (p *FailoverPolicy) degraded(stats EngineStats) bool {
+	minRequests := p.MinRequests
+	if minRequests <= 0 {
+		minRequests = defaultMinRequests
+	}
+	if stats.Requests < minRequests {
+		return false
+	}
+	if p.MaxErrorRate > 0 && stats.ErrorRate > p.MaxErrorRate {
+		return true
+	}
+	return p.MaxP95 > 0 && stats.P95 > p.MaxP95
+}
+
+// candidates returns the engines to try for an operation in order. Without
+// a selection policy, the current engine comes first unless degraded; with
+// one, the selected engine does. They are followed by the other engines that
+// support the operation. Without a failover policy only the first engine is
+// returned. Degraded engines are moved to the end rather than dropped so a
+// request is still attempted when every engine is degraded. It returns no
+// engines if the operation is not supported. In deterministic mode only the
+// pinned engine is returned. A preferred engine, checked by the caller,
+// replaces the current engine and bypasses the selection policy. Other
+// engines are left out unless they are selectable.
+func (c *Client) candidates(operation, preferred string) []omniserp.Engine {
+	if d := c.deterministic(); d != nil {
+		return c.deterministicCandidates(d, operation)
+	}
+	current := c.GetCurrentEngine()
+	selection := c.selectionPolicy()
+	failover := c.failoverPolicy()
+	if preferred != "" {
+		current, _ = c.registry.Get(preferred)
+		selection = nil
+	}
+	supports := func(engine omniserp.Engine) bool {
+		return slices.Contains(engine.GetSupportedTools(), operation)
+	}
+	if selection == nil && !supports(current) {
+		return nil
+	}
+	if failover == nil && selection == nil {
+		return []omniserp.Engine{current}
+	}
+
+	var engines []omniserp.Engine
+	if supports(current) {
+		engines = append(engines, current)
+	}
+	// Sorted so selection policies see a stable order
+	names := c.registry.List()
+	slices.Sort(names)
+	for _, name := range names {
+		if name == current.GetName() || !c.selectable(selection, name) {
+			continue
+		}
+		if engine, _ := c.registry.Get(name); supports(engine) {
+			engines = append(engines, engine)
+		}
+	}
+	if len(engines) == 0 {
+		return nil
+	}
+
+	if selection != nil {
+		engines = c.selectEngine(selection, operation, engines)
+	}
+	if failover == nil {
+		return engines[:1]
+	}
+
+	var healthy, degraded []omniserp.Engine
+	for _, engine := range engines {
+		if failover.degraded(c.stats.get(engine.GetName())) {
+			degraded = append(degraded, engine)
+		} else {
+			healthy = append(healthy, engine)
+		}
+	}
+	return append(healthy, degraded...)
+}
+
+// call runs fn against the current or selected engine, recording latency
+// and errors.
+// Results are sanitized if the client has a sanitizer.
+// With a failover policy, degraded engines are skipped and failed requests
+// are retried on the next candidate engine. Requests cancelled by
+// CancelInFlight are redirected to the newly selected engine, unless ctx
+// prefers an engine with ContextWithEngine. It returns the engine that
+// produced the result so callers can normalize the response correctly.
+func (c *Client) call(ctx context.Context, operation string, fn func(context.Context, omniserp.Engine) (*omniserp.SearchResult, error)) (*omniserp.SearchResult, omniserp.Engine, error) {
+	preferred, _ := EngineFromContext(ctx)
+	if preferred != "" {
+		if err := c.checkPreferred(operation, preferred); err != nil {
+			return nil, nil, err
+		}
+	}
+	engines := c.candidates(operation, preferred)
+	if len(engines) == 0 {
+		return nil, nil, c.checkSupport(operation)
+	}
+	tried := make(map[string]bool, len(engines))
+	var lastErr error
+	var lastName string
+	for i := 0; i < len(engines); i++ {
+		engine := engines[i]
+		name := engine.GetName()
+		if tried[name] {
+			continue
+		}
+		tried[name] = true
+		if lastErr != nil {
+			notifyFailover(ctx, FailoverEvent{Operation: operation, From: lastName, To: name, Err: lastErr})
+		}
+		lastName = name
+
+		reqCtx, done := c.inflight.start(ctx, name)
+		start := time.Now()
+		result, err := fn(reqCtx, engine)
+		elapsed := time.Since(start)
+		switched := errors.Is(context.Cause(reqCtx), ErrEngineSwitched)
+		done()
+
+		if switched && ctx.Err() == nil {
+			// Not the engine's fault, so it is not recorded in the stats
+			lastErr = ErrEngineSwitched
+			if next := c.GetCurrentEngine(); preferred == "" && !tried[next.GetName()] && slices.Contains(next.GetSupportedTools(), operation) {
+				engines = slices.Insert(engines, i+1, next)
+			}
+			continue
+		}
+
+		c.stats.record(name, elapsed, err != nil)
+		if err == nil {
+			c.usage.record(name, result)
+			return c.currentSanitizer().Sanitize(result), engine, nil
+		}
+		lastErr = err
+
+		// The caller gave up; trying another engine will not help
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			break
+		}
+	}
+	return nil, nil, lastErr
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// fakeEngine is a minimal engine returning canned serper-shaped responses
+type fakeEngine struct {
+	name   string
+	tools  []string
+	search func(params omniserp.SearchParams) (*omniserp.SearchResult, error)
+}
+
+func (e *fakeEngine) GetName() string             { return e.name }
+func (e *fakeEngine) GetVersion() string          { return "test" }
+func (e *fakeEngine) GetSupportedTools() []string { return e.tools }
+func (e *fakeEngine) Search(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchNews(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchImages(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchVideos(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchPlaces(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchMaps(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchReviews(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchShopping(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchScholar(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchLens(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) SearchAutocomplete(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(p)
+}
+func (e *fakeEngine) ScrapeWebpage(ctx context.Context, p omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return e.search(omniserp.SearchParams{Query: p.URL})
+}
+
+// newFakeClient creates a client backed by a serper-named fake engine
+func newFakeClient(t *testing.T, search func(params omniserp.SearchParams) (*omniserp.SearchResult, error)) *Client {
+	t.Helper()
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: search})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	return c
+}
+
+// organicResponse builds a serper-shaped response with the given links
+func organicResponse(links ...string) *omniserp.SearchResult {
+	organic := make([]any, 0, len(links))
+	for _, link := range links {
+		organic = append(organic, map[string]any{"title": link, "link": link})
+	}
+	return &omniserp.SearchResult{Data: map[string]any{"organic": organic}}
+}
@@ -0,0 +1,470 @@
+// Package firecrawl implements the omniserp.Engine interface for the
+// Firecrawl API, which searches the web and scrapes pages into clean
+// markdown with one API key.
+package firecrawl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.firecrawl.dev"
+	engineName    = "firecrawl"
+	engineVersion = "1.0.0"
+	searchPath    = "/v2/search"
+	scrapePath    = "/v2/scrape"
+	creditsPath   = "/v2/team/credit-usage"
+
+	// maxLimit is the largest number of results of a search
+	maxLimit = 100
+)
+
+// Search sources of the Search API
+const (
+	sourceWeb  = "web"
+	sourceNews = "news"
+)
+
+// Engine implements the omniserp.Engine interface for Firecrawl. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult, or a *omniserp.NormalizedScrapeResult
+// for scrapes.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Firecrawl engine from the FIRECRAWL_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("FIRECRAWL_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("FIRECRAWL_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Firecrawl engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, such as that of a self-hosted
+// Firecrawl instance, or to route requests through a CORS-friendly proxy
+// when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"webpage_scrape",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The API returns up to
+// 100 results and has no pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	if operation == "webpage_scrape" {
+		return nil
+	}
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLocation,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamFreshness,
+	}
+}
+
+// Extra declares the Firecrawl-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	ScrapeResults bool `extra:"scrape_results" description:"Scrape each web result and include its markdown as raw_content, for one credit per page"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// scrapeOptions are the output formats of scraped pages
+type scrapeOptions struct {
+	Formats         []string `json:"formats"`
+	OnlyMainContent bool     `json:"onlyMainContent"`
+}
+
+// searchRequest is the JSON body of a Search API request
+type searchRequest struct {
+	Query         string         `json:"query"`
+	Sources       []string       `json:"sources"`
+	Limit         int            `json:"limit,omitempty"`
+	TBS           string         `json:"tbs,omitempty"`
+	Location      string         `json:"location,omitempty"`
+	Country       string         `json:"country,omitempty"`
+	ScrapeOptions *scrapeOptions `json:"scrapeOptions,omitempty"`
+}
+
+// searchResponse is the JSON response of the Search API, with the results
+// of each source
+type searchResponse struct {
+	Data struct {
+		Web []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+			Markdown    string `json:"markdown"`
+		} `json:"web"`
+		News []struct {
+			Title    string `json:"title"`
+			URL      string `json:"url"`
+			Snippet  string `json:"snippet"`
+			Date     string `json:"date"`
+			ImageURL string `json:"imageUrl"`
+		} `json:"news"`
+	} `json:"data"`
+}
+
+// scrapeRequest is the JSON body of a Scrape API request
+type scrapeRequest struct {
+	URL string `json:"url"`
+	scrapeOptions
+}
+
+// scrapeResponse is the JSON response of the Scrape API. Metadata holds the
+// title, description, and meta tags of the page, whose values are strings or
+// lists of strings, and the final URL and status code.
+type scrapeResponse struct {
+	Data struct {
+		Markdown string         `json:"markdown"`
+		Links    []string       `json:"links"`
+		Metadata map[string]any `json:"metadata"`
+	} `json:"data"`
+}
+
+// post performs a POST request against a Firecrawl endpoint and returns the
+// raw JSON response
+func (e *Engine) post(ctx context.Context, path string, body any) ([]byte, *omniserp.ResponseMeta, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+path, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return e.do(req)
+}
+
+// do sends an authenticated request and returns the raw JSON response.
+// Failed requests, including exhausted credits (402), are reported as
+// *omniserp.APIError.
+func (e *Engine) do(req *http.Request) ([]byte, *omniserp.ResponseMeta, error) {
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	start := time.Now()
+	// #nosec G704 -- request to the Firecrawl API or a configured instance
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(raw), Response: meta}
+	}
+	return raw, meta, nil
+}
+
+// Credits returns the remaining credits of the Firecrawl team
+func (e *Engine) Credits(ctx context.Context) (*omniserp.Credits, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+creditsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	raw, _, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage struct {
+		Data struct {
+			RemainingCredits int `json:"remainingCredits"`
+			PlanCredits      int `json:"planCredits"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &usage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credit usage: %w", err)
+	}
+
+	return &omniserp.Credits{
+		Engine:    engineName,
+		Remaining: usage.Data.RemainingCredits,
+		Limit:     usage.Data.PlanCredits,
+	}, nil
+}
+
+// search performs a search of a source and parses the response
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams, source string) (*searchResponse, string, *omniserp.ResponseMeta, error) {
+	body := searchRequest{
+		Query:    params.Query,
+		Sources:  []string{source},
+		Limit:    min(params.NumResults, maxLimit),
+		TBS:      params.Freshness.TBS(),
+		Location: params.Location,
+		Country:  strings.ToUpper(params.Country),
+	}
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	if scrape, _ := extra["scrape_results"].(bool); scrape && source == sourceWeb {
+		body.ScrapeOptions = &scrapeOptions{Formats: []string{"markdown"}, OnlyMainContent: true}
+	}
+
+	raw, meta, err := e.post(ctx, searchPath, body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var resp searchResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &resp, string(raw), meta, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, meta *omniserp.ResponseMeta) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			Location:  params.Location,
+			Country:   params.Country,
+			TimeTaken: meta.Latency.Seconds(),
+		},
+	}
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a web search. With the "scrape_results" extra parameter,
+// each result is scraped and its markdown returned as RawContent.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, params, sourceWeb)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, meta)
+	for i, r := range resp.Data.Web {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position:   i + 1,
+			Title:      r.Title,
+			Link:       r.URL,
+			URL:        r.URL,
+			Snippet:    r.Description,
+			Domain:     hostname(r.URL),
+			RawContent: r.Markdown,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, params, sourceNews)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, meta)
+	for i, r := range resp.Data.News {
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position:  i + 1,
+			Title:     r.Title,
+			Link:      r.URL,
+			Source:    hostname(r.URL),
+			Date:      r.Date,
+			Snippet:   r.Snippet,
+			Thumbnail: r.ImageURL,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// ScrapeWebpage scrapes the main content of a page as markdown, which is
+// also returned as the text. Firecrawl follows redirects itself, so the
+// redirect options are not honored, but the final URL is reported.
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	if _, err := url.ParseRequestURI(params.URL); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	raw, meta, err := e.post(ctx, scrapePath, scrapeRequest{
+		URL:           params.URL,
+		scrapeOptions: scrapeOptions{Formats: []string{"markdown", "links"}, OnlyMainContent: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp scrapeResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	page := resp.Data
+	scraped := &omniserp.NormalizedScrapeResult{
+		URL:         params.URL,
+		FinalURL:    params.URL,
+		Text:        page.Markdown,
+		Markdown:    page.Markdown,
+		Links:       page.Links,
+		ContentHash: omniserp.ContentHash(page.Markdown),
+		Engine:      engineName,
+		FetchedAt:   time.Now().UTC(),
+	}
+	if finalURL, ok := page.Metadata["url"].(string); ok && finalURL != "" {
+		scraped.FinalURL = finalURL
+	}
+	if status, ok := page.Metadata["statusCode"].(float64); ok {
+		scraped.StatusCode = int(status)
+	}
+	for key, value := range page.Metadata {
+		switch key {
+		case "url", "sourceURL", "statusCode", "scrapeId", "proxyUsed", "cacheState", "cachedAt", "creditsUsed":
+			continue
+		}
+		if scraped.Metadata == nil {
+			scraped.Metadata = make(map[string]string, len(page.Metadata))
+		}
+		scraped.Metadata[strings.ToLower(key)] = stringValue(value)
+	}
+	scraped.Title = scraped.Metadata["title"]
+	if scraped.Title == "" {
+		scraped.Title = scraped.Metadata["og:title"]
+	}
+	return &omniserp.SearchResult{Data: scraped, Raw: string(raw), Response: meta}, nil
+}
+
+// stringValue formats a metadata value as a string, joining lists of
+// values such as repeated meta tags with commas
+func stringValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []any:
+		values := make([]string, 0, len(val))
+		for _, item := range val {
+			values = append(values, stringValue(item))
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// SearchImages performs an image search (not supported by Firecrawl)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Firecrawl")
+}
+
+// SearchVideos performs a video search (not supported by Firecrawl)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Firecrawl")
+}
+
+// SearchPlaces performs a places search (not supported by Firecrawl)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Firecrawl")
+}
+
+// SearchMaps performs a maps search (not supported by Firecrawl)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Firecrawl")
+}
+
+// SearchReviews performs a reviews search (not supported by Firecrawl)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Firecrawl")
+}
+
+// SearchShopping performs a shopping search (not supported by Firecrawl)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Firecrawl")
+}
+
+// SearchScholar performs a scholar search (not supported by Firecrawl)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Firecrawl")
+}
+
+// SearchLens performs a visual search (not supported by Firecrawl)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Firecrawl")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Firecrawl)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Firecrawl")
+}
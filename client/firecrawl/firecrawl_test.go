@@ -0,0 +1,188 @@
+package firecrawl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixtures of the Firecrawl endpoints, choosing the
+// search fixture by the requested source, and records the last request and
+// its body
+func newTestServer(t *testing.T) (*Engine, **http.Request, *map[string]any) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, news, scrape, credits := fixture("search.json"), fixture("news.json"), fixture("scrape.json"), fixture("credits.json")
+
+	var last *http.Request
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		body = nil
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			_ = json.Unmarshal(data, &body)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success":false,"error":"Unauthorized: Invalid token"}`))
+			return
+		}
+		switch r.URL.Path {
+		case searchPath:
+			if sources, _ := body["sources"].([]any); len(sources) == 1 && sources[0] == sourceNews {
+				_, _ = w.Write(news)
+				return
+			}
+			_, _ = w.Write(search)
+		case scrapePath:
+			_, _ = w.Write(scrape)
+		case creditsPath:
+			_, _ = w.Write(credits)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &last, &body
+}
+
+func TestSearch(t *testing.T) {
+	engine, last, body := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Country:    "de",
+		NumResults: 500,
+		Freshness:  omniserp.FreshnessWeek,
+		Extra:      map[string]any{"scrape_results": true},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if (*last).Method != http.MethodPost || (*last).Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON POST, got %s %s", (*last).Method, (*last).Header.Get("Content-Type"))
+	}
+	got := *body
+	if got["query"] != "golang" || got["country"] != "DE" || got["limit"] != float64(maxLimit) || got["tbs"] != "qdr:w" {
+		t.Errorf("Unexpected request body: %v", got)
+	}
+	if opts, _ := got["scrapeOptions"].(map[string]any); opts == nil {
+		t.Error("Expected scrape options with scrape_results")
+	}
+
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(normalized.OrganicResults))
+	}
+	second := normalized.OrganicResults[1]
+	if second.Position != 2 || second.Domain != "github.com" || !strings.HasPrefix(second.RawContent, "# The Go") {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+
+	params.Extra = nil
+	if _, err := engine.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, ok := (*body)["scrapeOptions"]; ok {
+		t.Error("Expected no scrape options without scrape_results")
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	normalized := result.Data.(*omniserp.NormalizedSearchResult)
+	if len(normalized.NewsResults) != 1 {
+		t.Fatalf("Expected 1 news result, got %d", len(normalized.NewsResults))
+	}
+	if news := normalized.NewsResults[0]; news.Source != "go.dev" || news.Date != "2 days ago" || news.Thumbnail == "" {
+		t.Errorf("Unexpected news result: %+v", news)
+	}
+}
+
+func TestScrapeWebpage(t *testing.T) {
+	engine, _, body := newTestServer(t)
+
+	result, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "https://golang.org/doc/effective_go"})
+	if err != nil {
+		t.Fatalf("ScrapeWebpage failed: %v", err)
+	}
+	if (*body)["url"] != "https://golang.org/doc/effective_go" || (*body)["onlyMainContent"] != true {
+		t.Errorf("Unexpected request body: %v", *body)
+	}
+
+	scraped, ok := result.Data.(*omniserp.NormalizedScrapeResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	if scraped.Title != "Effective Go - The Go Programming Language" || scraped.FinalURL != "https://go.dev/doc/effective_go" || scraped.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected scrape: %+v", scraped)
+	}
+	if !strings.HasPrefix(scraped.Text, "## Introduction") || scraped.Text != scraped.Markdown || scraped.ContentHash == "" {
+		t.Errorf("Unexpected content: %+v", scraped)
+	}
+	if len(scraped.Links) != 2 || !strings.Contains(scraped.Metadata["og:image"], ", ") {
+		t.Errorf("Unexpected links %v or metadata %v", scraped.Links, scraped.Metadata)
+	}
+	if _, ok := scraped.Metadata["scrapeid"]; ok {
+		t.Errorf("Expected no API bookkeeping in metadata, got %v", scraped.Metadata)
+	}
+
+	if _, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "go.dev"}); err == nil {
+		t.Error("Expected an error for a relative URL")
+	}
+}
+
+func TestCredits(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+
+	var reporter omniserp.CreditReporter = engine
+	credits, err := reporter.Credits(context.Background())
+	if err != nil {
+		t.Fatalf("Credits failed: %v", err)
+	}
+	if credits.Engine != engineName || credits.Remaining != 2750 || credits.Limit != 3000 {
+		t.Errorf("Unexpected credits: %+v", credits)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
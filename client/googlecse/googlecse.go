@@ -0,0 +1,286 @@
+// Package googlecse implements the omniserp.Engine interface for the Custom
+// Search JSON API of a Google Programmable Search Engine. It needs an API key
+// and the ID of a search engine configured to search the entire web or a set
+// of sites.
+package googlecse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://www.googleapis.com"
+	engineName    = "googlecse"
+	engineVersion = "1.0.0"
+	searchPath    = "/customsearch/v1"
+
+	// maxNum is the largest number of results the API returns per request
+	maxNum = 10
+)
+
+// Engine implements the omniserp.Engine interface for Google Custom Search
+type Engine struct {
+	apiKey  string
+	cx      string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Google Custom Search engine from the GOOGLE_CSE_KEY and
+// GOOGLE_CSE_CX env vars
+func New() (*Engine, error) {
+	apiKey := os.Getenv("GOOGLE_CSE_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GOOGLE_CSE_KEY environment variable is required")
+	}
+	cx := os.Getenv("GOOGLE_CSE_CX")
+	if cx == "" {
+		return nil, fmt.Errorf("GOOGLE_CSE_CX environment variable is required")
+	}
+	return NewWithAPIKey(apiKey, cx)
+}
+
+// NewWithAPIKey creates a new Google Custom Search engine with the provided
+// API key and search engine ID
+func NewWithAPIKey(apiKey, cx string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if cx == "" {
+		return nil, fmt.Errorf("search engine ID is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		cx:      cx,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_images",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The API has no location
+// parameter and returns at most 10 results per request.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// dateRestrict returns the date restriction of the API; there is no hourly
+// restriction
+func dateRestrict(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour, omniserp.FreshnessDay:
+		return "d1"
+	case omniserp.FreshnessWeek:
+		return "w1"
+	case omniserp.FreshnessMonth:
+		return "m1"
+	case omniserp.FreshnessYear:
+		return "y1"
+	}
+	return ""
+}
+
+// buildParams converts SearchParams to Custom Search query parameters
+func (e *Engine) buildParams(params omniserp.SearchParams) url.Values {
+	q := url.Values{}
+	q.Set("key", e.apiKey)
+	q.Set("cx", e.cx)
+	q.Set("q", params.Query)
+
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+		q.Set("lr", "lang_"+strings.ToLower(params.Language))
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	num := min(params.NumResults, maxNum)
+	if num > 0 {
+		q.Set("num", strconv.Itoa(num))
+	}
+	if params.Page > 1 {
+		if num <= 0 {
+			num = maxNum
+		}
+		// start is the 1-based index of the first result
+		q.Set("start", strconv.Itoa((params.Page-1)*num+1))
+	}
+	if restrict := dateRestrict(params.Freshness); restrict != "" {
+		q.Set("dateRestrict", restrict)
+	}
+	if params.SafeSearch {
+		q.Set("safe", "active")
+	}
+	return q
+}
+
+// makeRequest performs a GET request against the Custom Search API
+func (e *Engine) makeRequest(ctx context.Context, params url.Values) (*omniserp.SearchResult, error) {
+	reqURL := e.baseURL + searchPath + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	// #nosec G704 -- request to the Custom Search endpoint or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		// Exhausted daily quotas are reported as 429
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &omniserp.SearchResult{
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.makeRequest(ctx, e.buildParams(params))
+}
+
+// SearchNews performs a news search (not supported by Google Custom Search)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Google Custom Search")
+}
+
+// SearchImages performs an image search. Transparency and license filters are
+// sent as imgColorType and rights; the aspect ratio and minimum size are
+// enforced by the client.
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := e.buildParams(params)
+	q.Set("searchType", "image")
+	if filter := params.Image; filter != nil {
+		if filter.Transparent {
+			q.Set("imgColorType", "trans")
+		}
+		switch filter.License {
+		case omniserp.LicenseCreativeCommons:
+			q.Set("rights", "cc_publicdomain|cc_attribute|cc_sharealike|cc_noncommercial|cc_nonderived")
+		case omniserp.LicenseCommercial:
+			q.Set("rights", "cc_publicdomain|cc_attribute|cc_sharealike|cc_nonderived")
+		}
+	}
+	return e.makeRequest(ctx, q)
+}
+
+// SearchVideos performs a video search (not supported by Google Custom Search)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Google Custom Search")
+}
+
+// SearchPlaces performs a places search (not supported by Google Custom Search)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Google Custom Search")
+}
+
+// SearchMaps performs a maps search (not supported by Google Custom Search)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Google Custom Search")
+}
+
+// SearchReviews performs a reviews search (not supported by Google Custom Search)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Google Custom Search")
+}
+
+// SearchShopping performs a shopping search (not supported by Google Custom Search)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Google Custom Search")
+}
+
+// SearchScholar performs a scholar search (not supported by Google Custom Search)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Google Custom Search")
+}
+
+// SearchLens performs a visual search (not supported by Google Custom Search)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Google Custom Search")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Google Custom Search)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Google Custom Search")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Google Custom Search)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Google Custom Search")
+}
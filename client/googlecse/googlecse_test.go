@@ -0,0 +1,172 @@
+package googlecse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestEngine creates an engine for a server answering with the fixture and
+// records the query of the last request
+func newTestEngine(t *testing.T, fixture string, status int) (*Engine, *url.Values) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != searchPath {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		w.WriteHeader(status)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key", "test-cx")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestEngine(t, "search.json", http.StatusOK)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Language:   "en",
+		Country:    "US",
+		NumResults: 2,
+		Page:       6,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"key":          "test-key",
+		"cx":           "test-cx",
+		"q":            "golang",
+		"hl":           "en",
+		"lr":           "lang_en",
+		"gl":           "us",
+		"num":          "2",
+		"start":        "11",
+		"dateRestrict": "w1",
+		"safe":         "active",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	first := normalized.OrganicResults[0]
+	if first.Title != "The Go Programming Language" || first.Link != "https://go.dev/" || first.Domain != "go.dev" || first.Engine != engineName {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if first.Snippet != "Go is an open source programming language that makes it simple to build secure, scalable systems." {
+		t.Errorf("Expected the snippet without the trailing newline, got %q", first.Snippet)
+	}
+	if meta := normalized.SearchMetadata; meta.TotalResults != 1230000 || meta.SuggestedQuery != "go lang" || meta.CorrectedQuery != "" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestSearchNumResults(t *testing.T) {
+	engine, query := newTestEngine(t, "search.json", http.StatusOK)
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", NumResults: 50, Page: 2}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := query.Get("num"); got != "10" {
+		t.Errorf("Expected num to be capped at 10, got %q", got)
+	}
+	if got := query.Get("start"); got != "11" {
+		t.Errorf("Expected start 11, got %q", got)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, query := newTestEngine(t, "images.json", http.StatusOK)
+
+	params := omniserp.SearchParams{
+		Query: "gopher",
+		Image: &omniserp.ImageFilter{Transparent: true, License: omniserp.LicenseCommercial},
+	}
+	result, err := engine.SearchImages(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+
+	want := map[string]string{
+		"searchType":   "image",
+		"imgColorType": "trans",
+		"rights":       "cc_publicdomain|cc_attribute|cc_sharealike|cc_nonderived",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeImages(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeImages failed: %v", err)
+	}
+	if len(normalized.ImageResults) != 1 {
+		t.Fatalf("Expected 1 image result, got %d", len(normalized.ImageResults))
+	}
+	image := normalized.ImageResults[0]
+	if image.ImageURL != "https://go.dev/images/gophers/gopher.png" || image.SourceURL != "https://go.dev/blog/gopher" || image.Source != "go.dev" {
+		t.Errorf("Unexpected image result: %+v", image)
+	}
+	if image.Width != 1200 || image.Height != 800 || image.Thumbnail == "" {
+		t.Errorf("Expected a 1200x800 image with a thumbnail, got %+v", image)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestEngine(t, "search.json", http.StatusTooManyRequests)
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected a 429 APIError, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("GOOGLE_CSE_KEY", "key")
+	t.Setenv("GOOGLE_CSE_CX", "")
+	if _, err := New(); err == nil {
+		t.Error("Expected an error without GOOGLE_CSE_CX")
+	}
+
+	t.Setenv("GOOGLE_CSE_CX", "cx")
+	if _, err := New(); err != nil {
+		t.Errorf("New failed: %v", err)
+	}
+}
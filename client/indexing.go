@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"log"
+
+	"github.com/plexusone/omniserp/index"
+)
+
+// SetIndexer sets the indexer that receives the results of normalized
+// searches and scrapes, or disables indexing if nil
+func (c *Client) SetIndexer(indexer index.Indexer) {
+	c.mu.Lock()
+	c.indexer = indexer
+	c.mu.Unlock()
+}
+
+// currentIndexer returns the indexer of results, or nil
+func (c *Client) currentIndexer() index.Indexer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.indexer
+}
+
+// index passes documents to the indexer. Indexing is best effort: a failure
+// is logged and does not fail the search that produced the documents.
+func (c *Client) index(ctx context.Context, docs []index.Document) {
+	indexer := c.currentIndexer()
+	if indexer == nil || len(docs) == 0 {
+		return
+	}
+	if err := indexer.Index(ctx, docs); err != nil {
+		log.Printf("Failed to index %d documents: %v", len(docs), err)
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/index"
+)
+
+// recordingIndexer collects indexed documents
+type recordingIndexer struct {
+	docs []index.Document
+}
+
+func (r *recordingIndexer) Index(ctx context.Context, docs []index.Document) error {
+	r.docs = append(r.docs, docs...)
+	return nil
+}
+
+func TestIndexer(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if params.Query == "https://example.com/page" {
+			return &omniserp.SearchResult{Data: map[string]any{"text": "page text"}}, nil
+		}
+		return organicResponse("https://a.example", "https://b.example"), nil
+	})
+	indexer := &recordingIndexer{}
+	c.SetIndexer(indexer)
+	ctx := context.Background()
+
+	if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if _, err := c.ScrapeNormalized(ctx, omniserp.ScrapeParams{URL: "https://example.com/page"}); err != nil {
+		t.Fatalf("ScrapeNormalized failed: %v", err)
+	}
+
+	if len(indexer.docs) != 3 {
+		t.Fatalf("Expected 3 indexed documents, got %d", len(indexer.docs))
+	}
+	if indexer.docs[0].Query != "golang" || indexer.docs[0].Kind != index.KindOrganic {
+		t.Errorf("Unexpected search document: %+v", indexer.docs[0])
+	}
+	if indexer.docs[2].Kind != index.KindPage || indexer.docs[2].Text != "page text" {
+		t.Errorf("Unexpected page document: %+v", indexer.docs[2])
+	}
+}
+
+func TestAnnotators(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://a.example"), nil
+	})
+	c.SetAnnotators(omniserp.NewKeywordSentiment())
+
+	result, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if a := result.OrganicResults[0].Annotations; a == nil || a.Sentiment != omniserp.SentimentNeutral {
+		t.Errorf("Expected neutral sentiment annotation, got %+v", a)
+	}
+}
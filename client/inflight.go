@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrEngineSwitched is the cancellation cause of requests cancelled by
+// CancelInFlight or SwitchEngine. Use context.Cause or errors.Is to detect it.
+var ErrEngineSwitched = errors.New("request cancelled: engine switched")
+
+// inflightRequests tracks cancel functions of outstanding requests per engine.
+// The zero value is ready to use.
+type inflightRequests struct {
+	mu     sync.Mutex
+	nextID uint64
+	byName map[string]map[uint64]context.CancelCauseFunc
+}
+
+// start derives a cancellable context for a request against engine and
+// returns it with a function that must be called when the request finishes
+func (r *inflightRequests) start(ctx context.Context, engine string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	if r.byName == nil {
+		r.byName = make(map[string]map[uint64]context.CancelCauseFunc)
+	}
+	if r.byName[engine] == nil {
+		r.byName[engine] = make(map[uint64]context.CancelCauseFunc)
+	}
+	r.nextID++
+	id := r.nextID
+	r.byName[engine][id] = cancel
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.byName[engine], id)
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel cancels all outstanding requests against engine and returns how
+// many were cancelled
+func (r *inflightRequests) cancel(engine string, cause error) int {
+	r.mu.Lock()
+	cancels := r.byName[engine]
+	delete(r.byName, engine)
+	r.mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel(cause)
+	}
+	return len(cancels)
+}
+
+// count returns the number of outstanding requests against engine
+func (r *inflightRequests) count(engine string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.byName[engine])
+}
+
+// InFlight returns the number of outstanding requests against an engine
+func (c *Client) InFlight(engine string) int {
+	return c.inflight.count(engine)
+}
+
+// CancelInFlight cancels all outstanding requests against an engine with
+// ErrEngineSwitched as the cause and returns how many were cancelled.
+// Cancelled requests are redirected to the current engine if it differs
+// from the cancelled one; otherwise they fail with ErrEngineSwitched.
+// Helpers such as SearchByLocations keep the results that completed, so
+// partial results are surfaced alongside the per-item errors.
+func (c *Client) CancelInFlight(engine string) int {
+	return c.inflight.cancel(engine, ErrEngineSwitched)
+}
+
+// SwitchEngine selects a new active engine like SetEngine and, if
+// cancelInFlight is true, cancels the outstanding requests against the
+// previous engine so they are redirected to the new one promptly.
+// It returns the number of cancelled requests.
+func (c *Client) SwitchEngine(name string, cancelInFlight bool) (int, error) {
+	previous := c.GetCurrentEngine().GetName()
+	if err := c.SetEngine(name); err != nil {
+		return 0, err
+	}
+	if !cancelInFlight || previous == name {
+		return 0, nil
+	}
+	return c.CancelInFlight(previous), nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// blockingEngine blocks searches until their context is cancelled
+type blockingEngine struct {
+	*fakeEngine
+	started chan struct{}
+}
+
+func (e *blockingEngine) Search(ctx context.Context, p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	e.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSwitchEngineCancelsInFlight(t *testing.T) {
+	slow := &blockingEngine{
+		fakeEngine: &fakeEngine{name: "serpapi", tools: AllOperations()},
+		started:    make(chan struct{}, 1),
+	}
+	registry := omniserp.NewRegistry()
+	registry.Register(slow)
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://example.com"), nil
+	}})
+
+	c, err := NewWithRegistry(registry, "serpapi")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	type outcome struct {
+		result *omniserp.NormalizedSearchResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: "q"})
+		done <- outcome{result, err}
+	}()
+
+	<-slow.started
+	if n := c.InFlight("serpapi"); n != 1 {
+		t.Errorf("Expected 1 in-flight request, got %d", n)
+	}
+	cancelled, err := c.SwitchEngine("serper", true)
+	if err != nil || cancelled != 1 {
+		t.Fatalf("Expected 1 cancelled request, got %d (err: %v)", cancelled, err)
+	}
+
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("Expected request to be redirected, got error: %v", got.err)
+	}
+	if got.result.SearchMetadata.Engine != "serper" || len(got.result.OrganicResults) != 1 {
+		t.Errorf("Expected redirected serper result, got %+v", got.result)
+	}
+	if s := c.Stats()["serpapi"]; s.Errors != 0 {
+		t.Errorf("Expected cancellation not to count as an engine error, got %+v", s)
+	}
+
+	// Cancelling the current engine's requests fails them with ErrEngineSwitched
+	if err := c.SetEngine("serpapi"); err != nil {
+		t.Fatalf("SetEngine failed: %v", err)
+	}
+	go func() {
+		_, err := c.Search(context.Background(), omniserp.SearchParams{Query: "q"})
+		done <- outcome{err: err}
+	}()
+	<-slow.started
+	c.CancelInFlight("serpapi")
+	if got := <-done; !errors.Is(got.err, ErrEngineSwitched) {
+		t.Errorf("Expected ErrEngineSwitched, got %v", got.err)
+	}
+}
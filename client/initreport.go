@@ -0,0 +1,102 @@
+package client
+
+import (
+	"log"
+	"slices"
+
+	"github.com/plexusone/omniserp"
+)
+
+// EngineInit is the outcome of initializing one engine
+type EngineInit struct {
+	Engine string `json:"engine"`
+	Loaded bool   `json:"loaded"`
+	Error  string `json:"error,omitempty"` // why the engine failed, such as a missing API key
+}
+
+// InitReport describes which engines a client registered when it was
+// created and why the others failed, so servers can report the engines
+// they actually offer. Clients created from a registry report its engines
+// as loaded.
+type InitReport struct {
+	Engines []EngineInit `json:"engines"`
+
+	// Selected is the engine selected at creation, and Fallback describes
+	// a fallback from the SEARCH_ENGINE engine, if any
+	Selected string                 `json:"selected"`
+	Fallback *omniserp.FallbackInfo `json:"fallback,omitempty"`
+}
+
+// Loaded returns the names of the engines that were registered, in
+// registration order
+func (r *InitReport) Loaded() []string {
+	var names []string
+	for _, e := range r.Engines {
+		if e.Loaded {
+			names = append(names, e.Engine)
+		}
+	}
+	return names
+}
+
+// Failed returns the error of each engine that failed to initialize by
+// engine name
+func (r *InitReport) Failed() map[string]string {
+	failed := make(map[string]string)
+	for _, e := range r.Engines {
+		if !e.Loaded {
+			failed[e.Engine] = e.Error
+		}
+	}
+	return failed
+}
+
+// Degraded reports whether any engine failed to initialize
+func (r *InitReport) Degraded() bool {
+	return slices.ContainsFunc(r.Engines, func(e EngineInit) bool { return !e.Loaded })
+}
+
+// InitReport returns a copy of the report of the engines initialized when
+// the client was created
+func (c *Client) InitReport() *InitReport {
+	if c.init == nil {
+		return &InitReport{}
+	}
+	report := *c.init
+	report.Engines = slices.Clone(c.init.Engines)
+	if c.init.Fallback != nil {
+		fallback := *c.init.Fallback
+		report.Fallback = &fallback
+	}
+	return &report
+}
+
+// registryReport returns the report of a client created from a registry,
+// with its engines in name order
+func registryReport(registry *omniserp.Registry, selected string) *InitReport {
+	report := &InitReport{Selected: selected}
+	names := registry.List()
+	slices.Sort(names)
+	for _, name := range names {
+		report.Engines = append(report.Engines, EngineInit{Engine: name, Loaded: true})
+	}
+	return report
+}
+
+// registerEngine creates a built-in engine, registers it if it initializes,
+// and records the outcome in report. Label names the engine in logs.
+func registerEngine[E omniserp.Engine](registry *omniserp.Registry, report *InitReport, silent bool, name, label string, newEngine func() (E, error)) {
+	engine, err := newEngine()
+	if err != nil {
+		report.Engines = append(report.Engines, EngineInit{Engine: name, Error: err.Error()})
+		if !silent {
+			log.Printf("Failed to initialize %s engine: %v", label, err)
+		}
+		return
+	}
+	registry.Register(engine)
+	report.Engines = append(report.Engines, EngineInit{Engine: engine.GetName(), Loaded: true})
+	if !silent {
+		log.Printf("Registered %s engine", label)
+	}
+}
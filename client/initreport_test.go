@@ -0,0 +1,95 @@
+package client
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestInitReport(t *testing.T) {
+	t.Setenv("SEARCH_ENGINE", "missing")
+	t.Setenv("SERPER_API_KEY", "")
+	t.Setenv("TAVILY_API_KEY", "test-key")
+	t.Setenv("OMNISERP_PROXY_URL", "")
+
+	c, err := NewWithOptions(&Options{Silent: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions failed: %v", err)
+	}
+	report := c.InitReport()
+
+	loaded := report.Loaded()
+	if !slices.Contains(loaded, "tavily") || !slices.Contains(loaded, "duckduckgo") {
+		t.Errorf("Expected tavily and duckduckgo loaded, got %v", loaded)
+	}
+	registered := c.GetRegistry().List()
+	slices.Sort(registered)
+	if !slices.Equal(slices.Sorted(slices.Values(loaded)), registered) {
+		t.Errorf("Expected the registered engines %v, got %v", registered, loaded)
+	}
+	if failed := report.Failed(); !strings.Contains(failed["serper"], "SERPER_API_KEY") {
+		t.Errorf("Expected serper to fail for its API key, got %v", failed)
+	}
+	if !report.Degraded() {
+		t.Error("Expected a degraded report")
+	}
+	if report.Selected != c.GetName() || report.Fallback == nil || report.Fallback.Requested != "missing" {
+		t.Errorf("Unexpected selection: %s, %+v", report.Selected, report.Fallback)
+	}
+
+	// The report is a copy
+	report.Engines[0].Loaded = !report.Engines[0].Loaded
+	if c.InitReport().Engines[0].Loaded == report.Engines[0].Loaded {
+		t.Error("Expected InitReport to return a copy")
+	}
+}
+
+func TestInitReportRegistry(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper"})
+	registry.Register(&fakeEngine{name: "tavily"})
+	c, err := NewWithRegistry(registry, "tavily")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	report := c.InitReport()
+	if report.Degraded() || report.Selected != "tavily" || len(report.Failed()) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if loaded := report.Loaded(); !slices.Equal(loaded, []string{"serper", "tavily"}) {
+		t.Errorf("Expected serper and tavily loaded, got %v", loaded)
+	}
+
+	if report := (&Client{}).InitReport(); len(report.Engines) != 0 {
+		t.Errorf("Expected an empty report for a zero client, got %+v", report)
+	}
+}
+
+func TestNoKeylessEngines(t *testing.T) {
+	for _, name := range []string{
+		"SERPER_API_KEY", "SERPAPI_API_KEY", "SEARXNG_URL", "GOOGLE_CSE_KEY", "KAGI_API_KEY",
+		"TAVILY_API_KEY", "EXA_API_KEY", "YDC_API_KEY", "MOJEEK_API_KEY",
+		"DATAFORSEO_LOGIN", "VALUESERP_API_KEY", "SCALESERP_API_KEY", "ZENSERP_API_KEY",
+		"SEARCHAPI_API_KEY", "BRIGHTDATA_API_TOKEN", "APIFY_TOKEN", "PERPLEXITY_API_KEY",
+		"JINA_API_KEY", "FIRECRAWL_API_KEY", "ELASTICSEARCH_URL", "SEARCH_ENGINE", "OMNISERP_PROXY_URL",
+	} {
+		t.Setenv(name, "")
+	}
+
+	_, err := NewWithOptions(&Options{Silent: true, NoKeylessEngines: true})
+	if !errors.Is(err, omniserp.ErrNoEngines) {
+		t.Errorf("Expected ErrNoEngines without API keys, got %v", err)
+	}
+
+	c, err := NewWithOptions(&Options{Silent: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions failed: %v", err)
+	}
+	if loaded := c.InitReport().Loaded(); !slices.Equal(slices.Sorted(slices.Values(loaded)), []string{"duckduckgo", "wikipedia"}) {
+		t.Errorf("Expected only the keyless engines, got %v", loaded)
+	}
+}
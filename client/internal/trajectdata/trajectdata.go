@@ -0,0 +1,300 @@
+// Package trajectdata implements the Google SERP APIs of Traject Data,
+// ValueSerp and ScaleSERP, which share their request parameters, response
+// format and error reporting. The valueserp and scaleserp packages configure
+// an Engine with their name, base URL and search types.
+package trajectdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	engineVersion = "1.0.0"
+	searchPath    = "/search"
+
+	// maxNum is the largest number of results the APIs return per request
+	maxNum = 100
+)
+
+// Config describes one Traject Data API
+type Config struct {
+	// Name is the engine name, such as valueserp
+	Name string
+
+	// Title is the product name used in errors, such as ValueSerp
+	Title string
+
+	// BaseURL is the API base URL, such as https://api.valueserp.com
+	BaseURL string
+
+	// Tools lists the supported tools, which are among those in
+	// searchTypes
+	Tools []string
+}
+
+// searchTypes maps the tools of the APIs to the search_type parameter,
+// which is empty for web searches
+var searchTypes = map[string]string{
+	"google_search":          "",
+	"google_search_news":     "news",
+	"google_search_images":   "images",
+	"google_search_places":   "places",
+	"google_search_shopping": "shopping",
+}
+
+// Engine implements the omniserp.Engine interface for a Traject Data API
+type Engine struct {
+	config  Config
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates an engine for the API of config with the provided API key
+func New(config Config, apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		config:  config,
+		apiKey:  apiKey,
+		baseURL: config.BaseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return e.config.Name
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return slices.Clone(e.config.Tools)
+}
+
+// SupportedParams implements omniserp.ParamReporter. Freshness applies to
+// web and news searches only.
+func (e *Engine) SupportedParams(operation string) []string {
+	params := []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLocation,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamSafeSearch,
+	}
+	switch operation {
+	case "google_search", "google_search_news":
+		params = append(params, omniserp.ParamFreshness)
+	}
+	return params
+}
+
+// Extra declares the search parameters of the APIs accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Device       string `extra:"device" description:"Device to emulate: desktop, tablet, or mobile"`
+	GoogleDomain string `extra:"google_domain" description:"Google domain to search, such as google.co.uk"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// timePeriods maps freshness to the time_period parameter
+var timePeriods = map[omniserp.Freshness]string{
+	omniserp.FreshnessHour:  "last_hour",
+	omniserp.FreshnessDay:   "last_day",
+	omniserp.FreshnessWeek:  "last_week",
+	omniserp.FreshnessMonth: "last_month",
+	omniserp.FreshnessYear:  "last_year",
+}
+
+// buildParams converts SearchParams to query parameters for a search type,
+// which is empty for web searches
+func (e *Engine) buildParams(params omniserp.SearchParams, searchType string) url.Values {
+	q := url.Values{}
+	q.Set("api_key", e.apiKey)
+	q.Set("q", params.Query)
+	if searchType != "" {
+		q.Set("search_type", searchType)
+	}
+
+	if params.Location != "" {
+		q.Set("location", params.Location)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.GoogleDomain != "" {
+		q.Set("google_domain", params.GoogleDomain)
+	}
+	if params.NumResults > 0 {
+		q.Set("num", strconv.Itoa(min(params.NumResults, maxNum)))
+	}
+	if params.Page > 1 {
+		q.Set("page", strconv.Itoa(params.Page))
+	}
+	if period, ok := timePeriods[params.Freshness]; ok && (searchType == "" || searchType == "news") {
+		q.Set("time_period", period)
+	}
+	if params.SafeSearch {
+		q.Set("safe", "active")
+	}
+	for name, value := range omniserp.ExtraQuery(params, e.ExtraParams()) {
+		q.Set(name, value)
+	}
+	return q
+}
+
+// search performs the request of a tool. The APIs report failed requests
+// in the request_info of the response.
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams, tool string) (*omniserp.SearchResult, error) {
+	if !slices.Contains(e.config.Tools, tool) {
+		return nil, fmt.Errorf("%s is not supported by %s", tool, e.config.Title)
+	}
+
+	reqURL := e.baseURL + searchPath + "?" + e.buildParams(params, searchTypes[tool]).Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	// #nosec G704 -- request to the Traject Data API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if info, ok := result["request_info"].(map[string]any); ok {
+		if success, ok := info["success"].(bool); ok && !success {
+			message, _ := info["message"].(string)
+			return nil, fmt.Errorf("%s error: %s", e.config.Name, message)
+		}
+	}
+
+	return &omniserp.SearchResult{
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search")
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_news")
+}
+
+// SearchImages performs an image search, if the API supports it
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_images")
+}
+
+// SearchVideos performs a video search (not supported by the APIs)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_videos")
+}
+
+// SearchPlaces performs a places search
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_places")
+}
+
+// SearchMaps performs a maps search (not supported by the APIs)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_maps")
+}
+
+// SearchReviews performs a reviews search (not supported by the APIs)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_reviews")
+}
+
+// SearchShopping performs a Google Shopping search, if the API supports it
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_shopping")
+}
+
+// SearchScholar performs a scholar search (not supported by the APIs)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_scholar")
+}
+
+// SearchLens performs a visual search (not supported by the APIs)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_lens")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by the APIs)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, "google_search_autocomplete")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by the APIs)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by %s", e.config.Title)
+}
@@ -0,0 +1,163 @@
+package trajectdata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+var testConfig = Config{
+	Name:  "testserp",
+	Title: "TestSERP",
+	Tools: []string{"google_search", "google_search_news", "google_search_images", "google_search_places"},
+}
+
+// newTestServer answers every authorized search with an empty result and
+// records the query of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Path != searchPath || query.Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"request_info":{"success":false,"message":"Invalid API key"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"request_info":{"success":true},"organic_results":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := New(testConfig, "test-key")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSearchParams(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Location:   "Austin,Texas,United States",
+		Country:    "US",
+		Language:   "en",
+		NumResults: 150,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Extra:      map[string]any{"device": "mobile", "google_domain": "google.co.uk"},
+	}
+	if _, err := engine.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"q":             "golang",
+		"location":      "Austin,Texas,United States",
+		"gl":            "us",
+		"hl":            "en",
+		"num":           "100",
+		"page":          "2",
+		"time_period":   "last_week",
+		"safe":          "active",
+		"device":        "mobile",
+		"google_domain": "google.co.uk",
+		"search_type":   "",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestSearchTypes(t *testing.T) {
+	engine, query := newTestServer(t)
+	params := omniserp.SearchParams{Query: "golang", Freshness: omniserp.FreshnessDay}
+
+	tests := []struct {
+		name       string
+		search     func(context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
+		searchType string
+		freshness  bool
+	}{
+		{"Search", engine.Search, "", true},
+		{"SearchNews", engine.SearchNews, "news", true},
+		{"SearchImages", engine.SearchImages, "images", false},
+		{"SearchPlaces", engine.SearchPlaces, "places", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.search(context.Background(), params); err != nil {
+				t.Fatalf("%s failed: %v", tt.name, err)
+			}
+			if query.Get("search_type") != tt.searchType || query.Has("time_period") != tt.freshness {
+				t.Errorf("Unexpected query %s", query.Encode())
+			}
+		})
+	}
+}
+
+func TestUnsupportedTools(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	_, err := engine.SearchShopping(context.Background(), omniserp.SearchParams{Query: "keyboard"})
+	if err == nil || err.Error() != "google_search_shopping is not supported by TestSERP" {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+	if _, err := engine.SearchVideos(context.Background(), omniserp.SearchParams{Query: "golang"}); err == nil {
+		t.Error("Expected an error for video searches")
+	}
+
+	tools := engine.GetSupportedTools()
+	tools[0] = "changed"
+	if engine.GetSupportedTools()[0] != "google_search" {
+		t.Error("GetSupportedTools must return a copy of the configured tools")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
+
+func TestRequestInfoError(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "error.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	engine, _ := New(testConfig, "test-key")
+	engine.SetBaseURL(srv.URL)
+	_, err = engine.SearchPlaces(context.Background(), omniserp.SearchParams{Query: "coffee"})
+	if err == nil || !strings.HasPrefix(err.Error(), "testserp error: ") || !strings.Contains(err.Error(), "location parameter is required") {
+		t.Errorf("Expected the request_info message as the error, got %v", err)
+	}
+}
+
+func TestNewRequiresAPIKey(t *testing.T) {
+	if _, err := New(testConfig, ""); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ErrIterDone is returned by SearchIterator.Next when there are no more pages
+var ErrIterDone = errors.New("no more result pages")
+
+// SearchIterOptions configures a SearchIterator
+type SearchIterOptions struct {
+	// MaxPages limits the number of pages returned (0 means no limit; the
+	// iterator stops at the first page without organic results)
+	MaxPages int
+
+	// Prefetch fetches the next page in the background as soon as a page is
+	// returned, so continuation is served from memory
+	Prefetch bool
+}
+
+// pageFetch is the outcome of fetching one page
+type pageFetch struct {
+	result *omniserp.NormalizedSearchResult
+	err    error
+}
+
+// SearchIterator pages through normalized web search results. Result
+// positions continue across pages, and PagePosition keeps the position on
+// each page.
+// It is not safe for concurrent use; call Close to stop any pending prefetch.
+type SearchIterator struct {
+	client *Client
+	params omniserp.SearchParams
+	opts   SearchIterOptions
+
+	page int
+	done bool
+
+	// offset is the number of organic results on the pages already returned
+	offset int
+
+	// prefetched receives the next page when a prefetch is in flight
+	prefetched  chan pageFetch
+	prefetchCtx context.Context
+	cancel      context.CancelFunc
+	closeOnce   sync.Once
+}
+
+// SearchIter returns an iterator over the pages of a web search, starting at
+// params.Page (default 1)
+func (c *Client) SearchIter(params omniserp.SearchParams, opts *SearchIterOptions) *SearchIterator {
+	if opts == nil {
+		opts = &SearchIterOptions{}
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	return &SearchIterator{
+		client: c,
+		params: params,
+		opts:   *opts,
+		page:   params.Page,
+		offset: pageOffset(params),
+	}
+}
+
+// Page returns the page number that the next call to Next will return
+func (it *SearchIterator) Page() int {
+	return it.page
+}
+
+// Next returns the next page of results, or ErrIterDone when the results are
+// exhausted or MaxPages has been reached
+func (it *SearchIterator) Next(ctx context.Context) (*omniserp.NormalizedSearchResult, error) {
+	if it.done {
+		return nil, ErrIterDone
+	}
+
+	var fetched pageFetch
+	if it.prefetched != nil {
+		select {
+		case fetched = <-it.prefetched:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		it.prefetched = nil
+	} else {
+		fetched.result, fetched.err = it.fetch(ctx, it.page)
+	}
+
+	if fetched.err != nil {
+		return nil, fetched.err
+	}
+	if len(fetched.result.OrganicResults) == 0 {
+		it.done = true
+		return nil, ErrIterDone
+	}
+
+	// Number positions by the results actually returned, which may be fewer
+	// than a full page
+	fetched.result.OffsetPositions(it.offset)
+	it.offset += len(fetched.result.OrganicResults)
+
+	it.page++
+	if it.opts.MaxPages > 0 && it.page-it.params.Page >= it.opts.MaxPages {
+		it.done = true
+	} else if it.opts.Prefetch {
+		it.prefetch(ctx)
+	}
+
+	return fetched.result, nil
+}
+
+// Close cancels a pending prefetch. The iterator returns ErrIterDone afterwards.
+func (it *SearchIterator) Close() {
+	it.closeOnce.Do(func() {
+		it.done = true
+		if it.cancel != nil {
+			it.cancel()
+		}
+	})
+}
+
+// fetch retrieves one page
+func (it *SearchIterator) fetch(ctx context.Context, page int) (*omniserp.NormalizedSearchResult, error) {
+	params := it.params
+	params.Page = page
+	return it.client.SearchNormalized(ctx, params)
+}
+
+// prefetch starts fetching the current page in the background. The fetch
+// outlives the Next call that started it but is cancelled by Close.
+func (it *SearchIterator) prefetch(ctx context.Context) {
+	if it.cancel == nil {
+		it.prefetchCtx, it.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	}
+
+	ch := make(chan pageFetch, 1)
+	it.prefetched = ch
+	bg, page := it.prefetchCtx, it.page
+	go func() {
+		result, err := it.fetch(bg, page)
+		ch <- pageFetch{result: result, err: err}
+	}()
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestSearchIter(t *testing.T) {
+	var calls atomic.Int32
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		calls.Add(1)
+		if p.Page > 3 {
+			return organicResponse(), nil
+		}
+		return organicResponse(fmt.Sprintf("https://example.com/%d", p.Page)), nil
+	})
+	ctx := context.Background()
+
+	for _, prefetch := range []bool{false, true} {
+		calls.Store(0)
+		it := c.SearchIter(omniserp.SearchParams{Query: "q"}, &SearchIterOptions{Prefetch: prefetch})
+
+		var links []string
+		for {
+			page, err := it.Next(ctx)
+			if errors.Is(err, ErrIterDone) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			links = append(links, page.OrganicResults[0].Link)
+		}
+		it.Close()
+
+		if len(links) != 3 || links[2] != "https://example.com/3" {
+			t.Errorf("prefetch=%v: unexpected pages %v", prefetch, links)
+		}
+		if got := calls.Load(); got != 4 {
+			t.Errorf("prefetch=%v: expected 4 fetches, got %d", prefetch, got)
+		}
+	}
+
+	it := c.SearchIter(omniserp.SearchParams{Query: "q", Page: 2}, &SearchIterOptions{MaxPages: 1, Prefetch: true})
+	defer it.Close()
+	page, err := it.Next(ctx)
+	if err != nil || page.OrganicResults[0].Link != "https://example.com/2" {
+		t.Fatalf("Expected page 2, got %v (err: %v)", page, err)
+	}
+	if _, err := it.Next(ctx); !errors.Is(err, ErrIterDone) {
+		t.Errorf("Expected ErrIterDone after MaxPages, got %v", err)
+	}
+}
+
+func TestSearchIterPositions(t *testing.T) {
+	// Short pages: page 1 returns 3 results, page 2 returns 2
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		switch p.Page {
+		case 1:
+			return organicResponse("a", "b", "c"), nil
+		case 2:
+			return organicResponse("d", "e"), nil
+		}
+		return organicResponse(), nil
+	})
+	it := c.SearchIter(omniserp.SearchParams{Query: "q"}, nil)
+	defer it.Close()
+
+	var positions [][3]int
+	for {
+		page, err := it.Next(context.Background())
+		if errors.Is(err, ErrIterDone) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		for _, r := range page.OrganicResults {
+			positions = append(positions, [3]int{r.Position, r.PagePosition, r.SourcePosition})
+		}
+	}
+
+	want := [][3]int{{1, 1, 1}, {2, 2, 2}, {3, 3, 3}, {4, 1, 4}, {5, 2, 5}}
+	if fmt.Sprint(positions) != fmt.Sprint(want) {
+		t.Errorf("Expected positions %v, got %v", want, positions)
+	}
+
+	result, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: "q", Page: 2, NumResults: 3})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if r := result.OrganicResults[0]; r.Position != 4 || r.PagePosition != 1 {
+		t.Errorf("Expected page 2 to start at position 4, got %d (page position %d)", r.Position, r.PagePosition)
+	}
+}
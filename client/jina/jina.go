@@ -0,0 +1,351 @@
+// Package jina implements the omniserp.Engine interface for Jina AI's Search
+// Foundation APIs: the Search API (s.jina.ai) for web searches and the Reader
+// API (r.jina.ai) for webpage scrapes, both of which return page content as
+// markdown for LLM consumers.
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	defaultSearchURL = "https://s.jina.ai"
+	defaultReaderURL = "https://r.jina.ai"
+	engineName       = "jina"
+	engineVersion    = "1.0.0"
+)
+
+// Engine implements the omniserp.Engine interface for Jina. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult, or a *omniserp.NormalizedScrapeResult
+// for scrapes.
+type Engine struct {
+	apiKey    string
+	searchURL string
+	readerURL string
+	client    *http.Client
+}
+
+// New creates a new Jina engine from the JINA_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("JINA_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("JINA_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Jina engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:    apiKey,
+		searchURL: defaultSearchURL,
+		readerURL: defaultReaderURL,
+		client:    &http.Client{},
+	}, nil
+}
+
+// SetBaseURL sends the requests of both APIs to one host instead of
+// s.jina.ai and r.jina.ai, for testing or a proxy. Reader requests are told
+// apart by their path, which is the URL to read.
+func (e *Engine) SetBaseURL(u string) {
+	u = strings.TrimSuffix(u, "/")
+	e.searchURL, e.readerURL = u, u
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"webpage_scrape",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	if operation == "webpage_scrape" {
+		return nil
+	}
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLocation,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+	}
+}
+
+// Extra declares the Jina-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Site           string `extra:"site" description:"Only search pages of this domain"`
+	IncludeContent bool   `extra:"include_content" description:"Read each result and include its markdown as raw_content, at a higher token cost"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// searchResponse is the JSON response of the Search API
+type searchResponse struct {
+	Data []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+		Content     string `json:"content"`
+		Date        string `json:"date"`
+	} `json:"data"`
+}
+
+// readResponse is the JSON response of the Reader API
+type readResponse struct {
+	Data struct {
+		Title         string `json:"title"`
+		Description   string `json:"description"`
+		URL           string `json:"url"`
+		Content       string `json:"content"`
+		PublishedTime string `json:"publishedTime"`
+	} `json:"data"`
+}
+
+// get performs a GET request with the given headers and returns the raw
+// JSON response
+func (e *Engine) get(ctx context.Context, reqURL string, headers map[string]string) ([]byte, *omniserp.ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	// #nosec G704 -- request to the Jina APIs or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(raw), Response: meta}
+	}
+	return raw, meta, nil
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a web search. Results carry their description as the
+// snippet; with the "include_content" extra parameter each result is read
+// and its markdown returned as RawContent.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	if params.Location != "" {
+		q.Set("location", params.Location)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.NumResults > 0 {
+		q.Set("num", strconv.Itoa(params.NumResults))
+	}
+	if params.Page > 1 {
+		q.Set("page", strconv.Itoa(params.Page))
+	}
+
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	headers := map[string]string{}
+	if site, _ := extra["site"].(string); site != "" {
+		headers["X-Site"] = site
+	}
+	if content, _ := extra["include_content"].(bool); !content {
+		headers["X-Respond-With"] = "no-content"
+	}
+
+	raw, meta, err := e.get(ctx, e.searchURL+"/?"+q.Encode(), headers)
+	if err != nil {
+		return nil, err
+	}
+	var resp searchResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			Location:  params.Location,
+			Language:  params.Language,
+			Country:   params.Country,
+			TimeTaken: meta.Latency.Seconds(),
+		},
+	}
+	for i, r := range resp.Data {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position:   i + 1,
+			Title:      r.Title,
+			Link:       r.URL,
+			URL:        r.URL,
+			Snippet:    r.Description,
+			Domain:     hostname(r.URL),
+			Date:       r.Date,
+			RawContent: r.Content,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: string(raw), Response: meta}, nil
+}
+
+// ScrapeWebpage reads a page with the Reader API, which renders it in a
+// browser and returns its main content as markdown. The markdown is also
+// returned as the text, as the API returns one format per request.
+// Redirect options are not honored.
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	if _, err := url.ParseRequestURI(params.URL); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	raw, meta, err := e.get(ctx, e.readerURL+"/"+params.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp readResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	page := resp.Data
+	scraped := &omniserp.NormalizedScrapeResult{
+		URL:         params.URL,
+		FinalURL:    page.URL,
+		StatusCode:  meta.StatusCode,
+		Title:       page.Title,
+		Text:        page.Content,
+		Markdown:    page.Content,
+		Links:       omniserp.MarkdownLinks(page.Content),
+		ContentHash: omniserp.ContentHash(page.Content),
+		Engine:      engineName,
+		FetchedAt:   time.Now(),
+	}
+	if page.Description != "" || page.PublishedTime != "" {
+		scraped.Metadata = map[string]string{}
+		if page.Description != "" {
+			scraped.Metadata["description"] = page.Description
+		}
+		if page.PublishedTime != "" {
+			scraped.Metadata["published_time"] = page.PublishedTime
+		}
+	}
+	return &omniserp.SearchResult{Data: scraped, Raw: string(raw), Response: meta}, nil
+}
+
+// SearchNews performs a news search (not supported by Jina)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Jina")
+}
+
+// SearchImages performs an image search (not supported by Jina)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Jina")
+}
+
+// SearchVideos performs a video search (not supported by Jina)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Jina")
+}
+
+// SearchPlaces performs a places search (not supported by Jina)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Jina")
+}
+
+// SearchMaps performs a maps search (not supported by Jina)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Jina")
+}
+
+// SearchReviews performs a reviews search (not supported by Jina)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Jina")
+}
+
+// SearchShopping performs a shopping search (not supported by Jina)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Jina")
+}
+
+// SearchScholar performs a scholar search (not supported by Jina)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Jina")
+}
+
+// SearchLens performs a visual search (not supported by Jina)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Jina")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Jina)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Jina")
+}
@@ -0,0 +1,137 @@
+package jina
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the search fixture at the root and the reader
+// fixture at the path of a URL, and records the last request
+func newTestServer(t *testing.T) (*Engine, **http.Request) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, read := fixture("search.json"), fixture("read.json")
+
+	var last *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"data":null,"code":401,"name":"AuthenticationRequiredError","status":40103,"message":"Invalid API key"}`))
+			return
+		}
+		switch {
+		case r.URL.Path == "/" && r.URL.Query().Get("q") != "":
+			_, _ = w.Write(search)
+		case strings.HasPrefix(r.URL.Path, "/https://"):
+			_, _ = w.Write(read)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &last
+}
+
+func TestSearch(t *testing.T) {
+	engine, last := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Country:    "DE",
+		Language:   "de",
+		NumResults: 5,
+		Page:       2,
+		Extra:      map[string]any{"site": "go.dev"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := url.Values{"q": {"golang"}, "gl": {"de"}, "hl": {"de"}, "num": {"5"}, "page": {"2"}}
+	if got := (*last).URL.Query(); got.Encode() != want.Encode() {
+		t.Errorf("Expected query %s, got %s", want.Encode(), got.Encode())
+	}
+	if h := (*last).Header; h.Get("X-Site") != "go.dev" || h.Get("X-Respond-With") != "no-content" || h.Get("Accept") != "application/json" {
+		t.Errorf("Unexpected headers: %v", h)
+	}
+
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(normalized.OrganicResults))
+	}
+	if second := normalized.OrganicResults[1]; second.Position != 2 || second.Domain != "github.com" || second.Snippet == "" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+
+	params.Extra = map[string]any{"include_content": "true"}
+	if _, err := engine.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if (*last).Header.Get("X-Respond-With") != "" {
+		t.Error("Expected the content to be requested with include_content")
+	}
+}
+
+func TestScrapeWebpage(t *testing.T) {
+	engine, last := newTestServer(t)
+
+	result, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "https://go.dev/doc/effective_go"})
+	if err != nil {
+		t.Fatalf("ScrapeWebpage failed: %v", err)
+	}
+	if (*last).URL.Path != "/https://go.dev/doc/effective_go" {
+		t.Errorf("Expected the page URL as the path, got %s", (*last).URL.Path)
+	}
+
+	scraped, ok := result.Data.(*omniserp.NormalizedScrapeResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	if scraped.Title != "Effective Go" || !strings.HasPrefix(scraped.Markdown, "## Introduction") || scraped.ContentHash == "" {
+		t.Errorf("Unexpected scrape: %+v", scraped)
+	}
+	if len(scraped.Links) != 2 || scraped.Metadata["published_time"] != "2009-11-10T00:00:00Z" {
+		t.Errorf("Unexpected links %v or metadata %v", scraped.Links, scraped.Metadata)
+	}
+
+	if _, err := engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: "go.dev"}); err == nil {
+		t.Error("Expected an error for a relative URL")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
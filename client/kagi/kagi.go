@@ -0,0 +1,333 @@
+// Package kagi implements the omniserp.Engine interface for the Kagi API.
+// Web searches use the Search API, or the web index of the Enrichment API,
+// which covers the non-commercial web, with the "enrich" extra parameter;
+// news searches use the news index of the Enrichment API.
+package kagi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://kagi.com/api/v0"
+	engineName    = "kagi"
+	engineVersion = "1.0.0"
+
+	searchPath     = "/search"
+	enrichWebPath  = "/enrich/web"
+	enrichNewsPath = "/enrich/news"
+)
+
+// Types of the objects in the data of a response
+const (
+	typeResult  = 0
+	typeRelated = 1
+)
+
+// Engine implements the omniserp.Engine interface for Kagi. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Kagi engine from the KAGI_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("KAGI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("KAGI_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Kagi engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The Kagi API only takes
+// the query and a limit, and has no pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamNumResults,
+	}
+}
+
+// Extra declares the Kagi-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Enrich bool `extra:"enrich" description:"Search the non-commercial web of the Enrichment API instead of the full index"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// response is the JSON response of the Search and Enrichment APIs
+type response struct {
+	Meta struct {
+		MS float64 `json:"ms"`
+	} `json:"meta"`
+	Data  []item `json:"data"`
+	Error []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// item is a search result or a list of related searches, by type
+type item struct {
+	T         int    `json:"t"`
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Snippet   string `json:"snippet"`
+	Published string `json:"published"`
+	Thumbnail *struct {
+		URL string `json:"url"`
+	} `json:"thumbnail"`
+	List []string `json:"list"`
+}
+
+// get performs a GET request against a Kagi endpoint and parses the response
+func (e *Engine) get(ctx context.Context, path string, params url.Values) (*response, string, *omniserp.ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bot "+e.apiKey)
+
+	start := time.Now()
+	// #nosec G704 -- request to the Kagi API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(parsed.Error) > 0 {
+		errs := make([]error, 0, len(parsed.Error))
+		for _, apiErr := range parsed.Error {
+			errs = append(errs, fmt.Errorf("kagi error %d: %s", apiErr.Code, apiErr.Msg))
+		}
+		return nil, "", nil, errors.Join(errs...)
+	}
+	return &parsed, string(body), meta, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, resp *response) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			TimeTaken: resp.Meta.MS / 1000,
+		},
+	}
+}
+
+// Search performs a general web search. With the "enrich" extra parameter,
+// it searches the web index of the Enrichment API, which returns a fixed
+// number of results truncated to NumResults.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	path := searchPath
+	if enrich, _ := omniserp.ExtraArgs(params, e.ExtraParams())["enrich"].(bool); enrich {
+		path = enrichWebPath
+	} else if params.NumResults > 0 {
+		q.Set("limit", strconv.Itoa(params.NumResults))
+	}
+
+	resp, raw, meta, err := e.get(ctx, path, q)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for _, it := range resp.Data {
+		switch it.T {
+		case typeResult:
+			if params.NumResults > 0 && len(normalized.OrganicResults) == params.NumResults {
+				continue
+			}
+			normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+				Position: len(normalized.OrganicResults) + 1,
+				Title:    it.Title,
+				Link:     it.URL,
+				URL:      it.URL,
+				Snippet:  it.Snippet,
+				Date:     it.Published,
+			})
+		case typeRelated:
+			for _, query := range it.List {
+				normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: query})
+			}
+		}
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search on the news index of the Enrichment API,
+// which covers non-commercial news and discussions
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("q", params.Query)
+
+	resp, raw, meta, err := e.get(ctx, enrichNewsPath, q)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for _, it := range resp.Data {
+		if it.T != typeResult {
+			continue
+		}
+		if params.NumResults > 0 && len(normalized.NewsResults) == params.NumResults {
+			break
+		}
+		news := omniserp.NewsResult{
+			Position: len(normalized.NewsResults) + 1,
+			Title:    it.Title,
+			Link:     it.URL,
+			Date:     it.Published,
+			Snippet:  it.Snippet,
+		}
+		if u, err := url.Parse(it.URL); err == nil {
+			news.Source = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+		if it.Thumbnail != nil {
+			news.Thumbnail = it.Thumbnail.URL
+		}
+		normalized.NewsResults = append(normalized.NewsResults, news)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search (not supported by Kagi)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Kagi")
+}
+
+// SearchVideos performs a video search (not supported by Kagi)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Kagi")
+}
+
+// SearchPlaces performs a places search (not supported by Kagi)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Kagi")
+}
+
+// SearchMaps performs a maps search (not supported by Kagi)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Kagi")
+}
+
+// SearchReviews performs a reviews search (not supported by Kagi)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Kagi")
+}
+
+// SearchShopping performs a shopping search (not supported by Kagi)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Kagi")
+}
+
+// SearchScholar performs a scholar search (not supported by Kagi)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Kagi")
+}
+
+// SearchLens performs a visual search (not supported by Kagi)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Kagi")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Kagi)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Kagi")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Kagi)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Kagi")
+}
@@ -0,0 +1,138 @@
+package kagi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixtures of the Search API and the Enrichment
+// API, and records the path and query of the last request
+func newTestServer(t *testing.T) (*Engine, *string, *url.Values) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, news := fixture("search.json"), fixture("news.json")
+
+	var path string
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bot test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"meta":{},"data":null,"error":[{"code":1,"msg":"Unauthorized"}]}`))
+			return
+		}
+		path, query = r.URL.Path, r.URL.Query()
+		switch path {
+		case searchPath, enrichWebPath:
+			_, _ = w.Write(search)
+		case enrichNewsPath:
+			_, _ = w.Write(news)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &path, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, path, query := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", NumResults: 2}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if *path != searchPath || query.Get("q") != "golang" || query.Get("limit") != "2" {
+		t.Errorf("Unexpected request %s?%s", *path, query.Encode())
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected NumResults to limit the results to 2, got %d", len(normalized.OrganicResults))
+	}
+	second := normalized.OrganicResults[1]
+	if second.Link != "https://github.com/golang/go" || second.Position != 2 || second.Date != "2026-01-02T00:00:00Z" || second.Engine != engineName {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[1].Query != "golang generics" {
+		t.Errorf("Expected related searches, got %+v", normalized.RelatedSearches)
+	}
+	if normalized.SearchMetadata.TimeTaken != 0.412 {
+		t.Errorf("Expected the time taken in seconds, got %v", normalized.SearchMetadata.TimeTaken)
+	}
+}
+
+func TestSearchEnrich(t *testing.T) {
+	engine, path, query := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", NumResults: 1, Extra: map[string]any{"enrich": "true"}}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if *path != enrichWebPath || query.Has("limit") {
+		t.Errorf("Expected the web enrichment endpoint without a limit, got %s?%s", *path, query.Encode())
+	}
+	if normalized := result.Data.(*omniserp.NormalizedSearchResult); len(normalized.OrganicResults) != 1 {
+		t.Errorf("Expected the results to be truncated to 1, got %d", len(normalized.OrganicResults))
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, path, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if *path != enrichNewsPath {
+		t.Errorf("Expected the news enrichment endpoint, got %s", *path)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 2 {
+		t.Fatalf("Expected 2 news results, got %d", len(normalized.NewsResults))
+	}
+	news := normalized.NewsResults[0]
+	if news.Source != "example.org" || news.Date != "2026-02-10T00:00:00Z" || news.Thumbnail != "https://www.example.org/gopher.png" {
+		t.Errorf("Unexpected news result: %+v", news)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || !strings.Contains(apiErr.Body, "Unauthorized") {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/plexusone/omniserp"
+)
+
+// matrixConcurrency bounds the parallel searches issued by matrix helpers
+const matrixConcurrency = 5
+
+// LocationResult is the normalized result of a search for one location
+type LocationResult struct {
+	Location string                           `json:"location"`
+	Result   *omniserp.NormalizedSearchResult `json:"result,omitempty"`
+	Error    string                           `json:"error,omitempty"`
+}
+
+// LocationMatrix holds the per-location results of SearchByLocations
+type LocationMatrix struct {
+	Query   string           `json:"query"`
+	Results []LocationResult `json:"results"`
+}
+
+// SearchByLocations runs the same query across locations concurrently and
+// returns the per-location normalized results in input order. Failed
+// locations carry an error message; an error is returned only if every
+// location fails.
+func (c *Client) SearchByLocations(ctx context.Context, params omniserp.SearchParams, locations []string) (*LocationMatrix, error) {
+	matrix := &LocationMatrix{
+		Query:   params.Query,
+		Results: make([]LocationResult, len(locations)),
+	}
+
+	errs := runMatrix(ctx, len(locations), func(ctx context.Context, i int) error {
+		p := params
+		p.Location = locations[i]
+		matrix.Results[i].Location = locations[i]
+
+		result, err := c.SearchNormalized(ctx, p)
+		if err != nil {
+			matrix.Results[i].Error = err.Error()
+			return err
+		}
+		matrix.Results[i].Result = result
+		return nil
+	})
+
+	if err := allFailed(errs); err != nil {
+		return matrix, fmt.Errorf("all location searches failed: %w", err)
+	}
+	return matrix, nil
+}
+
+// Market is a language/country combination (SearchParams Language and Country)
+type Market struct {
+	Language string `json:"language"` // hl, e.g. "de"
+	Country  string `json:"country"`  // gl, e.g. "de"
+}
+
+// String returns the market as "language-country"
+func (m Market) String() string {
+	return m.Language + "-" + m.Country
+}
+
+// MarketResult is the normalized result of a search in one market
+type MarketResult struct {
+	Market Market                           `json:"market"`
+	Result *omniserp.NormalizedSearchResult `json:"result,omitempty"`
+	Error  string                           `json:"error,omitempty"`
+}
+
+// MarketMatrix holds the per-market results of SearchByMarkets
+type MarketMatrix struct {
+	Query   string         `json:"query"`
+	Results []MarketResult `json:"results"`
+
+	// InAllMarkets lists organic result links that appear in every
+	// successful market, in the order of the first market's ranking
+	InAllMarkets []string `json:"in_all_markets"`
+}
+
+// AppearsInAllMarkets reports whether link appears in every successful market
+func (m *MarketMatrix) AppearsInAllMarkets(link string) bool {
+	key := canonicalLink(link)
+	for _, l := range m.InAllMarkets {
+		if canonicalLink(l) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchByMarkets runs the same query across language/country combinations
+// concurrently and aggregates the results, flagging the organic results that
+// appear in every market. An error is returned only if every market fails.
+func (c *Client) SearchByMarkets(ctx context.Context, params omniserp.SearchParams, markets []Market) (*MarketMatrix, error) {
+	matrix := &MarketMatrix{
+		Query:   params.Query,
+		Results: make([]MarketResult, len(markets)),
+	}
+
+	errs := runMatrix(ctx, len(markets), func(ctx context.Context, i int) error {
+		p := params
+		p.Language = markets[i].Language
+		p.Country = markets[i].Country
+		matrix.Results[i].Market = markets[i]
+
+		result, err := c.SearchNormalized(ctx, p)
+		if err != nil {
+			matrix.Results[i].Error = err.Error()
+			return err
+		}
+		matrix.Results[i].Result = result
+		return nil
+	})
+
+	if err := allFailed(errs); err != nil {
+		return matrix, fmt.Errorf("all market searches failed: %w", err)
+	}
+
+	matrix.InAllMarkets = commonLinks(matrix.Results)
+	return matrix, nil
+}
+
+// commonLinks returns the organic links present in every successful market
+func commonLinks(results []MarketResult) []string {
+	counts := make(map[string]int)
+	markets := 0
+	var order []string
+
+	for _, mr := range results {
+		if mr.Result == nil {
+			continue
+		}
+		markets++
+		seen := make(map[string]bool)
+		for _, r := range mr.Result.OrganicResults {
+			key := canonicalLink(r.Link)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if counts[key] == 0 && markets == 1 {
+				order = append(order, r.Link)
+			}
+			counts[key]++
+		}
+	}
+
+	common := []string{}
+	for _, link := range order {
+		if counts[canonicalLink(link)] == markets {
+			common = append(common, link)
+		}
+	}
+	return common
+}
+
+// canonicalLink normalizes a URL for comparison across markets by dropping
+// the scheme, a leading "www.", and a trailing slash
+func canonicalLink(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(link))
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	key := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// runMatrix calls fn for indexes 0..n-1 with bounded concurrency and returns
+// the per-index errors
+func runMatrix(ctx context.Context, n int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, matrixConcurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// allFailed returns the first error if every entry failed, or nil otherwise
+func allFailed(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[0]
+}
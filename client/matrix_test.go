@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestSearchByLocations(t *testing.T) {
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if p.Location == "Nowhere" {
+			return nil, fmt.Errorf("unknown location")
+		}
+		return organicResponse("https://" + p.Location + ".example.com"), nil
+	})
+
+	matrix, err := c.SearchByLocations(context.Background(), omniserp.SearchParams{Query: "pizza"},
+		[]string{"Austin", "Boston", "Nowhere"})
+	if err != nil {
+		t.Fatalf("SearchByLocations failed: %v", err)
+	}
+
+	if len(matrix.Results) != 3 {
+		t.Fatalf("Expected 3 location results, got %d", len(matrix.Results))
+	}
+	if matrix.Results[1].Location != "Boston" || matrix.Results[1].Result.OrganicResults[0].Link != "https://Boston.example.com" {
+		t.Errorf("Results not in input order: %+v", matrix.Results[1])
+	}
+	if matrix.Results[2].Error == "" {
+		t.Error("Expected error for unknown location")
+	}
+
+	if _, err := c.SearchByLocations(context.Background(), omniserp.SearchParams{Query: "pizza"}, []string{"Nowhere"}); err == nil {
+		t.Error("Expected error when every location fails")
+	}
+}
+
+func TestSearchByMarkets(t *testing.T) {
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse(
+			"https://www.global.com/",
+			"https://"+p.Country+".local.com",
+			"https://shared.com/page",
+		), nil
+	})
+
+	matrix, err := c.SearchByMarkets(context.Background(), omniserp.SearchParams{Query: "gopher"}, []Market{
+		{Language: "de", Country: "de"},
+		{Language: "ja", Country: "jp"},
+		{Language: "pt", Country: "br"},
+	})
+	if err != nil {
+		t.Fatalf("SearchByMarkets failed: %v", err)
+	}
+
+	if len(matrix.InAllMarkets) != 2 {
+		t.Fatalf("Expected 2 links in all markets, got %v", matrix.InAllMarkets)
+	}
+	if !matrix.AppearsInAllMarkets("http://global.com") {
+		t.Error("Expected global.com to appear in all markets regardless of scheme and www")
+	}
+	if matrix.AppearsInAllMarkets("https://de.local.com") {
+		t.Error("Expected market-specific link not to appear in all markets")
+	}
+	if matrix.Results[1].Market.String() != "ja-jp" {
+		t.Errorf("Unexpected market order: %v", matrix.Results[1].Market)
+	}
+}
@@ -0,0 +1,238 @@
+// Package mojeek implements the omniserp.Engine interface for the Mojeek
+// Search API. Mojeek crawls and ranks the web with its own index, independent
+// of Google and Bing.
+package mojeek
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.mojeek.com"
+	engineName    = "mojeek"
+	engineVersion = "1.0.0"
+	searchPath    = "/search"
+
+	// maxNum is the largest number of results the API returns per request
+	maxNum = 100
+)
+
+// Engine implements the omniserp.Engine interface for Mojeek
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Mojeek engine from the MOJEEK_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("MOJEEK_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("MOJEEK_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Mojeek engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The language and country
+// boost the ranking of matching pages rather than filter them, and there is
+// no freshness parameter.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// buildParams converts SearchParams to Mojeek query parameters
+func (e *Engine) buildParams(params omniserp.SearchParams) url.Values {
+	q := url.Values{}
+	q.Set("api_key", e.apiKey)
+	q.Set("q", params.Query)
+	q.Set("fmt", "json")
+
+	if params.Language != "" {
+		q.Set("lb", strings.ToUpper(params.Language))
+	}
+	if params.Country != "" {
+		q.Set("rb", strings.ToUpper(params.Country))
+	}
+	num := min(params.NumResults, maxNum)
+	if num > 0 {
+		q.Set("t", strconv.Itoa(num))
+	}
+	if params.Page > 1 {
+		if num <= 0 {
+			num = 10
+		}
+		// s is the 1-based index of the first result
+		q.Set("s", strconv.Itoa((params.Page-1)*num+1))
+	}
+	if params.SafeSearch {
+		q.Set("safe", "1")
+	}
+	return q
+}
+
+// Search performs a general web search. Mojeek reports errors such as an
+// invalid key in the status of a successful response.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	reqURL := e.baseURL + searchPath + "?" + e.buildParams(params).Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	// #nosec G704 -- request to the Mojeek API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if response, ok := result["response"].(map[string]any); ok {
+		if status, _ := response["status"].(string); status != "" && status != "OK" {
+			return nil, fmt.Errorf("mojeek error: %s", status)
+		}
+	}
+
+	return &omniserp.SearchResult{
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// SearchNews performs a news search (not supported by Mojeek)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Mojeek")
+}
+
+// SearchImages performs an image search (not supported by Mojeek)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Mojeek")
+}
+
+// SearchVideos performs a video search (not supported by Mojeek)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Mojeek")
+}
+
+// SearchPlaces performs a places search (not supported by Mojeek)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Mojeek")
+}
+
+// SearchMaps performs a maps search (not supported by Mojeek)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Mojeek")
+}
+
+// SearchReviews performs a reviews search (not supported by Mojeek)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Mojeek")
+}
+
+// SearchShopping performs a shopping search (not supported by Mojeek)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Mojeek")
+}
+
+// SearchScholar performs a scholar search (not supported by Mojeek)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Mojeek")
+}
+
+// SearchLens performs a visual search (not supported by Mojeek)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Mojeek")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Mojeek)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Mojeek")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Mojeek)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Mojeek")
+}
@@ -0,0 +1,115 @@
+package mojeek
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestEngine creates an engine for a server answering with body and
+// records the query of the last request
+func newTestEngine(t *testing.T, body []byte, status int) (*Engine, *url.Values) {
+	t.Helper()
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != searchPath {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func readFixture(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestEngine(t, readFixture(t), http.StatusOK)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Language:   "en",
+		Country:    "gb",
+		NumResults: 2,
+		Page:       3,
+		SafeSearch: true,
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"api_key": "test-key",
+		"q":       "golang",
+		"fmt":     "json",
+		"lb":      "EN",
+		"rb":      "GB",
+		"t":       "2",
+		"s":       "5",
+		"safe":    "1",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	second := normalized.OrganicResults[1]
+	if second.Position != 2 || second.Link != "https://www.github.com/golang/go" || second.Domain != "github.com" || second.Engine != engineName {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if meta := normalized.SearchMetadata; meta.TotalResults != 48200 || meta.TimeTaken != 0.042 {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestSearchStatusError(t *testing.T) {
+	engine, _ := newTestEngine(t, []byte(`{"response":{"status":"ERROR: Invalid API key"}}`), http.StatusOK)
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err == nil || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Errorf("Expected the status as an error, got %v", err)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestEngine(t, []byte("Too Many Requests"), http.StatusTooManyRequests)
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected a 429 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Defaults of PAAOptions
+const (
+	defaultPAADepth       = 2
+	defaultPAAQuestions   = 3
+	defaultPAAMaxSearches = 20
+)
+
+// PAAOptions bounds ExpandPeopleAlsoAsk
+type PAAOptions struct {
+	// MaxDepth is the number of levels of questions below the initial
+	// search (default 2)
+	MaxDepth int
+
+	// MaxQuestions is the number of questions expanded per search (default 3)
+	MaxQuestions int
+
+	// MaxSearches is the total number of searches, including the initial
+	// one (default 20)
+	MaxSearches int
+}
+
+// PAANode is a "People Also Ask" question with its answer and the questions
+// raised by searching for it
+type PAANode struct {
+	omniserp.PeopleAlsoAsk
+
+	// Children are the new questions of the search for Question; they are
+	// not expanded at the maximum depth or once the search limit is reached
+	Children []*PAANode `json:"children,omitempty"`
+
+	// Error is set if the search for Question failed
+	Error string `json:"error,omitempty"`
+}
+
+// PAATree holds the questions expanded from a search
+type PAATree struct {
+	Query     string     `json:"query"`
+	Questions []*PAANode `json:"questions"`
+
+	// Searches is the number of searches made
+	Searches int `json:"searches"`
+}
+
+// ExpandPeopleAlsoAsk searches params, then searches each "People Also Ask"
+// question in turn to collect the questions it raises, level by level up to
+// the bounds of opts. Questions already in the tree are not repeated. Only
+// a failure of the initial search is returned as an error; failed follow-up
+// searches are recorded on their nodes.
+func (c *Client) ExpandPeopleAlsoAsk(ctx context.Context, params omniserp.SearchParams, opts *PAAOptions) (*PAATree, error) {
+	if opts == nil {
+		opts = &PAAOptions{}
+	}
+	maxDepth, maxQuestions, maxSearches := opts.MaxDepth, opts.MaxQuestions, opts.MaxSearches
+	if maxDepth <= 0 {
+		maxDepth = defaultPAADepth
+	}
+	if maxQuestions <= 0 {
+		maxQuestions = defaultPAAQuestions
+	}
+	if maxSearches <= 0 {
+		maxSearches = defaultPAAMaxSearches
+	}
+
+	result, err := c.SearchNormalized(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %q: %w", params.Query, err)
+	}
+	tree := &PAATree{Query: params.Query, Searches: 1}
+
+	seen := map[string]bool{questionKey(params.Query): true}
+	tree.Questions = newPAANodes(result.PeopleAlsoAsk, maxQuestions, seen)
+
+	level := tree.Questions
+	for depth := 1; depth < maxDepth && len(level) > 0; depth++ {
+		if remaining := maxSearches - tree.Searches; len(level) > remaining {
+			level = level[:remaining]
+		}
+		if len(level) == 0 {
+			break
+		}
+		tree.Searches += len(level)
+
+		results := make([]*omniserp.NormalizedSearchResult, len(level))
+		runMatrix(ctx, len(level), func(ctx context.Context, i int) error {
+			p := params
+			p.Query = level[i].Question
+			p.Page = 0
+			result, err := c.SearchNormalized(ctx, p)
+			if err != nil {
+				level[i].Error = err.Error()
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+
+		// Children are added in question order so duplicates resolve
+		// deterministically
+		var next []*PAANode
+		for i, node := range level {
+			if results[i] == nil {
+				continue
+			}
+			node.Children = newPAANodes(results[i].PeopleAlsoAsk, maxQuestions, seen)
+			next = append(next, node.Children...)
+		}
+		level = next
+	}
+	return tree, nil
+}
+
+// newPAANodes returns nodes for up to limit questions not seen before
+func newPAANodes(questions []omniserp.PeopleAlsoAsk, limit int, seen map[string]bool) []*PAANode {
+	var nodes []*PAANode
+	for _, q := range questions {
+		if len(nodes) == limit {
+			break
+		}
+		key := questionKey(q.Question)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		nodes = append(nodes, &PAANode{PeopleAlsoAsk: q})
+	}
+	return nodes
+}
+
+// questionKey normalizes a question for duplicate detection
+func questionKey(question string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(strings.ToLower(question)), " "), "?")
+}
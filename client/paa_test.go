@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// paaResponse builds a serper-shaped response with the given questions
+func paaResponse(questions ...string) *omniserp.SearchResult {
+	paa := make([]any, 0, len(questions))
+	for _, q := range questions {
+		paa = append(paa, map[string]any{"question": q, "answer": "answer to " + q})
+	}
+	return &omniserp.SearchResult{Data: map[string]any{"peopleAlsoAsk": paa}}
+}
+
+func TestExpandPeopleAlsoAsk(t *testing.T) {
+	questions := map[string][]string{
+		"go":                   {"What is Go?", "Is Go fast?", "Who made Go?"},
+		"What is Go?":          {"is go fast", "What is Go used for?"},
+		"Is Go fast?":          {"Is Go faster than Java?"},
+		"Who made Go?":         {},
+		"What is Go used for?": {"Is Go good for web?"},
+	}
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if params.Query == "Who made Go?" {
+			return nil, errors.New("boom")
+		}
+		return paaResponse(questions[params.Query]...), nil
+	})
+	ctx := context.Background()
+
+	tree, err := c.ExpandPeopleAlsoAsk(ctx, omniserp.SearchParams{Query: "go"}, &PAAOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("ExpandPeopleAlsoAsk failed: %v", err)
+	}
+	if len(tree.Questions) != 3 || tree.Searches != 4 {
+		t.Fatalf("Expected 3 questions and 4 searches, got %d and %d", len(tree.Questions), tree.Searches)
+	}
+	first := tree.Questions[0]
+	if first.Answer != "answer to What is Go?" {
+		t.Errorf("Expected the answer of the question, got %q", first.Answer)
+	}
+	// "is go fast" duplicates an earlier question
+	if len(first.Children) != 1 || first.Children[0].Question != "What is Go used for?" {
+		t.Errorf("Unexpected children: %+v", first.Children)
+	}
+	if len(first.Children[0].Children) != 0 {
+		t.Error("Expected no questions below the maximum depth")
+	}
+	if tree.Questions[2].Error == "" {
+		t.Error("Expected the failed follow-up search on its node")
+	}
+
+	// The search limit cuts off expansion
+	tree, err = c.ExpandPeopleAlsoAsk(ctx, omniserp.SearchParams{Query: "go"}, &PAAOptions{MaxDepth: 3, MaxSearches: 2})
+	if err != nil {
+		t.Fatalf("ExpandPeopleAlsoAsk failed: %v", err)
+	}
+	if tree.Searches != 2 || len(tree.Questions[0].Children) == 0 || tree.Questions[1].Children != nil {
+		t.Errorf("Expected only the first question expanded, got %d searches", tree.Searches)
+	}
+
+	if _, err := c.ExpandPeopleAlsoAsk(ctx, omniserp.SearchParams{Query: "Who made Go?"}, nil); err == nil {
+		t.Error("Expected an error when the initial search fails")
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// PageSnapshot is the stored state of a scraped page used for change
+// detection. Lines are stored as hashes so snapshots stay small.
+type PageSnapshot struct {
+	URL         string    `json:"url"`
+	ContentHash string    `json:"content_hash"`
+	LineHashes  []uint64  `json:"line_hashes,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// PageStore stores the latest snapshot per URL. Implementations must be
+// safe for concurrent use.
+type PageStore interface {
+	Get(url string) (*PageSnapshot, bool)
+	Put(snapshot *PageSnapshot) error
+}
+
+// PageChange describes how a page changed since its previous snapshot
+type PageChange struct {
+	URL       string `json:"url"`
+	FirstSeen bool   `json:"first_seen"` // no previous snapshot to compare
+	Changed   bool   `json:"changed"`
+
+	// Similarity is the fraction of distinct lines shared by both versions
+	// (1 when unchanged); ChangeRatio is 1 - Similarity
+	Similarity   float64 `json:"similarity"`
+	ChangeRatio  float64 `json:"change_ratio"`
+	LinesAdded   int     `json:"lines_added"`
+	LinesRemoved int     `json:"lines_removed"`
+
+	PreviousHash      string    `json:"previous_hash,omitempty"`
+	CurrentHash       string    `json:"current_hash"`
+	PreviousFetchedAt time.Time `json:"previous_fetched_at,omitzero"`
+	FetchedAt         time.Time `json:"fetched_at"`
+}
+
+// SetPageStore replaces the store of page snapshots used by CheckChanged
+func (c *Client) SetPageStore(store PageStore) {
+	c.mu.Lock()
+	c.pages = store
+	c.mu.Unlock()
+}
+
+// pageStore returns the store of page snapshots
+func (c *Client) pageStore() PageStore {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pages
+}
+
+// CheckChanged scrapes a page, compares it with the snapshot stored by the
+// previous CheckChanged call for the same URL, and stores the new snapshot.
+// Pages are compared by content hash, and the amount of change is measured
+// over the distinct lines of their text.
+func (c *Client) CheckChanged(ctx context.Context, pageURL string) (*PageChange, error) {
+	scraped, err := c.ScrapeNormalized(ctx, omniserp.ScrapeParams{URL: pageURL})
+	if err != nil {
+		return nil, err
+	}
+
+	current := newPageSnapshot(pageURL, scraped)
+	store := c.pageStore()
+	previous, found := store.Get(pageURL)
+	if err := store.Put(current); err != nil {
+		return nil, fmt.Errorf("failed to store page snapshot: %w", err)
+	}
+
+	change := &PageChange{
+		URL:         pageURL,
+		FirstSeen:   !found,
+		Similarity:  1,
+		CurrentHash: current.ContentHash,
+		FetchedAt:   current.FetchedAt,
+	}
+	if !found {
+		return change, nil
+	}
+
+	change.PreviousHash = previous.ContentHash
+	change.PreviousFetchedAt = previous.FetchedAt
+	if previous.ContentHash == current.ContentHash {
+		return change, nil
+	}
+
+	change.Changed = true
+	change.Similarity, change.LinesAdded, change.LinesRemoved = compareLines(previous.LineHashes, current.LineHashes)
+	change.ChangeRatio = 1 - change.Similarity
+	return change, nil
+}
+
+// newPageSnapshot builds the snapshot of a scraped page
+func newPageSnapshot(pageURL string, scraped *omniserp.NormalizedScrapeResult) *PageSnapshot {
+	snapshot := &PageSnapshot{
+		URL:         pageURL,
+		ContentHash: scraped.ContentHash,
+		FetchedAt:   scraped.FetchedAt,
+	}
+	if snapshot.ContentHash == "" {
+		snapshot.ContentHash = omniserp.ContentHash(scraped.Text)
+	}
+	if snapshot.FetchedAt.IsZero() {
+		snapshot.FetchedAt = time.Now().UTC()
+	}
+
+	seen := make(map[uint64]bool)
+	for _, line := range strings.Split(scraped.Text, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write([]byte(line))
+		if sum := h.Sum64(); !seen[sum] {
+			seen[sum] = true
+			snapshot.LineHashes = append(snapshot.LineHashes, sum)
+		}
+	}
+	return snapshot
+}
+
+// compareLines returns the Jaccard similarity of two sets of line hashes and
+// the number of lines only in current (added) and only in previous (removed)
+func compareLines(previous, current []uint64) (similarity float64, added, removed int) {
+	prev := make(map[uint64]bool, len(previous))
+	for _, h := range previous {
+		prev[h] = true
+	}
+	shared := 0
+	for _, h := range current {
+		if prev[h] {
+			shared++
+		} else {
+			added++
+		}
+	}
+	removed = len(prev) - shared
+
+	union := shared + added + removed
+	if union == 0 {
+		return 1, 0, 0
+	}
+	return float64(shared) / float64(union), added, removed
+}
+
+// MemoryPageStore keeps page snapshots in memory
+type MemoryPageStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]*PageSnapshot
+}
+
+// NewMemoryPageStore creates an empty in-memory page store
+func NewMemoryPageStore() *MemoryPageStore {
+	return &MemoryPageStore{snapshots: make(map[string]*PageSnapshot)}
+}
+
+// Get implements PageStore
+func (s *MemoryPageStore) Get(url string) (*PageSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.snapshots[url]
+	return snapshot, ok
+}
+
+// Put implements PageStore
+func (s *MemoryPageStore) Put(snapshot *PageSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snapshot.URL] = snapshot
+	return nil
+}
+
+// FilePageStore keeps page snapshots in a JSON file so changes can be
+// detected across runs, e.g. by a scheduled monitoring job
+type FilePageStore struct {
+	path string
+	mem  *MemoryPageStore
+}
+
+// NewFilePageStore opens the page store at path, which is created on the
+// first Put if it does not exist
+func NewFilePageStore(path string) (*FilePageStore, error) {
+	store := &FilePageStore{path: path, mem: NewMemoryPageStore()}
+
+	// #nosec G304 -- store path is provided by the caller
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page store: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.mem.snapshots); err != nil {
+		return nil, fmt.Errorf("failed to parse page store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Get implements PageStore
+func (s *FilePageStore) Get(url string) (*PageSnapshot, bool) {
+	return s.mem.Get(url)
+}
+
+// Put implements PageStore and rewrites the file
+func (s *FilePageStore) Put(snapshot *PageSnapshot) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	s.mem.snapshots[snapshot.URL] = snapshot
+
+	data, err := json.MarshalIndent(s.mem.snapshots, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal page store: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write page store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
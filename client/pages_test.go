@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestCheckChanged(t *testing.T) {
+	text := "Title\nfirst paragraph\nsecond paragraph\nfooter"
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return &omniserp.SearchResult{Data: map[string]any{"text": text}}, nil
+	})
+	ctx := context.Background()
+	url := "https://example.com/pricing"
+
+	change, err := c.CheckChanged(ctx, url)
+	if err != nil {
+		t.Fatalf("CheckChanged failed: %v", err)
+	}
+	if !change.FirstSeen || change.Changed {
+		t.Errorf("Expected first sighting without change, got %+v", change)
+	}
+
+	change, err = c.CheckChanged(ctx, url)
+	if err != nil {
+		t.Fatalf("CheckChanged failed: %v", err)
+	}
+	if change.FirstSeen || change.Changed || change.Similarity != 1 {
+		t.Errorf("Expected unchanged page, got %+v", change)
+	}
+
+	text = "Title\nfirst paragraph\nrevised paragraph\nfooter"
+	change, err = c.CheckChanged(ctx, url)
+	if err != nil {
+		t.Fatalf("CheckChanged failed: %v", err)
+	}
+	if !change.Changed || change.PreviousHash == change.CurrentHash {
+		t.Fatalf("Expected changed page, got %+v", change)
+	}
+	if change.LinesAdded != 1 || change.LinesRemoved != 1 {
+		t.Errorf("Expected 1 line added and removed, got +%d -%d", change.LinesAdded, change.LinesRemoved)
+	}
+	// 3 shared lines out of 5 distinct lines
+	if math.Abs(change.ChangeRatio-0.4) > 1e-9 {
+		t.Errorf("Expected change ratio 0.4, got %g", change.ChangeRatio)
+	}
+}
+
+func TestFilePageStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.json")
+	store, err := NewFilePageStore(path)
+	if err != nil {
+		t.Fatalf("NewFilePageStore failed: %v", err)
+	}
+	if err := store.Put(&PageSnapshot{URL: "https://example.com", ContentHash: "sha256:abc", LineHashes: []uint64{1, 2}}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	reopened, err := NewFilePageStore(path)
+	if err != nil {
+		t.Fatalf("NewFilePageStore failed: %v", err)
+	}
+	snapshot, ok := reopened.Get("https://example.com")
+	if !ok || snapshot.ContentHash != "sha256:abc" || len(snapshot.LineHashes) != 2 {
+		t.Errorf("Unexpected snapshot after reopening: %+v", snapshot)
+	}
+}
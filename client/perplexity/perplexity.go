@@ -0,0 +1,451 @@
+// Package perplexity implements the omniserp.Engine interface for the
+// Perplexity Sonar API, an answer-first engine: each search returns an
+// answer written by a Sonar model and grounded in the web pages it cites.
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.perplexity.ai"
+	engineName    = "perplexity"
+	engineVersion = "1.0.0"
+	requestPath   = "/chat/completions"
+
+	// defaultModel is the model of searches without PERPLEXITY_MODEL or the
+	// "model" extra parameter
+	defaultModel = "sonar"
+
+	// searchModeAcademic prioritizes scholarly sources
+	searchModeAcademic = "academic"
+)
+
+// Engine implements the omniserp.Engine interface for Perplexity. Results
+// are normalized by the engine and returned as the Data of each search
+// result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Perplexity engine from the PERPLEXITY_API_KEY env var,
+// and the model from PERPLEXITY_MODEL, which defaults to "sonar"
+func New() (*Engine, error) {
+	apiKey := os.Getenv("PERPLEXITY_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("PERPLEXITY_API_KEY environment variable is required")
+	}
+	engine, err := NewWithAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if model := os.Getenv("PERPLEXITY_MODEL"); model != "" {
+		engine.SetModel(model)
+	}
+	return engine, nil
+}
+
+// NewWithAPIKey creates a new Perplexity engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		model:   defaultModel,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetModel sets the Sonar model of searches without the "model" extra
+// parameter, such as "sonar-pro"
+func (e *Engine) SetModel(model string) {
+	e.model = model
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_scholar",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The model chooses the
+// number of sources, and there are no pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamFreshness,
+	}
+}
+
+// Extra declares the Perplexity-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Model              string `extra:"model" description:"Sonar model, such as sonar (default), sonar-pro, or sonar-reasoning-pro"`
+	SearchContextSize  string `extra:"search_context_size" description:"Amount of web content the model reads: low (default), medium, or high, at a higher price"`
+	SearchDomainFilter string `extra:"search_domain_filter" description:"Comma-separated domains to search, or to exclude when prefixed with -"`
+	RelatedQuestions   bool   `extra:"return_related_questions" description:"Return follow-up questions as related searches"`
+	DisableSearch      bool   `extra:"disable_search" description:"Answer from the model alone without searching the web"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// message is a chat message of a request or response
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// request is the JSON body of a chat completions request
+type request struct {
+	Model                  string            `json:"model"`
+	Messages               []message         `json:"messages"`
+	SearchMode             string            `json:"search_mode,omitempty"`
+	SearchRecencyFilter    string            `json:"search_recency_filter,omitempty"`
+	SearchDomainFilter     []string          `json:"search_domain_filter,omitempty"`
+	ReturnRelatedQuestions bool              `json:"return_related_questions,omitempty"`
+	DisableSearch          bool              `json:"disable_search,omitempty"`
+	WebSearchOptions       *webSearchOptions `json:"web_search_options,omitempty"`
+}
+
+// webSearchOptions are the web search options of a request
+type webSearchOptions struct {
+	SearchContextSize string        `json:"search_context_size,omitempty"`
+	UserLocation      *userLocation `json:"user_location,omitempty"`
+}
+
+// userLocation is the approximate location of the user
+type userLocation struct {
+	Country string `json:"country"`
+}
+
+// response is the JSON response of a chat completion. Citations are the
+// URLs that the answer cites as [1], [2], and so on, and SearchResults the
+// same sources with their titles.
+type response struct {
+	ID            string   `json:"id"`
+	Model         string   `json:"model"`
+	Citations     []string `json:"citations"`
+	SearchResults []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Date    string `json:"date"`
+		Snippet string `json:"snippet"`
+	} `json:"search_results"`
+	RelatedQuestions []string `json:"related_questions"`
+	Choices          []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+}
+
+// source is a cited web page
+type source struct {
+	title, link, date, snippet string
+}
+
+// sources returns the cited pages in citation order
+func (r *response) sources() []source {
+	if len(r.SearchResults) > 0 {
+		sources := make([]source, 0, len(r.SearchResults))
+		for _, s := range r.SearchResults {
+			sources = append(sources, source{title: s.Title, link: s.URL, date: s.Date, snippet: s.Snippet})
+		}
+		return sources
+	}
+	sources := make([]source, 0, len(r.Citations))
+	for _, link := range r.Citations {
+		sources = append(sources, source{title: hostname(link), link: link})
+	}
+	return sources
+}
+
+// thinkRE matches the reasoning that reasoning models write before their
+// answer
+var thinkRE = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// answer returns the answer of the response without reasoning
+func (r *response) answer() string {
+	if len(r.Choices) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(thinkRE.ReplaceAllString(r.Choices[0].Message.Content, ""))
+}
+
+// recencyFilter returns the search recency filter of a freshness
+func recencyFilter(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour, omniserp.FreshnessDay, omniserp.FreshnessWeek, omniserp.FreshnessMonth, omniserp.FreshnessYear:
+		return string(f)
+	}
+	return ""
+}
+
+// buildRequest converts SearchParams to a chat completions request. The
+// language is asked for in a system message, as the API has no parameter
+// for it.
+func (e *Engine) buildRequest(params omniserp.SearchParams, searchMode string) request {
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	model, _ := extra["model"].(string)
+	contextSize, _ := extra["search_context_size"].(string)
+	domains, _ := extra["search_domain_filter"].(string)
+	related, _ := extra["return_related_questions"].(bool)
+	disableSearch, _ := extra["disable_search"].(bool)
+
+	body := request{
+		Model:                  e.model,
+		SearchMode:             searchMode,
+		SearchRecencyFilter:    recencyFilter(params.Freshness),
+		ReturnRelatedQuestions: related,
+		DisableSearch:          disableSearch,
+	}
+	if model != "" {
+		body.Model = model
+	}
+	if params.Language != "" {
+		body.Messages = append(body.Messages, message{
+			Role:    "system",
+			Content: fmt.Sprintf("Answer in the language with the code %q.", params.Language),
+		})
+	}
+	body.Messages = append(body.Messages, message{Role: "user", Content: params.Query})
+	for _, domain := range strings.Split(domains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			body.SearchDomainFilter = append(body.SearchDomainFilter, domain)
+		}
+	}
+	if contextSize != "" || params.Country != "" {
+		body.WebSearchOptions = &webSearchOptions{SearchContextSize: contextSize}
+		if params.Country != "" {
+			body.WebSearchOptions.UserLocation = &userLocation{Country: strings.ToUpper(params.Country)}
+		}
+	}
+	return body
+}
+
+// search performs a chat completions request and parses the response
+func (e *Engine) search(ctx context.Context, body request) (*response, string, *omniserp.ResponseMeta, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+requestPath, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to the Perplexity API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(raw), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = parsed.ID
+	}
+	return &parsed, string(raw), meta, nil
+}
+
+// newNormalized returns a normalized result for params with the answer and
+// related questions of resp
+func newNormalized(params omniserp.SearchParams, resp *response, timeTaken time.Duration) *omniserp.NormalizedSearchResult {
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			Language:  params.Language,
+			Country:   params.Country,
+			TimeTaken: timeTaken.Seconds(),
+		},
+	}
+	if answer := resp.answer(); answer != "" {
+		normalized.AnswerBox = &omniserp.AnswerBox{Type: "answer", Title: resp.Model, Answer: answer}
+	}
+	for _, q := range resp.RelatedQuestions {
+		normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: q})
+	}
+	return normalized
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a web search. The answer of the model is returned as the
+// AnswerBox and its citations as the organic results, whose positions match
+// the citation numbers in the answer.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, e.buildRequest(params, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp, meta.Latency)
+	for i, s := range resp.sources() {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: i + 1,
+			Title:    s.title,
+			Link:     s.link,
+			URL:      s.link,
+			Snippet:  s.snippet,
+			Domain:   hostname(s.link),
+			Date:     s.date,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchScholar performs a search of scholarly sources in the academic
+// search mode. The answer is returned as the AnswerBox and its citations as
+// the scholar results.
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, e.buildRequest(params, searchModeAcademic))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp, meta.Latency)
+	for i, s := range resp.sources() {
+		var year string
+		if len(s.date) >= 4 {
+			year = s.date[:4]
+		}
+		normalized.ScholarResults = append(normalized.ScholarResults, omniserp.ScholarResult{
+			Position: i + 1,
+			Title:    s.title,
+			Link:     s.link,
+			Source:   hostname(s.link),
+			Year:     year,
+			Snippet:  s.snippet,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search (not supported by Perplexity)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Perplexity")
+}
+
+// SearchImages performs an image search (not supported by Perplexity)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Perplexity")
+}
+
+// SearchVideos performs a video search (not supported by Perplexity)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Perplexity")
+}
+
+// SearchPlaces performs a places search (not supported by Perplexity)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Perplexity")
+}
+
+// SearchMaps performs a maps search (not supported by Perplexity)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Perplexity")
+}
+
+// SearchReviews performs a reviews search (not supported by Perplexity)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Perplexity")
+}
+
+// SearchShopping performs a shopping search (not supported by Perplexity)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Perplexity")
+}
+
+// SearchLens performs a visual search (not supported by Perplexity)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Perplexity")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Perplexity)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Perplexity")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Perplexity)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Perplexity")
+}
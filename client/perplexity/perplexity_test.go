@@ -0,0 +1,160 @@
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture, or only citations for the academic
+// search mode, and records the body of the last request
+func newTestServer(t *testing.T) (*Engine, *request) {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var body request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != requestPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"Invalid API key","type":"invalid_api_key","code":401}}`))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body.SearchMode == searchModeAcademic {
+			_, _ = w.Write([]byte(`{"id":"a1","model":"sonar","citations":["https://arxiv.org/abs/2401.00001"],"choices":[{"message":{"role":"assistant","content":"See the paper [1]."}}]}`))
+			return
+		}
+		_, _ = w.Write(fixture)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &body
+}
+
+// normalizedOf returns the normalized result of a search
+func normalizedOf(t *testing.T, result *omniserp.SearchResult) *omniserp.NormalizedSearchResult {
+	t.Helper()
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	return normalized
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("PERPLEXITY_API_KEY", "test-key")
+	t.Setenv("PERPLEXITY_MODEL", "sonar-pro")
+	engine, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if engine.model != "sonar-pro" {
+		t.Errorf("Expected the model from the environment, got %q", engine.model)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:     "what is golang",
+		Language:  "de",
+		Country:   "de",
+		Freshness: omniserp.FreshnessMonth,
+		Extra: map[string]any{
+			"search_context_size":      "high",
+			"search_domain_filter":     "go.dev, -reddit.com",
+			"return_related_questions": "true",
+		},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if body.Model != defaultModel || body.SearchRecencyFilter != "month" || !body.ReturnRelatedQuestions {
+		t.Errorf("Unexpected request: %+v", body)
+	}
+	if len(body.Messages) != 2 || body.Messages[0].Role != "system" || body.Messages[1].Content != params.Query {
+		t.Errorf("Expected a language instruction and the query, got %+v", body.Messages)
+	}
+	if !slices.Equal(body.SearchDomainFilter, []string{"go.dev", "-reddit.com"}) {
+		t.Errorf("Unexpected domain filter %v", body.SearchDomainFilter)
+	}
+	if opts := body.WebSearchOptions; opts == nil || opts.SearchContextSize != "high" || opts.UserLocation == nil || opts.UserLocation.Country != "DE" {
+		t.Errorf("Unexpected web search options: %+v", opts)
+	}
+	if result.Response.RequestID != "3c90c3cc-0d44-4b50-8888-8dd25736052a" {
+		t.Errorf("Expected the completion ID as the request ID, got %q", result.Response.RequestID)
+	}
+
+	normalized := normalizedOf(t, result)
+	if box := normalized.AnswerBox; box == nil || box.Answer != "Go is an open source programming language designed at Google [1][2]." {
+		t.Errorf("Expected the answer without reasoning, got %+v", box)
+	}
+	organic := normalized.OrganicResults
+	if len(organic) != 2 {
+		t.Fatalf("Expected 2 cited results, got %d", len(organic))
+	}
+	if second := organic[1]; second.Position != 2 || second.Domain != "en.wikipedia.org" || second.Date != "2025-12-18" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[0].Query != "What is Go used for?" {
+		t.Errorf("Unexpected related searches: %+v", normalized.RelatedSearches)
+	}
+}
+
+func TestSearchScholar(t *testing.T) {
+	engine, body := newTestServer(t)
+	engine.SetModel("sonar-pro")
+
+	result, err := engine.SearchScholar(context.Background(), omniserp.SearchParams{
+		Query: "transformer scaling laws",
+		Extra: map[string]any{"model": "sonar-reasoning-pro"},
+	})
+	if err != nil {
+		t.Fatalf("SearchScholar failed: %v", err)
+	}
+	if body.Model != "sonar-reasoning-pro" || body.WebSearchOptions != nil || len(body.Messages) != 1 {
+		t.Errorf("Unexpected request: %+v", body)
+	}
+
+	papers := normalizedOf(t, result).ScholarResults
+	if len(papers) != 1 || papers[0].Title != "arxiv.org" || papers[0].Link != "https://arxiv.org/abs/2401.00001" {
+		t.Errorf("Expected the citation as a paper, got %+v", papers)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
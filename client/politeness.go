@@ -0,0 +1,234 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Politeness defaults
+const (
+	defaultHostDelay  = time.Second
+	defaultPerHost    = 1
+	maxCrawlDelay     = time.Minute
+	robotsFetchLimit  = 512 << 10
+	robotsUserAgent   = "*"
+	robotsHTTPTimeout = 10 * time.Second
+)
+
+// PolitenessPolicy limits how hard batch scrapes hit a single host
+type PolitenessPolicy struct {
+	// MinDelay is the minimum time between the starts of requests to the
+	// same host (default 1s)
+	MinDelay time.Duration
+
+	// MaxPerHost is the maximum concurrent requests per host (default 1)
+	MaxPerHost int
+
+	// RespectRobots reads the Crawl-delay of each host's robots.txt and uses
+	// it instead of MinDelay when it is longer (capped at one minute)
+	RespectRobots bool
+
+	// UserAgent selects the robots.txt group; "*" is used if no group
+	// matches (default "*")
+	UserAgent string
+
+	// HTTPClient fetches robots.txt (default: omniserp.NewPublicClient with
+	// a 10s timeout, which refuses private addresses)
+	HTTPClient *http.Client
+}
+
+// HostScheduler enforces a PolitenessPolicy across concurrent requests.
+// It is safe for concurrent use and can be shared by several batches.
+type HostScheduler struct {
+	policy PolitenessPolicy
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState is the schedule of one host
+type hostState struct {
+	slots chan struct{}
+
+	once sync.Once // resolves delay
+
+	mu    sync.Mutex
+	delay time.Duration
+	next  time.Time // earliest start of the next request
+}
+
+// NewHostScheduler creates a scheduler for the policy; nil uses the defaults
+func NewHostScheduler(policy *PolitenessPolicy) *HostScheduler {
+	s := &HostScheduler{hosts: make(map[string]*hostState)}
+	if policy != nil {
+		s.policy = *policy
+	}
+	if s.policy.MinDelay <= 0 {
+		s.policy.MinDelay = defaultHostDelay
+	}
+	if s.policy.MaxPerHost <= 0 {
+		s.policy.MaxPerHost = defaultPerHost
+	}
+	if s.policy.UserAgent == "" {
+		s.policy.UserAgent = robotsUserAgent
+	}
+	if s.policy.HTTPClient == nil {
+		s.policy.HTTPClient = omniserp.NewPublicClient()
+		s.policy.HTTPClient.Timeout = robotsHTTPTimeout
+	}
+	return s
+}
+
+// Acquire waits until a request to the host of rawURL may start under the
+// policy. The returned function must be called when the request finishes.
+func (s *HostScheduler) Acquire(ctx context.Context, rawURL string) (func(), error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL: %s", rawURL)
+	}
+
+	host := s.host(u.Host)
+	host.once.Do(func() {
+		delay := s.policy.MinDelay
+		if s.policy.RespectRobots {
+			if crawlDelay := s.crawlDelay(ctx, u); crawlDelay > delay {
+				delay = min(crawlDelay, maxCrawlDelay)
+			}
+		}
+		host.mu.Lock()
+		host.delay = delay
+		host.mu.Unlock()
+	})
+
+	select {
+	case host.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-host.slots }
+
+	// Reserve the next start time, then wait for it
+	host.mu.Lock()
+	now := time.Now()
+	start := host.next
+	if start.Before(now) {
+		start = now
+	}
+	host.next = start.Add(host.delay)
+	host.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// Delay returns the delay in effect for a host, once it has been contacted
+func (s *HostScheduler) Delay(host string) time.Duration {
+	s.mu.Lock()
+	state, ok := s.hosts[host]
+	s.mu.Unlock()
+	if !ok {
+		return s.policy.MinDelay
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.delay
+}
+
+// host returns the state of a host, creating it on first use
+func (s *HostScheduler) host(name string) *hostState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.hosts[name]
+	if !ok {
+		state = &hostState{slots: make(chan struct{}, s.policy.MaxPerHost)}
+		s.hosts[name] = state
+	}
+	return state
+}
+
+// crawlDelay fetches the Crawl-delay of a host's robots.txt, or returns 0
+// if robots.txt is missing or has none
+func (s *HostScheduler) crawlDelay(ctx context.Context, u *url.URL) time.Duration {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return 0
+	}
+
+	// #nosec G704 -- robots.txt of a host the caller asked to scrape
+	resp, err := s.policy.HTTPClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0
+	}
+	return parseCrawlDelay(io.LimitReader(resp.Body, robotsFetchLimit), s.policy.UserAgent)
+}
+
+// parseCrawlDelay returns the Crawl-delay of the robots.txt group matching
+// userAgent, falling back to the "*" group
+func parseCrawlDelay(r io.Reader, userAgent string) time.Duration {
+	userAgent = strings.ToLower(userAgent)
+	delays := make(map[string]time.Duration)
+
+	var agents []string
+	inAgents := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				agents = nil
+			}
+			agents = append(agents, strings.ToLower(value))
+			inAgents = true
+		case "crawl-delay":
+			inAgents = false
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			for _, agent := range agents {
+				delays[agent] = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	for agent, delay := range delays {
+		if agent != robotsUserAgent && strings.Contains(userAgent, agent) {
+			return delay
+		}
+	}
+	return delays[robotsUserAgent]
+}
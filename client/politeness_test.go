@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestParseCrawlDelay(t *testing.T) {
+	robots := `# robots.txt
+User-agent: *
+Disallow: /private
+Crawl-delay: 2
+
+User-agent: OmniSerpBot
+User-agent: OtherBot
+Crawl-delay: 0.5 # seconds
+`
+	if got := parseCrawlDelay(strings.NewReader(robots), "*"); got != 2*time.Second {
+		t.Errorf("Expected 2s for *, got %s", got)
+	}
+	if got := parseCrawlDelay(strings.NewReader(robots), "OmniSerpBot/1.0"); got != 500*time.Millisecond {
+		t.Errorf("Expected 500ms for OmniSerpBot, got %s", got)
+	}
+	if got := parseCrawlDelay(strings.NewReader("User-agent: *\nDisallow:\n"), "*"); got != 0 {
+		t.Errorf("Expected no delay, got %s", got)
+	}
+}
+
+func TestHostScheduler(t *testing.T) {
+	s := NewHostScheduler(&PolitenessPolicy{MinDelay: 40 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		release, err := s.Acquire(ctx, "https://example.com/page")
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected requests to the same host to be spaced, took %s", elapsed)
+	}
+
+	// Another host is not delayed by the first
+	start = time.Now()
+	release, err := s.Acquire(ctx, "https://example.org/")
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed > 30*time.Millisecond {
+		t.Errorf("Expected no wait for a new host, took %s", elapsed)
+	}
+
+	// A cancelled context stops waiting for a slot
+	held, _ := s.Acquire(ctx, "https://example.net/")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.Acquire(cancelled, "https://example.net/"); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+	held()
+}
+
+func TestHostSchedulerRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprintln(w, "User-agent: *\nCrawl-delay: 3")
+		}
+	}))
+	defer server.Close()
+
+	s := NewHostScheduler(&PolitenessPolicy{MinDelay: time.Millisecond, RespectRobots: true, HTTPClient: server.Client()})
+	release, err := s.Acquire(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	release()
+
+	u, _ := url.Parse(server.URL)
+	if got := s.Delay(u.Host); got != 3*time.Second {
+		t.Errorf("Expected robots.txt crawl delay of 3s, got %s", got)
+	}
+}
+
+func TestScrapeBatch(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if strings.Contains(params.Query, "broken") {
+			return nil, fmt.Errorf("scrape failed")
+		}
+		return &omniserp.SearchResult{Data: map[string]any{"text": "page " + params.Query}}, nil
+	})
+
+	urls := []string{"https://a.example/1", "https://b.example/1", "https://a.example/broken", "https://a.example/2"}
+	results, err := c.ScrapeBatch(context.Background(), urls, &ScrapeBatchOptions{
+		Politeness: &PolitenessPolicy{MinDelay: 10 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("ScrapeBatch failed: %v", err)
+	}
+
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("Result %d: expected %s, got %s", i, urls[i], r.URL)
+		}
+	}
+	if results[2].Error == "" || results[2].Result != nil {
+		t.Errorf("Expected error for broken page, got %+v", results[2])
+	}
+	if results[3].Result == nil || results[3].Result.Text != "page https://a.example/2" {
+		t.Errorf("Unexpected result: %+v", results[3])
+	}
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/plexusone/omniserp"
+)
+
+// baseURLSetter is implemented by engines whose API base URL can be
+// overridden, such as all built-in engines
+type baseURLSetter interface {
+	SetBaseURL(u string)
+}
+
+// RouteThroughProxy points every engine of the registry that has a settable
+// base URL at <proxyURL>/<engine name>, the layout served by omniserp-proxy.
+// Engines without one keep calling their APIs directly.
+func RouteThroughProxy(registry *omniserp.Registry, proxyURL string) {
+	proxyURL = strings.TrimSuffix(proxyURL, "/")
+	for name, engine := range registry.GetAll() {
+		if setter, ok := engine.(baseURLSetter); ok {
+			setter.SetBaseURL(proxyURL + "/" + name)
+		}
+	}
+}
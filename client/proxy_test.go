@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/kagi"
+)
+
+func TestRouteThroughProxy(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"meta":{"ms":1},"data":[]}`))
+	}))
+	defer srv.Close()
+
+	engine, err := kagi.NewWithAPIKey("placeholder")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	registry := omniserp.NewRegistry()
+	registry.Register(engine)
+	// Engines without a base URL are left alone
+	registry.Register(&fakeEngine{name: "fake"})
+	RouteThroughProxy(registry, srv.URL+"/")
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if path != "/kagi/search" {
+		t.Errorf("Expected the request at /kagi/search, got %s", path)
+	}
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+
+	"github.com/plexusone/omniserp"
+)
+
+// CollectReviews fetches up to maxPages pages of reviews, starting at
+// params.Page (default 1), and returns them as one result whose
+// ReviewSummary covers all of them. It stops early at a page without new
+// reviews. Reviews are numbered across pages, and the metadata is that of the
+// first page. If a later page fails, the reviews collected so far are
+// returned with the error.
+func (c *Client) CollectReviews(ctx context.Context, params omniserp.SearchParams, maxPages int) (*omniserp.NormalizedSearchResult, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	maxPages = max(maxPages, 1)
+
+	var collected *omniserp.NormalizedSearchResult
+	seen := make(map[string]bool)
+	for page := 0; page < maxPages; page++ {
+		result, err := c.SearchReviewsNormalized(ctx, params)
+		if err != nil {
+			if collected == nil {
+				return nil, err
+			}
+			collected.ReviewSummary = omniserp.SummarizeReviews(collected.ReviewResults)
+			return collected, err
+		}
+		reviews := result.ReviewResults
+		if collected == nil {
+			collected = result
+			collected.ReviewResults = nil
+		}
+
+		added := 0
+		for _, review := range reviews {
+			// Pages may overlap when new reviews are posted while paging
+			key := review.Author + "\x00" + review.Snippet
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			review.Position = len(collected.ReviewResults) + 1
+			collected.ReviewResults = append(collected.ReviewResults, review)
+			added++
+		}
+		if added == 0 {
+			break
+		}
+		params.Page++
+	}
+
+	collected.ReviewSummary = omniserp.SummarizeReviews(collected.ReviewResults)
+	return collected, nil
+}
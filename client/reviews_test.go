@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// reviewsResponse builds a serper-shaped reviews page with the given ratings
+func reviewsResponse(page int, ratings ...float64) *omniserp.SearchResult {
+	reviews := make([]any, 0, len(ratings))
+	for i, rating := range ratings {
+		reviews = append(reviews, map[string]any{
+			"rating":  rating,
+			"snippet": fmt.Sprintf("review %d", (page-1)*2+i+1),
+			"user":    map[string]any{"name": "reviewer"},
+		})
+	}
+	return &omniserp.SearchResult{Data: map[string]any{"reviews": reviews}}
+}
+
+func TestCollectReviews(t *testing.T) {
+	var pages []int
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		pages = append(pages, params.Page)
+		switch params.Page {
+		case 1:
+			return reviewsResponse(1, 5, 4), nil
+		case 2:
+			return reviewsResponse(2, 1, 2), nil
+		case 3:
+			return nil, errors.New("quota exceeded")
+		}
+		return reviewsResponse(params.Page), nil
+	})
+	ctx := context.Background()
+
+	result, err := c.CollectReviews(ctx, omniserp.SearchParams{Query: "gopher coffee"}, 2)
+	if err != nil {
+		t.Fatalf("CollectReviews failed: %v", err)
+	}
+	if len(result.ReviewResults) != 4 || result.ReviewResults[3].Position != 4 {
+		t.Errorf("Expected 4 reviews numbered across pages, got %+v", result.ReviewResults)
+	}
+	if summary := result.ReviewSummary; summary.Count != 4 || summary.AverageRating != 3 || summary.Histogram[5] != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	pages = nil
+	result, err = c.CollectReviews(ctx, omniserp.SearchParams{Query: "gopher coffee", Page: 2}, 5)
+	if err == nil || len(result.ReviewResults) != 2 || result.ReviewSummary.Count != 2 {
+		t.Errorf("Expected the second page with the error of the third, got %+v, %v", result, err)
+	}
+	if len(pages) != 2 || pages[0] != 2 {
+		t.Errorf("Expected pages 2 and 3 to be fetched, got %v", pages)
+	}
+}
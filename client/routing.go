@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+)
+
+// RouteDefault is the routing key for operations without their own route
+const RouteDefault = "*"
+
+// RoutingPolicy routes each operation to a configured engine, such as news
+// to serper and scholar to serpapi, with RouteDefault for everything else.
+// Operations without a route, or whose default engine does not support
+// them, are selected by Base (default: the current engine).
+type RoutingPolicy struct {
+	Routes map[string]string
+	Base   SelectionPolicy
+}
+
+// NewRoutingPolicy creates a routing policy from engine names keyed by
+// operation name or RouteDefault. Operations may also be given by their
+// engine-neutral identifiers, such as OpNews, or as "search" for OpSearch.
+func NewRoutingPolicy(routes map[string]string) *RoutingPolicy {
+	resolved := make(map[string]string, len(routes))
+	for operation, engine := range routes {
+		resolved[resolveOperation(operation)] = engine
+	}
+	return &RoutingPolicy{Routes: resolved}
+}
+
+// resolveOperation maps a short operation name to its tool name. Unknown
+// names are returned unchanged.
+func resolveOperation(name string) string {
+	if name == "search" {
+		return OpSearch
+	}
+	return ToolName(name)
+}
+
+// ParseRoutes parses routes written as "news=serper,scholar=serpapi,*=brave"
+func ParseRoutes(s string) (map[string]string, error) {
+	routes := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		operation, engine, ok := strings.Cut(item, "=")
+		operation, engine = strings.TrimSpace(operation), strings.TrimSpace(engine)
+		if !ok || operation == "" || engine == "" {
+			return nil, fmt.Errorf("invalid route %q: expected operation=engine", item)
+		}
+		routes[operation] = engine
+	}
+	return routes, nil
+}
+
+// Validate checks that every route names a known operation and a registered
+// engine that supports it, and reports all problems at once
+func (p *RoutingPolicy) Validate(registry *omniserp.Registry) error {
+	operations := make([]string, 0, len(p.Routes))
+	for operation := range p.Routes {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	var errs []error
+	for _, operation := range operations {
+		name := p.Routes[operation]
+		if operation != RouteDefault && !slices.Contains(AllOperations(), operation) {
+			errs = append(errs, fmt.Errorf("route %s: unknown operation (available: %s)", operation, strings.Join(AllOperations(), ", ")))
+			continue
+		}
+		engine, exists := registry.Get(name)
+		if !exists {
+			errs = append(errs, fmt.Errorf("route %s: engine '%s' not found. Available engines: %v", operation, name, registry.List()))
+			continue
+		}
+		if operation != RouteDefault && !slices.Contains(engine.GetSupportedTools(), operation) {
+			errs = append(errs, fmt.Errorf("route %s: %w: '%s' (engine: %s, supported: %v)",
+				operation, ErrOperationNotSupported, operation, name, engine.GetSupportedTools()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Select implements SelectionPolicy
+func (p *RoutingPolicy) Select(req SelectionRequest) omniserp.Engine {
+	for _, key := range []string{req.Operation, RouteDefault} {
+		name, ok := p.Routes[key]
+		if !ok {
+			continue
+		}
+		for _, engine := range req.Engines {
+			if engine.GetName() == name {
+				return engine
+			}
+		}
+	}
+	if p.Base != nil {
+		return p.Base.Select(req)
+	}
+	return req.Engines[0]
+}
+
+// SetRoutes validates routes against the registered engines and enables
+// per-operation routing, replacing any selection policy
+func (c *Client) SetRoutes(routes map[string]string) error {
+	policy := NewRoutingPolicy(routes)
+	if err := policy.Validate(c.registry); err != nil {
+		return fmt.Errorf("invalid routes: %w", err)
+	}
+	c.SetSelectionPolicy(policy)
+	return nil
+}
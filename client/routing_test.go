@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("news=serper, scholar=serpapi,*=brave")
+	if err != nil {
+		t.Fatalf("ParseRoutes failed: %v", err)
+	}
+	if routes["news"] != "serper" || routes["scholar"] != "serpapi" || routes[RouteDefault] != "brave" {
+		t.Errorf("Unexpected routes: %v", routes)
+	}
+
+	policy := NewRoutingPolicy(routes)
+	if policy.Routes[OpSearchNews] != "serper" || policy.Routes[OpSearchScholar] != "serpapi" {
+		t.Errorf("Expected short operation names to be resolved, got %v", policy.Routes)
+	}
+
+	if _, err := ParseRoutes("news"); err == nil {
+		t.Error("Expected error for route without engine")
+	}
+}
+
+func TestRouting(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi", "brave")
+
+	err := c.SetRoutes(map[string]string{
+		OpSearchNews:    "serper",
+		OpSearchScholar: "serpapi",
+		RouteDefault:    "brave",
+	})
+	if err != nil {
+		t.Fatalf("SetRoutes failed: %v", err)
+	}
+
+	ctx := context.Background()
+	params := omniserp.SearchParams{Query: "q"}
+	cases := []struct {
+		search func(context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
+		want   string
+	}{
+		{c.SearchNews, "serper"},
+		{c.SearchScholar, "serpapi"},
+		{c.Search, "brave"},
+		{c.SearchImages, "brave"},
+	}
+	for _, tc := range cases {
+		result, err := tc.search(ctx, params)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if got := firstLink(result); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestRoutingValidation(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: []string{OpSearch, OpSearchNews}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	err = c.SetRoutes(map[string]string{
+		OpSearchScholar: "serper",
+		OpSearchNews:    "brave",
+		"search_web":    "serper",
+	})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	if !errors.Is(err, ErrOperationNotSupported) {
+		t.Errorf("Expected unsupported operation to be reported, got %v", err)
+	}
+	for _, want := range []string{"engine 'brave' not found", "unknown operation"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+}
+
+// TestRoutingReload changes the routes, as the MCP config reloader does,
+// while searches are running; run with -race
+func TestRoutingReload(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi", "brave")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				if err := c.SetRoutes(map[string]string{RouteDefault: "brave"}); err != nil {
+					t.Errorf("SetRoutes failed: %v", err)
+					return
+				}
+			} else {
+				c.SetSelectionPolicy(nil)
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	for range 200 {
+		if _, err := c.Search(ctx, omniserp.SearchParams{Query: "q"}); err != nil {
+			t.Errorf("Search failed: %v", err)
+			break
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
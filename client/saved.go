@@ -0,0 +1,321 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/cron"
+)
+
+// ErrSavedSearchNotFound is returned for an unknown saved search name
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is a named search definition that teams can share so
+// monitoring queries are run the same way everywhere
+type SavedSearch struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Operation is a normalized search operation given by tool name or short
+	// name: search (default), news, images, scholar, or shopping
+	Operation string                `json:"operation,omitempty"`
+	Params    omniserp.SearchParams `json:"params"`
+
+	// Engine runs the search on a specific engine; empty uses the client's
+	// current engine, routes, and selection policy
+	Engine string `json:"engine,omitempty"`
+
+	// Schedule is a cron expression (see cron.Parse) for scheduled runners,
+	// such as "0 * * * *" for hourly; empty means the search is only run on
+	// demand
+	Schedule string `json:"schedule,omitempty"`
+
+	// Webhook receives the new results of scheduled runs in a custom shape;
+	// nil leaves them to the runner's own hooks
+	Webhook *SavedWebhook `json:"webhook,omitempty"`
+}
+
+// SavedWebhook is an endpoint, such as a Slack or Teams incoming webhook,
+// that scheduled runners post the new results of a saved search to
+type SavedWebhook struct {
+	URL string `json:"url"`
+
+	// Template is a Go text/template that renders the request body from a
+	// scheduler.WebhookData; empty posts the runner's default Slack-compatible
+	// payload. Besides the builtins, templates can use json, which encodes a
+	// value as JSON so strings are quoted and escaped inside JSON bodies, and
+	// truncate, which shortens a string to n runes.
+	Template string `json:"template,omitempty"`
+
+	// ContentType is the content type of the body (default application/json)
+	ContentType string `json:"content_type,omitempty"`
+
+	// Headers are added to the request, e.g. for authentication
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// webhookFuncs are the functions available to webhook templates
+var webhookFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n]) + "…"
+		}
+		return s
+	},
+}
+
+// ParseTemplate parses the webhook template with the webhook functions
+func (w *SavedWebhook) ParseTemplate() (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookFuncs).Parse(w.Template)
+}
+
+// validate checks the webhook URL and template
+func (w *SavedWebhook) validate() error {
+	var errs []error
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("webhook.url %q must be an http or https URL", w.URL))
+	}
+	if _, err := w.ParseTemplate(); err != nil {
+		errs = append(errs, fmt.Errorf("webhook.template: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// normalizedOp runs and normalizes one operation on an engine
+type normalizedOp struct {
+	search    func(omniserp.Engine, context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
+	normalize func(*omniserp.Normalizer, *omniserp.SearchResult, string) (*omniserp.NormalizedSearchResult, error)
+}
+
+// normalizedOps are the operations with a normalized result
+var normalizedOps = map[string]normalizedOp{
+	OpSearch:         {omniserp.Engine.Search, (*omniserp.Normalizer).NormalizeSearch},
+	OpSearchNews:     {omniserp.Engine.SearchNews, (*omniserp.Normalizer).NormalizeNews},
+	OpSearchImages:   {omniserp.Engine.SearchImages, (*omniserp.Normalizer).NormalizeImages},
+	OpSearchScholar:  {omniserp.Engine.SearchScholar, (*omniserp.Normalizer).NormalizeScholar},
+	OpSearchShopping: {omniserp.Engine.SearchShopping, (*omniserp.Normalizer).NormalizeShopping},
+}
+
+// Validate checks the saved search and resolves a short operation name
+func (s *SavedSearch) Validate() error {
+	var errs []error
+	if strings.TrimSpace(s.Name) == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if s.Operation == "" {
+		s.Operation = OpSearch
+	}
+	s.Operation = resolveOperation(s.Operation)
+	if _, ok := normalizedOps[s.Operation]; !ok {
+		errs = append(errs, fmt.Errorf("operation %q must be search, news, images, scholar, or shopping", s.Operation))
+	}
+	if strings.TrimSpace(s.Params.Query) == "" {
+		errs = append(errs, errors.New("params.query is required"))
+	}
+	if s.Params.Locale != "" {
+		if _, err := omniserp.ParseLocale(s.Params.Locale); err != nil {
+			errs = append(errs, fmt.Errorf("params.locale: %w", err))
+		}
+	}
+	if s.Schedule != "" {
+		if _, err := cron.Parse(s.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("schedule: %w", err))
+		}
+	}
+	if s.Webhook != nil {
+		if err := s.Webhook.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SavedSearches is a set of saved searches, optionally persisted to a JSON
+// file. It is safe for concurrent use.
+type SavedSearches struct {
+	path string // empty if not persisted
+
+	mu       sync.RWMutex
+	searches map[string]SavedSearch
+}
+
+// NewSavedSearches creates an empty in-memory set of saved searches
+func NewSavedSearches() *SavedSearches {
+	return &SavedSearches{searches: make(map[string]SavedSearch)}
+}
+
+// LoadSavedSearches opens the saved searches in the JSON file at path, which
+// is created on the first Save if it does not exist
+func LoadSavedSearches(path string) (*SavedSearches, error) {
+	s := NewSavedSearches()
+	s.path = path
+
+	// #nosec G304 -- saved searches path is provided by the caller
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+
+	var searches []SavedSearch
+	if err := json.Unmarshal(data, &searches); err != nil {
+		return nil, fmt.Errorf("failed to parse saved searches %s: %w", path, err)
+	}
+	for _, search := range searches {
+		if err := search.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid saved search %q: %w", search.Name, err)
+		}
+		s.searches[search.Name] = search
+	}
+	return s, nil
+}
+
+// Save validates and adds a saved search, replacing one with the same name
+func (s *SavedSearches) Save(search SavedSearch) error {
+	if err := search.Validate(); err != nil {
+		return fmt.Errorf("invalid saved search %q: %w", search.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches[search.Name] = search
+	return s.persist()
+}
+
+// Delete removes a saved search
+func (s *SavedSearches) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.searches[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSavedSearchNotFound, name)
+	}
+	delete(s.searches, name)
+	return s.persist()
+}
+
+// Get returns a saved search by name
+func (s *SavedSearches) Get(name string) (SavedSearch, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	search, ok := s.searches[name]
+	return search, ok
+}
+
+// List returns the saved searches sorted by name
+func (s *SavedSearches) List() []SavedSearch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	searches := make([]SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		searches = append(searches, search)
+	}
+	slices.SortFunc(searches, func(a, b SavedSearch) int { return strings.Compare(a.Name, b.Name) })
+	return searches
+}
+
+// persist writes the saved searches to the file, if any. The caller must
+// hold the write lock.
+func (s *SavedSearches) persist() error {
+	if s.path == "" {
+		return nil
+	}
+	searches := make([]SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		searches = append(searches, search)
+	}
+	slices.SortFunc(searches, func(a, b SavedSearch) int { return strings.Compare(a.Name, b.Name) })
+
+	data, err := json.MarshalIndent(searches, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal saved searches: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write saved searches: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// SetSavedSearches replaces the saved searches used by RunSaved
+func (c *Client) SetSavedSearches(searches *SavedSearches) {
+	c.mu.Lock()
+	c.saved = searches
+	c.mu.Unlock()
+}
+
+// SavedSearches returns the saved searches used by RunSaved
+func (c *Client) SavedSearches() *SavedSearches {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.saved
+}
+
+// RunSaved runs a saved search by name and returns its normalized result.
+// Saved searches with an engine run on that engine without failover;
+// others run like the corresponding normalized method.
+func (c *Client) RunSaved(ctx context.Context, name string) (*omniserp.NormalizedSearchResult, error) {
+	saved, ok := c.SavedSearches().Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, name)
+	}
+	return c.runNormalized(ctx, saved.Operation, saved.Engine, saved.Params)
+}
+
+// runNormalized runs a normalized operation on the named engine, or on the
+// current or selected engine with failover if name is empty
+func (c *Client) runNormalized(ctx context.Context, operation, name string, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params = omniserp.ApplyDefaults(ctx, params)
+	op := normalizedOps[operation]
+
+	var result *omniserp.SearchResult
+	var engine omniserp.Engine
+	var err error
+	if d := c.deterministic(); d != nil && name != "" {
+		// Deterministic runs are pinned to one engine
+		if name != d.Engine {
+			return nil, fmt.Errorf("deterministic mode is pinned to engine %s, not %s", d.Engine, name)
+		}
+		name = ""
+	}
+	if name == "" {
+		result, engine, err = c.call(ctx, operation, func(ctx context.Context, engine omniserp.Engine) (*omniserp.SearchResult, error) {
+			return op.search(engine, ctx, params)
+		})
+	} else {
+		if engine, err = c.GetEngine(name); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(engine.GetSupportedTools(), operation) {
+			return nil, fmt.Errorf("%w: %s (engine: %s)", ErrOperationNotSupported, operation, name)
+		}
+		start := time.Now()
+		result, err = op.search(engine, ctx, params)
+		c.stats.record(engine.GetName(), time.Since(start), err != nil)
+		if err == nil {
+			c.usage.record(engine.GetName(), result)
+			result = c.currentSanitizer().Sanitize(result)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := op.normalize(omniserp.NewNormalizer(engine.GetName()), result, params.Query)
+	return c.finishSearch(ctx, operation, normalized, params, err)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestSavedSearches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.json")
+	saved, err := LoadSavedSearches(path)
+	if err != nil {
+		t.Fatalf("LoadSavedSearches failed: %v", err)
+	}
+
+	if err := saved.Save(SavedSearch{Name: "empty"}); err == nil {
+		t.Error("Expected an error for a saved search without a query")
+	}
+	if err := saved.Save(SavedSearch{Name: "maps", Operation: "maps", Params: omniserp.SearchParams{Query: "cafe"}}); err == nil {
+		t.Error("Expected an error for an operation without a normalized result")
+	}
+	if err := saved.Save(SavedSearch{Name: "bad", Params: omniserp.SearchParams{Query: "go"}, Schedule: "hourly"}); err == nil {
+		t.Error("Expected an error for an invalid schedule")
+	}
+	if err := saved.Save(SavedSearch{Name: "bad", Params: omniserp.SearchParams{Query: "go", Locale: "xx-YY"}}); err == nil {
+		t.Error("Expected an error for an unknown locale")
+	}
+	if err := saved.Save(SavedSearch{Name: "bad", Params: omniserp.SearchParams{Query: "go"}, Webhook: &SavedWebhook{URL: "hooks.example"}}); err == nil {
+		t.Error("Expected an error for a webhook URL without a scheme")
+	}
+	if err := saved.Save(SavedSearch{Name: "bad", Params: omniserp.SearchParams{Query: "go"}, Webhook: &SavedWebhook{URL: "https://hooks.example", Template: "{{.Count"}}); err == nil {
+		t.Error("Expected an error for an invalid webhook template")
+	}
+	err = saved.Save(SavedSearch{
+		Name:      "competitor-news",
+		Operation: "news",
+		Params:    omniserp.SearchParams{Query: "acme corp"},
+		Schedule:  "0 * * * *",
+		Webhook:   &SavedWebhook{URL: "https://hooks.example/news", Template: `{"text": {{json .Search.Name}}}`},
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded, err := LoadSavedSearches(path)
+	if err != nil {
+		t.Fatalf("LoadSavedSearches failed: %v", err)
+	}
+	search, ok := reloaded.Get("competitor-news")
+	if !ok || search.Operation != OpSearchNews || search.Schedule != "0 * * * *" || search.Webhook == nil || search.Webhook.URL != "https://hooks.example/news" {
+		t.Errorf("Unexpected saved search after reloading: %+v", search)
+	}
+
+	if err := reloaded.Delete("competitor-news"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := reloaded.Delete("competitor-news"); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("Expected ErrSavedSearchNotFound, got %v", err)
+	}
+}
+
+func TestRunSaved(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://serper.example/" + params.Query), nil
+	}})
+	registry.Register(&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		organic := []any{map[string]any{"title": params.Query, "link": "https://serpapi.example/" + params.Query}}
+		return &omniserp.SearchResult{Data: map[string]any{"organic_results": organic}}, nil
+	}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.RunSaved(ctx, "missing"); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("Expected ErrSavedSearchNotFound, got %v", err)
+	}
+
+	_ = c.SavedSearches().Save(SavedSearch{Name: "default", Params: omniserp.SearchParams{Query: "go"}})
+	_ = c.SavedSearches().Save(SavedSearch{Name: "pinned", Engine: "serpapi", Params: omniserp.SearchParams{Query: "go"}})
+
+	result, err := c.RunSaved(ctx, "default")
+	if err != nil {
+		t.Fatalf("RunSaved failed: %v", err)
+	}
+	if got := result.OrganicResults[0].Link; got != "https://serper.example/go" {
+		t.Errorf("Expected the current engine, got %s", got)
+	}
+	if result.SearchMetadata.Fingerprint == "" {
+		t.Error("Expected the query fingerprint to be recorded")
+	}
+
+	result, err = c.RunSaved(ctx, "pinned")
+	if err != nil {
+		t.Fatalf("RunSaved failed: %v", err)
+	}
+	if got := result.OrganicResults[0].Link; got != "https://serpapi.example/go" {
+		t.Errorf("Expected the pinned engine, got %s", got)
+	}
+}
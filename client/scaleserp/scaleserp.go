@@ -0,0 +1,56 @@
+// Package scaleserp implements the omniserp.Engine interface for the
+// ScaleSERP Google SERP API. Its responses share the format of ValueSerp;
+// results are returned as decoded JSON and normalized by omniserp.Normalizer.
+package scaleserp
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/plexusone/omniserp/client/internal/trajectdata"
+)
+
+const (
+	baseURL    = "https://api.scaleserp.com"
+	engineName = "scaleserp"
+)
+
+// config describes ScaleSERP, which supports shopping but not image searches
+var config = trajectdata.Config{
+	Name:    engineName,
+	Title:   "ScaleSERP",
+	BaseURL: baseURL,
+	Tools: []string{
+		"google_search",
+		"google_search_news",
+		"google_search_places",
+		"google_search_shopping",
+	},
+}
+
+// Engine implements the omniserp.Engine interface for ScaleSERP
+type Engine struct {
+	*trajectdata.Engine
+}
+
+// Extra declares the ScaleSERP-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra = trajectdata.Extra
+
+// New creates a new ScaleSERP engine from the SCALESERP_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("SCALESERP_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("SCALESERP_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new ScaleSERP engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	engine, err := trajectdata.New(config, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{Engine: engine}, nil
+}
@@ -0,0 +1,149 @@
+package scaleserp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each search type and records the query
+// of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	fixtures := map[string][]byte{}
+	for searchType, name := range map[string]string{"": "search.json", "news": "news.json", "places": "places.json", "shopping": "shopping.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[searchType] = data
+	}
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Path != "/search" || query.Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"request_info":{"success":false,"message":"Invalid API key"}}`))
+			return
+		}
+		_, _ = w.Write(fixtures[query.Get("search_type")])
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", Country: "US"}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if query.Get("q") != "golang" || query.Has("search_type") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 || normalized.OrganicResults[0].Engine != engineName {
+		t.Fatalf("Unexpected organic results: %+v", normalized.OrganicResults)
+	}
+	if normalized.AnswerBox == nil || normalized.KnowledgeGraph == nil {
+		t.Error("Expected the answer box and knowledge graph")
+	}
+	if meta := normalized.SearchMetadata; meta.TotalResults != 2540000 || meta.Credits != 1 {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if query.Get("search_type") != "news" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 2 || normalized.NewsResults[1].Source != "news.example.com" {
+		t.Errorf("Unexpected news results: %+v", normalized.NewsResults)
+	}
+}
+
+func TestSearchShopping(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchShopping(context.Background(), omniserp.SearchParams{Query: "mechanical keyboard", Freshness: omniserp.FreshnessDay})
+	if err != nil {
+		t.Fatalf("SearchShopping failed: %v", err)
+	}
+	if query.Get("search_type") != "shopping" || query.Has("time_period") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeShopping(result, "mechanical keyboard")
+	if err != nil {
+		t.Fatalf("NormalizeShopping failed: %v", err)
+	}
+	if len(normalized.ShoppingResults) != 2 {
+		t.Fatalf("Expected 2 shopping results, got %d", len(normalized.ShoppingResults))
+	}
+	first := normalized.ShoppingResults[0]
+	if first.ProductID != "12873465239087" || first.Price != "$79.99" || first.Currency != "USD" || first.Source != "Keychron" || first.Reviews != 3120 || first.Thumbnail == "" {
+		t.Errorf("Unexpected first shopping result: %+v", first)
+	}
+	if second := normalized.ShoppingResults[1]; second.Price != "$169.00" || second.Source != "Best Buy" {
+		t.Errorf("Expected the parsed price, got %+v", second)
+	}
+}
+
+func TestSearchPlaces(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchPlaces(context.Background(), omniserp.SearchParams{Query: "coffee", Location: "Austin,Texas,United States"})
+	if err != nil {
+		t.Fatalf("SearchPlaces failed: %v", err)
+	}
+	if query.Get("search_type") != "places" || query.Get("location") != "Austin,Texas,United States" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizePlaces(result, "coffee")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	if len(normalized.PlaceResults) != 1 || normalized.PlaceResults[0].Latitude != 30.2637 {
+		t.Errorf("Unexpected places: %+v", normalized.PlaceResults)
+	}
+}
+
+func TestUnsupportedOperations(t *testing.T) {
+	engine, _ := NewWithAPIKey("test-key")
+	if _, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher"}); err == nil {
+		t.Error("Expected an error for image searches")
+	}
+	for _, tool := range engine.GetSupportedTools() {
+		if tool == "google_search_images" {
+			t.Error("Image search should not be reported as supported")
+		}
+	}
+}
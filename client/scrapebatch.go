@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/plexusone/omniserp"
+)
+
+// defaultBatchConcurrency bounds the parallel scrapes of a batch
+const defaultBatchConcurrency = 8
+
+// ScrapeBatchOptions configures ScrapeBatch
+type ScrapeBatchOptions struct {
+	// Concurrency is the maximum parallel scrapes across all hosts (default 8)
+	Concurrency int
+
+	// Politeness limits the requests per host (default: one request per
+	// host at a time, at least 1s apart). Ignored if Scheduler is set.
+	Politeness *PolitenessPolicy
+
+	// Scheduler shares per-host limits across batches
+	Scheduler *HostScheduler
+
+	// Params sets the redirect policy of every scrape; its URL is ignored
+	Params omniserp.ScrapeParams
+
+	// OnResult is called with the outcome of each URL as it finishes, such
+	// as to save progress (see RunScrapeJob). It is called concurrently.
+	OnResult func(ScrapeBatchResult)
+}
+
+// ScrapeBatchResult is the outcome of scraping one URL
+type ScrapeBatchResult struct {
+	URL    string                           `json:"url"`
+	Result *omniserp.NormalizedScrapeResult `json:"result,omitempty"`
+	Error  string                           `json:"error,omitempty"`
+}
+
+// ScrapeBatch scrapes URLs concurrently while keeping each host within the
+// politeness policy, and returns the results in input order. Failed URLs
+// carry an error message; an error is returned only if every URL fails.
+func (c *Client) ScrapeBatch(ctx context.Context, urls []string, opts *ScrapeBatchOptions) ([]ScrapeBatchResult, error) {
+	if opts == nil {
+		opts = &ScrapeBatchOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	scheduler := opts.Scheduler
+	if scheduler == nil {
+		scheduler = NewHostScheduler(opts.Politeness)
+	}
+
+	results := make([]ScrapeBatchResult, len(urls))
+	errs := make([]error, len(urls))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, pageURL := range urls {
+		results[i].URL = pageURL
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Wait for the host first so requests queued behind a slow host
+			// do not hold slots other hosts could use
+			release, err := scheduler.Acquire(ctx, pageURL)
+			if err == nil {
+				defer release()
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					err = ctx.Err()
+				}
+			}
+			if err == nil {
+				params := opts.Params
+				params.URL = pageURL
+				results[i].Result, err = c.ScrapeNormalized(ctx, params)
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+				errs[i] = err
+			}
+			if opts.OnResult != nil {
+				opts.OnResult(results[i])
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := allFailed(errs); err != nil {
+		return results, fmt.Errorf("all scrapes failed: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scrape job event types
+const (
+	jobEventStart  = "start"
+	jobEventDone   = "done"
+	jobEventFailed = "failed"
+)
+
+// ScrapeJob is the progress of a batch scrape or sitemap crawl recorded in
+// a JobHistory. URLs that failed stay pending, so they are retried when the
+// job resumes.
+type ScrapeJob struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source,omitempty"` // e.g. the sitemap URL
+	URLs      []string  `json:"urls"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Done are the scraped URLs, and Errors the last error of each URL
+	// that failed and has not been scraped since
+	Done   map[string]bool   `json:"done,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Pending returns the URLs of the job that have not been scraped, in order
+func (j *ScrapeJob) Pending() []string {
+	var pending []string
+	for _, u := range j.URLs {
+		if !j.Done[u] {
+			pending = append(pending, u)
+		}
+	}
+	return pending
+}
+
+// Finished reports whether every URL of the job has been scraped
+func (j *ScrapeJob) Finished() bool {
+	return len(j.Pending()) == 0
+}
+
+// jobEvent is one line of a job history
+type jobEvent struct {
+	Job    string    `json:"job"`
+	Type   string    `json:"type"`
+	At     time.Time `json:"at"`
+	Source string    `json:"source,omitempty"`
+	URLs   []string  `json:"urls,omitempty"` // of start events
+	URL    string    `json:"url,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// JobHistory is an append-only JSON Lines file of scrape job events. Every
+// scraped or failed URL is appended as it finishes, so the state of a job
+// survives interruptions and the job can be resumed by ID. It is safe for
+// concurrent use, but a file must not be shared by processes running the
+// same job.
+type JobHistory struct {
+	path string
+
+	mu   sync.Mutex
+	jobs map[string]*ScrapeJob
+}
+
+// OpenJobHistory opens the job history file at path, creating it on the
+// first append if it does not exist
+func OpenJobHistory(path string) (*JobHistory, error) {
+	h := &JobHistory{path: path, jobs: make(map[string]*ScrapeJob)}
+
+	// #nosec G304 -- job history path is provided by the caller
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event jobEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse job history %s line %d: %w", path, line, err)
+		}
+		h.apply(event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job history: %w", err)
+	}
+	return h, nil
+}
+
+// apply updates the jobs with an event. The caller must hold the lock or
+// own the history exclusively.
+func (h *JobHistory) apply(event jobEvent) {
+	if event.Type == jobEventStart {
+		h.jobs[event.Job] = &ScrapeJob{
+			ID:        event.Job,
+			Source:    event.Source,
+			URLs:      event.URLs,
+			CreatedAt: event.At,
+			UpdatedAt: event.At,
+			Done:      make(map[string]bool),
+			Errors:    make(map[string]string),
+		}
+		return
+	}
+
+	job, ok := h.jobs[event.Job]
+	if !ok {
+		return
+	}
+	job.UpdatedAt = event.At
+	switch event.Type {
+	case jobEventDone:
+		job.Done[event.URL] = true
+		delete(job.Errors, event.URL)
+	case jobEventFailed:
+		job.Errors[event.URL] = event.Error
+	}
+}
+
+// append applies an event and writes it to the history file
+func (h *JobHistory) append(event jobEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job event: %w", err)
+	}
+	// #nosec G304 -- job history path is provided by the caller
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open job history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write job history: %w", err)
+	}
+	h.apply(event)
+	return nil
+}
+
+// StartJob records a new job of urls, without duplicates, with a random
+// ID. Source describes where the URLs came from, such as a sitemap URL, and
+// may be empty.
+func (h *JobHistory) StartJob(source string, urls []string) (*ScrapeJob, error) {
+	seen := make(map[string]bool, len(urls))
+	urls = slices.DeleteFunc(slices.Clone(urls), func(u string) bool {
+		duplicate := seen[u]
+		seen[u] = true
+		return duplicate
+	})
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("a scrape job needs at least one URL")
+	}
+	id := newJobID()
+	if err := h.append(jobEvent{Job: id, Type: jobEventStart, At: time.Now().UTC(), Source: source, URLs: urls}); err != nil {
+		return nil, err
+	}
+	return h.Job(id)
+}
+
+// Job returns a copy of the state of a job
+func (h *JobHistory) Job(id string) (*ScrapeJob, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	job, ok := h.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("scrape job %q not found in %s", id, h.path)
+	}
+	return copyJob(job), nil
+}
+
+// Jobs returns copies of the jobs in the history, oldest first
+func (h *JobHistory) Jobs() []*ScrapeJob {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	jobs := make([]*ScrapeJob, 0, len(h.jobs))
+	for _, job := range h.jobs {
+		jobs = append(jobs, copyJob(job))
+	}
+	slices.SortFunc(jobs, func(a, b *ScrapeJob) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return jobs
+}
+
+// copyJob returns a deep copy of a job
+func copyJob(job *ScrapeJob) *ScrapeJob {
+	c := *job
+	c.URLs = slices.Clone(job.URLs)
+	c.Done = maps.Clone(job.Done)
+	c.Errors = maps.Clone(job.Errors)
+	return &c
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RunScrapeJob scrapes the pending URLs of a job with ScrapeBatch and
+// records each outcome in the history as it finishes, so an interrupted
+// job resumes where it stopped when run again. It returns the results of
+// this run only, and the updated job.
+func (c *Client) RunScrapeJob(ctx context.Context, history *JobHistory, id string, opts *ScrapeBatchOptions) ([]ScrapeBatchResult, *ScrapeJob, error) {
+	job, err := history.Job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending := job.Pending()
+	if len(pending) == 0 {
+		return nil, job, nil
+	}
+
+	batchOpts := ScrapeBatchOptions{}
+	if opts != nil {
+		batchOpts = *opts
+	}
+	var (
+		mu       sync.Mutex
+		writeErr error
+	)
+	onResult := batchOpts.OnResult
+	batchOpts.OnResult = func(result ScrapeBatchResult) {
+		// Cancelled scrapes stay pending rather than counting as failures
+		if ctx.Err() != nil && result.Error != "" {
+			return
+		}
+		event := jobEvent{Job: id, Type: jobEventDone, At: time.Now().UTC(), URL: result.URL}
+		if result.Error != "" {
+			event.Type, event.Error = jobEventFailed, result.Error
+		}
+		if err := history.append(event); err != nil {
+			mu.Lock()
+			writeErr = errors.Join(writeErr, err)
+			mu.Unlock()
+		}
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	results, batchErr := c.ScrapeBatch(ctx, pending, &batchOpts)
+	job, err = history.Job(id)
+	if err != nil {
+		return results, nil, err
+	}
+	if writeErr != nil {
+		return results, job, writeErr
+	}
+	return results, job, batchErr
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestRunScrapeJob(t *testing.T) {
+	var broken atomic.Bool
+	broken.Store(true)
+	var scrapes atomic.Int32
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		scrapes.Add(1)
+		if strings.Contains(params.Query, "flaky") && broken.Load() {
+			return nil, fmt.Errorf("scrape failed")
+		}
+		return &omniserp.SearchResult{Data: map[string]any{"text": "page " + params.Query}}, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	history, err := OpenJobHistory(path)
+	if err != nil {
+		t.Fatalf("OpenJobHistory failed: %v", err)
+	}
+	urls := []string{"https://a.example/1", "https://a.example/flaky", "https://b.example/1", "https://a.example/1"}
+	job, err := history.StartJob("https://a.example/sitemap.xml", urls)
+	if err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	if len(job.URLs) != 3 {
+		t.Fatalf("Expected duplicate URLs to be dropped, got %v", job.URLs)
+	}
+
+	opts := &ScrapeBatchOptions{Politeness: &PolitenessPolicy{MinDelay: time.Millisecond}}
+	results, job, err := c.RunScrapeJob(context.Background(), history, job.ID, opts)
+	if err != nil {
+		t.Fatalf("RunScrapeJob failed: %v", err)
+	}
+	if len(results) != 3 || job.Finished() || job.Errors["https://a.example/flaky"] == "" {
+		t.Errorf("Expected the flaky URL to fail, got %+v", job)
+	}
+
+	// A reopened history resumes with the failed URL only
+	history, err = OpenJobHistory(path)
+	if err != nil {
+		t.Fatalf("OpenJobHistory failed: %v", err)
+	}
+	job, err = history.Job(job.ID)
+	if err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if pending := job.Pending(); !slices.Equal(pending, []string{"https://a.example/flaky"}) {
+		t.Fatalf("Expected the failed URL pending, got %v", pending)
+	}
+
+	broken.Store(false)
+	scrapes.Store(0)
+	results, job, err = c.RunScrapeJob(context.Background(), history, job.ID, opts)
+	if err != nil {
+		t.Fatalf("RunScrapeJob failed: %v", err)
+	}
+	if scrapes.Load() != 1 || len(results) != 1 || !job.Finished() || len(job.Errors) != 0 {
+		t.Errorf("Expected only the failed URL to be retried, got %d scrapes and %+v", scrapes.Load(), job)
+	}
+
+	if _, _, err := c.RunScrapeJob(context.Background(), history, "missing", opts); err == nil {
+		t.Error("Expected an error for an unknown job")
+	}
+	if jobs := history.Jobs(); len(jobs) != 1 || jobs[0].Source != "https://a.example/sitemap.xml" {
+		t.Errorf("Unexpected jobs: %+v", jobs)
+	}
+}
+
+func TestReadSitemap(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/pages.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>%[1]s/sitemap.xml</loc></sitemap>
+</sitemapindex>`, srv.URL)
+		case "/pages.xml":
+			fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/ </loc><lastmod>2026-10-01</lastmod></url>
+  <url><loc>https://example.com/about</loc></url>
+</urlset>`)
+		case "/posts.xml.gz":
+			gz := gzip.NewWriter(w)
+			fmt.Fprint(gz, `<urlset><url><loc>https://example.com/blog/1</loc></url><url><loc>https://example.com/about</loc></url></urlset>`)
+			_ = gz.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	urls, err := ReadSitemap(context.Background(), srv.URL+"/sitemap.xml", srv.Client())
+	if err != nil {
+		t.Fatalf("ReadSitemap failed: %v", err)
+	}
+	want := []string{"https://example.com/", "https://example.com/about", "https://example.com/blog/1"}
+	if !slices.Equal(urls, want) {
+		t.Errorf("Expected %v, got %v", want, urls)
+	}
+
+	if _, err := ReadSitemap(context.Background(), srv.URL+"/missing.xml", srv.Client()); err == nil {
+		t.Error("Expected an error for a missing sitemap")
+	}
+
+	// The default client refuses the loopback server
+	if _, err := ReadSitemap(context.Background(), srv.URL+"/sitemap.xml", nil); !errors.Is(err, omniserp.ErrPrivateAddress) {
+		t.Errorf("Expected omniserp.ErrPrivateAddress, got %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+// Package searchapi implements the omniserp.Engine interface for
+// SearchAPI.io, whose Google engines are close to SerpAPI's and include
+// Google Lens. Results are returned as decoded JSON and normalized by
+// omniserp.Normalizer.
+package searchapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://www.searchapi.io"
+	engineName    = "searchapi"
+	engineVersion = "1.0.0"
+	searchPath    = "/api/v1/search"
+	accountPath   = "/api/v1/me"
+
+	// maxNum is the largest number of results the API returns per request
+	maxNum = 100
+)
+
+// Engine implements the omniserp.Engine interface for SearchAPI.io
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new SearchAPI.io engine from the SEARCHAPI_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("SEARCHAPI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("SEARCHAPI_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new SearchAPI.io engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools, including Lens
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"google_search_images",
+		"google_search_videos",
+		"google_search_places",
+		"google_search_maps",
+		"google_search_reviews",
+		"google_search_shopping",
+		"google_search_scholar",
+		"google_search_lens",
+		"google_search_autocomplete",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	switch operation {
+	case "google_search_scholar":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamNumResults, omniserp.ParamPage, omniserp.ParamCites}
+	case "google_search_reviews":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage}
+	case "google_search_lens", "google_search_autocomplete":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamCountry}
+	}
+	return omniserp.AllParams()
+}
+
+// Extra declares the SearchAPI.io-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Device       string `extra:"device" description:"Device to emulate: desktop, tablet, or mobile"`
+	GoogleDomain string `extra:"google_domain" description:"Google domain to search, such as google.co.uk"`
+	Filter       int    `extra:"filter" description:"Set to 0 to include similar and omitted results"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// timePeriods maps freshness to the time_period parameter
+var timePeriods = map[omniserp.Freshness]string{
+	omniserp.FreshnessHour:  "last_hour",
+	omniserp.FreshnessDay:   "last_day",
+	omniserp.FreshnessWeek:  "last_week",
+	omniserp.FreshnessMonth: "last_month",
+	omniserp.FreshnessYear:  "last_year",
+}
+
+// buildParams converts SearchParams to the query parameters of a
+// SearchAPI.io engine, such as "google" or "google_news"
+func (e *Engine) buildParams(params omniserp.SearchParams, engine string) url.Values {
+	q := url.Values{}
+	q.Set("engine", engine)
+	q.Set("q", params.Query)
+
+	if params.Location != "" {
+		q.Set("location", params.Location)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.GoogleDomain != "" {
+		q.Set("google_domain", params.GoogleDomain)
+	}
+	if params.NumResults > 0 {
+		q.Set("num", strconv.Itoa(min(params.NumResults, maxNum)))
+	}
+	if params.Page > 1 {
+		q.Set("page", strconv.Itoa(params.Page))
+	}
+	if period, ok := timePeriods[params.Freshness]; ok {
+		q.Set("time_period", period)
+	}
+	if params.SafeSearch {
+		q.Set("safe", "active")
+	}
+	if params.Verbatim {
+		q.Set("nfpr", "1")
+	}
+	for name, value := range omniserp.ExtraQuery(params, e.ExtraParams()) {
+		q.Set(name, value)
+	}
+	return q
+}
+
+// get performs a GET request against a SearchAPI.io endpoint. The API key
+// is sent as a bearer token so it stays out of logged URLs.
+func (e *Engine) get(ctx context.Context, path string, query url.Values) (*omniserp.SearchResult, error) {
+	reqURL := e.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	start := time.Now()
+	// #nosec G704 -- request to the SearchAPI.io API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if message, ok := result["error"].(string); ok && message != "" {
+		return nil, fmt.Errorf("searchapi error: %s", message)
+	}
+
+	return &omniserp.SearchResult{
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// search performs a request on a SearchAPI.io engine
+func (e *Engine) search(ctx context.Context, query url.Values) (*omniserp.SearchResult, error) {
+	return e.get(ctx, searchPath, query)
+}
+
+// Credits returns the remaining credits of the SearchAPI.io account.
+// Account requests are free and do not count against the quota.
+func (e *Engine) Credits(ctx context.Context) (*omniserp.Credits, error) {
+	result, err := e.get(ctx, accountPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var me struct {
+		Account struct {
+			RemainingCredits int `json:"remaining_credits"`
+			MonthlyAllowance int `json:"monthly_allowance"`
+		} `json:"account"`
+	}
+	if err := json.Unmarshal([]byte(result.Raw), &me); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal account: %w", err)
+	}
+
+	return &omniserp.Credits{
+		Engine:    engineName,
+		Remaining: me.Account.RemainingCredits,
+		Limit:     me.Account.MonthlyAllowance,
+	}, nil
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google"))
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_news"))
+}
+
+// SearchImages performs an image search
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_images"))
+}
+
+// SearchVideos performs a video search
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_videos"))
+}
+
+// SearchPlaces performs a places search
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_maps"))
+}
+
+// SearchMaps performs a maps search
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_maps"))
+}
+
+// SearchReviews gets the reviews of a place. The query is the place ID or
+// data ID of a place from a places or maps search; data IDs have the form
+// "0x...:0x...".
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("engine", "google_maps_reviews")
+	if strings.Contains(params.Query, ":") {
+		q.Set("data_id", params.Query)
+	} else {
+		q.Set("place_id", params.Query)
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	return e.search(ctx, q)
+}
+
+// SearchShopping performs a shopping search
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, e.buildParams(params, "google_shopping"))
+}
+
+// SearchScholar performs a scholar search
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("engine", "google_scholar")
+	q.Set("q", params.Query)
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.NumResults > 0 {
+		q.Set("num", strconv.Itoa(min(params.NumResults, 20)))
+	}
+	if params.Page > 1 {
+		q.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.Cites != "" {
+		// The query searches within the citing papers and may be empty
+		q.Set("cites", params.Cites)
+		if params.Query == "" {
+			q.Del("q")
+		}
+	}
+	return e.search(ctx, q)
+}
+
+// SearchLens performs a visual search with Google Lens. The query is the
+// URL of the image to search with.
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if _, err := url.ParseRequestURI(params.Query); err != nil {
+		return nil, fmt.Errorf("invalid image URL: %w", err)
+	}
+	q := url.Values{}
+	q.Set("engine", "google_lens")
+	q.Set("url", params.Query)
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	return e.search(ctx, q)
+}
+
+// SearchAutocomplete gets search suggestions
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	q := url.Values{}
+	q.Set("engine", "google_autocomplete")
+	q.Set("q", params.Query)
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToLower(params.Country))
+	}
+	return e.search(ctx, q)
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by SearchAPI.io)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by SearchAPI.io")
+}
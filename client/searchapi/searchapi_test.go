@@ -0,0 +1,274 @@
+package searchapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each SearchAPI.io engine and records
+// the query of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	fixtures := map[string][]byte{}
+	for engine, name := range map[string]string{
+		"google":              "search.json",
+		"google_images":       "images.json",
+		"google_shopping":     "shopping.json",
+		"google_scholar":      "scholar.json",
+		"google_maps_reviews": "reviews.json",
+		"google_lens":         "lens.json",
+	} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[engine] = data
+	}
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Invalid API key."}`))
+			return
+		}
+		switch r.URL.Path {
+		case accountPath:
+			_, _ = w.Write([]byte(`{"account":{"current_month_usage":250,"monthly_allowance":10000,"remaining_credits":9750}}`))
+		case searchPath:
+			fixture, ok := fixtures[query.Get("engine")]
+			if !ok {
+				fixture = []byte(`{"error":"Unsupported engine."}`)
+			}
+			_, _ = w.Write(fixture)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSupportedTools(t *testing.T) {
+	engine, _ := NewWithAPIKey("test-key")
+	if !slices.Contains(engine.GetSupportedTools(), "google_search_lens") {
+		t.Error("Expected google_search_lens to be supported")
+	}
+	if slices.Contains(engine.GetSupportedTools(), "webpage_scrape") {
+		t.Error("Expected webpage_scrape to be unsupported")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Location:   "Austin,Texas,United States",
+		Country:    "US",
+		Language:   "en",
+		NumResults: 20,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Verbatim:   true,
+		Extra:      map[string]any{"device": "mobile", "google_domain": "google.de"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"engine":        "google",
+		"q":             "golang",
+		"location":      "Austin,Texas,United States",
+		"gl":            "us",
+		"hl":            "en",
+		"num":           "20",
+		"page":          "2",
+		"time_period":   "last_week",
+		"safe":          "active",
+		"nfpr":          "1",
+		"device":        "mobile",
+		"google_domain": "google.de",
+		"api_key":       "",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 || normalized.OrganicResults[1].Date != "Jan 2, 2026" {
+		t.Fatalf("Unexpected organic results: %+v", normalized.OrganicResults)
+	}
+	if box := normalized.AnswerBox; box == nil || box.Link != "https://go.dev/" {
+		t.Errorf("Unexpected answer box: %+v", box)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || len(normalized.RelatedSearches) != 2 {
+		t.Errorf("Unexpected people also ask %+v or related searches %+v", normalized.PeopleAlsoAsk, normalized.RelatedSearches)
+	}
+	if meta := normalized.SearchMetadata; meta.Country != "us" || meta.CorrectedQuery != "golang" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher"})
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+	if query.Get("engine") != "google_images" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeImages(result, "gopher")
+	if err != nil {
+		t.Fatalf("NormalizeImages failed: %v", err)
+	}
+	if len(normalized.ImageResults) != 1 {
+		t.Fatalf("Expected 1 image, got %d", len(normalized.ImageResults))
+	}
+	if img := normalized.ImageResults[0]; img.ImageURL != "https://go.dev/blog/gopher/header.jpg" || img.Source != "go.dev" || img.Width != 1200 {
+		t.Errorf("Unexpected image: %+v", img)
+	}
+}
+
+func TestSearchShopping(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchShopping(context.Background(), omniserp.SearchParams{Query: "gopher plush"})
+	if err != nil {
+		t.Fatalf("SearchShopping failed: %v", err)
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeShopping(result, "gopher plush")
+	if err != nil {
+		t.Fatalf("NormalizeShopping failed: %v", err)
+	}
+	if len(normalized.ShoppingResults) != 1 {
+		t.Fatalf("Expected 1 product, got %d", len(normalized.ShoppingResults))
+	}
+	product := normalized.ShoppingResults[0]
+	if product.Source != "Gopher Shop" || product.OriginalPrice != "$24.99" || product.Link != "https://www.google.com/shopping/product/1234567890" || product.Reviews != 152 {
+		t.Errorf("Unexpected product: %+v", product)
+	}
+}
+
+func TestSearchScholar(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchScholar(context.Background(), omniserp.SearchParams{Cites: "1234567890", Page: 2})
+	if err != nil {
+		t.Fatalf("SearchScholar failed: %v", err)
+	}
+	if query.Get("cites") != "1234567890" || query.Has("q") || query.Get("page") != "2" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeScholar(result, "")
+	if err != nil {
+		t.Fatalf("NormalizeScholar failed: %v", err)
+	}
+	if len(normalized.ScholarResults) != 1 {
+		t.Fatalf("Expected 1 paper, got %d", len(normalized.ScholarResults))
+	}
+	paper := normalized.ScholarResults[0]
+	if len(paper.Authors) != 2 || paper.Year != "2019" || paper.Citations != 187 || paper.CitesID != "9876543210" || paper.PDF == "" {
+		t.Errorf("Unexpected paper: %+v", paper)
+	}
+}
+
+func TestSearchReviews(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchReviews(context.Background(), omniserp.SearchParams{Query: "0x8644b509e45b3c11:0x5b2a0b2a3d3e9f1c"})
+	if err != nil {
+		t.Fatalf("SearchReviews failed: %v", err)
+	}
+	if query.Get("data_id") != "0x8644b509e45b3c11:0x5b2a0b2a3d3e9f1c" || query.Has("place_id") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeReviews(result, "")
+	if err != nil {
+		t.Fatalf("NormalizeReviews failed: %v", err)
+	}
+	if len(normalized.ReviewResults) != 2 || normalized.ReviewResults[0].Author != "Alex" {
+		t.Errorf("Unexpected reviews: %+v", normalized.ReviewResults)
+	}
+
+	if _, err := engine.SearchReviews(context.Background(), omniserp.SearchParams{Query: "ChIJLwPMoJm1RIYRetVp1EtGm10"}); err != nil {
+		t.Fatalf("SearchReviews failed: %v", err)
+	}
+	if query.Get("place_id") != "ChIJLwPMoJm1RIYRetVp1EtGm10" {
+		t.Errorf("Expected a place ID, got %s", query.Encode())
+	}
+}
+
+func TestSearchLens(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	imageURL := "https://go.dev/images/gophers/ladder.svg"
+	result, err := engine.SearchLens(context.Background(), omniserp.SearchParams{Query: imageURL, Country: "US"})
+	if err != nil {
+		t.Fatalf("SearchLens failed: %v", err)
+	}
+	if query.Get("engine") != "google_lens" || query.Get("url") != imageURL || query.Get("gl") != "us" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	if matches, ok := result.Data.(map[string]any)["visual_matches"].([]any); !ok || len(matches) != 1 {
+		t.Errorf("Expected the visual matches, got %v", result.Data)
+	}
+
+	if _, err := engine.SearchLens(context.Background(), omniserp.SearchParams{Query: "gopher"}); err == nil {
+		t.Error("Expected an error for a query that is not an image URL")
+	}
+}
+
+func TestCredits(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	credits, err := engine.Credits(context.Background())
+	if err != nil {
+		t.Fatalf("Credits failed: %v", err)
+	}
+	if credits.Remaining != 9750 || credits.Limit != 10000 {
+		t.Errorf("Unexpected credits: %+v", credits)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	// Errors reported in the body of a successful response
+	if _, err := engine.SearchVideos(context.Background(), omniserp.SearchParams{Query: "golang"}); err == nil {
+		t.Error("Expected the response error")
+	}
+
+	engine.apiKey = "wrong-key"
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+//go:build integration
+
+package searxng_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/client/searxng"
+)
+
+const (
+	// searxngImage is the container image started when SEARXNG_URL is not
+	// set
+	searxngImage = "searxng/searxng:latest"
+
+	// readyTimeout bounds the wait for the container to serve requests
+	readyTimeout = 90 * time.Second
+)
+
+// TestIntegration exercises the client, engine, and normalizer against a
+// real SearXNG instance: the one at SEARXNG_URL, or otherwise a container
+// started with Docker. Run it with "go test -tags=integration ./client/searxng".
+func TestIntegration(t *testing.T) {
+	baseURL := os.Getenv("SEARXNG_URL")
+	if baseURL == "" {
+		baseURL = startContainer(t)
+	}
+
+	engine, err := searxng.NewWithURL(baseURL)
+	if err != nil {
+		t.Fatalf("NewWithURL failed: %v", err)
+	}
+	registry := omniserp.NewRegistry()
+	registry.Register(engine)
+	c, err := client.NewWithRegistry(registry, engine.GetName())
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	result, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "golang", NumResults: 5})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if result.SearchMetadata.Engine != "searxng" || result.SearchMetadata.Fingerprint == "" {
+		t.Errorf("Unexpected metadata: %+v", result.SearchMetadata)
+	}
+	// The upstream engines of SearXNG may be unavailable, so an empty
+	// result is not a failure of the client
+	if len(result.OrganicResults) == 0 {
+		t.Log("SearXNG returned no results; its upstream engines may be unavailable")
+	}
+	if len(result.OrganicResults) > 5 {
+		t.Errorf("Expected at most 5 results, got %d", len(result.OrganicResults))
+	}
+	for i, r := range result.OrganicResults {
+		if r.Position != i+1 || r.Link == "" || r.Engine != "searxng" {
+			t.Errorf("Unexpected result %d: %+v", i, r)
+		}
+	}
+
+	if _, err := c.SearchNewsNormalized(ctx, omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Errorf("SearchNewsNormalized failed: %v", err)
+	}
+	if stats := c.Stats()["searxng"]; stats.Requests != 2 || stats.Errors != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+// startContainer starts SearXNG with the test settings and returns its URL
+// once it serves requests. The container is named up front and removed in
+// a cleanup registered before it is started, so it does not outlive a test
+// that fails or panics at any step. The test is skipped if Docker is not
+// available.
+func startContainer(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("SEARXNG_URL is not set and docker is not available")
+	}
+	settings, err := filepath.Abs(filepath.Join("testdata", "settings.yml"))
+	if err != nil {
+		t.Fatalf("Failed to resolve settings: %v", err)
+	}
+
+	name := fmt.Sprintf("omniserp-searxng-%d-%d", os.Getpid(), time.Now().UnixNano())
+	t.Cleanup(func() {
+		// #nosec G204 -- container name generated by the test
+		_ = exec.Command("docker", "rm", "--force", name).Run()
+	})
+
+	// #nosec G204 -- fixed arguments and a path within the repository
+	if out, err := exec.Command("docker", "run", "--detach", "--name", name,
+		"--publish", "127.0.0.1::8080",
+		"--volume", settings+":/etc/searxng/settings.yml:ro",
+		searxngImage).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to start SearXNG: %v: %s", err, out)
+	}
+
+	// #nosec G204 -- container name generated by the test
+	out, err := exec.Command("docker", "port", name, "8080/tcp").Output()
+	if err != nil {
+		t.Fatalf("Failed to get the SearXNG port: %v", err)
+	}
+	baseURL := "http://" + strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	waitReady(t, name, baseURL)
+	return baseURL
+}
+
+// waitReady polls the health endpoint of SearXNG until it answers 200,
+// failing the test if the container exits or does not become ready in
+// time
+func waitReady(t *testing.T, name, baseURL string) {
+	t.Helper()
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	deadline := time.Now().Add(readyTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := httpClient.Get(baseURL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+
+		// #nosec G204 -- container name generated by the test
+		out, err := exec.Command("docker", "inspect", "--format", "{{.State.Running}}", name).Output()
+		if err != nil || strings.TrimSpace(string(out)) != "true" {
+			// #nosec G204 -- container name generated by the test
+			logs, _ := exec.Command("docker", "logs", name).CombinedOutput()
+			t.Fatalf("SearXNG exited before becoming ready:\n%s", logs)
+		}
+		time.Sleep(time.Second)
+	}
+	t.Fatalf("SearXNG at %s did not become ready within %s", baseURL, readyTimeout)
+}
@@ -0,0 +1,463 @@
+// Package searxng implements the omniserp.Engine interface for SearXNG, the
+// self-hosted metasearch engine, which needs no API key. The instance must
+// enable the JSON output format (search.formats in settings.yml).
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	engineName    = "searxng"
+	engineVersion = "1.0.0"
+	searchPath    = "/search"
+)
+
+// SearXNG categories used for each operation
+const (
+	categoryGeneral = "general"
+	categoryNews    = "news"
+	categoryImages  = "images"
+	categoryVideos  = "videos"
+	categoryScience = "science"
+)
+
+// Engine implements the omniserp.Engine interface for a SearXNG instance.
+// Results are normalized by the engine and returned as the Data of each
+// search result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new SearXNG engine for the instance at the SEARXNG_URL env
+// var. For instances behind an mTLS gateway, SEARXNG_CLIENT_CERT and
+// SEARXNG_CLIENT_KEY name the PEM client certificate and key, and
+// SEARXNG_CA_CERT the CA bundle verifying the gateway.
+func New() (*Engine, error) {
+	baseURL := os.Getenv("SEARXNG_URL")
+	if baseURL == "" {
+		return nil, fmt.Errorf("SEARXNG_URL environment variable is required")
+	}
+	engine, err := NewWithURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := omniserp.TransportConfig{
+		CertFile: os.Getenv("SEARXNG_CLIENT_CERT"),
+		KeyFile:  os.Getenv("SEARXNG_CLIENT_KEY"),
+		CAFile:   os.Getenv("SEARXNG_CA_CERT"),
+	}
+	if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" || tlsConfig.CAFile != "" {
+		transport, err := tlsConfig.NewTransport()
+		if err != nil {
+			return nil, fmt.Errorf("invalid SearXNG TLS configuration: %w", err)
+		}
+		engine.SetTransport(transport)
+	}
+	return engine, nil
+}
+
+// NewWithURL creates a new SearXNG engine for the instance at baseURL
+func NewWithURL(baseURL string) (*Engine, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid SearXNG URL: %w", err)
+	}
+	return &Engine{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the instance URL
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+		"google_search_images",
+		"google_search_videos",
+		"google_search_scholar",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. SearXNG has no
+// location parameter; the number of results is applied by truncation.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// Extra declares the SearXNG-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Engines string `extra:"engines" description:"Comma-separated SearXNG engines to query instead of those of the category"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// response is the JSON response of the SearXNG search endpoint
+type response struct {
+	NumberOfResults float64   `json:"number_of_results"`
+	Results         []result  `json:"results"`
+	Answers         []any     `json:"answers"`
+	Infoboxes       []infobox `json:"infoboxes"`
+	Suggestions     []string  `json:"suggestions"`
+	Corrections     []string  `json:"corrections"`
+}
+
+// result is one SearXNG result; the fields used depend on the category
+type result struct {
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	Engine        string   `json:"engine"`
+	PublishedDate string   `json:"publishedDate"`
+	ImgSrc        string   `json:"img_src"`
+	ThumbnailSrc  string   `json:"thumbnail_src"`
+	Thumbnail     string   `json:"thumbnail"`
+	Resolution    string   `json:"resolution"`
+	Length        any      `json:"length"`
+	Author        string   `json:"author"`
+	Authors       []string `json:"authors"`
+	Journal       string   `json:"journal"`
+	PDFURL        string   `json:"pdf_url"`
+}
+
+// infobox is a SearXNG knowledge panel
+type infobox struct {
+	Infobox string `json:"infobox"`
+	Content string `json:"content"`
+	Engine  string `json:"engine"`
+	ImgSrc  string `json:"img_src"`
+}
+
+// buildParams converts SearchParams to SearXNG query parameters
+func (e *Engine) buildParams(params omniserp.SearchParams, category string) url.Values {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	q.Set("format", "json")
+	q.Set("categories", category)
+	if params.Language != "" {
+		q.Set("language", params.Language)
+		if params.Country != "" {
+			q.Set("language", params.Language+"-"+strings.ToUpper(params.Country))
+		}
+	}
+	if params.Page > 1 {
+		q.Set("pageno", strconv.Itoa(params.Page))
+	}
+	// SearXNG has no hourly time range
+	switch params.Freshness {
+	case omniserp.FreshnessDay, omniserp.FreshnessWeek, omniserp.FreshnessMonth, omniserp.FreshnessYear:
+		q.Set("time_range", string(params.Freshness))
+	case omniserp.FreshnessHour:
+		q.Set("time_range", string(omniserp.FreshnessDay))
+	}
+	if params.SafeSearch {
+		q.Set("safesearch", "1")
+	}
+	for name, value := range omniserp.ExtraQuery(params, e.ExtraParams()) {
+		q.Set(name, value)
+	}
+	return q
+}
+
+// search queries one category and normalizes the response
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams, category string) (*omniserp.SearchResult, error) {
+	reqURL := e.baseURL + searchPath + "?" + e.buildParams(params, category).Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to the configured SearXNG instance
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		// A 403 usually means the JSON format is not enabled
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &omniserp.SearchResult{
+		Data:     normalize(&parsed, params, category),
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// normalize converts a SearXNG response of a category to a normalized result
+func normalize(resp *response, params omniserp.SearchParams, category string) *omniserp.NormalizedSearchResult {
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Language:     params.Language,
+			Country:      params.Country,
+			TotalResults: int64(resp.NumberOfResults),
+		},
+	}
+
+	results := resp.Results
+	if params.NumResults > 0 && len(results) > params.NumResults {
+		results = results[:params.NumResults]
+	}
+
+	for i, r := range results {
+		position := i + 1
+		switch category {
+		case categoryNews:
+			normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+				Position:  position,
+				Title:     r.Title,
+				Link:      r.URL,
+				Source:    r.Engine,
+				Date:      r.PublishedDate,
+				Snippet:   r.Content,
+				ImageURL:  r.ImgSrc,
+				Thumbnail: r.Thumbnail,
+			})
+		case categoryImages:
+			image := omniserp.ImageResult{
+				Position:  position,
+				Title:     r.Title,
+				ImageURL:  r.ImgSrc,
+				Thumbnail: r.ThumbnailSrc,
+				Source:    r.Engine,
+				SourceURL: r.URL,
+			}
+			image.Width, image.Height = parseResolution(r.Resolution)
+			normalized.ImageResults = append(normalized.ImageResults, image)
+		case categoryVideos:
+			normalized.VideoResults = append(normalized.VideoResults, omniserp.VideoResult{
+				Position:  position,
+				Title:     r.Title,
+				Link:      r.URL,
+				Channel:   r.Author,
+				Platform:  r.Engine,
+				Duration:  formatLength(r.Length),
+				Date:      r.PublishedDate,
+				Thumbnail: r.Thumbnail,
+				Snippet:   r.Content,
+			})
+		case categoryScience:
+			scholar := omniserp.ScholarResult{
+				Position: position,
+				Title:    r.Title,
+				Link:     r.URL,
+				Authors:  r.Authors,
+				Source:   r.Journal,
+				Snippet:  r.Content,
+				PDF:      r.PDFURL,
+			}
+			if len(r.PublishedDate) >= 4 {
+				scholar.Year = r.PublishedDate[:4]
+			}
+			normalized.ScholarResults = append(normalized.ScholarResults, scholar)
+		default:
+			normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+				Position: position,
+				Title:    r.Title,
+				Link:     r.URL,
+				URL:      r.URL,
+				Snippet:  r.Content,
+				Date:     r.PublishedDate,
+			})
+		}
+	}
+
+	if category == categoryGeneral {
+		if answer := answerText(resp.Answers); answer != "" {
+			normalized.AnswerBox = &omniserp.AnswerBox{Answer: answer}
+		}
+		if len(resp.Infoboxes) > 0 {
+			box := resp.Infoboxes[0]
+			normalized.KnowledgeGraph = &omniserp.KnowledgeGraph{
+				Title:       box.Infobox,
+				Description: box.Content,
+				Source:      box.Engine,
+				ImageURL:    box.ImgSrc,
+			}
+		}
+		for _, suggestion := range resp.Suggestions {
+			normalized.RelatedSearches = append(normalized.RelatedSearches, omniserp.RelatedSearch{Query: suggestion})
+		}
+		// SearXNG does not correct queries, it only suggests corrections
+		if len(resp.Corrections) > 0 {
+			normalized.SearchMetadata.SuggestedQuery = resp.Corrections[0]
+		}
+	}
+
+	return normalized
+}
+
+// answerText returns the first answer, which is a string in older SearXNG
+// versions and an object with an "answer" field in newer ones
+func answerText(answers []any) string {
+	for _, answer := range answers {
+		switch v := answer.(type) {
+		case string:
+			return v
+		case map[string]any:
+			if text, ok := v["answer"].(string); ok {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// parseResolution parses an image resolution such as "1920 x 1080"
+func parseResolution(s string) (int, int) {
+	w, h, ok := strings.Cut(strings.ReplaceAll(s, "×", "x"), "x")
+	if !ok {
+		return 0, 0
+	}
+	width, _ := strconv.Atoi(strings.TrimSpace(w))
+	height, _ := strconv.Atoi(strings.TrimSpace(h))
+	return width, height
+}
+
+// formatLength formats a video length, which is a string like "3:45" or a
+// number of seconds
+func formatLength(v any) string {
+	switch length := v.(type) {
+	case string:
+		return length
+	case float64:
+		return (time.Duration(length) * time.Second).String()
+	}
+	return ""
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, categoryGeneral)
+}
+
+// SearchNews performs a news search
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, categoryNews)
+}
+
+// SearchImages performs an image search. Size and aspect ratio filters are
+// enforced by the client; transparency and license filters cannot be
+// honored and are rejected.
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if params.Image != nil && (params.Image.Transparent || params.Image.License != "") {
+		return nil, fmt.Errorf("image transparency and license filters are not supported by SearXNG")
+	}
+	return e.search(ctx, params, categoryImages)
+}
+
+// SearchVideos performs a video search
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, categoryVideos)
+}
+
+// SearchPlaces performs a places search (not supported by SearXNG)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by SearXNG")
+}
+
+// SearchMaps performs a maps search (not supported by SearXNG)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by SearXNG")
+}
+
+// SearchReviews performs a reviews search (not supported by SearXNG)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by SearXNG")
+}
+
+// SearchShopping performs a shopping search (not supported by SearXNG)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by SearXNG")
+}
+
+// SearchScholar performs a scholar search in the SearXNG science category
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return e.search(ctx, params, categoryScience)
+}
+
+// SearchLens performs a visual search (not supported by SearXNG)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by SearXNG")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by SearXNG)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by SearXNG")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by SearXNG)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by SearXNG")
+}
@@ -0,0 +1,126 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestSearch(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != searchPath {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	engine, err := NewWithURL(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("NewWithURL failed: %v", err)
+	}
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Language:   "en",
+		Country:    "us",
+		NumResults: 2,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Extra:      map[string]any{"engines": "duckduckgo,wikipedia", "device": "mobile"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"q":          "golang",
+		"format":     "json",
+		"categories": "general",
+		"language":   "en-US",
+		"pageno":     "2",
+		"time_range": "week",
+		"safesearch": "1",
+		"engines":    "duckduckgo,wikipedia",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+	if query.Has("device") {
+		t.Error("Expected undeclared extra parameters to be dropped")
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected NumResults to limit the results to 2, got %d", len(normalized.OrganicResults))
+	}
+	first := normalized.OrganicResults[0]
+	if first.Link != "https://go.dev/" || first.Position != 1 || first.Engine != "searxng" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if normalized.SearchMetadata.TotalResults != 1250 || normalized.SearchMetadata.Engine != "searxng" {
+		t.Errorf("Unexpected metadata: %+v", normalized.SearchMetadata)
+	}
+	if normalized.AnswerBox == nil || normalized.AnswerBox.Answer != "Go was designed at Google." {
+		t.Errorf("Unexpected answer box: %+v", normalized.AnswerBox)
+	}
+	if normalized.KnowledgeGraph == nil || normalized.KnowledgeGraph.Title != "Go" {
+		t.Errorf("Unexpected knowledge graph: %+v", normalized.KnowledgeGraph)
+	}
+	if len(normalized.RelatedSearches) != 2 {
+		t.Errorf("Expected 2 related searches, got %+v", normalized.RelatedSearches)
+	}
+	if normalized.SearchMetadata.SuggestedQuery != "golang" {
+		t.Errorf("Expected the suggested query golang, got %q", normalized.SearchMetadata.SuggestedQuery)
+	}
+	if normalized.Raw == nil || normalized.Raw.Data != nil || normalized.Raw.Raw == "" {
+		t.Errorf("Expected the raw response without the normalized data, got %+v", normalized.Raw)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	engine, _ := NewWithURL(srv.URL)
+	_, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a 403 APIError, got %v", err)
+	}
+}
+
+func TestSearchImagesUnsupportedFilter(t *testing.T) {
+	engine, _ := NewWithURL("http://127.0.0.1:1")
+	_, err := engine.SearchImages(context.Background(), omniserp.SearchParams{
+		Query: "gopher",
+		Image: &omniserp.ImageFilter{License: omniserp.LicenseCreativeCommons},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected the license filter to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/plexusone/omniserp"
+)
+
+// SelectionPolicy picks the engine that serves a request when several
+// registered engines support the operation. With a selection policy the
+// current engine is only a default; each request may be served by any
+// engine that supports it. Implementations must be safe for concurrent use.
+type SelectionPolicy interface {
+	Select(req SelectionRequest) omniserp.Engine
+}
+
+// SelectionRequest describes the request an engine is selected for
+type SelectionRequest struct {
+	// Operation is the requested operation (e.g., OpSearch)
+	Operation string
+
+	// Engines are the engines that support the operation, current engine
+	// first if it supports the operation. It is never empty.
+	Engines []omniserp.Engine
+
+	// Stats are the rolling stats of Engines, keyed by engine name
+	Stats map[string]EngineStats
+}
+
+// CheapestPolicy selects the engine with the lowest cost per request.
+// Engines without a configured cost are only selected if no engine has one.
+type CheapestPolicy struct {
+	Costs map[string]float64
+}
+
+// NewCheapestPolicy creates a policy from per-request costs keyed by engine name
+func NewCheapestPolicy(costs map[string]float64) *CheapestPolicy {
+	return &CheapestPolicy{Costs: costs}
+}
+
+// Select implements SelectionPolicy
+func (p *CheapestPolicy) Select(req SelectionRequest) omniserp.Engine {
+	best, bestCost := req.Engines[0], math.Inf(1)
+	for _, engine := range req.Engines {
+		if cost, ok := p.Costs[engine.GetName()]; ok && cost < bestCost {
+			best, bestCost = engine, cost
+		}
+	}
+	return best
+}
+
+// FastestPolicy selects the engine with the lowest rolling p50 latency.
+// Engines that have not served a request yet are selected first so every
+// engine gets measured.
+type FastestPolicy struct{}
+
+// NewFastestPolicy creates a latency-based policy
+func NewFastestPolicy() *FastestPolicy {
+	return &FastestPolicy{}
+}
+
+// Select implements SelectionPolicy
+func (p *FastestPolicy) Select(req SelectionRequest) omniserp.Engine {
+	var best omniserp.Engine
+	var bestStats EngineStats
+	for _, engine := range req.Engines {
+		stats := req.Stats[engine.GetName()]
+		if stats.Requests == 0 {
+			return engine
+		}
+		if best == nil || stats.P50 < bestStats.P50 {
+			best, bestStats = engine, stats
+		}
+	}
+	return best
+}
+
+// RoundRobinPolicy rotates through the engines that support each request
+type RoundRobinPolicy struct {
+	next atomic.Uint64
+}
+
+// NewRoundRobinPolicy creates a round-robin policy
+func NewRoundRobinPolicy() *RoundRobinPolicy {
+	return &RoundRobinPolicy{}
+}
+
+// Select implements SelectionPolicy
+func (p *RoundRobinPolicy) Select(req SelectionRequest) omniserp.Engine {
+	n := p.next.Add(1) - 1
+	return req.Engines[n%uint64(len(req.Engines))]
+}
+
+// WeightedPolicy selects engines at random in proportion to their weights.
+// Engines without a weight are not selected unless no engine has one.
+type WeightedPolicy struct {
+	Weights map[string]int
+}
+
+// NewWeightedPolicy creates a policy from weights keyed by engine name
+func NewWeightedPolicy(weights map[string]int) *WeightedPolicy {
+	return &WeightedPolicy{Weights: weights}
+}
+
+// Select implements SelectionPolicy
+func (p *WeightedPolicy) Select(req SelectionRequest) omniserp.Engine {
+	total := 0
+	for _, engine := range req.Engines {
+		total += max(p.Weights[engine.GetName()], 0)
+	}
+	if total == 0 {
+		return req.Engines[0]
+	}
+
+	// #nosec G404 -- load distribution does not need a secure random source
+	n := rand.IntN(total)
+	for _, engine := range req.Engines {
+		if n -= max(p.Weights[engine.GetName()], 0); n < 0 {
+			return engine
+		}
+	}
+	return req.Engines[0]
+}
+
+// StickyPolicy keeps using the same engine for each operation, so all
+// requests of one query type are served consistently. The first engine per
+// operation is chosen by Base (default: the current engine), and a new one
+// is chosen if the sticky engine stops supporting the operation.
+type StickyPolicy struct {
+	Base SelectionPolicy
+
+	mu     sync.Mutex
+	chosen map[string]string
+}
+
+// NewStickyPolicy creates a sticky policy that chooses engines with base,
+// which may be nil
+func NewStickyPolicy(base SelectionPolicy) *StickyPolicy {
+	return &StickyPolicy{Base: base, chosen: make(map[string]string)}
+}
+
+// Select implements SelectionPolicy
+func (p *StickyPolicy) Select(req SelectionRequest) omniserp.Engine {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if name, ok := p.chosen[req.Operation]; ok {
+		for _, engine := range req.Engines {
+			if engine.GetName() == name {
+				return engine
+			}
+		}
+	}
+
+	engine := req.Engines[0]
+	if p.Base != nil {
+		if selected := p.Base.Select(req); selected != nil {
+			engine = selected
+		}
+	}
+	if p.chosen == nil {
+		p.chosen = make(map[string]string)
+	}
+	p.chosen[req.Operation] = engine.GetName()
+	return engine
+}
+
+// SetSelectionPolicy enables per-request engine selection with the given
+// policy, or restores the single current engine if nil
+func (c *Client) SetSelectionPolicy(policy SelectionPolicy) {
+	c.mu.Lock()
+	c.selection = policy
+	c.mu.Unlock()
+}
+
+// selectionPolicy returns the selection policy, or nil
+func (c *Client) selectionPolicy() SelectionPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.selection
+}
+
+// specialEngines are the keyless and special-purpose engines. They serve few
+// operations or only their own content, so they are not chosen for other
+// engines' requests unless opted in with Options.PolicyEngines.
+var specialEngines = map[string]bool{
+	"duckduckgo":    true,
+	"wikipedia":     true,
+	"elasticsearch": true,
+}
+
+// selectable reports whether an engine may be chosen by selection and
+// failover: engines that are not special, opted in, or the engine of a route
+func (c *Client) selectable(policy SelectionPolicy, name string) bool {
+	if !specialEngines[name] || c.optIn[name] {
+		return true
+	}
+	routing, ok := policy.(*RoutingPolicy)
+	return ok && slices.Contains(slices.Collect(maps.Values(routing.Routes)), name)
+}
+
+// selectEngine moves the engine chosen by policy to the front of engines
+func (c *Client) selectEngine(policy SelectionPolicy, operation string, engines []omniserp.Engine) []omniserp.Engine {
+	stats := make(map[string]EngineStats, len(engines))
+	for _, engine := range engines {
+		stats[engine.GetName()] = c.stats.get(engine.GetName())
+	}
+
+	chosen := policy.Select(SelectionRequest{Operation: operation, Engines: engines, Stats: stats})
+	for i, engine := range engines {
+		if chosen != nil && engine.GetName() == chosen.GetName() {
+			ordered := append([]omniserp.Engine{engine}, engines[:i]...)
+			return append(ordered, engines[i+1:]...)
+		}
+	}
+	return engines
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newSelectionClient creates a client with fake engines that report their
+// name as the only organic result
+func newSelectionClient(t *testing.T, names ...string) *Client {
+	t.Helper()
+	registry := omniserp.NewRegistry()
+	for _, name := range names {
+		registry.Register(&fakeEngine{name: name, tools: AllOperations(), search: func(omniserp.SearchParams) (*omniserp.SearchResult, error) {
+			return organicResponse(name), nil
+		}})
+	}
+	c, err := NewWithRegistry(registry, names[0])
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	return c
+}
+
+// servedBy runs a search and returns the name of the engine that served it
+func servedBy(t *testing.T, c *Client) string {
+	t.Helper()
+	result, err := c.Search(context.Background(), omniserp.SearchParams{Query: "q"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return firstLink(result)
+}
+
+// firstLink returns the link of the first organic result
+func firstLink(result *omniserp.SearchResult) string {
+	organic := result.Data.(map[string]any)["organic"].([]any)
+	return organic[0].(map[string]any)["link"].(string)
+}
+
+func TestSelectionPolicies(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi", "brave")
+
+	c.SetSelectionPolicy(NewCheapestPolicy(map[string]float64{"serper": 1, "serpapi": 0.5}))
+	if got := servedBy(t, c); got != "serpapi" {
+		t.Errorf("Cheapest: expected serpapi, got %s", got)
+	}
+
+	c.SetSelectionPolicy(NewRoundRobinPolicy())
+	seen := map[string]int{}
+	for range 6 {
+		seen[servedBy(t, c)]++
+	}
+	if len(seen) != 3 || seen["serper"] != 2 {
+		t.Errorf("RoundRobin: expected even rotation, got %v", seen)
+	}
+
+	c.SetSelectionPolicy(NewWeightedPolicy(map[string]int{"brave": 1}))
+	for range 5 {
+		if got := servedBy(t, c); got != "brave" {
+			t.Fatalf("Weighted: expected brave, got %s", got)
+		}
+	}
+
+	c.SetSelectionPolicy(nil)
+	if got := servedBy(t, c); got != "serper" {
+		t.Errorf("No policy: expected current engine, got %s", got)
+	}
+}
+
+func TestFastestPolicy(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi")
+	c.stats.record("serper", 300*time.Millisecond, false)
+	c.stats.record("serpapi", 100*time.Millisecond, false)
+
+	c.SetSelectionPolicy(NewFastestPolicy())
+	if got := servedBy(t, c); got != "serpapi" {
+		t.Errorf("Expected fastest engine serpapi, got %s", got)
+	}
+}
+
+func TestStickyPolicy(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi")
+	c.SetSelectionPolicy(NewStickyPolicy(NewRoundRobinPolicy()))
+
+	first := servedBy(t, c)
+	for range 3 {
+		if got := servedBy(t, c); got != first {
+			t.Fatalf("Expected search to stick to %s, got %s", first, got)
+		}
+	}
+
+	// Another operation gets its own selection from the round-robin base
+	result, err := c.SearchNews(context.Background(), omniserp.SearchParams{Query: "q"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if got := firstLink(result); got == first {
+		t.Errorf("Expected news to be assigned the next engine, got %s", got)
+	}
+}
+
+func TestSelectionSkipsUnsupportedEngines(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: []string{OpSearch}})
+	registry.Register(&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("serpapi"), nil
+	}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	if _, err := c.SearchScholar(context.Background(), omniserp.SearchParams{Query: "q"}); err == nil {
+		t.Fatal("Expected unsupported operation error without a selection policy")
+	}
+
+	c.SetSelectionPolicy(NewRoundRobinPolicy())
+	result, err := c.SearchScholar(context.Background(), omniserp.SearchParams{Query: "q"})
+	if err != nil {
+		t.Fatalf("SearchScholar failed: %v", err)
+	}
+	if got := firstLink(result); got != "serpapi" {
+		t.Errorf("Expected the only supporting engine, got %s", got)
+	}
+}
+
+func TestContextEngine(t *testing.T) {
+	c := newSelectionClient(t, "serper", "serpapi", "brave")
+	c.SetSelectionPolicy(NewWeightedPolicy(map[string]int{"brave": 1}))
+	ctx := ContextWithEngine(context.Background(), "serpapi")
+
+	result, err := c.Search(ctx, omniserp.SearchParams{Query: "q"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := firstLink(result); got != "serpapi" {
+		t.Errorf("Expected the preferred engine to bypass the selection policy, got %s", got)
+	}
+	if got := servedBy(t, c); got != "brave" {
+		t.Errorf("Expected the selection policy without a preference, got %s", got)
+	}
+
+	if _, err := c.Search(ContextWithEngine(context.Background(), "bing"), omniserp.SearchParams{Query: "q"}); err == nil {
+		t.Error("Expected an error for an unknown preferred engine")
+	}
+
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations()})
+	registry.Register(&fakeEngine{name: "serpapi", tools: []string{OpSearch}})
+	c, err = NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	if _, err := c.SearchScholar(ctx, omniserp.SearchParams{Query: "q"}); !errors.Is(err, ErrOperationNotSupported) {
+		t.Errorf("Expected ErrOperationNotSupported from the preferred engine, got %v", err)
+	}
+}
+
+func TestSelectionSkipsSpecialEngines(t *testing.T) {
+	c := newSelectionClient(t, "serper", "duckduckgo", "wikipedia")
+	c.SetSelectionPolicy(NewRoundRobinPolicy())
+	for range 4 {
+		if got := servedBy(t, c); got != "serper" {
+			t.Fatalf("Expected keyless engines to be skipped, got %s", got)
+		}
+	}
+
+	c.optIn = map[string]bool{"duckduckgo": true}
+	seen := map[string]bool{}
+	for range 4 {
+		seen[servedBy(t, c)] = true
+	}
+	if !seen["serper"] || !seen["duckduckgo"] || seen["wikipedia"] {
+		t.Errorf("Expected serper and the opted in duckduckgo, got %v", seen)
+	}
+
+	// A route is an explicit choice of the engine
+	if err := c.SetRoutes(map[string]string{"search": "wikipedia"}); err != nil {
+		t.Fatalf("SetRoutes failed: %v", err)
+	}
+	if got := servedBy(t, c); got != "wikipedia" {
+		t.Errorf("Expected the routed wikipedia engine, got %s", got)
+	}
+
+	// The current engine serves requests even if it is special
+	c = newSelectionClient(t, "duckduckgo", "serper")
+	c.SetSelectionPolicy(NewStickyPolicy(nil))
+	if got := servedBy(t, c); got != "duckduckgo" {
+		t.Errorf("Expected the current duckduckgo engine, got %s", got)
+	}
+}
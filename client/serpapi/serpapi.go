@@ -1,3 +1,7 @@
+// Package serpapi implements the omniserp.Engine interface for SerpAPI.
+// The default engine searches Google; engines of the Bing, Yandex, and Baidu
+// families search those engines through SerpAPI and are named
+// "serpapi-<family>".
 package serpapi
 
 import (
@@ -5,9 +9,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/plexusone/omniserp"
 )
@@ -16,13 +25,59 @@ const (
 	baseURL       = "https://serpapi.com"
 	engineName    = "serpapi"
 	engineVersion = "1.0.0"
-	searchURL     = "https://serpapi.com/search.json"
+	searchPath    = "/search.json"
+	accountPath   = "/account.json"
+
+	// scrapeLimit is the largest scraped page read
+	scrapeLimit = 10 << 20
+)
+
+// Family is a family of search engines scraped by SerpAPI
+type Family string
+
+const (
+	FamilyGoogle Family = "google"
+	FamilyBing   Family = "bing"
+	FamilyYandex Family = "yandex"
+	FamilyBaidu  Family = "baidu"
 )
 
+// Families are the non-Google families, which the default client registers
+// alongside the Google engine. The Baidu family backs the baidu engine,
+// which normalizes its results, and is not registered on its own.
+var Families = []Family{FamilyBing, FamilyYandex}
+
+// familyEngines maps the operations of each non-Google family to the
+// SerpAPI engine serving them
+var familyEngines = map[Family]map[string]string{
+	FamilyBing: {
+		"google_search":          "bing",
+		"google_search_news":     "bing_news",
+		"google_search_images":   "bing_images",
+		"google_search_videos":   "bing_videos",
+		"google_search_shopping": "bing_shopping",
+	},
+	FamilyYandex: {
+		"google_search":        "yandex",
+		"google_search_images": "yandex_images",
+		"google_search_videos": "yandex_videos",
+	},
+	FamilyBaidu: {
+		"google_search":      "baidu",
+		"google_search_news": "baidu_news",
+	},
+}
+
 // Engine implements the omniserp.Engine interface for SerpAPI
 type Engine struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	family  Family
+	client  *http.Client
+
+	// scraper fetches scraped pages. It is not the signed API client, so
+	// signatures and client certificates never reach the scraped hosts.
+	scraper *http.Client
 }
 
 // New creates a new SerpAPI engine instance
@@ -33,26 +88,80 @@ func New() (*Engine, error) {
 	}
 
 	return &Engine{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		family:  FamilyGoogle,
+		client:  &http.Client{},
+		scraper: omniserp.NewPublicClient(),
 	}, nil
 }
 
 // NewWithAPIKey creates a new SerpAPI engine instance with the provided API key
 func NewWithAPIKey(apiKey string) (*Engine, error) {
+	return NewFamilyWithAPIKey(apiKey, FamilyGoogle)
+}
+
+// NewFamily creates a SerpAPI engine for a family of search engines from the
+// SERPAPI_API_KEY env var
+func NewFamily(family Family) (*Engine, error) {
+	apiKey := os.Getenv("SERPAPI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("SERPAPI_API_KEY environment variable is required")
+	}
+	return NewFamilyWithAPIKey(apiKey, family)
+}
+
+// NewFamilyWithAPIKey creates a SerpAPI engine for a family of search
+// engines with the provided API key
+func NewFamilyWithAPIKey(apiKey string, family Family) (*Engine, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
+	if _, ok := familyEngines[family]; !ok && family != FamilyGoogle {
+		return nil, fmt.Errorf("unsupported SerpAPI engine family: %s", family)
+	}
 
 	return &Engine{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		family:  family,
+		client:  &http.Client{},
+		scraper: omniserp.NewPublicClient(),
 	}, nil
 }
 
-// GetName returns the engine name
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name: "serpapi" for Google and
+// "serpapi-<family>" for the other families
 func (e *Engine) GetName() string {
-	return engineName
+	if e.family == FamilyGoogle {
+		return engineName
+	}
+	return engineName + "-" + string(e.family)
+}
+
+// Family returns the family of search engines of the engine
+func (e *Engine) Family() Family {
+	return e.family
 }
 
 // GetVersion returns the engine version
@@ -62,6 +171,9 @@ func (e *Engine) GetVersion() string {
 
 // GetSupportedTools returns the list of supported tools
 func (e *Engine) GetSupportedTools() []string {
+	if engines, ok := familyEngines[e.family]; ok {
+		return slices.Sorted(maps.Keys(engines))
+	}
 	return []string{
 		"google_search",
 		"google_search_news",
@@ -78,10 +190,55 @@ func (e *Engine) GetSupportedTools() []string {
 	}
 }
 
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	switch e.family {
+	case FamilyBing:
+		return []string{omniserp.ParamQuery, omniserp.ParamLocation, omniserp.ParamLanguage, omniserp.ParamCountry, omniserp.ParamNumResults, omniserp.ParamPage, omniserp.ParamSafeSearch}
+	case FamilyYandex:
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamPage}
+	case FamilyBaidu:
+		return []string{omniserp.ParamQuery, omniserp.ParamNumResults, omniserp.ParamPage}
+	}
+	switch operation {
+	case "google_search_scholar":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamNumResults, omniserp.ParamCites}
+	case "google_search_autocomplete":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamCountry}
+	case "webpage_scrape":
+		return nil
+	}
+	return omniserp.AllParams()
+}
+
+// Extra declares the SerpAPI-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Device       string `extra:"device" description:"Device to emulate: desktop, tablet, or mobile"`
+	GoogleDomain string `extra:"google_domain" description:"Google domain to search, such as google.co.uk"`
+	NoCache      bool   `extra:"no_cache" description:"Fetch fresh results instead of cached ones"`
+	Filter       int    `extra:"filter" description:"Set to 0 to include similar and omitted results"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter. Engines of the
+// non-Google families only accept no_cache.
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	params := omniserp.DescribeExtraParams(Extra{})
+	if e.family != FamilyGoogle {
+		params = slices.DeleteFunc(params, func(p omniserp.ExtraParam) bool { return p.Name != "no_cache" })
+	}
+	return params
+}
+
 // makeRequest performs HTTP request to SerpAPI
-func (e *Engine) makeRequest(params map[string]string) (*omniserp.SearchResult, error) {
+func (e *Engine) makeRequest(ctx context.Context, params map[string]string) (*omniserp.SearchResult, error) {
+	return e.get(ctx, searchPath, params)
+}
+
+// get performs a GET request against a SerpAPI endpoint
+func (e *Engine) get(ctx context.Context, path string, params map[string]string) (*omniserp.SearchResult, error) {
 	// Build URL with query parameters
-	reqURL, err := url.Parse(searchURL)
+	reqURL, err := url.Parse(e.baseURL + path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
@@ -94,12 +251,13 @@ func (e *Engine) makeRequest(params map[string]string) (*omniserp.SearchResult,
 	}
 	reqURL.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, reqURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// #nosec G704 -- request to hardcoded SerpAPI endpoint
+	start := time.Now()
+	// #nosec G704 -- request to the SerpAPI endpoint or a configured proxy
 	resp, err := e.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -110,9 +268,10 @@ func (e *Engine) makeRequest(params map[string]string) (*omniserp.SearchResult,
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: %s", string(body))
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
 	}
 
 	var result map[string]any
@@ -121,8 +280,32 @@ func (e *Engine) makeRequest(params map[string]string) (*omniserp.SearchResult,
 	}
 
 	return &omniserp.SearchResult{
-		Data: result,
-		Raw:  string(body),
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// Credits returns the remaining searches of the SerpAPI account.
+// Account requests are free and do not count against the quota.
+func (e *Engine) Credits(ctx context.Context) (*omniserp.Credits, error) {
+	result, err := e.get(ctx, accountPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var account struct {
+		TotalSearchesLeft int `json:"total_searches_left"`
+		SearchesPerMonth  int `json:"searches_per_month"`
+	}
+	if err := json.Unmarshal([]byte(result.Raw), &account); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal account: %w", err)
+	}
+
+	return &omniserp.Credits{
+		Engine:    engineName,
+		Remaining: account.TotalSearchesLeft,
+		Limit:     account.SearchesPerMonth,
 	}, nil
 }
 
@@ -142,61 +325,185 @@ func (e *Engine) buildParams(params omniserp.SearchParams, engine string) map[st
 	if params.Country != "" {
 		apiParams["gl"] = params.Country
 	}
+	if params.GoogleDomain != "" {
+		apiParams["google_domain"] = params.GoogleDomain
+	}
+	if tbs := params.Freshness.TBS(); tbs != "" {
+		apiParams["tbs"] = tbs
+	}
+	if params.SafeSearch {
+		apiParams["safe"] = "active"
+	}
+	if params.Verbatim {
+		apiParams["nfpr"] = "1"
+	}
 	if params.NumResults > 0 {
 		apiParams["num"] = fmt.Sprintf("%d", params.NumResults)
 	}
+	if params.Page > 1 {
+		perPage := params.NumResults
+		if perPage <= 0 {
+			perPage = 10
+		}
+		apiParams["start"] = fmt.Sprintf("%d", (params.Page-1)*perPage)
+	}
+	maps.Copy(apiParams, omniserp.ExtraQuery(params, e.ExtraParams()))
 
 	return apiParams
 }
 
+// buildFamilyParams converts SearchParams to the parameters of a SerpAPI
+// engine of a non-Google family. Yandex takes the query as "text" and a
+// 0-based page; Bing and Baidu take a result offset.
+func (e *Engine) buildFamilyParams(params omniserp.SearchParams, engine string) map[string]string {
+	apiParams := map[string]string{"engine": engine}
+
+	switch e.family {
+	case FamilyBing:
+		apiParams["q"] = params.Query
+		if params.Location != "" {
+			apiParams["location"] = params.Location
+		}
+		if params.Country != "" {
+			apiParams["cc"] = strings.ToUpper(params.Country)
+			if params.Language != "" {
+				apiParams["mkt"] = strings.ToLower(params.Language) + "-" + strings.ToUpper(params.Country)
+			}
+		}
+		if params.SafeSearch {
+			apiParams["safeSearch"] = "Strict"
+		}
+		perPage := params.NumResults
+		if perPage > 0 {
+			apiParams["count"] = strconv.Itoa(perPage)
+		} else {
+			perPage = 10
+		}
+		if params.Page > 1 {
+			// first is the 1-based index of the first result
+			apiParams["first"] = strconv.Itoa((params.Page-1)*perPage + 1)
+		}
+	case FamilyYandex:
+		apiParams["text"] = params.Query
+		if params.Language != "" {
+			apiParams["lang"] = strings.ToLower(params.Language)
+		}
+		if params.Page > 1 {
+			apiParams["p"] = strconv.Itoa(params.Page - 1)
+		}
+	case FamilyBaidu:
+		apiParams["q"] = params.Query
+		perPage := params.NumResults
+		if perPage > 0 {
+			apiParams["rn"] = strconv.Itoa(perPage)
+		} else {
+			perPage = 10
+		}
+		if params.Page > 1 {
+			apiParams["pn"] = strconv.Itoa((params.Page - 1) * perPage)
+		}
+	}
+	maps.Copy(apiParams, omniserp.ExtraQuery(params, e.ExtraParams()))
+
+	return apiParams
+}
+
+// searchFamily performs an operation on the SerpAPI engine of a non-Google
+// family. ok is false for the Google family.
+func (e *Engine) searchFamily(ctx context.Context, operation string, params omniserp.SearchParams) (result *omniserp.SearchResult, ok bool, err error) {
+	engines, ok := familyEngines[e.family]
+	if !ok {
+		return nil, false, nil
+	}
+	engine, supported := engines[operation]
+	if !supported {
+		return nil, true, fmt.Errorf("%s is not supported by SerpAPI %s", operation, e.family)
+	}
+	result, err = e.makeRequest(ctx, e.buildFamilyParams(params, engine))
+	return result, true, err
+}
+
 // Search performs a general web search
 func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google"))
+	if result, ok, err := e.searchFamily(ctx, "google_search", params); ok {
+		return result, err
+	}
+	return e.makeRequest(ctx, e.buildParams(params, "google"))
 }
 
 // SearchNews performs a news search
 func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google_news"))
+	if result, ok, err := e.searchFamily(ctx, "google_search_news", params); ok {
+		return result, err
+	}
+	return e.makeRequest(ctx, e.buildParams(params, "google_news"))
 }
 
-// SearchImages performs an image search
+// SearchImages performs an image search. Transparency, aspect ratio, and
+// license filters are sent as "tbs"; the minimum size is enforced by the
+// client.
 func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google_images"))
+	if result, ok, err := e.searchFamily(ctx, "google_search_images", params); ok {
+		return result, err
+	}
+	apiParams := e.buildParams(params, "google_images")
+	if tbs := params.ImageTBS(); tbs != "" {
+		apiParams["tbs"] = tbs
+	}
+	return e.makeRequest(ctx, apiParams)
 }
 
 // SearchVideos performs a video search
 func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google_videos"))
+	if result, ok, err := e.searchFamily(ctx, "google_search_videos", params); ok {
+		return result, err
+	}
+	return e.makeRequest(ctx, e.buildParams(params, "google_videos"))
 }
 
 // SearchPlaces performs a places search
 func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if result, ok, err := e.searchFamily(ctx, "google_search_places", params); ok {
+		return result, err
+	}
 	// For places, we use Google Maps search with type parameter
 	apiParams := e.buildParams(params, "google_maps")
 	apiParams["type"] = "search"
-	return e.makeRequest(apiParams)
+	return e.makeRequest(ctx, apiParams)
 }
 
 // SearchMaps performs a maps search
 func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google_maps"))
+	if result, ok, err := e.searchFamily(ctx, "google_search_maps", params); ok {
+		return result, err
+	}
+	return e.makeRequest(ctx, e.buildParams(params, "google_maps"))
 }
 
 // SearchReviews performs a reviews search
 func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if result, ok, err := e.searchFamily(ctx, "google_search_reviews", params); ok {
+		return result, err
+	}
 	// Reviews can be searched through Google with specific query modification
 	apiParams := e.buildParams(params, "google")
 	apiParams["q"] = params.Query + " reviews"
-	return e.makeRequest(apiParams)
+	return e.makeRequest(ctx, apiParams)
 }
 
 // SearchShopping performs a shopping search
 func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest(e.buildParams(params, "google_shopping"))
+	if result, ok, err := e.searchFamily(ctx, "google_search_shopping", params); ok {
+		return result, err
+	}
+	return e.makeRequest(ctx, e.buildParams(params, "google_shopping"))
 }
 
 // SearchScholar performs a scholar search
 func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if result, ok, err := e.searchFamily(ctx, "google_search_scholar", params); ok {
+		return result, err
+	}
 	apiParams := map[string]string{
 		"q":      params.Query,
 		"engine": "google_scholar",
@@ -208,8 +515,16 @@ func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams
 	if params.NumResults > 0 {
 		apiParams["num"] = fmt.Sprintf("%d", params.NumResults)
 	}
+	if params.Cites != "" {
+		// The query searches within the citing papers and may be empty
+		apiParams["cites"] = params.Cites
+		if params.Query == "" {
+			delete(apiParams, "q")
+		}
+	}
+	maps.Copy(apiParams, omniserp.ExtraQuery(params, e.ExtraParams()))
 
-	return e.makeRequest(apiParams)
+	return e.makeRequest(ctx, apiParams)
 }
 
 // SearchLens performs a visual search (not supported by SerpAPI)
@@ -219,6 +534,9 @@ func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (
 
 // SearchAutocomplete gets search suggestions
 func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	if result, ok, err := e.searchFamily(ctx, "google_search_autocomplete", params); ok {
+		return result, err
+	}
 	apiParams := map[string]string{
 		"q":      params.Query,
 		"engine": "google_autocomplete",
@@ -230,8 +548,9 @@ func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchP
 	if params.Country != "" {
 		apiParams["gl"] = params.Country
 	}
+	maps.Copy(apiParams, omniserp.ExtraQuery(params, e.ExtraParams()))
 
-	return e.makeRequest(apiParams)
+	return e.makeRequest(ctx, apiParams)
 }
 
 // ScrapeWebpage scrapes content from a webpage (using SerpAPI's custom scraping)
@@ -243,21 +562,20 @@ func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams
 
 	// SerpAPI doesn't have a direct scraping endpoint like Serper
 	// We'll implement a basic HTTP scraping here
-	req, err := http.NewRequest(http.MethodGet, params.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
-	// #nosec G704 -- URL is intentionally user-provided for webpage scraping
-	resp, err := e.client.Do(req)
+	resp, chain, err := omniserp.FollowRedirects(e.scraper, req, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scrape webpage: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, scrapeLimit))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -266,16 +584,25 @@ func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams
 		return nil, fmt.Errorf("scraping error: status %d", resp.StatusCode)
 	}
 
-	// Return the raw HTML content
-	result := map[string]any{
-		"url":     params.URL,
-		"content": string(body),
-		"status":  resp.StatusCode,
-		"headers": resp.Header,
+	// Transcode to UTF-8 so non-UTF-8 pages are not returned as mojibake
+	contentType := resp.Header.Get("Content-Type")
+	text, charset := omniserp.DecodeHTML(contentType, body)
+
+	var scraped *omniserp.NormalizedScrapeResult
+	if contentType == "" || strings.Contains(contentType, "html") {
+		scraped = omniserp.ParseHTML(params.URL, []byte(text))
+	} else {
+		scraped = &omniserp.NormalizedScrapeResult{URL: params.URL, Text: text, ContentHash: omniserp.ContentHash(text)}
 	}
+	scraped.Charset = charset
+	scraped.FinalURL = resp.Request.URL.String()
+	scraped.RedirectChain = chain
+	scraped.StatusCode = resp.StatusCode
+	scraped.Engine = engineName
+	scraped.FetchedAt = time.Now().UTC()
 
 	return &omniserp.SearchResult{
-		Data: result,
-		Raw:  string(body),
+		Data: scraped,
+		Raw:  text,
 	}, nil
 }
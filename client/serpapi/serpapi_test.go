@@ -0,0 +1,237 @@
+package serpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// requestCases are the parameter combinations sent to every operation
+var requestCases = []struct {
+	name   string
+	params omniserp.SearchParams
+}{
+	{"minimal", omniserp.SearchParams{Query: "golang"}},
+	{"full", omniserp.SearchParams{
+		Query:      "golang generics",
+		Location:   "Austin, Texas",
+		Language:   "en",
+		Country:    "us",
+		NumResults: 20,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Verbatim:   true,
+		Image: &omniserp.ImageFilter{
+			MinWidth:    800,
+			Aspect:      omniserp.AspectWide,
+			Transparent: true,
+			License:     omniserp.LicenseCreativeCommons,
+		},
+		Cites: "1234567890",
+		Extra: map[string]any{"device": "mobile", "no_cache": true, "autocorrect": false},
+	}},
+}
+
+// operations are the search operations of the engine
+var operations = []struct {
+	name string
+	fn   func(omniserp.Engine, context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
+}{
+	{"search", omniserp.Engine.Search},
+	{"news", omniserp.Engine.SearchNews},
+	{"images", omniserp.Engine.SearchImages},
+	{"videos", omniserp.Engine.SearchVideos},
+	{"places", omniserp.Engine.SearchPlaces},
+	{"maps", omniserp.Engine.SearchMaps},
+	{"reviews", omniserp.Engine.SearchReviews},
+	{"shopping", omniserp.Engine.SearchShopping},
+	{"scholar", omniserp.Engine.SearchScholar},
+	{"lens", omniserp.Engine.SearchLens},
+	{"autocomplete", omniserp.Engine.SearchAutocomplete},
+}
+
+// TestRequestGolden checks the exact requests sent for each operation and
+// parameter combination against testdata/requests.golden. Run
+// "go test -update" to rewrite the file after an intended change.
+func TestRequestGolden(t *testing.T) {
+	var mu sync.Mutex
+	var recorded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		recorded = append(recorded, formatRequest(r, body))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL)
+	// The public scraper refuses the loopback test server
+	engine.scraper = srv.Client()
+	ctx := context.Background()
+
+	var out strings.Builder
+	capture := func(name string, call func() error) {
+		mu.Lock()
+		recorded = nil
+		mu.Unlock()
+		err := call()
+		mu.Lock()
+		// The server address varies between runs
+		fmt.Fprintf(&out, "=== %s\n%s", name, strings.ReplaceAll(strings.Join(recorded, ""), srv.URL, "http://server"))
+		mu.Unlock()
+		if err != nil {
+			fmt.Fprintf(&out, "error: %v\n\n", err)
+		}
+	}
+
+	for _, op := range operations {
+		for _, tc := range requestCases {
+			capture(op.name+"/"+tc.name, func() error {
+				_, err := op.fn(engine, ctx, tc.params)
+				return err
+			})
+		}
+	}
+	capture("scrape", func() error {
+		_, err := engine.ScrapeWebpage(ctx, omniserp.ScrapeParams{URL: srv.URL + "/page"})
+		return err
+	})
+
+	checkGolden(t, filepath.Join("testdata", "requests.golden"), out.String())
+}
+
+// formatRequest formats a request without the host and content length,
+// which vary between runs, and with a JSON body indented with sorted keys
+func formatRequest(r *http.Request, body []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL.RequestURI())
+	for _, name := range slices.Sorted(maps.Keys(r.Header)) {
+		if name == "Accept-Encoding" || name == "Content-Length" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(r.Header[name], ", "))
+	}
+	if len(body) > 0 {
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil {
+			body, _ = json.MarshalIndent(v, "", "  ")
+		}
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	return b.String() + "\n"
+}
+
+// checkGolden compares got with the golden file at path, rewriting it with -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o600); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run go test -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(want, []byte(got)) {
+		t.Errorf("Requests differ from %s (run go test -update if the change is intended):\n%s", path, got)
+	}
+}
+
+// TestFamilyRequestGolden checks the requests of the non-Google families
+// against testdata/families.golden
+func TestFamilyRequestGolden(t *testing.T) {
+	var recorded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded = append(recorded, formatRequest(r, nil))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var out strings.Builder
+	for _, family := range []Family{FamilyBing, FamilyYandex, FamilyBaidu} {
+		engine, err := NewFamilyWithAPIKey("test-key", family)
+		if err != nil {
+			t.Fatalf("NewFamilyWithAPIKey failed: %v", err)
+		}
+		engine.SetBaseURL(srv.URL)
+		for _, op := range operations {
+			for _, tc := range requestCases {
+				recorded = nil
+				_, err := op.fn(engine, context.Background(), tc.params)
+				fmt.Fprintf(&out, "=== %s/%s/%s\n%s", engine.GetName(), op.name, tc.name, strings.Join(recorded, ""))
+				if err != nil {
+					fmt.Fprintf(&out, "error: %v\n\n", err)
+				}
+			}
+		}
+	}
+
+	checkGolden(t, filepath.Join("testdata", "families.golden"), out.String())
+}
+
+func TestFamilies(t *testing.T) {
+	engine, err := NewFamilyWithAPIKey("test-key", FamilyYandex)
+	if err != nil {
+		t.Fatalf("NewFamilyWithAPIKey failed: %v", err)
+	}
+	if engine.GetName() != "serpapi-yandex" {
+		t.Errorf("Expected serpapi-yandex, got %s", engine.GetName())
+	}
+	if tools := engine.GetSupportedTools(); !slices.Equal(tools, []string{"google_search", "google_search_images", "google_search_videos"}) {
+		t.Errorf("Unexpected tools: %v", tools)
+	}
+	if params := engine.ExtraParams(); len(params) != 1 || params[0].Name != "no_cache" {
+		t.Errorf("Expected only no_cache, got %+v", params)
+	}
+
+	if _, err := NewFamilyWithAPIKey("test-key", "altavista"); err == nil {
+		t.Error("Expected an error for an unknown family")
+	}
+}
+
+func TestScrapeWebpagePrivateAddress(t *testing.T) {
+	var requested bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer srv.Close()
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	_, err = engine.ScrapeWebpage(context.Background(), omniserp.ScrapeParams{URL: srv.URL + "/admin"})
+	if !errors.Is(err, omniserp.ErrPrivateAddress) {
+		t.Errorf("Expected omniserp.ErrPrivateAddress, got %v", err)
+	}
+	if requested {
+		t.Error("Expected no request to the loopback server")
+	}
+}
@@ -3,12 +3,16 @@ package serper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/plexusone/omniserp"
 )
@@ -21,17 +25,35 @@ const (
 
 // Engine implements the omniserp.Engine interface for Serper API
 type Engine struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	client  *http.Client
+
+	// redirects resolves the redirects of scraped pages locally; nil
+	// disables resolution
+	redirects *http.Client
 }
 
 // New creates a new Serper engine instance using SERPER_API_KEY env var.
+// SERPER_RESOLVE_REDIRECTS=true enables local redirect resolution (see
+// SetResolveRedirects).
 func New() (*Engine, error) {
 	apiKey := os.Getenv("SERPER_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("SERPER_API_KEY environment variable is required")
 	}
-	return NewWithAPIKey(apiKey)
+	engine, err := NewWithAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("SERPER_RESOLVE_REDIRECTS"); v != "" {
+		resolve, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SERPER_RESOLVE_REDIRECTS: %w", err)
+		}
+		engine.SetResolveRedirects(resolve)
+	}
+	return engine, nil
 }
 
 // NewWithAPIKey creates a new Serper engine instance with the provided API key.
@@ -42,11 +64,47 @@ func NewWithAPIKey(apiKey string) (*Engine, error) {
 	}
 
 	return &Engine{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
 	}, nil
 }
 
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetResolveRedirects enables resolving the redirects of scraped pages with
+// a HEAD request from the local host before Serper fetches them, to apply
+// the redirect policy of omniserp.ScrapeParams and report the final URL and
+// redirect chain. Serper does not report redirects, so without it scrape
+// results report the requested URL as final and the redirect policy is not
+// applied. Resolution uses omniserp.NewPublicClient, without the engine's
+// signer or transport, so it cannot reach private or internal addresses.
+func (e *Engine) SetResolveRedirects(enabled bool) {
+	if enabled {
+		e.redirects = omniserp.NewPublicClient()
+	} else {
+		e.redirects = nil
+	}
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
 // GetName returns the engine name
 func (e *Engine) GetName() string {
 	return engineName
@@ -75,14 +133,40 @@ func (e *Engine) GetSupportedTools() []string {
 	}
 }
 
+// SupportedParams implements omniserp.ParamReporter
+func (e *Engine) SupportedParams(operation string) []string {
+	switch operation {
+	case "google_search_scholar":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamNumResults}
+	case "google_search_lens":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamCountry, omniserp.ParamNumResults}
+	case "google_search_autocomplete":
+		return []string{omniserp.ParamQuery, omniserp.ParamLanguage, omniserp.ParamCountry}
+	case "webpage_scrape":
+		return nil
+	}
+	return omniserp.AllParams()
+}
+
+// Extra declares the Serper-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Autocorrect bool `extra:"autocorrect" description:"Correct the spelling of the query (default true)"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
 // makeRequest performs HTTP request to Serper API
-func (e *Engine) makeRequest(endpoint string, params map[string]interface{}) (*omniserp.SearchResult, error) {
+func (e *Engine) makeRequest(ctx context.Context, endpoint string, params map[string]interface{}) (*omniserp.SearchResult, error) {
 	data, err := json.Marshal(params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, baseURL+endpoint, strings.NewReader(string(data)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+endpoint, strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -90,7 +174,8 @@ func (e *Engine) makeRequest(endpoint string, params map[string]interface{}) (*o
 	req.Header.Set("X-API-KEY", e.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	// #nosec G704 -- request to hardcoded Serper API endpoint
+	start := time.Now()
+	// #nosec G704 -- request to the Serper API endpoint or a configured proxy
 	resp, err := e.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -101,9 +186,10 @@ func (e *Engine) makeRequest(endpoint string, params map[string]interface{}) (*o
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: %s", string(body))
+		return nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
 	}
 
 	var result map[string]any
@@ -112,8 +198,9 @@ func (e *Engine) makeRequest(endpoint string, params map[string]interface{}) (*o
 	}
 
 	return &omniserp.SearchResult{
-		Data: result,
-		Raw:  string(body),
+		Data:     result,
+		Raw:      string(body),
+		Response: meta,
 	}, nil
 }
 
@@ -132,51 +219,70 @@ func (e *Engine) buildParams(params omniserp.SearchParams) map[string]any {
 	if params.Country != "" {
 		apiParams["gl"] = params.Country
 	}
+	if tbs := params.Freshness.TBS(); tbs != "" {
+		apiParams["tbs"] = tbs
+	}
+	if params.SafeSearch {
+		apiParams["safe"] = "active"
+	}
+	if params.Verbatim {
+		apiParams["autocorrect"] = false
+	}
 	if params.NumResults > 0 {
 		apiParams["num"] = params.NumResults
 	}
+	if params.Page > 1 {
+		apiParams["page"] = params.Page
+	}
+	maps.Copy(apiParams, omniserp.ExtraArgs(params, e.ExtraParams()))
 
 	return apiParams
 }
 
 // Search performs a general web search
 func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/search", e.buildParams(params))
+	return e.makeRequest(ctx, "/search", e.buildParams(params))
 }
 
 // SearchNews performs a news search
 func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/news", e.buildParams(params))
+	return e.makeRequest(ctx, "/news", e.buildParams(params))
 }
 
-// SearchImages performs an image search
+// SearchImages performs an image search. Transparency, aspect ratio, and
+// license filters are sent as "tbs"; the minimum size is enforced by the
+// client.
 func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/images", e.buildParams(params))
+	apiParams := e.buildParams(params)
+	if tbs := params.ImageTBS(); tbs != "" {
+		apiParams["tbs"] = tbs
+	}
+	return e.makeRequest(ctx, "/images", apiParams)
 }
 
 // SearchVideos performs a video search
 func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/videos", e.buildParams(params))
+	return e.makeRequest(ctx, "/videos", e.buildParams(params))
 }
 
 // SearchPlaces performs a places search
 func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/places", e.buildParams(params))
+	return e.makeRequest(ctx, "/places", e.buildParams(params))
 }
 
 // SearchMaps performs a maps search
 func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/maps", e.buildParams(params))
+	return e.makeRequest(ctx, "/maps", e.buildParams(params))
 }
 
 // SearchReviews performs a reviews search
 func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/reviews", e.buildParams(params))
+	return e.makeRequest(ctx, "/reviews", e.buildParams(params))
 }
 
 // SearchShopping performs a shopping search
 func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
-	return e.makeRequest("/shopping", e.buildParams(params))
+	return e.makeRequest(ctx, "/shopping", e.buildParams(params))
 }
 
 // SearchScholar performs a scholar search
@@ -191,8 +297,9 @@ func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams
 	if params.NumResults > 0 {
 		apiParams["num"] = params.NumResults
 	}
+	maps.Copy(apiParams, omniserp.ExtraArgs(params, e.ExtraParams()))
 
-	return e.makeRequest("/scholar", apiParams)
+	return e.makeRequest(ctx, "/scholar", apiParams)
 }
 
 // SearchLens performs a visual search
@@ -210,8 +317,9 @@ func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (
 	if params.NumResults > 0 {
 		apiParams["num"] = params.NumResults
 	}
+	maps.Copy(apiParams, omniserp.ExtraArgs(params, e.ExtraParams()))
 
-	return e.makeRequest("/lens", apiParams)
+	return e.makeRequest(ctx, "/lens", apiParams)
 }
 
 // SearchAutocomplete gets search suggestions
@@ -226,8 +334,9 @@ func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchP
 	if params.Country != "" {
 		apiParams["gl"] = params.Country
 	}
+	maps.Copy(apiParams, omniserp.ExtraArgs(params, e.ExtraParams()))
 
-	return e.makeRequest("/autocomplete", apiParams)
+	return e.makeRequest(ctx, "/autocomplete", apiParams)
 }
 
 // ScrapeWebpage scrapes content from a webpage
@@ -237,9 +346,83 @@ func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
+	// Serper does not report redirects, so they are resolved here first, if
+	// enabled, to apply the redirect policy and record the final URL
+	finalURL, chain, err := e.resolveRedirects(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
 	apiParams := map[string]interface{}{
-		"url": params.URL,
+		"url":             finalURL,
+		"includeMarkdown": true,
+	}
+
+	result, err := e.makeRequest(ctx, "/scrape", apiParams)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := result.Data.(map[string]any)
+	scraped := &omniserp.NormalizedScrapeResult{
+		URL:           params.URL,
+		FinalURL:      finalURL,
+		RedirectChain: chain,
+		Text:          stringValue(data["text"]),
+		Markdown:      stringValue(data["markdown"]),
+		Engine:        engineName,
+		FetchedAt:     time.Now().UTC(),
+	}
+	if metadata, ok := data["metadata"].(map[string]any); ok {
+		scraped.Metadata = make(map[string]string, len(metadata))
+		for key, value := range metadata {
+			scraped.Metadata[strings.ToLower(key)] = stringValue(value)
+		}
+		scraped.Title = scraped.Metadata["title"]
+		if scraped.Title == "" {
+			scraped.Title = scraped.Metadata["og:title"]
+		}
 	}
+	scraped.Links = omniserp.MarkdownLinks(scraped.Markdown)
+	scraped.ContentHash = omniserp.ContentHash(scraped.Text)
 
-	return e.makeRequest("/scrape", apiParams)
+	result.Data = scraped
+	return result, nil
+}
+
+// resolveRedirects follows the redirects of the page at params.URL with a
+// HEAD request under the redirect policy, if enabled with
+// SetResolveRedirects. Policy violations are returned as errors; if
+// resolution is disabled or the page cannot be reached, such as at a
+// private address, the requested URL is used as is.
+func (e *Engine) resolveRedirects(ctx context.Context, params omniserp.ScrapeParams) (string, []string, error) {
+	if e.redirects == nil {
+		return params.URL, nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, params.URL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	resp, chain, err := omniserp.FollowRedirects(e.redirects, req, params)
+	if errors.Is(err, omniserp.ErrTooManyRedirects) || errors.Is(err, omniserp.ErrCrossOriginRedirect) {
+		return "", nil, fmt.Errorf("failed to scrape webpage: %w", err)
+	}
+	if err != nil {
+		return params.URL, nil, nil
+	}
+	resp.Body.Close()
+	return resp.Request.URL.String(), chain, nil
+}
+
+// stringValue formats a JSON value as a string
+func stringValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
 }
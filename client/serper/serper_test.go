@@ -0,0 +1,204 @@
+package serper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// requestCases are the parameter combinations sent to every operation
+var requestCases = []struct {
+	name   string
+	params omniserp.SearchParams
+}{
+	{"minimal", omniserp.SearchParams{Query: "golang"}},
+	{"full", omniserp.SearchParams{
+		Query:      "golang generics",
+		Location:   "Austin, Texas",
+		Language:   "en",
+		Country:    "us",
+		NumResults: 20,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		SafeSearch: true,
+		Verbatim:   true,
+		Image: &omniserp.ImageFilter{
+			MinWidth:    800,
+			Aspect:      omniserp.AspectWide,
+			Transparent: true,
+			License:     omniserp.LicenseCreativeCommons,
+		},
+		Cites: "1234567890",
+		Extra: map[string]any{"autocorrect": "false", "device": "mobile"},
+	}},
+}
+
+// operations are the search operations of the engine
+var operations = []struct {
+	name string
+	fn   func(omniserp.Engine, context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
+}{
+	{"search", omniserp.Engine.Search},
+	{"news", omniserp.Engine.SearchNews},
+	{"images", omniserp.Engine.SearchImages},
+	{"videos", omniserp.Engine.SearchVideos},
+	{"places", omniserp.Engine.SearchPlaces},
+	{"maps", omniserp.Engine.SearchMaps},
+	{"reviews", omniserp.Engine.SearchReviews},
+	{"shopping", omniserp.Engine.SearchShopping},
+	{"scholar", omniserp.Engine.SearchScholar},
+	{"lens", omniserp.Engine.SearchLens},
+	{"autocomplete", omniserp.Engine.SearchAutocomplete},
+}
+
+// TestRequestGolden checks the exact requests sent for each operation and
+// parameter combination against testdata/requests.golden. Run
+// "go test -update" to rewrite the file after an intended change.
+func TestRequestGolden(t *testing.T) {
+	var mu sync.Mutex
+	var recorded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		recorded = append(recorded, formatRequest(r, body))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL)
+	ctx := context.Background()
+
+	var out strings.Builder
+	capture := func(name string, call func() error) {
+		mu.Lock()
+		recorded = nil
+		mu.Unlock()
+		err := call()
+		mu.Lock()
+		// The server address varies between runs
+		fmt.Fprintf(&out, "=== %s\n%s", name, strings.ReplaceAll(strings.Join(recorded, ""), srv.URL, "http://server"))
+		mu.Unlock()
+		if err != nil {
+			fmt.Fprintf(&out, "error: %v\n\n", err)
+		}
+	}
+
+	for _, op := range operations {
+		for _, tc := range requestCases {
+			capture(op.name+"/"+tc.name, func() error {
+				_, err := op.fn(engine, ctx, tc.params)
+				return err
+			})
+		}
+	}
+	capture("scrape", func() error {
+		_, err := engine.ScrapeWebpage(ctx, omniserp.ScrapeParams{URL: srv.URL + "/page"})
+		return err
+	})
+
+	checkGolden(t, filepath.Join("testdata", "requests.golden"), out.String())
+}
+
+// formatRequest formats a request without the host and content length,
+// which vary between runs, and with a JSON body indented with sorted keys
+func formatRequest(r *http.Request, body []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL.RequestURI())
+	for _, name := range slices.Sorted(maps.Keys(r.Header)) {
+		if name == "Accept-Encoding" || name == "Content-Length" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(r.Header[name], ", "))
+	}
+	if len(body) > 0 {
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil {
+			body, _ = json.MarshalIndent(v, "", "  ")
+		}
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	return b.String() + "\n"
+}
+
+// checkGolden compares got with the golden file at path, rewriting it with -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o600); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run go test -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(want, []byte(got)) {
+		t.Errorf("Requests differ from %s (run go test -update if the change is intended):\n%s", path, got)
+	}
+}
+
+// TestScrapeRedirects checks that scraped pages are only requested from the
+// local host when redirect resolution is enabled, and never at a private
+// address
+func TestScrapeRedirects(t *testing.T) {
+	var heads int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			mu.Lock()
+			heads++
+			mu.Unlock()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"text": "page"}`)
+	}))
+	defer srv.Close()
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL)
+
+	ctx := context.Background()
+	for _, resolve := range []bool{false, true} {
+		engine.SetResolveRedirects(resolve)
+		result, err := engine.ScrapeWebpage(ctx, omniserp.ScrapeParams{URL: srv.URL + "/page"})
+		if err != nil {
+			t.Fatalf("ScrapeWebpage failed: %v", err)
+		}
+		scraped := result.Data.(*omniserp.NormalizedScrapeResult)
+		if scraped.FinalURL != srv.URL+"/page" || scraped.RedirectChain != nil {
+			t.Errorf("Expected the requested URL as final, got %q %v", scraped.FinalURL, scraped.RedirectChain)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if heads != 0 {
+		t.Errorf("Expected no local request to the loopback page, got %d", heads)
+	}
+}
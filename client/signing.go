@@ -0,0 +1,40 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/plexusone/omniserp"
+)
+
+// requestSignerSetter is implemented by engines that can sign their
+// requests, such as all built-in engines
+type requestSignerSetter interface {
+	SetRequestSigner(signer omniserp.RequestSigner)
+}
+
+// SignEngineRequests sets the request signers of the registry's engines by
+// engine name. Signers of engines that are not registered are ignored, so
+// one configuration can cover optional engines; registered engines that
+// cannot sign their requests are an error, as their requests would be
+// rejected.
+func SignEngineRequests(registry *omniserp.Registry, signers map[string]omniserp.RequestSigner) error {
+	names := make([]string, 0, len(signers))
+	for name := range signers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		engine, ok := registry.Get(name)
+		if !ok {
+			continue
+		}
+		setter, ok := engine.(requestSignerSetter)
+		if !ok {
+			return fmt.Errorf("engine '%s' does not support request signing", name)
+		}
+		setter.SetRequestSigner(signers[name])
+	}
+	return nil
+}
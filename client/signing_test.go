@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/kagi"
+)
+
+func TestSignEngineRequests(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		_, _ = w.Write([]byte(`{"meta":{"ms":1},"data":[]}`))
+	}))
+	defer srv.Close()
+
+	engine, err := kagi.NewWithAPIKey("placeholder")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL)
+	registry := omniserp.NewRegistry()
+	registry.Register(engine)
+
+	signers := map[string]omniserp.RequestSigner{
+		"kagi": &omniserp.HMACSigner{Key: []byte("secret")},
+		// Signers of unregistered engines are ignored
+		"exa": &omniserp.HMACSigner{Key: []byte("secret")},
+	}
+	if err := SignEngineRequests(registry, signers); err != nil {
+		t.Fatalf("SignEngineRequests failed: %v", err)
+	}
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(signature) != 64 {
+		t.Errorf("Expected a hex SHA-256 signature, got %q", signature)
+	}
+
+	registry.Register(&fakeEngine{name: "fake"})
+	if err := SignEngineRequests(registry, map[string]omniserp.RequestSigner{"fake": signers["kagi"]}); err == nil {
+		t.Error("Expected an error for an engine without request signing")
+	}
+}
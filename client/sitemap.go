@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Sitemap limits
+const (
+	// sitemapFetchLimit is the largest sitemap read, the limit of the
+	// sitemaps protocol
+	sitemapFetchLimit = 50 << 20
+
+	// maxSitemapDepth bounds the nesting of sitemap indexes
+	maxSitemapDepth = 3
+)
+
+// sitemapDocument is a sitemap (urlset) or a sitemap index
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapLoc is an entry of a sitemap or sitemap index
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ReadSitemap returns the page URLs of the XML sitemap at sitemapURL, in
+// order and without duplicates, following sitemap indexes into their
+// sitemaps. Gzipped sitemaps are decompressed. A nil httpClient uses
+// omniserp.NewPublicClient, which refuses private addresses.
+func ReadSitemap(ctx context.Context, sitemapURL string, httpClient *http.Client) ([]string, error) {
+	if httpClient == nil {
+		httpClient = omniserp.NewPublicClient()
+	}
+	var urls []string
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+	if err := readSitemap(ctx, httpClient, sitemapURL, 0, visited, func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// readSitemap fetches one sitemap and adds its URLs, descending into the
+// sitemaps of an index
+func readSitemap(ctx context.Context, httpClient *http.Client, sitemapURL string, depth int, visited map[string]bool, add func(string)) error {
+	if visited[sitemapURL] {
+		return nil
+	}
+	visited[sitemapURL] = true
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid sitemap URL: %w", err)
+	}
+	// #nosec G704 -- sitemap URL is provided by the caller
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sitemap: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch sitemap %s: status %d", sitemapURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, sitemapFetchLimit))
+	if err != nil {
+		return fmt.Errorf("failed to read sitemap: %w", err)
+	}
+	// Gzipped sitemaps are detected by their magic number, since servers
+	// label them inconsistently
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		data, err = io.ReadAll(io.LimitReader(gz, sitemapFetchLimit))
+		if err != nil {
+			return fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse sitemap %s: %w", sitemapURL, err)
+	}
+
+	switch doc.XMLName.Local {
+	case "urlset":
+		for _, entry := range doc.URLs {
+			if loc := strings.TrimSpace(entry.Loc); loc != "" {
+				add(loc)
+			}
+		}
+	case "sitemapindex":
+		if depth >= maxSitemapDepth {
+			return fmt.Errorf("sitemap indexes nested deeper than %d at %s", maxSitemapDepth, sitemapURL)
+		}
+		for _, entry := range doc.Sitemaps {
+			loc := strings.TrimSpace(entry.Loc)
+			if loc == "" {
+				continue
+			}
+			if err := readSitemap(ctx, httpClient, loc, depth+1, visited, add); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s is not a sitemap: root element <%s>", sitemapURL, doc.XMLName.Local)
+	}
+	return nil
+}
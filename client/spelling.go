@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+
+	"github.com/plexusone/omniserp"
+)
+
+// SetVerbatimRequery enables or disables repeating web searches that the
+// engine spelling-corrected with the query as written (see Options.RequeryVerbatim)
+func (c *Client) SetVerbatimRequery(enabled bool) {
+	c.mu.Lock()
+	c.verbatimRequery = enabled
+	c.mu.Unlock()
+}
+
+// requeryVerbatimEnabled reports whether corrected web searches are repeated
+// verbatim
+func (c *Client) requeryVerbatimEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.verbatimRequery
+}
+
+// requeryVerbatim repeats a corrected web search with SearchParams.Verbatim.
+// The correction is kept as the SuggestedQuery of the verbatim result, so
+// callers can still offer it.
+func (c *Client) requeryVerbatim(ctx context.Context, corrected *omniserp.NormalizedSearchResult, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	params.Verbatim = true
+	verbatim, err := c.SearchNormalized(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if verbatim.SearchMetadata.SuggestedQuery == "" {
+		verbatim.SearchMetadata.SuggestedQuery = corrected.SearchMetadata.CorrectedQuery
+	}
+	return verbatim, nil
+}
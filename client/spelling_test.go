@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestRequeryVerbatim(t *testing.T) {
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		if params.Verbatim {
+			return organicResponse("https://example.com/golnag"), nil
+		}
+		result := organicResponse("https://go.dev")
+		result.Data.(map[string]any)["searchInformation"] = map[string]any{"showingResultsFor": "golang"}
+		return result, nil
+	})
+	ctx := context.Background()
+
+	result, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "golnag"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if result.SearchMetadata.CorrectedQuery != "golang" || result.OrganicResults[0].Link != "https://go.dev" {
+		t.Errorf("Expected the corrected results without requery, got %+v", result.SearchMetadata)
+	}
+
+	c.SetVerbatimRequery(true)
+	result, err = c.SearchNormalized(ctx, omniserp.SearchParams{Query: "golnag"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if result.OrganicResults[0].Link != "https://example.com/golnag" {
+		t.Errorf("Expected the verbatim results, got %+v", result.OrganicResults)
+	}
+	if result.SearchMetadata.CorrectedQuery != "" || result.SearchMetadata.SuggestedQuery != "golang" {
+		t.Errorf("Expected the correction as the suggested query, got %+v", result.SearchMetadata)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// statsWindow is the number of most recent requests kept per engine
+const statsWindow = 200
+
+// EngineStats summarizes the rolling latency and error rate of an engine
+// over its most recent requests
+type EngineStats struct {
+	Engine    string        `json:"engine"`
+	Requests  int           `json:"requests"`
+	Errors    int           `json:"errors"`
+	ErrorRate float64       `json:"error_rate"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+}
+
+// sample is one recorded request
+type sample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// statsRecorder keeps a fixed-size ring of samples per engine
+type statsRecorder struct {
+	mu      sync.Mutex
+	samples map[string][]sample
+	next    map[string]int
+}
+
+func newStatsRecorder() *statsRecorder {
+	return &statsRecorder{
+		samples: make(map[string][]sample),
+		next:    make(map[string]int),
+	}
+}
+
+// record adds a sample for engine, evicting the oldest once the window is full
+func (r *statsRecorder) record(engine string, latency time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := sample{latency: latency, failed: failed}
+	if len(r.samples[engine]) < statsWindow {
+		r.samples[engine] = append(r.samples[engine], s)
+		return
+	}
+	r.samples[engine][r.next[engine]] = s
+	r.next[engine] = (r.next[engine] + 1) % statsWindow
+}
+
+// get computes the stats of one engine
+func (r *statsRecorder) get(engine string) EngineStats {
+	r.mu.Lock()
+	samples := slices.Clone(r.samples[engine])
+	r.mu.Unlock()
+
+	stats := EngineStats{Engine: engine, Requests: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		latencies[i] = s.latency
+		if s.failed {
+			stats.Errors++
+		}
+	}
+	slices.Sort(latencies)
+
+	stats.ErrorRate = float64(stats.Errors) / float64(len(samples))
+	stats.P50 = percentile(latencies, 50)
+	stats.P95 = percentile(latencies, 95)
+	stats.P99 = percentile(latencies, 99)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats returns rolling latency percentiles and error rates for every
+// registered engine, keyed by engine name. Engines that have not served a
+// request yet report zero requests.
+func (c *Client) Stats() map[string]EngineStats {
+	stats := make(map[string]EngineStats)
+	for _, name := range c.registry.List() {
+		stats[name] = c.stats.get(name)
+	}
+	return stats
+}
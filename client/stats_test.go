@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestStatsAndFailover(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return nil, errors.New("upstream unavailable")
+	}})
+	backupCalls := 0
+	registry.Register(&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		backupCalls++
+		return &omniserp.SearchResult{Data: map[string]any{"organic_results": []any{}}}, nil
+	}})
+
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// Without a policy, errors are returned and recorded
+	if _, err := c.Search(ctx, omniserp.SearchParams{Query: "q"}); err == nil {
+		t.Fatal("Expected error without failover policy")
+	}
+	if s := c.Stats()["serper"]; s.Requests != 1 || s.Errors != 1 || s.ErrorRate != 1 {
+		t.Errorf("Unexpected serper stats: %+v", s)
+	}
+
+	// With a policy, failed requests are retried on the next engine
+	c.SetFailoverPolicy(&FailoverPolicy{MaxErrorRate: 0.5, MinRequests: 3})
+	for i := 0; i < 3; i++ {
+		result, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "q"})
+		if err != nil {
+			t.Fatalf("Expected failover to succeed: %v", err)
+		}
+		if result.SearchMetadata.Engine != "serpapi" {
+			t.Errorf("Expected result normalized by serpapi, got %q", result.SearchMetadata.Engine)
+		}
+	}
+
+	// serper is now degraded and skipped without being attempted
+	before := c.Stats()["serper"].Requests
+	if _, err := c.Search(ctx, omniserp.SearchParams{Query: "q"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if after := c.Stats()["serper"].Requests; after != before {
+		t.Errorf("Expected degraded engine to be skipped, requests went from %d to %d", before, after)
+	}
+	if s := c.Stats()["serpapi"]; s.Requests != backupCalls || s.Errors != 0 {
+		t.Errorf("Unexpected serpapi stats: %+v (calls: %d)", s, backupCalls)
+	}
+}
+
+func TestFailoverHook(t *testing.T) {
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return nil, errors.New("upstream unavailable")
+	}})
+	registry.Register(&fakeEngine{name: "serpapi", tools: AllOperations(), search: func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("serpapi"), nil
+	}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	c.SetFailoverPolicy(&FailoverPolicy{MaxErrorRate: 0.5})
+
+	var events []FailoverEvent
+	ctx := ContextWithFailoverHook(context.Background(), func(event FailoverEvent) {
+		events = append(events, event)
+	})
+	if _, err := c.SearchNews(ctx, omniserp.SearchParams{Query: "q"}); err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected one failover event, got %+v", events)
+	}
+	if e := events[0]; e.Operation != OpSearchNews || e.From != "serper" || e.To != "serpapi" || e.Err == nil {
+		t.Errorf("Unexpected failover event: %+v", e)
+	}
+}
+
+// TestFailoverReload changes the failover, sanitizer, and source policies,
+// as the MCP config reloader does, while searches are running; run with
+// -race
+func TestFailoverReload(t *testing.T) {
+	c := newFakeClient(t, func(p omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return organicResponse("https://example.com"), nil
+	})
+	scorer := omniserp.SourceScorerFunc(func(domain string) float64 { return 1 })
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.SetFailoverPolicy(&FailoverPolicy{MaxErrorRate: 0.5})
+			c.SetSanitizer(&omniserp.Sanitizer{Secrets: []string{"secret"}})
+			c.SetSourcePolicy(&omniserp.SourcePolicy{Scorer: scorer})
+			c.SetFailoverPolicy(nil)
+			c.SetSanitizer(nil)
+			c.SetSourcePolicy(nil)
+		}
+	}()
+
+	ctx := context.Background()
+	for range 200 {
+		if _, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "q"}); err != nil {
+			t.Errorf("SearchNormalized failed: %v", err)
+			break
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
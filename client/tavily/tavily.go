@@ -0,0 +1,351 @@
+// Package tavily implements the omniserp.Engine interface for the Tavily
+// Search API, which is built for LLM agents: it returns a generated answer
+// and the relevant content extracted from each result, and optionally the
+// full content of each page.
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://api.tavily.com"
+	engineName    = "tavily"
+	engineVersion = "1.0.0"
+	searchPath    = "/search"
+
+	// maxResults is the largest number of results the API returns
+	maxResults = 20
+)
+
+// Topics of the Search API
+const (
+	topicGeneral = "general"
+	topicNews    = "news"
+)
+
+// Engine implements the omniserp.Engine interface for Tavily. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Tavily engine from the TAVILY_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("TAVILY_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("TAVILY_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Tavily engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The API returns at most
+// 20 results and has no pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamNumResults,
+		omniserp.ParamFreshness,
+	}
+}
+
+// Extra declares the Tavily-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	SearchDepth string `extra:"search_depth" description:"basic (default) or advanced, which returns more relevant content for two credits"`
+	RawContent  bool   `extra:"include_raw_content" description:"Include the extracted content of each page as raw_content"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// request is the JSON body of a Search API request
+type request struct {
+	Query             string `json:"query"`
+	Topic             string `json:"topic"`
+	SearchDepth       string `json:"search_depth,omitempty"`
+	MaxResults        int    `json:"max_results,omitempty"`
+	TimeRange         string `json:"time_range,omitempty"`
+	IncludeAnswer     bool   `json:"include_answer"`
+	IncludeRawContent bool   `json:"include_raw_content,omitempty"`
+}
+
+// response is the JSON response of the Search API
+type response struct {
+	Answer  string `json:"answer"`
+	Results []struct {
+		Title         string `json:"title"`
+		URL           string `json:"url"`
+		Content       string `json:"content"`
+		RawContent    string `json:"raw_content"`
+		PublishedDate string `json:"published_date"`
+	} `json:"results"`
+	ResponseTime float64 `json:"response_time"`
+}
+
+// timeRange returns the time range of the API; there is no hourly range
+func timeRange(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour, omniserp.FreshnessDay:
+		return "day"
+	case omniserp.FreshnessWeek, omniserp.FreshnessMonth, omniserp.FreshnessYear:
+		return string(f)
+	}
+	return ""
+}
+
+// buildRequest converts SearchParams to a Search API request on a topic
+func (e *Engine) buildRequest(params omniserp.SearchParams, topic string) request {
+	extra := omniserp.ExtraArgs(params, e.ExtraParams())
+	depth, _ := extra["search_depth"].(string)
+	rawContent, _ := extra["include_raw_content"].(bool)
+	return request{
+		Query:             params.Query,
+		Topic:             topic,
+		SearchDepth:       depth,
+		MaxResults:        min(params.NumResults, maxResults),
+		TimeRange:         timeRange(params.Freshness),
+		IncludeAnswer:     true,
+		IncludeRawContent: rawContent,
+	}
+}
+
+// search performs a Search API request and parses the response
+func (e *Engine) search(ctx context.Context, body request) (*response, string, *omniserp.ResponseMeta, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+searchPath, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to the Tavily API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		// Exhausted plan limits are reported as 432 and 433
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(raw), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &parsed, string(raw), meta, nil
+}
+
+// newNormalized returns a normalized result for params with the answer of
+// resp, if any
+func newNormalized(params omniserp.SearchParams, resp *response) *omniserp.NormalizedSearchResult {
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			TimeTaken: resp.ResponseTime,
+		},
+	}
+	if resp.Answer != "" {
+		normalized.AnswerBox = &omniserp.AnswerBox{Type: "answer", Answer: resp.Answer}
+	}
+	return normalized
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// publishedDate converts the RFC 1123 dates of news results to RFC 3339,
+// and returns other dates as is
+func publishedDate(date string) string {
+	for _, layout := range []string{time.RFC1123, time.RFC1123Z} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return date
+}
+
+// Search performs a general web search. The extracted content of each result
+// is its snippet; with the "include_raw_content" extra parameter the content
+// of the page is returned as RawContent.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, e.buildRequest(params, topicGeneral))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.Results {
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position:   i + 1,
+			Title:      r.Title,
+			Link:       r.URL,
+			URL:        r.URL,
+			Snippet:    r.Content,
+			Domain:     hostname(r.URL),
+			Date:       publishedDate(r.PublishedDate),
+			RawContent: r.RawContent,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search on the news topic
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, e.buildRequest(params, topicNews))
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.Results {
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position: i + 1,
+			Title:    r.Title,
+			Link:     r.URL,
+			Source:   hostname(r.URL),
+			Date:     publishedDate(r.PublishedDate),
+			Snippet:  r.Content,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search (not supported by Tavily)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Tavily")
+}
+
+// SearchVideos performs a video search (not supported by Tavily)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Tavily")
+}
+
+// SearchPlaces performs a places search (not supported by Tavily)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Tavily")
+}
+
+// SearchMaps performs a maps search (not supported by Tavily)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Tavily")
+}
+
+// SearchReviews performs a reviews search (not supported by Tavily)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Tavily")
+}
+
+// SearchShopping performs a shopping search (not supported by Tavily)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Tavily")
+}
+
+// SearchScholar performs a scholar search (not supported by Tavily)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Tavily")
+}
+
+// SearchLens performs a visual search (not supported by Tavily)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Tavily")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Tavily)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Tavily")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Tavily)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Tavily")
+}
@@ -0,0 +1,144 @@
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each topic and records the body of the
+// last request
+func newTestServer(t *testing.T) (*Engine, *request) {
+	t.Helper()
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	search, news := fixture("search.json"), fixture("news.json")
+
+	var body request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != searchPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"detail":{"error":"Unauthorized: missing or invalid API key."}}`))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body.Topic == topicNews {
+			_, _ = w.Write(news)
+		} else {
+			_, _ = w.Write(search)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &body
+}
+
+func TestSearch(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		NumResults: 50,
+		Freshness:  omniserp.FreshnessHour,
+		Extra:      map[string]any{"search_depth": "advanced", "include_raw_content": "true"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	want := request{
+		Query:             "golang",
+		Topic:             topicGeneral,
+		SearchDepth:       "advanced",
+		MaxResults:        maxResults,
+		TimeRange:         "day",
+		IncludeAnswer:     true,
+		IncludeRawContent: true,
+	}
+	if *body != want {
+		t.Errorf("Expected request %+v, got %+v", want, *body)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if box := normalized.AnswerBox; box == nil || box.Answer != "Go is an open source programming language designed at Google." {
+		t.Errorf("Expected the answer in the answer box, got %+v", box)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	first, second := normalized.OrganicResults[0], normalized.OrganicResults[1]
+	if first.Domain != "go.dev" || first.Engine != engineName || first.RawContent == "" {
+		t.Errorf("Expected the first result with its raw content, got %+v", first)
+	}
+	if second.Domain != "github.com" || second.Position != 2 || second.RawContent != "" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if normalized.SearchMetadata.TimeTaken != 1.27 {
+		t.Errorf("Expected the response time, got %v", normalized.SearchMetadata.TimeTaken)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, body := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang", NumResults: 5})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if body.Topic != topicNews || body.MaxResults != 5 || body.IncludeRawContent {
+		t.Errorf("Unexpected news request: %+v", *body)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 1 {
+		t.Fatalf("Expected 1 news result, got %d", len(normalized.NewsResults))
+	}
+	news := normalized.NewsResults[0]
+	if news.Source != "example.org" || news.Date != "2026-02-10T09:30:00Z" {
+		t.Errorf("Unexpected news result: %+v", news)
+	}
+	if normalized.AnswerBox == nil {
+		t.Error("Expected the answer of news searches in the answer box")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
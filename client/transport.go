@@ -0,0 +1,44 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/plexusone/omniserp"
+)
+
+// transportSetter is implemented by engines whose HTTP transport can be
+// replaced, such as all built-in engines
+type transportSetter interface {
+	SetTransport(rt http.RoundTripper)
+}
+
+// ConfigureEngineTransports sets the HTTP transports of the registry's
+// engines from their configurations by engine name. Configurations of
+// engines that are not registered are ignored; registered engines without
+// a settable transport are an error.
+func ConfigureEngineTransports(registry *omniserp.Registry, configs map[string]omniserp.TransportConfig) error {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		engine, ok := registry.Get(name)
+		if !ok {
+			continue
+		}
+		setter, ok := engine.(transportSetter)
+		if !ok {
+			return fmt.Errorf("engine '%s' does not support transport configuration", name)
+		}
+		transport, err := configs[name].NewTransport()
+		if err != nil {
+			return fmt.Errorf("invalid transport configuration of engine '%s': %w", name, err)
+		}
+		setter.SetTransport(transport)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/kagi"
+)
+
+func TestConfigureEngineTransports(t *testing.T) {
+	engine, err := kagi.NewWithAPIKey("placeholder")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	registry := omniserp.NewRegistry()
+	registry.Register(engine)
+	registry.Register(&fakeEngine{name: "fake"})
+
+	// Configurations of unregistered engines are ignored
+	if err := ConfigureEngineTransports(registry, map[string]omniserp.TransportConfig{"kagi": {}, "exa": {}}); err != nil {
+		t.Errorf("ConfigureEngineTransports failed: %v", err)
+	}
+	if err := ConfigureEngineTransports(registry, map[string]omniserp.TransportConfig{"kagi": {CertFile: "client.pem"}}); err == nil {
+		t.Error("Expected an error for an invalid configuration")
+	}
+	if err := ConfigureEngineTransports(registry, map[string]omniserp.TransportConfig{"fake": {}}); err == nil {
+		t.Error("Expected an error for an engine without a settable transport")
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/plexusone/omniserp"
+)
+
+// EngineUsage is the API usage of an engine since the client was created
+type EngineUsage struct {
+	Engine string `json:"engine"`
+
+	// Requests is the number of successful requests
+	Requests int `json:"requests"`
+
+	// Credits is the credits spent: those reported by the engine, plus one
+	// per request for responses that do not report credits
+	Credits int `json:"credits"`
+
+	// Exact is true if the engine reported the credits of every request, so
+	// Credits is not an estimate
+	Exact bool `json:"exact"`
+}
+
+// usageRecorder counts successful requests and reported credits per engine.
+// The zero value is ready to use.
+type usageRecorder struct {
+	mu     sync.Mutex
+	byName map[string]*engineCredits
+}
+
+// engineCredits are the counters of one engine
+type engineCredits struct {
+	requests int
+	reported int // requests that reported credits
+	credits  int // credits reported
+}
+
+// record counts a successful request against engine
+func (r *usageRecorder) record(engine string, result *omniserp.SearchResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byName == nil {
+		r.byName = make(map[string]*engineCredits)
+	}
+	counts := r.byName[engine]
+	if counts == nil {
+		counts = &engineCredits{}
+		r.byName[engine] = counts
+	}
+	counts.requests++
+	if credits, ok := omniserp.CreditsUsed(result); ok {
+		counts.reported++
+		counts.credits += credits
+	}
+}
+
+// get returns the usage of one engine
+func (r *usageRecorder) get(engine string) EngineUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := EngineUsage{Engine: engine}
+	if counts := r.byName[engine]; counts != nil {
+		usage.Requests = counts.requests
+		usage.Credits = counts.credits + counts.requests - counts.reported
+		usage.Exact = counts.reported == counts.requests
+	}
+	return usage
+}
+
+// Usage returns the requests and credits spent per registered engine since
+// the client was created, keyed by engine name. Serper reports the credits
+// of each request, so its usage is exact; other engines are estimated at one
+// credit per successful request.
+func (c *Client) Usage() map[string]EngineUsage {
+	usage := make(map[string]EngineUsage)
+	for _, name := range c.registry.List() {
+		usage[name] = c.usage.get(name)
+	}
+	return usage
+}
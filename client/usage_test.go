@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestUsage(t *testing.T) {
+	credits := 2
+	c := newFakeClient(t, func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		result := organicResponse("https://go.dev")
+		if credits > 0 {
+			result.Data.(map[string]any)["credits"] = float64(credits)
+		}
+		return result, nil
+	})
+	ctx := context.Background()
+
+	result, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: "go"})
+	if err != nil {
+		t.Fatalf("SearchNormalized failed: %v", err)
+	}
+	if result.SearchMetadata.Credits != 2 {
+		t.Errorf("Expected 2 credits in the metadata, got %d", result.SearchMetadata.Credits)
+	}
+	if _, err := c.Search(ctx, omniserp.SearchParams{Query: "go"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := c.Usage()["serper"]; got != (EngineUsage{Engine: "serper", Requests: 2, Credits: 4, Exact: true}) {
+		t.Errorf("Unexpected usage: %+v", got)
+	}
+
+	// A response without credits is estimated at one credit
+	credits = 0
+	if _, err := c.Search(ctx, omniserp.SearchParams{Query: "go"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := c.Usage()["serper"]; got != (EngineUsage{Engine: "serper", Requests: 3, Credits: 5, Exact: false}) {
+		t.Errorf("Unexpected usage: %+v", got)
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	// validationQuery is the query of the one-result search that validates
+	// the credentials of engines that cannot report credits
+	validationQuery = "test"
+
+	// validationTimeout bounds the validation of Options.ValidateKeys
+	validationTimeout = 30 * time.Second
+)
+
+// InvalidKeysError is returned by ValidateKeys when engines reject their
+// credentials
+type InvalidKeysError struct {
+	// Engines holds the error of each engine that rejected its credentials
+	// by engine name
+	Engines map[string]error
+}
+
+// Error implements error
+func (e *InvalidKeysError) Error() string {
+	names := make([]string, 0, len(e.Engines))
+	for name := range e.Engines {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid API keys for %s", strings.Join(names, ", "))
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %v", name, e.Engines[name])
+	}
+	return b.String()
+}
+
+// ValidateKeys checks the credentials of every registered engine with a
+// minimal authenticated call, concurrently, so a bad key is found at
+// startup rather than on the first real search. Engines that implement
+// omniserp.CreditReporter are checked with their free account call; the
+// others run a one-result web search, which costs a request, and engines
+// without web search are skipped. It returns an *InvalidKeysError listing
+// the engines that answered 401 or 403. Other failures, such as timeouts
+// or rate limits, do not show that a key is invalid and are ignored.
+func (c *Client) ValidateKeys(ctx context.Context) error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		invalid = make(map[string]error)
+	)
+	for name, engine := range c.registry.GetAll() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := validateKey(ctx, engine); isAuthError(err) {
+				mu.Lock()
+				invalid[name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(invalid) > 0 {
+		return &InvalidKeysError{Engines: invalid}
+	}
+	return nil
+}
+
+// validateKey makes the minimal authenticated call of an engine
+func validateKey(ctx context.Context, engine omniserp.Engine) error {
+	if reporter, ok := engine.(omniserp.CreditReporter); ok {
+		_, err := reporter.Credits(ctx)
+		return err
+	}
+	if !slices.Contains(engine.GetSupportedTools(), OpSearch) {
+		return nil
+	}
+	_, err := engine.Search(ctx, omniserp.SearchParams{Query: validationQuery, NumResults: 1})
+	return err
+}
+
+// isAuthError reports whether an engine rejected its credentials
+func isAuthError(err error) bool {
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// creditEngine is a fake engine that reports credits, failing with err
+type creditEngine struct {
+	fakeEngine
+	err error
+}
+
+func (e *creditEngine) Credits(ctx context.Context) (*omniserp.Credits, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &omniserp.Credits{Engine: e.name, Remaining: 100}, nil
+}
+
+func TestValidateKeys(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		searches []omniserp.SearchParams
+	)
+	searchWith := func(err error) func(omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		return func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+			mu.Lock()
+			searches = append(searches, params)
+			mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			return organicResponse("https://go.dev"), nil
+		}
+	}
+	unsearched := func(params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+		t.Errorf("Unexpected search: %+v", params)
+		return nil, nil
+	}
+
+	registry := omniserp.NewRegistry()
+	registry.Register(&fakeEngine{name: "serper", tools: []string{OpSearch}, search: searchWith(nil)})
+	registry.Register(&creditEngine{fakeEngine: fakeEngine{name: "serpapi", tools: []string{OpSearch}, search: unsearched}})
+	c, err := NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+	if err := c.ValidateKeys(context.Background()); err != nil {
+		t.Fatalf("Expected valid keys, got %v", err)
+	}
+	if len(searches) != 1 || searches[0].Query != validationQuery || searches[0].NumResults != 1 {
+		t.Errorf("Expected one minimal search, got %+v", searches)
+	}
+
+	// Rejected credentials are reported; other failures are not
+	rejected := &omniserp.APIError{StatusCode: http.StatusUnauthorized, Body: `{"error":"Invalid API key"}`}
+	registry.Register(&creditEngine{fakeEngine: fakeEngine{name: "serpapi", tools: []string{OpSearch}, search: unsearched}, err: rejected})
+	registry.Register(&fakeEngine{name: "tavily", tools: []string{OpSearch}, search: searchWith(&omniserp.APIError{StatusCode: http.StatusForbidden})})
+	registry.Register(&fakeEngine{name: "exa", tools: []string{OpSearch}, search: searchWith(&omniserp.APIError{StatusCode: http.StatusTooManyRequests})})
+	registry.Register(&fakeEngine{name: "jina", tools: []string{OpScrapeWebpage}, search: unsearched})
+
+	err = c.ValidateKeys(context.Background())
+	var invalid *InvalidKeysError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected an InvalidKeysError, got %v", err)
+	}
+	if len(invalid.Engines) != 2 || !errors.Is(invalid.Engines["serpapi"], rejected) || invalid.Engines["tavily"] == nil {
+		t.Errorf("Expected serpapi and tavily to be invalid, got %v", invalid.Engines)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid API keys for serpapi, tavily\n") || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Errorf("Unexpected message: %s", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Package valueserp implements the omniserp.Engine interface for the
+// ValueSerp Google SERP API. Results are returned as decoded JSON and
+// normalized by omniserp.Normalizer.
+package valueserp
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/plexusone/omniserp/client/internal/trajectdata"
+)
+
+const (
+	baseURL    = "https://api.valueserp.com"
+	engineName = "valueserp"
+)
+
+// config describes ValueSerp, which supports image but not shopping searches
+var config = trajectdata.Config{
+	Name:    engineName,
+	Title:   "ValueSerp",
+	BaseURL: baseURL,
+	Tools: []string{
+		"google_search",
+		"google_search_news",
+		"google_search_images",
+		"google_search_places",
+	},
+}
+
+// Engine implements the omniserp.Engine interface for ValueSerp
+type Engine struct {
+	*trajectdata.Engine
+}
+
+// Extra declares the ValueSerp-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra = trajectdata.Extra
+
+// New creates a new ValueSerp engine from the VALUESERP_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("VALUESERP_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("VALUESERP_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new ValueSerp engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	engine, err := trajectdata.New(config, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{Engine: engine}, nil
+}
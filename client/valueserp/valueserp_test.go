@@ -0,0 +1,152 @@
+package valueserp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each search type and records the query
+// of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	fixtures := map[string][]byte{}
+	for searchType, name := range map[string]string{"": "search.json", "news": "news.json", "images": "images.json", "places": "places.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[searchType] = data
+	}
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Path != "/search" || query.Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"request_info":{"success":false,"message":"Invalid API key"}}`))
+			return
+		}
+		_, _ = w.Write(fixtures[query.Get("search_type")])
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{Query: "golang", Country: "US"}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if query.Get("q") != "golang" || query.Has("search_type") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	if second := normalized.OrganicResults[1]; second.Domain != "github.com" || second.Date != "Jan 2, 2026" || second.Engine != engineName {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if box := normalized.AnswerBox; box == nil || box.Answer != "Go is an open source programming language." || box.Link != "https://go.dev/" {
+		t.Errorf("Unexpected answer box: %+v", box)
+	}
+	if kg := normalized.KnowledgeGraph; kg == nil || kg.Source != "Wikipedia" {
+		t.Errorf("Unexpected knowledge graph: %+v", kg)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || normalized.PeopleAlsoAsk[0].Link != "https://go.dev/solutions/" {
+		t.Errorf("Unexpected people also ask: %+v", normalized.PeopleAlsoAsk)
+	}
+	if len(normalized.RelatedSearches) != 1 {
+		t.Errorf("Unexpected related searches: %+v", normalized.RelatedSearches)
+	}
+	meta := normalized.SearchMetadata
+	if meta.TotalResults != 2540000 || meta.CorrectedQuery != "golang" || meta.Country != "us" || meta.TimeTaken != 1.42 || meta.Credits != 1 {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang", Freshness: omniserp.FreshnessDay})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if query.Get("search_type") != "news" || query.Get("time_period") != "last_day" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 2 {
+		t.Fatalf("Expected 2 news results, got %d", len(normalized.NewsResults))
+	}
+	if first := normalized.NewsResults[0]; first.Source != "Example News" || first.Thumbnail == "" {
+		t.Errorf("Unexpected first news result: %+v", first)
+	}
+	if second := normalized.NewsResults[1]; second.Source != "news.example.com" {
+		t.Errorf("Expected the domain as the source, got %+v", second)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher", Freshness: omniserp.FreshnessDay})
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+	if query.Get("search_type") != "images" || query.Has("time_period") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeImages(result, "gopher")
+	if err != nil {
+		t.Fatalf("NormalizeImages failed: %v", err)
+	}
+	if len(normalized.ImageResults) != 1 || normalized.ImageResults[0].Width != 1200 || normalized.ImageResults[0].SourceURL != "https://go.dev/blog/gopher" {
+		t.Errorf("Unexpected image results: %+v", normalized.ImageResults)
+	}
+}
+
+func TestSearchPlaces(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchPlaces(context.Background(), omniserp.SearchParams{Query: "coffee", Location: "Austin,Texas,United States"})
+	if err != nil {
+		t.Fatalf("SearchPlaces failed: %v", err)
+	}
+	if query.Get("search_type") != "places" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizePlaces(result, "coffee")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	if len(normalized.PlaceResults) != 1 {
+		t.Fatalf("Expected 1 place, got %d", len(normalized.PlaceResults))
+	}
+	if place := normalized.PlaceResults[0]; place.Rating != 4.6 || place.Reviews != 812 || place.Longitude != -97.7446 || place.Type != "Coffee shop" {
+		t.Errorf("Unexpected place: %+v", place)
+	}
+}
@@ -0,0 +1,430 @@
+// Package wikipedia implements the omniserp.Engine interface for Wikipedia,
+// which needs no API key. Web searches use the MediaWiki search API for the
+// organic results, and the page summary of the best matching article, with
+// its Wikidata item, fills the knowledge graph. Wikipedia is free, so it is
+// suited as a fallback engine for encyclopedic queries.
+package wikipedia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	engineName    = "wikipedia"
+	engineVersion = "1.0.0"
+
+	apiPath     = "/w/api.php"
+	summaryPath = "/api/rest_v1/page/summary/"
+
+	// defaultLanguage is the wiki searched unless configured
+	defaultLanguage = "en"
+
+	// defaultUserAgent identifies the engine as the Wikimedia user agent
+	// policy requires
+	defaultUserAgent = "omniserp/" + engineVersion + " (https://github.com/plexusone/omniserp)"
+
+	// defaultNumResults is the number of results of searches without one,
+	// and maxNumResults the limit of the search API
+	defaultNumResults = 10
+	maxNumResults     = 500
+)
+
+// wikiLanguage matches the language codes of the Wikipedia subdomains, such
+// as "en", "simple", and "zh-min-nan"
+var wikiLanguage = regexp.MustCompile(`^[a-z][a-z0-9]{1,11}(-[a-z0-9]{1,8}){0,2}$`)
+
+// Engine implements the omniserp.Engine interface for Wikipedia. Results
+// are normalized by the engine and returned as the Data of each search
+// result as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	language  string
+	userAgent string
+	baseURL   string // replaces the wiki hosts, see SetBaseURL
+	client    *http.Client
+}
+
+// New creates a new Wikipedia engine for the wiki of the WIKIPEDIA_LANGUAGE
+// env var, "en" by default. WIKIPEDIA_USER_AGENT replaces the user agent,
+// such as with the contact address of a deployment. The error is returned
+// for an invalid language.
+func New() (*Engine, error) {
+	engine := &Engine{
+		language:  defaultLanguage,
+		userAgent: defaultUserAgent,
+		client:    &http.Client{},
+	}
+	if language := os.Getenv("WIKIPEDIA_LANGUAGE"); language != "" {
+		if err := engine.SetLanguage(language); err != nil {
+			return nil, fmt.Errorf("invalid WIKIPEDIA_LANGUAGE: %w", err)
+		}
+	}
+	if userAgent := os.Getenv("WIKIPEDIA_USER_AGENT"); userAgent != "" {
+		engine.userAgent = userAgent
+	}
+	return engine, nil
+}
+
+// SetLanguage sets the wiki searched when SearchParams.Language is empty,
+// such as "de" for de.wikipedia.org
+func (e *Engine) SetLanguage(language string) error {
+	language = strings.ToLower(language)
+	if !wikiLanguage.MatchString(language) {
+		return fmt.Errorf("%q is not a Wikipedia language code", language)
+	}
+	e.language = language
+	return nil
+}
+
+// SetBaseURL sends all requests to one host instead of the wikis, for
+// testing or a proxy. The language of the wiki is the first path segment,
+// such as <baseURL>/de/w/api.php.
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The language selects
+// the wiki, and Verbatim disables the rewriting of queries without results.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamLanguage,
+		omniserp.ParamVerbatim,
+	}
+}
+
+// wiki returns the language of the wiki searched with params: the primary
+// subtag of the language, such as "pt" for "pt-BR", or the engine language
+func (e *Engine) wiki(params omniserp.SearchParams) string {
+	language, _, _ := strings.Cut(strings.ToLower(params.Language), "_")
+	if wikiLanguage.MatchString(language) {
+		// Region subtags are not wikis, but variants such as zh-yue are
+		if primary, region, ok := strings.Cut(language, "-"); ok && len(region) == 2 {
+			language = primary
+		}
+		return language
+	}
+	return e.language
+}
+
+// siteURL returns the base URL of the wiki of a language
+func (e *Engine) siteURL(language string) string {
+	if e.baseURL != "" {
+		return e.baseURL + "/" + language
+	}
+	return "https://" + language + ".wikipedia.org"
+}
+
+// articleURL returns the link of an article of the wiki of a language
+func articleURL(language, title string) string {
+	return "https://" + language + ".wikipedia.org/wiki/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+}
+
+// get performs a GET request and returns the response body
+func (e *Engine) get(ctx context.Context, reqURL string) ([]byte, *omniserp.ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", e.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	// #nosec G704 -- request to Wikipedia or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, meta, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+	return body, meta, nil
+}
+
+// searchResponse is the JSON response of the search API
+type searchResponse struct {
+	Error *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+	Query struct {
+		SearchInfo struct {
+			TotalHits      int64  `json:"totalhits"`
+			Suggestion     string `json:"suggestion"`
+			RewrittenQuery string `json:"rewrittenquery"`
+		} `json:"searchinfo"`
+		Search []struct {
+			Title     string `json:"title"`
+			PageID    int64  `json:"pageid"`
+			Snippet   string `json:"snippet"`
+			Timestamp string `json:"timestamp"`
+		} `json:"search"`
+	} `json:"query"`
+}
+
+// summary is the JSON response of the page summary API
+type summary struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Extract      string `json:"extract"`
+	WikibaseItem string `json:"wikibase_item"`
+	Thumbnail    *struct {
+		Source string `json:"source"`
+	} `json:"thumbnail"`
+	ContentURLs struct {
+		Desktop struct {
+			Page string `json:"page"`
+		} `json:"desktop"`
+	} `json:"content_urls"`
+}
+
+// pageSize returns the number of results per page
+func pageSize(params omniserp.SearchParams) int {
+	if params.NumResults > 0 {
+		return min(params.NumResults, maxNumResults)
+	}
+	return defaultNumResults
+}
+
+// searchParams converts SearchParams to search API query parameters
+func searchParams(params omniserp.SearchParams) url.Values {
+	size := pageSize(params)
+	q := url.Values{}
+	q.Set("action", "query")
+	q.Set("list", "search")
+	q.Set("srsearch", params.Query)
+	q.Set("srlimit", strconv.Itoa(size))
+	q.Set("srprop", "snippet|timestamp")
+	q.Set("srinfo", "totalhits|suggestion|rewrittenquery")
+	q.Set("format", "json")
+	q.Set("formatversion", "2")
+	if params.Page > 1 {
+		q.Set("sroffset", strconv.Itoa((params.Page-1)*size))
+	}
+	if !params.Verbatim {
+		q.Set("srenablerewrites", "1")
+	}
+	return q
+}
+
+// Search searches the articles of the wiki. The summary of the first
+// result of the first page fills the knowledge graph; a summary that fails
+// to load is left out.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	language := e.wiki(params)
+	body, meta, err := e.get(ctx, e.siteURL(language)+apiPath+"?"+searchParams(params).Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed searchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	// The API reports errors in the body of successful responses
+	if parsed.Error != nil {
+		return nil, fmt.Errorf("wikipedia API error: %s: %s", parsed.Error.Code, parsed.Error.Info)
+	}
+
+	normalized := normalize(&parsed, params, language)
+	if len(normalized.OrganicResults) > 0 && params.Page <= 1 {
+		if s, err := e.summary(ctx, language, normalized.OrganicResults[0].Title); err == nil {
+			normalized.KnowledgeGraph = knowledgeGraph(s, language)
+		}
+	}
+
+	return &omniserp.SearchResult{
+		Data:     normalized,
+		Raw:      string(body),
+		Response: meta,
+	}, nil
+}
+
+// summary loads the page summary of an article
+func (e *Engine) summary(ctx context.Context, language, title string) (*summary, error) {
+	body, _, err := e.get(ctx, e.siteURL(language)+summaryPath+url.PathEscape(strings.ReplaceAll(title, " ", "_")))
+	if err != nil {
+		return nil, err
+	}
+	var s summary
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal summary: %w", err)
+	}
+	return &s, nil
+}
+
+// knowledgeGraph converts a page summary to a knowledge graph, or returns
+// nil for disambiguation pages and summaries without an extract
+func knowledgeGraph(s *summary, language string) *omniserp.KnowledgeGraph {
+	if s.Type == "disambiguation" || s.Extract == "" {
+		return nil
+	}
+	graph := &omniserp.KnowledgeGraph{
+		Title:       s.Title,
+		Type:        s.Description,
+		Description: s.Extract,
+		Source:      "Wikipedia",
+		Attributes:  map[string]string{"source_url": s.ContentURLs.Desktop.Page},
+	}
+	if graph.Attributes["source_url"] == "" {
+		graph.Attributes["source_url"] = articleURL(language, s.Title)
+	}
+	if s.Thumbnail != nil {
+		graph.ImageURL = s.Thumbnail.Source
+	}
+	if s.WikibaseItem != "" {
+		graph.Attributes["wikidata_id"] = s.WikibaseItem
+		graph.Attributes["wikidata_url"] = "https://www.wikidata.org/wiki/" + s.WikibaseItem
+	}
+	return graph
+}
+
+// normalize converts a search response to a normalized result
+func normalize(resp *searchResponse, params omniserp.SearchParams, language string) *omniserp.NormalizedSearchResult {
+	info := resp.Query.SearchInfo
+	normalized := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:         engineName,
+			Query:          params.Query,
+			Language:       language,
+			TotalResults:   info.TotalHits,
+			CorrectedQuery: info.RewrittenQuery,
+			SuggestedQuery: info.Suggestion,
+		},
+	}
+
+	offset := (max(params.Page, 1) - 1) * pageSize(params)
+	for i, r := range resp.Query.Search {
+		link := articleURL(language, r.Title)
+		result := omniserp.OrganicResult{
+			Position: offset + i + 1,
+			Title:    r.Title,
+			Link:     link,
+			URL:      link,
+			Snippet:  cleanSnippet(r.Snippet),
+			Domain:   language + ".wikipedia.org",
+		}
+		if len(r.Timestamp) >= len(time.DateOnly) {
+			result.Date = r.Timestamp[:len(time.DateOnly)]
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, result)
+	}
+	return normalized
+}
+
+// tags matches the HTML tags of search snippets, which highlight matches
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// cleanSnippet removes the markup of a search snippet
+func cleanSnippet(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tags.ReplaceAllString(s, ""))), " ")
+}
+
+// SearchNews performs a news search (not supported by Wikipedia)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Wikipedia")
+}
+
+// SearchImages performs an image search (not supported by Wikipedia)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by Wikipedia")
+}
+
+// SearchVideos performs a video search (not supported by Wikipedia)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by Wikipedia")
+}
+
+// SearchPlaces performs a places search (not supported by Wikipedia)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Wikipedia")
+}
+
+// SearchMaps performs a maps search (not supported by Wikipedia)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by Wikipedia")
+}
+
+// SearchReviews performs a reviews search (not supported by Wikipedia)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Wikipedia")
+}
+
+// SearchShopping performs a shopping search (not supported by Wikipedia)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Wikipedia")
+}
+
+// SearchScholar performs a scholar search (not supported by Wikipedia)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Wikipedia")
+}
+
+// SearchLens performs a visual search (not supported by Wikipedia)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Wikipedia")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Wikipedia)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Wikipedia")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Wikipedia)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Wikipedia")
+}
@@ -0,0 +1,145 @@
+package wikipedia
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the search and summary fixtures at <url>/<language>
+// and records the requests
+func newTestServer(t *testing.T) (*Engine, *[]*http.Request) {
+	t.Helper()
+	search, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	summary, err := os.ReadFile(filepath.Join("testdata", "summary.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Header.Get("User-Agent") != defaultUserAgent {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/en/w/api.php", "/de/w/api.php":
+			if r.URL.Query().Get("srsearch") == "broken" {
+				_, _ = w.Write([]byte(`{"error":{"code":"srsearch-text-disabled","info":"text search is disabled"}}`))
+				return
+			}
+			_, _ = w.Write(search)
+		case "/en/api/rest_v1/page/summary/Go_(programming_language)":
+			_, _ = w.Write(summary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &requests
+}
+
+func TestSearch(t *testing.T) {
+	engine, requests := newTestServer(t)
+
+	result, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", NumResults: 2})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	q := (*requests)[0].URL.Query()
+	if q.Get("srsearch") != "golang" || q.Get("srlimit") != "2" || q.Get("srenablerewrites") != "1" || q.Get("sroffset") != "" {
+		t.Errorf("Unexpected search parameters: %v", q)
+	}
+
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	meta := normalized.SearchMetadata
+	if meta.Engine != "wikipedia" || meta.Language != "en" || meta.TotalResults != 4211 || meta.SuggestedQuery != "golang programing" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	first := normalized.OrganicResults[0]
+	if first.Position != 1 || first.Link != "https://en.wikipedia.org/wiki/Go_%28programming_language%29" || first.Domain != "en.wikipedia.org" || first.Date != "2026-10-02" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if want := "Go is a high-level general purpose programming language that is statically typed & compiled."; first.Snippet != want {
+		t.Errorf("Expected snippet %q, got %q", want, first.Snippet)
+	}
+
+	graph := normalized.KnowledgeGraph
+	if graph == nil {
+		t.Fatal("Expected a knowledge graph")
+	}
+	if graph.Title != "Go (programming language)" || graph.Type != "Programming language" || graph.Source != "Wikipedia" {
+		t.Errorf("Unexpected knowledge graph: %+v", graph)
+	}
+	if graph.ImageURL == "" || graph.Attributes["wikidata_id"] != "Q37227" || graph.Attributes["source_url"] != "https://en.wikipedia.org/wiki/Go_(programming_language)" {
+		t.Errorf("Unexpected knowledge graph attributes: %+v", graph)
+	}
+}
+
+func TestSearchPagesAndLanguages(t *testing.T) {
+	engine, requests := newTestServer(t)
+
+	result, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", Language: "de-AT", Page: 2, NumResults: 2, Verbatim: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected no summary request past the first page, got %d requests", len(*requests))
+	}
+	r := (*requests)[0]
+	if r.URL.Path != "/de/w/api.php" || r.URL.Query().Get("sroffset") != "2" || r.URL.Query().Has("srenablerewrites") {
+		t.Errorf("Unexpected request: %s", r.URL)
+	}
+	normalized := result.Data.(*omniserp.NormalizedSearchResult)
+	if normalized.KnowledgeGraph != nil || normalized.OrganicResults[0].Position != 3 {
+		t.Errorf("Unexpected second page: %+v", normalized)
+	}
+	if link := normalized.OrganicResults[1].Link; link != "https://de.wikipedia.org/wiki/Goroutine" {
+		t.Errorf("Expected a German article link, got %s", link)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "broken"}); err == nil {
+		t.Error("Expected the API error to be returned")
+	}
+
+	engine.userAgent = "Go-http-client/1.1"
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a 403 APIError, got %v", err)
+	}
+
+	if err := engine.SetLanguage("en.evil.example/"); err == nil {
+		t.Error("Expected an invalid language to be rejected")
+	}
+	t.Setenv("WIKIPEDIA_LANGUAGE", "../")
+	if _, err := New(); err == nil {
+		t.Error("Expected an invalid WIKIPEDIA_LANGUAGE to be rejected")
+	}
+}
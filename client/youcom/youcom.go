@@ -0,0 +1,329 @@
+// Package youcom implements the omniserp.Engine interface for the You.com
+// Search API, which returns web and news results from one request.
+package youcom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://ydc-index.io"
+	engineName    = "youcom"
+	engineVersion = "1.0.0"
+	searchPath    = "/v1/search"
+
+	// maxOffset is the last page offset accepted by the API
+	maxOffset = 9
+)
+
+// Engine implements the omniserp.Engine interface for You.com. Results are
+// normalized by the engine and returned as the Data of each search result as
+// a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new You.com engine from the YDC_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("YDC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("YDC_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new You.com engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_news",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. The API has no location
+// parameter and returns at most 10 pages.
+func (e *Engine) SupportedParams(operation string) []string {
+	return []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+		omniserp.ParamFreshness,
+		omniserp.ParamSafeSearch,
+	}
+}
+
+// response is the JSON response of the Search API
+type response struct {
+	Results struct {
+		Web []struct {
+			URL          string   `json:"url"`
+			Title        string   `json:"title"`
+			Description  string   `json:"description"`
+			Snippets     []string `json:"snippets"`
+			PageAge      string   `json:"page_age"`
+			ThumbnailURL string   `json:"thumbnail_url"`
+		} `json:"web"`
+		News []struct {
+			URL          string `json:"url"`
+			Title        string `json:"title"`
+			Description  string `json:"description"`
+			PageAge      string `json:"page_age"`
+			ThumbnailURL string `json:"thumbnail_url"`
+		} `json:"news"`
+	} `json:"results"`
+	Metadata struct {
+		Latency float64 `json:"latency"`
+	} `json:"metadata"`
+}
+
+// freshness returns the freshness of the API; there is no hourly freshness
+func freshness(f omniserp.Freshness) string {
+	switch f {
+	case omniserp.FreshnessHour, omniserp.FreshnessDay:
+		return "day"
+	case omniserp.FreshnessWeek, omniserp.FreshnessMonth, omniserp.FreshnessYear:
+		return string(f)
+	}
+	return ""
+}
+
+// buildParams converts SearchParams to Search API query parameters. The
+// offset counts pages of count results.
+func buildParams(params omniserp.SearchParams) url.Values {
+	q := url.Values{}
+	q.Set("query", params.Query)
+	if params.Language != "" {
+		q.Set("language", strings.ToUpper(params.Language))
+	}
+	if params.Country != "" {
+		q.Set("country", strings.ToUpper(params.Country))
+	}
+	if params.NumResults > 0 {
+		q.Set("count", strconv.Itoa(params.NumResults))
+	}
+	if params.Page > 1 {
+		q.Set("offset", strconv.Itoa(min(params.Page-1, maxOffset)))
+	}
+	if f := freshness(params.Freshness); f != "" {
+		q.Set("freshness", f)
+	}
+	if params.SafeSearch {
+		q.Set("safesearch", "strict")
+	}
+	return q
+}
+
+// search performs a Search API request and parses the response
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams) (*response, string, *omniserp.ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+searchPath+"?"+buildParams(params).Encode(), nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-API-Key", e.apiKey)
+
+	start := time.Now()
+	// #nosec G704 -- request to the You.com API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var parsed response
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &parsed, string(body), meta, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, resp *response) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:    engineName,
+			Query:     params.Query,
+			Language:  params.Language,
+			Country:   params.Country,
+			TimeTaken: resp.Metadata.Latency,
+		},
+	}
+}
+
+// hostname returns the host of a link without the www. prefix
+func hostname(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a general web search. The snippet of a result is its
+// description, or its first snippet without one.
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.Results.Web {
+		snippet := r.Description
+		if snippet == "" && len(r.Snippets) > 0 {
+			snippet = r.Snippets[0]
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: i + 1,
+			Title:    r.Title,
+			Link:     r.URL,
+			URL:      r.URL,
+			Snippet:  snippet,
+			Domain:   hostname(r.URL),
+			Date:     r.PageAge,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search, returning the news results of the
+// Search API
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	resp, raw, meta, err := e.search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, resp)
+	for i, r := range resp.Results.News {
+		normalized.NewsResults = append(normalized.NewsResults, omniserp.NewsResult{
+			Position:  i + 1,
+			Title:     r.Title,
+			Link:      r.URL,
+			Source:    hostname(r.URL),
+			Date:      r.PageAge,
+			Snippet:   r.Description,
+			Thumbnail: r.ThumbnailURL,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchImages performs an image search (not supported by You.com)
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_images is not supported by You.com")
+}
+
+// SearchVideos performs a video search (not supported by You.com)
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_videos is not supported by You.com")
+}
+
+// SearchPlaces performs a places search (not supported by You.com)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by You.com")
+}
+
+// SearchMaps performs a maps search (not supported by You.com)
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_maps is not supported by You.com")
+}
+
+// SearchReviews performs a reviews search (not supported by You.com)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by You.com")
+}
+
+// SearchShopping performs a shopping search (not supported by You.com)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by You.com")
+}
+
+// SearchScholar performs a scholar search (not supported by You.com)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by You.com")
+}
+
+// SearchLens performs a visual search (not supported by You.com)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by You.com")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by You.com)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by You.com")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by You.com)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by You.com")
+}
@@ -0,0 +1,145 @@
+package youcom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of the Search API and records the query of
+// the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "search.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"detail":"Invalid API key"}`))
+			return
+		}
+		if r.URL.Path != searchPath {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Language:   "en",
+		Country:    "us",
+		NumResults: 2,
+		Page:       3,
+		Freshness:  omniserp.FreshnessHour,
+		SafeSearch: true,
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"query":      "golang",
+		"language":   "EN",
+		"country":    "US",
+		"count":      "2",
+		"offset":     "2",
+		"freshness":  "day",
+		"safesearch": "strict",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	second := normalized.OrganicResults[1]
+	if second.Position != 2 || second.Domain != "github.com" || second.Engine != engineName {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if !strings.HasPrefix(second.Snippet, "The Go programming language.") {
+		t.Errorf("Expected the first snippet without a description, got %q", second.Snippet)
+	}
+	if len(normalized.NewsResults) != 0 {
+		t.Errorf("Expected no news results in a web search, got %d", len(normalized.NewsResults))
+	}
+	if normalized.SearchMetadata.TimeTaken != 0.318 {
+		t.Errorf("Expected the latency in seconds, got %v", normalized.SearchMetadata.TimeTaken)
+	}
+}
+
+func TestSearchOffset(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang", Page: 20}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := query.Get("offset"); got != "9" {
+		t.Errorf("Expected offset to be capped at 9, got %q", got)
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	result, err := engine.SearchNews(context.Background(), omniserp.SearchParams{Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeNews(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 1 || len(normalized.OrganicResults) != 0 {
+		t.Fatalf("Expected only 1 news result, got %+v", normalized)
+	}
+	news := normalized.NewsResults[0]
+	if news.Source != "example.org" || news.Date != "2026-02-10T00:00:00" || news.Thumbnail != "https://www.example.org/gopher.png" {
+		t.Errorf("Unexpected news result: %+v", news)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || !strings.Contains(apiErr.Body, "Invalid API key") {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,558 @@
+// Package zenserp implements the omniserp.Engine interface for the Zenserp
+// Google SERP API. Web, image, video, and maps searches are the Google
+// search types selected by tbm, and their results are normalized by the
+// engine.
+package zenserp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+const (
+	baseURL       = "https://app.zenserp.com"
+	engineName    = "zenserp"
+	engineVersion = "1.0.0"
+	searchPath    = "/api/v2/search"
+
+	// defaultNum is the number of results per page when none is set, and
+	// maxNum the largest number the API returns per request
+	defaultNum = 10
+	maxNum     = 100
+)
+
+// Google search types, by the tbm parameter
+const (
+	tbmImages = "isch"
+	tbmVideos = "vid"
+	tbmMaps   = "lcl"
+)
+
+// Engine implements the omniserp.Engine interface for Zenserp. Results are
+// normalized by the engine and returned as the Data of each search result
+// as a *omniserp.NormalizedSearchResult.
+type Engine struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Zenserp engine from the ZENSERP_API_KEY env var
+func New() (*Engine, error) {
+	apiKey := os.Getenv("ZENSERP_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ZENSERP_API_KEY environment variable is required")
+	}
+	return NewWithAPIKey(apiKey)
+}
+
+// NewWithAPIKey creates a new Zenserp engine with the provided API key
+func NewWithAPIKey(apiKey string) (*Engine, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	return &Engine{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}, nil
+}
+
+// SetBaseURL overrides the API base URL, e.g. to route requests through a
+// CORS-friendly proxy when running in a browser
+func (e *Engine) SetBaseURL(u string) {
+	e.baseURL = strings.TrimSuffix(u, "/")
+}
+
+// SetRequestSigner signs every request of the engine with signer, for
+// APIs and gateways that require signed requests. A nil signer stops
+// signing.
+func (e *Engine) SetRequestSigner(signer omniserp.RequestSigner) {
+	omniserp.SignRequests(e.client, signer)
+}
+
+// SetTransport sets the HTTP transport of the engine, such as one with a
+// client certificate from omniserp.TransportConfig. A request signer is
+// kept.
+func (e *Engine) SetTransport(rt http.RoundTripper) {
+	omniserp.SetTransport(e.client, rt)
+}
+
+// GetName returns the engine name
+func (e *Engine) GetName() string {
+	return engineName
+}
+
+// GetVersion returns the engine version
+func (e *Engine) GetVersion() string {
+	return engineVersion
+}
+
+// GetSupportedTools returns the supported tools
+func (e *Engine) GetSupportedTools() []string {
+	return []string{
+		"google_search",
+		"google_search_images",
+		"google_search_videos",
+		"google_search_maps",
+	}
+}
+
+// SupportedParams implements omniserp.ParamReporter. Freshness applies to
+// web and video searches only.
+func (e *Engine) SupportedParams(operation string) []string {
+	params := []string{
+		omniserp.ParamQuery,
+		omniserp.ParamLocation,
+		omniserp.ParamLanguage,
+		omniserp.ParamCountry,
+		omniserp.ParamNumResults,
+		omniserp.ParamPage,
+	}
+	switch operation {
+	case "google_search", "google_search_videos":
+		params = append(params, omniserp.ParamFreshness)
+	}
+	return params
+}
+
+// Extra declares the Zenserp-specific search parameters accepted in
+// omniserp.SearchParams.Extra
+type Extra struct {
+	Device       string `extra:"device" description:"Device to emulate: desktop or mobile"`
+	SearchEngine string `extra:"search_engine" description:"Google domain to search, such as google.de"`
+}
+
+// ExtraParams implements omniserp.ExtraParamReporter
+func (e *Engine) ExtraParams() []omniserp.ExtraParam {
+	return omniserp.DescribeExtraParams(Extra{})
+}
+
+// timeRanges maps freshness to Google's qdr time ranges
+var timeRanges = map[omniserp.Freshness]string{
+	omniserp.FreshnessHour:  "qdr:h",
+	omniserp.FreshnessDay:   "qdr:d",
+	omniserp.FreshnessWeek:  "qdr:w",
+	omniserp.FreshnessMonth: "qdr:m",
+	omniserp.FreshnessYear:  "qdr:y",
+}
+
+// buildParams converts SearchParams to Zenserp query parameters for a
+// search type, which is empty for web searches. Pages are offsets in
+// results.
+func (e *Engine) buildParams(params omniserp.SearchParams, tbm string) url.Values {
+	q := url.Values{}
+	q.Set("q", params.Query)
+	if tbm != "" {
+		q.Set("tbm", tbm)
+	}
+
+	if params.Location != "" {
+		q.Set("location", params.Location)
+	}
+	if params.Country != "" {
+		q.Set("gl", strings.ToUpper(params.Country))
+	}
+	if params.Language != "" {
+		q.Set("hl", params.Language)
+	}
+	if params.GoogleDomain != "" {
+		q.Set("search_engine", params.GoogleDomain)
+	}
+	num := defaultNum
+	if params.NumResults > 0 {
+		num = min(params.NumResults, maxNum)
+		q.Set("num", strconv.Itoa(num))
+	}
+	if params.Page > 1 {
+		q.Set("start", strconv.Itoa((params.Page-1)*num))
+	}
+	if tbs, ok := timeRanges[params.Freshness]; ok && (tbm == "" || tbm == tbmVideos) {
+		q.Set("tbs", tbs)
+	}
+	for name, value := range omniserp.ExtraQuery(params, e.ExtraParams()) {
+		q.Set(name, value)
+	}
+	return q
+}
+
+// response is the JSON response of a search; the fields in use depend on
+// the search type
+type response struct {
+	NumberOfResults int64 `json:"number_of_results"`
+
+	// Organic mixes results with the people also ask questions
+	Organic         []organic       `json:"organic"`
+	AnswerBox       *answerBox      `json:"answer_box"`
+	KnowledgeGraph  *knowledgeGraph `json:"knowledge_graph"`
+	RelatedSearches json.RawMessage `json:"related_searches"`
+
+	ImageResults []image `json:"image_results"`
+	VideoResults []video `json:"video_results"`
+	MapsResults  []place `json:"maps_results"`
+	LocalResults []place `json:"local_results"`
+	Error        string  `json:"error"`
+}
+
+type organic struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Date        string `json:"date"`
+	Questions   []struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+		Title    string `json:"title"`
+		URL      string `json:"url"`
+	} `json:"questions"`
+}
+
+type answerBox struct {
+	Title       string `json:"title"`
+	Answer      string `json:"answer"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+type knowledgeGraph struct {
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+	Image       string `json:"image"`
+}
+
+type image struct {
+	Title     string `json:"title"`
+	SourceURL string `json:"sourceUrl"`
+	Thumbnail string `json:"thumbnail"`
+	Link      string `json:"link"`
+	Source    string `json:"source"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+type video struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Channel     string `json:"channel"`
+	Duration    string `json:"duration"`
+	Date        string `json:"date"`
+	Thumbnail   string `json:"thumbnail"`
+}
+
+type place struct {
+	Title     string  `json:"title"`
+	CID       string  `json:"cid"`
+	Address   string  `json:"address"`
+	Phone     string  `json:"phone"`
+	Website   string  `json:"website"`
+	Rating    float64 `json:"rating"`
+	Reviews   int     `json:"reviews"`
+	Type      string  `json:"type"`
+	Hours     string  `json:"hours"`
+	Price     string  `json:"price"`
+	Thumbnail string  `json:"thumbnail"`
+	GPS       *struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"gps_coordinates"`
+}
+
+// search performs a request of a search type
+func (e *Engine) search(ctx context.Context, params omniserp.SearchParams, tbm string) (*response, string, *omniserp.ResponseMeta, error) {
+	reqURL := e.baseURL + searchPath + "?" + e.buildParams(params, tbm).Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("apikey", e.apiKey)
+
+	start := time.Now()
+	// #nosec G704 -- request to the Zenserp API or a configured proxy
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	meta := omniserp.NewResponseMeta(resp, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, &omniserp.APIError{StatusCode: resp.StatusCode, Body: string(body), Response: meta}
+	}
+
+	var result response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if result.Error != "" {
+		return nil, "", nil, fmt.Errorf("zenserp error: %s", result.Error)
+	}
+	return &result, string(body), meta, nil
+}
+
+// newNormalized returns an empty normalized result for params
+func newNormalized(params omniserp.SearchParams, result *response) *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{
+			Engine:       engineName,
+			Query:        params.Query,
+			Location:     params.Location,
+			Language:     params.Language,
+			Country:      params.Country,
+			TotalResults: result.NumberOfResults,
+		},
+	}
+}
+
+// domain returns the host of a URL without www.
+func domain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Search performs a general web search
+func (e *Engine) Search(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, "")
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, o := range result.Organic {
+		for _, q := range o.Questions {
+			normalized.PeopleAlsoAsk = append(normalized.PeopleAlsoAsk, omniserp.PeopleAlsoAsk{
+				Question: q.Question,
+				Answer:   q.Answer,
+				Title:    q.Title,
+				Link:     q.URL,
+				Source:   domain(q.URL),
+			})
+		}
+		if o.URL == "" {
+			continue
+		}
+		normalized.OrganicResults = append(normalized.OrganicResults, omniserp.OrganicResult{
+			Position: len(normalized.OrganicResults) + 1,
+			Title:    o.Title,
+			Link:     o.URL,
+			URL:      o.URL,
+			Snippet:  o.Description,
+			Domain:   domain(o.URL),
+			Date:     o.Date,
+		})
+	}
+	if box := result.AnswerBox; box != nil {
+		normalized.AnswerBox = &omniserp.AnswerBox{
+			Title:   box.Title,
+			Answer:  box.Answer,
+			Snippet: box.Description,
+			Link:    box.URL,
+			Source:  domain(box.URL),
+		}
+	}
+	if kg := result.KnowledgeGraph; kg != nil {
+		normalized.KnowledgeGraph = &omniserp.KnowledgeGraph{
+			Title:       kg.Title,
+			Type:        kg.Type,
+			Description: kg.Description,
+			Source:      kg.Source,
+			ImageURL:    kg.Image,
+		}
+	}
+	normalized.RelatedSearches = relatedSearches(result.RelatedSearches)
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// relatedSearches parses related searches given as strings or as objects
+// with a title
+func relatedSearches(data json.RawMessage) []omniserp.RelatedSearch {
+	var queries []string
+	if json.Unmarshal(data, &queries) != nil {
+		var items []struct {
+			Title string `json:"title"`
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal(data, &items)
+		for _, item := range items {
+			queries = append(queries, cmp.Or(item.Query, item.Title))
+		}
+	}
+	var related []omniserp.RelatedSearch
+	for _, query := range queries {
+		if query != "" {
+			related = append(related, omniserp.RelatedSearch{Query: query})
+		}
+	}
+	return related
+}
+
+// SearchImages performs an image search
+func (e *Engine) SearchImages(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmImages)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	for _, img := range result.ImageResults {
+		normalized.ImageResults = append(normalized.ImageResults, omniserp.ImageResult{
+			Position:  len(normalized.ImageResults) + 1,
+			Title:     img.Title,
+			ImageURL:  cmp.Or(img.SourceURL, img.Thumbnail),
+			Thumbnail: img.Thumbnail,
+			Source:    cmp.Or(img.Source, domain(img.Link)),
+			SourceURL: img.Link,
+			Width:     img.Width,
+			Height:    img.Height,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchVideos performs a video search
+func (e *Engine) SearchVideos(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmVideos)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	videos := result.VideoResults
+	if len(videos) == 0 {
+		// Video searches may return the videos as organic results
+		for _, o := range result.Organic {
+			if o.URL != "" {
+				videos = append(videos, video{Title: o.Title, URL: o.URL, Description: o.Description, Date: o.Date})
+			}
+		}
+	}
+	for _, v := range videos {
+		normalized.VideoResults = append(normalized.VideoResults, omniserp.VideoResult{
+			Position:  len(normalized.VideoResults) + 1,
+			Title:     v.Title,
+			Link:      v.URL,
+			Channel:   v.Channel,
+			Platform:  platform(v.URL),
+			Duration:  v.Duration,
+			Date:      v.Date,
+			Thumbnail: v.Thumbnail,
+			Snippet:   v.Description,
+		})
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// platform returns the video platform of a URL, such as "youtube"
+func platform(link string) string {
+	host := domain(link)
+	switch {
+	case host == "youtu.be" || strings.HasSuffix(host, "youtube.com"):
+		return "youtube"
+	case strings.HasSuffix(host, "vimeo.com"):
+		return "vimeo"
+	}
+	name, _, _ := strings.Cut(host, ".")
+	return name
+}
+
+// SearchMaps performs a maps search of Google's local results
+func (e *Engine) SearchMaps(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	result, raw, meta, err := e.search(ctx, params, tbmMaps)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := newNormalized(params, result)
+	places := result.MapsResults
+	if len(places) == 0 {
+		places = result.LocalResults
+	}
+	for _, p := range places {
+		place := omniserp.PlaceResult{
+			Position:  len(normalized.PlaceResults) + 1,
+			Title:     p.Title,
+			DataID:    p.CID,
+			Address:   p.Address,
+			Phone:     p.Phone,
+			Website:   p.Website,
+			Rating:    p.Rating,
+			Reviews:   p.Reviews,
+			Type:      p.Type,
+			Hours:     p.Hours,
+			Price:     p.Price,
+			Thumbnail: p.Thumbnail,
+		}
+		if p.GPS != nil {
+			place.Latitude, place.Longitude = p.GPS.Latitude, p.GPS.Longitude
+		}
+		place.OpeningHours, _ = omniserp.ParseHours(place.Hours)
+		normalized.PlaceResults = append(normalized.PlaceResults, place)
+	}
+
+	return &omniserp.SearchResult{Data: normalized, Raw: raw, Response: meta}, nil
+}
+
+// SearchNews performs a news search (not supported by Zenserp)
+func (e *Engine) SearchNews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_news is not supported by Zenserp")
+}
+
+// SearchPlaces performs a places search (not supported by Zenserp)
+func (e *Engine) SearchPlaces(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_places is not supported by Zenserp")
+}
+
+// SearchReviews performs a reviews search (not supported by Zenserp)
+func (e *Engine) SearchReviews(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_reviews is not supported by Zenserp")
+}
+
+// SearchShopping performs a shopping search (not supported by Zenserp)
+func (e *Engine) SearchShopping(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_shopping is not supported by Zenserp")
+}
+
+// SearchScholar performs a scholar search (not supported by Zenserp)
+func (e *Engine) SearchScholar(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_scholar is not supported by Zenserp")
+}
+
+// SearchLens performs a visual search (not supported by Zenserp)
+func (e *Engine) SearchLens(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_lens is not supported by Zenserp")
+}
+
+// SearchAutocomplete gets search suggestions (not supported by Zenserp)
+func (e *Engine) SearchAutocomplete(ctx context.Context, params omniserp.SearchParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("google_search_autocomplete is not supported by Zenserp")
+}
+
+// ScrapeWebpage scrapes a webpage (not supported by Zenserp)
+func (e *Engine) ScrapeWebpage(ctx context.Context, params omniserp.ScrapeParams) (*omniserp.SearchResult, error) {
+	return nil, fmt.Errorf("webpage_scrape is not supported by Zenserp")
+}
@@ -0,0 +1,197 @@
+package zenserp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// newTestServer serves the fixture of each search type and records the query
+// of the last request
+func newTestServer(t *testing.T) (*Engine, *url.Values) {
+	t.Helper()
+	fixtures := map[string][]byte{}
+	for tbm, name := range map[string]string{"": "search.json", tbmImages: "images.json", tbmVideos: "videos.json", tbmMaps: "maps.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[tbm] = data
+	}
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Path != searchPath || r.Header.Get("apikey") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"Invalid API key"}`))
+			return
+		}
+		_, _ = w.Write(fixtures[query.Get("tbm")])
+	}))
+	t.Cleanup(srv.Close)
+
+	engine, err := NewWithAPIKey("test-key")
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/")
+	return engine, &query
+}
+
+// normalizedOf returns the normalized result of a search
+func normalizedOf(t *testing.T, result *omniserp.SearchResult) *omniserp.NormalizedSearchResult {
+	t.Helper()
+	normalized, ok := result.Data.(*omniserp.NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected normalized data, got %T", result.Data)
+	}
+	return normalized
+}
+
+func TestSearch(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	params := omniserp.SearchParams{
+		Query:      "golang",
+		Location:   "Austin,Texas,United States",
+		Country:    "us",
+		Language:   "en",
+		NumResults: 20,
+		Page:       2,
+		Freshness:  omniserp.FreshnessWeek,
+		// The extra parameter takes precedence over the Google domain
+		GoogleDomain: "google.fr",
+		Extra:        map[string]any{"device": "mobile", "search_engine": "google.de"},
+	}
+	result, err := engine.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	want := map[string]string{
+		"q":             "golang",
+		"location":      "Austin,Texas,United States",
+		"gl":            "US",
+		"hl":            "en",
+		"num":           "20",
+		"start":         "20",
+		"tbs":           "qdr:w",
+		"device":        "mobile",
+		"search_engine": "google.de",
+		"tbm":           "",
+		"apikey":        "",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("Parameter %s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizeSearch(result, params.Query)
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if len(normalized.OrganicResults) != 2 {
+		t.Fatalf("Expected 2 organic results, got %d", len(normalized.OrganicResults))
+	}
+	if second := normalized.OrganicResults[1]; second.Position != 2 || second.Domain != "github.com" || second.Date != "Jan 2, 2026" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if len(normalized.PeopleAlsoAsk) != 1 || normalized.PeopleAlsoAsk[0].Source != "go.dev" {
+		t.Errorf("Unexpected people also ask: %+v", normalized.PeopleAlsoAsk)
+	}
+	if box := normalized.AnswerBox; box == nil || box.Source != "go.dev" {
+		t.Errorf("Unexpected answer box: %+v", box)
+	}
+	if kg := normalized.KnowledgeGraph; kg == nil || kg.Source != "Wikipedia" {
+		t.Errorf("Unexpected knowledge graph: %+v", kg)
+	}
+	if len(normalized.RelatedSearches) != 2 || normalized.RelatedSearches[1].Query != "golang vs rust" {
+		t.Errorf("Unexpected related searches: %+v", normalized.RelatedSearches)
+	}
+	if normalized.SearchMetadata.TotalResults != 2540000 {
+		t.Errorf("Unexpected metadata: %+v", normalized.SearchMetadata)
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchImages(context.Background(), omniserp.SearchParams{Query: "gopher", Freshness: omniserp.FreshnessDay})
+	if err != nil {
+		t.Fatalf("SearchImages failed: %v", err)
+	}
+	if query.Get("tbm") != tbmImages || query.Has("tbs") {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	images := normalizedOf(t, result).ImageResults
+	if len(images) != 1 {
+		t.Fatalf("Expected 1 image, got %d", len(images))
+	}
+	if img := images[0]; img.ImageURL != "https://go.dev/blog/gopher/header.jpg" || img.Source != "go.dev" || img.Width != 1200 {
+		t.Errorf("Unexpected image: %+v", img)
+	}
+}
+
+func TestSearchVideos(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchVideos(context.Background(), omniserp.SearchParams{Query: "golang concurrency", Freshness: omniserp.FreshnessYear})
+	if err != nil {
+		t.Fatalf("SearchVideos failed: %v", err)
+	}
+	if query.Get("tbm") != tbmVideos || query.Get("tbs") != "qdr:y" {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	videos := normalizedOf(t, result).VideoResults
+	if len(videos) != 2 {
+		t.Fatalf("Expected 2 videos, got %d", len(videos))
+	}
+	if v := videos[0]; v.Platform != "youtube" || v.Duration != "31:21" || v.Channel != "gnbitcom" {
+		t.Errorf("Unexpected first video: %+v", v)
+	}
+	if v := videos[1]; v.Platform != "vimeo" {
+		t.Errorf("Unexpected second video: %+v", v)
+	}
+}
+
+func TestSearchMaps(t *testing.T) {
+	engine, query := newTestServer(t)
+
+	result, err := engine.SearchMaps(context.Background(), omniserp.SearchParams{Query: "coffee", Location: "Austin,Texas,United States"})
+	if err != nil {
+		t.Fatalf("SearchMaps failed: %v", err)
+	}
+	if query.Get("tbm") != tbmMaps {
+		t.Errorf("Unexpected query %s", query.Encode())
+	}
+	normalized, err := omniserp.NewNormalizer(engine.GetName()).NormalizePlaces(result, "coffee")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	if len(normalized.PlaceResults) != 1 {
+		t.Fatalf("Expected 1 place, got %d", len(normalized.PlaceResults))
+	}
+	if place := normalized.PlaceResults[0]; place.DataID != "1234567890" || place.Reviews != 812 || place.Latitude != 30.2637 || place.Type != "Coffee shop" {
+		t.Errorf("Unexpected place: %+v", place)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	engine, _ := newTestServer(t)
+	engine.apiKey = "wrong-key"
+
+	_, err := engine.Search(context.Background(), omniserp.SearchParams{Query: "golang"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a 403 APIError, got %v", err)
+	}
+}
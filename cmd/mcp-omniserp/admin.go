@@ -0,0 +1,326 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// Tool call outcomes recorded by usageCounter
+const (
+	outcomeSuccess     = "success"
+	outcomeError       = "error"
+	outcomeCacheHit    = "cache_hit"
+	outcomeRateLimited = "rate_limited"
+
+	outcomeBudgetExceeded = "budget_exceeded"
+)
+
+// Engine health statuses reported by /admin/health
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthUnknown  = "unknown"
+)
+
+// degradedErrorRate and degradedMinRequests define when an engine is
+// reported as degraded
+const (
+	degradedErrorRate   = 0.5
+	degradedMinRequests = 5
+)
+
+// ToolUsage counts the calls of one tool by outcome
+type ToolUsage struct {
+	Calls       int64 `json:"calls"`
+	Errors      int64 `json:"errors"`
+	CacheHits   int64 `json:"cache_hits"`
+	RateLimited int64 `json:"rate_limited"`
+
+	BudgetExceeded int64 `json:"budget_exceeded"`
+}
+
+// QueryUsage counts the calls of one distinct query
+type QueryUsage struct {
+	Fingerprint string `json:"fingerprint"`
+	Query       string `json:"query"`
+	Calls       int64  `json:"calls"`
+}
+
+// maxTrackedQueries bounds the distinct queries counted per usage counter;
+// new queries are not counted once it is reached
+const maxTrackedQueries = 1000
+
+// topQueries is the number of queries reported by /admin/usage
+const topQueries = 20
+
+// usageCounter counts tool calls since the server started
+type usageCounter struct {
+	started time.Time
+
+	mu      sync.Mutex
+	tools   map[string]*ToolUsage
+	queries map[string]*QueryUsage
+}
+
+func newUsageCounter() *usageCounter {
+	return &usageCounter{
+		started: time.Now(),
+		tools:   make(map[string]*ToolUsage),
+		queries: make(map[string]*QueryUsage),
+	}
+}
+
+// recordQuery counts one call with the given search parameters, grouping
+// queries by fingerprint
+func (u *usageCounter) recordQuery(params omniserp.SearchParams) {
+	fingerprint := params.Fingerprint()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := u.queries[fingerprint]
+	if usage == nil {
+		if len(u.queries) >= maxTrackedQueries {
+			return
+		}
+		usage = &QueryUsage{Fingerprint: fingerprint, Query: omniserp.CanonicalQuery(params.Query)}
+		u.queries[fingerprint] = usage
+	}
+	usage.Calls++
+}
+
+// topQueries returns the most frequent queries, most frequent first
+func (u *usageCounter) topQueries(n int) []QueryUsage {
+	u.mu.Lock()
+	queries := make([]QueryUsage, 0, len(u.queries))
+	for _, usage := range u.queries {
+		queries = append(queries, *usage)
+	}
+	u.mu.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Calls != queries[j].Calls {
+			return queries[i].Calls > queries[j].Calls
+		}
+		return queries[i].Fingerprint < queries[j].Fingerprint
+	})
+	if len(queries) > n {
+		queries = queries[:n]
+	}
+	return queries
+}
+
+// record counts one call of tool with the given outcome
+func (u *usageCounter) record(tool, outcome string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := u.tools[tool]
+	if usage == nil {
+		usage = &ToolUsage{}
+		u.tools[tool] = usage
+	}
+	usage.Calls++
+	switch outcome {
+	case outcomeError:
+		usage.Errors++
+	case outcomeCacheHit:
+		usage.CacheHits++
+	case outcomeRateLimited:
+		usage.RateLimited++
+	case outcomeBudgetExceeded:
+		usage.BudgetExceeded++
+	}
+}
+
+// snapshot returns a copy of the per-tool counters
+func (u *usageCounter) snapshot() map[string]ToolUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	tools := make(map[string]ToolUsage, len(u.tools))
+	for name, usage := range u.tools {
+		tools[name] = *usage
+	}
+	return tools
+}
+
+// newAdminHandler returns the /admin/ endpoints for the given targets, or
+// nil if no admin token is configured. The endpoints require
+// "Authorization: Bearer <admin token>". With multi-tenancy, each tenant is
+// a target and responses are keyed by tenant name.
+func newAdminHandler(cfg *Config, targets []*tenant) http.Handler {
+	if cfg.AdminToken == "" {
+		return nil
+	}
+
+	a := &adminAPI{targets: targets, multiTenant: len(cfg.Tenants) > 0}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/engines", a.engines)
+	mux.HandleFunc("GET /admin/health", a.health)
+	mux.HandleFunc("GET /admin/cache/stats", a.cacheStats)
+	mux.HandleFunc("GET /admin/usage", a.usage)
+
+	log.Printf("Admin endpoints enabled at /admin/")
+	return requireBearerToken(cfg.AdminToken, mux)
+}
+
+// requireBearerToken rejects requests without the expected bearer token
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminAPI implements the admin endpoints
+type adminAPI struct {
+	targets     []*tenant
+	multiTenant bool
+}
+
+// respond writes the value computed per target: the single value, or a
+// map keyed by tenant name with multi-tenancy. The status is 503 if fn
+// reports any target as unavailable.
+func (a *adminAPI) respond(w http.ResponseWriter, fn func(t *tenant) (any, bool)) {
+	code := http.StatusOK
+	values := make(map[string]any, len(a.targets))
+	for _, t := range a.targets {
+		v, ok := fn(t)
+		if !ok {
+			code = http.StatusServiceUnavailable
+		}
+		values[t.name] = v
+	}
+
+	if !a.multiTenant {
+		writeJSON(w, code, values[a.targets[0].name])
+		return
+	}
+	writeJSON(w, code, map[string]any{"tenants": values})
+}
+
+// EngineHealth is the health of one engine
+type EngineHealth struct {
+	Status string             `json:"status"`
+	Stats  client.EngineStats `json:"stats"`
+}
+
+func (a *adminAPI) engines(w http.ResponseWriter, r *http.Request) {
+	a.respond(w, func(t *tenant) (any, bool) {
+		return map[string]any{
+			"current": t.client.GetName(),
+			"engines": omniserp.GetAllEngineInfo(t.client.GetRegistry()),
+			"init":    t.client.InitReport(),
+		}, true
+	})
+}
+
+// health reports per-engine status from the rolling client stats. The
+// response is 503 if a current engine is degraded.
+func (a *adminAPI) health(w http.ResponseWriter, r *http.Request) {
+	a.respond(w, func(t *tenant) (any, bool) {
+		engines := make(map[string]EngineHealth)
+		for name, stats := range t.client.Stats() {
+			engines[name] = EngineHealth{Status: engineStatus(stats), Stats: stats}
+		}
+
+		current := t.client.GetName()
+		status := engines[current].Status
+		return map[string]any{
+			"status":  status,
+			"current": current,
+			"engines": engines,
+		}, status != healthDegraded
+	})
+}
+
+// engineStatus classifies an engine from its rolling stats
+func engineStatus(stats client.EngineStats) string {
+	switch {
+	case stats.Requests == 0:
+		return healthUnknown
+	case stats.Requests >= degradedMinRequests && stats.ErrorRate >= degradedErrorRate:
+		return healthDegraded
+	default:
+		return healthOK
+	}
+}
+
+func (a *adminAPI) cacheStats(w http.ResponseWriter, r *http.Request) {
+	a.respond(w, func(t *tenant) (any, bool) {
+		statser, ok := t.rt.cache.(interface{ Stats() CacheStats })
+		if !ok {
+			return map[string]any{"backend": CacheNone}, true
+		}
+
+		stats := statser.Stats()
+		hitRate := 0.0
+		if total := stats.Hits + stats.Misses; total > 0 {
+			hitRate = float64(stats.Hits) / float64(total)
+		}
+		return map[string]any{
+			"backend":  CacheMemory,
+			"entries":  stats.Entries,
+			"hits":     stats.Hits,
+			"misses":   stats.Misses,
+			"hit_rate": hitRate,
+		}, true
+	})
+}
+
+// Usage is the tool and engine usage of the server or one tenant
+type Usage struct {
+	Since          string               `json:"since"`
+	Tools          map[string]ToolUsage `json:"tools"`
+	EngineRequests map[string]int       `json:"engine_requests"`
+	EngineCredits  map[string]int       `json:"engine_credits"`
+	TopQueries     []QueryUsage         `json:"top_queries"`
+	Budget         *BudgetStatus        `json:"budget,omitempty"`
+}
+
+// usage reports tool calls since startup, the engine requests that consume
+// API credits (cache hits and rate-limited calls do not), and the credits
+// spent, which are exact for engines that report them
+func (a *adminAPI) usage(w http.ResponseWriter, r *http.Request) {
+	a.respond(w, func(t *tenant) (any, bool) {
+		engineRequests := make(map[string]int)
+		for name, stats := range t.client.Stats() {
+			engineRequests[name] = stats.Requests
+		}
+		engineCredits := make(map[string]int)
+		for name, usage := range t.client.Usage() {
+			engineCredits[name] = usage.Credits
+		}
+		return Usage{
+			Since:          t.rt.usage.started.UTC().Format(time.RFC3339),
+			Tools:          t.rt.usage.snapshot(),
+			EngineRequests: engineRequests,
+			EngineCredits:  engineCredits,
+			TopQueries:     t.rt.usage.topQueries(topQueries),
+			Budget:         t.rt.budget.status(),
+		}, true
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write admin response: %v", err)
+	}
+}
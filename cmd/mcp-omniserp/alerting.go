@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/plexusone/omniserp/alerts"
+	"github.com/plexusone/omniserp/client"
+)
+
+// defaultAlertCheckInterval is how often engine credits are polled
+const defaultAlertCheckInterval = 15 * time.Minute
+
+// AlertsConfig configures webhook alerts on budget and credit thresholds
+type AlertsConfig struct {
+	// WebhookURL receives Slack-compatible JSON alerts; may be given as
+	// "env:VAR" (OMNISERP_ALERT_WEBHOOK_URL)
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// BudgetThresholds are fractions of a budget (e.g. 0.8, 0.95, 1) at
+	// which to alert as it is used up
+	BudgetThresholds []float64 `json:"budget_thresholds,omitempty"`
+
+	// CreditThresholds are remaining engine credit counts (e.g. 1000, 100)
+	// at which to alert as they run down
+	CreditThresholds []float64 `json:"credit_thresholds,omitempty"`
+
+	// CheckInterval is how often engine credits are polled (default 15m)
+	CheckInterval Duration `json:"check_interval,omitempty"`
+}
+
+// validate checks the alert configuration
+func (a *AlertsConfig) validate() []error {
+	var errs []error
+	if a.WebhookURL == "" && (len(a.BudgetThresholds) > 0 || len(a.CreditThresholds) > 0) {
+		errs = append(errs, errors.New("alerts.webhook_url: required when alert thresholds are set"))
+	}
+	for _, threshold := range a.BudgetThresholds {
+		if threshold <= 0 || threshold > 1 {
+			errs = append(errs, fmt.Errorf("alerts.budget_thresholds: must be between 0 and 1, got %g", threshold))
+		}
+	}
+	for _, threshold := range a.CreditThresholds {
+		if threshold < 0 {
+			errs = append(errs, fmt.Errorf("alerts.credit_thresholds: must not be negative, got %g", threshold))
+		}
+	}
+	if a.CheckInterval < 0 {
+		errs = append(errs, fmt.Errorf("alerts.check_interval: must not be negative, got %s", time.Duration(a.CheckInterval)))
+	}
+	return errs
+}
+
+// newAlertMonitor returns the alert monitor, or nil if alerts are disabled
+func newAlertMonitor(cfg *Config) *alerts.Monitor {
+	if cfg.Alerts.WebhookURL == "" {
+		return nil
+	}
+	webhook := alerts.NewWebhook(resolveSecret(cfg.Alerts.WebhookURL))
+	return alerts.NewMonitor(webhook, cfg.Alerts.BudgetThresholds, cfg.Alerts.CreditThresholds)
+}
+
+// sendAlerts delivers alerts in the background so tool calls are not
+// delayed by the webhook
+func sendAlerts(monitor *alerts.Monitor, pending []alerts.Alert) {
+	if len(pending) == 0 {
+		return
+	}
+	go func() {
+		if err := monitor.Send(context.Background(), pending); err != nil {
+			slog.Error("failed to send alert", "error", err)
+		}
+	}()
+}
+
+// watchCredits polls the credits of every engine of each target and alerts
+// when they cross the configured thresholds. Engines that cannot report
+// credits are skipped.
+func watchCredits(ctx context.Context, cfg *Config, monitor *alerts.Monitor, targets []*tenant) {
+	if monitor == nil || len(cfg.Alerts.CreditThresholds) == 0 {
+		return
+	}
+	interval := time.Duration(cfg.Alerts.CheckInterval)
+	if interval <= 0 {
+		interval = defaultAlertCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, t := range targets {
+			for _, engineName := range t.client.ListEngines() {
+				credits, err := t.client.Credits(ctx, engineName)
+				if errors.Is(err, client.ErrOperationNotSupported) {
+					continue
+				}
+				if err != nil {
+					slog.Warn("failed to check engine credits", "tenant", t.name, "engine", engineName, "error", err)
+					continue
+				}
+
+				subject := engineName
+				if t.name != "" {
+					subject = t.name + "/" + engineName
+				}
+				sendAlerts(monitor, monitor.Credits(subject, credits.Remaining))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// searchAnnotations are the hints of the tools querying a search engine: they
+// modify nothing, and repeated calls with the same arguments have no further
+// effect beyond using quota, so clients may approve them automatically
+func searchAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:          title,
+		ReadOnlyHint:   true,
+		IdempotentHint: true,
+		OpenWorldHint:  boolPtr(true),
+	}
+}
+
+// scrapeAnnotations are the hints of the scrape tool. It modifies nothing, but
+// fetches arbitrary sites, which may rate limit or block repeated requests and
+// change between them, so it is not marked idempotent.
+func scrapeAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:         title,
+		ReadOnlyHint:  true,
+		OpenWorldHint: boolPtr(true),
+	}
+}
+
+// localAnnotations are the hints of the tools that only report the server's
+// own configuration
+func localAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:          title,
+		ReadOnlyHint:   true,
+		IdempotentHint: true,
+		OpenWorldHint:  boolPtr(false),
+	}
+}
+
+// sessionAnnotations are the hints of the tools that change the state of the
+// calling session only; repeating a call leaves the same state
+func sessionAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		IdempotentHint:  true,
+		DestructiveHint: boolPtr(false),
+		OpenWorldHint:   boolPtr(false),
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries triggers a sweep of expired entries when reached
+const maxCacheEntries = 1024
+
+// toolCache caches serialized tool results by tool name and arguments
+type toolCache interface {
+	Get(key string) (string, bool)
+	Set(key, value string)
+
+	// Flush removes all entries, such as after the engine changed
+	Flush()
+}
+
+// newToolCache returns the cache backend selected by the configuration,
+// or nil if caching is disabled
+func newToolCache(cfg *Config) toolCache {
+	if cfg.Cache == CacheMemory {
+		return newMemoryCache(time.Duration(cfg.CacheTTL))
+	}
+	return nil
+}
+
+// CacheStats reports cache effectiveness
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// memoryCache is an in-process cache with a fixed TTL
+type memoryCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    int64
+	misses  int64
+}
+
+func newMemoryCache(ttl time.Duration) *memoryCache {
+	return &memoryCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns a cached value that has not expired
+func (c *memoryCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return entry.value, true
+}
+
+// Set stores a value for the cache TTL
+func (c *memoryCache) Set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(c.ttl)}
+}
+
+// Flush removes all entries
+func (c *memoryCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// Stats returns the current cache statistics
+func (c *memoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/client/serper"
+	"github.com/plexusone/omniserp/client/tavily"
+)
+
+// newReloadRuntime returns a reloader of a server with the serper and tavily
+// engines and an in-memory cache; the engines are not called
+func newReloadRuntime(t *testing.T) *reloader {
+	t.Helper()
+	registry := omniserp.NewRegistry()
+	for _, newEngine := range []func(string) (omniserp.Engine, error){
+		func(key string) (omniserp.Engine, error) { return serper.NewWithAPIKey(key) },
+		func(key string) (omniserp.Engine, error) { return tavily.NewWithAPIKey(key) },
+	} {
+		engine, err := newEngine("test-key")
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+		registry.Register(engine)
+	}
+	searchClient, err := client.NewWithRegistry(registry, "serper")
+	if err != nil {
+		t.Fatalf("NewWithRegistry failed: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.Cache = CacheMemory
+	rt := &toolRuntime{
+		client:  searchClient,
+		cache:   newToolCache(cfg),
+		limiter: newRateLimiter(0, 0),
+		usage:   newUsageCounter(),
+	}
+	server := newServer()
+	registerTools(server, searchClient, cfg, rt)
+	return newReloader("", cfg, server, searchClient, rt)
+}
+
+func TestCacheKeyedByEngine(t *testing.T) {
+	r := newReloadRuntime(t)
+	params := omniserp.SearchParams{Query: "golang"}
+	calls := 0
+	search := func(context.Context) (*omniserp.SearchResult, error) {
+		calls++
+		return &omniserp.SearchResult{Data: map[string]any{"engine": r.client.GetName()}}, nil
+	}
+
+	for range 2 {
+		if _, _, err := r.rt.call(context.Background(), client.OpSearch, params, search); err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("Expected the second call to be served from cache, got %d calls", calls)
+	}
+
+	// Switching the engine without a reload must not serve the old result
+	if err := r.client.SetEngine("tavily"); err != nil {
+		t.Fatalf("SetEngine failed: %v", err)
+	}
+	if _, _, err := r.rt.call(context.Background(), client.OpSearch, params, search); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected a new search after the engine changed, got %d calls", calls)
+	}
+}
+
+func TestReloadFlushesCache(t *testing.T) {
+	r := newReloadRuntime(t)
+	r.rt.cache.Set("key", "value")
+
+	cfg := defaultConfig()
+	cfg.Cache, cfg.CacheTTL = CacheMemory, r.cfg.CacheTTL
+	cfg.Routes = map[string]string{"news": "tavily"}
+	if err := r.apply(cfg); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if _, ok := r.rt.cache.Get("key"); ok {
+		t.Error("Expected the cache to be flushed after the routes changed")
+	}
+
+	// An unchanged configuration keeps the cache
+	r.rt.cache.Set("key", "value")
+	unchanged := *cfg
+	if err := r.apply(&unchanged); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if _, ok := r.rt.cache.Get("key"); !ok {
+		t.Error("Expected the cache to be kept when the engine and routes are unchanged")
+	}
+}
+
+func TestMemoryCacheFlush(t *testing.T) {
+	c := newMemoryCache(time.Minute)
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Flush()
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected no entries after Flush, got %d", stats.Entries)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// ToolListCapabilities lists the operations and parameters of each engine
+const ToolListCapabilities = "list_engine_capabilities"
+
+// registerCapabilitiesTool registers the capability matrix tool, so agents
+// can check which engine honors a parameter before choosing a tool
+func registerCapabilitiesTool(server *mcp.Server, searchClient *client.Client) []string {
+	server.RemoveTools(ToolListCapabilities)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        ToolListCapabilities,
+		Description: "List the operations supported by each configured engine and the search parameters each honors",
+		Annotations: localAnnotations("Engine Capabilities"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		output, _ := json.MarshalIndent(omniserp.CapabilityMatrix(searchClient.GetRegistry()), "", "  ")
+		return textResult(string(output)), nil, nil
+	})
+
+	return []string{ToolListCapabilities}
+}
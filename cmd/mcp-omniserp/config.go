@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// Transports supported by the server
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// Cache backends supported by the server
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+)
+
+// Config is the server configuration. It is loaded from defaults, then an
+// optional JSON config file, then OMNISERP_* environment variables, so the
+// binary can be configured entirely from the environment in containers.
+type Config struct {
+	// Engine is the search engine to use (OMNISERP_ENGINE, or SEARCH_ENGINE)
+	Engine string `json:"engine"`
+
+	// Transport is "stdio" or "http" (OMNISERP_TRANSPORT)
+	Transport string `json:"transport"`
+
+	// Port is the HTTP transport listen port (OMNISERP_PORT)
+	Port int `json:"port"`
+
+	// Cache is the tool result cache backend, "none" or "memory" (OMNISERP_CACHE)
+	Cache string `json:"cache"`
+
+	// CacheTTL is how long cached tool results are served (OMNISERP_CACHE_TTL)
+	CacheTTL Duration `json:"cache_ttl"`
+
+	// Tools limits the registered tools to the listed operations, given by
+	// tool name or engine-neutral identifier such as "news"; empty registers
+	// all tools supported by the engine (OMNISERP_TOOLS, comma-separated)
+	Tools []string `json:"tools,omitempty"`
+
+	// Routes send operations to specific engines, such as
+	// {"news": "serper", "scholar": "serpapi", "*": "serpapi"}
+	// (OMNISERP_ROUTES, e.g. "news=serper,scholar=serpapi,*=serpapi").
+	// Routed engines must be configured and support their operations.
+	Routes map[string]string `json:"routes,omitempty"`
+
+	// MaxResultTokens truncates tool results to about this many tokens,
+	// shortening long strings and dropping the lowest-ranked entries; zero
+	// returns full results (OMNISERP_MAX_RESULT_TOKENS)
+	MaxResultTokens int `json:"max_result_tokens,omitempty"`
+
+	// RateLimit is the maximum tool calls per second; zero disables
+	// limiting (OMNISERP_RATE_LIMIT)
+	RateLimit float64 `json:"rate_limit,omitempty"`
+
+	// RateBurst is the number of calls allowed in a burst; defaults to the
+	// rate rounded up (OMNISERP_RATE_BURST)
+	RateBurst int `json:"rate_burst,omitempty"`
+
+	// Sanitize removes API keys, tracking parameters, and account
+	// identifiers from engine responses before they are cached or returned
+	// (OMNISERP_SANITIZE)
+	Sanitize bool `json:"sanitize,omitempty"`
+
+	// ValidateKeys checks the credentials of every configured engine at
+	// startup and exits listing the rejected keys, instead of failing on
+	// the first tool call (OMNISERP_VALIDATE_KEYS)
+	ValidateKeys bool `json:"validate_keys,omitempty"`
+
+	// LogLevel is "debug", "info", "warn", or "error" (OMNISERP_LOG_LEVEL)
+	LogLevel string `json:"log_level"`
+
+	// Tenants enables multi-tenancy on the HTTP transport: each client API
+	// key maps to its own engine credentials, rate limits, and budget.
+	// When set, requests without a valid tenant API key are rejected.
+	Tenants []TenantConfig `json:"tenants,omitempty"`
+
+	// AdminToken enables the /admin/ endpoints of the HTTP transport, which
+	// require it as a bearer token (OMNISERP_ADMIN_TOKEN)
+	AdminToken string `json:"admin_token,omitempty"`
+
+	// SavedSearches is a JSON file of saved searches, managed with
+	// "omniserp saved", that enables the run_saved_search and
+	// list_saved_searches tools (OMNISERP_SAVED_SEARCHES)
+	SavedSearches string `json:"saved_searches,omitempty"`
+
+	// Alerts configures webhook alerts on budget and credit thresholds
+	Alerts AlertsConfig `json:"alerts"`
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string like "5m"
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// defaultConfig returns the configuration used when nothing is set
+func defaultConfig() *Config {
+	return &Config{
+		Transport: TransportStdio,
+		Port:      8080,
+		Cache:     CacheNone,
+		CacheTTL:  Duration(5 * time.Minute),
+		LogLevel:  "info",
+	}
+}
+
+// loadConfig builds the configuration from defaults, the config file at path
+// (if non-empty), and environment variables, then validates it
+func loadConfig(path string) (*Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		// #nosec G304 -- config path is provided by the operator
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	// Report environment and validation problems together
+	if err := errors.Join(cfg.applyEnv(), cfg.Validate()); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides fields with the environment variables that are set
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	if v := os.Getenv("SEARCH_ENGINE"); v != "" {
+		c.Engine = v
+	}
+	if v := os.Getenv("OMNISERP_ENGINE"); v != "" {
+		c.Engine = v
+	}
+	if v := os.Getenv("OMNISERP_TRANSPORT"); v != "" {
+		c.Transport = v
+	}
+	if v := os.Getenv("OMNISERP_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_PORT: not a number: %q", v))
+		} else {
+			c.Port = port
+		}
+	}
+	if v := os.Getenv("OMNISERP_CACHE"); v != "" {
+		c.Cache = v
+	}
+	if v := os.Getenv("OMNISERP_CACHE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_CACHE_TTL: %w", err))
+		} else {
+			c.CacheTTL = Duration(ttl)
+		}
+	}
+	if v := os.Getenv("OMNISERP_TOOLS"); v != "" {
+		c.Tools = splitList(v)
+	}
+	if v := os.Getenv("OMNISERP_ROUTES"); v != "" {
+		if routes, err := client.ParseRoutes(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_ROUTES: %w", err))
+		} else {
+			c.Routes = routes
+		}
+	}
+	if v := os.Getenv("OMNISERP_MAX_RESULT_TOKENS"); v != "" {
+		if tokens, err := strconv.Atoi(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_MAX_RESULT_TOKENS: not a number: %q", v))
+		} else {
+			c.MaxResultTokens = tokens
+		}
+	}
+	if v := os.Getenv("OMNISERP_RATE_LIMIT"); v != "" {
+		if rate, err := strconv.ParseFloat(v, 64); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_RATE_LIMIT: not a number: %q", v))
+		} else {
+			c.RateLimit = rate
+		}
+	}
+	if v := os.Getenv("OMNISERP_RATE_BURST"); v != "" {
+		if burst, err := strconv.Atoi(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_RATE_BURST: not a number: %q", v))
+		} else {
+			c.RateBurst = burst
+		}
+	}
+	if v := os.Getenv("OMNISERP_SANITIZE"); v != "" {
+		if sanitize, err := strconv.ParseBool(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_SANITIZE: not a boolean: %q", v))
+		} else {
+			c.Sanitize = sanitize
+		}
+	}
+	if v := os.Getenv("OMNISERP_VALIDATE_KEYS"); v != "" {
+		if validate, err := strconv.ParseBool(v); err != nil {
+			errs = append(errs, fmt.Errorf("OMNISERP_VALIDATE_KEYS: not a boolean: %q", v))
+		} else {
+			c.ValidateKeys = validate
+		}
+	}
+	if v := os.Getenv("OMNISERP_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("OMNISERP_ADMIN_TOKEN"); v != "" {
+		c.AdminToken = v
+	}
+	if v := os.Getenv("OMNISERP_SAVED_SEARCHES"); v != "" {
+		c.SavedSearches = v
+	}
+	if v := os.Getenv("OMNISERP_ALERT_WEBHOOK_URL"); v != "" {
+		c.Alerts.WebhookURL = v
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks every field and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Transport != TransportStdio && c.Transport != TransportHTTP {
+		errs = append(errs, fmt.Errorf("transport: must be %q or %q, got %q", TransportStdio, TransportHTTP, c.Transport))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port: must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.Cache != CacheNone && c.Cache != CacheMemory {
+		errs = append(errs, fmt.Errorf("cache: must be %q or %q, got %q", CacheNone, CacheMemory, c.Cache))
+	}
+	if c.Cache == CacheMemory && c.CacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("cache_ttl: must be positive when the cache is enabled, got %s", time.Duration(c.CacheTTL)))
+	}
+	for _, tool := range c.Tools {
+		if !slices.Contains(client.AllOperations(), client.ToolName(tool)) {
+			errs = append(errs, fmt.Errorf("tools: unknown tool %q (available: %s)", tool, strings.Join(client.AllOperations(), ", ")))
+		}
+	}
+	for operation, engineName := range client.NewRoutingPolicy(c.Routes).Routes {
+		if operation != client.RouteDefault && !slices.Contains(client.AllOperations(), operation) {
+			errs = append(errs, fmt.Errorf("routes: unknown operation %q (available: %s)", operation, strings.Join(client.AllOperations(), ", ")))
+		}
+		if _, ok := engineFactories[engineName]; !ok {
+			errs = append(errs, fmt.Errorf("routes: unknown engine %q for %s", engineName, operation))
+		}
+	}
+	if len(c.Routes) > 0 && len(c.Tenants) > 0 {
+		errs = append(errs, errors.New("routes: not supported with tenants"))
+	}
+	if c.MaxResultTokens < 0 {
+		errs = append(errs, fmt.Errorf("max_result_tokens: must not be negative, got %d", c.MaxResultTokens))
+	}
+	if c.RateLimit < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit: must not be negative, got %g", c.RateLimit))
+	}
+	if c.RateBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate_burst: must not be negative, got %d", c.RateBurst))
+	}
+	if len(c.Tenants) > 0 && c.Transport != TransportHTTP {
+		errs = append(errs, fmt.Errorf("tenants: multi-tenancy requires the %q transport", TransportHTTP))
+	}
+	errs = append(errs, validateTenants(c.Tenants)...)
+	if c.AdminToken != "" && c.Transport != TransportHTTP {
+		errs = append(errs, fmt.Errorf("admin_token: admin endpoints require the %q transport", TransportHTTP))
+	}
+	errs = append(errs, c.Alerts.validate()...)
+	if _, err := parseLogLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("log_level: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// AllowsTool reports whether the tool filter permits a tool
+func (c *Config) AllowsTool(name string) bool {
+	if len(c.Tools) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.Tools, func(tool string) bool {
+		return client.ToolName(tool) == name
+	})
+}
+
+// parseLogLevel converts a level name to a slog.Level
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("must be debug, info, warn, or error, got %q", s)
+	}
+	return level, nil
+}
+
+// newSanitizer returns the response sanitizer of the server, or nil if
+// sanitization is disabled. The given API keys are redacted in addition to
+// those of the engine environment variables.
+func (c *Config) newSanitizer(apiKeys ...string) *omniserp.Sanitizer {
+	if !c.Sanitize {
+		return nil
+	}
+	return omniserp.NewSanitizer(append(apiKeys, os.Getenv("SERPER_API_KEY"), os.Getenv("SERPAPI_API_KEY"))...)
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// ErrInvalidCursor is returned when a search tool is called with a cursor
+// it did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SearchToolArgs are the arguments of the search tools
+type SearchToolArgs struct {
+	omniserp.SearchParams
+
+	// Cursor continues a previous search: its parameters replace the others
+	Cursor string `json:"cursor,omitempty" jsonschema:"description:next_cursor of a previous result of this tool to fetch its next page; other parameters are ignored"`
+}
+
+// cursorState is the content of a cursor: the tool and the parameters of
+// the page it fetches
+type cursorState struct {
+	Tool   string                `json:"tool"`
+	Params omniserp.SearchParams `json:"params"`
+}
+
+// encodeCursor returns the cursor of the page after the one fetched with
+// params
+func encodeCursor(toolName string, params omniserp.SearchParams) string {
+	params.Page = max(params.Page, 1) + 1
+	data, _ := json.Marshal(cursorState{Tool: toolName, Params: params})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the parameters of a cursor issued by toolName
+func decodeCursor(toolName, cursor string) (omniserp.SearchParams, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return omniserp.SearchParams{}, ErrInvalidCursor
+	}
+	var state cursorState
+	if err := json.Unmarshal(data, &state); err != nil || state.Params.Page < 2 {
+		return omniserp.SearchParams{}, ErrInvalidCursor
+	}
+	if state.Tool != toolName {
+		return omniserp.SearchParams{}, fmt.Errorf("%w: issued by %s", ErrInvalidCursor, state.Tool)
+	}
+	return state.Params, nil
+}
+
+// params returns the search parameters of the call, taken from the cursor
+// if one is given. Without a cursor a query is required, except for
+// citation searches.
+func (a SearchToolArgs) params(toolName string) (omniserp.SearchParams, error) {
+	if a.Cursor == "" {
+		if a.Query == "" && a.Cites == "" {
+			return omniserp.SearchParams{}, errors.New("query is required")
+		}
+		return a.SearchParams, nil
+	}
+	return decodeCursor(toolName, a.Cursor)
+}
+
+// paginates reports whether the results of a search tool have pages
+func paginates(toolName string) bool {
+	return toolName != client.OpSearchAutocomplete
+}
+
+// resultKeys are the top-level keys of the result lists of each search
+// tool, in engine responses and normalized results, so other lists such as
+// related searches are not counted as results
+var resultKeys = map[string][]string{
+	client.OpSearch:         {"organic", "organic_results", "items", "results"},
+	client.OpSearchNews:     {"news", "news_results", "items", "results"},
+	client.OpSearchImages:   {"images", "images_results", "image_results", "items", "results"},
+	client.OpSearchVideos:   {"videos", "videos_results", "video_results", "results"},
+	client.OpSearchPlaces:   {"places", "places_results", "place_results", "local_results", "results"},
+	client.OpSearchMaps:     {"places", "places_results", "place_results", "local_results", "results"},
+	client.OpSearchReviews:  {"reviews", "review_results", "results"},
+	client.OpSearchShopping: {"shopping", "shopping_results", "results"},
+	client.OpSearchScholar:  {"organic", "organic_results", "scholar_results", "items", "results"},
+	client.OpSearchLens:     {"visual_matches", "organic", "results"},
+}
+
+// addCursor appends the cursor of the next page to a tool result as a JSON
+// text block unless the page has no results, so clients following cursors
+// stop at the first empty page. Engines often return fewer results than
+// requested, so a short page is not taken as the last. The page is data,
+// the engine response, or the JSON text of the result if nil, such as for
+// cached results.
+func addCursor(result *mcp.CallToolResult, toolName string, params omniserp.SearchParams, data any) {
+	if data == nil {
+		if len(result.Content) == 0 {
+			return
+		}
+		text, ok := result.Content[0].(*mcp.TextContent)
+		if !ok {
+			return
+		}
+		data = json.RawMessage(text.Text)
+	}
+	if countResults(toolName, data) == 0 {
+		return
+	}
+	output, _ := json.Marshal(map[string]string{"next_cursor": encodeCursor(toolName, params)})
+	result.Content = append(result.Content, &mcp.TextContent{Text: string(output)})
+}
+
+// countResults returns the number of results of a search tool in an engine
+// response: the items of its result lists
+func countResults(toolName string, data any) int {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return 0
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, key := range resultKeys[toolName] {
+		if items, ok := fields[key].([]any); ok {
+			count += len(items)
+		}
+	}
+	return count
+}
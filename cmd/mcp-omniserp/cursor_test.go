@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// serperPage returns a serper-shaped response with n results in the list
+// named key
+func serperPage(key string, n int) map[string]any {
+	items := make([]any, n)
+	for i := range items {
+		items[i] = map[string]any{"title": fmt.Sprint("Result ", i+1), "link": fmt.Sprint("https://example.com/", i+1)}
+	}
+	return map[string]any{
+		key:               items,
+		"relatedSearches": []any{map[string]any{"query": "golang"}},
+	}
+}
+
+// nextCursor returns the cursor of a tool result, or "" if it has none
+func nextCursor(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) < 2 {
+		return ""
+	}
+	var cursor struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[len(result.Content)-1].(*mcp.TextContent).Text), &cursor); err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	return cursor.NextCursor
+}
+
+func TestAddCursor(t *testing.T) {
+	params := omniserp.SearchParams{Query: "golang", NumResults: 10}
+	cases := []struct {
+		name   string
+		params omniserp.SearchParams
+		data   any
+		want   bool
+	}{
+		{"full page", params, serperPage("organic", 10), true},
+		{"short page", params, serperPage("organic", 8), true},
+		{"empty page", params, serperPage("organic", 0), false},
+		{"related searches only", params, serperPage("news", 10), false},
+		{"serpapi", params, map[string]any{"organic_results": []any{map[string]any{"title": "Go"}}}, true},
+		{"normalized", params, &omniserp.NormalizedSearchResult{OrganicResults: make([]omniserp.OrganicResult, 5)}, true},
+		{"normalized empty", params, &omniserp.NormalizedSearchResult{RelatedSearches: make([]omniserp.RelatedSearch, 5)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, _ := json.Marshal(tc.data)
+			result := textResult(string(text))
+			addCursor(result, client.OpSearch, tc.params, tc.data)
+			if got := nextCursor(t, result) != ""; got != tc.want {
+				t.Errorf("Expected cursor %v, got %v", tc.want, got)
+			}
+
+			// Cached results are counted from their text
+			result = textResult(string(text))
+			addCursor(result, client.OpSearch, tc.params, nil)
+			if got := nextCursor(t, result) != ""; got != tc.want {
+				t.Errorf("Expected cursor %v for the cached result, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	result := textResult("{}")
+	params := omniserp.SearchParams{Query: "golang", NumResults: 2}
+	addCursor(result, client.OpSearchNews, params, serperPage("news", 2))
+	cursor := nextCursor(t, result)
+
+	next, err := SearchToolArgs{Cursor: cursor}.params(client.OpSearchNews)
+	if err != nil {
+		t.Fatalf("params failed: %v", err)
+	}
+	if next.Query != "golang" || next.Page != 2 || next.NumResults != 2 {
+		t.Errorf("Unexpected parameters of the next page: %+v", next)
+	}
+	if _, err := (SearchToolArgs{Cursor: cursor}).params(client.OpSearch); err == nil || !strings.Contains(err.Error(), "issued by") {
+		t.Errorf("Expected a cursor of another tool to be rejected, got %v", err)
+	}
+}
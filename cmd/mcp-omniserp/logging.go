@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp/client"
+)
+
+// loggerName is the logger of the log notifications sent to MCP clients
+const loggerName = "omniserp"
+
+// MCP logging levels of the events forwarded to clients
+const (
+	levelInfo    mcp.LoggingLevel = "info"
+	levelWarning mcp.LoggingLevel = "warning"
+)
+
+// sessionKey is the context key of the MCP session of a request
+type sessionKey struct{}
+
+// sessionMiddleware attaches the session of each request to its context, so
+// tool handlers can send log notifications to the calling client
+func sessionMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if session, ok := req.GetSession().(*mcp.ServerSession); ok {
+			ctx = context.WithValue(ctx, sessionKey{}, session)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// logEvent sends an operational event to the client of the session of ctx
+// as a log notification, such as a failover explaining a slow search. The
+// event is dropped unless the client enabled logging at level or below with
+// logging/setLevel.
+func logEvent(ctx context.Context, level mcp.LoggingLevel, message string, attrs map[string]any) {
+	session, ok := ctx.Value(sessionKey{}).(*mcp.ServerSession)
+	if !ok {
+		return
+	}
+	data := map[string]any{"message": message}
+	maps.Copy(data, attrs)
+	err := session.Log(ctx, &mcp.LoggingMessageParams{Logger: loggerName, Level: level, Data: data})
+	if err != nil {
+		slog.Debug("Failed to send log notification", "error", err)
+	}
+}
+
+// withFailoverEvents returns ctx forwarding the engine failovers of a tool
+// call to its client as warnings
+func withFailoverEvents(ctx context.Context, toolName string) context.Context {
+	return client.ContextWithFailoverHook(ctx, func(event client.FailoverEvent) {
+		reason := "failed"
+		if errors.Is(event.Err, client.ErrEngineSwitched) {
+			reason = "was switched"
+		}
+		logEvent(ctx, levelWarning, "Engine "+event.From+" "+reason+"; retrying on "+event.To, map[string]any{
+			"event": "failover",
+			"tool":  toolName,
+			"from":  event.From,
+			"to":    event.To,
+			"error": event.Err.Error(),
+		})
+	})
+}
@@ -28,194 +28,211 @@
 //
 // When a policy file exists, credentials are retrieved from the OS keychain with security
 // posture validation. Without a policy file, standard environment variables are used.
+//
+// Server Configuration:
+//
+// The server is configured from an optional JSON file (--config or OMNISERP_CONFIG)
+// overridden by environment variables, so it deploys cleanly in containers:
+//
+//	OMNISERP_ENGINE             search engine (SEARCH_ENGINE is also accepted)
+//	OMNISERP_TRANSPORT          stdio (default) or http
+//	OMNISERP_PORT               HTTP transport port (default 8080)
+//	OMNISERP_CACHE              none (default) or memory
+//	OMNISERP_CACHE_TTL          cache TTL (default 5m)
+//	OMNISERP_TOOLS              comma-separated allow-list of tools
+//	OMNISERP_MAX_RESULT_TOKENS  truncate tool results to about this many tokens
+//	OMNISERP_SAVED_SEARCHES     saved searches file for the saved search tools
+//	OMNISERP_SANITIZE           remove API keys and tracking parameters from results
+//	OMNISERP_VALIDATE_KEYS      check engine API keys at startup
+//	OMNISERP_LOG_LEVEL          debug, info (default), warn, or error
 package main
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	keyring "github.com/plexusone/omnivault-keyring"
-	"github.com/plexusone/vaultguard"
 
 	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/alerts"
 	"github.com/plexusone/omniserp/client"
-	"github.com/plexusone/omniserp/client/serpapi"
-	"github.com/plexusone/omniserp/client/serper"
 )
 
+// keyValidationTimeout bounds the startup check of engine credentials
+const keyValidationTimeout = 30 * time.Second
+
 // ToolDefinition defines a search tool with its metadata
 type ToolDefinition struct {
 	Name        string
+	Title       string
 	Description string
 	SearchFunc  func(context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error)
 }
 
 func main() {
-	ctx := context.Background()
+	configPath := flag.String("config", os.Getenv("OMNISERP_CONFIG"), "path to a JSON config file")
+	flag.Parse()
 
-	// Load policy from config files (or nil for permissive mode)
-	policy, err := vaultguard.LoadPolicy()
+	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		log.Fatalf("Failed to load policy: %v", err)
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	setupLogging(cfg)
 
-	// Initialize search client based on credential mode
-	var searchClient *client.Client
-	if policy == nil {
-		log.Println("No policy configured - using environment variables")
-		searchClient, err = initWithEnvCredentials()
-	} else {
-		log.Println("Policy loaded - using secure credential access")
-		searchClient, err = initWithSecureCredentials(ctx, policy)
+	ctx := context.Background()
+
+	// Tenants bring their own engine credentials
+	if len(cfg.Tenants) > 0 {
+		if err := runMultiTenantServer(cfg); err != nil {
+			log.Fatalf("Server failed: %v", err)
+		}
+		return
 	}
+
+	// Initialize search client based on credential mode
+	searchClient, err := initClient(ctx, cfg.Engine)
 	if err != nil {
 		log.Fatalf("Failed to initialize search client: %v", err)
 	}
-
-	runServer(ctx, searchClient)
-}
-
-// initWithEnvCredentials initializes the client using environment variables.
-func initWithEnvCredentials() (*client.Client, error) {
-	return client.New()
-}
-
-// initWithSecureCredentials initializes the client using VaultGuard and OS keychain.
-func initWithSecureCredentials(ctx context.Context, policy *vaultguard.Policy) (*client.Client, error) {
-	// Create keyring provider for OS credential store
-	keyringVault := keyring.New(keyring.Config{
-		ServiceName: "omnivault",
-	})
-
-	// Create VaultGuard with the keyring and loaded policy
-	sv, err := vaultguard.New(&vaultguard.Config{
-		CustomVault: keyringVault,
-		Policy:      policy,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("security check failed: %w", err)
-	}
-	defer sv.Close()
-
-	// Log security status
-	result := sv.SecurityResult()
-	if result != nil {
-		log.Printf("Security check passed: score=%d, level=%s", result.Score, result.Level)
-		if result.Details.Local != nil {
-			log.Printf("  Platform: %s, Encrypted: %v, Biometrics: %v",
-				result.Details.Local.Platform,
-				result.Details.Local.DiskEncrypted,
-				result.Details.Local.BiometricsConfigured)
+	if cfg.ValidateKeys {
+		if err := validateKeys(ctx, searchClient); err != nil {
+			log.Fatalf("Failed to validate engine credentials: %v", err)
 		}
 	}
 
-	// Determine which engine to use
-	engineName := os.Getenv("SEARCH_ENGINE")
-	if engineName == "" {
-		engineName = "serper"
+	// Routes are validated against the capabilities of the configured engines
+	if len(cfg.Routes) > 0 {
+		if err := searchClient.SetRoutes(cfg.Routes); err != nil {
+			log.Fatalf("Failed to configure routes: %v", err)
+		}
 	}
 
-	// Create registry and register engines with secure credentials
-	registry := omniserp.NewRegistry()
-
-	switch engineName {
-	case "serper":
-		apiKey, err := sv.GetValue(ctx, "SERPER_API_KEY")
-		if err != nil {
-			return nil, fmt.Errorf("failed to get SERPER_API_KEY from keychain: %w", err)
-		}
-		if apiKey == "" {
-			return nil, fmt.Errorf("SERPER_API_KEY not found in keychain. Add it with:\n" +
-				"  security add-generic-password -s \"omnivault\" -a \"SERPER_API_KEY\" -w \"your-key\"")
-		}
-		log.Println("SERPER_API_KEY retrieved from keychain successfully")
+	searchClient.SetSanitizer(cfg.newSanitizer())
 
-		engine, err := serper.NewWithAPIKey(apiKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create serper engine: %w", err)
-		}
-		registry.Register(engine)
+	saved, err := loadSavedSearches(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load saved searches: %v", err)
+	}
+	if saved != nil {
+		searchClient.SetSavedSearches(saved)
+	}
 
-	case "serpapi":
-		apiKey, err := sv.GetValue(ctx, "SERPAPI_API_KEY")
-		if err != nil {
-			return nil, fmt.Errorf("failed to get SERPAPI_API_KEY from keychain: %w", err)
-		}
-		if apiKey == "" {
-			return nil, fmt.Errorf("SERPAPI_API_KEY not found in keychain. Add it with:\n" +
-				"  security add-generic-password -s \"omnivault\" -a \"SERPAPI_API_KEY\" -w \"your-key\"")
-		}
-		log.Println("SERPAPI_API_KEY retrieved from keychain successfully")
+	if err := runServer(ctx, searchClient, cfg, *configPath); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
 
-		engine, err := serpapi.NewWithAPIKey(apiKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create serpapi engine: %w", err)
-		}
-		registry.Register(engine)
+// setupLogging routes the standard logger through slog at the configured level
+func setupLogging(cfg *Config) {
+	level, _ := parseLogLevel(cfg.LogLevel) // validated by loadConfig
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported engine: %s", engineName)
+// validateKeys checks the credentials of the engines of a client, bounded
+// by keyValidationTimeout
+func validateKeys(ctx context.Context, c *client.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, keyValidationTimeout)
+	defer cancel()
+	if err := c.ValidateKeys(ctx); err != nil {
+		return err
 	}
-
-	return client.NewWithRegistry(registry, engineName)
+	log.Printf("Validated the credentials of %d engines", len(c.GetRegistry().List()))
+	return nil
 }
 
-// runServer starts the MCP server with the configured search client.
-func runServer(ctx context.Context, searchClient *client.Client) {
-	log.Printf("Using engine: %s v%s", searchClient.GetName(), searchClient.GetVersion())
-	log.Printf("Available engines: %v", searchClient.ListEngines())
+// initWithEnvCredentials initializes the client using environment variables.
+func initWithEnvCredentials(engineName string) (*client.Client, error) {
+	return client.NewWithOptions(&client.Options{EngineName: engineName})
+}
 
-	// Create MCP server
+// newServer creates an MCP server without tools; see registerTools. The
+// server has the logging capability, which forwards operational events to
+// clients that set a logging level.
+func newServer() *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "mcp-omniserp",
 		Version: "2.0.0",
 	}, nil)
+	server.AddReceivingMiddleware(sessionMiddleware)
+	return server
+}
+
+// registerTools (re)registers the tools that are supported by the current
+// engine and permitted by the tool filter. Previously registered tools are
+// removed first so the function can be called again on configuration reload.
+func registerTools(server *mcp.Server, searchClient *client.Client, cfg *Config, rt *toolRuntime) {
+	server.RemoveTools(client.AllOperations()...)
 
 	// Define all possible search tools with their operation names
 	allTools := []ToolDefinition{
-		{client.OpSearch, "Perform a Google web search", searchClient.Search},
-		{client.OpSearchNews, "Search for news articles using Google News", searchClient.SearchNews},
-		{client.OpSearchImages, "Search for images using Google Images", searchClient.SearchImages},
-		{client.OpSearchVideos, "Search for videos using Google Videos", searchClient.SearchVideos},
-		{client.OpSearchPlaces, "Search for places using Google Places", searchClient.SearchPlaces},
-		{client.OpSearchMaps, "Search for locations using Google Maps", searchClient.SearchMaps},
-		{client.OpSearchReviews, "Search for reviews", searchClient.SearchReviews},
-		{client.OpSearchShopping, "Search for products using Google Shopping", searchClient.SearchShopping},
-		{client.OpSearchScholar, "Search for academic papers using Google Scholar", searchClient.SearchScholar},
-		{client.OpSearchLens, "Perform visual search using Google Lens", searchClient.SearchLens},
-		{client.OpSearchAutocomplete, "Get search suggestions using Google Autocomplete", searchClient.SearchAutocomplete},
+		{client.OpSearch, "Web Search", "Perform a Google web search", searchClient.Search},
+		{client.OpSearchNews, "News Search", "Search for news articles using Google News", searchClient.SearchNews},
+		{client.OpSearchImages, "Image Search", "Search for images using Google Images", searchClient.SearchImages},
+		{client.OpSearchVideos, "Video Search", "Search for videos using Google Videos", searchClient.SearchVideos},
+		{client.OpSearchPlaces, "Places Search", "Search for places using Google Places", searchClient.SearchPlaces},
+		{client.OpSearchMaps, "Maps Search", "Search for locations using Google Maps", searchClient.SearchMaps},
+		{client.OpSearchReviews, "Reviews Search", "Search for reviews", searchClient.SearchReviews},
+		{client.OpSearchShopping, "Shopping Search", "Search for products using Google Shopping", searchClient.SearchShopping},
+		{client.OpSearchScholar, "Scholar Search", "Search for academic papers using Google Scholar", searchClient.SearchScholar},
+		{client.OpSearchLens, "Lens Search", "Perform visual search using Google Lens", searchClient.SearchLens},
+		{client.OpSearchAutocomplete, "Autocomplete", "Get search suggestions using Google Autocomplete", searchClient.SearchAutocomplete},
 	}
 
 	// Register tools only if supported by the current engine
 	registeredTools := []string{}
 	skippedTools := []string{}
+	filteredTools := []string{}
 
 	for _, tool := range allTools {
+		if !cfg.AllowsTool(tool.Name) {
+			filteredTools = append(filteredTools, tool.Name)
+			continue
+		}
 		if searchClient.SupportsOperation(tool.Name) {
 			// Register this tool
 			toolName := tool.Name
 			toolDesc := tool.Description
 			searchFunc := tool.SearchFunc
 
-			mcp.AddTool(server, &mcp.Tool{
+			mcpTool := &mcp.Tool{
 				Name:        toolName,
 				Description: toolDesc,
-			}, func(ctx context.Context, req *mcp.CallToolRequest, args omniserp.SearchParams) (*mcp.CallToolResult, any, error) {
-				result, err := searchFunc(ctx, args)
+				Annotations: searchAnnotations(tool.Title),
+			}
+			// Without engine-specific parameters the schema is inferred
+			if schema, err := searchSchema(searchClient.GetRegistry(), toolName); err != nil {
+				log.Printf("Failed to describe extra parameters of %s: %v", toolName, err)
+			} else {
+				mcpTool.InputSchema = schema
+			}
+
+			mcp.AddTool(server, mcpTool, func(ctx context.Context, req *mcp.CallToolRequest, args SearchToolArgs) (*mcp.CallToolResult, any, error) {
+				params, err := args.params(toolName)
 				if err != nil {
 					return nil, nil, fmt.Errorf("%s failed: %w", toolName, err)
 				}
-
-				resultJSON, _ := json.MarshalIndent(result.Data, "", "  ")
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: string(resultJSON)},
-					},
-				}, nil, nil
+				ctx, params = rt.sessions.apply(ctx, req.Session, params)
+				// The page is counted before it is trimmed to the token limit
+				var page any
+				result, output, err := rt.call(ctx, toolName, params, func(ctx context.Context) (*omniserp.SearchResult, error) {
+					served, err := searchFunc(ctx, params)
+					if err == nil {
+						page = served.Data
+					}
+					return served, err
+				})
+				if err == nil && paginates(toolName) {
+					addCursor(result, toolName, params, page)
+				}
+				return result, output, err
 			})
 
 			registeredTools = append(registeredTools, tool.Name)
@@ -225,36 +242,214 @@ func runServer(ctx context.Context, searchClient *client.Client) {
 	}
 
 	// Register web scraping tool if supported
-	if searchClient.SupportsOperation(client.OpScrapeWebpage) {
+	switch {
+	case !cfg.AllowsTool(client.OpScrapeWebpage):
+		filteredTools = append(filteredTools, client.OpScrapeWebpage)
+	case searchClient.SupportsOperation(client.OpScrapeWebpage):
 		mcp.AddTool(server, &mcp.Tool{
 			Name:        client.OpScrapeWebpage,
 			Description: "Scrape content from a webpage",
+			Annotations: scrapeAnnotations("Webpage Scrape"),
 		}, func(ctx context.Context, req *mcp.CallToolRequest, args omniserp.ScrapeParams) (*mcp.CallToolResult, any, error) {
-			result, err := searchClient.ScrapeWebpage(ctx, args)
-			if err != nil {
-				return nil, nil, fmt.Errorf("scraping failed: %w", err)
-			}
-
-			resultJSON, _ := json.MarshalIndent(result.Data, "", "  ")
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: string(resultJSON)},
-				},
-			}, nil, nil
+			return rt.call(ctx, client.OpScrapeWebpage, args, func(ctx context.Context) (*omniserp.SearchResult, error) {
+				return searchClient.ScrapeWebpage(ctx, args)
+			})
 		})
 		registeredTools = append(registeredTools, client.OpScrapeWebpage)
-	} else {
+	default:
 		skippedTools = append(skippedTools, client.OpScrapeWebpage)
 	}
 
+	registeredTools = append(registeredTools, registerSavedSearchTools(server, searchClient, cfg, rt)...)
+	registeredTools = append(registeredTools, registerCapabilitiesTool(server, searchClient)...)
+	registeredTools = append(registeredTools, registerSessionTools(server, searchClient, rt)...)
+
 	// Log tool registration summary
 	log.Printf("Registered %d tools: %v", len(registeredTools), registeredTools)
 	if len(skippedTools) > 0 {
 		log.Printf("Skipped %d unsupported tools: %v", len(skippedTools), skippedTools)
 	}
+	if len(filteredTools) > 0 {
+		log.Printf("Filtered out %d tools by configuration: %v", len(filteredTools), filteredTools)
+	}
+}
+
+// toolRuntime holds the state shared by all tool handlers
+type toolRuntime struct {
+	// client serves the tools; its current engine is part of cache keys
+	client *client.Client
+
+	cache   toolCache // nil if caching is disabled
+	limiter *rateLimiter
+	usage   *usageCounter
+	budget  *budget // nil if unlimited
+
+	// maxTokens truncates results to about this many tokens (0 means no limit)
+	maxTokens int
+
+	// name identifies the tenant in alerts; alerts is nil if disabled
+	name   string
+	alerts *alerts.Monitor
+
+	// sessions holds the defaults set by each MCP session
+	sessions sessionStore
+}
+
+// call runs a tool subject to the rate limit and budget, serving and storing
+// its JSON output through the cache if enabled, and records usage. The
+// current engine and the engine preferred by ctx are part of the cache key. Rate limiting, budget
+// exhaustion, cache hits, and engine failovers are reported to the client
+// as log notifications; fn is called with a context reporting failovers.
+func (rt *toolRuntime) call(ctx context.Context, toolName string, args any, fn func(context.Context) (*omniserp.SearchResult, error)) (*mcp.CallToolResult, any, error) {
+	if !rt.limiter.Allow() {
+		rt.usage.record(toolName, outcomeRateLimited)
+		logEvent(ctx, levelWarning, "Rate limit exceeded", map[string]any{"event": "rate_limited", "tool": toolName})
+		return nil, nil, fmt.Errorf("%s failed: %w", toolName, ErrRateLimited)
+	}
+
+	// Search parameters are keyed by fingerprint so trivially different
+	// spellings of a query share cache entries and usage counts
+	var key string
+	if params, ok := args.(omniserp.SearchParams); ok {
+		key = toolName + ":" + params.Fingerprint()
+		rt.usage.recordQuery(params)
+	} else {
+		argsJSON, _ := json.Marshal(args)
+		key = toolName + ":" + string(argsJSON)
+	}
+	if engine, ok := client.EngineFromContext(ctx); ok {
+		key = engine + "/" + key
+	}
+	if rt.client != nil {
+		key = rt.client.GetName() + ">" + key
+	}
+
+	if rt.cache != nil {
+		if text, ok := rt.cache.Get(key); ok {
+			rt.usage.record(toolName, outcomeCacheHit)
+			logEvent(ctx, levelInfo, "Served from cache", map[string]any{"event": "cache_hit", "tool": toolName})
+			return textResult(text), nil, nil
+		}
+	}
+
+	if !rt.budget.spend() {
+		rt.usage.record(toolName, outcomeBudgetExceeded)
+		logEvent(ctx, levelWarning, "Request budget exceeded", map[string]any{"event": "budget_exceeded", "tool": toolName})
+		return nil, nil, fmt.Errorf("%s failed: %w", toolName, ErrBudgetExceeded)
+	}
+	if status := rt.budget.status(); status != nil && rt.alerts != nil {
+		sendAlerts(rt.alerts, rt.alerts.Budget(rt.name, status.Used, status.Limit))
+	}
+
+	result, err := fn(withFailoverEvents(ctx, toolName))
+	if err != nil {
+		rt.usage.record(toolName, outcomeError)
+		return nil, nil, fmt.Errorf("%s failed: %w", toolName, err)
+	}
+	rt.usage.record(toolName, outcomeSuccess)
+
+	data := result.Data
+	if rt.maxTokens > 0 {
+		if trimmed, err := omniserp.TrimData(data, rt.maxTokens); err == nil {
+			data = trimmed
+		}
+	}
+	resultJSON, _ := json.MarshalIndent(data, "", "  ")
+	if rt.cache != nil {
+		rt.cache.Set(key, string(resultJSON))
+	}
+	return textResult(string(resultJSON)), nil, nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+}
+
+// runServer starts the MCP server on the configured transport and reloads
+// the configuration on SIGHUP or when the config file changes.
+func runServer(ctx context.Context, searchClient *client.Client, cfg *Config, configPath string) error {
+	log.Printf("Using engine: %s v%s", searchClient.GetName(), searchClient.GetVersion())
+	log.Printf("Available engines: %v", searchClient.ListEngines())
+
+	server := newServer()
+	rt := &toolRuntime{
+		client:  searchClient,
+		cache:   newToolCache(cfg),
+		limiter: newRateLimiter(cfg.RateLimit, cfg.RateBurst),
+		usage:   newUsageCounter(),
+
+		maxTokens: cfg.MaxResultTokens,
+	}
+	registerTools(server, searchClient, cfg, rt)
+	go newReloader(configPath, cfg, server, searchClient, rt).watch(ctx)
+
+	target := &tenant{client: searchClient, server: server, rt: rt}
+	go watchCredits(ctx, cfg, newAlertMonitor(cfg), []*tenant{target})
+
+	if cfg.Transport == TransportHTTP {
+		return serveHTTP(cfg, server, nil, newAdminHandler(cfg, []*tenant{target}))
+	}
 
 	log.Printf("Starting OmniSerp MCP Server with %s engine...", searchClient.GetName())
-	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
-		log.Printf("Server failed: %v", err)
+	return server.Run(ctx, &mcp.StdioTransport{})
+}
+
+// runMultiTenantServer serves each configured tenant with its own client and
+// MCP server over the HTTP transport. Tenant configuration is not reloaded.
+func runMultiTenantServer(cfg *Config) error {
+	monitor := newAlertMonitor(cfg)
+	tenants, err := newTenants(cfg, monitor)
+	if err != nil {
+		return err
+	}
+	if cfg.ValidateKeys {
+		for _, t := range tenants {
+			if err := validateKeys(context.Background(), t.client); err != nil {
+				return fmt.Errorf("tenant %s: %w", t.name, err)
+			}
+		}
+	}
+	go watchCredits(context.Background(), cfg, monitor, tenants)
+	return serveHTTP(cfg, nil, tenants, newAdminHandler(cfg, tenants))
+}
+
+// serveHTTP serves the MCP streamable HTTP transport at /mcp and the admin
+// endpoints under /admin/ if enabled. With tenants, /mcp requires a tenant
+// API key and each tenant is served by its own MCP server instead of server.
+func serveHTTP(cfg *Config, server *mcp.Server, tenants []*tenant, admin http.Handler) error {
+	var mcpHandler http.Handler = mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		if t := tenantFromContext(r.Context()); t != nil {
+			return t.server
+		}
+		return server
+	}, nil)
+	if len(tenants) > 0 {
+		mcpHandler = requireTenant(tenants, mcpHandler)
+		log.Printf("Serving %d tenants: %v", len(tenants), tenantNames(tenants))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcpHandler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if admin != nil {
+		mux.Handle("/admin/", admin)
+	}
+
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Starting OmniSerp MCP Server on http://localhost:%d/mcp", cfg.Port)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
 }
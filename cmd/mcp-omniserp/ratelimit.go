@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a tool call exceeds the configured rate limit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// rateLimiter is a token bucket whose limits can be changed at runtime
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second; zero means unlimited
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	l := &rateLimiter{}
+	l.SetLimit(rate, burst)
+	return l
+}
+
+// SetLimit changes the rate (requests per second) and burst size.
+// A rate of zero disables limiting.
+func (l *rateLimiter) SetLimit(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	l.rate = rate
+	l.burst = float64(burst)
+	l.tokens = l.burst
+	l.last = time.Now()
+}
+
+// Allow reports whether a request may proceed, consuming a token if so
+func (l *rateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
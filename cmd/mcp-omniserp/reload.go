@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp/client"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+// reloader applies configuration changes to a running server. Engine
+// selection, routes, tool filters, rate limits, and the log level take effect
+// immediately; transport, port, cache, admin, and tenant settings require
+// a restart.
+type reloader struct {
+	path   string
+	server *mcp.Server
+	client *client.Client
+	rt     *toolRuntime
+
+	mu      sync.Mutex
+	cfg     *Config
+	modTime time.Time
+}
+
+func newReloader(path string, cfg *Config, server *mcp.Server, searchClient *client.Client, rt *toolRuntime) *reloader {
+	r := &reloader{
+		path:   path,
+		server: server,
+		client: searchClient,
+		rt:     rt,
+		cfg:    cfg,
+	}
+	r.modTime, _ = r.configModTime()
+	return r
+}
+
+// watch reloads the configuration on SIGHUP or when the config file changes
+// until ctx is done
+func (r *reloader) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	if len(reloadSignals) > 0 {
+		signal.Notify(hup, reloadSignals...)
+		defer signal.Stop(hup)
+	}
+
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload("SIGHUP")
+		case <-ticker.C:
+			if r.configChanged() {
+				r.reload("config file change")
+			}
+		}
+	}
+}
+
+// configModTime returns the modification time of the config file
+func (r *reloader) configModTime() (time.Time, error) {
+	if r.path == "" {
+		return time.Time{}, nil
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// configChanged reports whether the config file was modified since last seen
+func (r *reloader) configChanged() bool {
+	modTime, err := r.configModTime()
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if modTime.Equal(r.modTime) {
+		return false
+	}
+	r.modTime = modTime
+	return true
+}
+
+// reload loads and applies the configuration, keeping the current one if
+// the new configuration is invalid
+func (r *reloader) reload(reason string) {
+	log.Printf("Reloading configuration (%s)", reason)
+
+	cfg, err := loadConfig(r.path)
+	if err != nil {
+		log.Printf("Config reload failed, keeping current configuration: %v", err)
+		return
+	}
+	if err := r.apply(cfg); err != nil {
+		log.Printf("Config reload failed, keeping current configuration: %v", err)
+		return
+	}
+	log.Printf("Configuration reloaded: engine=%s", r.client.GetName())
+}
+
+// apply updates the running server to match cfg
+func (r *reloader) apply(cfg *Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Cached results are keyed by the current engine but not by routes,
+	// so they are dropped when requests may be served by another engine
+	engineChanged := cfg.Engine != "" && cfg.Engine != r.client.GetName()
+	routesChanged := !maps.Equal(cfg.Routes, r.cfg.Routes)
+	if (engineChanged || routesChanged) && r.rt.cache != nil {
+		defer r.rt.cache.Flush()
+	}
+
+	if engineChanged {
+		if err := r.client.SetEngine(cfg.Engine); err != nil {
+			return err
+		}
+	}
+
+	if routesChanged {
+		if len(cfg.Routes) == 0 {
+			r.client.SetSelectionPolicy(nil)
+		} else if err := r.client.SetRoutes(cfg.Routes); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Transport != r.cfg.Transport || cfg.Port != r.cfg.Port ||
+		cfg.Cache != r.cfg.Cache || cfg.CacheTTL != r.cfg.CacheTTL ||
+		cfg.AdminToken != r.cfg.AdminToken || cfg.MaxResultTokens != r.cfg.MaxResultTokens ||
+		cfg.SavedSearches != r.cfg.SavedSearches || len(cfg.Tenants) > 0 {
+		log.Printf("Transport, port, cache, admin, result truncation, saved search, and tenant changes take effect after a restart")
+		cfg.Transport, cfg.Port = r.cfg.Transport, r.cfg.Port
+		cfg.Cache, cfg.CacheTTL = r.cfg.Cache, r.cfg.CacheTTL
+		cfg.AdminToken, cfg.Tenants = r.cfg.AdminToken, nil
+		cfg.MaxResultTokens, cfg.SavedSearches = r.cfg.MaxResultTokens, r.cfg.SavedSearches
+	}
+
+	setupLogging(cfg)
+	r.rt.limiter.SetLimit(cfg.RateLimit, cfg.RateBurst)
+	registerTools(r.server, r.client, cfg, r.rt)
+
+	r.cfg = cfg
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !js && !wasip1
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals trigger a configuration reload
+var reloadSignals = []os.Signal{syscall.SIGHUP}
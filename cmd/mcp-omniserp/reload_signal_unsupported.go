@@ -0,0 +1,9 @@
+//go:build js || wasip1
+
+package main
+
+import "os"
+
+// reloadSignals is empty because signals are not available on this
+// platform; the config file is still watched for changes
+var reloadSignals []os.Signal
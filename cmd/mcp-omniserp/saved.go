@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// Saved search tools, registered when saved searches are configured
+const (
+	ToolRunSavedSearch    = "run_saved_search"
+	ToolListSavedSearches = "list_saved_searches"
+)
+
+// RunSavedSearchArgs are the arguments of the run_saved_search tool
+type RunSavedSearchArgs struct {
+	Name string `json:"name" jsonschema:"description:Name of the saved search to run"`
+}
+
+// loadSavedSearches loads the configured saved searches, or returns nil if
+// none are configured
+func loadSavedSearches(cfg *Config) (*client.SavedSearches, error) {
+	if cfg.SavedSearches == "" {
+		return nil, nil
+	}
+	return client.LoadSavedSearches(cfg.SavedSearches)
+}
+
+// registerSavedSearchTools registers the saved search tools if saved
+// searches are configured. Saved searches are run like the other tools,
+// subject to the rate limit, budget, and cache.
+func registerSavedSearchTools(server *mcp.Server, searchClient *client.Client, cfg *Config, rt *toolRuntime) []string {
+	server.RemoveTools(ToolRunSavedSearch, ToolListSavedSearches)
+	if cfg.SavedSearches == "" {
+		return nil
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        ToolRunSavedSearch,
+		Description: "Run a saved search by name and return its normalized results",
+		Annotations: searchAnnotations("Run Saved Search"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args RunSavedSearchArgs) (*mcp.CallToolResult, any, error) {
+		return rt.call(ctx, ToolRunSavedSearch, args, func(ctx context.Context) (*omniserp.SearchResult, error) {
+			result, err := searchClient.RunSaved(ctx, args.Name)
+			if err != nil {
+				return nil, err
+			}
+			result.Raw = nil
+			return &omniserp.SearchResult{Data: result}, nil
+		})
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        ToolListSavedSearches,
+		Description: "List the saved searches with their queries and schedules",
+		Annotations: localAnnotations("Saved Searches"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		output, _ := json.MarshalIndent(searchClient.SavedSearches().List(), "", "  ")
+		return textResult(string(output)), nil, nil
+	})
+
+	return []string{ToolRunSavedSearch, ToolListSavedSearches}
+}
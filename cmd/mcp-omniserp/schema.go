@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/plexusone/omniserp"
+)
+
+// searchSchema returns the input schema of a search tool, with the extra
+// parameters of the engines supporting operation listed under "extra" so
+// agents can discover them. Parameters accepted by several engines are
+// described once per engine. The query is optional so a cursor can be given
+// alone.
+func searchSchema(registry *omniserp.Registry, operation string) (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[SearchToolArgs](nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer the schema of %s: %w", operation, err)
+	}
+	schema.Required = slices.DeleteFunc(schema.Required, func(name string) bool {
+		return name == "query"
+	})
+	extra, ok := schema.Properties["extra"]
+	if !ok {
+		return schema, nil
+	}
+
+	names := registry.List()
+	slices.Sort(names)
+	descriptions := make(map[string][]string)
+	for _, name := range names {
+		engine, _ := registry.Get(name)
+		if !slices.Contains(engine.GetSupportedTools(), operation) {
+			continue
+		}
+		for _, param := range omniserp.ExtraParams(engine) {
+			prop, ok := extra.Properties[param.Name]
+			if !ok {
+				if extra.Properties == nil {
+					extra.Properties = make(map[string]*jsonschema.Schema)
+				}
+				prop = &jsonschema.Schema{Type: param.Type}
+				extra.Properties[param.Name] = prop
+			} else if prop.Type != param.Type {
+				// Engines disagree on the type, so any value is accepted
+				prop.Type = ""
+			}
+			descriptions[param.Name] = append(descriptions[param.Name], name+": "+param.Description)
+		}
+	}
+	for name, prop := range extra.Properties {
+		prop.Description = strings.Join(descriptions[name], "; ")
+	}
+	return schema, nil
+}
@@ -0,0 +1,113 @@
+//go:build !js && !wasip1
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	keyring "github.com/plexusone/omnivault-keyring"
+	"github.com/plexusone/vaultguard"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/client/serpapi"
+	"github.com/plexusone/omniserp/client/serper"
+)
+
+// initClient initializes the search client from the OS keychain when a
+// VaultGuard policy is configured, or from environment variables otherwise.
+func initClient(ctx context.Context, engineName string) (*client.Client, error) {
+	// Load policy from config files (or nil for permissive mode)
+	policy, err := vaultguard.LoadPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+
+	if policy == nil {
+		log.Println("No policy configured - using environment variables")
+		return initWithEnvCredentials(engineName)
+	}
+	log.Println("Policy loaded - using secure credential access")
+	return initWithSecureCredentials(ctx, policy, engineName)
+}
+
+// initWithSecureCredentials initializes the client using VaultGuard and OS keychain.
+func initWithSecureCredentials(ctx context.Context, policy *vaultguard.Policy, engineName string) (*client.Client, error) {
+	// Create keyring provider for OS credential store
+	keyringVault := keyring.New(keyring.Config{
+		ServiceName: "omnivault",
+	})
+
+	// Create VaultGuard with the keyring and loaded policy
+	sv, err := vaultguard.New(&vaultguard.Config{
+		CustomVault: keyringVault,
+		Policy:      policy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("security check failed: %w", err)
+	}
+	defer sv.Close()
+
+	// Log security status
+	result := sv.SecurityResult()
+	if result != nil {
+		log.Printf("Security check passed: score=%d, level=%s", result.Score, result.Level)
+		if result.Details.Local != nil {
+			log.Printf("  Platform: %s, Encrypted: %v, Biometrics: %v",
+				result.Details.Local.Platform,
+				result.Details.Local.DiskEncrypted,
+				result.Details.Local.BiometricsConfigured)
+		}
+	}
+
+	// Determine which engine to use
+	if engineName == "" {
+		engineName = "serper"
+	}
+
+	// Create registry and register engines with secure credentials
+	registry := omniserp.NewRegistry()
+
+	switch engineName {
+	case "serper":
+		apiKey, err := sv.GetValue(ctx, "SERPER_API_KEY")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SERPER_API_KEY from keychain: %w", err)
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("SERPER_API_KEY not found in keychain. Add it with:\n" +
+				"  security add-generic-password -s \"omnivault\" -a \"SERPER_API_KEY\" -w \"your-key\"")
+		}
+		log.Println("SERPER_API_KEY retrieved from keychain successfully")
+
+		engine, err := serper.NewWithAPIKey(apiKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create serper engine: %w", err)
+		}
+		registry.Register(engine)
+
+	case "serpapi":
+		apiKey, err := sv.GetValue(ctx, "SERPAPI_API_KEY")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SERPAPI_API_KEY from keychain: %w", err)
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("SERPAPI_API_KEY not found in keychain. Add it with:\n" +
+				"  security add-generic-password -s \"omnivault\" -a \"SERPAPI_API_KEY\" -w \"your-key\"")
+		}
+		log.Println("SERPAPI_API_KEY retrieved from keychain successfully")
+
+		engine, err := serpapi.NewWithAPIKey(apiKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create serpapi engine: %w", err)
+		}
+		registry.Register(engine)
+
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", engineName)
+	}
+
+	return client.NewWithRegistry(registry, engineName)
+}
@@ -0,0 +1,17 @@
+//go:build js || wasip1
+
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/plexusone/omniserp/client"
+)
+
+// initClient initializes the search client from environment variables.
+// Secure mode depends on the OS keychain, which is not available on this platform.
+func initClient(ctx context.Context, engineName string) (*client.Client, error) {
+	log.Println("Secure credential mode is not supported on this platform - using environment variables")
+	return initWithEnvCredentials(engineName)
+}
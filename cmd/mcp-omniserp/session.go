@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// ToolSetSessionDefaults pins the engine and search parameters of a session
+const ToolSetSessionDefaults = "set_session_defaults"
+
+// SessionDefaults are the engine and search parameters applied to the
+// searches of an MCP session, and the arguments of the set_session_defaults
+// tool
+type SessionDefaults struct {
+	Engine     string             `json:"engine,omitempty" jsonschema:"description:Engine to run the searches of the session"`
+	Location   string             `json:"location,omitempty" jsonschema:"description:Default search location"`
+	Language   string             `json:"language,omitempty" jsonschema:"description:Default search language (e.g., 'en')"`
+	Country    string             `json:"country,omitempty" jsonschema:"description:Default country code (e.g., 'us')"`
+	Locale     string             `json:"locale,omitempty" jsonschema:"description:Default country and language preset (e.g., 'de-DE'); explicit language and country take precedence"`
+	NumResults int                `json:"num_results,omitempty" jsonschema:"description:Default number of results"`
+	Freshness  omniserp.Freshness `json:"freshness,omitempty" jsonschema:"description:Default recency: hour, day, week, month, or year"`
+	SafeSearch bool               `json:"safe_search,omitempty" jsonschema:"description:Filter explicit results in every search of the session"`
+}
+
+// SetSessionDefaultsArgs are the arguments of the set_session_defaults tool
+type SetSessionDefaultsArgs struct {
+	SessionDefaults
+	Clear bool `json:"clear,omitempty" jsonschema:"description:Remove the current defaults before applying the others"`
+}
+
+// params returns the defaults as search parameters
+func (d SessionDefaults) params() omniserp.SearchParams {
+	return omniserp.SearchParams{
+		Location:   d.Location,
+		Language:   d.Language,
+		Country:    d.Country,
+		Locale:     d.Locale,
+		NumResults: d.NumResults,
+		Freshness:  d.Freshness,
+		SafeSearch: d.SafeSearch,
+	}
+}
+
+// sessionStore holds the defaults of each MCP session. Entries are removed
+// when their session closes, so one HTTP deployment can serve many clients
+// with different defaults. The zero value is ready to use.
+type sessionStore struct {
+	mu       sync.Mutex
+	defaults map[*mcp.ServerSession]SessionDefaults
+}
+
+// get returns the defaults of a session
+func (s *sessionStore) get(session *mcp.ServerSession) SessionDefaults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.defaults[session]
+}
+
+// update applies args to the defaults of a session and returns the result.
+// Set fields replace the current ones; unset fields keep them.
+func (s *sessionStore) update(session *mcp.ServerSession, args SetSessionDefaultsArgs) SessionDefaults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, known := s.defaults[session]
+	if args.Clear {
+		current = SessionDefaults{}
+	}
+	if args.Engine != "" {
+		current.Engine = args.Engine
+	}
+	params := args.params().MergeDefaults(current.params())
+	current.Location = params.Location
+	current.Language = params.Language
+	current.Country = params.Country
+	current.Locale = params.Locale
+	current.NumResults = params.NumResults
+	current.Freshness = params.Freshness
+	current.SafeSearch = params.SafeSearch
+
+	if s.defaults == nil {
+		s.defaults = make(map[*mcp.ServerSession]SessionDefaults)
+	}
+	s.defaults[session] = current
+	if !known && session != nil {
+		go func() {
+			_ = session.Wait()
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.defaults, session)
+		}()
+	}
+	return current
+}
+
+// apply returns ctx and params with the defaults of a session: unset
+// parameters are filled in, so they are part of the cache key, and the
+// session engine is preferred by the client
+func (s *sessionStore) apply(ctx context.Context, session *mcp.ServerSession, params omniserp.SearchParams) (context.Context, omniserp.SearchParams) {
+	defaults := s.get(session)
+	if defaults.Engine != "" {
+		ctx = client.ContextWithEngine(ctx, defaults.Engine)
+	}
+	return ctx, params.MergeDefaults(defaults.params())
+}
+
+// registerSessionTools registers the tool setting the defaults of the
+// calling session. The defaults apply to the search tools, not to saved
+// searches, which keep their own parameters.
+func registerSessionTools(server *mcp.Server, searchClient *client.Client, rt *toolRuntime) []string {
+	server.RemoveTools(ToolSetSessionDefaults)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        ToolSetSessionDefaults,
+		Description: "Set the engine and default search parameters (location, language, country, locale, number of results, freshness, safe search) for the rest of this session; returns the defaults in effect",
+		Annotations: sessionAnnotations("Session Defaults"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args SetSessionDefaultsArgs) (*mcp.CallToolResult, any, error) {
+		if args.Engine != "" {
+			if _, err := searchClient.GetEngine(args.Engine); err != nil {
+				return nil, nil, fmt.Errorf("%s failed: %w", ToolSetSessionDefaults, err)
+			}
+		}
+		if args.Locale != "" {
+			if _, err := omniserp.ParseLocale(args.Locale); err != nil {
+				return nil, nil, fmt.Errorf("%s failed: %w", ToolSetSessionDefaults, err)
+			}
+		}
+		if args.Freshness != "" && args.Freshness.TBS() == "" {
+			return nil, nil, fmt.Errorf("%s failed: invalid freshness %q", ToolSetSessionDefaults, args.Freshness)
+		}
+		output, _ := json.MarshalIndent(rt.sessions.update(req.Session, args), "", "  ")
+		return textResult(string(output)), nil, nil
+	})
+
+	return []string{ToolSetSessionDefaults}
+}
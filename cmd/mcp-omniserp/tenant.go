@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/alerts"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/client/apify"
+	"github.com/plexusone/omniserp/client/baidu"
+	"github.com/plexusone/omniserp/client/exa"
+	"github.com/plexusone/omniserp/client/firecrawl"
+	"github.com/plexusone/omniserp/client/jina"
+	"github.com/plexusone/omniserp/client/kagi"
+	"github.com/plexusone/omniserp/client/mojeek"
+	"github.com/plexusone/omniserp/client/perplexity"
+	"github.com/plexusone/omniserp/client/scaleserp"
+	"github.com/plexusone/omniserp/client/searchapi"
+	"github.com/plexusone/omniserp/client/serpapi"
+	"github.com/plexusone/omniserp/client/serper"
+	"github.com/plexusone/omniserp/client/tavily"
+	"github.com/plexusone/omniserp/client/valueserp"
+	"github.com/plexusone/omniserp/client/youcom"
+	"github.com/plexusone/omniserp/client/zenserp"
+)
+
+// ErrBudgetExceeded is returned when a tenant has used up its request budget
+var ErrBudgetExceeded = errors.New("request budget exceeded")
+
+// Budget periods
+const (
+	BudgetDay   = "day"
+	BudgetMonth = "month"
+)
+
+// engineFactories create engines from an API key, keyed by engine name
+var engineFactories = map[string]func(apiKey string) (omniserp.Engine, error){
+	"serper": func(apiKey string) (omniserp.Engine, error) {
+		return serper.NewWithAPIKey(apiKey)
+	},
+	"serpapi": func(apiKey string) (omniserp.Engine, error) {
+		return serpapi.NewWithAPIKey(apiKey)
+	},
+	"serpapi-bing": func(apiKey string) (omniserp.Engine, error) {
+		return serpapi.NewFamilyWithAPIKey(apiKey, serpapi.FamilyBing)
+	},
+	"serpapi-yandex": func(apiKey string) (omniserp.Engine, error) {
+		return serpapi.NewFamilyWithAPIKey(apiKey, serpapi.FamilyYandex)
+	},
+	"kagi": func(apiKey string) (omniserp.Engine, error) {
+		return kagi.NewWithAPIKey(apiKey)
+	},
+	"tavily": func(apiKey string) (omniserp.Engine, error) {
+		return tavily.NewWithAPIKey(apiKey)
+	},
+	"exa": func(apiKey string) (omniserp.Engine, error) {
+		return exa.NewWithAPIKey(apiKey)
+	},
+	"youcom": func(apiKey string) (omniserp.Engine, error) {
+		return youcom.NewWithAPIKey(apiKey)
+	},
+	"mojeek": func(apiKey string) (omniserp.Engine, error) {
+		return mojeek.NewWithAPIKey(apiKey)
+	},
+	"baidu": func(apiKey string) (omniserp.Engine, error) {
+		return baidu.NewWithAPIKey(apiKey)
+	},
+	"valueserp": func(apiKey string) (omniserp.Engine, error) {
+		return valueserp.NewWithAPIKey(apiKey)
+	},
+	"scaleserp": func(apiKey string) (omniserp.Engine, error) {
+		return scaleserp.NewWithAPIKey(apiKey)
+	},
+	"zenserp": func(apiKey string) (omniserp.Engine, error) {
+		return zenserp.NewWithAPIKey(apiKey)
+	},
+	"searchapi": func(apiKey string) (omniserp.Engine, error) {
+		return searchapi.NewWithAPIKey(apiKey)
+	},
+	"apify": func(apiKey string) (omniserp.Engine, error) {
+		return apify.NewWithAPIKey(apiKey)
+	},
+	"perplexity": func(apiKey string) (omniserp.Engine, error) {
+		return perplexity.NewWithAPIKey(apiKey)
+	},
+	"jina": func(apiKey string) (omniserp.Engine, error) {
+		return jina.NewWithAPIKey(apiKey)
+	},
+	"firecrawl": func(apiKey string) (omniserp.Engine, error) {
+		return firecrawl.NewWithAPIKey(apiKey)
+	},
+}
+
+// TenantConfig maps one client API key to its own engine credentials,
+// rate limits, and budget. Secret values may be given as "env:VAR" to read
+// them from the environment instead of the config file.
+type TenantConfig struct {
+	// Name identifies the tenant in logs and admin endpoints
+	Name string `json:"name"`
+
+	// APIKey is the key clients send as "Authorization: Bearer <key>" or
+	// in the X-API-Key header
+	APIKey string `json:"api_key"`
+
+	// Engine is the engine to use (default: the first credential in name order)
+	Engine string `json:"engine,omitempty"`
+
+	// Credentials maps engine names to the tenant's engine API keys
+	Credentials map[string]string `json:"credentials"`
+
+	// RateLimit and RateBurst limit the tenant's tool calls per second
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty"`
+
+	// Budget is the maximum number of engine requests per BudgetPeriod;
+	// zero means unlimited. Cache hits do not count against the budget.
+	Budget int `json:"budget,omitempty"`
+
+	// BudgetPeriod is "day" or "month" (default), in UTC
+	BudgetPeriod string `json:"budget_period,omitempty"`
+}
+
+// resolveSecret returns the value of an "env:VAR" reference, or s itself
+func resolveSecret(s string) string {
+	if name, ok := strings.CutPrefix(s, "env:"); ok {
+		return os.Getenv(name)
+	}
+	return s
+}
+
+// validate checks one tenant configuration
+func (t *TenantConfig) validate() []error {
+	var errs []error
+	prefix := fmt.Sprintf("tenants[%s]", t.Name)
+
+	if t.Name == "" {
+		errs = append(errs, errors.New("tenants: name is required"))
+	}
+	if resolveSecret(t.APIKey) == "" {
+		errs = append(errs, fmt.Errorf("%s.api_key: required (an env: reference must name a set variable)", prefix))
+	}
+	if len(t.Credentials) == 0 {
+		errs = append(errs, fmt.Errorf("%s.credentials: at least one engine API key is required", prefix))
+	}
+	for engine, key := range t.Credentials {
+		if _, ok := engineFactories[engine]; !ok {
+			errs = append(errs, fmt.Errorf("%s.credentials: unknown engine %q", prefix, engine))
+		} else if resolveSecret(key) == "" {
+			errs = append(errs, fmt.Errorf("%s.credentials.%s: API key is empty", prefix, engine))
+		}
+	}
+	if t.Engine != "" {
+		if _, ok := t.Credentials[t.Engine]; !ok {
+			errs = append(errs, fmt.Errorf("%s.engine: no credentials for engine %q", prefix, t.Engine))
+		}
+	}
+	if t.RateLimit < 0 || t.RateBurst < 0 {
+		errs = append(errs, fmt.Errorf("%s: rate_limit and rate_burst must not be negative", prefix))
+	}
+	if t.Budget < 0 {
+		errs = append(errs, fmt.Errorf("%s.budget: must not be negative, got %d", prefix, t.Budget))
+	}
+	if t.BudgetPeriod != "" && t.BudgetPeriod != BudgetDay && t.BudgetPeriod != BudgetMonth {
+		errs = append(errs, fmt.Errorf("%s.budget_period: must be %q or %q, got %q", prefix, BudgetDay, BudgetMonth, t.BudgetPeriod))
+	}
+	return errs
+}
+
+// validateTenants checks all tenants, including name and API key uniqueness
+func validateTenants(tenants []TenantConfig) []error {
+	var errs []error
+	names := make(map[string]bool)
+	keys := make(map[string]string)
+
+	for i := range tenants {
+		t := &tenants[i]
+		errs = append(errs, t.validate()...)
+
+		if names[t.Name] {
+			errs = append(errs, fmt.Errorf("tenants: duplicate name %q", t.Name))
+		}
+		names[t.Name] = true
+
+		if key := resolveSecret(t.APIKey); key != "" {
+			if other, ok := keys[key]; ok {
+				errs = append(errs, fmt.Errorf("tenants: %q and %q share an API key", other, t.Name))
+			}
+			keys[key] = t.Name
+		}
+	}
+	return errs
+}
+
+// tenant is a configured tenant with its own client, MCP server, and quotas
+type tenant struct {
+	name   string
+	apiKey string
+	client *client.Client
+	server *mcp.Server
+	rt     *toolRuntime
+}
+
+// newTenants creates a client and MCP server per configured tenant. Budget
+// alerts are sent through monitor if it is non-nil.
+func newTenants(cfg *Config, monitor *alerts.Monitor) ([]*tenant, error) {
+	saved, err := loadSavedSearches(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved searches: %w", err)
+	}
+
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	for _, tc := range cfg.Tenants {
+		registry := omniserp.NewRegistry()
+		engineNames := make([]string, 0, len(tc.Credentials))
+		apiKeys := make([]string, 0, len(tc.Credentials))
+		for engineName, key := range tc.Credentials {
+			apiKeys = append(apiKeys, resolveSecret(key))
+			engine, err := engineFactories[engineName](resolveSecret(key))
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s: failed to create %s engine: %w", tc.Name, engineName, err)
+			}
+			registry.Register(engine)
+			engineNames = append(engineNames, engineName)
+		}
+
+		engineName := tc.Engine
+		if engineName == "" {
+			sort.Strings(engineNames)
+			engineName = engineNames[0]
+		}
+		searchClient, err := client.NewWithRegistry(registry, engineName)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tc.Name, err)
+		}
+		searchClient.SetSanitizer(cfg.newSanitizer(apiKeys...))
+		if saved != nil {
+			searchClient.SetSavedSearches(saved)
+		}
+
+		t := &tenant{
+			name:   tc.Name,
+			apiKey: resolveSecret(tc.APIKey),
+			client: searchClient,
+			server: newServer(),
+			rt: &toolRuntime{
+				client:  searchClient,
+				cache:   newToolCache(cfg),
+				limiter: newRateLimiter(tc.RateLimit, tc.RateBurst),
+				usage:   newUsageCounter(),
+				budget:  newBudget(tc.Budget, tc.BudgetPeriod),
+				name:    tc.Name,
+				alerts:  monitor,
+
+				maxTokens: cfg.MaxResultTokens,
+			},
+		}
+		registerTools(t.server, t.client, cfg, t.rt)
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+type tenantKey struct{}
+
+// tenantFromContext returns the tenant authenticated by requireTenant
+func tenantFromContext(ctx context.Context) *tenant {
+	t, _ := ctx.Value(tenantKey{}).(*tenant)
+	return t
+}
+
+// requireTenant authenticates requests by client API key and stores the
+// matching tenant in the request context
+func requireTenant(tenants []*tenant, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+
+		// Compare against every key so timing does not reveal which matched
+		var match *tenant
+		for _, t := range tenants {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(t.apiKey)) == 1 {
+				match = t
+			}
+		}
+		if key == "" || match == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, match)))
+	})
+}
+
+// tenantNames returns the names of the tenants in order
+func tenantNames(tenants []*tenant) []string {
+	names := make([]string, len(tenants))
+	for i, t := range tenants {
+		names[i] = t.name
+	}
+	return names
+}
+
+// BudgetStatus reports a tenant's budget consumption in the current period
+type BudgetStatus struct {
+	Limit  int    `json:"limit"`
+	Used   int    `json:"used"`
+	Period string `json:"period"`
+	Resets string `json:"resets"`
+}
+
+// budget limits engine requests per calendar day or month (UTC)
+type budget struct {
+	limit  int
+	period string
+
+	mu     sync.Mutex
+	used   int
+	resets time.Time
+}
+
+// newBudget returns a budget, or nil if limit is zero (unlimited)
+func newBudget(limit int, period string) *budget {
+	if limit <= 0 {
+		return nil
+	}
+	if period == "" {
+		period = BudgetMonth
+	}
+	b := &budget{limit: limit, period: period}
+	b.resets = b.nextReset(time.Now())
+	return b
+}
+
+// nextReset returns the start of the period following now
+func (b *budget) nextReset(now time.Time) time.Time {
+	now = now.UTC()
+	if b.period == BudgetDay {
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// spend consumes one request, returning false if the budget is exhausted.
+// A nil budget is unlimited.
+func (b *budget) spend() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now := time.Now(); !now.Before(b.resets) {
+		b.used = 0
+		b.resets = b.nextReset(now)
+	}
+	if b.used >= b.limit {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// status returns the budget consumption, or nil for an unlimited budget
+func (b *budget) status() *BudgetStatus {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	used := b.used
+	if !time.Now().Before(b.resets) {
+		used = 0
+	}
+	return &BudgetStatus{
+		Limit:  b.limit,
+		Used:   used,
+		Period: b.period,
+		Resets: b.resets.Format(time.RFC3339),
+	}
+}
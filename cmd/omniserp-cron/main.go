@@ -0,0 +1,143 @@
+// omniserp-cron runs saved searches on their cron schedules as a
+// self-contained monitoring service.
+//
+// Saved searches are managed with "omniserp saved". Every run is appended to
+// a JSON Lines history file, and runs that find results not returned by
+// earlier runs of the same search trigger the configured webhook and export:
+//
+//	omniserp saved add --operation news --schedule "*/30 * * * *" acme acme corp
+//	omniserp-cron --webhook https://hooks.slack.com/services/... --export new.jsonl
+//
+// New results are also posted to the webhook of their saved search, if it
+// declares one, rendered with its template.
+//
+// Flags default to these environment variables:
+//
+//	OMNISERP_SAVED_SEARCHES   saved searches file (default saved-searches.json)
+//	OMNISERP_HISTORY          run history file (default omniserp-history.jsonl)
+//	OMNISERP_CRON_WEBHOOK_URL webhook for new results and price changes
+//	OMNISERP_PRICE_WATCH      products to watch (see below)
+//	OMNISERP_ENGINE           search engine (SEARCH_ENGINE is also accepted)
+//
+// With --price-watch, the prices of the products in a JSON file are tracked
+// in the shopping results of the saved searches, and price changes are sent
+// to the webhook, or logged without one. Prices continue from the history
+// across restarts:
+//
+//	[{"name": "Gopher plush", "product_id": "1234567890", "search": "gopher-plush"}]
+//
+// The saved searches file is read at startup; restart to pick up changes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/plexusone/omniserp/alerts"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/pricewatch"
+	"github.com/plexusone/omniserp/scheduler"
+)
+
+func main() {
+	savedPath := flag.String("saved", envOr("OMNISERP_SAVED_SEARCHES", "saved-searches.json"), "saved searches file")
+	historyPath := flag.String("history", envOr("OMNISERP_HISTORY", "omniserp-history.jsonl"), "run history file")
+	webhook := flag.String("webhook", os.Getenv("OMNISERP_CRON_WEBHOOK_URL"), "webhook URL notified of new results and price changes")
+	exportPath := flag.String("export", "", "JSON Lines file that new results are appended to")
+	engine := flag.String("engine", os.Getenv("OMNISERP_ENGINE"), "search engine")
+	priceWatch := flag.String("price-watch", os.Getenv("OMNISERP_PRICE_WATCH"), "JSON file of products whose prices are watched")
+	minChange := flag.Float64("price-min-change", 0, "minimum relative price change to report, such as 0.05 for 5%")
+	once := flag.Bool("once", false, "run every scheduled search once and exit")
+	flag.Parse()
+
+	saved, err := client.LoadSavedSearches(*savedPath)
+	if err != nil {
+		log.Fatalf("Failed to load saved searches: %v", err)
+	}
+	history, err := scheduler.OpenHistory(*historyPath)
+	if err != nil {
+		log.Fatalf("Failed to open history: %v", err)
+	}
+	searchClient, err := client.NewWithOptions(&client.Options{EngineName: *engine})
+	if err != nil {
+		log.Fatalf("Failed to initialize search client: %v", err)
+	}
+	searchClient.SetSavedSearches(saved)
+
+	runner := &scheduler.Runner{Client: searchClient, History: history}
+	runner.Hooks = append(runner.Hooks, scheduler.SavedWebhookHook(saved, nil))
+	if *webhook != "" {
+		runner.Hooks = append(runner.Hooks, scheduler.WebhookHook(*webhook, nil))
+	}
+	if *exportPath != "" {
+		runner.Hooks = append(runner.Hooks, scheduler.ExportHook(*exportPath))
+	}
+	if *priceWatch != "" {
+		products, err := pricewatch.LoadProducts(*priceWatch)
+		if err != nil {
+			log.Fatalf("Failed to load watched products: %v", err)
+		}
+		runs, err := scheduler.ReadHistory(*historyPath)
+		if err != nil {
+			log.Fatalf("Failed to read history: %v", err)
+		}
+		watcher := pricewatch.NewWatcher(products...)
+		watcher.MinChange = *minChange
+		watcher.Replay(runs)
+
+		var notifier alerts.Notifier = logNotifier{}
+		if *webhook != "" {
+			notifier = alerts.NewWebhook(*webhook)
+		}
+		runner.Observers = append(runner.Observers, watcher.Hook(notifier))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		for _, search := range saved.List() {
+			if search.Schedule == "" {
+				continue
+			}
+			run, err := runner.RunOnce(ctx, search.Name)
+			if err != nil {
+				log.Printf("Saved search %s failed: %v", search.Name, err)
+				continue
+			}
+			if run.Baseline {
+				log.Printf("Saved search %s: recorded baseline", search.Name)
+			} else {
+				log.Printf("Saved search %s: %d new results", search.Name, len(run.New))
+			}
+		}
+		return
+	}
+
+	log.Printf("Running %d saved searches from %s", len(saved.List()), *savedPath)
+	if err := runner.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Runner failed: %v", err)
+	}
+}
+
+// logNotifier logs alerts when no webhook is configured
+type logNotifier struct{}
+
+// Notify implements alerts.Notifier
+func (logNotifier) Notify(ctx context.Context, alert alerts.Alert) error {
+	log.Print(alert.Message)
+	return nil
+}
+
+// envOr returns the environment variable, or fallback if it is not set
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
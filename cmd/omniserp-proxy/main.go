@@ -0,0 +1,77 @@
+// omniserp-proxy sits between omniserp clients and the engine APIs, recording
+// every response to fixture files and serving requests from the fixtures, so
+// demos, workshops, and CI run without credentials or cost.
+//
+// Record a session with real API keys, then replay it with placeholder keys:
+//
+//	omniserp-proxy --mode record --dir fixtures
+//	OMNISERP_PROXY_URL=http://localhost:8090 SERPER_API_KEY=... omniserp -e serper -q golang
+//
+//	omniserp-proxy --dir fixtures
+//	OMNISERP_PROXY_URL=http://localhost:8090 SERPER_API_KEY=replay omniserp -e serper -q golang
+//
+// Clients send the requests of an engine to <proxy>/<engine name>. In replay
+// mode, requests that were not recorded fail with 404; auto mode records
+// them instead. API keys are redacted from the fixtures, which can be
+// committed.
+//
+// Flags default to these environment variables:
+//
+//	OMNISERP_PROXY_ADDR     listen address (default :8090)
+//	OMNISERP_PROXY_MODE     record, replay, or auto (default replay)
+//	OMNISERP_FIXTURES       fixtures directory (default fixtures)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/plexusone/omniserp/recorder"
+)
+
+func main() {
+	addr := flag.String("addr", envOr("OMNISERP_PROXY_ADDR", ":8090"), "listen address")
+	modeName := flag.String("mode", envOr("OMNISERP_PROXY_MODE", string(recorder.ModeReplay)), "record, replay, or auto")
+	dir := flag.String("dir", envOr("OMNISERP_FIXTURES", "fixtures"), "fixtures directory")
+	flag.Parse()
+
+	mode, err := recorder.ParseMode(*modeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           &recorder.Proxy{Dir: *dir, Mode: mode},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving engine APIs in %s mode from %s on %s", mode, *dir, *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// envOr returns the environment variable, or fallback if it is not set
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// DebugCommand groups developer utilities
+type DebugCommand struct {
+	DiffRaw DebugDiffRawCommand `command:"diff-raw" description:"Structurally diff the raw payloads of two engines for one query"`
+}
+
+// DebugDiffRawCommand runs one query on two engines and diffs the raw payload structure
+type DebugDiffRawCommand struct {
+	Type    string        `long:"type" description:"Search type" choice:"search" choice:"news" choice:"images" choice:"videos" choice:"places" choice:"shopping" choice:"scholar" default:"search"`
+	Timeout time.Duration `long:"timeout" description:"Timeout per engine" default:"30s"`
+	Args    struct {
+		Left  string `positional-arg-name:"engine-a" required:"yes"`
+		Right string `positional-arg-name:"engine-b" required:"yes"`
+		Query string `positional-arg-name:"query" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+// searchFuncs maps --type values to the corresponding Engine methods
+var searchFuncs = map[string]func(omniserp.Engine, context.Context, omniserp.SearchParams) (*omniserp.SearchResult, error){
+	"search":   omniserp.Engine.Search,
+	"news":     omniserp.Engine.SearchNews,
+	"images":   omniserp.Engine.SearchImages,
+	"videos":   omniserp.Engine.SearchVideos,
+	"places":   omniserp.Engine.SearchPlaces,
+	"shopping": omniserp.Engine.SearchShopping,
+	"scholar":  omniserp.Engine.SearchScholar,
+}
+
+// Execute implements flags.Commander
+func (cmd *DebugDiffRawCommand) Execute(args []string) error {
+	c, err := newAllEnginesClient()
+	if err != nil {
+		return err
+	}
+
+	search, ok := searchFuncs[cmd.Type]
+	if !ok {
+		return fmt.Errorf("unsupported search type: %s", cmd.Type)
+	}
+
+	params := omniserp.SearchParams{Query: cmd.Args.Query, NumResults: 10}
+
+	var shapes [2]map[string]string
+	for i, name := range []string{cmd.Args.Left, cmd.Args.Right} {
+		engine, err := c.GetEngine(name)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cmd.Timeout)
+		result, err := search(engine, ctx, params)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s search failed: %w", name, err)
+		}
+
+		shapes[i], err = payloadShape(rawPayload(result))
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s payload: %w", name, err)
+		}
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", cmd.Args.Left, cmd.Args.Right)
+	for _, line := range diffShapes(shapes[0], shapes[1]) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// rawPayload returns the raw response body of a result if it is JSON, as
+// engines that normalize their own responses return a
+// *omniserp.NormalizedSearchResult as the Data, or otherwise the Data
+func rawPayload(result *omniserp.SearchResult) interface{} {
+	if result.Raw != "" && json.Valid([]byte(result.Raw)) {
+		return json.RawMessage(result.Raw)
+	}
+	return result.Data
+}
+
+// payloadShape flattens a raw payload into a map of field paths to JSON types.
+// Array elements are merged under a single "[]" path segment so that the
+// structure, not the number of results, is compared.
+func payloadShape(data interface{}) (map[string]string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	shape := make(map[string]string)
+	collectShape(shape, "", v)
+	return shape, nil
+}
+
+// collectShape records the type of v at path and recurses into containers
+func collectShape(shape map[string]string, path string, v interface{}) {
+	if path != "" {
+		shape[path] = mergeType(shape[path], jsonType(v))
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			collectShape(shape, joinPath(path, k), child)
+		}
+	case []interface{}:
+		for _, child := range val {
+			collectShape(shape, path+"[]", child)
+		}
+	}
+}
+
+// mergeType combines the types seen at one path, e.g. "number|string".
+// Null is only reported when no other type has been seen.
+func mergeType(prev, typ string) string {
+	switch {
+	case prev == "" || prev == "null":
+		return typ
+	case typ == "null" || slices.Contains(strings.Split(prev, "|"), typ):
+		return prev
+	}
+	types := append(strings.Split(prev, "|"), typ)
+	slices.Sort(types)
+	return strings.Join(types, "|")
+}
+
+// joinPath appends an object key to a field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonType returns the JSON type name of a decoded JSON value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// diffShapes returns sorted diff lines: "-" for paths only in a, "+" for paths
+// only in b, and "~" for paths whose types differ
+func diffShapes(a, b map[string]string) []string {
+	paths := make(map[string]bool, len(a)+len(b))
+	for p := range a {
+		paths[p] = true
+	}
+	for p := range b {
+		paths[p] = true
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, p := range sorted {
+		ta, inA := a[p]
+		tb, inB := b[p]
+		switch {
+		case inA && !inB:
+			lines = append(lines, fmt.Sprintf("- %s (%s)", p, ta))
+		case !inA && inB:
+			lines = append(lines, fmt.Sprintf("+ %s (%s)", p, tb))
+		case ta != tb:
+			lines = append(lines, fmt.Sprintf("~ %s (%s -> %s)", p, ta, tb))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "payloads have identical structure")
+	}
+	return lines
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestPayloadShape(t *testing.T) {
+	cases := []struct {
+		name string
+		data interface{}
+		want map[string]string
+	}{
+		{
+			name: "nested objects",
+			data: map[string]interface{}{"info": map[string]interface{}{"total": 10, "query": "go"}},
+			want: map[string]string{"info": "object", "info.total": "number", "info.query": "string"},
+		},
+		{
+			name: "array elements merged",
+			data: map[string]interface{}{"organic": []interface{}{
+				map[string]interface{}{"title": "Go", "rating": 4.5},
+				map[string]interface{}{"title": "Rust", "rating": "n/a", "sponsored": true},
+			}},
+			want: map[string]string{
+				"organic":             "array",
+				"organic[]":           "object",
+				"organic[].title":     "string",
+				"organic[].rating":    "number|string",
+				"organic[].sponsored": "boolean",
+			},
+		},
+		{
+			name: "null replaced by type",
+			data: []interface{}{map[string]interface{}{"date": nil}, map[string]interface{}{"date": "today"}},
+			want: map[string]string{"[]": "object", "[].date": "string"},
+		},
+		{
+			name: "struct",
+			data: omniserp.AnswerBox{Answer: "42"},
+			want: map[string]string{"answer": "string"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shape, err := payloadShape(tc.data)
+			if err != nil {
+				t.Fatalf("payloadShape failed: %v", err)
+			}
+			if !maps.Equal(shape, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, shape)
+			}
+		})
+	}
+
+	if _, err := payloadShape(func() {}); err == nil {
+		t.Error("Expected an error for a payload that is not JSON")
+	}
+}
+
+func TestMergeType(t *testing.T) {
+	cases := []struct {
+		prev, typ, want string
+	}{
+		{"", "string", "string"},
+		{"null", "number", "number"},
+		{"string", "null", "string"},
+		{"string", "string", "string"},
+		{"string", "number", "number|string"},
+		{"number|string", "boolean", "boolean|number|string"},
+		{"boolean|number", "number", "boolean|number"},
+	}
+	for _, tc := range cases {
+		if got := mergeType(tc.prev, tc.typ); got != tc.want {
+			t.Errorf("mergeType(%q, %q) = %q, want %q", tc.prev, tc.typ, got, tc.want)
+		}
+	}
+}
+
+func TestDiffShapes(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b map[string]string
+		want []string
+	}{
+		{
+			name: "identical",
+			a:    map[string]string{"title": "string"},
+			b:    map[string]string{"title": "string"},
+			want: []string{"payloads have identical structure"},
+		},
+		{
+			name: "added, removed, and changed",
+			a:    map[string]string{"organic": "array", "organic[].link": "string", "total": "number"},
+			b:    map[string]string{"organic": "array", "organic[].url": "string", "total": "string"},
+			want: []string{
+				"- organic[].link (string)",
+				"+ organic[].url (string)",
+				"~ total (number -> string)",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := diffShapes(tc.a, tc.b); !slices.Equal(got, tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRawPayload(t *testing.T) {
+	normalized := &omniserp.NormalizedSearchResult{OrganicResults: []omniserp.OrganicResult{{Title: "Go"}}}
+	cases := []struct {
+		name   string
+		result *omniserp.SearchResult
+		want   string
+	}{
+		{"raw JSON", &omniserp.SearchResult{Data: normalized, Raw: `{"web":{"results":[]}}`}, "web.results"},
+		{"raw HTML", &omniserp.SearchResult{Data: normalized, Raw: "<html></html>"}, "organic_results"},
+		{"no raw", &omniserp.SearchResult{Data: map[string]interface{}{"organic": []interface{}{}}}, "organic"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shape, err := payloadShape(rawPayload(tc.result))
+			if err != nil {
+				t.Fatalf("payloadShape failed: %v", err)
+			}
+			if _, ok := shape[tc.want]; !ok {
+				t.Errorf("Expected %s in %v", tc.want, shape)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// EnginesCommand groups the engine inspection subcommands
+type EnginesCommand struct {
+	List   EnginesListCommand   `command:"list" description:"List registered engines with their capability matrix"`
+	Info   EnginesInfoCommand   `command:"info" description:"Show version, supported tools, and extra parameters for engines"`
+	Check  EnginesCheckCommand  `command:"check" description:"Perform a health check and API key validation per engine"`
+	Matrix EnginesMatrixCommand `command:"matrix" description:"Export the capability matrix of operations and parameters per engine"`
+}
+
+// EnginesListCommand prints registered engines and a capability matrix
+type EnginesListCommand struct{}
+
+// EnginesInfoCommand prints engine information as JSON
+type EnginesInfoCommand struct {
+	Args struct {
+		Names []string `positional-arg-name:"engine" description:"Engine names (default: all registered engines)"`
+	} `positional-args:"yes"`
+}
+
+// EnginesMatrixCommand exports the capability matrix as JSON or Markdown
+type EnginesMatrixCommand struct {
+	Format string `short:"f" long:"format" description:"Output format" choice:"markdown" choice:"json" default:"markdown"`
+}
+
+// EnginesCheckCommand performs a minimal search against each engine
+type EnginesCheckCommand struct {
+	Query   string        `long:"query" description:"Query used for the health check" default:"test"`
+	Timeout time.Duration `long:"timeout" description:"Timeout per engine" default:"15s"`
+}
+
+// newAllEnginesClient creates a client with all engines registered, honoring --engine if set
+func newAllEnginesClient() (*client.Client, error) {
+	return client.NewWithOptions(&client.Options{
+		EngineName: opts.Engine,
+		Silent:     true,
+	})
+}
+
+// selectedEngineNames returns the engines to operate on in sorted order
+func selectedEngineNames(c *client.Client, names []string) ([]string, error) {
+	if len(names) == 0 {
+		if opts.Engine != "" {
+			names = []string{opts.Engine}
+		} else {
+			names = c.ListEngines()
+		}
+	}
+	for _, name := range names {
+		if _, err := c.GetEngine(name); err != nil {
+			return nil, err
+		}
+	}
+	names = slices.Clone(names)
+	slices.Sort(names)
+	return names, nil
+}
+
+// Execute implements flags.Commander
+func (cmd *EnginesListCommand) Execute(args []string) error {
+	c, err := newAllEnginesClient()
+	if err != nil {
+		return err
+	}
+
+	names, err := selectedEngineNames(c, nil)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENGINE\tVERSION\tTOOLS")
+	for _, name := range names {
+		engine, _ := c.GetEngine(name)
+		fmt.Fprintf(w, "%s\t%s\t%d\n", name, engine.GetVersion(), len(engine.GetSupportedTools()))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	return writeCapabilityMatrix(c, names)
+}
+
+// writeCapabilityMatrix prints an operations x engines support table
+func writeCapabilityMatrix(c *client.Client, names []string) error {
+	matrix := omniserp.CapabilityMatrix(c.GetRegistry())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprint(w, "OPERATION")
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%s", name)
+	}
+	fmt.Fprintln(w)
+
+	for _, op := range client.AllOperations() {
+		fmt.Fprint(w, op)
+		for _, name := range names {
+			mark := "✗"
+			if matrix.Supports(name, op) {
+				mark = "✓"
+			}
+			fmt.Fprintf(w, "\t%s", mark)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return w.Flush()
+}
+
+// Execute implements flags.Commander
+func (cmd *EnginesMatrixCommand) Execute(args []string) error {
+	c, err := newAllEnginesClient()
+	if err != nil {
+		return err
+	}
+
+	matrix := omniserp.CapabilityMatrix(c.GetRegistry())
+	if cmd.Format == "markdown" {
+		fmt.Print(matrix.Markdown())
+		return nil
+	}
+
+	output, err := json.MarshalIndent(matrix, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal capability matrix: %w", err)
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+// Execute implements flags.Commander
+func (cmd *EnginesInfoCommand) Execute(args []string) error {
+	c, err := newAllEnginesClient()
+	if err != nil {
+		return err
+	}
+
+	names, err := selectedEngineNames(c, cmd.Args.Names)
+	if err != nil {
+		return err
+	}
+
+	infos := make([]omniserp.EngineInfo, 0, len(names))
+	for _, name := range names {
+		engine, _ := c.GetEngine(name)
+		infos = append(infos, omniserp.GetEngineInfo(engine))
+	}
+
+	output, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal engine info: %w", err)
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
+
+// Execute implements flags.Commander
+func (cmd *EnginesCheckCommand) Execute(args []string) error {
+	c, err := newAllEnginesClient()
+	if err != nil {
+		return err
+	}
+
+	names, err := selectedEngineNames(c, nil)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENGINE\tSTATUS\tLATENCY\tDETAIL")
+	for _, name := range names {
+		engine, _ := c.GetEngine(name)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cmd.Timeout)
+		start := time.Now()
+		_, err := engine.Search(ctx, omniserp.SearchParams{Query: cmd.Query, NumResults: 1})
+		latency := time.Since(start).Round(time.Millisecond)
+		cancel()
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tFAIL\t%s\t%v\n", name, latency, err)
+		} else {
+			fmt.Fprintf(w, "%s\tOK\t%s\t\n", name, latency)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d engines failed the health check", failed, len(names))
+	}
+	return nil
+}
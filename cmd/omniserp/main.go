@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 
 	flags "github.com/jessevdk/go-flags"
 
@@ -13,23 +17,69 @@ import (
 )
 
 type Options struct {
-	Engine string `short:"e" long:"engine" description:"Search engine (serper, serpapi)" required:"true"`
-	Query  string `short:"q" long:"query" description:"Query" required:"true"`
+	Engine string `short:"e" long:"engine" description:"Search engine (serper, serpapi, searxng, googlecse, kagi, tavily, exa, youcom, mojeek, baidu, dataforseo, valueserp, scaleserp, zenserp, searchapi, brightdata, apify, perplexity, jina, firecrawl, elasticsearch, wikipedia, duckduckgo)"`
+	Query  string `short:"q" long:"query" description:"Query"`
+
+	Extra map[string]string `short:"x" long:"extra" value-name:"NAME:VALUE" description:"Engine-specific parameter, repeatable (see above)"`
+
+	Engines EnginesCommand `command:"engines" description:"List, inspect, and health check search engines"`
+	Report  ReportCommand  `command:"report" description:"Generate a Markdown or HTML research report"`
+	Scholar ScholarCommand `command:"scholar" description:"Search scholarly articles with optional BibTeX/RIS output"`
+	Rank    RankCommand    `command:"rank" description:"Track keyword rankings of a domain and report movement"`
+	Watch   WatchCommand   `command:"watch" description:"Repeat a search and report result changes"`
+	Scrape  ScrapeCommand  `command:"scrape" description:"Scrape URLs or a sitemap as a resumable job"`
+	Saved   SavedCommand   `command:"saved" description:"Manage and run saved searches"`
+	Debug   DebugCommand   `command:"debug" description:"Developer utilities for extending engines and the normalizer"`
 }
 
+var opts Options
+
 func main() {
-	opts := Options{}
-	_, err := flags.Parse(&opts)
+	parser := flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+	parser.LongDescription = extraParamsHelp()
+
+	_, err := parser.Parse()
 	if err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			fmt.Println(flagsErr.Message)
+			os.Exit(0)
+		}
 		log.Fatal(err)
 	}
 
-	query := opts.Query
+	// A subcommand has already been executed by the parser
+	if parser.Active != nil {
+		return
+	}
 
-	// Create client SDK
-	var c *client.Client
+	if opts.Engine == "" || opts.Query == "" {
+		log.Fatal("the required flags `-e, --engine' and `-q, --query' were not specified")
+	}
 
-	c, err = client.NewWithEngine(opts.Engine)
+	runSearch(opts.Engine, opts.Query, opts.Extra)
+}
+
+// extraParamsHelp lists the engine-specific parameters of the built-in engines
+func extraParamsHelp() string {
+	extras := client.EngineExtraParams()
+	names := slices.Sorted(maps.Keys(extras))
+
+	var b strings.Builder
+	b.WriteString("Engine-specific parameters for --extra:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%s:\n", name)
+		for _, param := range extras[name] {
+			fmt.Fprintf(&b, "%s (%s): %s\n", param.Name, param.Type, param.Description)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// runSearch performs a web search and prints the raw result as JSON
+func runSearch(engineName, query string, extra map[string]string) {
+	// Create client SDK
+	c, err := client.NewWithEngine(engineName)
 	if err != nil {
 		log.Fatalf("Failed to initialize client: %v", err)
 	}
@@ -39,6 +89,13 @@ func main() {
 		Query:      query,
 		NumResults: 10,
 	}
+	// Values are converted to the declared types by the engine
+	for name, value := range extra {
+		if params.Extra == nil {
+			params.Extra = make(map[string]any, len(extra))
+		}
+		params.Extra[name] = value
+	}
 
 	result, err := c.Search(context.Background(), params)
 	if err != nil {
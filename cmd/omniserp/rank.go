@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/rank"
+)
+
+// RankCommand groups the rank tracking subcommands
+type RankCommand struct {
+	Track   RankTrackCommand   `command:"track" description:"Record a ranking snapshot of a domain for keywords"`
+	Compare RankCompareCommand `command:"compare" description:"Compare two ranking snapshots and report movement"`
+}
+
+// RankTrackCommand records a ranking snapshot
+type RankTrackCommand struct {
+	Domain string `short:"d" long:"domain" description:"Target domain" required:"true"`
+	Output string `short:"o" long:"output" description:"Snapshot file to write" required:"true"`
+	Num    int    `short:"n" long:"num" description:"Number of results per keyword" default:"100"`
+
+	Args struct {
+		Keywords []string `positional-arg-name:"keyword" description:"Keywords to track" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// RankCompareCommand compares two ranking snapshots
+type RankCompareCommand struct {
+	JSON bool `long:"json" description:"Output the report as JSON instead of Markdown"`
+
+	Args struct {
+		Previous string `positional-arg-name:"previous" description:"Earlier snapshot file" required:"true"`
+		Current  string `positional-arg-name:"current" description:"Later snapshot file" required:"true"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *RankTrackCommand) Execute(args []string) error {
+	c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	snapshot, err := rank.Track(context.Background(), c, cmd.Domain, cmd.Args.Keywords, omniserp.SearchParams{NumResults: cmd.Num})
+	if err != nil {
+		return err
+	}
+
+	return snapshot.Save(cmd.Output)
+}
+
+// Execute implements flags.Commander
+func (cmd *RankCompareCommand) Execute(args []string) error {
+	previous, err := rank.LoadSnapshot(cmd.Args.Previous)
+	if err != nil {
+		return err
+	}
+	current, err := rank.LoadSnapshot(cmd.Args.Current)
+	if err != nil {
+		return err
+	}
+
+	report := rank.Compare(previous, current)
+	if cmd.JSON {
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Print(report.Markdown())
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/report"
+)
+
+// ReportCommand generates a Markdown or HTML research report
+type ReportCommand struct {
+	Format string   `short:"f" long:"format" description:"Report format" choice:"markdown" choice:"html" default:"markdown"`
+	Output string   `short:"o" long:"output" description:"Output file (default: stdout)"`
+	Title  string   `short:"t" long:"title" description:"Report title"`
+	Scrape int      `long:"scrape" description:"Scrape the top N organic results of each query into the report" default:"0"`
+	Inputs []string `short:"i" long:"input" description:"Normalized result JSON file to include (repeatable)"`
+
+	Args struct {
+		Queries []string `positional-arg-name:"query" description:"Queries to search and include"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *ReportCommand) Execute(args []string) error {
+	if len(cmd.Args.Queries) == 0 && len(cmd.Inputs) == 0 {
+		return fmt.Errorf("at least one query or --input file is required")
+	}
+
+	r := report.New(cmd.Title)
+
+	for _, path := range cmd.Inputs {
+		result, err := readNormalizedResult(path)
+		if err != nil {
+			return err
+		}
+		r.Results = append(r.Results, result)
+	}
+
+	if len(cmd.Args.Queries) > 0 {
+		c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+		if err != nil {
+			return fmt.Errorf("failed to initialize client: %w", err)
+		}
+
+		ctx := context.Background()
+		for _, query := range cmd.Args.Queries {
+			result, err := c.SearchNormalized(ctx, omniserp.SearchParams{Query: query, NumResults: 10})
+			if err != nil {
+				return fmt.Errorf("search for %q failed: %w", query, err)
+			}
+			r.Results = append(r.Results, result)
+
+			for i := 0; i < cmd.Scrape && i < len(result.OrganicResults); i++ {
+				organic := result.OrganicResults[i]
+				scraped, err := c.ScrapeWebpage(ctx, omniserp.ScrapeParams{URL: organic.Link})
+				if err != nil {
+					log.Printf("Skipping %s: %v", organic.Link, err)
+					continue
+				}
+				text := scrapedText(scraped, organic.Link)
+				if text == "" {
+					log.Printf("Skipping %s: no text", organic.Link)
+					continue
+				}
+				r.AddPage(report.Page{
+					URL:     organic.Link,
+					Title:   organic.Title,
+					Content: text,
+				})
+			}
+		}
+	}
+
+	if r.Title == "" && len(r.Results) > 0 {
+		r.Title = "Search Report: " + r.Results[0].SearchMetadata.Query
+	}
+
+	if cmd.Output == "" {
+		return r.Write(os.Stdout, report.Format(cmd.Format))
+	}
+
+	f, err := os.Create(cmd.Output) // #nosec G304 -- output path is provided by the CLI user
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := r.Write(f, report.Format(cmd.Format)); err != nil {
+		f.Close()
+		return err
+	}
+	// A failed close can lose buffered data of the report
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// readNormalizedResult loads a normalized result from a JSON file
+func readNormalizedResult(path string) (*omniserp.NormalizedSearchResult, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- input path is provided by the CLI user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var result omniserp.NormalizedSearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// scrapedText extracts the page text from an engine scrape response, which
+// is a *omniserp.NormalizedScrapeResult. The raw response is not used: for
+// engines that fetch the page themselves it is the whole page HTML.
+func scrapedText(result *omniserp.SearchResult, pageURL string) string {
+	scraped, err := omniserp.NormalizeScrape(result, pageURL)
+	if err != nil {
+		return ""
+	}
+	if scraped.Text != "" {
+		return scraped.Text
+	}
+	return scraped.Markdown
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestScrapedText(t *testing.T) {
+	page := "<html><body><p>Hello</p></body></html>"
+	tests := []struct {
+		name   string
+		result *omniserp.SearchResult
+		want   string
+	}{
+		{"Text", &omniserp.SearchResult{Data: &omniserp.NormalizedScrapeResult{Text: "Hello", Markdown: "# Hello"}, Raw: page}, "Hello"},
+		{"Markdown", &omniserp.SearchResult{Data: &omniserp.NormalizedScrapeResult{Markdown: "# Hello"}, Raw: page}, "# Hello"},
+		{"Empty", &omniserp.SearchResult{Data: &omniserp.NormalizedScrapeResult{}, Raw: page}, ""},
+		{"Unknown", &omniserp.SearchResult{Data: []byte(page), Raw: page}, ""},
+	}
+	for _, tt := range tests {
+		if got := scrapedText(tt.result, "https://example.com"); got != tt.want {
+			t.Errorf("%s: scrapedText = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// SavedCommand groups the saved search subcommands
+type SavedCommand struct {
+	File string `short:"f" long:"file" env:"OMNISERP_SAVED_SEARCHES" description:"Saved searches file" default:"saved-searches.json"`
+
+	List   SavedListCommand   `command:"list" description:"List saved searches"`
+	Add    SavedAddCommand    `command:"add" description:"Add or replace a saved search"`
+	Delete SavedDeleteCommand `command:"delete" description:"Delete a saved search"`
+	Run    SavedRunCommand    `command:"run" description:"Run a saved search and print the normalized result"`
+}
+
+// SavedListCommand prints the saved searches as JSON
+type SavedListCommand struct{}
+
+// SavedAddCommand adds or replaces a saved search
+type SavedAddCommand struct {
+	Operation   string `short:"o" long:"operation" description:"Operation: search, news, images, scholar, or shopping" default:"search"`
+	Engine      string `long:"pin-engine" description:"Always run on this engine instead of the default selection"`
+	Schedule    string `short:"s" long:"schedule" description:"Cron schedule for scheduled runners, e.g. \"0 * * * *\""`
+	Description string `short:"d" long:"description" description:"Description"`
+	Num         int    `short:"n" long:"num" description:"Number of results"`
+	Location    string `long:"location" description:"Search location"`
+	Language    string `long:"language" description:"Language code (hl)"`
+	Country     string `long:"country" description:"Country code (gl)"`
+	Locale      string `long:"locale" description:"Country and language preset, e.g. de-DE, ja-JP, or pt-BR"`
+
+	Webhook         string   `long:"webhook" description:"Webhook URL that scheduled runners post new results to"`
+	WebhookTemplate string   `long:"webhook-template" description:"File with a Go template for the webhook body"`
+	WebhookHeaders  []string `long:"webhook-header" description:"Webhook request header as \"Name: value\" (repeatable)"`
+
+	Args struct {
+		Name  string   `positional-arg-name:"name" description:"Saved search name" required:"true"`
+		Query []string `positional-arg-name:"query" description:"Search query" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// SavedDeleteCommand deletes a saved search
+type SavedDeleteCommand struct {
+	Args struct {
+		Name string `positional-arg-name:"name" description:"Saved search name" required:"true"`
+	} `positional-args:"yes"`
+}
+
+// SavedRunCommand runs a saved search
+type SavedRunCommand struct {
+	Args struct {
+		Name string `positional-arg-name:"name" description:"Saved search name" required:"true"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *SavedListCommand) Execute(args []string) error {
+	saved, err := client.LoadSavedSearches(opts.Saved.File)
+	if err != nil {
+		return err
+	}
+	return printJSON(saved.List())
+}
+
+// Execute implements flags.Commander
+func (cmd *SavedAddCommand) Execute(args []string) error {
+	saved, err := client.LoadSavedSearches(opts.Saved.File)
+	if err != nil {
+		return err
+	}
+	webhook, err := cmd.webhook()
+	if err != nil {
+		return err
+	}
+	return saved.Save(client.SavedSearch{
+		Name:        cmd.Args.Name,
+		Description: cmd.Description,
+		Operation:   cmd.Operation,
+		Engine:      cmd.Engine,
+		Schedule:    cmd.Schedule,
+		Params: omniserp.SearchParams{
+			Query:      strings.Join(cmd.Args.Query, " "),
+			NumResults: cmd.Num,
+			Location:   cmd.Location,
+			Language:   cmd.Language,
+			Country:    cmd.Country,
+			Locale:     cmd.Locale,
+		},
+		Webhook: webhook,
+	})
+}
+
+// webhook returns the webhook given by the flags, or nil without --webhook
+func (cmd *SavedAddCommand) webhook() (*client.SavedWebhook, error) {
+	if cmd.Webhook == "" {
+		if cmd.WebhookTemplate != "" || len(cmd.WebhookHeaders) > 0 {
+			return nil, fmt.Errorf("--webhook-template and --webhook-header require --webhook")
+		}
+		return nil, nil
+	}
+	webhook := &client.SavedWebhook{URL: cmd.Webhook}
+	if cmd.WebhookTemplate != "" {
+		// #nosec G304 -- template path is provided by the user
+		data, err := os.ReadFile(cmd.WebhookTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook template: %w", err)
+		}
+		webhook.Template = string(data)
+	}
+	for _, header := range cmd.WebhookHeaders {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid webhook header %q, expected \"Name: value\"", header)
+		}
+		if webhook.Headers == nil {
+			webhook.Headers = make(map[string]string)
+		}
+		webhook.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return webhook, nil
+}
+
+// Execute implements flags.Commander
+func (cmd *SavedDeleteCommand) Execute(args []string) error {
+	saved, err := client.LoadSavedSearches(opts.Saved.File)
+	if err != nil {
+		return err
+	}
+	return saved.Delete(cmd.Args.Name)
+}
+
+// Execute implements flags.Commander
+func (cmd *SavedRunCommand) Execute(args []string) error {
+	saved, err := client.LoadSavedSearches(opts.Saved.File)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	c.SetSavedSearches(saved)
+
+	result, err := c.RunSaved(context.Background(), cmd.Args.Name)
+	if err != nil {
+		return err
+	}
+	result.Raw = nil
+	return printJSON(result)
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	fmt.Println(string(output))
+	return nil
+}
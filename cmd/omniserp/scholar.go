@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/export"
+)
+
+// ScholarCommand performs a scholar search with optional citation output
+type ScholarCommand struct {
+	BibTeX     bool `long:"bibtex" description:"Output results as BibTeX entries"`
+	RIS        bool `long:"ris" description:"Output results in RIS format"`
+	NumResults int  `short:"n" long:"num" description:"Number of results" default:"10"`
+
+	Args struct {
+		Query []string `positional-arg-name:"query" description:"Scholar query" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *ScholarCommand) Execute(args []string) error {
+	if cmd.BibTeX && cmd.RIS {
+		return fmt.Errorf("--bibtex and --ris are mutually exclusive")
+	}
+
+	c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	result, err := c.SearchScholarNormalized(context.Background(), omniserp.SearchParams{
+		Query:      strings.Join(cmd.Args.Query, " "),
+		NumResults: cmd.NumResults,
+	})
+	if err != nil {
+		return fmt.Errorf("scholar search failed: %w", err)
+	}
+
+	switch {
+	case cmd.BibTeX:
+		return export.WriteBibTeX(os.Stdout, result.ScholarResults)
+	case cmd.RIS:
+		return export.WriteRIS(os.Stdout, result.ScholarResults)
+	}
+
+	output, err := json.MarshalIndent(result.ScholarResults, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	fmt.Println(string(output))
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp/client"
+)
+
+// ScrapeCommand scrapes a batch of URLs or the pages of a sitemap as a
+// resumable job
+type ScrapeCommand struct {
+	Sitemap     string        `long:"sitemap" description:"Scrape the pages of an XML sitemap or sitemap index"`
+	File        string        `short:"f" long:"file" description:"File of URLs to scrape, one per line"`
+	Jobs        string        `long:"jobs" env:"OMNISERP_SCRAPE_JOBS" description:"Job history file" default:"scrape-jobs.jsonl"`
+	Resume      string        `long:"resume" value-name:"JOB-ID" description:"Resume an interrupted job instead of starting one"`
+	Output      string        `short:"o" long:"output" description:"Append results as JSON Lines to a file instead of stdout"`
+	Concurrency int           `short:"c" long:"concurrency" description:"Maximum parallel scrapes" default:"8"`
+	Delay       time.Duration `long:"delay" description:"Minimum time between requests to a host" default:"1s"`
+
+	Args struct {
+		URLs []string `positional-arg-name:"url" description:"URLs to scrape"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *ScrapeCommand) Execute(args []string) error {
+	c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	history, err := client.OpenJobHistory(cmd.Jobs)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	id := cmd.Resume
+	if id == "" {
+		source, urls, err := cmd.urls(ctx)
+		if err != nil {
+			return err
+		}
+		job, err := history.StartJob(source, urls)
+		if err != nil {
+			return err
+		}
+		id = job.ID
+	} else if cmd.Sitemap != "" || cmd.File != "" || len(cmd.Args.URLs) > 0 {
+		return fmt.Errorf("--resume takes no URLs, sitemap, or file")
+	}
+
+	out := io.Writer(os.Stdout)
+	if cmd.Output != "" {
+		// #nosec G304 -- output path is provided by the user
+		f, err := os.OpenFile(cmd.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Results are written as they finish so an interrupted job keeps the
+	// pages scraped so far
+	var mu sync.Mutex
+	encoder := json.NewEncoder(out)
+	_, job, err := c.RunScrapeJob(ctx, history, id, &client.ScrapeBatchOptions{
+		Concurrency: cmd.Concurrency,
+		Politeness:  &client.PolitenessPolicy{MinDelay: cmd.Delay, RespectRobots: true},
+		OnResult: func(result client.ScrapeBatchResult) {
+			if result.Error != "" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			_ = encoder.Encode(result)
+		},
+	})
+	if job != nil {
+		fmt.Fprintf(os.Stderr, "job %s: %d of %d URLs scraped, %d failed\n", job.ID, len(job.Done), len(job.URLs), len(job.Errors))
+		if !job.Finished() {
+			fmt.Fprintf(os.Stderr, "resume with: omniserp scrape --jobs %s --resume %s\n", cmd.Jobs, job.ID)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// urls returns the URLs of a new job and their source
+func (cmd *ScrapeCommand) urls(ctx context.Context) (string, []string, error) {
+	urls := append([]string(nil), cmd.Args.URLs...)
+	var sources []string
+
+	if cmd.File != "" {
+		// #nosec G304 -- URL file path is provided by the user
+		data, err := os.ReadFile(cmd.File)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read URL file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				urls = append(urls, line)
+			}
+		}
+		sources = append(sources, cmd.File)
+	}
+	if cmd.Sitemap != "" {
+		sitemapURLs, err := client.ReadSitemap(ctx, cmd.Sitemap, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		urls = append(urls, sitemapURLs...)
+		sources = append(sources, cmd.Sitemap)
+	}
+
+	if len(urls) == 0 {
+		return "", nil, fmt.Errorf("no URLs to scrape: give URLs, --file, --sitemap, or --resume")
+	}
+	return strings.Join(sources, ", "), urls, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+)
+
+// WatchCommand repeats a search and reports how its results change
+type WatchCommand struct {
+	Interval   time.Duration `short:"i" long:"interval" description:"Time between searches" default:"1h"`
+	Count      int           `short:"c" long:"count" description:"Number of searches, 0 to watch until interrupted" default:"0"`
+	NumResults int           `short:"n" long:"num" description:"Number of results" default:"10"`
+	JSON       bool          `long:"json" description:"Output each change as a JSON line"`
+
+	Args struct {
+		Query []string `positional-arg-name:"query" description:"Query to watch" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// Execute implements flags.Commander
+func (cmd *WatchCommand) Execute(args []string) error {
+	if cmd.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive, got %s", cmd.Interval)
+	}
+
+	c, err := client.NewWithOptions(&client.Options{EngineName: opts.Engine, Silent: true})
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	params := omniserp.SearchParams{
+		Query:      strings.Join(cmd.Args.Query, " "),
+		NumResults: cmd.NumResults,
+	}
+
+	var previous *omniserp.NormalizedSearchResult
+	for run := 1; ; run++ {
+		current, err := c.SearchNormalized(ctx, params)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			// A failed search is reported and retried at the next interval
+			fmt.Fprintf(os.Stderr, "%s search failed: %v\n", time.Now().Format(time.DateTime), err)
+		case previous == nil:
+			fmt.Fprintf(os.Stderr, "%s watching %q: %d results\n", time.Now().Format(time.DateTime), params.Query, len(current.OrganicResults))
+			previous = current
+		default:
+			if err := cmd.print(omniserp.DiffResults(previous, current)); err != nil {
+				return err
+			}
+			previous = current
+		}
+
+		if cmd.Count > 0 && run >= cmd.Count {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cmd.Interval):
+		}
+	}
+}
+
+// print writes a diff with changes as text or a JSON line
+func (cmd *WatchCommand) print(diff *omniserp.ResultsDiff) error {
+	if !diff.Changed() {
+		return nil
+	}
+	now := time.Now()
+
+	if cmd.JSON {
+		output, err := json.Marshal(struct {
+			Time time.Time `json:"time"`
+			*omniserp.ResultsDiff
+		}{now.UTC(), diff})
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Printf("%s\n", now.Format(time.DateTime))
+	for _, r := range diff.Added {
+		fmt.Printf("  + %2d  %s\n", r.CurrentPosition, r.URL)
+	}
+	for _, r := range diff.Removed {
+		fmt.Printf("  - %2d  %s\n", r.PreviousPosition, r.URL)
+	}
+	for _, r := range diff.Moved {
+		arrow := "▲"
+		if r.Delta < 0 {
+			arrow = "▼"
+		}
+		fmt.Printf("  %s %2d  %s (was %d)\n", arrow, r.CurrentPosition, r.URL, r.PreviousPosition)
+	}
+	for _, f := range diff.FeaturesAdded {
+		fmt.Printf("  + %s\n", f)
+	}
+	for _, f := range diff.FeaturesRemoved {
+		fmt.Printf("  - %s\n", f)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package omniserp
+
+// CreditsUsed returns the API credits that an engine reported charging for
+// a request, such as the "credits" field of Serper responses or the
+// "request_info.credits_used" field of ValueSerp responses. It returns false
+// if the response does not report credits.
+func CreditsUsed(result *SearchResult) (int, bool) {
+	if result == nil {
+		return 0, false
+	}
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	credits, ok := data["credits"].(float64)
+	if !ok {
+		if info, isMap := data["request_info"].(map[string]any); isMap {
+			credits, ok = info["credits_used"].(float64)
+		}
+	}
+	if !ok || credits < 0 {
+		return 0, false
+	}
+	return int(credits), true
+}
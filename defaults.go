@@ -0,0 +1,68 @@
+package omniserp
+
+import "context"
+
+// defaultsKey is the context key of the default search parameters
+type defaultsKey struct{}
+
+// WithDefaults returns a copy of ctx carrying default search parameters,
+// such as the location, language, and safe search of a user session. The
+// client fills the unset fields of every search made with the context from
+// them. Defaults attached to ctx earlier still apply to fields that
+// defaults leaves unset. The query and page are never defaulted.
+func WithDefaults(ctx context.Context, defaults SearchParams) context.Context {
+	if outer, ok := DefaultsFromContext(ctx); ok {
+		defaults = defaults.MergeDefaults(outer)
+	}
+	return context.WithValue(ctx, defaultsKey{}, defaults)
+}
+
+// DefaultsFromContext returns the default search parameters attached to ctx
+// with WithDefaults
+func DefaultsFromContext(ctx context.Context) (SearchParams, bool) {
+	defaults, ok := ctx.Value(defaultsKey{}).(SearchParams)
+	return defaults, ok
+}
+
+// ApplyDefaults fills the unset fields of params from their locale preset
+// and the defaults attached to ctx, if any
+func ApplyDefaults(ctx context.Context, params SearchParams) SearchParams {
+	if defaults, ok := DefaultsFromContext(ctx); ok {
+		return params.MergeDefaults(defaults)
+	}
+	return params.WithLocale()
+}
+
+// MergeDefaults returns p with its unset location, language, country,
+// locale, Google domain, number of results, freshness, and safe search
+// taken from defaults. The locale presets of p and defaults are applied
+// first, so the preset of p takes precedence over the fields of defaults.
+// Because an unset SafeSearch is false, a default of true cannot be turned
+// off per call.
+func (p SearchParams) MergeDefaults(defaults SearchParams) SearchParams {
+	p = p.WithLocale()
+	defaults = defaults.WithLocale()
+	if p.Location == "" {
+		p.Location = defaults.Location
+	}
+	if p.Language == "" {
+		p.Language = defaults.Language
+	}
+	if p.Country == "" {
+		p.Country = defaults.Country
+	}
+	if p.Locale == "" {
+		p.Locale = defaults.Locale
+	}
+	if p.GoogleDomain == "" {
+		p.GoogleDomain = defaults.GoogleDomain
+	}
+	if p.NumResults == 0 {
+		p.NumResults = defaults.NumResults
+	}
+	if p.Freshness == "" {
+		p.Freshness = defaults.Freshness
+	}
+	p.SafeSearch = p.SafeSearch || defaults.SafeSearch
+	return p
+}
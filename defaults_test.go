@@ -0,0 +1,28 @@
+package omniserp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWithDefaults(t *testing.T) {
+	ctx := context.Background()
+	if got := ApplyDefaults(ctx, SearchParams{Query: "go"}); !reflect.DeepEqual(got, SearchParams{Query: "go"}) {
+		t.Errorf("Expected params unchanged without defaults, got %+v", got)
+	}
+
+	ctx = WithDefaults(ctx, SearchParams{Location: "Paris, France", Language: "fr", SafeSearch: true})
+	ctx = WithDefaults(ctx, SearchParams{Language: "en", Query: "ignored", Page: 3})
+
+	got := ApplyDefaults(ctx, SearchParams{Query: "go", Country: "us"})
+	want := SearchParams{Query: "go", Location: "Paris, France", Language: "en", Country: "us", SafeSearch: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	got = ApplyDefaults(ctx, SearchParams{Query: "go", Location: "Berlin", Language: "de"})
+	if got.Location != "Berlin" || got.Language != "de" {
+		t.Errorf("Expected explicit fields to win, got %+v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package omniserp
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SERP features of a normalized result, as reported by Features
+const (
+	FeatureAnswerBox      = "answer_box"
+	FeatureKnowledgeGraph = "knowledge_graph"
+	FeaturePeopleAlsoAsk  = "people_also_ask"
+	FeatureRelated        = "related_searches"
+	FeatureNews           = "news"
+	FeatureImages         = "images"
+	FeatureVideos         = "videos"
+	FeaturePlaces         = "places"
+	FeatureShopping       = "shopping"
+)
+
+// Features returns the SERP features present in the result, such as
+// FeatureAnswerBox, in a fixed order
+func (r *NormalizedSearchResult) Features() []string {
+	if r == nil {
+		return nil
+	}
+	var features []string
+	add := func(name string, present bool) {
+		if present {
+			features = append(features, name)
+		}
+	}
+	add(FeatureAnswerBox, r.AnswerBox != nil)
+	add(FeatureKnowledgeGraph, r.KnowledgeGraph != nil)
+	add(FeaturePeopleAlsoAsk, len(r.PeopleAlsoAsk) > 0)
+	add(FeatureRelated, len(r.RelatedSearches) > 0)
+	add(FeatureNews, len(r.NewsResults) > 0)
+	add(FeatureImages, len(r.ImageResults) > 0)
+	add(FeatureVideos, len(r.VideoResults) > 0)
+	add(FeaturePlaces, len(r.PlaceResults) > 0)
+	add(FeatureShopping, len(r.ShoppingResults) > 0)
+	return features
+}
+
+// DiffFeatures returns the features of current that are not in previous,
+// and those of previous that are not in current
+func DiffFeatures(previous, current []string) (added, removed []string) {
+	for _, f := range current {
+		if !slices.Contains(previous, f) {
+			added = append(added, f)
+		}
+	}
+	for _, f := range previous {
+		if !slices.Contains(current, f) {
+			removed = append(removed, f)
+		}
+	}
+	return added, removed
+}
+
+// ResultChange is an organic result that appeared, disappeared, or moved
+// between two searches. The position of the search it is missing from is 0.
+type ResultChange struct {
+	URL              string `json:"url"`
+	Title            string `json:"title,omitempty"`
+	PreviousPosition int    `json:"previous_position,omitempty"`
+	CurrentPosition  int    `json:"current_position,omitempty"`
+	Delta            int    `json:"delta,omitempty"` // positive means moved up
+}
+
+// ResultsDiff is the change of the organic results and SERP features from
+// one search to another
+type ResultsDiff struct {
+	Added           []ResultChange `json:"added,omitempty"`
+	Removed         []ResultChange `json:"removed,omitempty"`
+	Moved           []ResultChange `json:"moved,omitempty"`
+	Unchanged       int            `json:"unchanged"`
+	FeaturesAdded   []string       `json:"features_added,omitempty"`
+	FeaturesRemoved []string       `json:"features_removed,omitempty"`
+}
+
+// Changed reports whether any result or feature changed
+func (d *ResultsDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Moved) > 0 ||
+		len(d.FeaturesAdded) > 0 || len(d.FeaturesRemoved) > 0
+}
+
+// DiffResults compares the organic results and SERP features of a previous
+// search with a current one, such as two runs of the same query. Results
+// are matched by their CanonicalURL, so tracking parameters and "www."
+// do not count as changes; the first of duplicate URLs is used. Added and
+// moved results are ordered by their current position, removed results by
+// their previous position. A nil result has no results or features.
+func DiffResults(previous, current *NormalizedSearchResult) *ResultsDiff {
+	prev, curr := rankedURLs(previous), rankedURLs(current)
+
+	diff := &ResultsDiff{}
+	for key, c := range curr {
+		p, ok := prev[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, ResultChange{URL: c.link, Title: c.title, CurrentPosition: c.position})
+		case p.position != c.position:
+			diff.Moved = append(diff.Moved, ResultChange{
+				URL:              c.link,
+				Title:            c.title,
+				PreviousPosition: p.position,
+				CurrentPosition:  c.position,
+				Delta:            p.position - c.position,
+			})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for key, p := range prev {
+		if _, ok := curr[key]; !ok {
+			diff.Removed = append(diff.Removed, ResultChange{URL: p.link, Title: p.title, PreviousPosition: p.position})
+		}
+	}
+
+	byCurrent := func(a, b ResultChange) int { return cmp.Compare(a.CurrentPosition, b.CurrentPosition) }
+	slices.SortFunc(diff.Added, byCurrent)
+	slices.SortFunc(diff.Moved, byCurrent)
+	slices.SortFunc(diff.Removed, func(a, b ResultChange) int {
+		return cmp.Compare(a.PreviousPosition, b.PreviousPosition)
+	})
+
+	diff.FeaturesAdded, diff.FeaturesRemoved = DiffFeatures(previous.Features(), current.Features())
+	return diff
+}
+
+// rankedURL is an organic result of a search being diffed
+type rankedURL struct {
+	link     string
+	title    string
+	position int
+}
+
+// rankedURLs returns the organic results of a search by canonical URL.
+// Results without a position are ranked by their order.
+func rankedURLs(result *NormalizedSearchResult) map[string]rankedURL {
+	ranked := make(map[string]rankedURL)
+	if result == nil {
+		return ranked
+	}
+	for i, r := range result.OrganicResults {
+		link := r.Link
+		if link == "" {
+			link = r.URL
+		}
+		if link == "" {
+			continue
+		}
+		key := CanonicalURL(link)
+		if _, ok := ranked[key]; ok {
+			continue
+		}
+		position := r.Position
+		if position == 0 {
+			position = i + 1
+		}
+		ranked[key] = rankedURL{link: link, title: r.Title, position: position}
+	}
+	return ranked
+}
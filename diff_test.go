@@ -0,0 +1,64 @@
+package omniserp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	previous := &NormalizedSearchResult{
+		OrganicResults: []OrganicResult{
+			{Position: 1, Link: "https://go.dev/"},
+			{Position: 2, Link: "https://www.github.com/golang/go?utm_source=serp"},
+			{Position: 3, Link: "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+			{Position: 4, Link: "https://go.dev/"},
+		},
+		AnswerBox:     &AnswerBox{Answer: "Go"},
+		PeopleAlsoAsk: []PeopleAlsoAsk{{Question: "Is Go fast?"}},
+	}
+	current := &NormalizedSearchResult{
+		OrganicResults: []OrganicResult{
+			{Position: 1, Link: "https://github.com/golang/go"},
+			{Position: 2, Link: "https://go.dev"},
+			{Position: 3, Link: "https://gobyexample.com/"},
+		},
+		AnswerBox:   &AnswerBox{Answer: "Go"},
+		NewsResults: []NewsResult{{Title: "Go 1.25"}},
+	}
+
+	diff := DiffResults(previous, current)
+	if !diff.Changed() {
+		t.Fatal("Expected changes")
+	}
+	if len(diff.Added) != 1 || diff.Added[0].URL != "https://gobyexample.com/" || diff.Added[0].CurrentPosition != 3 {
+		t.Errorf("Unexpected added results: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].PreviousPosition != 3 {
+		t.Errorf("Unexpected removed results: %+v", diff.Removed)
+	}
+	if len(diff.Moved) != 2 || diff.Moved[0].Delta != 1 || diff.Moved[1].Delta != -1 || diff.Moved[1].PreviousPosition != 1 {
+		t.Errorf("Unexpected moved results: %+v", diff.Moved)
+	}
+	if !slices.Equal(diff.FeaturesAdded, []string{FeatureNews}) || !slices.Equal(diff.FeaturesRemoved, []string{FeaturePeopleAlsoAsk}) {
+		t.Errorf("Unexpected feature changes: +%v -%v", diff.FeaturesAdded, diff.FeaturesRemoved)
+	}
+
+	if diff := DiffResults(current, current); diff.Changed() || diff.Unchanged != 3 {
+		t.Errorf("Expected no changes to itself, got %+v", diff)
+	}
+	if diff := DiffResults(nil, current); len(diff.Added) != 3 || len(diff.FeaturesAdded) != 2 {
+		t.Errorf("Expected everything added from nil, got %+v", diff)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	result := &NormalizedSearchResult{
+		KnowledgeGraph:  &KnowledgeGraph{Title: "Go"},
+		RelatedSearches: []RelatedSearch{{Query: "golang tutorial"}},
+		ShoppingResults: []ShoppingResult{{Title: "Gopher plush"}},
+	}
+	want := []string{FeatureKnowledgeGraph, FeatureRelated, FeatureShopping}
+	if got := result.Features(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,63 @@
+// Package events publishes a message for every completed search to a
+// streaming platform, so downstream pipelines can consume SERP data as it is
+// collected. Kafka is reached through the Confluent REST Proxy and NATS
+// with the nats.go client, optionally through JetStream. Set a Publisher on
+// the client to publish every normalized search.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Event describes one completed search. The normalized result carries the
+// search metadata; the raw engine response is not included.
+type Event struct {
+	ID          string                           `json:"id"`
+	Operation   string                           `json:"operation"`
+	Engine      string                           `json:"engine"`
+	Query       string                           `json:"query"`
+	Fingerprint string                           `json:"fingerprint,omitempty"`
+	Time        time.Time                        `json:"time"`
+	Result      *omniserp.NormalizedSearchResult `json:"result"`
+}
+
+// NewEvent creates the event of a normalized search result of an operation
+func NewEvent(operation string, result *omniserp.NormalizedSearchResult) Event {
+	stripped := *result
+	stripped.Raw = nil
+	return Event{
+		ID:          newID(),
+		Operation:   operation,
+		Engine:      result.SearchMetadata.Engine,
+		Query:       result.SearchMetadata.Query,
+		Fingerprint: result.SearchMetadata.Fingerprint,
+		Time:        time.Now().UTC(),
+		Result:      &stripped,
+	}
+}
+
+// Key returns the message key of the event, the fingerprint of the search
+// or else its query, so repeated searches are kept in order on one partition
+func (e Event) Key() string {
+	if e.Fingerprint != "" {
+		return e.Fingerprint
+	}
+	return e.Query
+}
+
+// Publisher delivers search events
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// newID returns a random event ID
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,265 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/plexusone/omniserp"
+)
+
+func testResult() *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "golang", Fingerprint: "abc123"},
+		OrganicResults: []omniserp.OrganicResult{{Position: 1, Title: "Go", Link: "https://go.dev/"}},
+		Raw:            &omniserp.SearchResult{Raw: `{"organic":[]}`},
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	result := testResult()
+	event := NewEvent("google_search", result)
+
+	if event.ID == "" || event.Engine != "serper" || event.Query != "golang" || event.Key() != "abc123" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.Result.Raw != nil || result.Raw == nil {
+		t.Error("Expected the raw response to be dropped from the event only")
+	}
+	if other := NewEvent("google_search", result); other.ID == event.ID {
+		t.Error("Expected unique event IDs")
+	}
+
+	result.SearchMetadata.Fingerprint = ""
+	if key := NewEvent("google_search", result).Key(); key != "golang" {
+		t.Errorf("Expected the query as the key, got %q", key)
+	}
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var path, contentType, user string
+	var produced struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Event  `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		user, _, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &produced)
+		_, _ = w.Write([]byte(`{"key_schema_id":null,"value_schema_id":null,"offsets":[{"partition":0,"offset":42,"error_code":null,"error":null}]}`))
+	}))
+	defer srv.Close()
+
+	publisher := NewKafkaPublisher(srv.URL+"/", "serp-events")
+	publisher.Username, publisher.Password = "key", "secret"
+	if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if path != "/topics/serp-events" || contentType != kafkaContentType || user != "key" {
+		t.Errorf("Unexpected request: path %q, content type %q, user %q", path, contentType, user)
+	}
+	if len(produced.Records) != 1 || produced.Records[0].Key != "abc123" || produced.Records[0].Value.Operation != "google_search" {
+		t.Fatalf("Unexpected records: %+v", produced.Records)
+	}
+	if results := produced.Records[0].Value.Result.OrganicResults; len(results) != 1 || results[0].Link != "https://go.dev/" {
+		t.Errorf("Expected the normalized results, got %+v", results)
+	}
+}
+
+func TestKafkaPublisherErrors(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"status": {http.StatusNotFound, `{"error_code":40401,"message":"Topic not found"}`},
+		"offset": {http.StatusOK, `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"Kafka error"}]}`},
+	}
+	for name, response := range responses {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(response.status)
+			_, _ = w.Write([]byte(response.body))
+		}))
+		err := NewKafkaPublisher(srv.URL, "serp-events").Publish(context.Background(), NewEvent("google_search", testResult()))
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		srv.Close()
+	}
+}
+
+// natsServer is a minimal NATS server that records published messages.
+// It rejects publishes with pubErr if set, answers requests, such as
+// JetStream publishes, with ack, and closes the connection after the first
+// flushed publish if closeAfter is set.
+type natsServer struct {
+	listener   net.Listener
+	messages   chan string
+	connects   chan string
+	pubErr     string
+	ack        string
+	closeAfter bool
+}
+
+func newNATSServer(t *testing.T, configure func(*natsServer)) *natsServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &natsServer{listener: listener, messages: make(chan string, 10), connects: make(chan string, 10)}
+	if configure != nil {
+		configure(s)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *natsServer) serve(conn net.Conn) {
+	defer conn.Close()
+	_, _ = conn.Write([]byte(`INFO {"server_id":"test","version":"2.10.0","proto":1,"max_payload":1048576}` + "\r\n"))
+	reader := bufio.NewReader(conn)
+	var inboxSID string
+	var published bool
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch op {
+		case "CONNECT":
+			s.connects <- arg
+		case "SUB":
+			fields := strings.Fields(arg)
+			inboxSID = fields[len(fields)-1]
+		case "PUB":
+			fields := strings.Fields(arg)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if s.pubErr != "" {
+				_, _ = conn.Write([]byte("-ERR '" + s.pubErr + "'\r\n"))
+				continue
+			}
+			s.messages <- fields[0] + " " + string(payload[:size])
+			published = true
+			if len(fields) == 3 {
+				_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[1], inboxSID, len(s.ack), s.ack)
+			}
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+			if s.closeAfter && published {
+				return
+			}
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	server := newNATSServer(t, nil)
+	publisher := NewNATSPublisher("nats://user:pass@"+server.listener.Addr().String(), "serp.events")
+	defer publisher.Close()
+
+	for range 2 {
+		if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	var options map[string]any
+	if err := json.Unmarshal([]byte(<-server.connects), &options); err != nil || options["user"] != "user" || options["pass"] != "pass" {
+		t.Errorf("Unexpected connect options: %v (%v)", options, err)
+	}
+	if len(server.connects) != 0 {
+		t.Error("Expected the connection to be reused")
+	}
+	subject, payload, _ := strings.Cut(<-server.messages, " ")
+	var event Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil || subject != "serp.events" || event.Query != "golang" {
+		t.Errorf("Unexpected message on %s: %s (%v)", subject, payload, err)
+	}
+}
+
+func TestNATSPublisherReconnects(t *testing.T) {
+	server := newNATSServer(t, func(s *natsServer) { s.closeAfter = true })
+	publisher := NewNATSPublisher("nats://"+server.listener.Addr().String(), "serp.events")
+	publisher.Options = []nats.Option{nats.ReconnectWait(10 * time.Millisecond)}
+	defer publisher.Close()
+
+	for range 2 {
+		if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	if len(server.messages) < 2 {
+		t.Errorf("Expected both events after reconnecting, got %d", len(server.messages))
+	}
+}
+
+func TestNATSPublisherJetStream(t *testing.T) {
+	server := newNATSServer(t, func(s *natsServer) { s.ack = `{"stream":"SERP","seq":1}` })
+	publisher := NewNATSPublisher("nats://"+server.listener.Addr().String(), "serp.events")
+	publisher.JetStream = true
+	defer publisher.Close()
+
+	if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if subject, _, _ := strings.Cut(<-server.messages, " "); subject != "serp.events" {
+		t.Errorf("Expected the event on serp.events, got %s", subject)
+	}
+
+	// Without a stream capturing the subject the event is not persisted
+	server = newNATSServer(t, func(s *natsServer) {
+		s.ack = `{"error":{"code":503,"err_code":10039,"description":"jetstream not enabled"}}`
+	})
+	publisher = NewNATSPublisher("nats://"+server.listener.Addr().String(), "serp.events")
+	publisher.JetStream = true
+	defer publisher.Close()
+	if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err == nil || !strings.Contains(err.Error(), "jetstream not enabled") {
+		t.Errorf("Expected the JetStream error, got %v", err)
+	}
+}
+
+func TestNATSPublisherErrors(t *testing.T) {
+	server := newNATSServer(t, func(s *natsServer) { s.pubErr = `Permissions Violation for Publish to "serp.events"` })
+	publisher := NewNATSPublisher("nats://"+server.listener.Addr().String(), "serp.events")
+	defer publisher.Close()
+
+	err := publisher.Publish(context.Background(), NewEvent("google_search", testResult()))
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("Expected the server error, got %v", err)
+	}
+
+	publisher.Subject = "serp events"
+	if err := publisher.Publish(context.Background(), NewEvent("google_search", testResult())); err == nil {
+		t.Error("Expected an error for an invalid subject")
+	}
+}
@@ -0,0 +1,151 @@
+//go:build js && wasm
+
+// Command wasm exposes the omniserp client and normalizer to browser JavaScript.
+//
+// Build and serve it with the Go WebAssembly support file:
+//
+//	GOOS=js GOARCH=wasm go build -o omniserp.wasm ./examples/wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Browsers cannot call the search APIs directly because of CORS, so searches
+// are sent to a proxy that forwards requests and injects the API key.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/client/serpapi"
+	"github.com/plexusone/omniserp/client/serper"
+)
+
+func main() {
+	js.Global().Set("omniserpNormalize", js.FuncOf(normalize))
+	js.Global().Set("omniserpSearch", js.FuncOf(search))
+
+	// Keep the Go runtime alive for callbacks
+	select {}
+}
+
+// normalize(engine, kind, rawJSON, query) returns normalized JSON for a raw
+// engine response. kind is one of "search", "news", "images", or "scholar".
+func normalize(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return jsError("usage: omniserpNormalize(engine, kind, rawJSON, [query])")
+	}
+	query := ""
+	if len(args) > 3 {
+		query = args[3].String()
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal([]byte(args[2].String()), &data); err != nil {
+		return jsError(fmt.Sprintf("failed to parse raw response: %v", err))
+	}
+
+	output, err := normalizeResult(args[0].String(), args[1].String(), &omniserp.SearchResult{Data: data}, query)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return output
+}
+
+// search(proxyURL, engine, query) returns a Promise resolving to normalized
+// web search results as JSON. The proxy must expose the engine's API paths.
+func search(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return jsError("usage: omniserpSearch(proxyURL, engine, query)")
+	}
+	proxyURL, engineName, query := args[0].String(), args[1].String(), args[2].String()
+
+	return newPromise(func() (string, error) {
+		c, err := newProxyClient(proxyURL, engineName)
+		if err != nil {
+			return "", err
+		}
+		result, err := c.SearchNormalized(context.Background(), omniserp.SearchParams{Query: query})
+		if err != nil {
+			return "", err
+		}
+		output, err := json.Marshal(result)
+		return string(output), err
+	})
+}
+
+// newProxyClient creates a client whose engine sends requests to proxyURL.
+// The proxy injects the real API key, so a placeholder is used here.
+func newProxyClient(proxyURL, engineName string) (*client.Client, error) {
+	registry := omniserp.NewRegistry()
+	switch engineName {
+	case "serper":
+		engine, err := serper.NewWithAPIKey("proxy")
+		if err != nil {
+			return nil, err
+		}
+		engine.SetBaseURL(proxyURL)
+		registry.Register(engine)
+	case "serpapi":
+		engine, err := serpapi.NewWithAPIKey("proxy")
+		if err != nil {
+			return nil, err
+		}
+		engine.SetBaseURL(proxyURL)
+		registry.Register(engine)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", engineName)
+	}
+	return client.NewWithRegistry(registry, engineName)
+}
+
+func normalizeResult(engineName, kind string, result *omniserp.SearchResult, query string) (string, error) {
+	normalizer := omniserp.NewNormalizer(engineName)
+
+	var normalized *omniserp.NormalizedSearchResult
+	var err error
+	switch kind {
+	case "search":
+		normalized, err = normalizer.NormalizeSearch(result, query)
+	case "news":
+		normalized, err = normalizer.NormalizeNews(result, query)
+	case "images":
+		normalized, err = normalizer.NormalizeImages(result, query)
+	case "scholar":
+		normalized, err = normalizer.NormalizeScholar(result, query)
+	default:
+		return "", fmt.Errorf("unsupported kind: %s", kind)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	output, err := json.Marshal(normalized)
+	return string(output), err
+}
+
+// newPromise runs fn in a goroutine, since blocking HTTP calls must not run
+// on the JavaScript event loop, and settles a Promise with its result
+func newPromise(fn func() (string, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			output, err := fn()
+			if err != nil {
+				reject.Invoke(jsError(err.Error()))
+				return
+			}
+			resolve.Invoke(output)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
@@ -0,0 +1,161 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/plexusone/omniserp"
+)
+
+// bibtexEscaper escapes characters with special meaning in BibTeX field values
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+)
+
+// bibtexURLEscaper keeps URLs valid in BibTeX and LaTeX: braces and
+// backslashes are percent-encoded, which does not change the URL, and
+// percent signs are escaped so they do not start a comment
+var bibtexURLEscaper = strings.NewReplacer(
+	"%", `\%`,
+	"{", `\%7B`,
+	"}", `\%7D`,
+	`\`, `\%5C`,
+)
+
+// WriteBibTeX writes scholar results as BibTeX @article entries. Duplicate
+// keys get the suffixes a to z, then aa, ab, and so on.
+func WriteBibTeX(w io.Writer, results []omniserp.ScholarResult) error {
+	used := make(map[string]bool)
+	duplicates := make(map[string]int)
+
+	for _, r := range results {
+		base := citationKey(r)
+		key := base
+		for used[key] {
+			key = base + keySuffix(duplicates[base])
+			duplicates[base]++
+		}
+		used[key] = true
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "@article{%s,\n", key)
+		writeBibTeXField(&b, "title", r.Title)
+		writeBibTeXField(&b, "author", strings.Join(r.Authors, " and "))
+		writeBibTeXField(&b, "journal", r.Source)
+		writeBibTeXField(&b, "year", r.Year)
+		writeBibTeXField(&b, "url", r.Link)
+		writeBibTeXField(&b, "abstract", r.Snippet)
+		if r.Citations > 0 {
+			writeBibTeXField(&b, "note", fmt.Sprintf("Cited by %d", r.Citations))
+		}
+		b.WriteString("}\n\n")
+
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeBibTeXField writes a single non-empty field line
+func writeBibTeXField(b *strings.Builder, name, value string) {
+	if value = strings.TrimSpace(value); value == "" {
+		return
+	}
+	if name == "url" {
+		value = bibtexURLEscaper.Replace(value)
+	} else {
+		value = bibtexEscaper.Replace(value)
+	}
+	fmt.Fprintf(b, "  %s = {%s},\n", name, value)
+}
+
+// citationKey builds a key such as "vaswani2017attention"
+func citationKey(r omniserp.ScholarResult) string {
+	var key strings.Builder
+
+	if len(r.Authors) > 0 {
+		fields := strings.Fields(r.Authors[0])
+		if len(fields) > 0 {
+			key.WriteString(keyPart(fields[len(fields)-1]))
+		}
+	}
+	key.WriteString(keyPart(r.Year))
+	for _, word := range strings.Fields(r.Title) {
+		if part := keyPart(word); len(part) > 3 {
+			key.WriteString(part)
+			break
+		}
+	}
+
+	if key.Len() == 0 {
+		return fmt.Sprintf("result%d", r.Position)
+	}
+	return key.String()
+}
+
+// keySuffix returns the n-th suffix of a duplicate key: a to z for 0 to 25,
+// then aa, ab, and so on
+func keySuffix(n int) string {
+	var suffix []byte
+	for n++; n > 0; n = (n - 1) / 26 {
+		suffix = append([]byte{byte('a' + (n-1)%26)}, suffix...) // #nosec G115 -- 'a' plus a remainder below 26
+	}
+	return string(suffix)
+}
+
+// keyPart lowercases s and strips everything but letters and digits
+func keyPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// WriteRIS writes scholar results in the RIS citation format
+func WriteRIS(w io.Writer, results []omniserp.ScholarResult) error {
+	for _, r := range results {
+		var b strings.Builder
+		writeRISTag(&b, "TY", "JOUR")
+		writeRISTag(&b, "TI", r.Title)
+		for _, author := range r.Authors {
+			writeRISTag(&b, "AU", author)
+		}
+		writeRISTag(&b, "PY", r.Year)
+		writeRISTag(&b, "JO", r.Source)
+		writeRISTag(&b, "AB", r.Snippet)
+		writeRISTag(&b, "UR", r.Link)
+		writeRISTag(&b, "L1", r.PDF)
+		if r.Citations > 0 {
+			writeRISTag(&b, "N1", fmt.Sprintf("Cited by %d", r.Citations))
+		}
+		b.WriteString("ER  - \n\n")
+
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeRISTag writes a single non-empty tag line
+func writeRISTag(b *strings.Builder, tag, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s  - %s\n", tag, value)
+}
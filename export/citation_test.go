@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+var scholarResults = []omniserp.ScholarResult{
+	{
+		Position:  1,
+		Title:     "Attention is all you need",
+		Link:      "https://arxiv.org/abs/1706.03762",
+		Authors:   []string{"A Vaswani", "N Shazeer"},
+		Year:      "2017",
+		Source:    "Advances in neural information processing systems",
+		Citations: 100000,
+	},
+	{
+		Position: 2,
+		Title:    "Attention is all you need & more",
+		Authors:  []string{"A Vaswani"},
+		Year:     "2017",
+	},
+}
+
+func TestWriteBibTeX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBibTeX(&buf, scholarResults); err != nil {
+		t.Fatalf("WriteBibTeX failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"@article{vaswani2017attention,",
+		"@article{vaswani2017attentiona,",
+		"  author = {A Vaswani and N Shazeer},",
+		"  year = {2017},",
+		"  title = {Attention is all you need \\& more},",
+		"  note = {Cited by 100000},",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("BibTeX output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteBibTeXURL(t *testing.T) {
+	var buf bytes.Buffer
+	results := []omniserp.ScholarResult{{Title: "Escaping", Link: `https://example.com/a%20b?q={x}&p=\_y#z`}}
+	if err := WriteBibTeX(&buf, results); err != nil {
+		t.Fatalf("WriteBibTeX failed: %v", err)
+	}
+
+	want := `  url = {https://example.com/a\%20b?q=\%7Bx\%7D&p=\%5C_y#z},`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("BibTeX output missing %q\n%s", want, buf.String())
+	}
+}
+
+func TestKeySuffix(t *testing.T) {
+	tests := map[int]string{0: "a", 25: "z", 26: "aa", 27: "ab", 701: "zz", 702: "aaa"}
+	for n, want := range tests {
+		if got := keySuffix(n); got != want {
+			t.Errorf("keySuffix(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	results := make([]omniserp.ScholarResult, 29)
+	for i := range results {
+		results[i] = omniserp.ScholarResult{Title: "Attention", Authors: []string{"A Vaswani"}, Year: "2017"}
+	}
+	var buf bytes.Buffer
+	if err := WriteBibTeX(&buf, results); err != nil {
+		t.Fatalf("WriteBibTeX failed: %v", err)
+	}
+	for _, want := range []string{"{vaswani2017attention,", "{vaswani2017attentionz,", "{vaswani2017attentionaa,", "{vaswani2017attentionab,"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("BibTeX output missing key %q", want)
+		}
+	}
+	keys := make(map[string]bool)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if key, ok := strings.CutPrefix(line, "@article{"); ok {
+			if keys[key] {
+				t.Errorf("Duplicate key %s", key)
+			}
+			keys[key] = true
+		}
+	}
+	if len(keys) != len(results) {
+		t.Errorf("Expected %d keys, got %d", len(results), len(keys))
+	}
+}
+
+func TestWriteRIS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRIS(&buf, scholarResults[:1]); err != nil {
+		t.Fatalf("WriteRIS failed: %v", err)
+	}
+
+	want := "TY  - JOUR\nTI  - Attention is all you need\nAU  - A Vaswani\nAU  - N Shazeer\nPY  - 2017\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("Unexpected RIS output:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "ER  - \n") {
+		t.Error("RIS record missing ER terminator")
+	}
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/plexusone/omniserp"
+)
+
+// CSVWriter writes records as CSV with a header row
+type CSVWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a CSV writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write flattens and writes a normalized result
+func (cw *CSVWriter) Write(result *omniserp.NormalizedSearchResult) error {
+	return cw.WriteRecords(Flatten(result))
+}
+
+// WriteRecords writes already flattened records
+func (cw *CSVWriter) WriteRecords(records []Record) error {
+	if !cw.wroteHeader {
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.name
+		}
+		if err := cw.w.Write(header); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+
+	row := make([]string, len(columns))
+	for i := range records {
+		for j, col := range columns {
+			row[j] = formatValue(col.value(&records[i]))
+		}
+		if err := cw.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes buffered CSV data
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,255 @@
+// Package export flattens normalized search results into tabular records and
+// writes them as CSV, JSON Lines, or Parquet files.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Format identifies an export file format
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// Result types included in exported records
+const (
+	TypeOrganic  = "organic"
+	TypeNews     = "news"
+	TypeShopping = "shopping"
+	TypePlace    = "place"
+)
+
+// Record is a single flattened result row shared by all result types
+type Record struct {
+	Query    string  `json:"query"`
+	Engine   string  `json:"engine"`
+	Type     string  `json:"type"`
+	Position int     `json:"position"`
+	Title    string  `json:"title"`
+	Link     string  `json:"link,omitempty"`
+	Snippet  string  `json:"snippet,omitempty"`
+	Source   string  `json:"source,omitempty"`
+	Date     string  `json:"date,omitempty"`
+	Price    string  `json:"price,omitempty"`
+	Rating   float64 `json:"rating,omitempty"`
+	Reviews  int     `json:"reviews,omitempty"`
+	Address  string  `json:"address,omitempty"`
+
+	// FetchedAt is when the engine returned the item (RFC 3339)
+	FetchedAt string `json:"fetched_at,omitempty"`
+
+	// Annotations of organic and news results (see omniserp.Annotate)
+	Sentiment      string   `json:"sentiment,omitempty"`
+	SentimentScore float64  `json:"sentiment_score,omitempty"`
+	Entities       []string `json:"entities,omitempty"`
+}
+
+// Writer writes normalized results as flattened records
+type Writer interface {
+	// Write flattens and writes a normalized result
+	Write(result *omniserp.NormalizedSearchResult) error
+
+	// Close flushes buffered data; it does not close the underlying io.Writer
+	Close() error
+}
+
+// NewWriter creates a Writer for the given format
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	case FormatParquet:
+		return NewParquetWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// Flatten converts the organic, news, shopping, and place results into records
+func Flatten(result *omniserp.NormalizedSearchResult) []Record {
+	if result == nil {
+		return nil
+	}
+
+	query := result.SearchMetadata.Query
+
+	// Items carry their own engine when results from several engines were merged
+	engine := func(itemEngine string) string {
+		if itemEngine != "" {
+			return itemEngine
+		}
+		return result.SearchMetadata.Engine
+	}
+	records := make([]Record, 0,
+		len(result.OrganicResults)+len(result.NewsResults)+len(result.ShoppingResults)+len(result.PlaceResults))
+
+	for _, r := range result.OrganicResults {
+		records = append(records, Record{
+			Query:     query,
+			Engine:    engine(r.Engine),
+			FetchedAt: formatTime(r.FetchedAt),
+			Type:      TypeOrganic,
+			Position:  r.Position,
+			Title:     r.Title,
+			Link:      r.Link,
+			Snippet:   r.Snippet,
+			Source:    r.Domain,
+			Date:      r.Date,
+		}.annotated(r.Annotations))
+	}
+
+	for _, r := range result.NewsResults {
+		records = append(records, Record{
+			Query:     query,
+			Engine:    engine(r.Engine),
+			FetchedAt: formatTime(r.FetchedAt),
+			Type:      TypeNews,
+			Position:  r.Position,
+			Title:     r.Title,
+			Link:      r.Link,
+			Snippet:   r.Snippet,
+			Source:    r.Source,
+			Date:      r.Date,
+		}.annotated(r.Annotations))
+	}
+
+	for _, r := range result.ShoppingResults {
+		records = append(records, Record{
+			Query:     query,
+			Engine:    engine(r.Engine),
+			FetchedAt: formatTime(r.FetchedAt),
+			Type:      TypeShopping,
+			Position:  r.Position,
+			Title:     r.Title,
+			Link:      r.Link,
+			Source:    r.Source,
+			Price:     r.Price,
+			Rating:    r.Rating,
+			Reviews:   r.Reviews,
+		})
+	}
+
+	for _, r := range result.PlaceResults {
+		records = append(records, Record{
+			Query:     query,
+			Engine:    engine(r.Engine),
+			FetchedAt: formatTime(r.FetchedAt),
+			Type:      TypePlace,
+			Position:  r.Position,
+			Title:     r.Title,
+			Link:      r.Website,
+			Price:     r.Price,
+			Rating:    r.Rating,
+			Reviews:   r.Reviews,
+			Address:   r.Address,
+		})
+	}
+
+	return records
+}
+
+// annotated returns the record with the sentiment and entities of an item
+func (r Record) annotated(annotations *omniserp.Annotations) Record {
+	if annotations != nil {
+		r.Sentiment = annotations.Sentiment
+		r.SentimentScore = annotations.SentimentScore
+		r.Entities = annotations.Entities
+	}
+	return r
+}
+
+// columnKind is the value type of an exported column
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt
+	kindFloat
+)
+
+// column describes one exported field of a Record. Its value is nil when
+// the record has no value for the column, such as the rating of an organic
+// result, which is written as null or an empty cell.
+type column struct {
+	name  string
+	kind  columnKind
+	value func(r *Record) any
+}
+
+// columns defines the exported column order shared by all formats
+var columns = []column{
+	{"query", kindString, func(r *Record) any { return r.Query }},
+	{"engine", kindString, func(r *Record) any { return r.Engine }},
+	{"type", kindString, func(r *Record) any { return r.Type }},
+	{"position", kindInt, func(r *Record) any { return int64(r.Position) }},
+	{"title", kindString, func(r *Record) any { return r.Title }},
+	{"link", kindString, func(r *Record) any { return r.Link }},
+	{"snippet", kindString, func(r *Record) any { return r.Snippet }},
+	{"source", kindString, func(r *Record) any { return r.Source }},
+	{"date", kindString, func(r *Record) any { return r.Date }},
+	{"price", kindString, func(r *Record) any { return r.Price }},
+	{"rating", kindFloat, func(r *Record) any {
+		if !r.rated() {
+			return nil
+		}
+		return r.Rating
+	}},
+	{"reviews", kindInt, func(r *Record) any {
+		if !r.rated() {
+			return nil
+		}
+		return int64(r.Reviews)
+	}},
+	{"address", kindString, func(r *Record) any { return r.Address }},
+	{"fetched_at", kindString, func(r *Record) any { return r.FetchedAt }},
+	{"sentiment", kindString, func(r *Record) any { return r.Sentiment }},
+	{"sentiment_score", kindFloat, func(r *Record) any {
+		if r.Sentiment == "" {
+			return nil
+		}
+		return r.SentimentScore
+	}},
+	{"entities", kindString, func(r *Record) any { return strings.Join(r.Entities, "; ") }},
+}
+
+// rated reports whether the record is of a type with ratings and review
+// counts, so a zero is a value rather than absent
+func (r *Record) rated() bool {
+	return r.Type == TypeShopping || r.Type == TypePlace
+}
+
+// formatTime renders a fetch time as RFC 3339, or an empty string if unset
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatValue renders a column value as text, using an empty string for zero numbers
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
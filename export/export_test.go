@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+func testResult() *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		OrganicResults: []omniserp.OrganicResult{
+			{Position: 1, Title: "Go", Link: "https://go.dev", Snippet: "The Go programming language"},
+			{Position: 2, Title: "Go, \"the\" docs", Link: "https://go.dev/doc"},
+		},
+		NewsResults: []omniserp.NewsResult{
+			{Position: 1, Title: "Go 1.25 released", Link: "https://go.dev/blog", Source: "Go Blog", Date: "1 day ago"},
+		},
+		PlaceResults: []omniserp.PlaceResult{
+			{Position: 1, Title: "Gopher Cafe", Address: "1 Main St", Rating: 4.5, Reviews: 120},
+		},
+		SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "golang"},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	records := Flatten(testResult())
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(records))
+	}
+
+	types := []string{TypeOrganic, TypeOrganic, TypeNews, TypePlace}
+	for i, r := range records {
+		if r.Type != types[i] {
+			t.Errorf("Record %d: expected type %s, got %s", i, types[i], r.Type)
+		}
+		if r.Query != "golang" || r.Engine != "serper" {
+			t.Errorf("Record %d: missing query/engine provenance: %+v", i, r)
+		}
+	}
+
+	if records[3].Rating != 4.5 || records[3].Reviews != 120 {
+		t.Errorf("Place rating/reviews not flattened: %+v", records[3])
+	}
+}
+
+func TestFlattenItemProvenance(t *testing.T) {
+	result := testResult()
+	fetched := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result.NewsResults[0].Engine = "serpapi"
+	result.NewsResults[0].FetchedAt = fetched
+
+	records := Flatten(result)
+	if records[2].Engine != "serpapi" || records[2].FetchedAt != "2025-06-01T12:00:00Z" {
+		t.Errorf("Expected item engine and fetch time, got %+v", records[2])
+	}
+	if records[0].Engine != "serper" || records[0].FetchedAt != "" {
+		t.Errorf("Expected metadata engine fallback, got %+v", records[0])
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	if err := w.Write(testResult()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV output: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Expected header + 4 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "query" || len(rows[0]) != len(columns) {
+		t.Errorf("Unexpected header: %v", rows[0])
+	}
+	if rows[2][4] != "Go, \"the\" docs" {
+		t.Errorf("Expected quoted title to round-trip, got %q", rows[2][4])
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLWriter(&buf)
+	if err := w.Write(testResult()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d", len(lines))
+	}
+
+	var r Record
+	if err := json.Unmarshal([]byte(lines[2]), &r); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if r.Type != TypeNews || r.Source != "Go Blog" {
+		t.Errorf("Unexpected news record: %+v", r)
+	}
+}
+
+func TestParquetWriterLayout(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf)
+	if err := w.Write(testResult()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatal("Parquet file must start and end with PAR1")
+	}
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	if footerLen <= 0 || footerLen > len(data)-12 {
+		t.Fatalf("Invalid footer length %d for file of %d bytes", footerLen, len(data))
+	}
+
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	for _, col := range columns {
+		if !bytes.Contains(footer, []byte(col.name)) {
+			t.Errorf("Footer missing column %q", col.name)
+		}
+	}
+}
+
+func TestEncodeDefinitionLevels(t *testing.T) {
+	got := encodeDefinitionLevels([]bool{true, true, false, true})
+	want := []byte{2 << 1, 1, 1 << 1, 0, 1 << 1, 1}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeDefinitionLevels = %v, want %v", got, want)
+	}
+}
+
+func TestNewWriterUnsupported(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "xlsx"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
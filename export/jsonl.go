@@ -0,0 +1,38 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/plexusone/omniserp"
+)
+
+// JSONLWriter writes one JSON object per record per line
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLWriter creates a JSON Lines writer
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// Write flattens and writes a normalized result
+func (jw *JSONLWriter) Write(result *omniserp.NormalizedSearchResult) error {
+	return jw.WriteRecords(Flatten(result))
+}
+
+// WriteRecords writes already flattened records
+func (jw *JSONLWriter) WriteRecords(records []Record) error {
+	for i := range records {
+		if err := jw.enc.Encode(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op; records are written as they arrive
+func (jw *JSONLWriter) Close() error {
+	return nil
+}
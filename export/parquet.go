@@ -0,0 +1,317 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ParquetWriter buffers records and writes them as a single row group Parquet
+// file on Close. All columns are optional; empty strings and numbers the
+// record does not have, such as the rating of an organic result, are stored
+// as nulls, while zeros are stored as values. Pages are PLAIN encoded and
+// uncompressed, which keeps the writer dependency-free while remaining
+// readable by Spark, DuckDB, Arrow, etc.
+type ParquetWriter struct {
+	w       io.Writer
+	records []Record
+	closed  bool
+}
+
+// NewParquetWriter creates a Parquet writer
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{w: w}
+}
+
+// Write flattens and buffers a normalized result
+func (pw *ParquetWriter) Write(result *omniserp.NormalizedSearchResult) error {
+	return pw.WriteRecords(Flatten(result))
+}
+
+// WriteRecords buffers already flattened records
+func (pw *ParquetWriter) WriteRecords(records []Record) error {
+	pw.records = append(pw.records, records...)
+	return nil
+}
+
+// Parquet format constants (see parquet.thrift)
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetRepetitionOptional = 1
+	parquetConvertedTypeUTF8  = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetPageTypeData       = 0
+	parquetCodecUncompressed  = 0
+)
+
+// parquetType returns the physical Parquet type of a column kind
+func parquetType(kind columnKind) int32 {
+	switch kind {
+	case kindInt:
+		return parquetTypeInt64
+	case kindFloat:
+		return parquetTypeDouble
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+// columnChunkMeta records where a column chunk was written
+type columnChunkMeta struct {
+	col    column
+	offset int64
+	size   int64
+}
+
+// Close writes the buffered records as a Parquet file
+func (pw *ParquetWriter) Close() error {
+	if pw.closed {
+		return nil
+	}
+	pw.closed = true
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	chunks := make([]columnChunkMeta, 0, len(columns))
+	for _, col := range columns {
+		offset := int64(file.Len())
+		pw.writeColumnChunk(&file, col)
+		chunks = append(chunks, columnChunkMeta{col: col, offset: offset, size: int64(file.Len()) - offset})
+	}
+
+	footer := pw.fileMetaData(chunks)
+	file.Write(footer)
+
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(len(footer))) // #nosec G115 -- footer size is bounded by the schema
+	file.Write(footerLen[:])
+	file.WriteString(parquetMagic)
+
+	_, err := pw.w.Write(file.Bytes())
+	return err
+}
+
+// writeColumnChunk writes a single data page containing all values of a column
+func (pw *ParquetWriter) writeColumnChunk(buf *bytes.Buffer, col column) {
+	defLevels := make([]bool, len(pw.records))
+	var values bytes.Buffer
+
+	for i := range pw.records {
+		v := col.value(&pw.records[i])
+		switch val := v.(type) {
+		case nil:
+			continue
+		case string:
+			if val == "" {
+				continue
+			}
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(val))) // #nosec G115 -- string length fits in uint32
+			values.WriteString(val)
+		case int64:
+			_ = binary.Write(&values, binary.LittleEndian, val)
+		case float64:
+			_ = binary.Write(&values, binary.LittleEndian, math.Float64bits(val))
+		}
+		defLevels[i] = true
+	}
+
+	levels := encodeDefinitionLevels(defLevels)
+
+	var page bytes.Buffer
+	_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels))) // #nosec G115 -- level data is small
+	page.Write(levels)
+	page.Write(values.Bytes())
+
+	var header thriftCompact
+	header.fieldI32(1, parquetPageTypeData)
+	header.fieldI32(2, int32(page.Len())) // #nosec G115 -- page size fits in int32
+	header.fieldI32(3, int32(page.Len())) // #nosec G115 -- page size fits in int32
+	header.fieldStructBegin(5)
+	header.fieldI32(1, int32(len(pw.records))) // #nosec G115 -- record count fits in int32
+	header.fieldI32(2, parquetEncodingPlain)
+	header.fieldI32(3, parquetEncodingRLE)
+	header.fieldI32(4, parquetEncodingRLE)
+	header.structEnd()
+	header.structEnd()
+
+	buf.Write(header.Bytes())
+	buf.Write(page.Bytes())
+}
+
+// encodeDefinitionLevels encodes bit-width 1 definition levels as RLE runs
+func encodeDefinitionLevels(levels []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		buf = binary.AppendUvarint(buf, uint64(j-i)<<1)
+		if levels[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+// fileMetaData encodes the Parquet footer
+func (pw *ParquetWriter) fileMetaData(chunks []columnChunkMeta) []byte {
+	numRows := int64(len(pw.records))
+
+	var totalSize int64
+	for _, c := range chunks {
+		totalSize += c.size
+	}
+
+	var t thriftCompact
+	t.fieldI32(1, 1) // version
+
+	// Schema: root element followed by one element per column
+	t.fieldListBegin(2, thriftTypeStruct, len(columns)+1)
+	t.structBegin()
+	t.fieldBinary(4, "schema")
+	t.fieldI32(5, int32(len(columns))) // #nosec G115 -- column count is a small constant
+	t.structEnd()
+	for _, col := range columns {
+		t.structBegin()
+		t.fieldI32(1, parquetType(col.kind))
+		t.fieldI32(3, parquetRepetitionOptional)
+		t.fieldBinary(4, col.name)
+		if col.kind == kindString {
+			t.fieldI32(6, parquetConvertedTypeUTF8)
+		}
+		t.structEnd()
+	}
+
+	t.fieldI64(3, numRows)
+
+	// A single row group holding every column chunk
+	t.fieldListBegin(4, thriftTypeStruct, 1)
+	t.structBegin()
+	t.fieldListBegin(1, thriftTypeStruct, len(chunks))
+	for _, c := range chunks {
+		t.structBegin()
+		t.fieldI64(2, c.offset)
+		t.fieldStructBegin(3)
+		t.fieldI32(1, parquetType(c.col.kind))
+		t.fieldListBegin(2, thriftTypeI32, 2)
+		t.i32(parquetEncodingPlain)
+		t.i32(parquetEncodingRLE)
+		t.fieldListBegin(3, thriftTypeBinary, 1)
+		t.binary(c.col.name)
+		t.fieldI32(4, parquetCodecUncompressed)
+		t.fieldI64(5, numRows)
+		t.fieldI64(6, c.size)
+		t.fieldI64(7, c.size)
+		t.fieldI64(9, c.offset)
+		t.structEnd()
+		t.structEnd()
+	}
+	t.fieldI64(2, totalSize)
+	t.fieldI64(3, numRows)
+	t.structEnd()
+
+	t.fieldBinary(6, "omniserp export")
+	t.structEnd()
+
+	return t.Bytes()
+}
+
+// Thrift compact protocol type identifiers
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftCompact is a minimal Thrift compact protocol encoder sufficient for
+// Parquet page headers and file metadata
+type thriftCompact struct {
+	bytes.Buffer
+	lastField []int16
+	current   int16
+}
+
+func (t *thriftCompact) fieldHeader(id int16, typ byte) {
+	delta := id - t.current
+	if delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.current = id
+}
+
+func (t *thriftCompact) varint(v int64) {
+	t.Write(binary.AppendUvarint(nil, uint64((v<<1)^(v>>63)))) // #nosec G115 -- zigzag encoding
+}
+
+func (t *thriftCompact) i32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftCompact) binary(s string) {
+	t.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.WriteString(s)
+}
+
+func (t *thriftCompact) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftTypeI32)
+	t.i32(v)
+}
+
+func (t *thriftCompact) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftTypeI64)
+	t.varint(v)
+}
+
+func (t *thriftCompact) fieldBinary(id int16, s string) {
+	t.fieldHeader(id, thriftTypeBinary)
+	t.binary(s)
+}
+
+func (t *thriftCompact) fieldListBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elemType) // #nosec G115 -- size is below 15
+	} else {
+		t.WriteByte(0xf0 | elemType)
+		t.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+func (t *thriftCompact) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftTypeStruct)
+	t.structBegin()
+}
+
+// structBegin starts a nested struct (a list element or struct field)
+func (t *thriftCompact) structBegin() {
+	t.lastField = append(t.lastField, t.current)
+	t.current = 0
+}
+
+// structEnd writes the stop byte and restores the enclosing field id
+func (t *thriftCompact) structEnd() {
+	t.WriteByte(0)
+	if n := len(t.lastField); n > 0 {
+		t.current = t.lastField[n-1]
+		t.lastField = t.lastField[:n-1]
+	}
+}
@@ -0,0 +1,334 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// No Parquet reader module is vendored, so these tests decode the file with
+// the small reader below, written from parquet.thrift and the Thrift compact
+// protocol specification rather than from the writer.
+
+// thriftReader decodes Thrift compact protocol structs into maps of field id
+// to value: int64 for integers, []byte for binaries, []any for lists and
+// map[int16]any for structs
+type thriftReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *thriftReader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.fail("unexpected end of data at %d", r.pos)
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[min(r.pos, len(r.data)):])
+	if n <= 0 {
+		r.fail("invalid varint at %d", r.pos)
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1) // #nosec G115 -- zigzag decoding
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 3:
+		return int64(r.byte())
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		if r.pos+8 > len(r.data) {
+			r.fail("unexpected end of double at %d", r.pos)
+			return 0.0
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v
+	case 8:
+		n := int(r.uvarint()) // #nosec G115 -- bounded by the check below
+		if n < 0 || r.pos+n > len(r.data) {
+			r.fail("invalid binary length %d at %d", n, r.pos)
+			return []byte(nil)
+		}
+		v := r.data[r.pos : r.pos+n]
+		r.pos += n
+		return v
+	case 9, 10:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint()) // #nosec G115 -- list sizes are small
+		}
+		list := make([]any, 0, size)
+		for i := 0; i < size && r.err == nil; i++ {
+			list = append(list, r.value(header&0x0f))
+		}
+		return list
+	case 12:
+		return r.structure()
+	default:
+		r.fail("unsupported thrift type %d at %d", typ, r.pos)
+		return nil
+	}
+}
+
+func (r *thriftReader) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for r.err == nil {
+		header := r.byte()
+		if header == 0 {
+			break
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag()) // #nosec G115 -- field ids fit in int16
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+	return fields
+}
+
+// readParquetColumns decodes every column of a Parquet file written by
+// ParquetWriter; nulls are nil
+func readParquetColumns(data []byte) (map[string][]any, error) {
+	if len(data) < 12 || !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		return nil, fmt.Errorf("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footerLen > len(data)-12 {
+		return nil, fmt.Errorf("invalid footer length %d", footerLen)
+	}
+	footer := &thriftReader{data: data[len(data)-8-footerLen : len(data)-8]}
+	meta := footer.structure()
+	if footer.err != nil {
+		return nil, fmt.Errorf("invalid footer: %w", footer.err)
+	}
+
+	numRows, _ := meta[3].(int64)
+	rowGroups, _ := meta[4].([]any)
+	if len(rowGroups) != 1 {
+		return nil, fmt.Errorf("expected 1 row group, got %d", len(rowGroups))
+	}
+	chunks, _ := rowGroups[0].(map[int16]any)[1].([]any)
+
+	columns := make(map[string][]any)
+	for _, c := range chunks {
+		chunkMeta, _ := c.(map[int16]any)[3].(map[int16]any)
+		typ, _ := chunkMeta[1].(int64)
+		path, _ := chunkMeta[3].([]any)
+		offset, _ := chunkMeta[9].(int64)
+		if len(path) != 1 || offset <= 0 || offset >= int64(len(data)) {
+			return nil, fmt.Errorf("invalid column chunk metadata %v", chunkMeta)
+		}
+		name := string(path[0].([]byte))
+
+		page := &thriftReader{data: data[offset:]}
+		header := page.structure()
+		if page.err != nil {
+			return nil, fmt.Errorf("invalid page header of %s: %w", name, page.err)
+		}
+		size, _ := header[3].(int64)
+		dataHeader, _ := header[5].(map[int16]any)
+		if count, _ := dataHeader[1].(int64); count != numRows {
+			return nil, fmt.Errorf("page of %s has %d values, want %d", name, count, numRows)
+		}
+		body := data[offset+int64(page.pos):]
+		if int64(len(body)) < size {
+			return nil, fmt.Errorf("truncated page of %s", name)
+		}
+
+		values, err := decodeOptionalPage(body[:size], typ, int(numRows))
+		if err != nil {
+			return nil, fmt.Errorf("invalid page of %s: %w", name, err)
+		}
+		columns[name] = values
+	}
+	return columns, nil
+}
+
+// decodeOptionalPage decodes the RLE/bit-packed hybrid definition levels of
+// bit width 1 and the PLAIN values of a data page
+func decodeOptionalPage(page []byte, typ int64, n int) ([]any, error) {
+	if len(page) < 4 {
+		return nil, fmt.Errorf("missing definition levels")
+	}
+	levelsLen := int(binary.LittleEndian.Uint32(page))
+	if 4+levelsLen > len(page) {
+		return nil, fmt.Errorf("invalid definition levels length %d", levelsLen)
+	}
+	levels := page[4 : 4+levelsLen]
+	values := page[4+levelsLen:]
+
+	defined := make([]bool, 0, n)
+	for len(levels) > 0 && len(defined) < n {
+		header, k := binary.Uvarint(levels)
+		if k <= 0 {
+			return nil, fmt.Errorf("invalid run header")
+		}
+		levels = levels[k:]
+		if header&1 == 0 {
+			if len(levels) < 1 {
+				return nil, fmt.Errorf("truncated RLE run")
+			}
+			for i := uint64(0); i < header>>1; i++ {
+				defined = append(defined, levels[0] == 1)
+			}
+			levels = levels[1:]
+			continue
+		}
+		groups := int(header >> 1) // #nosec G115 -- run lengths are small
+		if len(levels) < groups {
+			return nil, fmt.Errorf("truncated bit-packed run")
+		}
+		for _, b := range levels[:groups] {
+			for bit := 0; bit < 8; bit++ {
+				defined = append(defined, b&(1<<bit) != 0)
+			}
+		}
+		levels = levels[groups:]
+	}
+	if len(defined) < n {
+		return nil, fmt.Errorf("got %d definition levels, want %d", len(defined), n)
+	}
+
+	out := make([]any, n)
+	for i := 0; i < n; i++ {
+		if !defined[i] {
+			continue
+		}
+		switch typ {
+		case parquetTypeInt64:
+			if len(values) < 8 {
+				return nil, fmt.Errorf("truncated int64 value")
+			}
+			out[i] = int64(binary.LittleEndian.Uint64(values)) // #nosec G115 -- two's complement value
+			values = values[8:]
+		case parquetTypeDouble:
+			if len(values) < 8 {
+				return nil, fmt.Errorf("truncated double value")
+			}
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetTypeByteArray:
+			if len(values) < 4 {
+				return nil, fmt.Errorf("truncated byte array length")
+			}
+			size := int(binary.LittleEndian.Uint32(values))
+			if 4+size > len(values) {
+				return nil, fmt.Errorf("truncated byte array value")
+			}
+			out[i] = string(values[4 : 4+size])
+			values = values[4+size:]
+		default:
+			return nil, fmt.Errorf("unsupported physical type %d", typ)
+		}
+	}
+	if len(values) != 0 {
+		return nil, fmt.Errorf("%d bytes left after the values", len(values))
+	}
+	return out, nil
+}
+
+func TestParquetWriterReadBack(t *testing.T) {
+	result := testResult()
+	result.OrganicResults[1].Position = 0
+	result.PlaceResults = append(result.PlaceResults, omniserp.PlaceResult{Position: 2, Title: "New Cafe"})
+
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf)
+	if err := w.Write(result); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	got, err := readParquetColumns(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to read Parquet file: %v", err)
+	}
+	if len(got) != len(columns) {
+		t.Errorf("Expected %d columns, got %d", len(columns), len(got))
+	}
+
+	// Rows: two organic results, one news result and two places, the
+	// second of them without a rating
+	tests := []struct {
+		column string
+		want   []any
+	}{
+		{"type", []any{TypeOrganic, TypeOrganic, TypeNews, TypePlace, TypePlace}},
+		{"position", []any{int64(1), int64(0), int64(1), int64(1), int64(2)}},
+		{"title", []any{"Go", "Go, \"the\" docs", "Go 1.25 released", "Gopher Cafe", "New Cafe"}},
+		{"link", []any{"https://go.dev", "https://go.dev/doc", "https://go.dev/blog", nil, nil}},
+		{"rating", []any{nil, nil, nil, 4.5, 0.0}},
+		{"reviews", []any{nil, nil, nil, int64(120), int64(0)}},
+		{"sentiment_score", []any{nil, nil, nil, nil, nil}},
+	}
+	for _, tt := range tests {
+		values := got[tt.column]
+		if len(values) != len(tt.want) {
+			t.Errorf("Column %s has %d values, want %d", tt.column, len(values), len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			if values[i] != want {
+				t.Errorf("%s[%d] = %#v, want %#v", tt.column, i, values[i], want)
+			}
+		}
+	}
+}
+
+func TestParquetWriterSentimentZero(t *testing.T) {
+	records := []Record{
+		{Type: TypeOrganic, Position: 1, Sentiment: "neutral"},
+		{Type: TypeOrganic, Position: 2},
+	}
+
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf)
+	if err := w.WriteRecords(records); err != nil {
+		t.Fatalf("WriteRecords failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	got, err := readParquetColumns(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to read Parquet file: %v", err)
+	}
+	if scores := got["sentiment_score"]; len(scores) != 2 || scores[0] != 0.0 || scores[1] != nil {
+		t.Errorf("sentiment_score = %#v, want [0 <nil>]", scores)
+	}
+}
@@ -0,0 +1,158 @@
+package omniserp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Types of extra parameters, as JSON Schema type names
+const (
+	ExtraString  = "string"
+	ExtraBoolean = "boolean"
+	ExtraInteger = "integer"
+	ExtraNumber  = "number"
+)
+
+// ExtraParam describes an engine-specific search parameter, which is passed
+// by name in SearchParams.Extra
+type ExtraParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// ExtraParamReporter is implemented by engines that accept engine-specific
+// parameters in SearchParams.Extra. Engines only send the parameters they
+// report and ignore other names.
+type ExtraParamReporter interface {
+	// ExtraParams returns the engine-specific parameters in name order
+	ExtraParams() []ExtraParam
+}
+
+// DescribeExtraParams describes the engine-specific parameters declared as
+// the fields of a struct, such as
+//
+//	type ExtraParams struct {
+//		Device string `extra:"device" description:"Device to emulate"`
+//	}
+//
+// The type of each parameter is derived from the field type. Fields without
+// an extra tag are skipped. It returns the parameters in name order.
+func DescribeExtraParams(v any) []ExtraParam {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []ExtraParam
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("extra")
+		if name == "" || name == "-" {
+			continue
+		}
+		params = append(params, ExtraParam{
+			Name:        name,
+			Type:        extraType(field.Type),
+			Description: field.Tag.Get("description"),
+		})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// extraType returns the JSON Schema type name of a Go type
+func extraType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return ExtraBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ExtraInteger
+	case reflect.Float32, reflect.Float64:
+		return ExtraNumber
+	}
+	return ExtraString
+}
+
+// ExtraParams returns the engine-specific parameters of an engine, or none
+// if it does not implement ExtraParamReporter
+func ExtraParams(engine Engine) []ExtraParam {
+	if reporter, ok := engine.(ExtraParamReporter); ok {
+		return reporter.ExtraParams()
+	}
+	return nil
+}
+
+// ExtraArgs returns the values of the parameters of params.Extra that are
+// described by spec, by name. String values, such as those given on a command
+// line, are converted to the declared type; values that do not convert are
+// kept as given for the engine API to reject.
+func ExtraArgs(params SearchParams, spec []ExtraParam) map[string]any {
+	if len(params.Extra) == 0 {
+		return nil
+	}
+	args := make(map[string]any)
+	for _, param := range spec {
+		value, ok := params.Extra[param.Name]
+		if !ok || value == nil {
+			continue
+		}
+		args[param.Name] = convertExtra(value, param.Type)
+	}
+	return args
+}
+
+// convertExtra converts a string value to an extra parameter type
+func convertExtra(value any, typ string) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	s = strings.TrimSpace(s)
+	switch typ {
+	case ExtraBoolean:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case ExtraInteger:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case ExtraNumber:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// formatExtra formats an extra parameter value for a query string
+func formatExtra(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// ExtraQuery returns the values of ExtraArgs formatted for a query string
+func ExtraQuery(params SearchParams, spec []ExtraParam) map[string]string {
+	args := ExtraArgs(params, spec)
+	if len(args) == 0 {
+		return nil
+	}
+	query := make(map[string]string, len(args))
+	for name, value := range args {
+		query[name] = formatExtra(value)
+	}
+	return query
+}
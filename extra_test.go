@@ -0,0 +1,62 @@
+package omniserp
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testExtra struct {
+	Device  string  `extra:"device" description:"Device to emulate"`
+	NoCache bool    `extra:"no_cache" description:"Skip the cache"`
+	Filter  int     `extra:"filter"`
+	Ratio   float64 `extra:"ratio"`
+	Ignored string
+}
+
+func TestDescribeExtraParams(t *testing.T) {
+	want := []ExtraParam{
+		{Name: "device", Type: ExtraString, Description: "Device to emulate"},
+		{Name: "filter", Type: ExtraInteger},
+		{Name: "no_cache", Type: ExtraBoolean, Description: "Skip the cache"},
+		{Name: "ratio", Type: ExtraNumber},
+	}
+	for _, v := range []any{testExtra{}, &testExtra{}} {
+		if got := DescribeExtraParams(v); !reflect.DeepEqual(got, want) {
+			t.Errorf("DescribeExtraParams(%T) = %+v, want %+v", v, got, want)
+		}
+	}
+	if got := DescribeExtraParams("device"); got != nil {
+		t.Errorf("Expected no parameters for a non-struct, got %+v", got)
+	}
+}
+
+func TestExtraArgs(t *testing.T) {
+	spec := DescribeExtraParams(testExtra{})
+	params := SearchParams{Extra: map[string]any{
+		"device":   "mobile",
+		"no_cache": "true",
+		"filter":   " 0 ",
+		"ratio":    0.5,
+		"other":    "dropped",
+	}}
+
+	want := map[string]any{"device": "mobile", "no_cache": true, "filter": int64(0), "ratio": 0.5}
+	if got := ExtraArgs(params, spec); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtraArgs() = %#v, want %#v", got, want)
+	}
+
+	// Values that do not convert are passed on as given
+	params.Extra = map[string]any{"filter": "none"}
+	if got := ExtraArgs(params, spec); got["filter"] != "none" {
+		t.Errorf("Expected the unconverted value, got %#v", got["filter"])
+	}
+
+	params.Extra = map[string]any{"no_cache": true, "ratio": 0.25, "filter": float64(1)}
+	wantQuery := map[string]string{"no_cache": "true", "ratio": "0.25", "filter": "1"}
+	if got := ExtraQuery(params, spec); !reflect.DeepEqual(got, wantQuery) {
+		t.Errorf("ExtraQuery() = %v, want %v", got, wantQuery)
+	}
+	if got := ExtraQuery(SearchParams{}, spec); got != nil {
+		t.Errorf("Expected no query without extras, got %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package omniserp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CanonicalQuery normalizes a query for comparison: it is lowercased and
+// runs of whitespace are collapsed to single spaces
+func CanonicalQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Canonical returns the canonical form of the parameters: the canonical
+// query and the non-default parameters, lowercased and sorted by name.
+// Parameters that differ only in casing, whitespace, or an explicit first
+// page have the same canonical form, as do a locale preset and its fields.
+func (p SearchParams) Canonical() string {
+	if _, ok := LookupLocale(p.Locale); ok {
+		p = p.WithLocale()
+		p.Locale = ""
+	}
+	fields := map[string]string{
+		"q":        CanonicalQuery(p.Query),
+		"location": CanonicalQuery(p.Location),
+		"hl":       strings.ToLower(strings.TrimSpace(p.Language)),
+		"gl":       strings.ToLower(strings.TrimSpace(p.Country)),
+		"locale":   strings.ToLower(strings.TrimSpace(p.Locale)),
+		"domain":   strings.ToLower(strings.TrimSpace(p.GoogleDomain)),
+		"tbs":      p.ImageTBS(),
+		"cites":    strings.TrimSpace(p.Cites),
+	}
+	if p.NumResults > 0 {
+		fields["num"] = strconv.Itoa(p.NumResults)
+	}
+	if p.Page > 1 {
+		fields["page"] = strconv.Itoa(p.Page)
+	}
+	if p.SafeSearch {
+		fields["safe"] = "active"
+	}
+	if p.Verbatim {
+		fields["nfpr"] = "1"
+	}
+	if p.Image != nil && p.Image.MinWidth > 0 {
+		fields["imgw"] = strconv.Itoa(p.Image.MinWidth)
+	}
+	if p.Image != nil && p.Image.MinHeight > 0 {
+		fields["imgh"] = strconv.Itoa(p.Image.MinHeight)
+	}
+	if p.NewsRanking != nil {
+		r := p.NewsRanking.withDefaults()
+		fields["newsrank"] = strings.Join([]string{
+			strconv.FormatFloat(r.HalfLifeHours, 'g', -1, 64),
+			strconv.FormatFloat(r.RecencyWeight, 'g', -1, 64),
+			strconv.FormatFloat(r.SourcePenalty, 'g', -1, 64),
+		}, ",")
+	}
+	for name, value := range p.Extra {
+		if value != nil {
+			fields["x."+name] = formatExtra(value)
+		}
+	}
+
+	pairs := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if value != "" {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// Fingerprint returns a short stable hash of the canonical parameters for
+// use as a cache key, for deduplication, and for usage analytics
+func (p SearchParams) Fingerprint() string {
+	sum := sha256.Sum256([]byte(p.Canonical()))
+	return hex.EncodeToString(sum[:8])
+}
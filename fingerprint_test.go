@@ -0,0 +1,41 @@
+package omniserp
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	base := SearchParams{Query: "golang generics", Country: "us", NumResults: 10}
+
+	same := []SearchParams{
+		{Query: "  Golang   Generics ", Country: "US", NumResults: 10},
+		{Query: "golang\tgenerics", Country: "us", NumResults: 10, Page: 1},
+		{Query: "golang generics", Country: "us", NumResults: 10, Extra: map[string]any{"device": nil}},
+	}
+	ranked := SearchParams{Query: "golang generics", NewsRanking: &NewsRanking{}}
+	if explicit := (SearchParams{Query: "golang generics", NewsRanking: &NewsRanking{HalfLifeHours: DefaultNewsHalfLifeHours}}); explicit.Fingerprint() != ranked.Fingerprint() {
+		t.Errorf("Expected the default news ranking to match, got %q and %q", explicit.Canonical(), ranked.Canonical())
+	}
+	for _, p := range same {
+		if p.Fingerprint() != base.Fingerprint() {
+			t.Errorf("Expected %+v to match %q, got %q", p, base.Canonical(), p.Canonical())
+		}
+	}
+
+	different := []SearchParams{
+		{Query: "golang generic", Country: "us", NumResults: 10},
+		{Query: "golang generics", Country: "uk", NumResults: 10},
+		{Query: "golang generics", Country: "us", NumResults: 10, Page: 2},
+		{Query: "golang generics", Country: "us", NumResults: 10, Freshness: FreshnessWeek},
+		{Query: "golang generics", Country: "us", NumResults: 10, Verbatim: true},
+		{Query: "golang generics", Country: "us", NumResults: 10, Extra: map[string]any{"device": "mobile"}},
+		{Query: "golang generics", Country: "us", NumResults: 10, NewsRanking: &NewsRanking{}},
+	}
+	for _, p := range different {
+		if p.Fingerprint() == base.Fingerprint() {
+			t.Errorf("Expected %+v to differ from %+v", p, base)
+		}
+	}
+
+	if got, want := base.Canonical(), "gl=us&num=10&q=golang generics"; got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+}
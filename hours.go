@@ -0,0 +1,265 @@
+package omniserp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minutesPerDay is the number of minutes in a day
+const minutesPerDay = 24 * 60
+
+// TimeRange is an opening period in minutes after midnight. Close is after
+// Open; periods that end after midnight close later than 24*60.
+type TimeRange struct {
+	Open  int `json:"open"`
+	Close int `json:"close"`
+}
+
+// String formats the period as "09:00-17:00"
+func (r TimeRange) String() string {
+	closeAt := r.Close
+	if closeAt > minutesPerDay {
+		closeAt -= minutesPerDay
+	}
+	return formatMinutes(r.Open) + "-" + formatMinutes(closeAt)
+}
+
+// formatMinutes formats minutes after midnight as "15:04"
+func formatMinutes(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// OpeningHours is a weekly schedule of a place
+type OpeningHours struct {
+	// Days are the opening periods indexed by time.Weekday. Days without
+	// periods are closed or were not listed.
+	Days [7][]TimeRange `json:"days"`
+}
+
+// OpenNow reports whether the place is open at t, which must be in the
+// local time of the place. Periods after midnight count towards the day they
+// started on.
+func (h *OpeningHours) OpenNow(t time.Time) bool {
+	if h == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	for _, r := range h.Days[t.Weekday()] {
+		if minute >= r.Open && minute < r.Close {
+			return true
+		}
+	}
+	for _, r := range h.Days[(t.Weekday()+6)%7] {
+		if minute+minutesPerDay >= r.Open && minute+minutesPerDay < r.Close {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the schedule as "Monday: 09:00-17:00; Tuesday: ..."
+func (h *OpeningHours) String() string {
+	if h == nil {
+		return ""
+	}
+	days := make([]string, 0, 7)
+	for i := range 7 {
+		day := time.Weekday((i + 1) % 7) // Monday first
+		var periods []string
+		for _, r := range h.Days[day] {
+			periods = append(periods, r.String())
+		}
+		if len(periods) == 0 {
+			periods = []string{"Closed"}
+		}
+		days = append(days, day.String()+": "+strings.Join(periods, ", "))
+	}
+	return strings.Join(days, "; ")
+}
+
+// ErrHoursFormat is returned by ParseHours for text without a weekly schedule
+var ErrHoursFormat = errors.New("unrecognized opening hours")
+
+var (
+	dayPattern = `(?:mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?`
+	dayListRe  = regexp.MustCompile(`^(` + dayPattern + `(?:\s*(?:-|,|&|and)\s*` + dayPattern + `)*)\s*:?\s*(.*)$`)
+	timeRe     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+)
+
+// weekdays maps three-letter day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseHours parses free-text weekly opening hours, with one entry per day
+// or day range separated by semicolons or newlines, such as
+// "Monday: 9 AM–5 PM; Tuesday: 11 AM–2 PM, 5–10 PM; Sun: Closed" or
+// "Mon-Fri 09:00-17:00\nSat: Open 24 hours". Times without AM or PM use the
+// 24-hour clock unless the end of the period has one. Days that are not
+// listed are closed.
+func ParseHours(text string) (*OpeningHours, error) {
+	text = strings.NewReplacer(
+		"\u2013", "-", "\u2014", "-", "\u2011", "-", " to ", "-",
+		"\u00a0", " ", "\u2009", " ", "\u202f", " ",
+	).Replace(strings.ToLower(text))
+
+	hours := &OpeningHours{}
+	entries := 0
+	for _, entry := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		m := dayListRe.FindStringSubmatch(entry)
+		if m == nil {
+			return nil, fmt.Errorf("%w: %q", ErrHoursFormat, entry)
+		}
+		days, err := parseDays(m[1])
+		if err != nil {
+			return nil, err
+		}
+		periods, err := parsePeriods(m[2])
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			hours.Days[day] = append(hours.Days[day], periods...)
+		}
+		entries++
+	}
+	if entries == 0 {
+		return nil, ErrHoursFormat
+	}
+	return hours, nil
+}
+
+// parseDays parses a day list such as "mon-fri", "sat & sun", or "monday"
+func parseDays(list string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.FieldsFunc(strings.ReplaceAll(list, " and ", "&"), func(r rune) bool { return r == ',' || r == '&' }) {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := parseDay(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseDay(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		// Ranges may wrap around the week, such as "fri-mon"
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseDay parses a day name by its first three letters
+func parseDay(name string) (time.Weekday, error) {
+	name = strings.TrimSpace(name)
+	if len(name) >= 3 {
+		if day, ok := weekdays[name[:3]]; ok {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown day %q", ErrHoursFormat, name)
+}
+
+// parsePeriods parses the opening periods of a day, such as
+// "9 am-5 pm", "11-2 pm, 5-10 pm", "closed", or "open 24 hours"
+func parsePeriods(text string) ([]TimeRange, error) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "open"))
+	switch text {
+	case "closed":
+		return nil, nil
+	case "24 hours", "24h", "all day":
+		return []TimeRange{{Open: 0, Close: minutesPerDay}}, nil
+	}
+
+	var periods []TimeRange
+	for _, period := range strings.Split(text, ",") {
+		bounds := strings.Split(period, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: period %q", ErrHoursFormat, strings.TrimSpace(period))
+		}
+		closeAt, closeMeridiem, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		openAt, openMeridiem, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		if openMeridiem == "" && closeMeridiem != "" && openAt <= 12*60 {
+			// The start takes the meridiem of the end unless it would then
+			// start after it ends, as in "11-2 pm"
+			hour, minute := openAt/60, openAt%60
+			openAt = clock12(hour, minute, closeMeridiem)
+			if openAt >= closeAt {
+				openAt = clock12(hour, minute, otherMeridiem(closeMeridiem))
+			}
+		}
+		if closeAt <= openAt {
+			closeAt += minutesPerDay
+		}
+		periods = append(periods, TimeRange{Open: openAt, Close: closeAt})
+	}
+	return periods, nil
+}
+
+// parseClock parses a time of day such as "9", "9:30 am", or "17:00" into
+// minutes after midnight and returns its meridiem, which is empty for the
+// 24-hour clock
+func parseClock(text string) (int, string, error) {
+	text = strings.TrimSpace(text)
+	switch text {
+	case "noon":
+		return 12 * 60, "pm", nil
+	case "midnight":
+		return 0, "am", nil
+	}
+	m := timeRe.FindStringSubmatch(text)
+	if m == nil {
+		return 0, "", fmt.Errorf("%w: time %q", ErrHoursFormat, text)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	meridiem := strings.ReplaceAll(m[3], ".", "")
+	if hour > 24 || minute > 59 || (meridiem != "" && hour > 12) {
+		return 0, "", fmt.Errorf("%w: time %q", ErrHoursFormat, text)
+	}
+	if meridiem != "" {
+		return clock12(hour, minute, meridiem), meridiem, nil
+	}
+	return (hour*60 + minute) % minutesPerDay, "", nil
+}
+
+// clock12 converts a 12-hour clock time to minutes after midnight
+func clock12(hour, minute int, meridiem string) int {
+	hour %= 12
+	if meridiem == "pm" {
+		hour += 12
+	}
+	return hour*60 + minute
+}
+
+// otherMeridiem returns pm for am and am for pm
+func otherMeridiem(meridiem string) string {
+	if meridiem == "am" {
+		return "pm"
+	}
+	return "am"
+}
@@ -0,0 +1,60 @@
+package omniserp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseHours(t *testing.T) {
+	hours, err := ParseHours("Monday: 9 AM–5 PM; Tuesday: 11–2 PM, 5–10 PM; Wed-Fri 09:00-17:30\nSaturday: Open 24 hours; Sunday: Closed")
+	if err != nil {
+		t.Fatalf("ParseHours failed: %v", err)
+	}
+	want := "Monday: 09:00-17:00; Tuesday: 11:00-14:00, 17:00-22:00; Wednesday: 09:00-17:30; " +
+		"Thursday: 09:00-17:30; Friday: 09:00-17:30; Saturday: 00:00-24:00; Sunday: Closed"
+	if got := hours.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, text := range []string{"", "Open ⋅ Closes 6 PM", "Monday: soon", "Funday: 9-5"} {
+		if _, err := ParseHours(text); !errors.Is(err, ErrHoursFormat) {
+			t.Errorf("ParseHours(%q) = %v, want ErrHoursFormat", text, err)
+		}
+	}
+}
+
+func TestOpenNow(t *testing.T) {
+	hours, err := ParseHours("Fri-Sat: 6 PM–2 AM; Sun & Mon: 10:00-14:00")
+	if err != nil {
+		t.Fatalf("ParseHours failed: %v", err)
+	}
+
+	// January 2, 2026 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		t    time.Time
+		open bool
+	}{
+		{at(2, 17, 59), false},
+		{at(2, 18, 0), true},
+		{at(3, 1, 30), true}, // Friday night
+		{at(3, 2, 0), false},
+		{at(4, 1, 0), true}, // Saturday night
+		{at(4, 10, 0), true},
+		{at(5, 13, 59), true},
+		{at(6, 12, 0), false},
+	}
+	for _, tt := range tests {
+		if got := hours.OpenNow(tt.t); got != tt.open {
+			t.Errorf("OpenNow(%s) = %t, want %t", tt.t.Format("Mon 15:04"), got, tt.open)
+		}
+	}
+
+	var unknown *OpeningHours
+	if unknown.OpenNow(at(2, 12, 0)) {
+		t.Error("Expected unknown hours to be closed")
+	}
+}
@@ -0,0 +1,130 @@
+package omniserp
+
+import "strings"
+
+// ImageAspect is an aspect ratio class of images
+type ImageAspect string
+
+// Aspect ratio classes supported by ImageFilter
+const (
+	AspectSquare    ImageAspect = "square"    // width within 10% of height
+	AspectTall      ImageAspect = "tall"      // narrower than square
+	AspectWide      ImageAspect = "wide"      // wider than square, including panoramic
+	AspectPanoramic ImageAspect = "panoramic" // at least twice as wide as high
+)
+
+// ImageLicense is a usage rights filter of images
+type ImageLicense string
+
+// Usage rights supported by ImageFilter
+const (
+	// LicenseCreativeCommons keeps images under Creative Commons licenses
+	LicenseCreativeCommons ImageLicense = "creative_commons"
+
+	// LicenseCommercial keeps images with commercial and other licenses
+	LicenseCommercial ImageLicense = "commercial"
+)
+
+// ImageFilter restricts the results of image searches. Engines apply the
+// filters they support; the client additionally enforces the size and
+// aspect ratio on the normalized results (see FilterImages).
+type ImageFilter struct {
+	// MinWidth and MinHeight are the minimum dimensions in pixels
+	MinWidth  int `json:"min_width,omitempty" jsonschema:"description:Minimum image width in pixels"`
+	MinHeight int `json:"min_height,omitempty" jsonschema:"description:Minimum image height in pixels"`
+
+	// Aspect is the aspect ratio class
+	Aspect ImageAspect `json:"aspect,omitempty" jsonschema:"description:Aspect ratio: square, tall, wide, or panoramic"`
+
+	// Transparent keeps images with a transparent background
+	Transparent bool `json:"transparent,omitempty" jsonschema:"description:Only images with a transparent background"`
+
+	// License keeps images with the given usage rights
+	License ImageLicense `json:"license,omitempty" jsonschema:"description:Usage rights: creative_commons or commercial"`
+}
+
+// TBS returns the Google "tbs" image filters, such as "ic:trans,iar:w", or
+// "" if none apply. The minimum size has no Google equivalent.
+func (f *ImageFilter) TBS() string {
+	if f == nil {
+		return ""
+	}
+	var parts []string
+	if f.Transparent {
+		parts = append(parts, "ic:trans")
+	}
+	switch f.Aspect {
+	case AspectSquare:
+		parts = append(parts, "iar:s")
+	case AspectTall:
+		parts = append(parts, "iar:t")
+	case AspectWide:
+		parts = append(parts, "iar:w")
+	case AspectPanoramic:
+		parts = append(parts, "iar:xw")
+	}
+	switch f.License {
+	case LicenseCreativeCommons:
+		parts = append(parts, "il:cl")
+	case LicenseCommercial:
+		parts = append(parts, "il:ol")
+	}
+	return strings.Join(parts, ",")
+}
+
+// ImageTBS returns the Google "tbs" parameter of an image search: the
+// freshness and image filters of the parameters
+func (p SearchParams) ImageTBS() string {
+	var parts []string
+	for _, tbs := range []string{p.Freshness.TBS(), p.Image.TBS()} {
+		if tbs != "" {
+			parts = append(parts, tbs)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Matches reports whether an image satisfies the size and aspect ratio of
+// the filter. Images with unknown dimensions do not match if the filter
+// constrains them. Transparency and license cannot be checked from results
+// and are left to the engine.
+func (f *ImageFilter) Matches(image ImageResult) bool {
+	if f == nil || (f.MinWidth <= 0 && f.MinHeight <= 0 && f.Aspect == "") {
+		return true
+	}
+	if image.Width <= 0 || image.Height <= 0 {
+		return false
+	}
+	if image.Width < f.MinWidth || image.Height < f.MinHeight {
+		return false
+	}
+
+	ratio := float64(image.Width) / float64(image.Height)
+	switch f.Aspect {
+	case AspectSquare:
+		return ratio >= 0.9 && ratio <= 1.1
+	case AspectTall:
+		return ratio < 0.9
+	case AspectWide:
+		return ratio > 1.1
+	case AspectPanoramic:
+		return ratio >= 2
+	}
+	return true
+}
+
+// FilterImages returns the images that match the filter, renumbering their
+// positions; images is not modified
+func FilterImages(images []ImageResult, f *ImageFilter) []ImageResult {
+	if f == nil {
+		return images
+	}
+	var filtered []ImageResult
+	for _, image := range images {
+		if f.Matches(image) {
+			image.Position = len(filtered) + 1
+			filtered = append(filtered, image)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,61 @@
+package omniserp
+
+import "testing"
+
+func TestImageTBS(t *testing.T) {
+	params := SearchParams{
+		Freshness: FreshnessMonth,
+		Image: &ImageFilter{
+			MinWidth:    1024,
+			Aspect:      AspectPanoramic,
+			Transparent: true,
+			License:     LicenseCommercial,
+		},
+	}
+	if got, want := params.ImageTBS(), "qdr:m,ic:trans,iar:xw,il:ol"; got != want {
+		t.Errorf("ImageTBS() = %q, want %q", got, want)
+	}
+	if got := (SearchParams{}).ImageTBS(); got != "" {
+		t.Errorf("Expected no tbs without filters, got %q", got)
+	}
+}
+
+func TestFilterImages(t *testing.T) {
+	images := []ImageResult{
+		{Position: 1, Title: "square", Width: 1000, Height: 1000},
+		{Position: 2, Title: "small", Width: 200, Height: 100},
+		{Position: 3, Title: "unknown"},
+		{Position: 4, Title: "wide", Width: 1600, Height: 900},
+		{Position: 5, Title: "panorama", Width: 3000, Height: 1000},
+		{Position: 6, Title: "tall", Width: 800, Height: 1200},
+	}
+
+	tests := []struct {
+		filter *ImageFilter
+		want   []string
+	}{
+		{nil, []string{"square", "small", "unknown", "wide", "panorama", "tall"}},
+		{&ImageFilter{License: LicenseCreativeCommons}, []string{"square", "small", "unknown", "wide", "panorama", "tall"}},
+		{&ImageFilter{MinWidth: 900}, []string{"square", "wide", "panorama"}},
+		{&ImageFilter{MinHeight: 1000}, []string{"square", "panorama", "tall"}},
+		{&ImageFilter{Aspect: AspectSquare}, []string{"square"}},
+		{&ImageFilter{Aspect: AspectWide}, []string{"small", "wide", "panorama"}},
+		{&ImageFilter{Aspect: AspectPanoramic}, []string{"small", "panorama"}},
+		{&ImageFilter{Aspect: AspectTall, MinWidth: 500}, []string{"tall"}},
+	}
+	for _, tt := range tests {
+		got := FilterImages(images, tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("FilterImages(%+v) returned %d images, want %v", tt.filter, len(got), tt.want)
+			continue
+		}
+		for i, image := range got {
+			if image.Title != tt.want[i] || image.Position != i+1 {
+				t.Errorf("FilterImages(%+v)[%d] = %s at %d, want %s at %d", tt.filter, i, image.Title, image.Position, tt.want[i], i+1)
+			}
+		}
+	}
+	if images[3].Position != 4 {
+		t.Error("Expected the input images to be unchanged")
+	}
+}
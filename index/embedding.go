@@ -0,0 +1,117 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// DefaultEmbeddingURL is the OpenAI embeddings endpoint
+const DefaultEmbeddingURL = "https://api.openai.com/v1/embeddings"
+
+// DefaultEmbeddingModel is the embedding model used when none is set
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// HTTPEmbedder calls an OpenAI-compatible embeddings endpoint, which
+// includes OpenAI and local servers such as Ollama and llama.cpp
+type HTTPEmbedder struct {
+	URL    string
+	Model  string
+	APIKey string
+	Client *http.Client
+}
+
+// NewHTTPEmbedder creates an embedder for the OpenAI API using the
+// OPENAI_API_KEY environment variable
+func NewHTTPEmbedder() *HTTPEmbedder {
+	return &HTTPEmbedder{
+		URL:    DefaultEmbeddingURL,
+		Model:  DefaultEmbeddingModel,
+		APIKey: os.Getenv("OPENAI_API_KEY"),
+	}
+}
+
+// Embed implements Embedder
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	request := map[string]any{"model": e.Model, "input": texts}
+	if err := postJSON(ctx, e.Client, http.MethodPost, e.URL, e.headers(), request, &response); err != nil {
+		return nil, fmt.Errorf("failed to embed texts: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range response.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("missing embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// headers returns the request headers
+func (e *HTTPEmbedder) headers() map[string]string {
+	if e.APIKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + e.APIKey}
+}
+
+// errVectorCount reports an embedder that returned the wrong number of vectors
+func errVectorCount(want, got int) error {
+	return fmt.Errorf("embedder returned %d vectors for %d documents", got, want)
+}
+
+// postJSON sends body as JSON and decodes a successful JSON response into
+// out, which may be nil
+func postJSON(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// #nosec G704 -- endpoint URL is configured by the caller
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("API error: %s: %s", resp.Status, string(body))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Package index pushes search and scrape results into a vector store so an
+// agent can retrieve over the research corpus it has accumulated. Set an
+// Indexer on the client to index every normalized search and scrape.
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Document kinds
+const (
+	KindOrganic = "organic"
+	KindNews    = "news"
+	KindScholar = "scholar"
+	KindPage    = "page"
+)
+
+// Document is one indexed result or scraped page
+type Document struct {
+	ID        string    `json:"id"` // derived from the URL, see DocumentID
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Text      string    `json:"text"`
+	Kind      string    `json:"kind"`
+	Query     string    `json:"query,omitempty"` // search that found the document
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+}
+
+// Content returns the text that is embedded for the document
+func (d Document) Content() string {
+	if d.Title == "" {
+		return d.Text
+	}
+	return d.Title + "\n\n" + d.Text
+}
+
+// Indexer stores documents, replacing documents with the same ID
+type Indexer interface {
+	Index(ctx context.Context, docs []Document) error
+}
+
+// Match is a document returned by a similarity search
+type Match struct {
+	Document Document `json:"document"`
+	Score    float64  `json:"score"`
+}
+
+// Searcher retrieves the documents most similar to a query
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) ([]Match, error)
+}
+
+// Embedder converts texts to embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// DocumentID returns the stable ID of the document at a URL, so re-indexing
+// a page replaces its previous version
+func DocumentID(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
+
+// FromSearch converts the organic, news, and scholar results of a normalized
+// result into documents. Results without a link or text are skipped.
+func FromSearch(result *omniserp.NormalizedSearchResult) []Document {
+	if result == nil {
+		return nil
+	}
+	meta := result.SearchMetadata
+
+	var docs []Document
+	add := func(kind, link, title, text, engine string, fetchedAt time.Time) {
+		if link == "" || (title == "" && text == "") {
+			return
+		}
+		if engine == "" {
+			engine = meta.Engine
+		}
+		docs = append(docs, Document{
+			ID:        DocumentID(link),
+			URL:       link,
+			Title:     title,
+			Text:      text,
+			Kind:      kind,
+			Query:     meta.Query,
+			Engine:    engine,
+			FetchedAt: fetchedAt,
+		})
+	}
+	for _, r := range result.OrganicResults {
+		add(KindOrganic, r.Link, r.Title, r.Snippet, r.Engine, r.FetchedAt)
+	}
+	for _, r := range result.NewsResults {
+		add(KindNews, r.Link, r.Title, r.Snippet, r.Engine, r.FetchedAt)
+	}
+	for _, r := range result.ScholarResults {
+		add(KindScholar, r.Link, r.Title, r.Snippet, r.Engine, r.FetchedAt)
+	}
+	return docs
+}
+
+// FromScrape converts a scraped page into a document, or returns nil if the
+// page has no text
+func FromScrape(result *omniserp.NormalizedScrapeResult) []Document {
+	if result == nil || strings.TrimSpace(result.Text) == "" {
+		return nil
+	}
+	url := result.FinalURL
+	if url == "" {
+		url = result.URL
+	}
+	return []Document{{
+		ID:        DocumentID(url),
+		URL:       url,
+		Title:     result.Title,
+		Text:      result.Text,
+		Kind:      KindPage,
+		Engine:    result.Engine,
+		FetchedAt: result.FetchedAt,
+	}}
+}
+
+// MemoryIndex is an in-memory vector index with brute-force cosine
+// similarity search, suitable for an agent session or tests
+type MemoryIndex struct {
+	embedder Embedder
+
+	mu      sync.RWMutex
+	docs    map[string]Document
+	vectors map[string][]float32
+}
+
+// NewMemoryIndex creates an empty index that embeds documents and queries
+// with embedder
+func NewMemoryIndex(embedder Embedder) *MemoryIndex {
+	return &MemoryIndex{
+		embedder: embedder,
+		docs:     make(map[string]Document),
+		vectors:  make(map[string][]float32),
+	}
+}
+
+// Index implements Indexer
+func (m *MemoryIndex) Index(ctx context.Context, docs []Document) error {
+	vectors, err := embedDocuments(ctx, m.embedder, docs)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, doc := range docs {
+		m.docs[doc.ID] = doc
+		m.vectors[doc.ID] = vectors[i]
+	}
+	return nil
+}
+
+// Len returns the number of indexed documents
+func (m *MemoryIndex) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.docs)
+}
+
+// Search implements Searcher
+func (m *MemoryIndex) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	vectors, err := m.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	matches := make([]Match, 0, len(m.docs))
+	for id, doc := range m.docs {
+		matches = append(matches, Match{Document: doc, Score: cosine(vectors[0], m.vectors[id])})
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Document.ID, b.Document.ID)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// embedDocuments embeds the content of docs, checking that the embedder
+// returned one vector per document
+func embedDocuments(ctx context.Context, embedder Embedder, docs []Document) ([][]float32, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Content()
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(docs) {
+		return nil, errVectorCount(len(docs), len(vectors))
+	}
+	return vectors, nil
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 if they differ
+// in length or either is zero
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,155 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+// wordEmbedder embeds texts as hashed bags of words
+type wordEmbedder struct{}
+
+func (wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, 32)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			v[h.Sum32()%32]++
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+var searchResult = &omniserp.NormalizedSearchResult{
+	SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "go concurrency"},
+	OrganicResults: []omniserp.OrganicResult{
+		{Title: "Goroutines and channels", Link: "https://go.dev/tour/concurrency", Snippet: "goroutines channels select"},
+		{Title: "No link", Snippet: "skipped"},
+	},
+	NewsResults: []omniserp.NewsResult{
+		{Title: "Rust async runtime", Link: "https://example.com/rust", Snippet: "tokio futures executor", Engine: "serpapi"},
+	},
+}
+
+func TestFromSearch(t *testing.T) {
+	docs := FromSearch(searchResult)
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(docs))
+	}
+	if docs[0].Kind != KindOrganic || docs[0].Engine != "serper" || docs[0].Query != "go concurrency" {
+		t.Errorf("Unexpected organic document: %+v", docs[0])
+	}
+	if docs[1].Kind != KindNews || docs[1].Engine != "serpapi" {
+		t.Errorf("Unexpected news document: %+v", docs[1])
+	}
+	if docs[0].ID != DocumentID("https://go.dev/tour/concurrency") {
+		t.Errorf("Expected ID derived from the URL, got %s", docs[0].ID)
+	}
+}
+
+func TestMemoryIndex(t *testing.T) {
+	idx := NewMemoryIndex(wordEmbedder{})
+	ctx := context.Background()
+	if err := idx.Index(ctx, FromSearch(searchResult)); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	// Re-indexing replaces documents with the same ID
+	if err := idx.Index(ctx, FromSearch(searchResult)); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if idx.Len() != 2 {
+		t.Errorf("Expected 2 documents, got %d", idx.Len())
+	}
+
+	matches, err := idx.Search(ctx, "goroutines and channels", 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Document.URL != "https://go.dev/tour/concurrency" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestQdrant(t *testing.T) {
+	var upserted []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("api-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/collections/research/points":
+			for _, p := range body["points"].([]any) {
+				upserted = append(upserted, p.(map[string]any))
+			}
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/collections/research/points/search":
+			payload := upserted[0]["payload"]
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"result": []any{map[string]any{"score": 0.9, "payload": payload}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	q := NewQdrant(server.URL, "research", wordEmbedder{})
+	q.APIKey = "secret"
+	ctx := context.Background()
+	if err := q.Index(ctx, FromSearch(searchResult)); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if len(upserted) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(upserted))
+	}
+	if id := upserted[0]["id"].(string); len(id) != 36 || strings.Count(id, "-") != 4 {
+		t.Errorf("Expected a UUID point ID, got %s", id)
+	}
+
+	matches, err := q.Search(ctx, "goroutines", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Score != 0.9 || matches[0].Document.Title != "Goroutines and channels" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "test-model" || len(body.Input) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Out of order, as the API does not guarantee ordering
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	e := &HTTPEmbedder{URL: server.URL, Model: "test-model"}
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+}
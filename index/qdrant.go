@@ -0,0 +1,104 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Qdrant indexes documents in a Qdrant collection through its REST API.
+// Documents are stored as points whose payload holds the document fields.
+type Qdrant struct {
+	URL        string // e.g. http://localhost:6333
+	Collection string
+	APIKey     string
+	Embedder   Embedder
+	Client     *http.Client
+}
+
+// NewQdrant creates a Qdrant indexer for a collection
+func NewQdrant(baseURL, collection string, embedder Embedder) *Qdrant {
+	return &Qdrant{URL: baseURL, Collection: collection, Embedder: embedder}
+}
+
+// CreateCollection creates the collection for vectors of the given size
+// with cosine distance. It fails if the collection already exists.
+func (q *Qdrant) CreateCollection(ctx context.Context, size int) error {
+	body := map[string]any{"vectors": map[string]any{"size": size, "distance": "Cosine"}}
+	if err := postJSON(ctx, q.Client, http.MethodPut, q.endpoint(""), q.headers(), body, nil); err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", q.Collection, err)
+	}
+	return nil
+}
+
+// Index implements Indexer
+func (q *Qdrant) Index(ctx context.Context, docs []Document) error {
+	vectors, err := embedDocuments(ctx, q.Embedder, docs)
+	if err != nil || len(docs) == 0 {
+		return err
+	}
+
+	points := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		points[i] = map[string]any{
+			"id":      pointID(doc.ID),
+			"vector":  vectors[i],
+			"payload": doc,
+		}
+	}
+	body := map[string]any{"points": points}
+	if err := postJSON(ctx, q.Client, http.MethodPut, q.endpoint("/points?wait=true"), q.headers(), body, nil); err != nil {
+		return fmt.Errorf("failed to upsert points: %w", err)
+	}
+	return nil
+}
+
+// Search implements Searcher
+func (q *Qdrant) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	vectors, err := q.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var response struct {
+		Result []struct {
+			Score   float64  `json:"score"`
+			Payload Document `json:"payload"`
+		} `json:"result"`
+	}
+	body := map[string]any{"vector": vectors[0], "limit": limit, "with_payload": true}
+	if err := postJSON(ctx, q.Client, http.MethodPost, q.endpoint("/points/search"), q.headers(), body, &response); err != nil {
+		return nil, fmt.Errorf("failed to search points: %w", err)
+	}
+
+	matches := make([]Match, len(response.Result))
+	for i, r := range response.Result {
+		matches[i] = Match{Document: r.Payload, Score: r.Score}
+	}
+	return matches, nil
+}
+
+// endpoint returns the URL of a collection API path
+func (q *Qdrant) endpoint(path string) string {
+	return strings.TrimRight(q.URL, "/") + "/collections/" + url.PathEscape(q.Collection) + path
+}
+
+// headers returns the request headers
+func (q *Qdrant) headers() map[string]string {
+	if q.APIKey == "" {
+		return nil
+	}
+	return map[string]string{"api-key": q.APIKey}
+}
+
+// pointID formats a document ID as the UUID that Qdrant requires for
+// string point IDs
+func pointID(id string) string {
+	id = (id + strings.Repeat("0", 32))[:32]
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
@@ -0,0 +1,125 @@
+package omniserp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Locale is a named country and language preset, such as "de-DE", with the
+// matching Google domain. Engines that take a market or region code derive
+// it from the country and language, so presets keep them consistent across
+// engines.
+type Locale struct {
+	// Name is the language and country tag of the preset, such as "pt-BR"
+	Name string `json:"name"`
+
+	// Country is the lowercased country code, such as "br"
+	Country string `json:"country"`
+
+	// Language is the lowercased language code, such as "pt"
+	Language string `json:"language"`
+
+	// GoogleDomain is the Google domain of the country, such as
+	// "google.com.br"
+	GoogleDomain string `json:"google_domain"`
+}
+
+// locales are the presets by lowercased name
+var locales = func() map[string]Locale {
+	presets := []Locale{
+		{"en-US", "us", "en", "google.com"},
+		{"en-GB", "gb", "en", "google.co.uk"},
+		{"en-CA", "ca", "en", "google.ca"},
+		{"en-AU", "au", "en", "google.com.au"},
+		{"en-IN", "in", "en", "google.co.in"},
+		{"en-IE", "ie", "en", "google.ie"},
+		{"de-DE", "de", "de", "google.de"},
+		{"de-AT", "at", "de", "google.at"},
+		{"de-CH", "ch", "de", "google.ch"},
+		{"fr-FR", "fr", "fr", "google.fr"},
+		{"fr-CA", "ca", "fr", "google.ca"},
+		{"fr-BE", "be", "fr", "google.be"},
+		{"es-ES", "es", "es", "google.es"},
+		{"es-MX", "mx", "es", "google.com.mx"},
+		{"es-AR", "ar", "es", "google.com.ar"},
+		{"it-IT", "it", "it", "google.it"},
+		{"nl-NL", "nl", "nl", "google.nl"},
+		{"pt-BR", "br", "pt", "google.com.br"},
+		{"pt-PT", "pt", "pt", "google.pt"},
+		{"pl-PL", "pl", "pl", "google.pl"},
+		{"sv-SE", "se", "sv", "google.se"},
+		{"da-DK", "dk", "da", "google.dk"},
+		{"fi-FI", "fi", "fi", "google.fi"},
+		{"tr-TR", "tr", "tr", "google.com.tr"},
+		{"ja-JP", "jp", "ja", "google.co.jp"},
+		{"ko-KR", "kr", "ko", "google.co.kr"},
+		{"hi-IN", "in", "hi", "google.co.in"},
+		{"id-ID", "id", "id", "google.co.id"},
+	}
+	m := make(map[string]Locale, len(presets))
+	for _, l := range presets {
+		m[strings.ToLower(l.Name)] = l
+	}
+	return m
+}()
+
+// LookupLocale returns the preset of a name, matched case-insensitively and
+// with either a hyphen or an underscore, so "pt_br" finds "pt-BR"
+func LookupLocale(name string) (Locale, bool) {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	l, ok := locales[name]
+	return l, ok
+}
+
+// ParseLocale returns the preset of a name like LookupLocale, or an error
+// listing the presets
+func ParseLocale(name string) (Locale, error) {
+	l, ok := LookupLocale(name)
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale %q (available: %s)", name, strings.Join(LocaleNames(), ", "))
+	}
+	return l, nil
+}
+
+// Locales returns the presets sorted by name
+func Locales() []Locale {
+	all := make([]Locale, 0, len(locales))
+	for _, l := range locales {
+		all = append(all, l)
+	}
+	slices.SortFunc(all, func(a, b Locale) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return all
+}
+
+// LocaleNames returns the names of the presets in sorted order
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+	for _, l := range Locales() {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// WithLocale returns p with its unset country, language, and Google domain
+// taken from the preset named by p.Locale. Explicitly set fields take
+// precedence, and unknown names leave p unchanged; use ParseLocale to
+// validate names from users.
+func (p SearchParams) WithLocale() SearchParams {
+	l, ok := LookupLocale(p.Locale)
+	if !ok {
+		return p
+	}
+	if p.Country == "" {
+		p.Country = l.Country
+	}
+	if p.Language == "" {
+		p.Language = l.Language
+	}
+	if p.GoogleDomain == "" {
+		p.GoogleDomain = l.GoogleDomain
+	}
+	return p
+}
@@ -0,0 +1,58 @@
+package omniserp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLookupLocale(t *testing.T) {
+	for _, name := range []string{"pt-BR", "pt_br", " PT-br "} {
+		l, ok := LookupLocale(name)
+		if !ok || l.Name != "pt-BR" || l.Country != "br" || l.Language != "pt" || l.GoogleDomain != "google.com.br" {
+			t.Errorf("LookupLocale(%q) = %+v, %v", name, l, ok)
+		}
+	}
+	if _, err := ParseLocale("xx-YY"); err == nil {
+		t.Error("Expected an error for an unknown locale")
+	}
+
+	names := LocaleNames()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("Expected sorted names, got %v", names)
+		}
+	}
+	for _, l := range Locales() {
+		if l.Country == "" || l.Language == "" || l.GoogleDomain == "" {
+			t.Errorf("Incomplete locale %+v", l)
+		}
+	}
+}
+
+func TestWithLocale(t *testing.T) {
+	got := SearchParams{Query: "go", Locale: "ja-JP", Language: "en"}.WithLocale()
+	if got.Country != "jp" || got.Language != "en" || got.GoogleDomain != "google.co.jp" {
+		t.Errorf("Expected the preset with the explicit language, got %+v", got)
+	}
+	if got := (SearchParams{Query: "go", Locale: "xx-YY"}).WithLocale(); got.Country != "" || got.GoogleDomain != "" {
+		t.Errorf("Expected an unknown locale to change nothing, got %+v", got)
+	}
+
+	// The locale of a search takes precedence over default fields
+	ctx := WithDefaults(context.Background(), SearchParams{Country: "us", Language: "en"})
+	got = ApplyDefaults(ctx, SearchParams{Query: "go", Locale: "de-DE"})
+	if got.Country != "de" || got.Language != "de" || got.GoogleDomain != "google.de" {
+		t.Errorf("Expected the locale over the defaults, got %+v", got)
+	}
+	ctx = WithDefaults(context.Background(), SearchParams{Locale: "fr-FR"})
+	got = ApplyDefaults(ctx, SearchParams{Query: "go", Language: "en"})
+	if got.Country != "fr" || got.Language != "en" || got.GoogleDomain != "google.fr" {
+		t.Errorf("Expected the default locale under the explicit language, got %+v", got)
+	}
+
+	preset := SearchParams{Query: "go", Locale: "de_DE"}
+	explicit := SearchParams{Query: "go", Country: "DE", Language: "de", GoogleDomain: "google.de"}
+	if preset.Fingerprint() != explicit.Fingerprint() {
+		t.Errorf("Expected a locale to match its fields, got %q and %q", preset.Canonical(), explicit.Canonical())
+	}
+}
@@ -0,0 +1,272 @@
+package omniserp
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultNewsSimilarity is the title similarity above which news results
+// are considered copies of the same story
+const DefaultNewsSimilarity = 0.6
+
+// Defaults of NewsRanking
+const (
+	DefaultNewsHalfLifeHours = 24.0
+	DefaultNewsRecencyWeight = 0.7
+	DefaultNewsSourcePenalty = 0.5
+)
+
+// newsDateLayouts are the absolute date formats of news engines
+var newsDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006, 03:04 PM, -0700 MST", // SerpAPI
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// trackingParams are query parameters that do not identify content
+var trackingParams = []string{"utm_", "fbclid", "gclid", "mc_cid", "mc_eid", "ocid", "cmpid", "outputtype"}
+
+// NewsMention is a copy of a story reported by another source
+type NewsMention struct {
+	Title  string `json:"title"`
+	Link   string `json:"link"`
+	Source string `json:"source,omitempty"`
+	Date   string `json:"date,omitempty"`
+}
+
+// CanonicalURL normalizes a URL for detecting copies of the same page: the
+// scheme, a leading "www." or "amp.", AMP path suffixes, a trailing slash,
+// the fragment, and tracking parameters such as utm_* are dropped, and the
+// host is lowercased
+func CanonicalURL(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(link))
+	}
+
+	host := strings.ToLower(u.Host)
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "amp.")
+
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	path = strings.TrimSuffix(path, "/amp")
+	path = strings.TrimSuffix(path, ".amp")
+
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, prefix := range trackingParams {
+			if strings.HasPrefix(lower, prefix) {
+				query.Del(name)
+				break
+			}
+		}
+	}
+
+	key := host + path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+// TitleSimilarity returns the Jaccard similarity (0-1) of the words of two
+// headlines, ignoring case, punctuation, and a trailing " - Source" or
+// " | Source" suffix
+func TitleSimilarity(a, b string) float64 {
+	wa, wb := titleWords(a), titleWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+// titleWords returns the set of lowercased words of a headline
+func titleWords(title string) map[string]bool {
+	// Syndicated headlines often end with the publisher's name
+	for _, sep := range []string{" - ", " | ", " — "} {
+		if i := strings.LastIndex(title, sep); i > len(title)/2 {
+			title = title[:i]
+		}
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// DedupNews groups syndicated copies of the same story and returns one
+// representative per story, in rank order, with the other copies in
+// AlsoReportedBy. Results are copies if their canonical URLs are equal
+// (see CanonicalURL) or their title similarity (see TitleSimilarity) is at
+// least threshold, or DefaultNewsSimilarity if threshold is zero. The
+// representative is the highest-ranked copy.
+func DedupNews(results []NewsResult, threshold float64) []NewsResult {
+	if threshold <= 0 {
+		threshold = DefaultNewsSimilarity
+	}
+
+	var stories []NewsResult
+	var storyURLs []map[string]bool
+	for _, r := range results {
+		canonical := CanonicalURL(r.Link)
+		story := -1
+		for i := range stories {
+			if storyURLs[i][canonical] || TitleSimilarity(stories[i].Title, r.Title) >= threshold {
+				story = i
+				break
+			}
+		}
+
+		if story < 0 {
+			r.AlsoReportedBy = nil
+			stories = append(stories, r)
+			storyURLs = append(storyURLs, map[string]bool{canonical: true})
+			continue
+		}
+		storyURLs[story][canonical] = true
+		stories[story].AlsoReportedBy = append(stories[story].AlsoReportedBy, NewsMention{
+			Title:  r.Title,
+			Link:   r.Link,
+			Source: r.Source,
+			Date:   r.Date,
+		})
+	}
+	return stories
+}
+
+// ParseNewsDate parses the date of a news result: an absolute date in one of
+// the formats of the supported engines, a relative date such as "3 hours
+// ago" measured from now, or "yesterday". It returns the zero time for other
+// dates.
+func ParseNewsDate(date string, now time.Time) time.Time {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Time{}
+	}
+	for _, layout := range newsDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC()
+		}
+	}
+	if strings.EqualFold(date, "yesterday") {
+		return now.AddDate(0, 0, -1)
+	}
+	return parseReviewDate("", date, now)
+}
+
+// NewsRanking reorders news results for a briefing by combining the
+// recency of each story with the engine's ranking, and by demoting further
+// stories from a source already ranked above them. Zero fields use the
+// defaults.
+type NewsRanking struct {
+	// HalfLifeHours is the age at which a story's recency score halves
+	// (default DefaultNewsHalfLifeHours)
+	HalfLifeHours float64 `json:"half_life_hours,omitempty" jsonschema:"description:Age in hours at which a story counts half as recent (default 24)"`
+
+	// RecencyWeight is the share of recency in the score, between 0 and 1,
+	// the rest being the engine's ranking (default DefaultNewsRecencyWeight)
+	RecencyWeight float64 `json:"recency_weight,omitempty" jsonschema:"description:Share of recency versus the engine ranking between 0 and 1 (default 0.7)"`
+
+	// SourcePenalty multiplies the score of a story once for every story of
+	// the same source ranked above it; 1 disables source diversity (default
+	// DefaultNewsSourcePenalty)
+	SourcePenalty float64 `json:"source_penalty,omitempty" jsonschema:"description:Score factor per earlier story from the same source; 1 disables diversity (default 0.5)"`
+}
+
+// withDefaults returns the ranking with the defaults in place of zero and
+// out-of-range fields
+func (r NewsRanking) withDefaults() NewsRanking {
+	if r.HalfLifeHours <= 0 {
+		r.HalfLifeHours = DefaultNewsHalfLifeHours
+	}
+	if r.RecencyWeight <= 0 || r.RecencyWeight > 1 {
+		r.RecencyWeight = DefaultNewsRecencyWeight
+	}
+	if r.SourcePenalty <= 0 || r.SourcePenalty > 1 {
+		r.SourcePenalty = DefaultNewsSourcePenalty
+	}
+	return r
+}
+
+// RankNews orders news results by the ranking and returns them as a new
+// slice. The recency of a story halves every HalfLifeHours of its age at
+// now, using PublishedAt; undated stories have no recency. Stories are
+// picked by their score in turn, each time demoting the remaining stories of
+// the sources already picked, so one outlet does not dominate the top of the
+// list. The ranked results take over the positions of the input in order;
+// PagePosition and SourcePosition keep the engine's positions.
+func RankNews(results []NewsResult, ranking NewsRanking, now time.Time) []NewsResult {
+	ranking = ranking.withDefaults()
+	halfLife, weight, penalty := ranking.HalfLifeHours, ranking.RecencyWeight, ranking.SourcePenalty
+
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		relevance := 1 - float64(i)/float64(len(results))
+		var recency float64
+		if !r.PublishedAt.IsZero() {
+			age := max(now.Sub(r.PublishedAt).Hours(), 0)
+			recency = math.Pow(0.5, age/halfLife)
+		}
+		scores[i] = weight*recency + (1-weight)*relevance
+	}
+
+	ranked := make([]NewsResult, 0, len(results))
+	picked := make([]bool, len(results))
+	perSource := make(map[string]int)
+	for range results {
+		best, bestScore := -1, 0.0
+		for i, r := range results {
+			if picked[i] {
+				continue
+			}
+			score := scores[i] * math.Pow(penalty, float64(perSource[newsSourceKey(r)]))
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		perSource[newsSourceKey(results[best])]++
+		ranked = append(ranked, results[best])
+	}
+
+	for i := range ranked {
+		if ranked[i].SourcePosition == 0 {
+			ranked[i].SourcePosition = ranked[i].Position
+		}
+		ranked[i].Position = results[i].Position
+	}
+	return ranked
+}
+
+// newsSourceKey identifies the outlet of a news result by its source name,
+// or by the host of its link without one
+func newsSourceKey(r NewsResult) string {
+	if source := strings.ToLower(strings.TrimSpace(r.Source)); source != "" {
+		return source
+	}
+	host, _, _ := strings.Cut(CanonicalURL(r.Link), "/")
+	return host
+}
@@ -0,0 +1,115 @@
+package omniserp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := map[string]string{
+		"https://www.Example.com/story/":                     "example.com/story",
+		"http://example.com/story?utm_source=x&fbclid=y":     "example.com/story",
+		"https://amp.example.com/story/amp":                  "example.com/story",
+		"https://example.com/story?id=7&utm_medium=social#c": "example.com/story?id=7",
+	}
+	for link, want := range tests {
+		if got := CanonicalURL(link); got != want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	a := "Acme Corp announces record quarterly earnings - Reuters"
+	b := "Acme Corp Announces Record Quarterly Earnings | Yahoo Finance"
+	if got := TitleSimilarity(a, b); got != 1 {
+		t.Errorf("Expected identical headlines apart from the source, got %g", got)
+	}
+	if got := TitleSimilarity(a, "Weather forecast for the weekend"); got != 0 {
+		t.Errorf("Expected unrelated headlines to have no similarity, got %g", got)
+	}
+}
+
+func TestDedupNews(t *testing.T) {
+	results := []NewsResult{
+		{Position: 1, Title: "Acme Corp announces record quarterly earnings", Link: "https://reuters.com/acme", Source: "Reuters"},
+		{Position: 2, Title: "Rival unveils new product line", Link: "https://news.example/rival", Source: "Example News"},
+		{Position: 3, Title: "Acme Corp announces record quarterly earnings - Yahoo Finance", Link: "https://finance.yahoo.com/acme", Source: "Yahoo Finance"},
+		{Position: 4, Title: "Different headline, same article", Link: "https://www.reuters.com/acme/?utm_source=twitter", Source: "Reuters"},
+	}
+
+	stories := DedupNews(results, 0)
+	if len(stories) != 2 {
+		t.Fatalf("Expected 2 stories, got %d: %+v", len(stories), stories)
+	}
+	if stories[0].Position != 1 || len(stories[0].AlsoReportedBy) != 2 {
+		t.Errorf("Expected the top result with 2 copies, got %+v", stories[0])
+	}
+	if stories[0].AlsoReportedBy[0].Source != "Yahoo Finance" {
+		t.Errorf("Unexpected copy: %+v", stories[0].AlsoReportedBy[0])
+	}
+	if stories[1].Position != 2 || len(stories[1].AlsoReportedBy) != 0 {
+		t.Errorf("Expected the unrelated story alone, got %+v", stories[1])
+	}
+	if len(results[0].AlsoReportedBy) != 0 {
+		t.Error("Expected the input results to be unchanged")
+	}
+}
+
+func TestParseNewsDate(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"3 hours ago":                     now.Add(-3 * time.Hour),
+		"a day ago":                       now.AddDate(0, 0, -1),
+		"Yesterday":                       now.AddDate(0, 0, -1),
+		"Jan 2, 2026":                     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		"01/09/2026, 08:00 AM, +0000 UTC": time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC),
+		"2026-01-09T10:00:00+02:00":       time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC),
+		"Fri, 9 Jan 2026 08:00:00 +0000":  time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC),
+		"3小时前":                            {},
+		"":                                {},
+	}
+	for date, want := range tests {
+		if got := ParseNewsDate(date, now); !got.Equal(want) {
+			t.Errorf("ParseNewsDate(%q) = %v, want %v", date, got, want)
+		}
+	}
+}
+
+func TestRankNews(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	results := []NewsResult{
+		{Position: 11, Title: "Old", Source: "Reuters", PublishedAt: now.Add(-72 * time.Hour)},
+		{Position: 12, Title: "Latest", Source: "Reuters", PublishedAt: now.Add(-time.Hour)},
+		{Position: 13, Title: "Recent", Source: "reuters", PublishedAt: now.Add(-2 * time.Hour)},
+		{Position: 14, Title: "Other outlet", Source: "AP", PublishedAt: now.Add(-5 * time.Hour)},
+	}
+
+	titles := func(ranked []NewsResult) []string {
+		var out []string
+		for _, r := range ranked {
+			out = append(out, r.Title)
+		}
+		return out
+	}
+
+	ranked := RankNews(results, NewsRanking{}, now)
+	want := []string{"Latest", "Other outlet", "Recent", "Old"}
+	if got := titles(ranked); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if ranked[0].Position != 11 || ranked[0].SourcePosition != 12 || ranked[3].Position != 14 || ranked[3].SourcePosition != 11 {
+		t.Errorf("Expected the input positions in order, got %+v", ranked)
+	}
+	if results[0].Title != "Old" || results[0].SourcePosition != 0 {
+		t.Error("Expected the input results to be unchanged")
+	}
+
+	// Without diversity, recency and the engine's ranking decide
+	ranked = RankNews(results, NewsRanking{SourcePenalty: 1}, now)
+	want = []string{"Latest", "Recent", "Other outlet", "Old"}
+	if got := titles(ranked); !slices.Equal(got, want) {
+		t.Errorf("Expected %v without diversity, got %v", want, got)
+	}
+}
@@ -1,5 +1,7 @@
 package omniserp
 
+import "time"
+
 // NormalizedSearchResult represents a unified search result structure across all engines
 type NormalizedSearchResult struct {
 	// Organic search results
@@ -31,6 +33,10 @@ type NormalizedSearchResult struct {
 	// Scholar-specific (for SearchScholar)
 	ScholarResults []ScholarResult `json:"scholar_results,omitempty"`
 
+	// Reviews-specific (for SearchReviews), with statistics over the reviews
+	ReviewResults []ReviewResult `json:"review_results,omitempty"`
+	ReviewSummary *ReviewSummary `json:"review_summary,omitempty"`
+
 	// Autocomplete-specific (for SearchAutocomplete)
 	Suggestions []string `json:"suggestions,omitempty"`
 
@@ -43,13 +49,31 @@ type NormalizedSearchResult struct {
 
 // OrganicResult represents a standard web search result
 type OrganicResult struct {
-	Position int    `json:"position"`
-	Title    string `json:"title"`
-	Link     string `json:"link"`
-	URL      string `json:"url"` // Alias for Link
-	Snippet  string `json:"snippet"`
-	Domain   string `json:"domain,omitempty"`
-	Date     string `json:"date,omitempty"`
+	// Position within the result set: across pages when paginating (see
+	// OffsetPositions) and across sources when merging (see MergeResults).
+	// PagePosition is the position on the page that returned the item and
+	// SourcePosition the position within the item's engine results.
+	Position       int `json:"position"`
+	PagePosition   int `json:"page_position,omitempty"`
+	SourcePosition int `json:"source_position,omitempty"`
+
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	URL     string `json:"url"` // Alias for Link
+	Snippet string `json:"snippet"`
+	Domain  string `json:"domain,omitempty"`
+	Date    string `json:"date,omitempty"`
+
+	// RawContent is the text content of the page, for engines that extract
+	// it, such as Tavily and Exa
+	RawContent string `json:"raw_content,omitempty"`
+
+	// Annotations added by annotators (see Annotate)
+	Annotations *Annotations `json:"annotations,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // AnswerBox represents a featured answer at the top of results
@@ -89,14 +113,32 @@ type PeopleAlsoAsk struct {
 
 // NewsResult represents a news article result
 type NewsResult struct {
-	Position  int    `json:"position"`
+	// Positions, as in OrganicResult
+	Position       int `json:"position"`
+	PagePosition   int `json:"page_position,omitempty"`
+	SourcePosition int `json:"source_position,omitempty"`
+
 	Title     string `json:"title"`
 	Link      string `json:"link"`
 	Source    string `json:"source"`
-	Date      string `json:"date,omitempty"`
 	Snippet   string `json:"snippet,omitempty"`
 	ImageURL  string `json:"image_url,omitempty"`
 	Thumbnail string `json:"thumbnail,omitempty"`
+
+	// Date is the date as displayed, such as "3 hours ago"; PublishedAt is
+	// the parsed date (see ParseNewsDate), or zero if unknown
+	Date        string    `json:"date,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitzero"`
+
+	// AlsoReportedBy lists syndicated copies of the story (see DedupNews)
+	AlsoReportedBy []NewsMention `json:"also_reported_by,omitempty"`
+
+	// Annotations added by annotators (see Annotate)
+	Annotations *Annotations `json:"annotations,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // ImageResult represents an image search result
@@ -110,6 +152,10 @@ type ImageResult struct {
 	Width     int    `json:"width,omitempty"`
 	Height    int    `json:"height,omitempty"`
 	IsProduct bool   `json:"is_product,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // VideoResult represents a video search result
@@ -124,6 +170,10 @@ type VideoResult struct {
 	Views     string `json:"views,omitempty"`
 	Thumbnail string `json:"thumbnail,omitempty"`
 	Snippet   string `json:"snippet,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // PlaceResult represents a local business or place result
@@ -144,6 +194,17 @@ type PlaceResult struct {
 	Longitude  float64           `json:"longitude,omitempty"`
 	Thumbnail  string            `json:"thumbnail,omitempty"`
 	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// OpeningHours is the weekly schedule parsed from Hours, or nil if
+	// Hours is not a weekly schedule (see ParseHours)
+	OpeningHours *OpeningHours `json:"opening_hours,omitempty"`
+
+	// DistanceKM is the distance from the origin given to PlacesNear
+	DistanceKM float64 `json:"distance_km,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // ShoppingResult represents a shopping/product result
@@ -162,11 +223,19 @@ type ShoppingResult struct {
 	Thumbnail     string   `json:"thumbnail,omitempty"`
 	Images        []string `json:"images,omitempty"`
 	InStock       bool     `json:"in_stock,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // ScholarResult represents a scholarly article result
 type ScholarResult struct {
-	Position       int      `json:"position"`
+	// Positions, as in OrganicResult
+	Position       int `json:"position"`
+	PagePosition   int `json:"page_position,omitempty"`
+	SourcePosition int `json:"source_position,omitempty"`
+
 	Title          string   `json:"title"`
 	Link           string   `json:"link"`
 	PublicationURL string   `json:"publication_url,omitempty"`
@@ -176,15 +245,58 @@ type ScholarResult struct {
 	Citations      int      `json:"citations,omitempty"`
 	Snippet        string   `json:"snippet,omitempty"`
 	PDF            string   `json:"pdf,omitempty"`
+
+	// CitesID identifies the paper in SearchParams.Cites to search for the
+	// papers citing it
+	CitesID string `json:"cites_id,omitempty"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
 
 // SearchMetadata contains metadata about the search itself
 type SearchMetadata struct {
 	Engine       string  `json:"engine"` // "serper", "serpapi", etc.
 	Query        string  `json:"query"`
+	Fingerprint  string  `json:"fingerprint,omitempty"` // see SearchParams.Fingerprint
 	Location     string  `json:"location,omitempty"`
 	Language     string  `json:"language,omitempty"`
 	Country      string  `json:"country,omitempty"`
 	TotalResults int64   `json:"total_results,omitempty"`
 	TimeTaken    float64 `json:"time_taken,omitempty"` // seconds
+	Credits      int     `json:"credits,omitempty"`    // credits charged, if reported by the engine (see CreditsUsed)
+
+	// CorrectedQuery is the spelling the engine searched for instead of
+	// Query ("Showing results for"), and SuggestedQuery a spelling it
+	// suggested without applying it ("Did you mean"). Set
+	// SearchParams.Verbatim to disable the correction.
+	CorrectedQuery string `json:"corrected_query,omitempty"`
+	SuggestedQuery string `json:"suggested_query,omitempty"`
+}
+
+// NormalizedScrapeResult represents a scraped webpage. Engines return it as
+// the Data of a ScrapeWebpage result.
+type NormalizedScrapeResult struct {
+	URL        string `json:"url"`       // requested URL
+	FinalURL   string `json:"final_url"` // URL after redirects
+	StatusCode int    `json:"status_code,omitempty"`
+	Charset    string `json:"charset,omitempty"` // charset of the page before transcoding to UTF-8
+
+	// RedirectChain lists the requested URL, each redirect, and the final
+	// URL in order; it is empty if there were no redirects
+	RedirectChain []string `json:"redirect_chain,omitempty"`
+
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text"`
+	Markdown string            `json:"markdown,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"` // e.g. description, og:title
+	Links    []string          `json:"links,omitempty"`    // absolute outbound links
+
+	// ContentHash is "sha256:" followed by the hex digest of Text
+	ContentHash string `json:"content_hash"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
 }
@@ -2,17 +2,30 @@ package omniserp
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Normalizer converts engine-specific responses to normalized format
 type Normalizer struct {
 	engineName string
+
+	// format is the response format of the engine: its name without the
+	// variant suffix, as in "serpapi" for serpapi-bing
+	format string
 }
 
-// NewNormalizer creates a new normalizer for the specified engine
+// NewNormalizer creates a new normalizer for the specified engine. Variants
+// named "<engine>-<variant>", such as the SerpAPI engine families, are
+// normalized like their engine.
 func NewNormalizer(engineName string) *Normalizer {
-	return &Normalizer{engineName: strings.ToLower(engineName)}
+	engineName = strings.ToLower(engineName)
+	format, _, _ := strings.Cut(engineName, "-")
+	return &Normalizer{engineName: engineName, format: format}
 }
 
 // NormalizeSearch normalizes a web search result
@@ -20,6 +33,9 @@ func (n *Normalizer) NormalizeSearch(result *SearchResult, query string) (*Norma
 	if result == nil || result.Data == nil {
 		return nil, fmt.Errorf("nil result or data")
 	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
 
 	data, ok := result.Data.(map[string]any)
 	if !ok {
@@ -34,15 +50,22 @@ func (n *Normalizer) NormalizeSearch(result *SearchResult, query string) (*Norma
 		Raw: result,
 	}
 
-	switch n.engineName {
+	switch n.format {
 	case "serper":
 		n.normalizeSerperSearch(data, normalized)
-	case "serpapi":
+	case "serpapi", "searchapi":
 		n.normalizeSerpAPISearch(data, normalized)
+	case "googlecse":
+		n.normalizeGoogleCSESearch(data, normalized)
+	case "mojeek":
+		n.normalizeMojeekSearch(data, normalized)
+	case "valueserp", "scaleserp":
+		n.normalizeValueSerpSearch(data, normalized)
 	default:
 		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
 	}
 
+	n.stamp(normalized)
 	return normalized, nil
 }
 
@@ -51,6 +74,9 @@ func (n *Normalizer) NormalizeNews(result *SearchResult, query string) (*Normali
 	if result == nil || result.Data == nil {
 		return nil, fmt.Errorf("nil result or data")
 	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
 
 	data, ok := result.Data.(map[string]any)
 	if !ok {
@@ -65,15 +91,18 @@ func (n *Normalizer) NormalizeNews(result *SearchResult, query string) (*Normali
 		Raw: result,
 	}
 
-	switch n.engineName {
+	switch n.format {
 	case "serper":
 		n.normalizeSerperNews(data, normalized)
-	case "serpapi":
+	case "serpapi", "searchapi":
 		n.normalizeSerpAPINews(data, normalized)
+	case "valueserp", "scaleserp":
+		n.normalizeValueSerpNews(data, normalized)
 	default:
 		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
 	}
 
+	n.stamp(normalized)
 	return normalized, nil
 }
 
@@ -82,6 +111,9 @@ func (n *Normalizer) NormalizeImages(result *SearchResult, query string) (*Norma
 	if result == nil || result.Data == nil {
 		return nil, fmt.Errorf("nil result or data")
 	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
 
 	data, ok := result.Data.(map[string]any)
 	if !ok {
@@ -96,21 +128,258 @@ func (n *Normalizer) NormalizeImages(result *SearchResult, query string) (*Norma
 		Raw: result,
 	}
 
-	switch n.engineName {
+	switch n.format {
 	case "serper":
 		n.normalizeSerperImages(data, normalized)
 	case "serpapi":
 		n.normalizeSerpAPIImages(data, normalized)
+	case "searchapi":
+		n.normalizeSearchAPIImages(data, normalized)
+	case "googlecse":
+		n.normalizeGoogleCSEImages(data, normalized)
+	case "valueserp":
+		n.normalizeValueSerpImages(data, normalized)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
+	}
+
+	n.stamp(normalized)
+	return normalized, nil
+}
+
+// NormalizeShopping normalizes a shopping search result
+func (n *Normalizer) NormalizeShopping(result *SearchResult, query string) (*NormalizedSearchResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
+
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+
+	normalized := &NormalizedSearchResult{
+		SearchMetadata: SearchMetadata{
+			Engine: n.engineName,
+			Query:  query,
+		},
+		Raw: result,
+	}
+
+	switch n.format {
+	case "serper":
+		n.normalizeSerperShopping(data, normalized)
+	case "serpapi":
+		n.normalizeSerpAPIShopping(data, normalized)
+	case "searchapi":
+		n.normalizeSearchAPIShopping(data, normalized)
+	case "scaleserp":
+		n.normalizeScaleSerpShopping(data, normalized)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
+	}
+
+	n.stamp(normalized)
+	return normalized, nil
+}
+
+// NormalizePlaces normalizes a places or maps search result
+func (n *Normalizer) NormalizePlaces(result *SearchResult, query string) (*NormalizedSearchResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
+
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+
+	normalized := &NormalizedSearchResult{
+		SearchMetadata: SearchMetadata{
+			Engine: n.engineName,
+			Query:  query,
+		},
+		Raw: result,
+	}
+
+	switch n.format {
+	case "serper":
+		n.normalizeSerperPlaces(data, normalized)
+	case "serpapi", "searchapi":
+		n.normalizeSerpAPIPlaces(data, normalized)
+	case "valueserp", "scaleserp":
+		n.normalizeValueSerpPlaces(data, normalized)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
+	}
+
+	n.stamp(normalized)
+	return normalized, nil
+}
+
+// NormalizeReviews normalizes a reviews search result and summarizes the
+// ratings of its reviews
+func (n *Normalizer) NormalizeReviews(result *SearchResult, query string) (*NormalizedSearchResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
+
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+
+	normalized := &NormalizedSearchResult{
+		SearchMetadata: SearchMetadata{
+			Engine: n.engineName,
+			Query:  query,
+		},
+		Raw: result,
+	}
+
+	switch n.format {
+	case "serper", "serpapi", "searchapi":
+		n.normalizeReviews(data, normalized)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
+	}
+
+	n.stamp(normalized)
+	normalized.ReviewSummary = SummarizeReviews(normalized.ReviewResults)
+	return normalized, nil
+}
+
+// NormalizeScholar normalizes a scholar search result
+func (n *Normalizer) NormalizeScholar(result *SearchResult, query string) (*NormalizedSearchResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
+
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+
+	normalized := &NormalizedSearchResult{
+		SearchMetadata: SearchMetadata{
+			Engine: n.engineName,
+			Query:  query,
+		},
+		Raw: result,
+	}
+
+	switch n.format {
+	case "serper":
+		n.normalizeSerperScholar(data, normalized)
+	case "serpapi":
+		n.normalizeSerpAPIScholar(data, normalized)
+	case "searchapi":
+		n.normalizeSearchAPIScholar(data, normalized)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
+	}
+
+	n.stamp(normalized)
+	return normalized, nil
+}
+
+// NormalizeAutocomplete converts an autocomplete response to the normalized
+// format, with the suggestions in engine order
+func (n *Normalizer) NormalizeAutocomplete(result *SearchResult, query string) (*NormalizedSearchResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+	if normalized, ok := n.prenormalized(result, query); ok {
+		return normalized, nil
+	}
+
+	data, ok := result.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+
+	normalized := &NormalizedSearchResult{
+		SearchMetadata: SearchMetadata{
+			Engine: n.engineName,
+			Query:  query,
+		},
+		Raw: result,
+	}
+
+	switch n.format {
+	case "serper", "serpapi", "searchapi":
+		// These engines return {"suggestions": [{"value": "..."}]}
+		if suggestions, ok := data["suggestions"].([]any); ok {
+			for _, item := range suggestions {
+				if itemMap, ok := item.(map[string]any); ok {
+					if value := getString(itemMap, "value"); value != "" {
+						normalized.Suggestions = append(normalized.Suggestions, value)
+					}
+				}
+			}
+		}
 	default:
 		return nil, fmt.Errorf("unsupported engine: %s", n.engineName)
 	}
 
+	n.stamp(normalized)
 	return normalized, nil
 }
 
+// prenormalized returns the result of an engine that normalizes its own
+// responses by returning a *NormalizedSearchResult as the Data, like engines
+// return scraped pages as a *NormalizedScrapeResult. The engine name, query,
+// and raw response are filled in if the engine did not set them.
+func (n *Normalizer) prenormalized(result *SearchResult, query string) (*NormalizedSearchResult, bool) {
+	data, ok := result.Data.(*NormalizedSearchResult)
+	if !ok {
+		return nil, false
+	}
+	// The items are copied so stamping does not modify the engine's Data
+	normalized := *data
+	normalized.OrganicResults = slices.Clone(data.OrganicResults)
+	normalized.NewsResults = slices.Clone(data.NewsResults)
+	normalized.ImageResults = slices.Clone(data.ImageResults)
+	normalized.VideoResults = slices.Clone(data.VideoResults)
+	normalized.PlaceResults = slices.Clone(data.PlaceResults)
+	normalized.ShoppingResults = slices.Clone(data.ShoppingResults)
+	normalized.ScholarResults = slices.Clone(data.ScholarResults)
+	normalized.ReviewResults = slices.Clone(data.ReviewResults)
+	if normalized.SearchMetadata.Engine == "" {
+		normalized.SearchMetadata.Engine = n.engineName
+	}
+	if normalized.SearchMetadata.Query == "" {
+		normalized.SearchMetadata.Query = query
+	}
+	if normalized.Raw == nil {
+		// Without the Data, which would refer back to the result
+		normalized.Raw = &SearchResult{Raw: result.Raw, Response: result.Response}
+	}
+	n.stamp(&normalized)
+	return &normalized, true
+}
+
 // Helper functions for Serper normalization
 
 func (n *Normalizer) normalizeSerperSearch(data map[string]any, normalized *NormalizedSearchResult) {
+	// Extract spelling corrections
+	if info, ok := data["searchInformation"].(map[string]any); ok {
+		normalized.SearchMetadata.CorrectedQuery = getString(info, "showingResultsFor")
+		normalized.SearchMetadata.SuggestedQuery = getString(info, "didYouMean")
+	}
+
 	// Extract organic results
 	if organic, ok := data["organic"].([]any); ok {
 		for i, item := range organic {
@@ -213,6 +482,119 @@ func (n *Normalizer) normalizeSerperImages(data map[string]any, normalized *Norm
 					Thumbnail: getString(itemMap, "imageUrl"),
 					Source:    getString(itemMap, "source"),
 					SourceURL: getString(itemMap, "link"),
+					Width:     getInt(itemMap, "imageWidth"),
+					Height:    getInt(itemMap, "imageHeight"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSerperShopping(data map[string]any, normalized *NormalizedSearchResult) {
+	if shopping, ok := data["shopping"].([]any); ok {
+		for i, item := range shopping {
+			if itemMap, ok := item.(map[string]any); ok {
+				normalized.ShoppingResults = append(normalized.ShoppingResults, ShoppingResult{
+					Position:  i + 1,
+					Title:     getString(itemMap, "title"),
+					Link:      getString(itemMap, "link"),
+					ProductID: getString(itemMap, "productId"),
+					Price:     getString(itemMap, "price"),
+					Rating:    getFloat(itemMap, "rating"),
+					Reviews:   getInt(itemMap, "ratingCount"),
+					Source:    getString(itemMap, "source"),
+					Delivery:  getString(itemMap, "delivery"),
+					Thumbnail: getString(itemMap, "imageUrl"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSerperPlaces(data map[string]any, normalized *NormalizedSearchResult) {
+	if places, ok := data["places"].([]any); ok {
+		for i, item := range places {
+			if itemMap, ok := item.(map[string]any); ok {
+				placeID := getString(itemMap, "placeId")
+				if placeID == "" {
+					placeID = getString(itemMap, "cid")
+				}
+				placeType := getString(itemMap, "type")
+				if placeType == "" {
+					placeType = getString(itemMap, "category")
+				}
+				hours := weeklyHours(itemMap["openingHours"])
+				openingHours, _ := ParseHours(hours)
+				normalized.PlaceResults = append(normalized.PlaceResults, PlaceResult{
+					Position:     i + 1,
+					Title:        getString(itemMap, "title"),
+					PlaceID:      placeID,
+					Address:      getString(itemMap, "address"),
+					Phone:        getString(itemMap, "phoneNumber"),
+					Website:      getString(itemMap, "website"),
+					Rating:       getFloat(itemMap, "rating"),
+					Reviews:      getInt(itemMap, "ratingCount"),
+					Type:         placeType,
+					Hours:        hours,
+					OpeningHours: openingHours,
+					Price:        getString(itemMap, "priceLevel"),
+					Latitude:     getFloat(itemMap, "latitude"),
+					Longitude:    getFloat(itemMap, "longitude"),
+					Thumbnail:    getString(itemMap, "thumbnailUrl"),
+				})
+			}
+		}
+	}
+}
+
+// normalizeReviews normalizes the reviews of Serper and SerpAPI, which share
+// the Google Maps review format apart from the name of the ISO date
+func (n *Normalizer) normalizeReviews(data map[string]any, normalized *NormalizedSearchResult) {
+	now := time.Now().UTC()
+	if reviews, ok := data["reviews"].([]any); ok {
+		for i, item := range reviews {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			iso := getString(itemMap, "isoDate")
+			if iso == "" {
+				iso = getString(itemMap, "iso_date")
+			}
+			review := ReviewResult{
+				Position:    i + 1,
+				Rating:      getFloat(itemMap, "rating"),
+				Snippet:     getString(itemMap, "snippet"),
+				Likes:       getInt(itemMap, "likes"),
+				Date:        getString(itemMap, "date"),
+				PublishedAt: parseReviewDate(iso, getString(itemMap, "date"), now),
+			}
+			if user, ok := itemMap["user"].(map[string]any); ok {
+				review.Author = getString(user, "name")
+			}
+			normalized.ReviewResults = append(normalized.ReviewResults, review)
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSerperScholar(data map[string]any, normalized *NormalizedSearchResult) {
+	if organic, ok := data["organic"].([]any); ok {
+		for i, item := range organic {
+			if itemMap, ok := item.(map[string]any); ok {
+				authors, source, year := parsePublicationInfo(getString(itemMap, "publicationInfo"))
+				if y := getInt(itemMap, "year"); y > 0 {
+					year = fmt.Sprintf("%d", y)
+				}
+				normalized.ScholarResults = append(normalized.ScholarResults, ScholarResult{
+					Position:  i + 1,
+					Title:     getString(itemMap, "title"),
+					Link:      getString(itemMap, "link"),
+					Authors:   authors,
+					Year:      year,
+					Source:    source,
+					Citations: getInt(itemMap, "citedBy"),
+					Snippet:   getString(itemMap, "snippet"),
+					PDF:       getString(itemMap, "pdfUrl"),
 				})
 			}
 		}
@@ -222,6 +604,15 @@ func (n *Normalizer) normalizeSerperImages(data map[string]any, normalized *Norm
 // Helper functions for SerpAPI normalization
 
 func (n *Normalizer) normalizeSerpAPISearch(data map[string]any, normalized *NormalizedSearchResult) {
+	// Extract spelling corrections; older responses only have spelling_fix
+	if info, ok := data["search_information"].(map[string]any); ok {
+		normalized.SearchMetadata.CorrectedQuery = getString(info, "showing_results_for")
+		if normalized.SearchMetadata.CorrectedQuery == "" {
+			normalized.SearchMetadata.CorrectedQuery = getString(info, "spelling_fix")
+		}
+		normalized.SearchMetadata.SuggestedQuery = getString(info, "did_you_mean")
+	}
+
 	// Extract organic results
 	if organic, ok := data["organic_results"].([]any); ok {
 		for i, item := range organic {
@@ -296,7 +687,12 @@ func (n *Normalizer) normalizeSerpAPISearch(data map[string]any, normalized *Nor
 }
 
 func (n *Normalizer) normalizeSerpAPINews(data map[string]any, normalized *NormalizedSearchResult) {
-	if news, ok := data["news_results"].([]any); ok {
+	news, ok := data["news_results"].([]any)
+	if !ok {
+		// The Bing and Baidu news engines return news as organic results
+		news, ok = data["organic_results"].([]any)
+	}
+	if ok {
 		for i, item := range news {
 			if itemMap, ok := item.(map[string]any); ok {
 				normalized.NewsResults = append(normalized.NewsResults, NewsResult{
@@ -328,12 +724,659 @@ func (n *Normalizer) normalizeSerpAPIImages(data map[string]any, normalized *Nor
 					Thumbnail: getString(itemMap, "thumbnail"),
 					Source:    getString(itemMap, "source"),
 					SourceURL: getString(itemMap, "link"),
+					Width:     getInt(itemMap, "original_width"),
+					Height:    getInt(itemMap, "original_height"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSerpAPIShopping(data map[string]any, normalized *NormalizedSearchResult) {
+	if shopping, ok := data["shopping_results"].([]any); ok {
+		for i, item := range shopping {
+			if itemMap, ok := item.(map[string]any); ok {
+				link := getString(itemMap, "link")
+				if link == "" {
+					link = getString(itemMap, "product_link")
+				}
+				normalized.ShoppingResults = append(normalized.ShoppingResults, ShoppingResult{
+					Position:      i + 1,
+					Title:         getString(itemMap, "title"),
+					Link:          link,
+					ProductID:     getString(itemMap, "product_id"),
+					Price:         getString(itemMap, "price"),
+					OriginalPrice: getString(itemMap, "old_price"),
+					Rating:        getFloat(itemMap, "rating"),
+					Reviews:       getInt(itemMap, "reviews"),
+					Source:        getString(itemMap, "source"),
+					Delivery:      getString(itemMap, "delivery"),
+					Thumbnail:     getString(itemMap, "thumbnail"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSerpAPIPlaces(data map[string]any, normalized *NormalizedSearchResult) {
+	places, _ := data["local_results"].([]any)
+	if place, ok := data["place_results"].(map[string]any); ok && len(places) == 0 {
+		// A query that matches one place returns its details instead
+		places = []any{place}
+	}
+	for i, item := range places {
+		itemMap, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		place := PlaceResult{
+			Position:  i + 1,
+			Title:     getString(itemMap, "title"),
+			PlaceID:   getString(itemMap, "place_id"),
+			DataID:    getString(itemMap, "data_id"),
+			Address:   getString(itemMap, "address"),
+			Phone:     getString(itemMap, "phone"),
+			Website:   getString(itemMap, "website"),
+			Rating:    getFloat(itemMap, "rating"),
+			Reviews:   getInt(itemMap, "reviews"),
+			Type:      getString(itemMap, "type"),
+			Hours:     getString(itemMap, "hours"),
+			Price:     getString(itemMap, "price"),
+			Thumbnail: getString(itemMap, "thumbnail"),
+		}
+		if gps, ok := itemMap["gps_coordinates"].(map[string]any); ok {
+			place.Latitude = getFloat(gps, "latitude")
+			place.Longitude = getFloat(gps, "longitude")
+		}
+		// The weekly schedule is preferred over the current status, such as
+		// "Open ⋅ Closes 6 PM"
+		if weekly := weeklyHours(itemMap["operating_hours"]); weekly != "" {
+			place.Hours = weekly
+		}
+		place.OpeningHours, _ = ParseHours(place.Hours)
+		normalized.PlaceResults = append(normalized.PlaceResults, place)
+	}
+}
+
+func (n *Normalizer) normalizeSerpAPIScholar(data map[string]any, normalized *NormalizedSearchResult) {
+	if organic, ok := data["organic_results"].([]any); ok {
+		for i, item := range organic {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			scholar := ScholarResult{
+				Position: i + 1,
+				Title:    getString(itemMap, "title"),
+				Link:     getString(itemMap, "link"),
+				Snippet:  getString(itemMap, "snippet"),
+			}
+
+			if pubInfo, ok := itemMap["publication_info"].(map[string]any); ok {
+				scholar.Authors, scholar.Source, scholar.Year = parsePublicationInfo(getString(pubInfo, "summary"))
+				if authors, ok := pubInfo["authors"].([]any); ok && len(authors) > 0 {
+					scholar.Authors = nil
+					for _, author := range authors {
+						if authorMap, ok := author.(map[string]any); ok {
+							scholar.Authors = append(scholar.Authors, getString(authorMap, "name"))
+						}
+					}
+				}
+			}
+
+			if links, ok := itemMap["inline_links"].(map[string]any); ok {
+				if citedBy, ok := links["cited_by"].(map[string]any); ok {
+					scholar.Citations = getInt(citedBy, "total")
+					scholar.CitesID = getString(citedBy, "cites_id")
+				}
+			}
+
+			if resources, ok := itemMap["resources"].([]any); ok {
+				for _, resource := range resources {
+					if resourceMap, ok := resource.(map[string]any); ok && strings.EqualFold(getString(resourceMap, "file_format"), "PDF") {
+						scholar.PDF = getString(resourceMap, "link")
+						break
+					}
+				}
+			}
+
+			normalized.ScholarResults = append(normalized.ScholarResults, scholar)
+		}
+	}
+}
+
+// yearPattern matches a publication year in a scholar summary
+var yearPattern = regexp.MustCompile(`\b(1[89]|20)\d{2}\b`)
+
+// Helper functions for SearchAPI.io normalization. Its web, news, places,
+// reviews, and autocomplete results have SerpAPI's format.
+
+func (n *Normalizer) normalizeSearchAPIImages(data map[string]any, normalized *NormalizedSearchResult) {
+	if images, ok := data["images"].([]any); ok {
+		for i, item := range images {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			image := ImageResult{
+				Position:  i + 1,
+				Title:     getString(itemMap, "title"),
+				Thumbnail: getString(itemMap, "thumbnail"),
+			}
+			if original, ok := itemMap["original"].(map[string]any); ok {
+				image.ImageURL = getString(original, "link")
+				image.Width = getInt(original, "width")
+				image.Height = getInt(original, "height")
+			}
+			if source, ok := itemMap["source"].(map[string]any); ok {
+				image.Source = getString(source, "name")
+				image.SourceURL = getString(source, "link")
+			}
+			normalized.ImageResults = append(normalized.ImageResults, image)
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSearchAPIShopping(data map[string]any, normalized *NormalizedSearchResult) {
+	if shopping, ok := data["shopping_results"].([]any); ok {
+		for i, item := range shopping {
+			if itemMap, ok := item.(map[string]any); ok {
+				link := getString(itemMap, "link")
+				if link == "" {
+					link = getString(itemMap, "product_link")
+				}
+				normalized.ShoppingResults = append(normalized.ShoppingResults, ShoppingResult{
+					Position:      i + 1,
+					Title:         getString(itemMap, "title"),
+					Link:          link,
+					ProductID:     getString(itemMap, "product_id"),
+					Price:         getString(itemMap, "price"),
+					OriginalPrice: getString(itemMap, "original_price"),
+					Rating:        getFloat(itemMap, "rating"),
+					Reviews:       getInt(itemMap, "reviews"),
+					Source:        getString(itemMap, "seller"),
+					Delivery:      getString(itemMap, "delivery"),
+					Thumbnail:     getString(itemMap, "thumbnail"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeSearchAPIScholar(data map[string]any, normalized *NormalizedSearchResult) {
+	if organic, ok := data["organic_results"].([]any); ok {
+		for i, item := range organic {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			scholar := ScholarResult{
+				Position: i + 1,
+				Title:    getString(itemMap, "title"),
+				Link:     getString(itemMap, "link"),
+				Snippet:  getString(itemMap, "snippet"),
+			}
+			scholar.Authors, scholar.Source, scholar.Year = parsePublicationInfo(getString(itemMap, "publication"))
+			if authors, ok := itemMap["authors"].([]any); ok && len(authors) > 0 {
+				scholar.Authors = nil
+				for _, author := range authors {
+					if authorMap, ok := author.(map[string]any); ok {
+						scholar.Authors = append(scholar.Authors, getString(authorMap, "name"))
+					}
+				}
+			}
+
+			if links, ok := itemMap["inline_links"].(map[string]any); ok {
+				if citedBy, ok := links["cited_by"].(map[string]any); ok {
+					scholar.Citations = getInt(citedBy, "total")
+					scholar.CitesID = getString(citedBy, "cites_id")
+				}
+			}
+
+			if resource, ok := itemMap["resource"].(map[string]any); ok && strings.EqualFold(getString(resource, "format"), "PDF") {
+				scholar.PDF = getString(resource, "link")
+			}
+
+			normalized.ScholarResults = append(normalized.ScholarResults, scholar)
+		}
+	}
+}
+
+// Helper functions for Google Custom Search normalization
+
+func (n *Normalizer) normalizeGoogleCSESearch(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeGoogleCSEMetadata(data, normalized)
+	if items, ok := data["items"].([]any); ok {
+		for i, item := range items {
+			if itemMap, ok := item.(map[string]any); ok {
+				normalized.OrganicResults = append(normalized.OrganicResults, OrganicResult{
+					Position: i + 1,
+					Title:    getString(itemMap, "title"),
+					Link:     getString(itemMap, "link"),
+					URL:      getString(itemMap, "link"),
+					Snippet:  strings.TrimSpace(getString(itemMap, "snippet")),
+					Domain:   getString(itemMap, "displayLink"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeGoogleCSEImages(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeGoogleCSEMetadata(data, normalized)
+	if items, ok := data["items"].([]any); ok {
+		for i, item := range items {
+			if itemMap, ok := item.(map[string]any); ok {
+				image, _ := itemMap["image"].(map[string]any)
+				normalized.ImageResults = append(normalized.ImageResults, ImageResult{
+					Position:  i + 1,
+					Title:     getString(itemMap, "title"),
+					ImageURL:  getString(itemMap, "link"),
+					Thumbnail: getString(image, "thumbnailLink"),
+					Source:    getString(itemMap, "displayLink"),
+					SourceURL: getString(image, "contextLink"),
+					Width:     getInt(image, "width"),
+					Height:    getInt(image, "height"),
+				})
+			}
+		}
+	}
+}
+
+// normalizeGoogleCSEMetadata extracts the total and the spelling suggestion,
+// which the API does not apply to the query
+func (n *Normalizer) normalizeGoogleCSEMetadata(data map[string]any, normalized *NormalizedSearchResult) {
+	if info, ok := data["searchInformation"].(map[string]any); ok {
+		if total, err := strconv.ParseInt(getString(info, "totalResults"), 10, 64); err == nil {
+			normalized.SearchMetadata.TotalResults = total
+		}
+		normalized.SearchMetadata.TimeTaken = getFloat(info, "searchTime")
+	}
+	if spelling, ok := data["spelling"].(map[string]any); ok {
+		normalized.SearchMetadata.SuggestedQuery = getString(spelling, "correctedQuery")
+	}
+}
+
+// Helper functions for Mojeek normalization
+
+func (n *Normalizer) normalizeMojeekSearch(data map[string]any, normalized *NormalizedSearchResult) {
+	resp, ok := data["response"].(map[string]any)
+	if !ok {
+		return
+	}
+	if head, ok := resp["head"].(map[string]any); ok {
+		normalized.SearchMetadata.TotalResults = int64(getInt(head, "total"))
+		normalized.SearchMetadata.TimeTaken = getFloat(head, "timer")
+	}
+	if results, ok := resp["results"].([]any); ok {
+		for i, result := range results {
+			if resultMap, ok := result.(map[string]any); ok {
+				link := getString(resultMap, "url")
+				organic := OrganicResult{
+					Position: i + 1,
+					Title:    getString(resultMap, "title"),
+					Link:     link,
+					URL:      link,
+					Snippet:  getString(resultMap, "desc"),
+				}
+				if u, err := url.Parse(link); err == nil {
+					organic.Domain = strings.TrimPrefix(u.Hostname(), "www.")
+				}
+				normalized.OrganicResults = append(normalized.OrganicResults, organic)
+			}
+		}
+	}
+}
+
+// Helper functions for ValueSerp normalization, which also normalize
+// ScaleSERP results of the same format
+
+func (n *Normalizer) normalizeValueSerpSearch(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeValueSerpMetadata(data, normalized)
+
+	if organic, ok := data["organic_results"].([]any); ok {
+		for i, item := range organic {
+			if itemMap, ok := item.(map[string]any); ok {
+				normalized.OrganicResults = append(normalized.OrganicResults, OrganicResult{
+					Position: i + 1,
+					Title:    getString(itemMap, "title"),
+					Link:     getString(itemMap, "link"),
+					URL:      getString(itemMap, "link"),
+					Snippet:  getString(itemMap, "snippet"),
+					Domain:   strings.TrimPrefix(getString(itemMap, "domain"), "www."),
+					Date:     getString(itemMap, "date"),
+				})
+			}
+		}
+	}
+
+	// The answer box holds a list of answers, of which the first is kept
+	if answerBox, ok := data["answer_box"].(map[string]any); ok {
+		if answers, ok := answerBox["answers"].([]any); ok && len(answers) > 0 {
+			if answer, ok := answers[0].(map[string]any); ok {
+				box := &AnswerBox{
+					Type:   getString(answerBox, "answer_box_type"),
+					Answer: getString(answer, "answer"),
+				}
+				if source, ok := answer["source"].(map[string]any); ok {
+					box.Title = getString(source, "title")
+					box.Link = getString(source, "link")
+					box.Source = getString(source, "domain")
+				}
+				normalized.AnswerBox = box
+			}
+		}
+	}
+
+	if kg, ok := data["knowledge_graph"].(map[string]any); ok {
+		normalized.KnowledgeGraph = &KnowledgeGraph{
+			Title:       getString(kg, "title"),
+			Type:        getString(kg, "type"),
+			Description: getString(kg, "description"),
+			ImageURL:    getString(kg, "image"),
+		}
+		if source, ok := kg["source"].(map[string]any); ok {
+			normalized.KnowledgeGraph.Source = getString(source, "name")
+		}
+	}
+
+	if related, ok := data["related_searches"].([]any); ok {
+		for _, item := range related {
+			if itemMap, ok := item.(map[string]any); ok {
+				normalized.RelatedSearches = append(normalized.RelatedSearches, RelatedSearch{
+					Query: getString(itemMap, "query"),
+					Link:  getString(itemMap, "link"),
+				})
+			}
+		}
+	}
+
+	if paa, ok := data["related_questions"].([]any); ok {
+		for _, item := range paa {
+			if itemMap, ok := item.(map[string]any); ok {
+				question := PeopleAlsoAsk{
+					Question: getString(itemMap, "question"),
+					Answer:   getString(itemMap, "answer"),
+				}
+				if source, ok := itemMap["source"].(map[string]any); ok {
+					question.Title = getString(source, "title")
+					question.Link = getString(source, "link")
+					question.Source = getString(source, "domain")
+				}
+				normalized.PeopleAlsoAsk = append(normalized.PeopleAlsoAsk, question)
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeValueSerpNews(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeValueSerpMetadata(data, normalized)
+
+	if news, ok := data["news_results"].([]any); ok {
+		for i, item := range news {
+			if itemMap, ok := item.(map[string]any); ok {
+				source := getString(itemMap, "source")
+				if source == "" {
+					source = getString(itemMap, "domain")
+				}
+				normalized.NewsResults = append(normalized.NewsResults, NewsResult{
+					Position:  i + 1,
+					Title:     getString(itemMap, "title"),
+					Link:      getString(itemMap, "link"),
+					Source:    source,
+					Date:      getString(itemMap, "date"),
+					Snippet:   getString(itemMap, "snippet"),
+					Thumbnail: getString(itemMap, "thumbnail"),
+				})
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeValueSerpImages(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeValueSerpMetadata(data, normalized)
+
+	if images, ok := data["image_results"].([]any); ok {
+		for i, item := range images {
+			if itemMap, ok := item.(map[string]any); ok {
+				normalized.ImageResults = append(normalized.ImageResults, ImageResult{
+					Position:  i + 1,
+					Title:     getString(itemMap, "title"),
+					ImageURL:  getString(itemMap, "image"),
+					Source:    getString(itemMap, "domain"),
+					SourceURL: getString(itemMap, "link"),
+					Width:     getInt(itemMap, "width"),
+					Height:    getInt(itemMap, "height"),
 				})
 			}
 		}
 	}
 }
 
+func (n *Normalizer) normalizeValueSerpPlaces(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeValueSerpMetadata(data, normalized)
+
+	if places, ok := data["places_results"].([]any); ok {
+		for i, item := range places {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			place := PlaceResult{
+				Position:  i + 1,
+				Title:     getString(itemMap, "title"),
+				DataID:    getString(itemMap, "data_cid"),
+				Address:   getString(itemMap, "address"),
+				Phone:     getString(itemMap, "phone"),
+				Website:   getString(itemMap, "link"),
+				Rating:    getFloat(itemMap, "rating"),
+				Reviews:   getInt(itemMap, "reviews"),
+				Type:      getString(itemMap, "category"),
+				Hours:     getString(itemMap, "hours"),
+				Thumbnail: getString(itemMap, "image"),
+			}
+			if gps, ok := itemMap["gps_coordinates"].(map[string]any); ok {
+				place.Latitude = getFloat(gps, "latitude")
+				place.Longitude = getFloat(gps, "longitude")
+			}
+			place.OpeningHours, _ = ParseHours(place.Hours)
+			normalized.PlaceResults = append(normalized.PlaceResults, place)
+		}
+	}
+}
+
+func (n *Normalizer) normalizeScaleSerpShopping(data map[string]any, normalized *NormalizedSearchResult) {
+	n.normalizeValueSerpMetadata(data, normalized)
+
+	if shopping, ok := data["shopping_results"].([]any); ok {
+		for i, item := range shopping {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			result := ShoppingResult{
+				Position:  i + 1,
+				Title:     getString(itemMap, "title"),
+				Link:      getString(itemMap, "link"),
+				ProductID: getString(itemMap, "id"),
+				Price:     getString(itemMap, "price"),
+				Rating:    getFloat(itemMap, "rating"),
+				Reviews:   getInt(itemMap, "reviews"),
+				Source:    getString(itemMap, "merchant"),
+				Delivery:  getString(itemMap, "delivery"),
+				Thumbnail: getString(itemMap, "image"),
+			}
+			if parsed, ok := itemMap["price_parsed"].(map[string]any); ok {
+				if result.Price == "" {
+					result.Price = getString(parsed, "raw")
+				}
+				result.Currency = getString(parsed, "currency")
+			}
+			normalized.ShoppingResults = append(normalized.ShoppingResults, result)
+		}
+	}
+}
+
+// normalizeValueSerpMetadata extracts the search parameters, total results,
+// and spelling corrections of any search type
+func (n *Normalizer) normalizeValueSerpMetadata(data map[string]any, normalized *NormalizedSearchResult) {
+	if info, ok := data["search_information"].(map[string]any); ok {
+		normalized.SearchMetadata.TotalResults = int64(getInt(info, "total_results"))
+		normalized.SearchMetadata.CorrectedQuery = getString(info, "showing_results_for")
+		if normalized.SearchMetadata.CorrectedQuery == "" {
+			normalized.SearchMetadata.CorrectedQuery = getString(info, "spelling_fix")
+		}
+		normalized.SearchMetadata.SuggestedQuery = getString(info, "did_you_mean")
+	}
+	if searchParams, ok := data["search_parameters"].(map[string]any); ok {
+		normalized.SearchMetadata.Location = getString(searchParams, "location")
+		normalized.SearchMetadata.Language = getString(searchParams, "hl")
+		normalized.SearchMetadata.Country = getString(searchParams, "gl")
+	}
+	if meta, ok := data["search_metadata"].(map[string]any); ok {
+		normalized.SearchMetadata.TimeTaken = getFloat(meta, "total_time_taken")
+	}
+}
+
+// stamp records the engine and fetch time on every result item so they are
+// retained when items from several engines are merged, cached, or exported,
+// sets the page and source positions of ranked items, and records the
+// credits reported by the engine. Items that already have an engine or
+// fetch time keep it. The fetch time is that of the HTTP response, so
+// results replayed from a cache or recording keep their original time, or
+// the current time if unknown; relative news dates are resolved against it.
+func (n *Normalizer) stamp(normalized *NormalizedSearchResult) {
+	fetchedAt := time.Now().UTC()
+	if raw := normalized.Raw; raw != nil && raw.Response != nil && !raw.Response.FetchedAt.IsZero() {
+		fetchedAt = raw.Response.FetchedAt
+	}
+	provenance := func(engine *string, at *time.Time) {
+		if *engine == "" {
+			*engine = n.engineName
+		}
+		if at.IsZero() {
+			*at = fetchedAt
+		}
+	}
+
+	for i := range normalized.OrganicResults {
+		item := &normalized.OrganicResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.NewsResults {
+		item := &normalized.NewsResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+		if item.PublishedAt.IsZero() {
+			item.PublishedAt = ParseNewsDate(item.Date, fetchedAt)
+		}
+	}
+	for i := range normalized.ImageResults {
+		item := &normalized.ImageResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.VideoResults {
+		item := &normalized.VideoResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.PlaceResults {
+		item := &normalized.PlaceResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.ShoppingResults {
+		item := &normalized.ShoppingResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.ScholarResults {
+		item := &normalized.ScholarResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	for i := range normalized.ReviewResults {
+		item := &normalized.ReviewResults[i]
+		provenance(&item.Engine, &item.FetchedAt)
+	}
+	normalized.OffsetPositions(0)
+	if credits, ok := CreditsUsed(normalized.Raw); ok {
+		normalized.SearchMetadata.Credits = credits
+	}
+}
+
+// parsePublicationInfo splits a Google Scholar summary such as
+// "A Author, B Author - Journal Name, 2020 - publisher.com" into its parts
+func parsePublicationInfo(summary string) (authors []string, source, year string) {
+	parts := strings.Split(summary, " - ")
+	if len(parts) == 0 || strings.TrimSpace(parts[0]) == "" {
+		return nil, "", ""
+	}
+
+	for _, author := range strings.Split(parts[0], ",") {
+		author = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(author), "…"))
+		if author != "" {
+			authors = append(authors, author)
+		}
+	}
+
+	if len(parts) > 1 {
+		venue := parts[1]
+		year = yearPattern.FindString(venue)
+		if year != "" {
+			venue = strings.Replace(venue, year, "", 1)
+		}
+		source = strings.Trim(strings.TrimSpace(venue), ", …")
+	}
+
+	return authors, source, year
+}
+
+// Helper function to safely extract integer values from JSON-decoded maps
+func getInt(m map[string]any, key string) int {
+	switch val := m[key].(type) {
+	case float64:
+		return int(val)
+	case int:
+		return val
+	case string:
+		i, _ := strconv.Atoi(strings.ReplaceAll(val, ",", ""))
+		return i
+	}
+	return 0
+}
+
+// weeklyHours formats a map of day names to opening hours, such as
+// {"monday": "9 AM–5 PM"}, as "Monday: 9 AM–5 PM; ..." starting on Monday.
+// It returns "" if hours is not such a map.
+func weeklyHours(hours any) string {
+	days, ok := hours.(map[string]any)
+	if !ok {
+		return ""
+	}
+	var entries []string
+	for i := range 7 {
+		day := time.Weekday((i + 1) % 7)
+		for name, value := range days {
+			if text, ok := value.(string); ok && strings.EqualFold(name, day.String()) {
+				entries = append(entries, day.String()+": "+text)
+			}
+		}
+	}
+	return strings.Join(entries, "; ")
+}
+
+// Helper function to safely extract float values from JSON-decoded maps
+func getFloat(m map[string]any, key string) float64 {
+	switch val := m[key].(type) {
+	case float64:
+		return val
+	case int:
+		return float64(val)
+	case string:
+		f, _ := strconv.ParseFloat(strings.ReplaceAll(val, ",", ""), 64)
+		return f
+	}
+	return 0
+}
+
 // Helper function to safely extract string values from maps
 func getString(m map[string]any, key string) string {
 	if val, ok := m[key]; ok {
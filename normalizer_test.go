@@ -2,6 +2,7 @@ package omniserp
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNormalizeSerperSearch(t *testing.T) {
@@ -56,6 +57,13 @@ func TestNormalizeSerperSearch(t *testing.T) {
 		t.Errorf("Expected link 'https://golang.org', got '%s'", normalized.OrganicResults[0].Link)
 	}
 
+	// Verify provenance
+	for _, r := range normalized.OrganicResults {
+		if r.Engine != "serper" || r.FetchedAt.IsZero() {
+			t.Errorf("Expected engine and fetch time on %q, got %q %v", r.Title, r.Engine, r.FetchedAt)
+		}
+	}
+
 	// Verify answer box
 	if normalized.AnswerBox == nil {
 		t.Fatal("Expected answer box to be present")
@@ -177,15 +185,47 @@ func TestNormalizeNews(t *testing.T) {
 	}
 }
 
+func TestNormalizeEngineVariant(t *testing.T) {
+	// Bing news through SerpAPI returns the articles as organic results
+	bingNews := map[string]any{
+		"organic_results": []any{
+			map[string]any{
+				"title":   "Go 1.26 Released",
+				"link":    "https://go.dev/blog/go1.26",
+				"source":  "The Go Blog",
+				"date":    "2 days ago",
+				"snippet": "The Go team is happy to announce...",
+			},
+		},
+	}
+
+	normalized, err := NewNormalizer("serpapi-bing").NormalizeNews(&SearchResult{Data: bingNews}, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeNews failed: %v", err)
+	}
+	if len(normalized.NewsResults) != 1 || normalized.NewsResults[0].Source != "The Go Blog" {
+		t.Fatalf("Expected 1 news result from The Go Blog, got %+v", normalized.NewsResults)
+	}
+	if normalized.SearchMetadata.Engine != "serpapi-bing" || normalized.NewsResults[0].Engine != "serpapi-bing" {
+		t.Errorf("Expected the variant as the engine, got %q and %q", normalized.SearchMetadata.Engine, normalized.NewsResults[0].Engine)
+	}
+
+	if _, err := NewNormalizer("unknown-variant").NormalizeSearch(&SearchResult{Data: bingNews}, "golang"); err == nil {
+		t.Error("Expected an error for a variant of an unknown engine")
+	}
+}
+
 func TestNormalizeImages(t *testing.T) {
 	// Mock Serper images response
 	serperImages := map[string]any{
 		"images": []any{
 			map[string]any{
-				"title":    "Gopher Mascot",
-				"imageUrl": "https://example.com/gopher.png",
-				"link":     "https://golang.org",
-				"source":   "golang.org",
+				"title":       "Gopher Mascot",
+				"imageUrl":    "https://example.com/gopher.png",
+				"link":        "https://golang.org",
+				"source":      "golang.org",
+				"imageWidth":  float64(1200),
+				"imageHeight": float64(800),
 			},
 		},
 	}
@@ -212,6 +252,175 @@ func TestNormalizeImages(t *testing.T) {
 	if normalized.ImageResults[0].ImageURL != "https://example.com/gopher.png" {
 		t.Errorf("Expected imageUrl 'https://example.com/gopher.png', got '%s'", normalized.ImageResults[0].ImageURL)
 	}
+
+	if normalized.ImageResults[0].Width != 1200 || normalized.ImageResults[0].Height != 800 {
+		t.Errorf("Expected 1200x800, got %dx%d", normalized.ImageResults[0].Width, normalized.ImageResults[0].Height)
+	}
+
+	serpAPIImages := map[string]any{
+		"images_results": []any{
+			map[string]any{
+				"title":           "Gopher Mascot",
+				"original":        "https://example.com/gopher.png",
+				"original_width":  float64(640),
+				"original_height": float64(640),
+			},
+		},
+	}
+	normalized, err = NewNormalizer("serpapi").NormalizeImages(&SearchResult{Data: serpAPIImages}, "golang gopher")
+	if err != nil {
+		t.Fatalf("NormalizeImages failed: %v", err)
+	}
+	if got := normalized.ImageResults[0]; got.Width != 640 || got.Height != 640 {
+		t.Errorf("Expected 640x640, got %dx%d", got.Width, got.Height)
+	}
+}
+
+func TestNormalizeShopping(t *testing.T) {
+	serper := map[string]any{
+		"shopping": []any{
+			map[string]any{
+				"title":       "Gopher Plush",
+				"source":      "Gopher Store",
+				"link":        "https://shop.example/plush",
+				"price":       "$19.99",
+				"imageUrl":    "https://shop.example/plush.jpg",
+				"rating":      4.5,
+				"ratingCount": float64(120),
+				"productId":   "123",
+			},
+		},
+	}
+	normalized, err := NewNormalizer("serper").NormalizeShopping(&SearchResult{Data: serper}, "gopher plush")
+	if err != nil {
+		t.Fatalf("NormalizeShopping failed: %v", err)
+	}
+	if len(normalized.ShoppingResults) != 1 {
+		t.Fatalf("Expected 1 shopping result, got %d", len(normalized.ShoppingResults))
+	}
+	got := normalized.ShoppingResults[0]
+	if got.ProductID != "123" || got.Price != "$19.99" || got.Rating != 4.5 || got.Reviews != 120 || got.Engine != "serper" {
+		t.Errorf("Unexpected serper result: %+v", got)
+	}
+
+	serpAPI := map[string]any{
+		"shopping_results": []any{
+			map[string]any{
+				"position":     float64(1),
+				"title":        "Gopher Plush",
+				"product_link": "https://shop.example/plush",
+				"product_id":   "123",
+				"price":        "$17.99",
+				"old_price":    "$19.99",
+				"reviews":      "1,024",
+				"thumbnail":    "https://shop.example/plush.jpg",
+			},
+		},
+	}
+	normalized, err = NewNormalizer("serpapi").NormalizeShopping(&SearchResult{Data: serpAPI}, "gopher plush")
+	if err != nil {
+		t.Fatalf("NormalizeShopping failed: %v", err)
+	}
+	got = normalized.ShoppingResults[0]
+	if got.Link != "https://shop.example/plush" || got.OriginalPrice != "$19.99" || got.Reviews != 1024 {
+		t.Errorf("Unexpected serpapi result: %+v", got)
+	}
+}
+
+func TestNormalizePlaces(t *testing.T) {
+	serper := map[string]any{
+		"places": []any{
+			map[string]any{
+				"title":       "Gopher Coffee",
+				"address":     "1 Main St, Austin, TX",
+				"latitude":    30.2672,
+				"longitude":   -97.7431,
+				"rating":      4.6,
+				"ratingCount": float64(321),
+				"category":    "Coffee shop",
+				"phoneNumber": "(512) 555-0100",
+				"cid":         "987",
+				"openingHours": map[string]any{
+					"Sunday": "Closed",
+					"Monday": "7 AM–6 PM",
+				},
+			},
+		},
+	}
+	normalized, err := NewNormalizer("serper").NormalizePlaces(&SearchResult{Data: serper}, "coffee")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	if len(normalized.PlaceResults) != 1 {
+		t.Fatalf("Expected 1 place, got %d", len(normalized.PlaceResults))
+	}
+	got := normalized.PlaceResults[0]
+	if got.PlaceID != "987" || got.Type != "Coffee shop" || got.Reviews != 321 || got.Latitude != 30.2672 || got.Longitude != -97.7431 {
+		t.Errorf("Unexpected serper place: %+v", got)
+	}
+	if got.Hours != "Monday: 7 AM–6 PM; Sunday: Closed" || len(got.OpeningHours.Days[time.Monday]) != 1 {
+		t.Errorf("Unexpected serper hours: %q, %v", got.Hours, got.OpeningHours)
+	}
+
+	serpAPI := map[string]any{
+		"local_results": []any{
+			map[string]any{
+				"title":           "Gopher Coffee",
+				"place_id":        "ChIJ123",
+				"gps_coordinates": map[string]any{"latitude": 30.2672, "longitude": -97.7431},
+				"hours":           "Open ⋅ Closes 6 PM",
+				"reviews":         float64(321),
+			},
+		},
+	}
+	normalized, err = NewNormalizer("serpapi").NormalizePlaces(&SearchResult{Data: serpAPI}, "coffee")
+	if err != nil {
+		t.Fatalf("NormalizePlaces failed: %v", err)
+	}
+	got = normalized.PlaceResults[0]
+	if got.PlaceID != "ChIJ123" || got.Hours != "Open ⋅ Closes 6 PM" || got.OpeningHours != nil || !got.HasLocation() {
+		t.Errorf("Unexpected serpapi place: %+v", got)
+	}
+}
+
+func TestNormalizeReviews(t *testing.T) {
+	serper := map[string]any{
+		"reviews": []any{
+			map[string]any{
+				"rating":  float64(5),
+				"date":    "a week ago",
+				"isoDate": "2026-06-01T10:00:00Z",
+				"snippet": "Great coffee",
+				"likes":   float64(3),
+				"user":    map[string]any{"name": "Ada"},
+			},
+			map[string]any{
+				"rating":  float64(3),
+				"date":    "2 years ago",
+				"snippet": "Slow service",
+				"user":    map[string]any{"name": "Bob"},
+			},
+		},
+	}
+	for _, engine := range []string{"serper", "serpapi"} {
+		normalized, err := NewNormalizer(engine).NormalizeReviews(&SearchResult{Data: serper}, "gopher coffee")
+		if err != nil {
+			t.Fatalf("NormalizeReviews failed: %v", err)
+		}
+		if len(normalized.ReviewResults) != 2 {
+			t.Fatalf("Expected 2 reviews, got %d", len(normalized.ReviewResults))
+		}
+		got := normalized.ReviewResults[0]
+		if got.Author != "Ada" || got.Rating != 5 || got.Likes != 3 || got.PublishedAt.Year() != 2026 || got.Engine != engine {
+			t.Errorf("Unexpected %s review: %+v", engine, got)
+		}
+		if normalized.ReviewResults[1].PublishedAt.IsZero() {
+			t.Errorf("Expected the relative date to be parsed")
+		}
+		if summary := normalized.ReviewSummary; summary == nil || summary.Count != 2 || summary.AverageRating != 4 {
+			t.Errorf("Unexpected %s summary: %+v", engine, summary)
+		}
+	}
 }
 
 func TestNormalizerUnifiedStructure(t *testing.T) {
@@ -259,3 +468,141 @@ func TestNormalizerUnifiedStructure(t *testing.T) {
 		t.Errorf("Normalized links don't match")
 	}
 }
+
+func TestNormalizeScholar(t *testing.T) {
+	serperScholar := map[string]any{
+		"organic": []any{
+			map[string]any{
+				"title":           "Attention is all you need",
+				"link":            "https://arxiv.org/abs/1706.03762",
+				"publicationInfo": "A Vaswani, N Shazeer, N Parmar - Advances in neural information processing systems, 2017 - proceedings.neurips.cc",
+				"citedBy":         float64(100000),
+				"pdfUrl":          "https://arxiv.org/pdf/1706.03762",
+			},
+		},
+	}
+
+	serpAPIScholar := map[string]any{
+		"organic_results": []any{
+			map[string]any{
+				"title": "Attention is all you need",
+				"link":  "https://arxiv.org/abs/1706.03762",
+				"publication_info": map[string]any{
+					"summary": "A Vaswani, N Shazeer, N Parmar - Advances in neural information processing systems, 2017 - proceedings.neurips.cc",
+				},
+				"inline_links": map[string]any{
+					"cited_by": map[string]any{"total": float64(100000), "cites_id": "2960712678066186980"},
+				},
+				"resources": []any{
+					map[string]any{"file_format": "PDF", "link": "https://arxiv.org/pdf/1706.03762"},
+				},
+			},
+		},
+	}
+
+	for engine, data := range map[string]map[string]any{"serper": serperScholar, "serpapi": serpAPIScholar} {
+		normalized, err := NewNormalizer(engine).NormalizeScholar(&SearchResult{Data: data}, "transformers")
+		if err != nil {
+			t.Fatalf("%s: NormalizeScholar failed: %v", engine, err)
+		}
+		if len(normalized.ScholarResults) != 1 {
+			t.Fatalf("%s: expected 1 scholar result, got %d", engine, len(normalized.ScholarResults))
+		}
+
+		r := normalized.ScholarResults[0]
+		if len(r.Authors) != 3 || r.Authors[0] != "A Vaswani" {
+			t.Errorf("%s: unexpected authors %v", engine, r.Authors)
+		}
+		if r.Year != "2017" {
+			t.Errorf("%s: expected year 2017, got %q", engine, r.Year)
+		}
+		if r.Source != "Advances in neural information processing systems" {
+			t.Errorf("%s: unexpected source %q", engine, r.Source)
+		}
+		if r.Citations != 100000 {
+			t.Errorf("%s: expected 100000 citations, got %d", engine, r.Citations)
+		}
+		if engine == "serpapi" && r.CitesID != "2960712678066186980" {
+			t.Errorf("%s: unexpected cites ID %q", engine, r.CitesID)
+		}
+		if r.PDF != "https://arxiv.org/pdf/1706.03762" {
+			t.Errorf("%s: unexpected PDF link %q", engine, r.PDF)
+		}
+	}
+}
+
+func TestNormalizeAutocomplete(t *testing.T) {
+	data := map[string]any{
+		"suggestions": []any{
+			map[string]any{"value": "golang tutorial"},
+			map[string]any{"value": ""},
+			map[string]any{"value": "golang generics"},
+		},
+	}
+	for _, engine := range []string{"serper", "serpapi"} {
+		normalized, err := NewNormalizer(engine).NormalizeAutocomplete(&SearchResult{Data: data}, "golang")
+		if err != nil {
+			t.Fatalf("%s: NormalizeAutocomplete failed: %v", engine, err)
+		}
+		if len(normalized.Suggestions) != 2 || normalized.Suggestions[1] != "golang generics" {
+			t.Errorf("%s: unexpected suggestions %v", engine, normalized.Suggestions)
+		}
+	}
+}
+
+func TestNormalizeSpelling(t *testing.T) {
+	responses := map[string]map[string]any{
+		"serper": {
+			"searchInformation": map[string]any{"showingResultsFor": "golang", "didYouMean": "go lang"},
+		},
+		"serpapi": {
+			"search_information": map[string]any{"showing_results_for": "golang", "did_you_mean": "go lang"},
+		},
+	}
+	for engine, data := range responses {
+		normalized, err := NewNormalizer(engine).NormalizeSearch(&SearchResult{Data: data}, "golnag")
+		if err != nil {
+			t.Fatalf("%s: NormalizeSearch failed: %v", engine, err)
+		}
+		if meta := normalized.SearchMetadata; meta.CorrectedQuery != "golang" || meta.SuggestedQuery != "go lang" {
+			t.Errorf("%s: unexpected corrections %q and %q", engine, meta.CorrectedQuery, meta.SuggestedQuery)
+		}
+	}
+}
+
+func TestNormalizeProvenance(t *testing.T) {
+	fetched := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Replayed responses keep the fetch time of their HTTP response
+	result := &SearchResult{
+		Data:     map[string]any{"organic": []any{map[string]any{"title": "Go", "link": "https://go.dev"}}},
+		Response: &ResponseMeta{FetchedAt: fetched},
+	}
+	normalized, err := NewNormalizer("serper").NormalizeSearch(result, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if r := normalized.OrganicResults[0]; r.Engine != "serper" || !r.FetchedAt.Equal(fetched) {
+		t.Errorf("Expected the response fetch time, got %q %v", r.Engine, r.FetchedAt)
+	}
+
+	// Items of pre-normalized results keep their provenance, and the
+	// engine's Data is not modified
+	data := &NormalizedSearchResult{OrganicResults: []OrganicResult{
+		{Position: 1, Title: "Cached", Engine: "brave", FetchedAt: fetched},
+		{Position: 2, Title: "Fresh"},
+	}}
+	normalized, err = NewNormalizer("custom").NormalizeSearch(&SearchResult{Data: data}, "golang")
+	if err != nil {
+		t.Fatalf("NormalizeSearch failed: %v", err)
+	}
+	if r := normalized.OrganicResults[0]; r.Engine != "brave" || !r.FetchedAt.Equal(fetched) {
+		t.Errorf("Expected the original provenance to be kept, got %q %v", r.Engine, r.FetchedAt)
+	}
+	if r := normalized.OrganicResults[1]; r.Engine != "custom" || r.FetchedAt.IsZero() {
+		t.Errorf("Expected provenance to be filled in, got %q %v", r.Engine, r.FetchedAt)
+	}
+	if r := data.OrganicResults[1]; r.Engine != "" || !r.FetchedAt.IsZero() || r.PagePosition != 0 {
+		t.Errorf("Expected the engine's data not to be modified, got %+v", r)
+	}
+}
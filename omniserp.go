@@ -1,37 +1,92 @@
 package omniserp
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 )
 
-// GetDefaultEngine returns the default engine based on environment variable
-// Falls back to "serper" if SEARCH_ENGINE is not set or the specified engine is not available
-func GetDefaultEngine(registry *Registry) (Engine, error) {
-	selectedEngine := os.Getenv("SEARCH_ENGINE")
-	if selectedEngine == "" {
-		selectedEngine = "serper" // Default to Serper
-	}
+// DefaultEngineName is used when SEARCH_ENGINE is not set
+const DefaultEngineName = "serper"
 
-	engine, exists := registry.Get(selectedEngine)
-	if !exists {
-		availableEngines := registry.List()
-		if len(availableEngines) == 0 {
-			return nil, fmt.Errorf("no search engines available")
-		}
+// ErrEngineNotFound is returned by GetDefaultEngine with FallbackStrict when
+// the requested engine is not registered
+var ErrEngineNotFound = errors.New("engine not found")
 
-		// Try to fallback to serper, otherwise use the first available
-		if fallbackEngine, exists := registry.Get("serper"); exists {
-			return fallbackEngine, fmt.Errorf("engine '%s' not found, falling back to 'serper'. Available engines: %v", selectedEngine, availableEngines)
-		}
+// ErrNoEngines is returned when the registry is empty
+var ErrNoEngines = errors.New("no search engines available")
+
+// FallbackPolicy controls what GetDefaultEngine does when the requested
+// engine is not registered
+type FallbackPolicy int
+
+const (
+	// FallbackAllowed selects "serper", or else the first available engine,
+	// and reports the substitution in FallbackInfo
+	FallbackAllowed FallbackPolicy = iota
+
+	// FallbackStrict returns ErrEngineNotFound instead of substituting
+	FallbackStrict
+)
+
+// FallbackInfo describes how GetDefaultEngine chose an engine
+type FallbackInfo struct {
+	Requested string   `json:"requested"`
+	Selected  string   `json:"selected,omitempty"`
+	FellBack  bool     `json:"fell_back"`
+	Available []string `json:"available"`
+}
+
+// String describes the fallback, or returns an empty string if none occurred
+func (f FallbackInfo) String() string {
+	if !f.FellBack {
+		return ""
+	}
+	return fmt.Sprintf("engine '%s' not found, falling back to '%s'. Available engines: %v", f.Requested, f.Selected, f.Available)
+}
 
-		// Use first available engine
-		firstEngine := availableEngines[0]
-		engine, _ = registry.Get(firstEngine)
-		return engine, fmt.Errorf("engine '%s' not found, falling back to '%s'. Available engines: %v", selectedEngine, firstEngine, availableEngines)
+// GetDefaultEngine returns the engine named by the SEARCH_ENGINE environment
+// variable, defaulting to "serper". If that engine is not registered, the
+// policy decides between an error and a fallback engine; a fallback is not an
+// error and is reported in FallbackInfo instead.
+func GetDefaultEngine(registry *Registry, policy FallbackPolicy) (Engine, FallbackInfo, error) {
+	info := FallbackInfo{
+		Requested: os.Getenv("SEARCH_ENGINE"),
+		Available: registry.List(),
+	}
+	if info.Requested == "" {
+		info.Requested = DefaultEngineName
 	}
 
-	return engine, nil
+	if engine, exists := registry.Get(info.Requested); exists {
+		info.Selected = info.Requested
+		return engine, info, nil
+	}
+	if len(info.Available) == 0 {
+		return nil, info, ErrNoEngines
+	}
+	if policy == FallbackStrict {
+		return nil, info, fmt.Errorf("%w: '%s'. Available engines: %v", ErrEngineNotFound, info.Requested, info.Available)
+	}
+
+	// Try to fallback to serper, otherwise use the engine supporting the
+	// most operations, so keyless engines with few operations come last
+	info.Selected = DefaultEngineName
+	engine, exists := registry.Get(DefaultEngineName)
+	if !exists {
+		names := slices.Clone(info.Available)
+		slices.Sort(names)
+		info.Selected = names[0]
+		engine, _ = registry.Get(info.Selected)
+		for _, name := range names[1:] {
+			if candidate, _ := registry.Get(name); len(candidate.GetSupportedTools()) > len(engine.GetSupportedTools()) {
+				info.Selected, engine = name, candidate
+			}
+		}
+	}
+	info.FellBack = true
+	return engine, info, nil
 }
 
 // GetEngineInfo returns information about an engine
@@ -39,6 +94,10 @@ type EngineInfo struct {
 	Name           string   `json:"name"`
 	Version        string   `json:"version"`
 	SupportedTools []string `json:"supported_tools"`
+
+	// ExtraParams are the engine-specific parameters the engine accepts in
+	// SearchParams.Extra
+	ExtraParams []ExtraParam `json:"extra_params,omitempty"`
 }
 
 // GetEngineInfo returns information about a specific engine
@@ -47,6 +106,7 @@ func GetEngineInfo(engine Engine) EngineInfo {
 		Name:           engine.GetName(),
 		Version:        engine.GetVersion(),
 		SupportedTools: engine.GetSupportedTools(),
+		ExtraParams:    ExtraParams(engine),
 	}
 }
 
@@ -0,0 +1,58 @@
+package omniserp
+
+import (
+	"errors"
+	"testing"
+)
+
+// namedEngine is an Engine stub that only reports its name and tools
+type namedEngine struct {
+	Engine
+	name  string
+	tools []string
+}
+
+func (e namedEngine) GetName() string             { return e.name }
+func (e namedEngine) GetSupportedTools() []string { return e.tools }
+
+func TestGetDefaultEngine(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(namedEngine{name: "serpapi"})
+	registry.Register(namedEngine{name: "serper"})
+
+	t.Setenv("SEARCH_ENGINE", "serpapi")
+	engine, info, err := GetDefaultEngine(registry, FallbackStrict)
+	if err != nil || engine.GetName() != "serpapi" || info.FellBack {
+		t.Fatalf("Expected serpapi without fallback, got %v %+v %v", engine, info, err)
+	}
+
+	t.Setenv("SEARCH_ENGINE", "brave")
+	engine, info, err = GetDefaultEngine(registry, FallbackAllowed)
+	if err != nil {
+		t.Fatalf("Expected fallback without error, got %v", err)
+	}
+	if engine.GetName() != "serper" || !info.FellBack || info.Requested != "brave" || info.Selected != "serper" {
+		t.Errorf("Expected fallback to serper, got %+v", info)
+	}
+	if info.String() == "" {
+		t.Error("Expected fallback description")
+	}
+
+	engine, info, err = GetDefaultEngine(registry, FallbackStrict)
+	if !errors.Is(err, ErrEngineNotFound) || engine != nil || info.FellBack {
+		t.Errorf("Expected ErrEngineNotFound in strict mode, got %v %+v %v", engine, info, err)
+	}
+
+	// Without serper, the engine supporting the most operations is preferred
+	registry = NewRegistry()
+	registry.Register(namedEngine{name: "duckduckgo", tools: []string{"google_search"}})
+	registry.Register(namedEngine{name: "serpapi", tools: []string{"google_search", "google_search_news"}})
+	engine, info, err = GetDefaultEngine(registry, FallbackAllowed)
+	if err != nil || engine.GetName() != "serpapi" || !info.FellBack {
+		t.Errorf("Expected fallback to serpapi, got %+v %v", info, err)
+	}
+
+	if _, _, err := GetDefaultEngine(NewRegistry(), FallbackAllowed); !errors.Is(err, ErrNoEngines) {
+		t.Errorf("Expected ErrNoEngines for empty registry, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package omniserp
+
+import (
+	"math"
+	"sort"
+)
+
+// earthRadiusKM is the mean radius of the Earth
+const earthRadiusKM = 6371.0088
+
+// GeoPoint is a position in decimal degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DistanceKM returns the great-circle distance between two points in
+// kilometers
+func DistanceKM(a, b GeoPoint) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// HasLocation reports whether the place has coordinates
+func (p PlaceResult) HasLocation() bool {
+	return p.Latitude != 0 || p.Longitude != 0
+}
+
+// Location returns the coordinates of the place
+func (p PlaceResult) Location() GeoPoint {
+	return GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// PlacesNear returns the places with coordinates, with DistanceKM set to
+// their distance from origin, sorted nearest first. A positive radiusKM drops
+// the places farther away. Places keep their engine positions; places is not
+// modified.
+func PlacesNear(places []PlaceResult, origin GeoPoint, radiusKM float64) []PlaceResult {
+	var near []PlaceResult
+	for _, place := range places {
+		if !place.HasLocation() {
+			continue
+		}
+		place.DistanceKM = DistanceKM(origin, place.Location())
+		if radiusKM > 0 && place.DistanceKM > radiusKM {
+			continue
+		}
+		near = append(near, place)
+	}
+	sort.SliceStable(near, func(i, j int) bool { return near[i].DistanceKM < near[j].DistanceKM })
+	return near
+}
@@ -0,0 +1,43 @@
+package omniserp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKM(t *testing.T) {
+	london := GeoPoint{Latitude: 51.5074, Longitude: -0.1278}
+	paris := GeoPoint{Latitude: 48.8566, Longitude: 2.3522}
+	if got := DistanceKM(london, paris); math.Abs(got-343.5) > 1 {
+		t.Errorf("Expected about 343.5 km from London to Paris, got %.1f", got)
+	}
+	if got := DistanceKM(paris, paris); got != 0 {
+		t.Errorf("Expected no distance to the same point, got %g", got)
+	}
+}
+
+func TestPlacesNear(t *testing.T) {
+	origin := GeoPoint{Latitude: 30.2672, Longitude: -97.7431} // Austin
+	places := []PlaceResult{
+		{Position: 1, Title: "Round Rock", Latitude: 30.5083, Longitude: -97.6789},
+		{Position: 2, Title: "Unknown"},
+		{Position: 3, Title: "Downtown", Latitude: 30.2682, Longitude: -97.7421},
+		{Position: 4, Title: "Houston", Latitude: 29.7604, Longitude: -95.3698},
+	}
+
+	near := PlacesNear(places, origin, 0)
+	if len(near) != 3 || near[0].Title != "Downtown" || near[1].Title != "Round Rock" || near[2].Title != "Houston" {
+		t.Fatalf("Expected the places with coordinates nearest first, got %+v", near)
+	}
+	if near[0].Position != 3 || near[0].DistanceKM <= 0 || near[0].DistanceKM > 1 {
+		t.Errorf("Unexpected nearest place: %+v", near[0])
+	}
+
+	near = PlacesNear(places, origin, 50)
+	if len(near) != 2 || near[1].Title != "Round Rock" {
+		t.Errorf("Expected the places within 50 km, got %+v", near)
+	}
+	if places[0].DistanceKM != 0 {
+		t.Error("Expected the input places to be unchanged")
+	}
+}
@@ -0,0 +1,88 @@
+package omniserp
+
+// OffsetPositions renumbers the organic, news, and scholar results of one
+// page to follow offset results on earlier pages: PagePosition keeps the
+// position on the page, while Position and SourcePosition become offset plus
+// PagePosition. The normalizer numbers every page from 1 with an offset of
+// zero; pagination helpers call it with the number of results returned by
+// the earlier pages.
+func (r *NormalizedSearchResult) OffsetPositions(offset int) {
+	for i := range r.OrganicResults {
+		item := &r.OrganicResults[i]
+		item.PagePosition, item.Position, item.SourcePosition = offsetPosition(item.PagePosition, item.Position, offset)
+	}
+	for i := range r.NewsResults {
+		item := &r.NewsResults[i]
+		item.PagePosition, item.Position, item.SourcePosition = offsetPosition(item.PagePosition, item.Position, offset)
+	}
+	for i := range r.ScholarResults {
+		item := &r.ScholarResults[i]
+		item.PagePosition, item.Position, item.SourcePosition = offsetPosition(item.PagePosition, item.Position, offset)
+	}
+}
+
+// offsetPosition returns the page, global, and source positions of an item,
+// taking the page position from position if it is not set yet
+func offsetPosition(pagePosition, position, offset int) (int, int, int) {
+	if pagePosition == 0 {
+		pagePosition = position
+	}
+	return pagePosition, offset + pagePosition, offset + pagePosition
+}
+
+// MergeResults combines pages or the results of several engines into one
+// result. Organic, news, and scholar results are concatenated in order and
+// their Position is renumbered from 1 across the merged result, while
+// PagePosition and SourcePosition keep the positions within their page and
+// source. Items without an engine are attributed to the engine of their
+// result. The metadata and featured content come from the first result.
+// The inputs are not modified.
+func MergeResults(results ...*NormalizedSearchResult) *NormalizedSearchResult {
+	merged := &NormalizedSearchResult{}
+	first := true
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if first {
+			merged.AnswerBox = result.AnswerBox
+			merged.KnowledgeGraph = result.KnowledgeGraph
+			merged.RelatedSearches = result.RelatedSearches
+			merged.PeopleAlsoAsk = result.PeopleAlsoAsk
+			merged.SearchMetadata = result.SearchMetadata
+			first = false
+		}
+		engine := result.SearchMetadata.Engine
+		for _, item := range result.OrganicResults {
+			if item.Engine == "" {
+				item.Engine = engine
+			}
+			if item.SourcePosition == 0 {
+				item.SourcePosition = item.Position
+			}
+			item.Position = len(merged.OrganicResults) + 1
+			merged.OrganicResults = append(merged.OrganicResults, item)
+		}
+		for _, item := range result.NewsResults {
+			if item.Engine == "" {
+				item.Engine = engine
+			}
+			if item.SourcePosition == 0 {
+				item.SourcePosition = item.Position
+			}
+			item.Position = len(merged.NewsResults) + 1
+			merged.NewsResults = append(merged.NewsResults, item)
+		}
+		for _, item := range result.ScholarResults {
+			if item.Engine == "" {
+				item.Engine = engine
+			}
+			if item.SourcePosition == 0 {
+				item.SourcePosition = item.Position
+			}
+			item.Position = len(merged.ScholarResults) + 1
+			merged.ScholarResults = append(merged.ScholarResults, item)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,47 @@
+package omniserp
+
+import "testing"
+
+func TestMergeResults(t *testing.T) {
+	page := func(engine string, links ...string) *NormalizedSearchResult {
+		result := &NormalizedSearchResult{SearchMetadata: SearchMetadata{Engine: engine, Query: "q"}}
+		for i, link := range links {
+			result.OrganicResults = append(result.OrganicResults, OrganicResult{Position: i + 1, Link: link})
+		}
+		result.OffsetPositions(0)
+		return result
+	}
+
+	second := page("serper", "c")
+	second.OffsetPositions(2)
+	if r := second.OrganicResults[0]; r.Position != 3 || r.PagePosition != 1 || r.SourcePosition != 3 {
+		t.Errorf("Unexpected offset positions: %+v", r)
+	}
+
+	merged := MergeResults(page("serper", "a", "b"), nil, second, page("serpapi", "x"))
+	want := []struct {
+		link                                   string
+		position, pagePosition, sourcePosition int
+		engine                                 string
+	}{
+		{"a", 1, 1, 1, "serper"},
+		{"b", 2, 2, 2, "serper"},
+		{"c", 3, 1, 3, "serper"},
+		{"x", 4, 1, 1, "serpapi"},
+	}
+	if len(merged.OrganicResults) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(merged.OrganicResults))
+	}
+	for i, w := range want {
+		r := merged.OrganicResults[i]
+		if r.Link != w.link || r.Position != w.position || r.PagePosition != w.pagePosition || r.SourcePosition != w.sourcePosition || r.Engine != w.engine {
+			t.Errorf("Result %d: expected %+v, got %+v", i, w, r)
+		}
+	}
+	if merged.SearchMetadata.Engine != "serper" {
+		t.Errorf("Expected the metadata of the first result, got %+v", merged.SearchMetadata)
+	}
+	if second.OrganicResults[0].Position != 3 {
+		t.Error("Expected the inputs to be unchanged")
+	}
+}
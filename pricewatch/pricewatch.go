@@ -0,0 +1,332 @@
+// Package pricewatch tracks the prices of specific products in the shopping
+// results of saved searches over time and reports price changes as alerts.
+//
+// A Watcher is seeded from the scheduler history and observes later runs
+// through a scheduler observer hook:
+//
+//	runs, _ := scheduler.ReadHistory("omniserp-history.jsonl")
+//	watcher := pricewatch.NewWatcher(products...)
+//	watcher.Replay(runs)
+//	runner.Observers = append(runner.Observers, watcher.Hook(alerts.NewWebhook(url)))
+package pricewatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/alerts"
+	"github.com/plexusone/omniserp/scheduler"
+)
+
+// Product is a product to watch. It matches shopping results with the same
+// product ID or, without one, the same canonical URL.
+type Product struct {
+	// Name labels the product in alerts (default: the product ID or URL)
+	Name string `json:"name,omitempty"`
+
+	ProductID string `json:"product_id,omitempty"`
+	URL       string `json:"url,omitempty"`
+
+	// Search restricts the product to runs of one saved search; empty
+	// matches runs of every saved search
+	Search string `json:"search,omitempty"`
+}
+
+// key identifies the product in the watcher
+func (p Product) key() string {
+	if p.ProductID != "" {
+		return "id:" + p.ProductID
+	}
+	return "url:" + omniserp.CanonicalURL(p.URL)
+}
+
+// label returns the name of the product in alerts
+func (p Product) label() string {
+	switch {
+	case p.Name != "":
+		return p.Name
+	case p.ProductID != "":
+		return p.ProductID
+	}
+	return p.URL
+}
+
+// matches reports whether a shopping result is the product
+func (p Product) matches(r omniserp.ShoppingResult) bool {
+	if p.ProductID != "" {
+		return r.ProductID == p.ProductID
+	}
+	return p.URL != "" && r.Link != "" && omniserp.CanonicalURL(r.Link) == omniserp.CanonicalURL(p.URL)
+}
+
+// LoadProducts reads the products to watch from a JSON array file
+func LoadProducts(path string) ([]Product, error) {
+	// #nosec G304 -- products path is provided by the caller
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read products: %w", err)
+	}
+	var products []Product
+	if err := json.Unmarshal(data, &products); err != nil {
+		return nil, fmt.Errorf("failed to parse products %s: %w", path, err)
+	}
+	for i, p := range products {
+		if p.ProductID == "" && p.URL == "" {
+			return nil, fmt.Errorf("product %d: product_id or url is required", i+1)
+		}
+	}
+	return products, nil
+}
+
+// Observation is the price of a product in one run
+type Observation struct {
+	Product    string    `json:"product"`
+	Title      string    `json:"title,omitempty"`
+	Link       string    `json:"link,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Change is a change of the price of a product between runs
+type Change struct {
+	Product    string    `json:"product"`
+	Title      string    `json:"title,omitempty"`
+	Link       string    `json:"link,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Previous   float64   `json:"previous"`
+	Current    float64   `json:"current"`
+	Currency   string    `json:"currency,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Delta returns the price difference, negative for a price drop
+func (c Change) Delta() float64 {
+	return c.Current - c.Previous
+}
+
+// Percent returns the relative price change in percent
+func (c Change) Percent() float64 {
+	if c.Previous == 0 {
+		return 0
+	}
+	return c.Delta() / c.Previous * 100
+}
+
+// Alert converts the change to a price alert
+func (c Change) Alert() alerts.Alert {
+	direction := "rose"
+	if c.Delta() < 0 {
+		direction = "dropped"
+	}
+	return alerts.Alert{
+		Kind:      alerts.KindPrice,
+		Subject:   c.Product,
+		Threshold: c.Previous,
+		Value:     c.Current,
+		Message: fmt.Sprintf(":moneybag: %s %s from %s to %s (%+.1f%%)",
+			c.Product, direction, formatPrice(c.Previous, c.Currency), formatPrice(c.Current, c.Currency), c.Percent()),
+		Time: c.ObservedAt,
+	}
+}
+
+// Watcher remembers the last price of each watched product and reports
+// changes. It is safe for concurrent use.
+type Watcher struct {
+	products []Product
+
+	// MinChange is the relative change (0.05 for 5%) below which price
+	// changes are not reported; small changes accumulate until they reach it.
+	// Zero reports every change.
+	MinChange float64
+
+	mu   sync.Mutex
+	last map[string]Observation
+}
+
+// NewWatcher creates a watcher for products
+func NewWatcher(products ...Product) *Watcher {
+	return &Watcher{products: products, last: make(map[string]Observation)}
+}
+
+// Observe records the prices of the watched products in a run and returns
+// the price changes since the last run that listed them. The first
+// observation of a product is a baseline and reports no change, as do
+// results without a parsable price and changes of currency.
+func (w *Watcher) Observe(run *scheduler.Run) []Change {
+	if run == nil || run.Result == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changes []Change
+	for _, product := range w.products {
+		if product.Search != "" && product.Search != run.Search {
+			continue
+		}
+		current, ok := find(product, run.Result.ShoppingResults, run.RanAt)
+		if !ok {
+			continue
+		}
+
+		key := product.key()
+		previous, seen := w.last[key]
+		if !seen || previous.Currency != current.Currency {
+			w.last[key] = current
+			continue
+		}
+		if current.Price == previous.Price ||
+			math.Abs(current.Price-previous.Price) < w.MinChange*previous.Price {
+			continue
+		}
+		w.last[key] = current
+		changes = append(changes, Change{
+			Product:    current.Product,
+			Title:      current.Title,
+			Link:       current.Link,
+			Source:     current.Source,
+			Previous:   previous.Price,
+			Current:    current.Price,
+			Currency:   current.Currency,
+			ObservedAt: current.ObservedAt,
+		})
+	}
+	return changes
+}
+
+// find returns the price of the first shopping result matching product
+func find(product Product, results []omniserp.ShoppingResult, at time.Time) (Observation, bool) {
+	for _, r := range results {
+		if !product.matches(r) {
+			continue
+		}
+		price, currency, ok := ParsePrice(r.Price)
+		if !ok {
+			continue
+		}
+		if r.Currency != "" {
+			currency = r.Currency
+		}
+		return Observation{
+			Product:    product.label(),
+			Title:      r.Title,
+			Link:       r.Link,
+			Source:     r.Source,
+			Price:      price,
+			Currency:   currency,
+			ObservedAt: at,
+		}, true
+	}
+	return Observation{}, false
+}
+
+// Replay observes the runs of a history in order, such as those returned by
+// scheduler.ReadHistory, so the watcher continues from the last known prices
+// across restarts. It returns the price changes within the history.
+func (w *Watcher) Replay(runs []*scheduler.Run) []Change {
+	var changes []Change
+	for _, run := range runs {
+		changes = append(changes, w.Observe(run)...)
+	}
+	return changes
+}
+
+// Prices returns the last known price of each watched product that has been
+// observed, sorted by product name
+func (w *Watcher) Prices() []Observation {
+	w.mu.Lock()
+	prices := make([]Observation, 0, len(w.last))
+	for _, observation := range w.last {
+		prices = append(prices, observation)
+	}
+	w.mu.Unlock()
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Product < prices[j].Product })
+	return prices
+}
+
+// Hook returns a scheduler observer that sends an alert through notifier for
+// every price change in a run
+func (w *Watcher) Hook(notifier alerts.Notifier) scheduler.Hook {
+	return func(ctx context.Context, run *scheduler.Run) error {
+		var errs []error
+		for _, change := range w.Observe(run) {
+			if err := notifier.Notify(ctx, change.Alert()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// currencySymbols maps currency symbols to ISO 4217 codes
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"€":   "EUR",
+	"£":   "GBP",
+	"¥":   "JPY",
+	"₹":   "INR",
+	"C$":  "CAD",
+	"A$":  "AUD",
+}
+
+// ParsePrice parses a displayed price such as "$1,299.99", "1.299,00 €", or
+// "EUR 12.50" into its amount and ISO 4217 currency code, which is empty if
+// the price has no recognized currency. The last comma or period is taken as
+// the decimal separator unless three digits follow it.
+func ParsePrice(s string) (amount float64, currency string, ok bool) {
+	start := strings.IndexFunc(s, unicode.IsDigit)
+	if start < 0 {
+		return 0, "", false
+	}
+	end := start
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == ',' || s[end] == '.') {
+		end++
+	}
+	number := strings.TrimRight(s[start:end], ",.")
+
+	// The last separator is a thousands separator if three digits follow it
+	if i := strings.LastIndexAny(number, ",."); i >= 0 && len(number)-i-1 != 3 {
+		number = strings.NewReplacer(",", "", ".", "").Replace(number[:i]) + "." + number[i+1:]
+	} else {
+		number = strings.NewReplacer(",", "", ".", "").Replace(number)
+	}
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	symbols := strings.TrimSpace(s[:start]) + " " + strings.TrimSpace(s[end:])
+	for _, field := range strings.Fields(symbols) {
+		if code, ok := currencySymbols[field]; ok {
+			return amount, code, true
+		}
+		if len(field) == 3 && strings.ToUpper(field) == field && strings.IndexFunc(field, func(r rune) bool { return !unicode.IsUpper(r) }) < 0 {
+			return amount, field, true
+		}
+	}
+	return amount, "", true
+}
+
+// formatPrice formats an amount with its currency code
+func formatPrice(amount float64, currency string) string {
+	if currency == "" {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	return fmt.Sprintf("%s %.2f", currency, amount)
+}
@@ -0,0 +1,140 @@
+package pricewatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/alerts"
+	"github.com/plexusone/omniserp/scheduler"
+)
+
+// recorder collects alerts
+type recorder struct {
+	alerts []alerts.Alert
+}
+
+func (r *recorder) Notify(ctx context.Context, alert alerts.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+// shoppingRun builds a run of search listing the plush and the mug at the
+// given prices
+func shoppingRun(search string, day int, plush, mug string) *scheduler.Run {
+	return &scheduler.Run{
+		Search: search,
+		RanAt:  time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC),
+		Result: &omniserp.NormalizedSearchResult{ShoppingResults: []omniserp.ShoppingResult{
+			{Position: 1, Title: "Gopher Plush", ProductID: "123", Link: "https://shop.example/plush", Price: plush},
+			{Position: 2, Title: "Gopher Mug", Link: "https://www.mugs.example/gopher/?utm_source=ads", Price: mug},
+		}},
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in       string
+		amount   float64
+		currency string
+	}{
+		{"$1,299.99", 1299.99, "USD"},
+		{"$1,299", 1299, "USD"},
+		{"1.299,00 €", 1299, "EUR"},
+		{"£12.5", 12.5, "GBP"},
+		{"EUR 12,50", 12.5, "EUR"},
+		{"19.99", 19.99, ""},
+		{"Now $24.00 used", 24, "USD"},
+	}
+	for _, tt := range tests {
+		amount, currency, ok := ParsePrice(tt.in)
+		if !ok || amount != tt.amount || currency != tt.currency {
+			t.Errorf("ParsePrice(%q) = %g, %q, %t; want %g, %q", tt.in, amount, currency, ok, tt.amount, tt.currency)
+		}
+	}
+	if _, _, ok := ParsePrice("Free"); ok {
+		t.Error("Expected a price without digits to be rejected")
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	watcher := NewWatcher(
+		Product{Name: "plush", ProductID: "123"},
+		Product{URL: "https://mugs.example/gopher", Search: "mugs"},
+	)
+	watcher.MinChange = 0.05
+
+	runs := []*scheduler.Run{
+		shoppingRun("mugs", 1, "$20.00", "$10.00"),
+		shoppingRun("mugs", 2, "$19.50", "$10.00"), // 2.5% is below the minimum
+		shoppingRun("mugs", 3, "$18.99", "$12.00"),
+		shoppingRun("plush", 4, "$18.99", "$8.00"), // the mug is only watched in "mugs"
+	}
+	if changes := watcher.Replay(runs[:1]); len(changes) != 0 {
+		t.Errorf("Expected the first run to be a baseline, got %+v", changes)
+	}
+	if changes := watcher.Observe(runs[1]); len(changes) != 0 {
+		t.Errorf("Expected changes below the minimum to be ignored, got %+v", changes)
+	}
+
+	changes := watcher.Replay(runs[2:])
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	plush, mug := changes[0], changes[1]
+	if plush.Product != "plush" || plush.Previous != 20 || plush.Current != 18.99 || plush.Currency != "USD" {
+		t.Errorf("Unexpected plush change: %+v", plush)
+	}
+	if mug.Product != "https://mugs.example/gopher" || mug.Delta() != 2 || mug.Percent() != 20 {
+		t.Errorf("Unexpected mug change: %+v", mug)
+	}
+
+	prices := watcher.Prices()
+	if len(prices) != 2 || prices[1].Product != "plush" || prices[1].Price != 18.99 {
+		t.Errorf("Unexpected prices: %+v", prices)
+	}
+}
+
+func TestHook(t *testing.T) {
+	notifier := &recorder{}
+	watcher := NewWatcher(Product{Name: "Gopher plush", ProductID: "123"})
+	hook := watcher.Hook(notifier)
+	ctx := context.Background()
+
+	for i, price := range []string{"$20.00", "$15.00", "$15.00"} {
+		if err := hook(ctx, shoppingRun("plush", i+1, price, "")); err != nil {
+			t.Fatalf("Hook failed: %v", err)
+		}
+	}
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %+v", notifier.alerts)
+	}
+	alert := notifier.alerts[0]
+	if alert.Kind != alerts.KindPrice || alert.Threshold != 20 || alert.Value != 15 {
+		t.Errorf("Unexpected alert: %+v", alert)
+	}
+	if want := ":moneybag: Gopher plush dropped from USD 20.00 to USD 15.00 (-25.0%)"; alert.Message != want {
+		t.Errorf("Message = %q, want %q", alert.Message, want)
+	}
+}
+
+func TestLoadProducts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "plush", "product_id": "123"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	products, err := LoadProducts(path)
+	if err != nil || len(products) != 1 || products[0].ProductID != "123" {
+		t.Errorf("LoadProducts() = %+v, %v", products, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "plush"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProducts(path); err == nil {
+		t.Error("Expected a product without an ID or URL to be rejected")
+	}
+}
@@ -0,0 +1,179 @@
+package rank
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Movement classifies how a keyword ranking changed between snapshots
+type Movement string
+
+const (
+	MovementImproved  Movement = "improved"
+	MovementDeclined  Movement = "declined"
+	MovementUnchanged Movement = "unchanged"
+	MovementNew       Movement = "new"  // ranks now but did not before
+	MovementLost      Movement = "lost" // ranked before but not now
+	MovementAbsent    Movement = "absent"
+)
+
+// KeywordChange is the movement of one keyword between two snapshots
+type KeywordChange struct {
+	Keyword          string   `json:"keyword"`
+	PreviousPosition int      `json:"previous_position"`
+	CurrentPosition  int      `json:"current_position"`
+	Delta            int      `json:"delta"` // positive means moved up
+	Movement         Movement `json:"movement"`
+	PreviousURL      string   `json:"previous_url,omitempty"`
+	CurrentURL       string   `json:"current_url,omitempty"`
+	FeaturesAdded    []string `json:"features_added,omitempty"`
+	FeaturesRemoved  []string `json:"features_removed,omitempty"`
+	OwnedGained      []string `json:"owned_features_gained,omitempty"`
+	OwnedLost        []string `json:"owned_features_lost,omitempty"`
+
+	// Results is the diff of all organic results and SERP features, set when
+	// both snapshots recorded the results of the keyword
+	Results *omniserp.ResultsDiff `json:"results,omitempty"`
+}
+
+// Report compares two snapshots of the same domain
+type Report struct {
+	Domain  string           `json:"domain"`
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	Changes []KeywordChange  `json:"changes"`
+	Summary map[Movement]int `json:"summary"`
+}
+
+// Compare reports per-keyword position deltas, new/lost rankings, and SERP
+// feature changes from the previous to the current snapshot. The organic
+// results of a keyword are compared with omniserp.DiffResults, so the
+// tracker and the CLI watch mode report the same changes.
+func Compare(previous, current *Snapshot) *Report {
+	report := &Report{
+		Domain:  current.Domain,
+		From:    previous.TakenAt,
+		To:      current.TakenAt,
+		Summary: make(map[Movement]int),
+	}
+
+	for _, keyword := range sortedKeywords(previous, current) {
+		prev := previous.Keywords[keyword]
+		curr := current.Keywords[keyword]
+
+		change := KeywordChange{
+			Keyword:          keyword,
+			PreviousPosition: prev.Position,
+			CurrentPosition:  curr.Position,
+			PreviousURL:      prev.URL,
+			CurrentURL:       curr.URL,
+		}
+		if prev.Results != nil && curr.Results != nil {
+			change.Results = omniserp.DiffResults(prev.serp(), curr.serp())
+			// Snapshots keep the names of the features rather than the
+			// features themselves
+			change.Results.FeaturesAdded, change.Results.FeaturesRemoved = omniserp.DiffFeatures(prev.Features, curr.Features)
+			change.FeaturesAdded, change.FeaturesRemoved = change.Results.FeaturesAdded, change.Results.FeaturesRemoved
+		} else {
+			change.FeaturesAdded, change.FeaturesRemoved = omniserp.DiffFeatures(prev.Features, curr.Features)
+		}
+		change.OwnedGained, change.OwnedLost = omniserp.DiffFeatures(prev.OwnedFeatures, curr.OwnedFeatures)
+
+		switch {
+		case prev.Position == 0 && curr.Position == 0:
+			change.Movement = MovementAbsent
+		case prev.Position == 0:
+			change.Movement = MovementNew
+		case curr.Position == 0:
+			change.Movement = MovementLost
+		default:
+			change.Delta = prev.Position - curr.Position
+			switch {
+			case change.Delta > 0:
+				change.Movement = MovementImproved
+			case change.Delta < 0:
+				change.Movement = MovementDeclined
+			default:
+				change.Movement = MovementUnchanged
+			}
+		}
+
+		report.Summary[change.Movement]++
+		report.Changes = append(report.Changes, change)
+	}
+
+	return report
+}
+
+// serp rebuilds the organic results of the keyword for omniserp.DiffResults
+func (kr KeywordRank) serp() *omniserp.NormalizedSearchResult {
+	result := &omniserp.NormalizedSearchResult{}
+	for _, r := range kr.Results {
+		result.OrganicResults = append(result.OrganicResults, omniserp.OrganicResult{Position: r.Position, Title: r.Title, Link: r.URL})
+	}
+	return result
+}
+
+// Markdown renders the report as a Markdown table
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Rank Movement: %s\n\n", r.Domain)
+	fmt.Fprintf(&b, "%s → %s\n\n", r.From.Format(time.DateOnly), r.To.Format(time.DateOnly))
+
+	for _, m := range []Movement{MovementImproved, MovementDeclined, MovementNew, MovementLost, MovementUnchanged} {
+		fmt.Fprintf(&b, "- %s: %d\n", m, r.Summary[m])
+	}
+	b.WriteString("\n| Keyword | Previous | Current | Change | SERP Features | Results |\n")
+	b.WriteString("|---------|----------|---------|--------|---------------|---------|\n")
+
+	for _, c := range r.Changes {
+		var features []string
+		for _, f := range c.FeaturesAdded {
+			features = append(features, "+"+f)
+		}
+		for _, f := range c.FeaturesRemoved {
+			features = append(features, "-"+f)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			strings.ReplaceAll(c.Keyword, "|", "\\|"),
+			formatPosition(c.PreviousPosition),
+			formatPosition(c.CurrentPosition),
+			formatMovement(c),
+			strings.Join(features, ", "),
+			formatResults(c.Results))
+	}
+
+	return b.String()
+}
+
+func formatPosition(p int) string {
+	if p == 0 {
+		return "–"
+	}
+	return fmt.Sprintf("%d", p)
+}
+
+// formatResults counts the added, removed and moved organic results
+func formatResults(d *omniserp.ResultsDiff) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("+%d -%d ↕%d", len(d.Added), len(d.Removed), len(d.Moved))
+}
+
+func formatMovement(c KeywordChange) string {
+	switch c.Movement {
+	case MovementImproved:
+		return fmt.Sprintf("▲ %d", c.Delta)
+	case MovementDeclined:
+		return fmt.Sprintf("▼ %d", -c.Delta)
+	case MovementUnchanged, MovementAbsent:
+		return "="
+	default:
+		return string(c.Movement)
+	}
+}
@@ -0,0 +1,195 @@
+// Package rank tracks the search positions of a target domain across keywords
+// and compares tracking snapshots to report position movement.
+package rank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// SERP features recorded per keyword
+const (
+	FeatureAnswerBox      = omniserp.FeatureAnswerBox
+	FeatureKnowledgeGraph = omniserp.FeatureKnowledgeGraph
+	FeaturePeopleAlsoAsk  = omniserp.FeaturePeopleAlsoAsk
+	FeatureRelated        = omniserp.FeatureRelated
+	FeatureNews           = omniserp.FeatureNews
+	FeatureImages         = omniserp.FeatureImages
+	FeatureVideos         = omniserp.FeatureVideos
+	FeaturePlaces         = omniserp.FeaturePlaces
+	FeatureShopping       = omniserp.FeatureShopping
+)
+
+// Searcher performs normalized web searches; *client.Client implements it
+type Searcher interface {
+	SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error)
+}
+
+// KeywordRank is the ranking of the target domain for one keyword
+type KeywordRank struct {
+	Keyword  string `json:"keyword"`
+	Position int    `json:"position"` // 0 if the domain does not rank
+	URL      string `json:"url,omitempty"`
+
+	// Features lists the SERP features present for the keyword
+	Features []string `json:"features,omitempty"`
+
+	// OwnedFeatures lists SERP features that link to the target domain
+	OwnedFeatures []string `json:"owned_features,omitempty"`
+
+	// Results lists the organic results of the keyword, which Compare diffs
+	// with omniserp.DiffResults
+	Results []RankedResult `json:"results,omitempty"`
+}
+
+// RankedResult is an organic result of a keyword search
+type RankedResult struct {
+	Position int    `json:"position"`
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url"`
+}
+
+// Snapshot is the ranking of a domain for a set of keywords at a point in time
+type Snapshot struct {
+	Domain   string                 `json:"domain"`
+	Engine   string                 `json:"engine,omitempty"`
+	TakenAt  time.Time              `json:"taken_at"`
+	Keywords map[string]KeywordRank `json:"keywords"`
+}
+
+// Track searches each keyword and records the domain's ranking
+func Track(ctx context.Context, searcher Searcher, domain string, keywords []string, params omniserp.SearchParams) (*Snapshot, error) {
+	results := make([]*omniserp.NormalizedSearchResult, 0, len(keywords))
+	for _, keyword := range keywords {
+		p := params
+		p.Query = keyword
+		result, err := searcher.SearchNormalized(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("search for %q failed: %w", keyword, err)
+		}
+		results = append(results, result)
+	}
+	return NewSnapshot(domain, results...), nil
+}
+
+// NewSnapshot builds a snapshot from already fetched normalized results
+func NewSnapshot(domain string, results ...*omniserp.NormalizedSearchResult) *Snapshot {
+	snapshot := &Snapshot{
+		Domain:   strings.ToLower(domain),
+		TakenAt:  time.Now().UTC(),
+		Keywords: make(map[string]KeywordRank),
+	}
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if snapshot.Engine == "" {
+			snapshot.Engine = result.SearchMetadata.Engine
+		}
+		kr := keywordRank(snapshot.Domain, result)
+		snapshot.Keywords[kr.Keyword] = kr
+	}
+
+	return snapshot
+}
+
+// keywordRank extracts the domain's position and SERP features from a result
+func keywordRank(domain string, result *omniserp.NormalizedSearchResult) KeywordRank {
+	kr := KeywordRank{Keyword: result.SearchMetadata.Query}
+
+	for _, r := range result.OrganicResults {
+		if kr.Position == 0 && MatchesDomain(r.Link, domain) {
+			kr.Position = r.Position
+			kr.URL = r.Link
+		}
+		kr.Results = append(kr.Results, RankedResult{Position: r.Position, Title: r.Title, URL: r.Link})
+	}
+
+	kr.Features = result.Features()
+
+	// Features with links are owned when one of them points to the domain
+	links := map[string][]string{}
+	if result.AnswerBox != nil {
+		links[FeatureAnswerBox] = []string{result.AnswerBox.Link}
+	}
+	for _, paa := range result.PeopleAlsoAsk {
+		links[FeaturePeopleAlsoAsk] = append(links[FeaturePeopleAlsoAsk], paa.Link)
+	}
+	for _, news := range result.NewsResults {
+		links[FeatureNews] = append(links[FeatureNews], news.Link)
+	}
+	for _, video := range result.VideoResults {
+		links[FeatureVideos] = append(links[FeatureVideos], video.Link)
+	}
+	for _, feature := range kr.Features {
+		if slices.ContainsFunc(links[feature], func(link string) bool { return MatchesDomain(link, domain) }) {
+			kr.OwnedFeatures = append(kr.OwnedFeatures, feature)
+		}
+	}
+
+	return kr
+}
+
+// MatchesDomain reports whether link points to domain or one of its subdomains
+func MatchesDomain(link, domain string) bool {
+	if link == "" || domain == "" {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// Save writes the snapshot as JSON
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- snapshot path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// sortedKeywords returns the union of keywords in both snapshots, sorted
+func sortedKeywords(a, b *Snapshot) []string {
+	seen := make(map[string]bool)
+	for k := range a.Keywords {
+		seen[k] = true
+	}
+	for k := range b.Keywords {
+		seen[k] = true
+	}
+	keywords := make([]string, 0, len(seen))
+	for k := range seen {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	return keywords
+}
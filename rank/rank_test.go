@@ -0,0 +1,122 @@
+package rank
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func result(query string, links ...string) *omniserp.NormalizedSearchResult {
+	r := &omniserp.NormalizedSearchResult{SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: query}}
+	for i, link := range links {
+		r.OrganicResults = append(r.OrganicResults, omniserp.OrganicResult{Position: i + 1, Link: link})
+	}
+	return r
+}
+
+func TestMatchesDomain(t *testing.T) {
+	tests := []struct {
+		link, domain string
+		want         bool
+	}{
+		{"https://www.example.com/page", "example.com", true},
+		{"https://blog.example.com/", "example.com", true},
+		{"https://notexample.com/", "example.com", false},
+		{"https://example.org/", "example.com", false},
+		{"", "example.com", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesDomain(tt.link, tt.domain); got != tt.want {
+			t.Errorf("MatchesDomain(%q, %q) = %v, want %v", tt.link, tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := NewSnapshot("example.com",
+		result("up", "https://a.com", "https://b.com", "https://example.com/up"),
+		result("down", "https://example.com/down", "https://a.com"),
+		result("lost", "https://example.com/lost"),
+		result("same", "https://example.com/same"),
+	)
+
+	afterNew := result("new", "https://a.com", "https://example.com/new")
+	afterNew.AnswerBox = &omniserp.AnswerBox{Link: "https://example.com/answer"}
+	after := NewSnapshot("example.com",
+		result("up", "https://example.com/up"),
+		result("down", "https://a.com", "https://b.com", "https://example.com/down"),
+		result("lost", "https://a.com"),
+		result("same", "https://example.com/same"),
+		afterNew,
+	)
+
+	report := Compare(before, after)
+
+	want := map[string]Movement{
+		"up":   MovementImproved,
+		"down": MovementDeclined,
+		"lost": MovementLost,
+		"same": MovementUnchanged,
+		"new":  MovementNew,
+	}
+	for _, c := range report.Changes {
+		if c.Movement != want[c.Keyword] {
+			t.Errorf("%s: expected %s, got %s", c.Keyword, want[c.Keyword], c.Movement)
+		}
+		switch c.Keyword {
+		case "up":
+			if c.Delta != 2 {
+				t.Errorf("up: expected delta 2, got %d", c.Delta)
+			}
+		case "down":
+			if c.Delta != -2 {
+				t.Errorf("down: expected delta -2, got %d", c.Delta)
+			}
+		case "new":
+			if len(c.FeaturesAdded) != 1 || c.FeaturesAdded[0] != FeatureAnswerBox {
+				t.Errorf("new: expected answer box added, got %v", c.FeaturesAdded)
+			}
+			if len(c.OwnedGained) != 1 {
+				t.Errorf("new: expected owned answer box, got %v", c.OwnedGained)
+			}
+		}
+	}
+
+	if report.Summary[MovementImproved] != 1 || report.Summary[MovementLost] != 1 {
+		t.Errorf("Unexpected summary: %v", report.Summary)
+	}
+
+	if md := report.Markdown(); !strings.Contains(md, "| up | 3 | 1 | ▲ 2 |") {
+		t.Errorf("Markdown missing improved row:\n%s", md)
+	}
+}
+
+func TestCompareResults(t *testing.T) {
+	before := NewSnapshot("example.com", result("lost", "https://example.com/lost", "https://b.com"))
+	after := NewSnapshot("example.com", result("lost", "https://a.com", "https://www.b.com/?utm_source=x"))
+
+	report := Compare(before, after)
+	d := report.Changes[0].Results
+	if d == nil {
+		t.Fatal("Expected a results diff")
+	}
+	if len(d.Added) != 1 || d.Added[0].URL != "https://a.com" {
+		t.Errorf("Expected a.com added, got %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].URL != "https://example.com/lost" {
+		t.Errorf("Expected the domain result removed, got %+v", d.Removed)
+	}
+	if len(d.Moved) != 0 || d.Unchanged != 1 {
+		t.Errorf("Expected b.com unchanged, got %+v", d)
+	}
+	if md := report.Markdown(); !strings.Contains(md, "| lost | 1 | – | lost |  | +1 -1 ↕0 |") {
+		t.Errorf("Markdown missing results counts:\n%s", md)
+	}
+
+	// Snapshots saved before results were recorded are compared by position
+	before.Keywords["lost"] = KeywordRank{Keyword: "lost", Position: 1, URL: "https://example.com/lost"}
+	if c := Compare(before, after).Changes[0]; c.Results != nil || c.Movement != MovementLost {
+		t.Errorf("Expected a lost keyword without a results diff, got %+v", c)
+	}
+}
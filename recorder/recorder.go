@@ -0,0 +1,385 @@
+// Package recorder implements an HTTP proxy between omniserp engines and
+// their APIs that records every response to fixture files and can serve
+// requests entirely from the fixtures, so demos, workshops, and CI run
+// without credentials or cost.
+//
+// Engines are pointed at the proxy with their SetBaseURL method, or all at
+// once with the ProxyURL client option: requests for an engine are sent to
+// <proxy>/<engine name>/<API path>. API keys are passed through to the
+// upstream API, are not part of the fixture key, and are redacted from the
+// fixtures, so replays work with placeholder keys.
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ErrNotRecorded is returned in replay mode for requests that have no
+// fixture
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Mode selects whether the proxy calls the upstream APIs
+type Mode string
+
+const (
+	// ModeRecord forwards every request and records its response,
+	// replacing earlier fixtures of the same request
+	ModeRecord Mode = "record"
+
+	// ModeReplay serves fixtures without calling the upstream APIs and
+	// answers requests that were not recorded with 404
+	ModeReplay Mode = "replay"
+
+	// ModeAuto serves fixtures and forwards and records the requests that
+	// were not recorded yet
+	ModeAuto Mode = "auto"
+)
+
+// ParseMode parses a mode name
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(s)); mode {
+	case ModeRecord, ModeReplay, ModeAuto:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid mode %q: must be record, replay, or auto", s)
+}
+
+// maxRequestBody is the largest request body the proxy forwards
+const maxRequestBody = 1 << 20
+
+// credentialParams are the query parameters carrying API keys, which are
+// left out of fixture keys and redacted from fixtures
+var credentialParams = []string{"api_key", "apikey", "key"}
+
+// credentialHeaders are the request headers carrying API keys
+var credentialHeaders = []string{"Authorization", "X-API-Key", "X-Subscription-Token"}
+
+// DefaultUpstreams returns the API base URLs of the built-in engines by
+// engine name. SearXNG and Elasticsearch are included when SEARXNG_URL and
+// ELASTICSEARCH_URL are set. DuckDuckGo, Jina, and Wikipedia are resolved
+// per request, since these engines send the requests of their several
+// services or wikis to one base URL.
+func DefaultUpstreams() map[string]string {
+	upstreams := map[string]string{
+		"serper":         "https://google.serper.dev",
+		"serpapi":        "https://serpapi.com",
+		"serpapi-bing":   "https://serpapi.com",
+		"serpapi-yandex": "https://serpapi.com",
+		"googlecse":      "https://www.googleapis.com",
+		"kagi":           "https://kagi.com/api/v0",
+		"tavily":         "https://api.tavily.com",
+		"exa":            "https://api.exa.ai",
+		"youcom":         "https://ydc-index.io",
+		"mojeek":         "https://api.mojeek.com",
+		"baidu":          "https://serpapi.com",
+		"dataforseo":     "https://api.dataforseo.com",
+		"valueserp":      "https://api.valueserp.com",
+		"scaleserp":      "https://api.scaleserp.com",
+		"zenserp":        "https://app.zenserp.com",
+		"searchapi":      "https://www.searchapi.io",
+		"brightdata":     "https://api.brightdata.com",
+		"apify":          "https://api.apify.com",
+		"perplexity":     "https://api.perplexity.ai",
+		"firecrawl":      "https://api.firecrawl.dev",
+	}
+	if u := os.Getenv("SEARXNG_URL"); u != "" {
+		upstreams["searxng"] = strings.TrimSuffix(u, "/")
+	}
+	if u := os.Getenv("ELASTICSEARCH_URL"); u != "" {
+		upstreams["elasticsearch"] = strings.TrimSuffix(u, "/")
+	}
+	return upstreams
+}
+
+// jinaUpstream returns the Jina API serving a request: the Reader API for
+// paths that are URLs to read, and the Search API otherwise
+func jinaUpstream(path string) string {
+	if strings.HasPrefix(path, "/http:") || strings.HasPrefix(path, "/https:") {
+		return "https://r.jina.ai"
+	}
+	return "https://s.jina.ai"
+}
+
+// wikipediaLanguage matches the language path segment of Wikipedia requests
+var wikipediaLanguage = regexp.MustCompile(`^[a-z][a-z0-9]{1,11}(-[a-z0-9]{1,8}){0,2}$`)
+
+// wikipediaUpstream returns the URL of a Wikipedia request, whose path
+// starts with the language of the wiki, such as /de/w/api.php
+func wikipediaUpstream(path string) (string, bool) {
+	language, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !wikipediaLanguage.MatchString(language) {
+		return "", false
+	}
+	return "https://" + language + ".wikipedia.org/" + rest, true
+}
+
+// duckDuckGoUpstream returns the DuckDuckGo service serving a request
+func duckDuckGoUpstream(path string, query url.Values) string {
+	switch {
+	case strings.HasPrefix(path, "/html"):
+		return "https://html.duckduckgo.com"
+	case query.Get("format") == "json":
+		return "https://api.duckduckgo.com"
+	}
+	return "https://duckduckgo.com"
+}
+
+// Fixture is one recorded exchange with an engine API
+type Fixture struct {
+	Engine string `json:"engine"`
+	Method string `json:"method"`
+
+	// Path and Query are relative to the engine's API base URL; API keys
+	// are removed from the query
+	Path        string `json:"path"`
+	Query       string `json:"query,omitempty"`
+	RequestBody string `json:"request_body,omitempty"`
+
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        string    `json:"body"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// Proxy forwards engine requests to their APIs and records or replays the
+// responses as one JSON fixture file per request under Dir/<engine>/
+type Proxy struct {
+	// Dir is the directory of the fixtures
+	Dir string
+
+	// Mode selects recording or replay (default ModeReplay)
+	Mode Mode
+
+	// Upstreams maps engine names to API base URLs. If nil,
+	// DefaultUpstreams is used. DuckDuckGo is always routed.
+	Upstreams map[string]string
+
+	// Client performs upstream requests (default http.DefaultClient)
+	Client *http.Client
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	engine, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	path = "/" + path
+	upstreamURL, ok := p.upstreamURL(engine, path, r.URL.Query())
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown engine %q", engine), http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	query := stripCredentials(r.URL.Query())
+	key := fixtureKey(r.Method, path, query, body)
+
+	mode := p.Mode
+	if mode == "" {
+		mode = ModeReplay
+	}
+	if mode != ModeRecord {
+		fixture, err := p.load(engine, key)
+		if err == nil {
+			writeFixture(w, fixture)
+			return
+		}
+		if mode == ModeReplay || !errors.Is(err, ErrNotRecorded) {
+			status := http.StatusNotFound
+			if !errors.Is(err, ErrNotRecorded) {
+				status = http.StatusInternalServerError
+			}
+			http.Error(w, fmt.Sprintf("%s %s: %v", r.Method, r.URL.Path, err), status)
+			return
+		}
+	}
+
+	fixture, err := p.forward(r, upstreamURL, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	fixture.Engine, fixture.Path, fixture.Query = engine, path, query.Encode()
+	if len(body) > 0 {
+		fixture.RequestBody = string(body)
+	}
+	redact(fixture, r)
+	if err := p.save(key, fixture); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeFixture(w, fixture)
+}
+
+// upstreamURL returns the URL of the upstream API serving a request path of
+// an engine
+func (p *Proxy) upstreamURL(engine, path string, query url.Values) (string, bool) {
+	switch engine {
+	case "duckduckgo":
+		return duckDuckGoUpstream(path, query) + path, true
+	case "jina":
+		return jinaUpstream(path) + path, true
+	case "wikipedia":
+		return wikipediaUpstream(path)
+	}
+	upstreams := p.Upstreams
+	if upstreams == nil {
+		upstreams = DefaultUpstreams()
+	}
+	base, ok := upstreams[engine]
+	return strings.TrimSuffix(base, "/") + path, ok && base != ""
+}
+
+// forward sends the request to the upstream URL and returns its response
+// as a fixture
+func (p *Proxy) forward(r *http.Request, upstreamURL string, body []byte) (*Fixture, error) {
+	if r.URL.RawQuery != "" {
+		upstreamURL += "?" + r.URL.RawQuery
+	}
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, upstreamURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = r.Header.Clone()
+	// Let the transport negotiate compression so fixtures are plain text
+	req.Header.Del("Accept-Encoding")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// #nosec G704 -- request to a configured engine API
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &Fixture{
+		Method:      r.Method,
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(respBody),
+		RecordedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// stripCredentials returns the query without API keys
+func stripCredentials(query url.Values) url.Values {
+	stripped := url.Values{}
+	for name, values := range query {
+		if !slices.Contains(credentialParams, strings.ToLower(name)) {
+			stripped[name] = values
+		}
+	}
+	return stripped
+}
+
+// redact replaces the API keys of the request wherever they occur in the
+// fixture, such as in echoed request parameters
+func redact(fixture *Fixture, r *http.Request) {
+	var secrets []string
+	for _, name := range credentialParams {
+		secrets = append(secrets, r.URL.Query().Get(name))
+	}
+	for _, name := range credentialHeaders {
+		value := r.Header.Get(name)
+		if _, token, ok := strings.Cut(value, " "); ok {
+			value = token
+		}
+		secrets = append(secrets, value)
+	}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		fixture.Body = strings.ReplaceAll(fixture.Body, secret, omniserp.Redacted)
+		fixture.RequestBody = strings.ReplaceAll(fixture.RequestBody, secret, omniserp.Redacted)
+	}
+}
+
+// fixtureKey identifies a request by its method, path, query without API
+// keys, and body. url.Values.Encode sorts the parameters.
+func fixtureKey(method, path string, query url.Values, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", method, path, query.Encode())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// fixturePath returns the file of a fixture
+func (p *Proxy) fixturePath(engine, key string) string {
+	return filepath.Join(p.Dir, engine, key+".json")
+}
+
+// load reads the fixture of a request
+func (p *Proxy) load(engine, key string) (*Fixture, error) {
+	// #nosec G304 -- engine names are checked against the upstreams and keys are hashes
+	data, err := os.ReadFile(p.fixturePath(engine, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotRecorded
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", key, err)
+	}
+	return &fixture, nil
+}
+
+// save writes the fixture of a request, replacing an earlier one
+func (p *Proxy) save(key string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	path := p.fixturePath(fixture.Engine, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// writeFixture writes the recorded response, dated when it was recorded so
+// results normalized from it keep their original fetch time
+func writeFixture(w http.ResponseWriter, fixture *Fixture) {
+	if fixture.ContentType != "" {
+		w.Header().Set("Content-Type", fixture.ContentType)
+	}
+	if !fixture.RecordedAt.IsZero() {
+		w.Header().Set("Date", fixture.RecordedAt.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(fixture.Status)
+	_, _ = io.WriteString(w, fixture.Body)
+}
@@ -0,0 +1,185 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client/mojeek"
+)
+
+// newUpstream serves Mojeek responses that echo the API key, as some APIs
+// echo request parameters, and counts the requests
+func newUpstream(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"response":{"status":"OK","head":{"total":1,"key":"` + r.URL.Query().Get("api_key") + `"},"results":[{"title":"Go","url":"https://go.dev/","desc":"` + r.URL.Query().Get("q") + `"}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// newEngine returns a Mojeek engine with apiKey sending requests to proxy
+func newEngine(t *testing.T, proxy *Proxy, apiKey string) *mojeek.Engine {
+	t.Helper()
+	srv := httptest.NewServer(proxy)
+	t.Cleanup(srv.Close)
+	engine, err := mojeek.NewWithAPIKey(apiKey)
+	if err != nil {
+		t.Fatalf("NewWithAPIKey failed: %v", err)
+	}
+	engine.SetBaseURL(srv.URL + "/mojeek")
+	return engine
+}
+
+func TestRecordReplay(t *testing.T) {
+	upstream, calls := newUpstream(t)
+	dir := t.TempDir()
+	upstreams := map[string]string{"mojeek": upstream.URL}
+	params := omniserp.SearchParams{Query: "golang"}
+
+	recording := newEngine(t, &Proxy{Dir: dir, Mode: ModeRecord, Upstreams: upstreams}, "secret-key")
+	if _, err := recording.Search(context.Background(), params); err != nil {
+		t.Fatalf("Search failed while recording: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mojeek", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 fixture, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	if strings.Contains(string(data), "secret-key") || !strings.Contains(string(data), omniserp.Redacted) {
+		t.Errorf("Expected the API key to be redacted from the fixture, got %s", data)
+	}
+
+	// Replays are dated when they were recorded
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+	recordedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fixture.RecordedAt = recordedAt
+	if data, err = json.Marshal(fixture); err != nil {
+		t.Fatalf("Failed to encode fixture: %v", err)
+	}
+	if err := os.WriteFile(files[0], data, 0o600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	// Replays match without credentials and never call the upstream
+	replaying := newEngine(t, &Proxy{Dir: dir, Mode: ModeReplay, Upstreams: upstreams}, "placeholder")
+	result, err := replaying.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search failed while replaying: %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", *calls)
+	}
+	normalized, err := omniserp.NewNormalizer("mojeek").NormalizeSearch(result, params.Query)
+	if err != nil || len(normalized.OrganicResults) != 1 || normalized.OrganicResults[0].Snippet != "golang" {
+		t.Errorf("Expected the recorded result, got %+v, %v", normalized, err)
+	}
+	if err == nil && !normalized.OrganicResults[0].FetchedAt.Equal(recordedAt) {
+		t.Errorf("Expected the recorded fetch time, got %v", normalized.OrganicResults[0].FetchedAt)
+	}
+
+	_, err = replaying.Search(context.Background(), omniserp.SearchParams{Query: "rust"})
+	var apiErr *omniserp.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || *calls != 1 {
+		t.Errorf("Expected a 404 for an unrecorded request without an upstream call, got %v", err)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	upstream, calls := newUpstream(t)
+	engine := newEngine(t, &Proxy{Dir: t.TempDir(), Mode: ModeAuto, Upstreams: map[string]string{"mojeek": upstream.URL}}, "key")
+
+	for _, query := range []string{"golang", "golang", "rust"} {
+		if _, err := engine.Search(context.Background(), omniserp.SearchParams{Query: query}); err != nil {
+			t.Fatalf("Search %q failed: %v", query, err)
+		}
+	}
+	if *calls != 2 {
+		t.Errorf("Expected only the unrecorded requests upstream, got %d calls", *calls)
+	}
+}
+
+func TestUnknownEngine(t *testing.T) {
+	srv := httptest.NewServer(&Proxy{Dir: t.TempDir(), Upstreams: map[string]string{}})
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/nope/search")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ParseMode("Replay"); err != nil || mode != ModeReplay {
+		t.Errorf("Expected replay, got %q, %v", mode, err)
+	}
+	if _, err := ParseMode("stream"); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+}
+
+func TestDuckDuckGoUpstream(t *testing.T) {
+	tests := map[string]string{
+		"/html/?q=go":            "https://html.duckduckgo.com",
+		"/?q=go&format=json":     "https://api.duckduckgo.com",
+		"/?q=go&ia=news":         "https://duckduckgo.com",
+		"/news.js?q=go&vqd=4-12": "https://duckduckgo.com",
+	}
+	for target, want := range tests {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if got := duckDuckGoUpstream(r.URL.Path, r.URL.Query()); got != want {
+			t.Errorf("%s: expected %s, got %s", target, want, got)
+		}
+	}
+}
+
+func TestJinaUpstream(t *testing.T) {
+	tests := map[string]string{
+		"/":                         "https://s.jina.ai",
+		"/https://go.dev/doc/":      "https://r.jina.ai",
+		"/http://example.com/a?b=1": "https://r.jina.ai",
+	}
+	for path, want := range tests {
+		if got := jinaUpstream(path); got != want {
+			t.Errorf("%s: expected %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestWikipediaUpstream(t *testing.T) {
+	tests := map[string]string{
+		"/en/w/api.php": "https://en.wikipedia.org/w/api.php",
+		"/zh-min-nan/api/rest_v1/page/summary/Go": "https://zh-min-nan.wikipedia.org/api/rest_v1/page/summary/Go",
+		"/evil.example/w/api.php":                 "",
+		"/":                                       "",
+	}
+	for path, want := range tests {
+		got, ok := wikipediaUpstream(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package omniserp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestFollowRedirects(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+	mux.Handle("/b", http.RedirectHandler("/final", http.StatusFound))
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/away", http.RedirectHandler(other.URL+"/elsewhere", http.StatusFound))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	follow := func(path string, params ScrapeParams) (*http.Response, []string, error) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("NewRequest failed: %v", err)
+		}
+		resp, chain, err := FollowRedirects(server.Client(), req, params)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, chain, err
+	}
+
+	resp, chain, err := follow("/a", ScrapeParams{})
+	if err != nil {
+		t.Fatalf("FollowRedirects failed: %v", err)
+	}
+	want := []string{server.URL + "/a", server.URL + "/b", server.URL + "/final"}
+	if !slices.Equal(chain, want) || resp.Request.URL.String() != want[2] {
+		t.Errorf("Expected chain %v, got %v", want, chain)
+	}
+
+	if _, chain, _ := follow("/final", ScrapeParams{}); chain != nil {
+		t.Errorf("Expected no chain without redirects, got %v", chain)
+	}
+
+	if _, _, err := follow("/a", ScrapeParams{MaxRedirects: 1}); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("Expected ErrTooManyRedirects, got %v", err)
+	}
+	if _, _, err := follow("/a", ScrapeParams{MaxRedirects: -1}); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("Expected redirects to be disallowed, got %v", err)
+	}
+
+	if _, _, err := follow("/away", ScrapeParams{SameOrigin: true}); !errors.Is(err, ErrCrossOriginRedirect) {
+		t.Errorf("Expected ErrCrossOriginRedirect, got %v", err)
+	}
+	if _, chain, err := follow("/away", ScrapeParams{}); err != nil || len(chain) != 2 {
+		t.Errorf("Expected cross-origin redirect to be followed by default, got %v %v", chain, err)
+	}
+}
+
+func TestNewPublicClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the loopback server not to be reached")
+	}))
+	defer server.Close()
+
+	resp, err := NewPublicClient().Get(server.URL)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Expected ErrPrivateAddress, got %v", err)
+	}
+
+	cases := map[string]bool{
+		"93.184.215.14":        true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.0.0.1":             false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.215.14": true,
+	}
+	for addr, want := range cases {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/plexusone/omniserp"
+)
+
+// ContextOptions configures FormatContext
+type ContextOptions struct {
+	// MaxTokens is the approximate token budget of the block, estimated with
+	// omniserp.EstimateTokens (0 means no limit). Sources that do not fit
+	// are dropped, lowest ranked first.
+	MaxTokens int
+
+	// MaxSources limits the number of numbered sources (0 means no limit)
+	MaxSources int
+}
+
+// contextSource is one numbered source of a context block
+type contextSource struct {
+	Title   string
+	Link    string
+	Snippet string
+}
+
+// FormatContext renders a normalized result as a compact Markdown block for
+// injecting into LLM prompts: the query, a direct answer if any, and
+// numbered sources with title, URL, and snippet that the model can cite
+// as [1], [2], and so on.
+func FormatContext(result *omniserp.NormalizedSearchResult, opts *ContextOptions) string {
+	if result == nil {
+		return ""
+	}
+	if opts == nil {
+		opts = &ContextOptions{}
+	}
+
+	var b strings.Builder
+	if q := result.SearchMetadata.Query; q != "" {
+		fmt.Fprintf(&b, "Search results for %q:\n\n", q)
+	}
+	if answer := contextAnswer(result); answer != "" {
+		fmt.Fprintf(&b, "Answer: %s\n\n", answer)
+	}
+
+	used := omniserp.EstimateTokens(b.String())
+	for i, source := range contextSources(result) {
+		if opts.MaxSources > 0 && i >= opts.MaxSources {
+			break
+		}
+		entry := formatContextSource(i+1, source)
+		cost := omniserp.EstimateTokens(entry)
+		if opts.MaxTokens > 0 && used+cost > opts.MaxTokens {
+			break
+		}
+		b.WriteString(entry)
+		used += cost
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// formatContextSource renders one numbered source
+func formatContextSource(n int, source contextSource) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d] %s\n", n, oneLine(source.Title))
+	if source.Link != "" {
+		fmt.Fprintf(&b, "%s\n", source.Link)
+	}
+	if snippet := oneLine(source.Snippet); snippet != "" {
+		fmt.Fprintf(&b, "%s\n", snippet)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// contextAnswer returns the direct answer of a result, if any
+func contextAnswer(result *omniserp.NormalizedSearchResult) string {
+	if ab := result.AnswerBox; ab != nil {
+		if answer := joinNonEmpty(" — ", ab.Answer, ab.Snippet); answer != "" {
+			return oneLine(answer)
+		}
+	}
+	if kg := result.KnowledgeGraph; kg != nil && kg.Description != "" {
+		return oneLine(joinNonEmpty(": ", kg.Title, kg.Description))
+	}
+	return ""
+}
+
+// contextSources collects the cited results of a result in rank order
+func contextSources(result *omniserp.NormalizedSearchResult) []contextSource {
+	var sources []contextSource
+	for _, r := range result.OrganicResults {
+		sources = append(sources, contextSource{Title: r.Title, Link: r.Link, Snippet: r.Snippet})
+	}
+	for _, r := range result.NewsResults {
+		snippet := joinNonEmpty(" — ", joinNonEmpty(", ", r.Source, r.Date), r.Snippet)
+		sources = append(sources, contextSource{Title: r.Title, Link: r.Link, Snippet: snippet})
+	}
+	for _, r := range result.ScholarResults {
+		snippet := joinNonEmpty(" — ", joinNonEmpty(", ", strings.Join(r.Authors, ", "), r.Year), r.Snippet)
+		sources = append(sources, contextSource{Title: r.Title, Link: r.Link, Snippet: snippet})
+	}
+	return sources
+}
+
+// oneLine collapses whitespace, including newlines, into single spaces
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+)
+
+func TestFormatContext(t *testing.T) {
+	result := &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{Query: "go generics"},
+		AnswerBox:      &omniserp.AnswerBox{Answer: "Go 1.18"},
+		OrganicResults: []omniserp.OrganicResult{
+			{Position: 1, Title: "Tutorial: Getting started with generics", Link: "https://go.dev/doc/tutorial/generics", Snippet: "This tutorial\nintroduces the basics."},
+			{Position: 2, Title: "Type Parameters Proposal", Link: "https://go.googlesource.com/proposal", Snippet: "Type parameters for Go."},
+		},
+	}
+
+	out := FormatContext(result, nil)
+	for _, want := range []string{
+		"Search results for \"go generics\":\n\nAnswer: Go 1.18\n\n",
+		"[1] Tutorial: Getting started with generics\nhttps://go.dev/doc/tutorial/generics\nThis tutorial introduces the basics.\n",
+		"[2] Type Parameters Proposal\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Context missing %q\n%s", want, out)
+		}
+	}
+
+	budget := omniserp.EstimateTokens(out) - 5
+	trimmed := FormatContext(result, &ContextOptions{MaxTokens: budget})
+	if !strings.Contains(trimmed, "[1]") || strings.Contains(trimmed, "[2]") {
+		t.Errorf("Expected only the first source within %d tokens\n%s", budget, trimmed)
+	}
+	if got := omniserp.EstimateTokens(trimmed); got > budget {
+		t.Errorf("Context uses %d tokens, budget %d", got, budget)
+	}
+}
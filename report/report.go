@@ -0,0 +1,409 @@
+// Package report renders normalized search results into shareable Markdown or
+// HTML research reports with one section per SERP feature and a source list.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Format is the output format of a report
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// Page is scraped content attached to a report
+type Page struct {
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content"`
+}
+
+// Report bundles one or more normalized results and optional scraped pages
+type Report struct {
+	Title       string                             `json:"title"`
+	GeneratedAt time.Time                          `json:"generated_at"`
+	Results     []*omniserp.NormalizedSearchResult `json:"results"`
+	Pages       []Page                             `json:"pages,omitempty"`
+
+	// MaxContentLength truncates scraped page content to at most this many
+	// bytes, at a character boundary (0 means no limit)
+	MaxContentLength int `json:"-"`
+}
+
+// New creates a report for the given results
+func New(title string, results ...*omniserp.NormalizedSearchResult) *Report {
+	return &Report{
+		Title:       title,
+		GeneratedAt: time.Now().UTC(),
+		Results:     results,
+	}
+}
+
+// AddPage attaches scraped page content to the report
+func (r *Report) AddPage(page Page) {
+	r.Pages = append(r.Pages, page)
+}
+
+// Write renders the report in the given format
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatMarkdown, "md", "":
+		_, err := io.WriteString(w, r.Markdown())
+		return err
+	case FormatHTML:
+		return r.WriteHTML(w)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// item is a single entry in a report section
+type item struct {
+	Title  string
+	Link   string
+	Detail string
+}
+
+// section is a titled group of items or free text
+type section struct {
+	Title string
+	Text  string
+	Items []item
+}
+
+// query groups the sections generated for one normalized result
+type query struct {
+	Query    string
+	Engine   string
+	Sections []section
+}
+
+// document is the format-independent model rendered by Markdown and WriteHTML
+type document struct {
+	Title       string
+	GeneratedAt string
+	Queries     []query
+	Pages       []Page
+	Sources     []item
+}
+
+// build converts the report into its format-independent model
+func (r *Report) build() document {
+	doc := document{
+		Title:       r.Title,
+		GeneratedAt: r.GeneratedAt.Format(time.RFC1123),
+	}
+	if doc.Title == "" {
+		doc.Title = "Search Report"
+	}
+
+	sources := newSourceList()
+	for _, result := range r.Results {
+		if result == nil {
+			continue
+		}
+		doc.Queries = append(doc.Queries, query{
+			Query:    result.SearchMetadata.Query,
+			Engine:   result.SearchMetadata.Engine,
+			Sections: buildSections(result, sources),
+		})
+	}
+
+	for _, page := range r.Pages {
+		if r.MaxContentLength > 0 && len(page.Content) > r.MaxContentLength {
+			// Cut before the character at the limit so it is not split
+			cut := r.MaxContentLength
+			for cut > 0 && !utf8.RuneStart(page.Content[cut]) {
+				cut--
+			}
+			page.Content = page.Content[:cut] + "…"
+		}
+		doc.Pages = append(doc.Pages, page)
+		sources.add(page.Title, page.URL)
+	}
+
+	doc.Sources = sources.items
+	return doc
+}
+
+// buildSections creates one section per SERP feature present in the result
+func buildSections(result *omniserp.NormalizedSearchResult, sources *sourceList) []section {
+	var sections []section
+
+	if ab := result.AnswerBox; ab != nil {
+		text := ab.Answer
+		if text == "" {
+			text = ab.Snippet
+		}
+		sections = append(sections, section{Title: "Answer", Text: text, Items: linkItems(ab.Title, ab.Link)})
+		sources.add(ab.Title, ab.Link)
+	}
+
+	if kg := result.KnowledgeGraph; kg != nil {
+		s := section{Title: "Knowledge Graph", Text: joinNonEmpty(": ", kg.Title, kg.Description)}
+		keys := make([]string, 0, len(kg.Attributes))
+		for key := range kg.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s.Items = append(s.Items, item{Title: key, Detail: kg.Attributes[key]})
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.OrganicResults) > 0 {
+		s := section{Title: "Web Results"}
+		for _, r := range result.OrganicResults {
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Link, Detail: r.Snippet})
+			sources.add(r.Title, r.Link)
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.NewsResults) > 0 {
+		s := section{Title: "News"}
+		for _, r := range result.NewsResults {
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Link, Detail: joinNonEmpty(" · ", r.Source, r.Date, r.Snippet)})
+			sources.add(r.Title, r.Link)
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.ScholarResults) > 0 {
+		s := section{Title: "Scholarly Articles"}
+		for _, r := range result.ScholarResults {
+			detail := joinNonEmpty(" · ", strings.Join(r.Authors, ", "), r.Year, r.Source)
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Link, Detail: detail})
+			sources.add(r.Title, r.Link)
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.ShoppingResults) > 0 {
+		s := section{Title: "Shopping"}
+		for _, r := range result.ShoppingResults {
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Link, Detail: joinNonEmpty(" · ", r.Price, r.Source)})
+			sources.add(r.Title, r.Link)
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.PlaceResults) > 0 {
+		s := section{Title: "Places"}
+		for _, r := range result.PlaceResults {
+			rating := ""
+			if r.Rating > 0 {
+				rating = fmt.Sprintf("%.1f★ (%d reviews)", r.Rating, r.Reviews)
+			}
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Website, Detail: joinNonEmpty(" · ", r.Address, rating)})
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.VideoResults) > 0 {
+		s := section{Title: "Videos"}
+		for _, r := range result.VideoResults {
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.Link, Detail: joinNonEmpty(" · ", r.Channel, r.Duration)})
+			sources.add(r.Title, r.Link)
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.ImageResults) > 0 {
+		s := section{Title: "Images"}
+		for _, r := range result.ImageResults {
+			s.Items = append(s.Items, item{Title: r.Title, Link: r.ImageURL, Detail: r.Source})
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.PeopleAlsoAsk) > 0 {
+		s := section{Title: "People Also Ask"}
+		for _, r := range result.PeopleAlsoAsk {
+			s.Items = append(s.Items, item{Title: r.Question, Link: r.Link, Detail: r.Answer})
+		}
+		sections = append(sections, s)
+	}
+
+	if len(result.RelatedSearches) > 0 {
+		s := section{Title: "Related Searches"}
+		for _, r := range result.RelatedSearches {
+			s.Items = append(s.Items, item{Title: r.Query})
+		}
+		sections = append(sections, s)
+	}
+
+	return sections
+}
+
+// linkItems returns a single-item list for a link, or nil if the link is empty
+func linkItems(title, link string) []item {
+	if link == "" {
+		return nil
+	}
+	return []item{{Title: title, Link: link}}
+}
+
+// joinNonEmpty joins the non-empty values with sep
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// sourceList is an ordered, de-duplicated list of cited links
+type sourceList struct {
+	seen  map[string]bool
+	items []item
+}
+
+func newSourceList() *sourceList {
+	return &sourceList{seen: make(map[string]bool)}
+}
+
+func (s *sourceList) add(title, link string) {
+	if link == "" || s.seen[link] {
+		return
+	}
+	s.seen[link] = true
+	if title == "" {
+		title = link
+	}
+	s.items = append(s.items, item{Title: title, Link: link})
+}
+
+// Markdown renders the report as Markdown
+func (r *Report) Markdown() string {
+	doc := r.build()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	fmt.Fprintf(&b, "_Generated %s_\n\n", doc.GeneratedAt)
+
+	for _, q := range doc.Queries {
+		fmt.Fprintf(&b, "## %s\n\n", q.Query)
+		if q.Engine != "" {
+			fmt.Fprintf(&b, "Engine: `%s`\n\n", q.Engine)
+		}
+		for _, s := range q.Sections {
+			fmt.Fprintf(&b, "### %s\n\n", s.Title)
+			if s.Text != "" {
+				fmt.Fprintf(&b, "%s\n\n", s.Text)
+			}
+			for _, it := range s.Items {
+				b.WriteString("- ")
+				b.WriteString(markdownLink(it.Title, it.Link))
+				if it.Detail != "" {
+					b.WriteString(" — ")
+					b.WriteString(it.Detail)
+				}
+				b.WriteString("\n")
+			}
+			if len(s.Items) > 0 {
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if len(doc.Pages) > 0 {
+		b.WriteString("## Scraped Content\n\n")
+		for _, p := range doc.Pages {
+			title := p.Title
+			if title == "" {
+				title = p.URL
+			}
+			fmt.Fprintf(&b, "### %s\n\n", title)
+			fmt.Fprintf(&b, "<%s>\n\n", markdownURL.Replace(p.URL))
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(p.Content))
+		}
+	}
+
+	if len(doc.Sources) > 0 {
+		b.WriteString("## Sources\n\n")
+		for i, s := range doc.Sources {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, markdownLink(s.Title, s.Link))
+		}
+	}
+
+	return b.String()
+}
+
+// markdownTitle escapes the link text of a Markdown link
+var markdownTitle = strings.NewReplacer("\\", "\\\\", "[", "\\[", "]", "\\]")
+
+// markdownURL percent-encodes the characters that end a Markdown link
+// destination or autolink
+var markdownURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownLink formats a Markdown link, falling back to plain text without a URL
+func markdownLink(title, link string) string {
+	title = markdownTitle.Replace(title)
+	if link == "" {
+		return title
+	}
+	return fmt.Sprintf("[%s](%s)", title, markdownURL.Replace(link))
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+.detail { color: #555; }
+pre { white-space: pre-wrap; background: #f6f8fa; padding: 1rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p><em>Generated {{.GeneratedAt}}</em></p>
+{{range .Queries}}
+<h2>{{.Query}}</h2>
+{{if .Engine}}<p>Engine: <code>{{.Engine}}</code></p>{{end}}
+{{range .Sections}}
+<h3>{{.Title}}</h3>
+{{if .Text}}<p>{{.Text}}</p>{{end}}
+{{if .Items}}<ul>
+{{range .Items}}<li>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if .Detail}} <span class="detail">— {{.Detail}}</span>{{end}}</li>
+{{end}}</ul>{{end}}
+{{end}}
+{{end}}
+{{if .Pages}}
+<h2>Scraped Content</h2>
+{{range .Pages}}
+<h3>{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</h3>
+<p><a href="{{.URL}}">{{.URL}}</a></p>
+<pre>{{.Content}}</pre>
+{{end}}
+{{end}}
+{{if .Sources}}
+<h2>Sources</h2>
+<ol>
+{{range .Sources}}<li><a href="{{.Link}}">{{.Title}}</a></li>
+{{end}}</ol>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the report as a standalone HTML document
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r.build())
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/plexusone/omniserp"
+)
+
+func testResult() *omniserp.NormalizedSearchResult {
+	return &omniserp.NormalizedSearchResult{
+		OrganicResults: []omniserp.OrganicResult{
+			{Position: 1, Title: "Go", Link: "https://go.dev", Snippet: "The Go programming language"},
+			{Position: 2, Title: "Go Docs", Link: "https://go.dev/doc", Snippet: "Documentation"},
+		},
+		AnswerBox: &omniserp.AnswerBox{Answer: "Go is a programming language", Title: "Go", Link: "https://go.dev"},
+		RelatedSearches: []omniserp.RelatedSearch{
+			{Query: "golang tutorial"},
+		},
+		SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "golang"},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := New("Go Research", testResult())
+	r.AddPage(Page{URL: "https://go.dev/doc", Title: "Go Docs", Content: "Lots of documentation"})
+
+	md := r.Markdown()
+
+	for _, want := range []string{
+		"# Go Research",
+		"## golang",
+		"### Answer",
+		"### Web Results",
+		"- [Go Docs](https://go.dev/doc) — Documentation",
+		"### Related Searches",
+		"## Scraped Content",
+		"## Sources",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown output missing %q", want)
+		}
+	}
+
+	// Sources are de-duplicated: go.dev appears in the answer box and organic results
+	if n := strings.Count(md, ". [Go](https://go.dev)"); n != 1 {
+		t.Errorf("Expected https://go.dev once in sources, found %d times", n)
+	}
+}
+
+func TestHTMLEscapesContent(t *testing.T) {
+	result := testResult()
+	result.OrganicResults[0].Title = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	if err := New("Report", result).Write(&buf, FormatHTML); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "<script>") {
+		t.Error("HTML output contains unescaped script tag")
+	}
+	if !strings.Contains(out, "<h3>Web Results</h3>") {
+		t.Error("HTML output missing Web Results section")
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New("Report", testResult()).Write(&buf, "pdf"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestMaxContentLength(t *testing.T) {
+	r := New("Report", testResult())
+	r.AddPage(Page{URL: "https://example.com", Content: "Grüße aus Köln"})
+	// The limit falls inside the two-byte ü
+	r.MaxContentLength = 3
+
+	md := r.Markdown()
+	if !utf8.ValidString(md) {
+		t.Fatal("Markdown output is not valid UTF-8")
+	}
+	if !strings.Contains(md, "Gr…") || strings.Contains(md, "Grü") {
+		t.Errorf("Expected content cut before the split character, got %q", md)
+	}
+}
+
+func TestMarkdownLinkEscaping(t *testing.T) {
+	tests := []struct {
+		title, link, want string
+	}{
+		{"Go", "https://go.dev", "[Go](https://go.dev)"},
+		{"[draft] Go", "", `\[draft\] Go`},
+		{`C:\Go [1.25]`, "https://example.com/a b", `[C:\\Go \[1.25\]](https://example.com/a%20b)`},
+		{"Go (language)", "https://en.wikipedia.org/wiki/Go_(programming_language)", "[Go (language)](https://en.wikipedia.org/wiki/Go_%28programming_language%29)"},
+	}
+	for _, tt := range tests {
+		if got := markdownLink(tt.title, tt.link); got != tt.want {
+			t.Errorf("markdownLink(%q, %q) = %q, want %q", tt.title, tt.link, got, tt.want)
+		}
+	}
+
+	r := New("Report", testResult())
+	r.AddPage(Page{URL: "https://example.com/<a b>", Content: "Text"})
+	if md := r.Markdown(); !strings.Contains(md, "<https://example.com/%3Ca%20b%3E>") {
+		t.Errorf("Expected an escaped page autolink, got %q", md)
+	}
+}
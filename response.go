@@ -0,0 +1,91 @@
+package omniserp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestIDHeaders are the headers that carry a provider request ID, in
+// order of preference
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Amzn-Requestid", "Cf-Ray"}
+
+// rateLimitPrefixes are the prefixes of rate limit headers
+var rateLimitPrefixes = []string{"X-Ratelimit-", "X-Rate-Limit-", "Ratelimit"}
+
+// ResponseMeta describes the HTTP response an engine result was parsed from
+type ResponseMeta struct {
+	StatusCode int `json:"status_code"`
+
+	// RequestID is the provider's ID of the request, for bug reports
+	RequestID string `json:"request_id,omitempty"`
+
+	// Headers are the rate limit, Retry-After, and request ID headers
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Latency is the time from sending the request to reading the body
+	Latency time.Duration `json:"latency"`
+
+	// FetchedAt is when the response was produced, from its Date header if
+	// any, so responses replayed from a recording keep their original time
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+}
+
+// NewResponseMeta records the status code and headers of interest of resp
+func NewResponseMeta(resp *http.Response, latency time.Duration) *ResponseMeta {
+	meta := &ResponseMeta{StatusCode: resp.StatusCode, Latency: latency, FetchedAt: time.Now().UTC()}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		meta.FetchedAt = date.UTC()
+	}
+	for name, values := range resp.Header {
+		if len(values) == 0 || !interestingHeader(name) {
+			continue
+		}
+		if meta.Headers == nil {
+			meta.Headers = make(map[string]string)
+		}
+		meta.Headers[name] = values[0]
+	}
+	for _, name := range requestIDHeaders {
+		if id := resp.Header.Get(name); id != "" {
+			meta.RequestID = id
+			break
+		}
+	}
+	return meta
+}
+
+// interestingHeader reports whether a canonical header name is kept in
+// ResponseMeta.Headers
+func interestingHeader(name string) bool {
+	if name == "Retry-After" {
+		return true
+	}
+	for _, id := range requestIDHeaders {
+		if name == id {
+			return true
+		}
+	}
+	for _, prefix := range rateLimitPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIError is returned when an engine API responds with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+	Response   *ResponseMeta
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.Response != nil && e.Response.RequestID != "" {
+		return fmt.Sprintf("API error: status %d (request %s): %s", e.StatusCode, e.Response.RequestID, e.Body)
+	}
+	return fmt.Sprintf("API error: status %d: %s", e.StatusCode, e.Body)
+}
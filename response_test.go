@@ -0,0 +1,46 @@
+package omniserp
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewResponseMeta(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("Retry-After", "30")
+	resp.Header.Set("X-Request-ID", "req-123")
+	resp.Header.Set("Content-Type", "application/json")
+
+	meta := NewResponseMeta(resp, 250*time.Millisecond)
+	if meta.StatusCode != http.StatusTooManyRequests || meta.Latency != 250*time.Millisecond {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+	if meta.RequestID != "req-123" {
+		t.Errorf("Expected request ID req-123, got %q", meta.RequestID)
+	}
+	want := map[string]string{"X-Ratelimit-Remaining": "0", "Retry-After": "30", "X-Request-Id": "req-123"}
+	if len(meta.Headers) != len(want) {
+		t.Errorf("Expected headers %v, got %v", want, meta.Headers)
+	}
+	for name, value := range want {
+		if meta.Headers[name] != value {
+			t.Errorf("Header %s: expected %q, got %q", name, value, meta.Headers[name])
+		}
+	}
+
+	if meta.FetchedAt.IsZero() {
+		t.Error("Expected a fetch time without a Date header")
+	}
+	resp.Header.Set("Date", "Sun, 01 Jun 2025 12:00:00 GMT")
+	if fetched := NewResponseMeta(resp, 0).FetchedAt; !fetched.Equal(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the fetch time of the Date header, got %v", fetched)
+	}
+
+	err := &APIError{StatusCode: resp.StatusCode, Body: "slow down", Response: meta}
+	if msg := err.Error(); !strings.Contains(msg, "429") || !strings.Contains(msg, "req-123") {
+		t.Errorf("Expected the status and request ID in %q", msg)
+	}
+}
@@ -0,0 +1,135 @@
+package omniserp
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecentReviewWindow is the period before the newest review whose reviews
+// are counted as recent in a ReviewSummary
+const RecentReviewWindow = 90 * 24 * time.Hour
+
+// ReviewResult represents a review of a place or product
+type ReviewResult struct {
+	Position int     `json:"position"`
+	Rating   float64 `json:"rating,omitempty"`
+	Snippet  string  `json:"snippet,omitempty"`
+	Author   string  `json:"author,omitempty"`
+	Likes    int     `json:"likes,omitempty"`
+
+	// Date is the date as displayed, such as "3 weeks ago"; PublishedAt is
+	// the parsed date, or zero if unknown
+	Date        string    `json:"date,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitzero"`
+
+	// Provenance
+	Engine    string    `json:"engine,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+}
+
+// ReviewSummary holds aggregate statistics of a set of reviews
+type ReviewSummary struct {
+	// Count is the number of reviews with a rating
+	Count         int     `json:"count"`
+	AverageRating float64 `json:"average_rating"`
+
+	// Histogram counts the ratings rounded to whole stars, keyed 1 to 5
+	Histogram map[int]int `json:"histogram"`
+
+	// RecentCount and RecentAverage cover the rated reviews published within
+	// RecentReviewWindow of the newest one. Trend is the recent average minus
+	// the average of the older reviews, positive if ratings are improving,
+	// and zero unless there are both recent and older dated reviews.
+	RecentCount   int     `json:"recent_count,omitempty"`
+	RecentAverage float64 `json:"recent_average,omitempty"`
+	Trend         float64 `json:"trend,omitempty"`
+}
+
+// SummarizeReviews computes the statistics of reviews, or returns nil if
+// none of them has a rating
+func SummarizeReviews(reviews []ReviewResult) *ReviewSummary {
+	summary := &ReviewSummary{Histogram: make(map[int]int)}
+	var total float64
+	var newest time.Time
+	for _, review := range reviews {
+		if review.Rating <= 0 {
+			continue
+		}
+		summary.Count++
+		total += review.Rating
+		stars := min(max(int(math.Round(review.Rating)), 1), 5)
+		summary.Histogram[stars]++
+		if review.PublishedAt.After(newest) {
+			newest = review.PublishedAt
+		}
+	}
+	if summary.Count == 0 {
+		return nil
+	}
+	summary.AverageRating = round2(total / float64(summary.Count))
+	if newest.IsZero() {
+		return summary
+	}
+
+	var recentTotal, olderTotal float64
+	olderCount := 0
+	cutoff := newest.Add(-RecentReviewWindow)
+	for _, review := range reviews {
+		switch {
+		case review.Rating <= 0 || review.PublishedAt.IsZero():
+		case review.PublishedAt.After(cutoff):
+			summary.RecentCount++
+			recentTotal += review.Rating
+		default:
+			olderCount++
+			olderTotal += review.Rating
+		}
+	}
+	if summary.RecentCount > 0 {
+		summary.RecentAverage = round2(recentTotal / float64(summary.RecentCount))
+	}
+	if summary.RecentCount > 0 && olderCount > 0 {
+		summary.Trend = round2(summary.RecentAverage - olderTotal/float64(olderCount))
+	}
+	return summary
+}
+
+// round2 rounds to two decimals
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// relativeDateRe matches relative dates such as "3 weeks ago" or "a month ago"
+var relativeDateRe = regexp.MustCompile(`(?i)^(?:edited\s+)?(a|an|\d+)\s+(minute|hour|day|week|month|year)s?\s+ago$`)
+
+// parseReviewDate parses an ISO 8601 date or a relative date such as
+// "3 weeks ago" measured from now, and returns the zero time otherwise
+func parseReviewDate(iso, date string, now time.Time) time.Time {
+	if t, err := time.Parse(time.RFC3339, iso); err == nil {
+		return t.UTC()
+	}
+	m := relativeDateRe.FindStringSubmatch(strings.TrimSpace(date))
+	if m == nil {
+		return time.Time{}
+	}
+	n := 1
+	if v, err := strconv.Atoi(m[1]); err == nil {
+		n = v
+	}
+	switch strings.ToLower(m[2]) {
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute)
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour)
+	case "day":
+		return now.AddDate(0, 0, -n)
+	case "week":
+		return now.AddDate(0, 0, -7*n)
+	case "month":
+		return now.AddDate(0, -n, 0)
+	}
+	return now.AddDate(-n, 0, 0)
+}
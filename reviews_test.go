@@ -0,0 +1,57 @@
+package omniserp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeReviews(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC)
+	}
+	reviews := []ReviewResult{
+		{Rating: 5, PublishedAt: day(30)},
+		{Rating: 4, PublishedAt: day(1)},
+		{Rating: 4.6},
+		{Rating: 2, PublishedAt: day(1).AddDate(-1, 0, 0)},
+		{Rating: 1, PublishedAt: day(1).AddDate(-1, 0, 0)},
+		{Snippet: "No rating"},
+	}
+
+	summary := SummarizeReviews(reviews)
+	if summary.Count != 5 || summary.AverageRating != 3.32 {
+		t.Errorf("Expected 5 ratings averaging 3.32, got %+v", summary)
+	}
+	want := map[int]int{1: 1, 2: 1, 4: 1, 5: 2}
+	for stars, count := range want {
+		if summary.Histogram[stars] != count {
+			t.Errorf("Expected %d reviews with %d stars, got %d", count, stars, summary.Histogram[stars])
+		}
+	}
+	if summary.RecentCount != 2 || summary.RecentAverage != 4.5 || summary.Trend != 3 {
+		t.Errorf("Expected 2 recent reviews averaging 4.5, 3 above the older ones, got %+v", summary)
+	}
+
+	if SummarizeReviews([]ReviewResult{{Snippet: "No rating"}}) != nil {
+		t.Error("Expected no summary without ratings")
+	}
+	if summary := SummarizeReviews([]ReviewResult{{Rating: 4}}); summary.Trend != 0 || summary.RecentCount != 0 {
+		t.Errorf("Expected no trend without dates, got %+v", summary)
+	}
+}
+
+func TestParseReviewDate(t *testing.T) {
+	now := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	tests := map[[2]string]time.Time{
+		{"2026-06-01T10:00:00Z", "a month ago"}: time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC),
+		{"", "a month ago"}:                     now.AddDate(0, -1, 0),
+		{"", "3 weeks ago"}:                     now.AddDate(0, 0, -21),
+		{"", "Edited 2 years ago"}:              now.AddDate(-2, 0, 0),
+		{"", "last summer"}:                     {},
+	}
+	for in, want := range tests {
+		if got := parseReviewDate(in[0], in[1], now); !got.Equal(want) {
+			t.Errorf("parseReviewDate(%q, %q) = %v, want %v", in[0], in[1], got, want)
+		}
+	}
+}
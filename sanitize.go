@@ -0,0 +1,219 @@
+package omniserp
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces secrets and account identifiers in sanitized results
+const Redacted = "[REDACTED]"
+
+// DefaultSanitizedParams are the URL query parameters removed by
+// NewSanitizer: credentials and common click-tracking parameters
+var DefaultSanitizedParams = []string{
+	"api_key", "apikey", "access_token", "token",
+	"utm_*", "gclid", "fbclid", "msclkid", "ved", "ei", "usg",
+}
+
+// DefaultSanitizedFields are the JSON fields redacted by NewSanitizer,
+// which identify the account of an engine API
+var DefaultSanitizedFields = []string{
+	"api_key", "account_id", "account_email",
+}
+
+// Sanitizer removes secrets, tracking parameters, and account identifiers
+// from engine responses, so they can be logged, cached, or returned to
+// clients without leaking credentials. A nil Sanitizer leaves results
+// unchanged.
+type Sanitizer struct {
+	// Secrets are literal values, such as API keys, that are replaced with
+	// Redacted wherever they occur
+	Secrets []string
+
+	// Params are URL query parameters removed from URLs in the response. A
+	// trailing "*" matches parameters by prefix, as in "utm_*".
+	Params []string
+
+	// Fields are JSON object keys whose values are replaced with Redacted
+	Fields []string
+}
+
+// NewSanitizer creates a sanitizer with the default parameters and fields
+// that also redacts the given secrets; empty secrets are ignored
+func NewSanitizer(secrets ...string) *Sanitizer {
+	s := &Sanitizer{
+		Params: DefaultSanitizedParams,
+		Fields: DefaultSanitizedFields,
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			s.Secrets = append(s.Secrets, secret)
+		}
+	}
+	return s
+}
+
+// Sanitize returns a sanitized copy of result; result is not modified.
+// JSON in Raw is re-encoded compactly. Typed Data, such as a
+// *NormalizedSearchResult, keeps its type.
+func (s *Sanitizer) Sanitize(result *SearchResult) *SearchResult {
+	if s == nil || result == nil {
+		return result
+	}
+	sanitized := &SearchResult{
+		Data: s.sanitizeData(result.Data),
+		Raw:  s.sanitizeRaw(result.Raw),
+	}
+	if result.Response != nil {
+		response := *result.Response
+		if response.Headers != nil {
+			response.Headers = make(map[string]string, len(result.Response.Headers))
+			for name, value := range result.Response.Headers {
+				response.Headers[name] = s.SanitizeString(value)
+			}
+		}
+		sanitized.Response = &response
+	}
+	return sanitized
+}
+
+// SanitizeString redacts secrets in str and removes sanitized parameters if
+// str is a URL
+func (s *Sanitizer) SanitizeString(str string) string {
+	if s == nil {
+		return str
+	}
+	for _, secret := range s.Secrets {
+		if secret != "" {
+			str = strings.ReplaceAll(str, secret, Redacted)
+		}
+	}
+	if !strings.Contains(str, "://") || !strings.Contains(str, "?") {
+		return str
+	}
+	u, err := url.Parse(str)
+	if err != nil || u.RawQuery == "" {
+		return str
+	}
+	query := u.Query()
+	removed := false
+	for name := range query {
+		if s.sanitizedParam(name) {
+			query.Del(name)
+			removed = true
+		}
+	}
+	if !removed {
+		return str
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// sanitizedParam reports whether a query parameter is removed
+func (s *Sanitizer) sanitizedParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, param := range s.Params {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == param {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizedField reports whether a JSON field is redacted
+func (s *Sanitizer) sanitizedField(key string) bool {
+	for _, field := range s.Fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeValue sanitizes a decoded JSON value
+func (s *Sanitizer) sanitizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		sanitized := make(map[string]any, len(v))
+		for key, value := range v {
+			if s.sanitizedField(key) {
+				sanitized[key] = Redacted
+			} else {
+				sanitized[key] = s.sanitizeValue(value)
+			}
+		}
+		return sanitized
+	case []any:
+		sanitized := make([]any, len(v))
+		for i, value := range v {
+			sanitized[i] = s.sanitizeValue(value)
+		}
+		return sanitized
+	case string:
+		return s.SanitizeString(v)
+	default:
+		return v
+	}
+}
+
+// sanitizeData sanitizes response data. Typed data is sanitized through its
+// JSON encoding and decoded back into its type; if that fails, the
+// sanitized JSON values are returned instead. Data that cannot be encoded
+// as JSON is dropped rather than returned unsanitized.
+func (s *Sanitizer) sanitizeData(data any) any {
+	switch data.(type) {
+	case nil, map[string]any, []any, string:
+		return s.sanitizeValue(data)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil
+	}
+	generic = s.sanitizeValue(generic)
+	if b, err = json.Marshal(generic); err != nil {
+		return generic
+	}
+
+	t := reflect.TypeOf(data)
+	if t.Kind() == reflect.Pointer {
+		typed := reflect.New(t.Elem())
+		if err := json.Unmarshal(b, typed.Interface()); err != nil {
+			return generic
+		}
+		return typed.Interface()
+	}
+	typed := reflect.New(t)
+	if err := json.Unmarshal(b, typed.Interface()); err != nil {
+		return generic
+	}
+	return typed.Elem().Interface()
+}
+
+// sanitizeRaw sanitizes a raw response body, which is usually JSON
+func (s *Sanitizer) sanitizeRaw(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	var generic any
+	if err := json.Unmarshal([]byte(raw), &generic); err != nil {
+		return s.SanitizeString(raw)
+	}
+	b, err := json.Marshal(s.sanitizeValue(generic))
+	if err != nil {
+		return s.SanitizeString(raw)
+	}
+	return string(b)
+}
@@ -0,0 +1,68 @@
+package omniserp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	s := NewSanitizer("secret-key", "")
+	result := &SearchResult{
+		Data: map[string]any{
+			"organic": []any{
+				map[string]any{"link": "https://example.com/page?id=7&utm_source=serp&gclid=abc"},
+			},
+			"search_metadata": map[string]any{
+				"json_endpoint": "https://serpapi.com/search.json?q=go&api_key=secret-key",
+				"account_id":    "acct-42",
+			},
+			"note": "key secret-key leaked",
+		},
+		Raw:      `{"account_email": "me@example.com", "next": "https://serpapi.com/search?q=go&api_key=secret-key"}`,
+		Response: &ResponseMeta{StatusCode: 200, Headers: map[string]string{"X-Echo": "secret-key"}},
+	}
+
+	sanitized := s.Sanitize(result)
+	data := sanitized.Data.(map[string]any)
+	if link := data["organic"].([]any)[0].(map[string]any)["link"]; link != "https://example.com/page?id=7" {
+		t.Errorf("Expected tracking parameters removed, got %v", link)
+	}
+	meta := data["search_metadata"].(map[string]any)
+	if meta["json_endpoint"] != "https://serpapi.com/search.json?q=go" {
+		t.Errorf("Expected the API key parameter removed, got %v", meta["json_endpoint"])
+	}
+	if meta["account_id"] != Redacted {
+		t.Errorf("Expected the account ID redacted, got %v", meta["account_id"])
+	}
+	if data["note"] != "key [REDACTED] leaked" {
+		t.Errorf("Expected the secret redacted, got %v", data["note"])
+	}
+	if strings.Contains(sanitized.Raw, "secret-key") || strings.Contains(sanitized.Raw, "me@example.com") {
+		t.Errorf("Expected the raw response sanitized, got %s", sanitized.Raw)
+	}
+	if sanitized.Response.Headers["X-Echo"] != Redacted {
+		t.Errorf("Expected the header redacted, got %q", sanitized.Response.Headers["X-Echo"])
+	}
+
+	// The input is not modified
+	if result.Data.(map[string]any)["note"] != "key secret-key leaked" || result.Response.Headers["X-Echo"] != "secret-key" {
+		t.Error("Sanitize modified its input")
+	}
+
+	// Typed data keeps its type
+	typed := s.Sanitize(&SearchResult{Data: &NormalizedSearchResult{
+		OrganicResults: []OrganicResult{{Link: "https://example.com/?utm_medium=email"}},
+	}})
+	normalized, ok := typed.Data.(*NormalizedSearchResult)
+	if !ok {
+		t.Fatalf("Expected *NormalizedSearchResult, got %T", typed.Data)
+	}
+	if link := normalized.OrganicResults[0].Link; link != "https://example.com/" {
+		t.Errorf("Expected tracking parameters removed, got %q", link)
+	}
+
+	var nilSanitizer *Sanitizer
+	if nilSanitizer.Sanitize(result) != result {
+		t.Error("Expected a nil sanitizer to return the result unchanged")
+	}
+}
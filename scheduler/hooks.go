@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/export"
+)
+
+// WebhookHook posts runs with new results as Slack-compatible JSON: a
+// summary in "text" and the run, without its full result, in "run"
+func WebhookHook(url string, httpClient *http.Client) Hook {
+	return func(ctx context.Context, run *Run) error {
+		payload, err := defaultPayload(run)
+		if err != nil {
+			return err
+		}
+		return postWebhook(ctx, httpClient, url, "application/json", nil, payload)
+	}
+}
+
+// WebhookData is the data that the webhook templates of saved searches are
+// executed with
+type WebhookData struct {
+	Search client.SavedSearch // the saved search of the run
+	Run    *Run
+	New    []export.Record // the new results of the run
+	Count  int             // the number of new results
+}
+
+// SavedWebhookHook posts runs with new results to the webhook of their saved
+// search, rendered with its template or as the payload of WebhookHook
+// without one. Runs of saved searches without a webhook are skipped.
+func SavedWebhookHook(searches *client.SavedSearches, httpClient *http.Client) Hook {
+	return func(ctx context.Context, run *Run) error {
+		search, ok := searches.Get(run.Search)
+		if !ok || search.Webhook == nil {
+			return nil
+		}
+		webhook := search.Webhook
+
+		var payload []byte
+		if webhook.Template == "" {
+			var err error
+			if payload, err = defaultPayload(run); err != nil {
+				return err
+			}
+		} else {
+			tmpl, err := webhook.ParseTemplate()
+			if err != nil {
+				return fmt.Errorf("failed to parse webhook template of saved search %s: %w", run.Search, err)
+			}
+			var buf bytes.Buffer
+			data := WebhookData{Search: search, Run: run, New: run.New, Count: len(run.New)}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return fmt.Errorf("failed to render webhook template of saved search %s: %w", run.Search, err)
+			}
+			payload = buf.Bytes()
+		}
+
+		contentType := webhook.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		return postWebhook(ctx, httpClient, webhook.URL, contentType, webhook.Headers, payload)
+	}
+}
+
+// defaultPayload returns the Slack-compatible payload of a run
+func defaultPayload(run *Run) ([]byte, error) {
+	summary := *run
+	summary.Result = nil
+	payload, err := json.Marshal(map[string]any{
+		"text": fmt.Sprintf("%d new results for saved search %s", len(run.New), run.Search),
+		"run":  summary,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run: %w", err)
+	}
+	return payload, nil
+}
+
+// postWebhook posts a payload and checks for a 2xx response
+func postWebhook(ctx context.Context, httpClient *http.Client, url, contentType string, headers map[string]string, payload []byte) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	// #nosec G704 -- webhook URL is configured by the operator
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error: %s: %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// ExportHook appends the new results of runs to a JSON Lines file as
+// export records
+func ExportHook(path string) Hook {
+	var mu sync.Mutex
+	return func(ctx context.Context, run *Run) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// #nosec G304 -- export path is provided by the operator
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open export file: %w", err)
+		}
+		defer f.Close()
+		return export.NewJSONLWriter(f).WriteRecords(run.New)
+	}
+}
@@ -0,0 +1,268 @@
+// Package scheduler runs saved searches on their cron schedules, records
+// every run in a history file, and notifies hooks when a run finds results
+// that earlier runs of the same search did not.
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/cron"
+	"github.com/plexusone/omniserp/export"
+)
+
+// Run is the outcome of one run of a saved search
+type Run struct {
+	Search string    `json:"search"`
+	RanAt  time.Time `json:"ran_at"`
+	Error  string    `json:"error,omitempty"`
+
+	// Result is the normalized result without the raw response
+	Result *omniserp.NormalizedSearchResult `json:"result,omitempty"`
+
+	// New are the results whose links were not returned by earlier runs.
+	// Baseline is set on the first run of a search, whose results are all
+	// new and do not trigger hooks.
+	New      []export.Record `json:"new,omitempty"`
+	Baseline bool            `json:"baseline,omitempty"`
+}
+
+// Hook is called after a run that found new results
+type Hook func(ctx context.Context, run *Run) error
+
+// History is an append-only JSON Lines file of runs. It remembers the links
+// seen per saved search so new results can be detected across restarts.
+type History struct {
+	path string
+
+	mu   sync.Mutex
+	seen map[string]map[string]bool
+}
+
+// OpenHistory opens the history file at path, creating it on the first
+// append if it does not exist
+func OpenHistory(path string) (*History, error) {
+	runs, err := ReadHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &History{path: path, seen: make(map[string]map[string]bool)}
+	for _, run := range runs {
+		h.remember(run)
+	}
+	return h, nil
+}
+
+// ReadHistory returns the runs in the history file at path in the order
+// they were recorded, or none if the file does not exist
+func ReadHistory(path string) ([]*Run, error) {
+	// #nosec G304 -- history path is provided by the caller
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var runs []*Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("failed to parse history %s line %d: %w", path, line, err)
+		}
+		runs = append(runs, &run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return runs, nil
+}
+
+// remember records the links of a run. The caller must hold the lock or
+// own the history exclusively.
+func (h *History) remember(run *Run) {
+	if run.Result == nil {
+		return
+	}
+	if h.seen[run.Search] == nil {
+		h.seen[run.Search] = make(map[string]bool)
+	}
+	for _, record := range export.Flatten(run.Result) {
+		if record.Link != "" {
+			h.seen[run.Search][record.Link] = true
+		}
+	}
+}
+
+// Record fills in the new results of a run, remembers its links, and
+// appends it to the history file
+func (h *History) Record(run *Run) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if run.Result != nil {
+		seen := h.seen[run.Search]
+		run.Baseline = seen == nil
+		for _, record := range export.Flatten(run.Result) {
+			if record.Link != "" && !seen[record.Link] {
+				run.New = append(run.New, record)
+			}
+		}
+		h.remember(run)
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	// #nosec G304 -- history path is provided by the caller
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Searcher runs saved searches; *client.Client implements it
+type Searcher interface {
+	RunSaved(ctx context.Context, name string) (*omniserp.NormalizedSearchResult, error)
+	SavedSearches() *client.SavedSearches
+}
+
+// Runner runs the saved searches of a client
+type Runner struct {
+	Client  Searcher
+	History *History // nil to keep no history; every run is then a baseline
+	Hooks   []Hook
+
+	// Observers are called after every successful run, including baselines
+	// and runs without new results, before the hooks
+	Observers []Hook
+
+	// Now returns the current time (default time.Now)
+	Now func() time.Time
+}
+
+// now returns the current time
+func (r *Runner) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// RunOnce runs a saved search, records it in the history, calls the
+// observers, and calls the hooks if it found new results. A failed search is
+// recorded and returned as the run error; observer and hook errors are
+// returned after all of them have run.
+func (r *Runner) RunOnce(ctx context.Context, name string) (*Run, error) {
+	run := &Run{Search: name, RanAt: r.now().UTC()}
+	result, err := r.Client.RunSaved(ctx, name)
+	if err != nil {
+		run.Error = err.Error()
+	} else {
+		result.Raw = nil
+		run.Result = result
+	}
+
+	if r.History != nil {
+		if herr := r.History.Record(run); herr != nil {
+			return run, herr
+		}
+	} else {
+		run.Baseline = true
+	}
+	if err != nil {
+		return run, err
+	}
+
+	var errs []error
+	for _, observer := range r.Observers {
+		if err := observer(ctx, run); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(run.New) == 0 || run.Baseline {
+		return run, errors.Join(errs...)
+	}
+	for _, hook := range r.Hooks {
+		if err := hook(ctx, run); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return run, errors.Join(errs...)
+}
+
+// Start runs the scheduled saved searches until ctx is cancelled. Searches
+// without a schedule are skipped. Due searches run one at a time, and
+// failures are logged without stopping the runner.
+func (r *Runner) Start(ctx context.Context) error {
+	type entry struct {
+		schedule string
+		next     time.Time
+	}
+	entries := make(map[string]*entry)
+
+	for {
+		now := r.now()
+		var wake time.Time
+		for _, saved := range r.Client.SavedSearches().List() {
+			if saved.Schedule == "" {
+				continue
+			}
+			schedule, err := cron.Parse(saved.Schedule)
+			if err != nil {
+				continue // rejected when the search was saved
+			}
+
+			e := entries[saved.Name]
+			if e == nil || e.schedule != saved.Schedule {
+				e = &entry{schedule: saved.Schedule, next: schedule.Next(now)}
+				entries[saved.Name] = e
+			}
+			if !e.next.IsZero() && !e.next.After(now) {
+				run, err := r.RunOnce(ctx, saved.Name)
+				switch {
+				case err != nil:
+					log.Printf("Saved search %s failed: %v", saved.Name, err)
+				case len(run.New) > 0 && !run.Baseline:
+					log.Printf("Saved search %s found %d new results", saved.Name, len(run.New))
+				}
+				e.next = schedule.Next(r.now())
+			}
+			if !e.next.IsZero() && (wake.IsZero() || e.next.Before(wake)) {
+				wake = e.next
+			}
+		}
+
+		// Check again within a minute so new schedules are picked up
+		delay := time.Minute
+		if !wake.IsZero() {
+			delay = min(max(wake.Sub(r.now()), 0), time.Minute)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
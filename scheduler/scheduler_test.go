@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omniserp"
+	"github.com/plexusone/omniserp/client"
+	"github.com/plexusone/omniserp/export"
+)
+
+// fakeSearcher returns canned organic links per run
+type fakeSearcher struct {
+	saved *client.SavedSearches
+	links [][]string
+	runs  int
+}
+
+func (f *fakeSearcher) RunSaved(ctx context.Context, name string) (*omniserp.NormalizedSearchResult, error) {
+	if f.runs >= len(f.links) {
+		return nil, errors.New("engine unavailable")
+	}
+	result := &omniserp.NormalizedSearchResult{SearchMetadata: omniserp.SearchMetadata{Query: name}}
+	for i, link := range f.links[f.runs] {
+		result.OrganicResults = append(result.OrganicResults, omniserp.OrganicResult{Position: i + 1, Title: link, Link: link})
+	}
+	f.runs++
+	return result, nil
+}
+
+func (f *fakeSearcher) SavedSearches() *client.SavedSearches {
+	return f.saved
+}
+
+func TestRunOnce(t *testing.T) {
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	history, err := OpenHistory(historyPath)
+	if err != nil {
+		t.Fatalf("OpenHistory failed: %v", err)
+	}
+
+	var notified, observed []*Run
+	searcher := &fakeSearcher{links: [][]string{
+		{"https://a.example", "https://b.example"},
+		{"https://a.example", "https://c.example"},
+		{"https://c.example", "https://d.example"},
+	}}
+	runner := &Runner{Client: searcher, History: history, Hooks: []Hook{
+		func(ctx context.Context, run *Run) error {
+			notified = append(notified, run)
+			return nil
+		},
+		ExportHook(filepath.Join(dir, "new.jsonl")),
+	}, Observers: []Hook{
+		func(ctx context.Context, run *Run) error {
+			observed = append(observed, run)
+			return nil
+		},
+	}}
+	ctx := context.Background()
+
+	run, err := runner.RunOnce(ctx, "acme")
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if !run.Baseline || len(notified) != 0 {
+		t.Errorf("Expected a baseline run without notification, got %+v", run)
+	}
+
+	run, err = runner.RunOnce(ctx, "acme")
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(run.New) != 1 || run.New[0].Link != "https://c.example" || len(notified) != 1 {
+		t.Errorf("Expected c.example to be new, got %+v", run.New)
+	}
+
+	// A reopened history remembers the links seen before
+	reopened, err := OpenHistory(historyPath)
+	if err != nil {
+		t.Fatalf("OpenHistory failed: %v", err)
+	}
+	runner.History = reopened
+	run, err = runner.RunOnce(ctx, "acme")
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(run.New) != 1 || run.New[0].Link != "https://d.example" {
+		t.Errorf("Expected d.example to be new, got %+v", run.New)
+	}
+
+	if _, err := runner.RunOnce(ctx, "acme"); err == nil {
+		t.Error("Expected the failed search to be returned")
+	}
+
+	if len(observed) != 3 {
+		t.Errorf("Expected every successful run to be observed, got %d", len(observed))
+	}
+
+	runs, err := ReadHistory(historyPath)
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	if len(runs) != 4 {
+		t.Fatalf("Expected 4 runs in the history, got %d", len(runs))
+	}
+	if !runs[0].Baseline || runs[3].Error != "engine unavailable" {
+		t.Errorf("Unexpected runs: %+v, %+v", runs[0], runs[3])
+	}
+	exported, _ := os.ReadFile(filepath.Join(dir, "new.jsonl"))
+	if strings.Count(string(exported), "\n") != 2 {
+		t.Errorf("Expected 2 exported records, got:\n%s", exported)
+	}
+}
+
+func TestWebhookHook(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	run := &Run{Search: "acme", Result: &omniserp.NormalizedSearchResult{}}
+	run.New = make([]export.Record, 2)
+	if err := WebhookHook(server.URL, nil)(context.Background(), run); err != nil {
+		t.Fatalf("WebhookHook failed: %v", err)
+	}
+	if payload["text"] != "2 new results for saved search acme" {
+		t.Errorf("Unexpected text: %v", payload["text"])
+	}
+	if _, ok := payload["run"].(map[string]any)["result"]; ok {
+		t.Error("Expected the full result to be omitted")
+	}
+}
+
+func TestSavedWebhookHook(t *testing.T) {
+	var body, contentType, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, contentType, token = string(data), r.Header.Get("Content-Type"), r.Header.Get("X-Token")
+	}))
+	defer server.Close()
+
+	saved := client.NewSavedSearches()
+	err := saved.Save(client.SavedSearch{
+		Name:   "acme",
+		Params: omniserp.SearchParams{Query: "acme \"corp\""},
+		Webhook: &client.SavedWebhook{
+			URL:      server.URL,
+			Template: `{"text": {{json (printf "%d new for %s" .Count .Search.Params.Query)}}, "links": [{{range $i, $r := .New}}{{if $i}}, {{end}}{{json (truncate 5 $r.Title)}}{{end}}]}`,
+			Headers:  map[string]string{"X-Token": "secret"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := saved.Save(client.SavedSearch{Name: "quiet", Params: omniserp.SearchParams{Query: "quiet"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	hook := SavedWebhookHook(saved, nil)
+	run := &Run{Search: "acme", New: []export.Record{{Title: "Acme launches"}, {Title: "Acme"}}}
+	if err := hook(context.Background(), run); err != nil {
+		t.Fatalf("SavedWebhookHook failed: %v", err)
+	}
+	var payload struct {
+		Text  string   `json:"text"`
+		Links []string `json:"links"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("Expected a JSON body, got %s: %v", body, err)
+	}
+	if payload.Text != `2 new for acme "corp"` || len(payload.Links) != 2 || payload.Links[0] != "Acme …" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if contentType != "application/json" || token != "secret" {
+		t.Errorf("Unexpected headers: %q, %q", contentType, token)
+	}
+
+	// Searches without a webhook are skipped
+	body = ""
+	if err := hook(context.Background(), &Run{Search: "quiet", New: run.New}); err != nil || body != "" {
+		t.Errorf("Expected no request, got %q (%v)", body, err)
+	}
+}
@@ -0,0 +1,223 @@
+package omniserp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// skippedHTML matches elements whose content is not page text. They are
+// removed before parsing because scripts often contain markup-like text.
+var skippedHTML = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)<script\b.*?</script\s*>`),
+	regexp.MustCompile(`(?is)<style\b.*?</style\s*>`),
+	regexp.MustCompile(`(?is)<noscript\b.*?</noscript\s*>`),
+	regexp.MustCompile(`(?is)<template\b.*?</template\s*>`),
+	regexp.MustCompile(`(?is)<svg\b.*?</svg\s*>`),
+}
+
+// htmlTag matches any tag, for the plain-text fallback
+var htmlTag = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// markdownLink matches the target of an absolute markdown link
+var markdownLink = regexp.MustCompile(`\]\((https?://[^)\s]+)`)
+
+// blockElements start a new line of text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+// ContentHash returns "sha256:" followed by the hex digest of text
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ParseHTML extracts the title, text, meta tags, and absolute links of an
+// HTML page. Relative links are resolved against pageURL. Malformed markup
+// is tolerated; if it cannot be parsed at all, the text is the markup with
+// tags removed.
+func ParseHTML(pageURL string, body []byte) *NormalizedScrapeResult {
+	result := &NormalizedScrapeResult{URL: pageURL, Metadata: make(map[string]string)}
+	base, _ := url.Parse(pageURL)
+
+	for _, re := range skippedHTML {
+		body = re.ReplaceAll(body, nil)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var text, title strings.Builder
+	inTitle := false
+	parsed := true
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			parsed = err == io.EOF
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch name {
+			case "title":
+				inTitle = true
+			case "meta":
+				addMeta(result.Metadata, t.Attr)
+			case "a":
+				if link := resolveLink(base, attr(t.Attr, "href")); link != "" && !slices.Contains(result.Links, link) {
+					result.Links = append(result.Links, link)
+				}
+			}
+			if blockElements[name] {
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == "title" {
+				inTitle = false
+			}
+			if blockElements[name] {
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inTitle {
+				title.Write(t)
+			} else {
+				text.Write(t)
+			}
+		}
+	}
+
+	result.Title = strings.Join(strings.Fields(title.String()), " ")
+	result.Text = cleanText(text.String())
+	if !parsed && result.Text == "" {
+		result.Text = cleanText(htmlTag.ReplaceAllString(string(body), "\n"))
+	}
+	if result.Title == "" {
+		result.Title = result.Metadata["og:title"]
+	}
+	if len(result.Metadata) == 0 {
+		result.Metadata = nil
+	}
+	result.ContentHash = ContentHash(result.Text)
+	return result
+}
+
+// MarkdownLinks returns the distinct absolute link targets in markdown
+func MarkdownLinks(markdown string) []string {
+	var links []string
+	for _, match := range markdownLink.FindAllStringSubmatch(markdown, -1) {
+		if !slices.Contains(links, match[1]) {
+			links = append(links, match[1])
+		}
+	}
+	return links
+}
+
+// NormalizeScrape returns the normalized scrape result of an engine
+// response. Engines in this module return a *NormalizedScrapeResult as the
+// Data; map responses from other engines are converted on a best-effort
+// basis using their "text", "markdown", or "content" fields.
+func NormalizeScrape(result *SearchResult, pageURL string) (*NormalizedScrapeResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, fmt.Errorf("nil result or data")
+	}
+
+	switch data := result.Data.(type) {
+	case *NormalizedScrapeResult:
+		return data, nil
+	case map[string]any:
+		scraped := &NormalizedScrapeResult{
+			URL:      pageURL,
+			Text:     getString(data, "text"),
+			Markdown: getString(data, "markdown"),
+			Title:    getString(data, "title"),
+		}
+		if scraped.Text == "" {
+			if content := getString(data, "content"); content != "" {
+				scraped = ParseHTML(pageURL, []byte(content))
+			}
+		}
+		if scraped.Text == "" {
+			scraped.Text = scraped.Markdown
+		}
+		scraped.ContentHash = ContentHash(scraped.Text)
+		return scraped, nil
+	default:
+		return nil, fmt.Errorf("unexpected data type: %T", result.Data)
+	}
+}
+
+// addMeta records a <meta name|property=... content=...> tag
+func addMeta(metadata map[string]string, attrs []xml.Attr) {
+	key := attr(attrs, "name")
+	if key == "" {
+		key = attr(attrs, "property")
+	}
+	content := strings.TrimSpace(attr(attrs, "content"))
+	if key != "" && content != "" {
+		metadata[strings.ToLower(key)] = content
+	}
+}
+
+// attr returns the value of the named attribute, ignoring case
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// resolveLink resolves href against base, returning only http(s) links
+// without fragments
+func resolveLink(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+	link, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		link = base.ResolveReference(link)
+	}
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return ""
+	}
+	link.Fragment = ""
+	return link.String()
+}
+
+// cleanText collapses whitespace within lines and drops empty lines
+func cleanText(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
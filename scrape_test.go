@@ -0,0 +1,76 @@
+package omniserp
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Go &amp; Generics</title>
+  <meta name="description" content="Type parameters in Go">
+  <meta property="og:type" content=article>
+  <style>body { color: red; }</style>
+  <script>if (a < b && c) { document.write("<p>hidden</p>") }</script>
+</head>
+<body>
+  <h1>Generics</h1>
+  <p>Go 1.18 added <a href="/doc/generics">type parameters</a>.<br>
+  See <a href="https://go.dev/blog/intro-generics#top">the blog</a> and <a href="mailto:x@y">mail</a>.
+  <p>Unclosed paragraph with <img src="x.png"> an image
+  <ul><li>One<li>Two</ul>
+</body>
+</html>`
+
+func TestParseHTML(t *testing.T) {
+	result := ParseHTML("https://go.dev/tour/", []byte(testPage))
+
+	if result.Title != "Go & Generics" {
+		t.Errorf("Expected title 'Go & Generics', got %q", result.Title)
+	}
+	if result.Metadata["description"] != "Type parameters in Go" || result.Metadata["og:type"] != "article" {
+		t.Errorf("Unexpected metadata: %v", result.Metadata)
+	}
+
+	wantLinks := []string{"https://go.dev/doc/generics", "https://go.dev/blog/intro-generics"}
+	if !slices.Equal(result.Links, wantLinks) {
+		t.Errorf("Expected links %v, got %v", wantLinks, result.Links)
+	}
+
+	for _, want := range []string{"Generics\n", "Go 1.18 added type parameters.", "Unclosed paragraph with an image", "One\nTwo"} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("Expected %q in text:\n%s", want, result.Text)
+		}
+	}
+	for _, unwanted := range []string{"hidden", "color"} {
+		if strings.Contains(result.Text, unwanted) {
+			t.Errorf("Unexpected %q in text:\n%s", unwanted, result.Text)
+		}
+	}
+	if result.ContentHash != ContentHash(result.Text) || !strings.HasPrefix(result.ContentHash, "sha256:") {
+		t.Errorf("Unexpected content hash %q", result.ContentHash)
+	}
+}
+
+func TestNormalizeScrape(t *testing.T) {
+	scraped, err := NormalizeScrape(&SearchResult{Data: map[string]any{"text": "Hello", "markdown": "# Hello"}}, "https://example.com")
+	if err != nil {
+		t.Fatalf("NormalizeScrape failed: %v", err)
+	}
+	if scraped.Text != "Hello" || scraped.Markdown != "# Hello" || scraped.URL != "https://example.com" {
+		t.Errorf("Unexpected result: %+v", scraped)
+	}
+
+	want := &NormalizedScrapeResult{URL: "https://example.com", Text: "Hi"}
+	if got, _ := NormalizeScrape(&SearchResult{Data: want}, ""); got != want {
+		t.Error("Expected normalized data to be returned as is")
+	}
+
+	links := MarkdownLinks("[a](https://a.dev/x) [b](/rel) [c](https://a.dev/x)")
+	if !slices.Equal(links, []string{"https://a.dev/x"}) {
+		t.Errorf("Unexpected markdown links: %v", links)
+	}
+}
@@ -0,0 +1,90 @@
+package omniserp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner(t *testing.T) {
+	var signature, timestamp, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		signature, timestamp = r.Header.Get("X-Gateway-Signature"), r.Header.Get("X-Timestamp")
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = io.WriteString(mac, StringToSign(r, data, timestamp))
+		if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{}
+	SignRequests(client, &HMACSigner{
+		Key:    []byte("secret"),
+		Header: "X-Gateway-Signature",
+		Now:    func() time.Time { return time.Unix(1760000000, 0) },
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/search?q=golang", strings.NewReader(`{"q":"golang"}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a valid signature, got status %d", resp.StatusCode)
+	}
+	if body != `{"q":"golang"}` || timestamp != "1760000000" || req.Header.Get("X-Gateway-Signature") != "" {
+		t.Errorf("Expected the body intact and the caller's request unmodified, got body %q, timestamp %q", body, timestamp)
+	}
+}
+
+func TestSignRequests(t *testing.T) {
+	var signedBy string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signedBy = r.Header.Get("X-Signer")
+	}))
+	defer srv.Close()
+
+	signer := func(name string) RequestSigner {
+		return RequestSignerFunc(func(req *http.Request, body []byte) error {
+			req.Header.Set("X-Signer", name)
+			return nil
+		})
+	}
+	get := func(client *http.Client) error {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	client := &http.Client{}
+	SignRequests(client, signer("first"))
+	SignRequests(client, signer("second"))
+	if err := get(client); err != nil || signedBy != "second" {
+		t.Errorf("Expected the second signer to replace the first, got %q, %v", signedBy, err)
+	}
+
+	SignRequests(client, nil)
+	if err := get(client); err != nil || signedBy != "" || client.Transport != nil {
+		t.Errorf("Expected a nil signer to stop signing, got %q, %v", signedBy, err)
+	}
+
+	errSign := errors.New("no key")
+	SignRequests(client, RequestSignerFunc(func(req *http.Request, body []byte) error { return errSign }))
+	if err := get(client); !errors.Is(err, errSign) {
+		t.Errorf("Expected the signing error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package sql
+
+import "strings"
+
+// Table names
+const (
+	TableMigrations      = "omniserp_schema_migrations"
+	TableSearches        = "omniserp_searches"
+	TableOrganicResults  = "omniserp_organic_results"
+	TableNewsResults     = "omniserp_news_results"
+	TableShoppingResults = "omniserp_shopping_results"
+	TablePlaceResults    = "omniserp_place_results"
+)
+
+// migration is one schema version. Statements are written with the
+// column types as {id}, {time}, and {float}, which expand per dialect.
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations are the schema versions in order. Applied migrations must not
+// change; schema changes are new versions.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE ` + TableSearches + ` (
+	id {id},
+	engine VARCHAR(64) NOT NULL,
+	query TEXT NOT NULL,
+	fingerprint VARCHAR(64),
+	location VARCHAR(255),
+	language VARCHAR(16),
+	country VARCHAR(16),
+	total_results BIGINT,
+	credits INTEGER,
+	corrected_query TEXT,
+	searched_at {time} NOT NULL
+)`,
+			`CREATE INDEX omniserp_searches_fingerprint ON ` + TableSearches + ` (fingerprint)`,
+			`CREATE INDEX omniserp_searches_searched_at ON ` + TableSearches + ` (searched_at)`,
+
+			`CREATE TABLE ` + TableOrganicResults + ` (
+	search_id BIGINT NOT NULL REFERENCES ` + TableSearches + ` (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	title TEXT,
+	link TEXT,
+	snippet TEXT,
+	domain VARCHAR(255),
+	date VARCHAR(64),
+	engine VARCHAR(64),
+	fetched_at {time}
+)`,
+			`CREATE INDEX omniserp_organic_results_search ON ` + TableOrganicResults + ` (search_id)`,
+			`CREATE INDEX omniserp_organic_results_domain ON ` + TableOrganicResults + ` (domain)`,
+
+			`CREATE TABLE ` + TableNewsResults + ` (
+	search_id BIGINT NOT NULL REFERENCES ` + TableSearches + ` (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	title TEXT,
+	link TEXT,
+	source VARCHAR(255),
+	date VARCHAR(64),
+	snippet TEXT,
+	engine VARCHAR(64),
+	fetched_at {time}
+)`,
+			`CREATE INDEX omniserp_news_results_search ON ` + TableNewsResults + ` (search_id)`,
+
+			`CREATE TABLE ` + TableShoppingResults + ` (
+	search_id BIGINT NOT NULL REFERENCES ` + TableSearches + ` (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	title TEXT,
+	link TEXT,
+	product_id VARCHAR(255),
+	price VARCHAR(64),
+	original_price VARCHAR(64),
+	currency VARCHAR(16),
+	rating {float},
+	reviews INTEGER,
+	source VARCHAR(255),
+	engine VARCHAR(64),
+	fetched_at {time}
+)`,
+			`CREATE INDEX omniserp_shopping_results_search ON ` + TableShoppingResults + ` (search_id)`,
+
+			`CREATE TABLE ` + TablePlaceResults + ` (
+	search_id BIGINT NOT NULL REFERENCES ` + TableSearches + ` (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	title TEXT,
+	place_id VARCHAR(255),
+	address TEXT,
+	phone VARCHAR(64),
+	website TEXT,
+	rating {float},
+	reviews INTEGER,
+	type VARCHAR(255),
+	latitude {float},
+	longitude {float},
+	engine VARCHAR(64),
+	fetched_at {time}
+)`,
+			`CREATE INDEX omniserp_place_results_search ON ` + TablePlaceResults + ` (search_id)`,
+		},
+	},
+}
+
+// columnTypes are the dialect types of the {id}, {time}, and {float}
+// placeholders of migrations
+var columnTypes = map[Dialect]*strings.Replacer{
+	Postgres: strings.NewReplacer(
+		"{id}", "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		"{time}", "TIMESTAMPTZ",
+		"{float}", "DOUBLE PRECISION",
+	),
+	MySQL: strings.NewReplacer(
+		"{id}", "BIGINT AUTO_INCREMENT PRIMARY KEY",
+		"{time}", "DATETIME(6)",
+		"{float}", "DOUBLE",
+	),
+	SQLite: strings.NewReplacer(
+		"{id}", "INTEGER PRIMARY KEY AUTOINCREMENT",
+		"{time}", "TIMESTAMP",
+		"{float}", "REAL",
+	),
+}
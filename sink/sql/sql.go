@@ -0,0 +1,250 @@
+// Package sql persists normalized search results in a SQL database, so
+// analysts can query SERP data directly with SQL. It works with Postgres,
+// MySQL, and SQLite through database/sql; the caller opens the database with
+// a driver of their choice, such as pgx, go-sql-driver/mysql, or
+// modernc.org/sqlite. Migrate creates and upgrades the schema, and Write
+// stores the organic, news, shopping, and place results of a search with
+// their provenance.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// Dialect identifies a SQL database
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+// Sink writes normalized results to a database
+type Sink struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// New creates a sink for a database of a dialect. Migrate must have been
+// run on the database before results are written.
+func New(db *sql.DB, dialect Dialect) (*Sink, error) {
+	if _, ok := columnTypes[dialect]; !ok {
+		return nil, fmt.Errorf("unsupported SQL dialect: %s", dialect)
+	}
+	return &Sink{db: db, dialect: dialect}, nil
+}
+
+// rebind converts the ? placeholders of a statement to the dialect's
+func (s *Sink) rebind(query string) string {
+	if s.dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SchemaVersion returns the version of the last applied migration, or 0 if
+// none has been applied
+func (s *Sink) SchemaVersion(ctx context.Context) (int, error) {
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+	var version int
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM `+TableMigrations)
+	if err := row.Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *Sink) createMigrationsTable(ctx context.Context) error {
+	stmt := columnTypes[s.dialect].Replace(`CREATE TABLE IF NOT EXISTS ` + TableMigrations + ` (
+	version INTEGER PRIMARY KEY,
+	applied_at {time} NOT NULL
+)`)
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
+
+// Migrate applies the migrations that the database does not have yet, each
+// in its own transaction. MySQL commits schema changes immediately, so a
+// failed migration there may need to be cleaned up by hand.
+func (s *Sink) Migrate(ctx context.Context) error {
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.apply(ctx, m); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+func (s *Sink) apply(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range m.statements {
+		if _, err := tx.ExecContext(ctx, columnTypes[s.dialect].Replace(stmt)); err != nil {
+			return err
+		}
+	}
+	insert := s.rebind(`INSERT INTO ` + TableMigrations + ` (version, applied_at) VALUES (?, ?)`)
+	if _, err := tx.ExecContext(ctx, insert, m.version, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Write stores a normalized result in one transaction and returns the ID of
+// its row in the searches table. Items without their own engine, as from a
+// single engine, are attributed to the engine of the search.
+func (s *Sink) Write(ctx context.Context, result *omniserp.NormalizedSearchResult) (int64, error) {
+	if result == nil {
+		return 0, fmt.Errorf("nil result")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := s.insertSearch(ctx, tx, result.SearchMetadata)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.insertResults(ctx, tx, id, result); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit results: %w", err)
+	}
+	return id, nil
+}
+
+// insertSearch inserts the search metadata and returns the new ID
+func (s *Sink) insertSearch(ctx context.Context, tx *sql.Tx, meta omniserp.SearchMetadata) (int64, error) {
+	query := `INSERT INTO ` + TableSearches + ` (engine, query, fingerprint, location, language, country, total_results, credits, corrected_query, searched_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	args := []any{
+		meta.Engine, meta.Query, nullString(meta.Fingerprint), nullString(meta.Location),
+		nullString(meta.Language), nullString(meta.Country), meta.TotalResults, meta.Credits,
+		nullString(meta.CorrectedQuery), time.Now().UTC(),
+	}
+
+	// Postgres drivers do not report the last insert ID
+	if s.dialect == Postgres {
+		var id int64
+		if err := tx.QueryRowContext(ctx, s.rebind(query+` RETURNING id`), args...).Scan(&id); err != nil {
+			return 0, fmt.Errorf("failed to insert search: %w", err)
+		}
+		return id, nil
+	}
+	res, err := tx.ExecContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert search: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get search ID: %w", err)
+	}
+	return id, nil
+}
+
+// insertResults inserts the result items of a search
+func (s *Sink) insertResults(ctx context.Context, tx *sql.Tx, id int64, result *omniserp.NormalizedSearchResult) error {
+	engine := func(itemEngine string) string {
+		if itemEngine != "" {
+			return itemEngine
+		}
+		return result.SearchMetadata.Engine
+	}
+
+	var rows [][]any
+	for _, r := range result.OrganicResults {
+		rows = append(rows, []any{id, r.Position, r.Title, r.Link, r.Snippet, nullString(r.Domain), nullString(r.Date), engine(r.Engine), nullTime(r.FetchedAt)})
+	}
+	if err := s.insertRows(ctx, tx, TableOrganicResults, "search_id, position, title, link, snippet, domain, date, engine, fetched_at", rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, r := range result.NewsResults {
+		rows = append(rows, []any{id, r.Position, r.Title, r.Link, nullString(r.Source), nullString(r.Date), r.Snippet, engine(r.Engine), nullTime(r.FetchedAt)})
+	}
+	if err := s.insertRows(ctx, tx, TableNewsResults, "search_id, position, title, link, source, date, snippet, engine, fetched_at", rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, r := range result.ShoppingResults {
+		rows = append(rows, []any{id, r.Position, r.Title, r.Link, nullString(r.ProductID), nullString(r.Price), nullString(r.OriginalPrice), nullString(r.Currency), r.Rating, r.Reviews, nullString(r.Source), engine(r.Engine), nullTime(r.FetchedAt)})
+	}
+	if err := s.insertRows(ctx, tx, TableShoppingResults, "search_id, position, title, link, product_id, price, original_price, currency, rating, reviews, source, engine, fetched_at", rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, r := range result.PlaceResults {
+		rows = append(rows, []any{id, r.Position, r.Title, nullString(r.PlaceID), nullString(r.Address), nullString(r.Phone), nullString(r.Website), r.Rating, r.Reviews, nullString(r.Type), r.Latitude, r.Longitude, engine(r.Engine), nullTime(r.FetchedAt)})
+	}
+	return s.insertRows(ctx, tx, TablePlaceResults, "search_id, position, title, place_id, address, phone, website, rating, reviews, type, latitude, longitude, engine, fetched_at", rows)
+}
+
+// insertRows inserts rows into the columns of a table with one prepared
+// statement
+func (s *Sink) insertRows(ctx context.Context, tx *sql.Tx, table, columns string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", strings.Count(columns, ",")+1), ", ")
+	stmt, err := tx.PrepareContext(ctx, s.rebind(`INSERT INTO `+table+` (`+columns+`) VALUES (`+placeholders+`)`))
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert into %s: %w", table, err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("failed to insert into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime stores zero times as NULL and other times in UTC
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
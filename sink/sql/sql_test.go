@@ -0,0 +1,257 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omniserp"
+)
+
+// fakeDB is a database/sql driver that records statements, tracks the
+// schema version, and hands out search IDs
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []fakeExec
+	version    int64
+	lastID     int64
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+// queries returns the recorded statements containing substr
+func (db *fakeDB) queries(substr string) []fakeExec {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var matches []fakeExec
+	for _, s := range db.statements {
+		if strings.Contains(s.query, substr) {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+func (db *fakeDB) record(query string, args []driver.Value) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.statements = append(db.statements, fakeExec{query: query, args: args})
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO "+TableMigrations):
+		db.version = args[0].(int64)
+	case strings.HasPrefix(query, "INSERT INTO "+TableSearches):
+		db.lastID++
+	}
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx *fakeTx) Commit() error   { tx.db.record("COMMIT", nil); return nil }
+func (tx *fakeTx) Rollback() error { tx.db.record("ROLLBACK", nil); return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+// Exec reports the last search ID as the insert ID
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.record(s.query, args)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return fakeResult{id: s.db.lastID}, nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.record(s.query, args)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if strings.Contains(s.query, "MAX(version)") {
+		return &fakeRows{value: s.db.version}, nil
+	}
+	return &fakeRows{value: s.db.lastID}, nil
+}
+
+// fakeRows is a single row with a single integer column
+type fakeRows struct {
+	value int64
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+type fakeResult struct{ id int64 }
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+func newTestSink(t *testing.T, dialect Dialect) (*Sink, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+
+	sink, err := New(db, dialect)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return sink, fake
+}
+
+func TestNewUnsupportedDialect(t *testing.T) {
+	if _, err := New(nil, "oracle"); err == nil {
+		t.Error("Expected an error for an unsupported dialect")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	types := map[Dialect]string{
+		Postgres: "GENERATED BY DEFAULT AS IDENTITY",
+		MySQL:    "AUTO_INCREMENT",
+		SQLite:   "AUTOINCREMENT",
+	}
+	for dialect, idType := range types {
+		t.Run(string(dialect), func(t *testing.T) {
+			sink, fake := newTestSink(t, dialect)
+			ctx := context.Background()
+
+			if err := sink.Migrate(ctx); err != nil {
+				t.Fatalf("Migrate failed: %v", err)
+			}
+			for _, table := range []string{TableSearches, TableOrganicResults, TableNewsResults, TableShoppingResults, TablePlaceResults} {
+				if len(fake.queries("CREATE TABLE "+table+" (")) != 1 {
+					t.Errorf("Expected table %s to be created", table)
+				}
+			}
+			if created := fake.queries("CREATE TABLE " + TableSearches); !strings.Contains(created[0].query, idType) {
+				t.Errorf("Expected %s IDs, got %s", idType, created[0].query)
+			}
+			for _, s := range fake.queries("") {
+				if strings.Contains(s.query, "{") {
+					t.Errorf("Unexpanded column type in %s", s.query)
+				}
+			}
+
+			// A migrated database is left as it is
+			if err := sink.Migrate(ctx); err != nil {
+				t.Fatalf("Second Migrate failed: %v", err)
+			}
+			if n := len(fake.queries("CREATE TABLE " + TableSearches)); n != 1 {
+				t.Errorf("Expected the schema to be created once, got %d times", n)
+			}
+			version, err := sink.SchemaVersion(ctx)
+			if err != nil || version != len(migrations) {
+				t.Errorf("Expected schema version %d, got %d (%v)", len(migrations), version, err)
+			}
+		})
+	}
+}
+
+func testResult() *omniserp.NormalizedSearchResult {
+	fetched := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	return &omniserp.NormalizedSearchResult{
+		SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "golang", Fingerprint: "abc123", Country: "us"},
+		OrganicResults: []omniserp.OrganicResult{
+			{Position: 1, Title: "Go", Link: "https://go.dev/", Domain: "go.dev"},
+			{Position: 2, Title: "Go on GitHub", Link: "https://github.com/golang/go", Domain: "github.com", Engine: "serpapi", FetchedAt: fetched},
+		},
+		NewsResults:     []omniserp.NewsResult{{Position: 1, Title: "Go 1.26", Link: "https://go.dev/blog", Source: "go.dev"}},
+		ShoppingResults: []omniserp.ShoppingResult{{Position: 1, Title: "Gopher plush", Price: "$20", Rating: 4.5, Reviews: 10}},
+		PlaceResults:    []omniserp.PlaceResult{{Position: 1, Title: "Cafe", Latitude: 30.26, Longitude: -97.74}},
+	}
+}
+
+func TestWritePostgres(t *testing.T) {
+	sink, fake := newTestSink(t, Postgres)
+
+	id, err := sink.Write(context.Background(), testResult())
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if id != 1 {
+		t.Errorf("Expected search ID 1, got %d", id)
+	}
+
+	searches := fake.queries("INSERT INTO " + TableSearches)
+	if len(searches) != 1 || !strings.Contains(searches[0].query, "RETURNING id") || !strings.Contains(searches[0].query, "$10") {
+		t.Fatalf("Unexpected search insert: %+v", searches)
+	}
+	if args := searches[0].args; args[0] != "serper" || args[2] != "abc123" || args[3] != nil {
+		t.Errorf("Unexpected search values: %v", args)
+	}
+
+	organic := fake.queries("INSERT INTO " + TableOrganicResults)
+	if len(organic) != 2 {
+		t.Fatalf("Expected 2 organic rows, got %d", len(organic))
+	}
+	// search_id, position, title, link, snippet, domain, date, engine, fetched_at
+	if args := organic[0].args; args[0] != int64(1) || args[5] != "go.dev" || args[7] != "serper" || args[8] != nil {
+		t.Errorf("Unexpected first organic row: %v", args)
+	}
+	if args := organic[1].args; args[7] != "serpapi" || args[8] != time.Date(2026, 1, 2, 2, 4, 5, 0, time.UTC) {
+		t.Errorf("Expected the item provenance in UTC, got %v", organic[1].args)
+	}
+	for _, table := range []string{TableNewsResults, TableShoppingResults, TablePlaceResults} {
+		if len(fake.queries("INSERT INTO "+table)) != 1 {
+			t.Errorf("Expected 1 row in %s", table)
+		}
+	}
+	if len(fake.queries("COMMIT")) != 1 {
+		t.Error("Expected the results to be committed")
+	}
+}
+
+func TestWriteLastInsertID(t *testing.T) {
+	sink, fake := newTestSink(t, SQLite)
+
+	for want := int64(1); want <= 2; want++ {
+		id, err := sink.Write(context.Background(), &omniserp.NormalizedSearchResult{
+			SearchMetadata: omniserp.SearchMetadata{Engine: "serper", Query: "golang"},
+		})
+		if err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if id != want {
+			t.Errorf("Expected search ID %d, got %d", want, id)
+		}
+	}
+	searches := fake.queries("INSERT INTO " + TableSearches)
+	if strings.Contains(searches[0].query, "$1") || strings.Contains(searches[0].query, "RETURNING") {
+		t.Errorf("Expected ? placeholders, got %s", searches[0].query)
+	}
+	if len(fake.queries("INSERT INTO "+TableOrganicResults)) != 0 {
+		t.Error("Expected no result rows for an empty result")
+	}
+}
@@ -0,0 +1,194 @@
+package omniserp
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultSourceScore is the score of sources that a SourceList does not
+// list
+const DefaultSourceScore = 0.5
+
+// SourceScorer scores the reputation of a publisher by the domain of a
+// result, from 0 for sources to avoid, such as content farms, to 1 for
+// trusted publishers. Scoring is called for every result, so implementations
+// backed by a remote service should cache.
+type SourceScorer interface {
+	ScoreSource(domain string) float64
+}
+
+// SourceScorerFunc adapts a function to the SourceScorer interface
+type SourceScorerFunc func(domain string) float64
+
+// ScoreSource implements SourceScorer
+func (f SourceScorerFunc) ScoreSource(domain string) float64 {
+	return f(domain)
+}
+
+// SourceList is a SourceScorer of listed domains. A domain matches an entry
+// for itself or for a parent domain, the most specific entry winning, so
+// "example.com" also scores "news.example.com".
+type SourceList struct {
+	// Scores maps lowercased domains to scores from 0 to 1
+	Scores map[string]float64 `json:"scores"`
+
+	// Default is the score of unlisted domains; nil uses
+	// DefaultSourceScore
+	Default *float64 `json:"default,omitempty"`
+}
+
+// NewSourceList creates a list that scores the trusted domains 1, the
+// demoted domains 0, and other domains DefaultSourceScore
+func NewSourceList(trusted, demoted []string) *SourceList {
+	list := &SourceList{Scores: make(map[string]float64)}
+	for _, domain := range trusted {
+		list.Scores[normalizeDomain(domain)] = 1
+	}
+	for _, domain := range demoted {
+		list.Scores[normalizeDomain(domain)] = 0
+	}
+	return list
+}
+
+// LoadSourceList reads a source list from a JSON file such as
+//
+//	{"default": 0.5, "scores": {"reuters.com": 1, "contentfarm.example": 0}}
+func LoadSourceList(path string) (*SourceList, error) {
+	// #nosec G304 -- source list path is provided by the caller
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source list: %w", err)
+	}
+	var list SourceList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse source list %s: %w", path, err)
+	}
+	scores := make(map[string]float64, len(list.Scores))
+	for domain, score := range list.Scores {
+		if score < 0 || score > 1 {
+			return nil, fmt.Errorf("source list %s: score of %s must be between 0 and 1, got %g", path, domain, score)
+		}
+		scores[normalizeDomain(domain)] = score
+	}
+	list.Scores = scores
+	return &list, nil
+}
+
+// ScoreSource implements SourceScorer
+func (l *SourceList) ScoreSource(domain string) float64 {
+	domain = normalizeDomain(domain)
+	for domain != "" {
+		if score, ok := l.Scores[domain]; ok {
+			return score
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	if l.Default != nil {
+		return *l.Default
+	}
+	return DefaultSourceScore
+}
+
+// normalizeDomain lowercases a domain and drops a leading "www." and a
+// trailing dot
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimSuffix(domain, ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// SourcePolicy applies the reputation of sources to the organic and news
+// results of a search (see ApplySourcePolicy)
+type SourcePolicy struct {
+	Scorer SourceScorer
+
+	// MinScore drops the results of sources scoring below it; zero keeps
+	// every result
+	MinScore float64
+
+	// Weight is the share of the source score in the order of the results,
+	// between 0 and 1, the rest being the engine's ranking; zero keeps the
+	// engine's order
+	Weight float64
+}
+
+// ApplySourcePolicy drops the organic and news results of sources scoring
+// below the policy's MinScore and reorders the rest by a blend of their
+// rank and source score, in place. A result is scored by its Domain, or by
+// the host of its link. The remaining results take over the positions of
+// the first results in order; PagePosition and SourcePosition keep the
+// engine's positions. A policy without a scorer changes nothing.
+func ApplySourcePolicy(result *NormalizedSearchResult, policy SourcePolicy) {
+	if result == nil || policy.Scorer == nil {
+		return
+	}
+	result.OrganicResults = applySourcePolicy(result.OrganicResults, policy, func(r *OrganicResult) (string, *int, *int) {
+		return resultDomain(r.Domain, r.Link), &r.Position, &r.SourcePosition
+	})
+	result.NewsResults = applySourcePolicy(result.NewsResults, policy, func(r *NewsResult) (string, *int, *int) {
+		return resultDomain("", r.Link), &r.Position, &r.SourcePosition
+	})
+}
+
+// resultDomain returns domain, or the host of link without one
+func resultDomain(domain, link string) string {
+	if domain != "" {
+		return domain
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// applySourcePolicy filters and reorders ranked items; fields returns the
+// domain, position, and source position of an item
+func applySourcePolicy[T any](items []T, policy SourcePolicy, fields func(*T) (string, *int, *int)) []T {
+	if len(items) == 0 {
+		return items
+	}
+	weight := min(max(policy.Weight, 0), 1)
+
+	type scored struct {
+		item  T
+		score float64
+	}
+	positions := make([]int, 0, len(items))
+	kept := make([]scored, 0, len(items))
+	for i := range items {
+		domain, position, _ := fields(&items[i])
+		positions = append(positions, *position)
+		score := policy.Scorer.ScoreSource(domain)
+		if policy.MinScore > 0 && score < policy.MinScore {
+			continue
+		}
+		relevance := 1 - float64(i)/float64(len(items))
+		kept = append(kept, scored{item: items[i], score: (1-weight)*relevance + weight*score})
+	}
+	if weight > 0 {
+		slices.SortStableFunc(kept, func(a, b scored) int {
+			return cmp.Compare(b.score, a.score)
+		})
+	}
+
+	out := make([]T, len(kept))
+	for i := range kept {
+		out[i] = kept[i].item
+		_, position, sourcePosition := fields(&out[i])
+		if *sourcePosition == 0 {
+			*sourcePosition = *position
+		}
+		*position = positions[i]
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package omniserp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceListScoreSource(t *testing.T) {
+	list := NewSourceList([]string{"Reuters.com", "www.apnews.com"}, []string{"contentfarm.example", "blog.reuters.com"})
+
+	tests := map[string]float64{
+		"reuters.com":           1,
+		"www.reuters.com":       1,
+		"world.reuters.com":     1,
+		"blog.reuters.com":      0,
+		"apnews.com":            1,
+		"a.contentfarm.example": 0,
+		"example.com":           DefaultSourceScore,
+		"":                      DefaultSourceScore,
+	}
+	for domain, want := range tests {
+		if got := list.ScoreSource(domain); got != want {
+			t.Errorf("ScoreSource(%q) = %g, want %g", domain, got, want)
+		}
+	}
+
+	unlisted := 0.2
+	list.Default = &unlisted
+	if got := list.ScoreSource("example.com"); got != unlisted {
+		t.Errorf("Expected the list default for unlisted domains, got %g", got)
+	}
+}
+
+func TestLoadSourceList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sources.json")
+	if err := os.WriteFile(path, []byte(`{"default": 0.4, "scores": {"WWW.Reuters.com": 0.9}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	list, err := LoadSourceList(path)
+	if err != nil {
+		t.Fatalf("LoadSourceList failed: %v", err)
+	}
+	if got := list.ScoreSource("reuters.com"); got != 0.9 {
+		t.Errorf("Expected the listed score, got %g", got)
+	}
+	if got := list.ScoreSource("example.com"); got != 0.4 {
+		t.Errorf("Expected the default score, got %g", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"scores": {"reuters.com": 2}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSourceList(path); err == nil {
+		t.Error("Expected an error for a score above 1")
+	}
+}
+
+func TestApplySourcePolicy(t *testing.T) {
+	newResult := func() *NormalizedSearchResult {
+		return &NormalizedSearchResult{
+			OrganicResults: []OrganicResult{
+				{Position: 11, Title: "Farm", Domain: "contentfarm.example"},
+				{Position: 12, Title: "Blog", Domain: "blog.example"},
+				{Position: 13, Title: "Wire", Domain: "reuters.com"},
+			},
+			NewsResults: []NewsResult{
+				{Position: 1, Title: "Farm", Link: "https://contentfarm.example/story"},
+				{Position: 2, Title: "Wire", Link: "https://www.reuters.com/story"},
+			},
+		}
+	}
+	scorer := NewSourceList([]string{"reuters.com"}, []string{"contentfarm.example"})
+
+	result := newResult()
+	ApplySourcePolicy(result, SourcePolicy{Scorer: scorer})
+	if result.OrganicResults[0].Title != "Farm" || result.OrganicResults[0].Position != 11 {
+		t.Errorf("Expected a policy without weight or minimum to keep the results, got %+v", result.OrganicResults)
+	}
+
+	result = newResult()
+	ApplySourcePolicy(result, SourcePolicy{Scorer: scorer, MinScore: 0.1})
+	organic := result.OrganicResults
+	if len(organic) != 2 || organic[0].Title != "Blog" || organic[0].Position != 11 || organic[0].SourcePosition != 12 {
+		t.Errorf("Expected the content farm dropped and positions renumbered, got %+v", organic)
+	}
+
+	result = newResult()
+	ApplySourcePolicy(result, SourcePolicy{Scorer: scorer, Weight: 0.5})
+	organic = result.OrganicResults
+	if organic[0].Title != "Wire" || organic[2].Title != "Farm" || organic[2].Position != 13 {
+		t.Errorf("Expected the trusted source first and the content farm last, got %+v", organic)
+	}
+	if news := result.NewsResults; news[0].Title != "Wire" || news[0].Position != 1 || news[0].SourcePosition != 2 {
+		t.Errorf("Expected news scored by the host of their link, got %+v", news)
+	}
+}
@@ -0,0 +1,206 @@
+package omniserp
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters per token of common
+// LLM tokenizers on English text
+const charsPerToken = 4
+
+// trimSnippetRunes is the length that snippets and other long strings are
+// truncated to before results are dropped
+const trimSnippetRunes = 200
+
+// EstimateTokens approximates the number of LLM tokens in s. It is a
+// heuristic of about four characters per token, close enough to budget
+// prompt space without depending on a model-specific tokenizer.
+func EstimateTokens(s string) int {
+	n := utf8.RuneCountInString(s)
+	return (n + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateResultTokens approximates the number of tokens of the JSON
+// encoding of a normalized result
+func EstimateResultTokens(result *NormalizedSearchResult) int {
+	return estimateJSONTokens(result)
+}
+
+// estimateJSONTokens approximates the number of tokens of the JSON encoding of v
+func estimateJSONTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return EstimateTokens(string(data))
+}
+
+// TruncateText shortens s to at most maxRunes runes, cutting at a word
+// boundary where possible and appending an ellipsis when truncated
+func TruncateText(s string, maxRunes int) string {
+	if maxRunes <= 0 || utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	runes := []rune(s)
+	cut := string(runes[:maxRunes-1])
+	if i := strings.LastIndexAny(cut, " \t\n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \t\n.,;:") + "…"
+}
+
+// TrimResult returns a copy of result that fits within maxTokens as
+// estimated by EstimateResultTokens. It drops the raw response, then
+// truncates snippets and page content, then drops the lowest-ranked entries
+// of the longest result lists until the result fits or no entries remain.
+// The original result is not modified; it is returned as is if it already
+// fits.
+func TrimResult(result *NormalizedSearchResult, maxTokens int) *NormalizedSearchResult {
+	if result == nil || maxTokens <= 0 || EstimateResultTokens(result) <= maxTokens {
+		return result
+	}
+
+	trimmed := *result
+	trimmed.Raw = nil
+	if EstimateResultTokens(&trimmed) <= maxTokens {
+		return &trimmed
+	}
+
+	trimmed.OrganicResults = slices.Clone(trimmed.OrganicResults)
+	for i := range trimmed.OrganicResults {
+		r := &trimmed.OrganicResults[i]
+		r.Snippet = TruncateText(r.Snippet, trimSnippetRunes)
+		r.RawContent = TruncateText(r.RawContent, trimSnippetRunes)
+	}
+	trimmed.NewsResults = slices.Clone(trimmed.NewsResults)
+	for i := range trimmed.NewsResults {
+		r := &trimmed.NewsResults[i]
+		r.Snippet = TruncateText(r.Snippet, trimSnippetRunes)
+	}
+	trimmed.VideoResults = slices.Clone(trimmed.VideoResults)
+	for i := range trimmed.VideoResults {
+		r := &trimmed.VideoResults[i]
+		r.Snippet = TruncateText(r.Snippet, trimSnippetRunes)
+	}
+	trimmed.ScholarResults = slices.Clone(trimmed.ScholarResults)
+	for i := range trimmed.ScholarResults {
+		r := &trimmed.ScholarResults[i]
+		r.Snippet = TruncateText(r.Snippet, trimSnippetRunes)
+	}
+	trimmed.PeopleAlsoAsk = slices.Clone(trimmed.PeopleAlsoAsk)
+	for i := range trimmed.PeopleAlsoAsk {
+		r := &trimmed.PeopleAlsoAsk[i]
+		r.Answer = TruncateText(r.Answer, trimSnippetRunes)
+	}
+
+	// Each list reports its length and drops its last (lowest-ranked) entry
+	lists := []struct {
+		size func() int
+		drop func()
+	}{
+		{func() int { return len(trimmed.OrganicResults) }, func() { trimmed.OrganicResults = trimmed.OrganicResults[:len(trimmed.OrganicResults)-1] }},
+		{func() int { return len(trimmed.NewsResults) }, func() { trimmed.NewsResults = trimmed.NewsResults[:len(trimmed.NewsResults)-1] }},
+		{func() int { return len(trimmed.ImageResults) }, func() { trimmed.ImageResults = trimmed.ImageResults[:len(trimmed.ImageResults)-1] }},
+		{func() int { return len(trimmed.VideoResults) }, func() { trimmed.VideoResults = trimmed.VideoResults[:len(trimmed.VideoResults)-1] }},
+		{func() int { return len(trimmed.PlaceResults) }, func() { trimmed.PlaceResults = trimmed.PlaceResults[:len(trimmed.PlaceResults)-1] }},
+		{func() int { return len(trimmed.ShoppingResults) }, func() { trimmed.ShoppingResults = trimmed.ShoppingResults[:len(trimmed.ShoppingResults)-1] }},
+		{func() int { return len(trimmed.ScholarResults) }, func() { trimmed.ScholarResults = trimmed.ScholarResults[:len(trimmed.ScholarResults)-1] }},
+		{func() int { return len(trimmed.RelatedSearches) }, func() { trimmed.RelatedSearches = trimmed.RelatedSearches[:len(trimmed.RelatedSearches)-1] }},
+		{func() int { return len(trimmed.PeopleAlsoAsk) }, func() { trimmed.PeopleAlsoAsk = trimmed.PeopleAlsoAsk[:len(trimmed.PeopleAlsoAsk)-1] }},
+		{func() int { return len(trimmed.Suggestions) }, func() { trimmed.Suggestions = trimmed.Suggestions[:len(trimmed.Suggestions)-1] }},
+	}
+	for EstimateResultTokens(&trimmed) > maxTokens {
+		longest := -1
+		for i, list := range lists {
+			if n := list.size(); n > 0 && (longest < 0 || n > lists[longest].size()) {
+				longest = i
+			}
+		}
+		if longest < 0 {
+			break
+		}
+		lists[longest].drop()
+	}
+	return &trimmed
+}
+
+// TrimData fits arbitrary JSON-encodable data, such as a raw engine
+// response, within maxTokens of JSON. Long strings are truncated first, then
+// the last entries of the longest arrays are dropped, assuming arrays are
+// ranked. It returns the data decoded into generic maps and slices, or data
+// itself if it already fits.
+func TrimData(data any, maxTokens int) (any, error) {
+	if maxTokens <= 0 || estimateJSONTokens(data) <= maxTokens {
+		return data, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+
+	value = truncateStrings(value, trimSnippetRunes)
+	for estimateJSONTokens(value) > maxTokens {
+		if !dropLongestArrayEntry(&value) {
+			break
+		}
+	}
+	return value, nil
+}
+
+// truncateStrings truncates every string in a decoded JSON value
+func truncateStrings(value any, maxRunes int) any {
+	switch v := value.(type) {
+	case string:
+		return TruncateText(v, maxRunes)
+	case map[string]any:
+		for k, item := range v {
+			v[k] = truncateStrings(item, maxRunes)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = truncateStrings(item, maxRunes)
+		}
+	}
+	return value
+}
+
+// dropLongestArrayEntry removes the last entry of the longest array in a
+// decoded JSON value and reports whether there was one
+func dropLongestArrayEntry(value *any) bool {
+	var longest *[]any
+	var visit func(v any, set func([]any))
+	var setLongest func([]any)
+	visit = func(v any, set func([]any)) {
+		switch v := v.(type) {
+		case map[string]any:
+			// Sorted so ties between arrays are broken deterministically
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				visit(v[k], func(a []any) { v[k] = a })
+			}
+		case []any:
+			if len(v) > 0 && (longest == nil || len(v) > len(*longest)) {
+				arr := v
+				longest, setLongest = &arr, set
+			}
+			for i, item := range v {
+				visit(item, func(a []any) { v[i] = a })
+			}
+		}
+	}
+	visit(*value, func(a []any) { *value = a })
+
+	if longest == nil {
+		return false
+	}
+	setLongest((*longest)[:len(*longest)-1])
+	return true
+}
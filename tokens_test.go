@@ -0,0 +1,86 @@
+package omniserp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":          0,
+		"go":        1,
+		"abcd":      1,
+		"abcde":     2,
+		"héllo wör": 3,
+	}
+	for s, want := range tests {
+		if got := EstimateTokens(s); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	if got := TruncateText("short", 10); got != "short" {
+		t.Errorf("Expected unchanged text, got %q", got)
+	}
+	if got := TruncateText("the quick brown fox jumps", 14); got != "the quick…" {
+		t.Errorf("Expected cut at a word boundary, got %q", got)
+	}
+}
+
+func TestTrimResult(t *testing.T) {
+	result := &NormalizedSearchResult{Raw: &SearchResult{Raw: strings.Repeat("x", 2000)}}
+	for i := range 20 {
+		result.OrganicResults = append(result.OrganicResults, OrganicResult{
+			Position: i + 1,
+			Title:    fmt.Sprintf("Result %d", i+1),
+			Snippet:  strings.Repeat("word ", 100),
+		})
+	}
+	result.RelatedSearches = []RelatedSearch{{Query: "related"}}
+
+	trimmed := TrimResult(result, 500)
+	if got := EstimateResultTokens(trimmed); got > 500 {
+		t.Errorf("Trimmed result uses %d tokens, budget 500", got)
+	}
+	if trimmed.Raw != nil {
+		t.Error("Expected raw response to be dropped")
+	}
+	if n := len(trimmed.OrganicResults); n == 0 || n == 20 {
+		t.Fatalf("Expected some organic results to be dropped, got %d", n)
+	}
+	if trimmed.OrganicResults[0].Position != 1 {
+		t.Errorf("Expected top-ranked results to be kept, got position %d", trimmed.OrganicResults[0].Position)
+	}
+	if len(trimmed.RelatedSearches) != 1 {
+		t.Error("Expected the shorter list to be kept")
+	}
+	if len(result.OrganicResults) != 20 || len(result.OrganicResults[0].Snippet) != 500 || result.Raw == nil {
+		t.Error("Expected the original result to be unchanged")
+	}
+}
+
+func TestTrimData(t *testing.T) {
+	var organic []map[string]any
+	for i := range 50 {
+		organic = append(organic, map[string]any{"position": i + 1, "snippet": strings.Repeat("a", 300)})
+	}
+	data := map[string]any{"organic": organic, "searchParameters": map[string]any{"q": "test"}}
+
+	trimmed, err := TrimData(data, 400)
+	if err != nil {
+		t.Fatalf("TrimData failed: %v", err)
+	}
+	if got := estimateJSONTokens(trimmed); got > 400 {
+		t.Errorf("Trimmed data uses %d tokens, budget 400", got)
+	}
+	kept := trimmed.(map[string]any)["organic"].([]any)
+	if len(kept) == 0 || len(kept) == 50 {
+		t.Fatalf("Expected some entries to be dropped, got %d", len(kept))
+	}
+	if first := kept[0].(map[string]any); first["position"] != float64(1) || len(first["snippet"].(string)) > 3*trimSnippetRunes {
+		t.Errorf("Unexpected first entry: %v", first)
+	}
+}
@@ -0,0 +1,194 @@
+package omniserp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransportConfig configures the HTTP transport of an engine, such as the
+// client certificate of a self-hosted engine behind an mTLS gateway, or the
+// static egress of API keys allowlisted by IP address
+type TransportConfig struct {
+	// CertFile and KeyFile are the PEM client certificate and key presented
+	// to servers that request one
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+
+	// CAFile is a PEM bundle of the CAs that verify server certificates,
+	// instead of the system roots, such as the CA of a private gateway
+	CAFile string `json:"ca_file,omitempty"`
+
+	// ServerName overrides the host name verified in server certificates
+	ServerName string `json:"server_name,omitempty"`
+
+	// Hosts limits the TLS settings above to requests for these host
+	// names, such as the API host of the engine, so other hosts are not
+	// sent the client certificate and are verified with the system roots.
+	// Empty applies them to every host.
+	Hosts []string `json:"hosts,omitempty"`
+
+	// LocalAddr is the local IP address or network interface name that
+	// requests are sent from, for API keys allowlisted by IP address. An
+	// interface sends from its first IPv4 address, or else its first
+	// address.
+	LocalAddr string `json:"local_addr,omitempty"`
+
+	// Resolve maps host names to the IP addresses to connect to instead of
+	// resolving them with DNS, like curl --resolve. TLS still verifies the
+	// host name. With an HTTP proxy, it applies to the proxy's host.
+	Resolve map[string]string `json:"resolve,omitempty"`
+}
+
+// NewTransport returns a transport with the settings of http.DefaultTransport
+// and the configuration
+func (c TransportConfig) NewTransport() (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	switch {
+	case c.CertFile != "" && c.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case c.CertFile != "" || c.KeyFile != "":
+		return nil, fmt.Errorf("client certificate and key are both required")
+	}
+	if c.CAFile != "" {
+		// #nosec G304 -- configured CA bundle
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.LocalAddr != "" || len(c.Resolve) > 0 {
+		dial, err := c.dialer()
+		if err != nil {
+			return nil, err
+		}
+		transport.DialContext = dial
+	}
+
+	if len(c.Hosts) == 0 {
+		transport.TLSClientConfig = tlsConfig
+		return transport, nil
+	}
+	scoped := transport.Clone()
+	scoped.TLSClientConfig = tlsConfig
+	hosts := make(map[string]bool, len(c.Hosts))
+	for _, host := range c.Hosts {
+		hosts[strings.ToLower(host)] = true
+	}
+	return &hostTransport{hosts: hosts, scoped: scoped, base: transport}, nil
+}
+
+// hostTransport sends the requests for its hosts with the scoped transport
+// and all other requests with the base transport
+type hostTransport struct {
+	hosts  map[string]bool
+	scoped http.RoundTripper
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hosts[strings.ToLower(req.URL.Hostname())] {
+		return t.scoped.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// dialer returns a dial function that connects from LocalAddr to the
+// addresses of Resolve
+func (c TransportConfig) dialer() (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	for host, ip := range c.Resolve {
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("invalid IP address %q for host %s", ip, host)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var ipv4 bool
+	if c.LocalAddr != "" {
+		ip, err := localIP(c.LocalAddr)
+		if err != nil {
+			return nil, err
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+		ipv4 = ip.To4() != nil
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip, ok := c.Resolve[host]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		// Connect over the address family of the local address
+		if network == "tcp" && dialer.LocalAddr != nil {
+			network = "tcp6"
+			if ipv4 {
+				network = "tcp4"
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// localIP returns the IP address of a local address or interface name
+func localIP(localAddr string) (net.IP, error) {
+	if ip := net.ParseIP(localAddr); ip != nil {
+		return ip, nil
+	}
+	iface, err := net.InterfaceByName(localAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local address %q: %w", localAddr, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of interface %s: %w", localAddr, err)
+	}
+	var first net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if first == nil {
+			first = ipNet.IP
+		}
+	}
+	if first == nil {
+		return nil, fmt.Errorf("interface %s has no IP address", localAddr)
+	}
+	return first, nil
+}
+
+// SetTransport sets the transport of client, keeping a request signer set
+// with SignRequests
+func SetTransport(client *http.Client, rt http.RoundTripper) {
+	if t, ok := client.Transport.(*SigningTransport); ok {
+		t.Base = rt
+		return
+	}
+	client.Transport = rt
+}
@@ -0,0 +1,258 @@
+package omniserp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes a file in dir and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestTransportConfigMTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "searxng.internal", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "omniserp", x509.ExtKeyUsageClientAuth)
+
+	pair, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("Failed to load server certificate: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	var clientName string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientName = r.TLS.PeerCertificates[0].Subject.CommonName
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	config := TransportConfig{
+		CertFile:   writeFile(t, dir, "client.pem", clientCert),
+		KeyFile:    writeFile(t, dir, "client-key.pem", clientKey),
+		CAFile:     writeFile(t, dir, "ca.pem", ca.pem),
+		ServerName: "searxng.internal",
+	}
+	transport, err := config.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if clientName != "omniserp" {
+		t.Errorf("Expected the client certificate, got %q", clientName)
+	}
+
+	// Without the client certificate the gateway rejects the handshake
+	config.CertFile, config.KeyFile = "", ""
+	transport, err = config.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	if resp, err := (&http.Client{Transport: transport}).Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+
+	// The TLS settings apply only to the configured hosts
+	config.CertFile = writeFile(t, dir, "client.pem", clientCert)
+	config.KeyFile = writeFile(t, dir, "client-key.pem", clientKey)
+	config.Hosts = []string{"127.0.0.1"}
+	transport, err = config.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	clientName = ""
+	resp, err = (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get of a configured host failed: %v", err)
+	}
+	resp.Body.Close()
+	if clientName != "omniserp" {
+		t.Errorf("Expected the client certificate for a configured host, got %q", clientName)
+	}
+	config.Hosts = []string{"api.example.test"}
+	transport, err = config.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	if resp, err := (&http.Client{Transport: transport}).Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Expected other hosts to be verified with the system roots")
+	}
+}
+
+func TestTransportConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]TransportConfig{
+		"cert without key": {CertFile: "client.pem"},
+		"missing files":    {CertFile: filepath.Join(dir, "nope.pem"), KeyFile: filepath.Join(dir, "nope-key.pem")},
+		"missing CA":       {CAFile: filepath.Join(dir, "nope.pem")},
+		"CA without certs": {CAFile: writeFile(t, dir, "empty.pem", []byte("not a certificate"))},
+	}
+	for name, config := range tests {
+		if _, err := config.NewTransport(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSetTransportKeepsSigner(t *testing.T) {
+	client := &http.Client{}
+	SignRequests(client, &HMACSigner{Key: []byte("secret")})
+	transport := &http.Transport{}
+	SetTransport(client, transport)
+
+	signing, ok := client.Transport.(*SigningTransport)
+	if !ok || signing.Base != transport {
+		t.Errorf("Expected the signer to wrap the new transport, got %#v", client.Transport)
+	}
+}
+
+func TestTransportConfigEgress(t *testing.T) {
+	var host, remote string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, remote = r.Host, r.RemoteAddr
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	config := TransportConfig{
+		LocalAddr: "127.0.0.1",
+		Resolve:   map[string]string{"api.example.test": "127.0.0.1"},
+	}
+	transport, err := config.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get("http://api.example.test:" + port + "/search")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if host != "api.example.test:"+port {
+		t.Errorf("Expected the request for the resolved host, got %q", host)
+	}
+	if ip, _, _ := net.SplitHostPort(remote); ip != "127.0.0.1" {
+		t.Errorf("Expected the request from the local address, got %q", remote)
+	}
+}
+
+func TestTransportConfigInterface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("No network interfaces: %v", err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		ip, err := localIP(iface.Name)
+		if err != nil || !ip.IsLoopback() {
+			t.Errorf("Expected a loopback address of %s, got %v, %v", iface.Name, ip, err)
+		}
+		return
+	}
+	t.Skip("No loopback interface")
+}
+
+func TestTransportConfigEgressErrors(t *testing.T) {
+	tests := map[string]TransportConfig{
+		"unknown interface": {LocalAddr: "nope0"},
+		"invalid resolve":   {Resolve: map[string]string{"api.example.test": "not-an-ip"}},
+	}
+	for name, config := range tests {
+		if _, err := config.NewTransport(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -11,17 +11,97 @@ type SearchParams struct {
 	Language   string `json:"language,omitempty" jsonschema:"description:Search language (e.g., 'en')"`
 	Country    string `json:"country,omitempty" jsonschema:"description:Country code (e.g., 'us')"`
 	NumResults int    `json:"num_results,omitempty" jsonschema:"description:Number of results (1-100),default:10"`
+	Page       int    `json:"page,omitempty" jsonschema:"description:Results page starting at 1,default:1"`
+
+	// Locale presets the country, language, and Google domain by name, such
+	// as "de-DE" (see Locales); explicitly set fields take precedence
+	Locale string `json:"locale,omitempty" jsonschema:"description:Country and language preset (e.g., 'de-DE', 'ja-JP', 'pt-BR')"`
+
+	// GoogleDomain is the Google domain to search, such as "google.de"; it
+	// is honored by engines that search Google by domain and ignored by
+	// others
+	GoogleDomain string `json:"google_domain,omitempty" jsonschema:"description:Google domain to search (e.g., 'google.de')"`
+
+	// Freshness limits results to those published within a recent period
+	Freshness Freshness `json:"freshness,omitempty" jsonschema:"description:Only results from the past hour, day, week, month, or year"`
+
+	// SafeSearch filters explicit results
+	SafeSearch bool `json:"safe_search,omitempty" jsonschema:"description:Filter explicit results"`
+
+	// Verbatim searches for the query as written, without automatic
+	// spelling correction
+	Verbatim bool `json:"verbatim,omitempty" jsonschema:"description:Search for the query as written without spelling correction"`
+
+	// Image filters the results of image searches by size, aspect ratio,
+	// transparency, and usage rights; it is ignored by other searches
+	Image *ImageFilter `json:"image,omitempty" jsonschema:"description:Image search filters"`
+
+	// NewsRanking reorders the results of news searches by recency and
+	// source diversity instead of the engine's ranking (see RankNews); it is
+	// ignored by other searches
+	NewsRanking *NewsRanking `json:"news_ranking,omitempty" jsonschema:"description:Rank news results by recency and source diversity for a briefing"`
+
+	// Cites restricts scholar searches to the papers citing the paper with
+	// this ID (see ScholarResult.CitesID); the query is then optional. It is
+	// honored by engines reporting ParamCites and ignored by other searches.
+	Cites string `json:"cites,omitempty" jsonschema:"description:Only scholar results citing the paper with this cites ID"`
+
+	// Extra holds engine-specific parameters by name, as described by the
+	// engines implementing ExtraParamReporter. Each engine sends only the
+	// parameters it describes.
+	Extra map[string]any `json:"extra,omitempty" jsonschema:"description:Engine-specific parameters by name"`
+}
+
+// Freshness is a recency filter for search results
+type Freshness string
+
+// Freshness periods supported by SearchParams
+const (
+	FreshnessHour  Freshness = "hour"
+	FreshnessDay   Freshness = "day"
+	FreshnessWeek  Freshness = "week"
+	FreshnessMonth Freshness = "month"
+	FreshnessYear  Freshness = "year"
+)
+
+// TBS returns the Google "tbs" time filter for the period, such as "qdr:w",
+// or "" if the period is empty or unknown
+func (f Freshness) TBS() string {
+	switch f {
+	case FreshnessHour:
+		return "qdr:h"
+	case FreshnessDay:
+		return "qdr:d"
+	case FreshnessWeek:
+		return "qdr:w"
+	case FreshnessMonth:
+		return "qdr:m"
+	case FreshnessYear:
+		return "qdr:y"
+	}
+	return ""
 }
 
 // ScrapeParams represents parameters for web scraping
 type ScrapeParams struct {
 	URL string `json:"url" jsonschema:"description:URL to scrape"`
+
+	// MaxRedirects limits the redirects followed; 0 uses DefaultMaxRedirects
+	// and a negative value disallows redirects
+	MaxRedirects int `json:"max_redirects,omitempty" jsonschema:"description:Maximum redirects to follow (0 for the default of 10; -1 to disallow redirects)"`
+
+	// SameOrigin rejects redirects to a different scheme, host, or port
+	SameOrigin bool `json:"same_origin,omitempty" jsonschema:"description:Reject redirects to another origin"`
 }
 
 // SearchResult represents a common search result structure
 type SearchResult struct {
 	Data interface{} `json:"data"`
 	Raw  string      `json:"raw,omitempty"`
+
+	// Response describes the HTTP response of the engine API; it is nil for
+	// results that were not fetched from an API
+	Response *ResponseMeta `json:"response,omitempty"`
 }
 
 // Engine defines the interface that all search engines must implement
@@ -72,6 +152,19 @@ type Engine interface {
 	ScrapeWebpage(ctx context.Context, params ScrapeParams) (*SearchResult, error)
 }
 
+// Credits reports the remaining API credits of an engine account
+type Credits struct {
+	Engine    string `json:"engine"`
+	Remaining int    `json:"remaining"`
+	Limit     int    `json:"limit,omitempty"` // credits per billing period, if known
+}
+
+// CreditReporter is implemented by engines that can report the remaining
+// credits of their account
+type CreditReporter interface {
+	Credits(ctx context.Context) (*Credits, error)
+}
+
 // Registry manages available search engines
 type Registry struct {
 	engines map[string]Engine